@@ -0,0 +1,98 @@
+package admin
+
+import (
+	"fmt"
+	"net"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/paths"
+)
+
+// Server serves the admin API as JSON-RPC over a unix socket
+type Server struct {
+	listener net.Listener
+	path     string
+}
+
+// Serve starts the admin API on the socket named in the config, or the default socket path
+func Serve(cfg *config.Live, deps Dependencies) (*Server, error) {
+	socketPath := ""
+	if admin := cfg.Load().Admin; admin != nil {
+		socketPath = admin.Socket
+	}
+	if socketPath == "" {
+		defaultPath, err := paths.GetAdminSocketPath()
+		if err != nil {
+			return nil, err
+		}
+		socketPath = defaultPath
+	}
+
+	return Listen(socketPath, NewService(cfg, deps))
+}
+
+// Listen serves the given service on a unix socket at socketPath
+func Listen(socketPath string, service *Service) (*Server, error) {
+	rpcServer := rpc.NewServer()
+	if err := rpcServer.RegisterName("Admin", service); err != nil {
+		return nil, fmt.Errorf("failed to register admin service: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(socketPath), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+
+	// Only the owning user may manage the metatool, so the socket is bound in a directory nobody else
+	// can enter and only moved into place once its permissions are restricted
+	privateDir, err := os.MkdirTemp(filepath.Dir(socketPath), ".admin-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	defer os.RemoveAll(privateDir)
+
+	boundPath := filepath.Join(privateDir, "admin.sock")
+	listener, err := net.Listen("unix", boundPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", socketPath, err)
+	}
+	if err := os.Chmod(boundPath, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+
+	// Replaces any stale socket left behind by a previous run
+	if err := os.Rename(boundPath, socketPath); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to move socket into place: %w", err)
+	}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go rpcServer.ServeCodec(jsonrpc.NewServerCodec(conn))
+		}
+	}()
+
+	logging.Infof("Admin API listening on %s", socketPath)
+	return &Server{listener: listener, path: socketPath}, nil
+}
+
+// Path returns the socket path the server is listening on
+func (s *Server) Path() string {
+	return s.path
+}
+
+// Close stops accepting connections and removes the socket
+func (s *Server) Close() error {
+	err := s.listener.Close()
+	os.Remove(s.path)
+	return err
+}
@@ -0,0 +1,284 @@
+package admin
+
+import (
+	"context"
+	"net/rpc"
+	"net/rpc/jsonrpc"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/approval"
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/persistence"
+)
+
+// fakeProxy records calls and returns canned tool lists
+type fakeProxy struct {
+	calls []string
+}
+
+func (f *fakeProxy) GetAllTools() map[string][]*mcp.Tool {
+	return map[string][]*mcp.Tool{"github": {{Name: "get_issue"}, {Name: "delete_repo"}}}
+}
+
+func (f *fakeProxy) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	f.calls = append(f.calls, serverName+"."+toolName)
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "done"}}}, nil
+}
+
+func (f *fakeProxy) RestartServer(serverName string) ([]*mcp.Tool, error) {
+	f.calls = append(f.calls, "restart "+serverName)
+	return f.GetAllTools()[serverName], nil
+}
+
+func startTestServer(t *testing.T, proxyManager *fakeProxy) *rpc.Client {
+	return startTestServerWith(t, Dependencies{Upstream: proxyManager})
+}
+
+func startTestServerWith(t *testing.T, deps Dependencies) *rpc.Client {
+	t.Helper()
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	cfg := &config.Config{
+		MCPServers: map[string]config.MCPServerConfig{
+			"github": {Command: "mcp-server-github", ApprovalRequired: []string{"delete_*"}},
+			"slack":  {Command: "mcp-server-slack", Hidden: true},
+		},
+	}
+
+	socketDir := t.TempDir()
+	socketPath := filepath.Join(socketDir, "admin.sock")
+	server, err := Listen(socketPath, NewService(config.NewLive(cfg), deps))
+	if err != nil {
+		t.Fatalf("Listen() error = %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+
+	info, err := os.Stat(socketPath)
+	if err != nil {
+		t.Fatalf("Expected socket to exist: %v", err)
+	}
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected socket permissions 0600, got %v", info.Mode().Perm())
+	}
+	if entries, _ := os.ReadDir(socketDir); len(entries) != 1 {
+		t.Errorf("Expected only the socket in its directory, got %d entries", len(entries))
+	}
+
+	client, err := jsonrpc.Dial("unix", socketPath)
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	return client
+}
+
+func TestAdminAPI_Servers(t *testing.T) {
+	client := startTestServer(t, &fakeProxy{})
+
+	var servers ServersReply
+	if err := client.Call("Admin.ListServers", &Empty{}, &servers); err != nil {
+		t.Fatalf("ListServers error = %v", err)
+	}
+	if len(servers.Servers) != 2 {
+		t.Fatalf("Expected 2 servers, got %d", len(servers.Servers))
+	}
+	if !servers.Servers[0].Connected || servers.Servers[0].ToolCount != 2 {
+		t.Errorf("Expected github connected with 2 tools, got %+v", servers.Servers[0])
+	}
+	if servers.Servers[1].Connected {
+		t.Errorf("Expected slack to be disconnected, got %+v", servers.Servers[1])
+	}
+
+	var restarted ServerInfo
+	if err := client.Call("Admin.RestartServer", &NameArgs{Name: "github"}, &restarted); err != nil {
+		t.Fatalf("RestartServer error = %v", err)
+	}
+	if restarted.ToolCount != 2 {
+		t.Errorf("Expected restarted github to have 2 tools, got %+v", restarted)
+	}
+	if err := client.Call("Admin.RemoveServer", &NameArgs{Name: "github"}, &ServerChangeReply{}); err == nil {
+		t.Error("Expected error removing a server without a config editor")
+	}
+
+	var policies PoliciesReply
+	if err := client.Call("Admin.GetPolicies", &Empty{}, &policies); err != nil {
+		t.Fatalf("GetPolicies error = %v", err)
+	}
+	if len(policies.Servers["github"].ApprovalRequired) != 1 || !policies.Servers["slack"].Hidden {
+		t.Errorf("Unexpected policies: %+v", policies)
+	}
+}
+
+func TestService_ReloadedConfig(t *testing.T) {
+	live := config.NewLive(&config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"github": {Command: "mcp-server-github"},
+	}})
+	service := NewService(live, Dependencies{Upstream: &fakeProxy{}})
+
+	// A reload replaces the config, and later requests report the replacement
+	live.Store(&config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"github": {Command: "mcp-server-github-v2", HiddenTools: []string{"delete_*"}},
+		"linear": {Command: "mcp-server-linear"},
+	}})
+
+	var servers ServersReply
+	if err := service.ListServers(&Empty{}, &servers); err != nil {
+		t.Fatalf("ListServers error = %v", err)
+	}
+	if len(servers.Servers) != 2 || servers.Servers[0].Command != "mcp-server-github-v2" {
+		t.Errorf("Expected the reloaded servers, got %+v", servers.Servers)
+	}
+
+	var restarted ServerInfo
+	if err := service.RestartServer(&NameArgs{Name: "github"}, &restarted); err != nil {
+		t.Fatalf("RestartServer error = %v", err)
+	}
+	if restarted.Command != "mcp-server-github-v2" {
+		t.Errorf("Expected the reloaded command, got %+v", restarted)
+	}
+
+	var policies PoliciesReply
+	if err := service.GetPolicies(&Empty{}, &policies); err != nil {
+		t.Fatalf("GetPolicies error = %v", err)
+	}
+	if len(policies.Servers["github"].HiddenTools) != 1 {
+		t.Errorf("Expected the reloaded policies, got %+v", policies)
+	}
+}
+
+func TestAdminAPI_Tools(t *testing.T) {
+	ctx := context.Background()
+	mcpServer := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	client := startTestServerWith(t, Dependencies{MCPServer: mcpServer, Upstream: &fakeProxy{}})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := mcpServer.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	clientSession, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientSession.Close()
+	listed := func() []string {
+		result, err := clientSession.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		return names
+	}
+
+	// Permissions are only granted by the operator with mcp-metatool grant
+	tool := &persistence.SavedToolDefinition{Name: "double", Description: "Double a number", Code: `params["n"] * 2`, Permissions: []string{"notify"}}
+	var saved SaveToolReply
+	if err := client.Call("Admin.SaveTool", tool, &saved); err != nil {
+		t.Fatalf("SaveTool error = %v", err)
+	}
+	if saved.Tool.Version != 1 || len(saved.Tool.Permissions) != 0 {
+		t.Errorf("Expected version 1 without permissions, got %+v", saved.Tool)
+	}
+	if names := listed(); !slices.Equal(names, []string{"double"}) {
+		t.Errorf("Expected saved tool to be listed, got %v", names)
+	}
+
+	if err := client.Call("Admin.SaveTool", &persistence.SavedToolDefinition{Name: "bad"}, &saved); err == nil {
+		t.Error("Expected error saving tool without description")
+	}
+	if err := client.Call("Admin.SaveTool", &persistence.SavedToolDefinition{Name: "bad", Description: "Bad", Code: "def f(:\n  pass"}, &saved); err == nil {
+		t.Error("Expected error saving tool that doesn't compile")
+	}
+
+	var tools ToolsReply
+	if err := client.Call("Admin.ListTools", &Empty{}, &tools); err != nil {
+		t.Fatalf("ListTools error = %v", err)
+	}
+	if len(tools.Tools) != 1 || tools.Tools[0].Name != "double" {
+		t.Errorf("Unexpected tools: %+v", tools.Tools)
+	}
+
+	var loaded persistence.SavedToolDefinition
+	if err := client.Call("Admin.GetTool", &NameArgs{Name: "double"}, &loaded); err != nil {
+		t.Fatalf("GetTool error = %v", err)
+	}
+	if loaded.Code != tool.Code {
+		t.Errorf("Expected code %q, got %q", tool.Code, loaded.Code)
+	}
+
+	if err := client.Call("Admin.DeleteTool", &NameArgs{Name: "double"}, &Empty{}); err != nil {
+		t.Fatalf("DeleteTool error = %v", err)
+	}
+	if err := client.Call("Admin.GetTool", &NameArgs{Name: "double"}, &loaded); err == nil {
+		t.Error("Expected error loading deleted tool")
+	}
+	if names := listed(); len(names) != 0 {
+		t.Errorf("Expected deleted tool to be unlisted, got %v", names)
+	}
+}
+
+func TestAdminAPI_Approvals(t *testing.T) {
+	proxyManager := &fakeProxy{}
+	client := startTestServer(t, proxyManager)
+
+	first, _ := approval.Enqueue("github", "delete_repo", map[string]interface{}{"repo": "a"})
+	second, _ := approval.Enqueue("github", "delete_repo", map[string]interface{}{"repo": "b"})
+
+	var approvals ApprovalsReply
+	if err := client.Call("Admin.ListApprovals", &Empty{}, &approvals); err != nil {
+		t.Fatalf("ListApprovals error = %v", err)
+	}
+	if len(approvals.Calls) != 2 {
+		t.Fatalf("Expected 2 pending calls, got %d", len(approvals.Calls))
+	}
+
+	var result mcp.CallToolResult
+	if err := client.Call("Admin.ApproveCall", &IDArgs{ID: first.ID}, &result); err != nil {
+		t.Fatalf("ApproveCall error = %v", err)
+	}
+	if len(proxyManager.calls) != 1 || proxyManager.calls[0] != "github.delete_repo" {
+		t.Errorf("Expected approved call to be executed, got %v", proxyManager.calls)
+	}
+
+	if err := client.Call("Admin.DenyCall", &IDArgs{ID: second.ID}, &Empty{}); err != nil {
+		t.Fatalf("DenyCall error = %v", err)
+	}
+	if err := client.Call("Admin.DenyCall", &IDArgs{ID: second.ID}, &Empty{}); err == nil {
+		t.Error("Expected error denying an already resolved call")
+	}
+
+	if len(proxyManager.calls) != 1 {
+		t.Errorf("Expected denied call not to run, got %v", proxyManager.calls)
+	}
+}
+
+func TestAdminAPI_SetDebug(t *testing.T) {
+	client := startTestServer(t, &fakeProxy{})
+	defer logging.SetDebug(logging.Debug())
+
+	enabled := true
+	var reply DebugReply
+	if err := client.Call("Admin.SetDebug", &DebugArgs{Enabled: &enabled}, &reply); err != nil {
+		t.Fatalf("SetDebug error = %v", err)
+	}
+	if !reply.Debug || !logging.Debug() {
+		t.Errorf("SetDebug(true) = %+v, debug = %v; want enabled", reply, logging.Debug())
+	}
+
+	if err := client.Call("Admin.SetDebug", &DebugArgs{}, &reply); err != nil {
+		t.Fatalf("SetDebug error = %v", err)
+	}
+	if reply.Debug || logging.Debug() {
+		t.Errorf("SetDebug({}) = %+v, want debug logging toggled off", reply)
+	}
+}
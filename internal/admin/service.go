@@ -0,0 +1,299 @@
+package admin
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/approval"
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/tools"
+)
+
+// Empty is used for methods that take no arguments or return no data
+type Empty struct{}
+
+// NameArgs identifies a saved tool
+type NameArgs struct {
+	Name string `json:"name"`
+}
+
+// IDArgs identifies a pending approval
+type IDArgs struct {
+	ID string `json:"id"`
+}
+
+// ServerInfo describes a configured upstream server
+type ServerInfo struct {
+	Name      string `json:"name"`
+	Command   string `json:"command"`
+	Connected bool   `json:"connected"`
+	ToolCount int    `json:"toolCount"`
+}
+
+// ServerArgs adds a server, or replaces the settings of an existing one
+type ServerArgs struct {
+	Name   string                 `json:"name"`
+	Server config.MCPServerConfig `json:"server"`
+}
+
+// ServerChangeReply reports how a server change was applied to the running servers
+type ServerChangeReply struct {
+	Summary *proxy.ReloadSummary `json:"summary"`
+}
+
+// SaveToolReply returns a saved tool along with the permissions its new code lost
+type SaveToolReply struct {
+	Tool    *persistence.SavedToolDefinition `json:"tool"`
+	Revoked []string                         `json:"revoked,omitempty"`
+}
+
+// ServersReply lists the configured upstream servers
+type ServersReply struct {
+	Servers []ServerInfo `json:"servers"`
+}
+
+// ToolsReply lists saved tools
+type ToolsReply struct {
+	Tools []*persistence.SavedToolDefinition `json:"tools"`
+}
+
+// ApprovalsReply lists calls awaiting approval
+type ApprovalsReply struct {
+	Calls []*approval.PendingCall `json:"calls"`
+}
+
+// ServerPolicy holds the tool filtering and approval settings of one server
+type ServerPolicy struct {
+	Hidden           bool     `json:"hidden,omitempty"`
+	AllowedTools     []string `json:"allowedTools,omitempty"`
+	HiddenTools      []string `json:"hiddenTools,omitempty"`
+	ApprovalRequired []string `json:"approvalRequired,omitempty"`
+}
+
+// PoliciesReply describes the policies currently in effect
+type PoliciesReply struct {
+	Servers      map[string]ServerPolicy             `json:"servers"`
+	BuiltinTools map[string]config.BuiltinToolConfig `json:"builtinTools,omitempty"`
+}
+
+// DebugArgs sets debug logging on or off, or flips it when Enabled is omitted
+type DebugArgs struct {
+	Enabled *bool `json:"enabled,omitempty"`
+}
+
+// DebugReply reports whether debug logging is enabled
+type DebugReply struct {
+	Debug bool `json:"debug"`
+}
+
+// Dependencies are what the admin service needs from the running metatool.
+// Nil fields leave the methods that need them reporting that they're unavailable.
+type Dependencies struct {
+	MCPServer       *mcp.Server        // saved tools are added to and removed from its tool list
+	Upstream        proxy.ProxyManager // ungated, used to execute approved calls and restart servers
+	GatedUpstream   proxy.ProxyManager // used by saved tools, subject to approval gating
+	StarlarkOptions []starlark.Option
+	Editor          tools.ConfigEditor
+}
+
+// Service implements the admin API methods, exposed over JSON-RPC as "Admin.<Method>"
+type Service struct {
+	config *config.Live
+	deps   Dependencies
+}
+
+// NewService creates an admin service reporting on whichever config is in effect
+func NewService(cfg *config.Live, deps Dependencies) *Service {
+	return &Service{config: cfg, deps: deps}
+}
+
+// ListServers reports each configured server and whether it is connected
+func (s *Service) ListServers(args *Empty, reply *ServersReply) error {
+	var serverTools map[string][]*mcp.Tool
+	if s.deps.Upstream != nil {
+		serverTools = s.deps.Upstream.GetAllTools()
+	}
+
+	reply.Servers = []ServerInfo{}
+	for name, serverConfig := range s.config.Load().MCPServers {
+		discovered, connected := serverTools[name]
+		reply.Servers = append(reply.Servers, ServerInfo{
+			Name:      name,
+			Command:   serverConfig.Command,
+			Connected: connected,
+			ToolCount: len(discovered),
+		})
+	}
+
+	sort.Slice(reply.Servers, func(i, j int) bool {
+		return reply.Servers[i].Name < reply.Servers[j].Name
+	})
+	return nil
+}
+
+// RestartServer relaunches a server and reports its rediscovered tools
+func (s *Service) RestartServer(args *NameArgs, reply *ServerInfo) error {
+	if s.deps.Upstream == nil {
+		return fmt.Errorf("no upstream servers are connected")
+	}
+	serverTools, err := proxy.RestartServer(s.deps.Upstream, args.Name)
+	if err != nil {
+		return fmt.Errorf("failed to restart server '%s': %w", args.Name, err)
+	}
+	*reply = ServerInfo{
+		Name:      args.Name,
+		Command:   s.config.Load().MCPServers[args.Name].Command,
+		Connected: true,
+		ToolCount: len(serverTools),
+	}
+	return nil
+}
+
+// AddServer adds a server to servers.json, or replaces an existing server's settings, and applies the change
+func (s *Service) AddServer(args *ServerArgs, reply *ServerChangeReply) error {
+	if args.Name == "" {
+		return fmt.Errorf("server name is required")
+	}
+	return s.editServers(reply, func(cfg *config.Config) error {
+		if cfg.MCPServers == nil {
+			cfg.MCPServers = make(map[string]config.MCPServerConfig)
+		}
+		cfg.MCPServers[args.Name] = args.Server
+		return nil
+	})
+}
+
+// RemoveServer removes a server from servers.json and stops it
+func (s *Service) RemoveServer(args *NameArgs, reply *ServerChangeReply) error {
+	return s.editServers(reply, func(cfg *config.Config) error {
+		if _, exists := cfg.MCPServers[args.Name]; !exists {
+			return fmt.Errorf("server %s is not configured", args.Name)
+		}
+		delete(cfg.MCPServers, args.Name)
+		return nil
+	})
+}
+
+// editServers edits servers.json and applies it to the running servers, as add_server and remove_server do
+func (s *Service) editServers(reply *ServerChangeReply, edit func(cfg *config.Config) error) error {
+	if s.deps.Editor == nil {
+		return fmt.Errorf("managing servers requires a valid servers.json at startup")
+	}
+	summary, err := s.deps.Editor.Edit(edit)
+	reply.Summary = summary
+	return err
+}
+
+// ListTools returns every saved tool definition
+func (s *Service) ListTools(args *Empty, reply *ToolsReply) error {
+	saved, err := persistence.ListTools()
+	if err != nil {
+		return fmt.Errorf("failed to list saved tools: %w", err)
+	}
+	reply.Tools = saved
+	return nil
+}
+
+// GetTool returns a single saved tool definition
+func (s *Service) GetTool(args *NameArgs, reply *persistence.SavedToolDefinition) error {
+	tool, err := persistence.LoadTool(args.Name)
+	if err != nil {
+		return fmt.Errorf("tool '%s' not found: %w", args.Name, err)
+	}
+	*reply = *tool
+	return nil
+}
+
+// SaveTool creates or updates a saved tool as save_tool does and returns the stored definition.
+// Permissions in the definition are ignored; they are granted with mcp-metatool grant.
+func (s *Service) SaveTool(args *persistence.SavedToolDefinition, reply *SaveToolReply) error {
+	revoked, err := tools.SaveTool(s.deps.MCPServer, args, s.deps.GatedUpstream, s.deps.StarlarkOptions...)
+	if err != nil {
+		return fmt.Errorf("failed to save tool: %w", err)
+	}
+	reply.Tool, reply.Revoked = args, revoked
+	return nil
+}
+
+// DeleteTool deletes a saved tool as delete_saved_tool does, keeping a backup that can be restored
+func (s *Service) DeleteTool(args *NameArgs, reply *Empty) error {
+	if err := tools.DeleteTool(s.deps.MCPServer, args.Name); err != nil {
+		return fmt.Errorf("failed to delete tool '%s': %w", args.Name, err)
+	}
+	return nil
+}
+
+// ListApprovals returns the calls awaiting approval
+func (s *Service) ListApprovals(args *Empty, reply *ApprovalsReply) error {
+	calls, err := approval.List()
+	if err != nil {
+		return fmt.Errorf("failed to list pending approvals: %w", err)
+	}
+	reply.Calls = calls
+	return nil
+}
+
+// ApproveCall executes a pending call against its upstream server and returns the result
+func (s *Service) ApproveCall(args *IDArgs, reply *mcp.CallToolResult) error {
+	if s.deps.Upstream == nil {
+		return fmt.Errorf("no upstream servers are connected")
+	}
+
+	call, err := approval.Load(args.ID)
+	if err != nil {
+		return err
+	}
+
+	// Remove before executing so a call can never run twice
+	if err := approval.Remove(call.ID); err != nil {
+		return err
+	}
+
+	result, err := s.deps.Upstream.CallTool(call.Server, call.Tool, call.Arguments)
+	if err != nil {
+		return fmt.Errorf("approved call %s failed: %w", call.ID, err)
+	}
+	*reply = *result
+	return nil
+}
+
+// DenyCall discards a pending call
+func (s *Service) DenyCall(args *IDArgs, reply *Empty) error {
+	if _, err := approval.Load(args.ID); err != nil {
+		return err
+	}
+	return approval.Remove(args.ID)
+}
+
+// GetPolicies reports the tool filtering, approval and built-in tool settings
+func (s *Service) GetPolicies(args *Empty, reply *PoliciesReply) error {
+	cfg := s.config.Load()
+	reply.Servers = make(map[string]ServerPolicy)
+	for name, serverConfig := range cfg.MCPServers {
+		reply.Servers[name] = ServerPolicy{
+			Hidden:           serverConfig.Hidden,
+			AllowedTools:     serverConfig.AllowedTools,
+			HiddenTools:      serverConfig.HiddenTools,
+			ApprovalRequired: serverConfig.ApprovalRequired,
+		}
+	}
+	reply.BuiltinTools = cfg.BuiltinTools
+	return nil
+}
+
+// SetDebug switches debug logging on or off without restarting the server
+func (s *Service) SetDebug(args *DebugArgs, reply *DebugReply) error {
+	if args.Enabled == nil {
+		reply.Debug = logging.ToggleDebug()
+		return nil
+	}
+	logging.SetDebug(*args.Enabled)
+	reply.Debug = *args.Enabled
+	return nil
+}
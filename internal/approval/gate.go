@@ -0,0 +1,115 @@
+package approval
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+)
+
+// PendingError is returned when a call has been queued for approval instead of executed
+type PendingError struct {
+	Call *PendingCall
+}
+
+// Error implements the error interface
+func (e *PendingError) Error() string {
+	return fmt.Sprintf("call to %s.%s requires approval; queued as %s", e.Call.Server, e.Call.Tool, e.Call.ID)
+}
+
+// Gate wraps a ProxyManager and queues calls that the config marks as requiring approval
+type Gate struct {
+	proxyManager proxy.ProxyManager
+	config       *config.Live
+}
+
+// NewGate creates an approval gate in front of the given proxy manager, applying the approval
+// requirements of whichever configuration is in effect at the time of each call
+func NewGate(proxyManager proxy.ProxyManager, cfg *config.Live) *Gate {
+	return &Gate{
+		proxyManager: proxyManager,
+		config:       cfg,
+	}
+}
+
+// GetAllTools returns all discovered tools from the wrapped proxy manager
+func (g *Gate) GetAllTools() map[string][]*mcp.Tool {
+	return g.proxyManager.GetAllTools()
+}
+
+// PendingServers returns the servers the wrapped proxy manager has yet to start
+func (g *Gate) PendingServers() []string {
+	return proxy.PendingServers(g.proxyManager)
+}
+
+// EnsureStarted starts a pending server in the wrapped proxy manager
+func (g *Gate) EnsureStarted(serverName string) ([]*mcp.Tool, error) {
+	return proxy.EnsureStarted(g.proxyManager, serverName)
+}
+
+// ServerStatuses reports the state of the wrapped proxy manager's servers
+func (g *Gate) ServerStatuses() []proxy.ServerStatus {
+	statuses, _ := proxy.ServerStatuses(g.proxyManager)
+	return statuses
+}
+
+// ToolPrefix returns the prefix of one of the wrapped proxy manager's servers' tool names
+func (g *Gate) ToolPrefix(serverName string) string {
+	return proxy.ToolPrefix(g.proxyManager, serverName)
+}
+
+// ToolAliases returns the aliases of one of the wrapped proxy manager's servers' tools
+func (g *Gate) ToolAliases(serverName string) map[string]string {
+	return proxy.ToolAliases(g.proxyManager, serverName)
+}
+
+// ServerStderr returns what one of the wrapped proxy manager's servers wrote to stderr
+func (g *Gate) ServerStderr(serverName string) []string {
+	return proxy.ServerStderr(g.proxyManager, serverName)
+}
+
+// RestartServer restarts one of the wrapped proxy manager's servers
+func (g *Gate) RestartServer(serverName string) ([]*mcp.Tool, error) {
+	return proxy.RestartServer(g.proxyManager, serverName)
+}
+
+// InstanceID identifies the instance serving one of the wrapped proxy manager's servers
+func (g *Gate) InstanceID(serverName string) (uint64, bool) {
+	return proxy.InstanceID(g.proxyManager, serverName)
+}
+
+// CheckHealth pings the wrapped proxy manager's servers
+func (g *Gate) CheckHealth() {
+	proxy.CheckHealth(g.proxyManager)
+}
+
+// CallTool forwards the call, or queues it and returns a PendingError if approval is required
+func (g *Gate) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return g.CallToolContext(context.Background(), serverName, toolName, arguments)
+}
+
+// CallToolContext is CallTool, forwarding ctx to the wrapped proxy manager
+func (g *Gate) CallToolContext(ctx context.Context, serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if g.RequiresApproval(serverName, toolName) {
+		call, err := Enqueue(serverName, toolName, arguments)
+		if err != nil {
+			return nil, fmt.Errorf("failed to queue call for approval: %w", err)
+		}
+		return nil, &PendingError{Call: call}
+	}
+
+	return proxy.CallToolContext(ctx, g.proxyManager, serverName, toolName, arguments)
+}
+
+// RequiresApproval reports whether calls to the given tool are gated
+func (g *Gate) RequiresApproval(serverName, toolName string) bool {
+	cfg := g.config.Load()
+	if cfg == nil {
+		return false
+	}
+	serverConfig, exists := cfg.MCPServers[serverName]
+	return exists && serverConfig.RequiresApproval(toolName)
+}
@@ -0,0 +1,66 @@
+package approval
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+// recordingProxy records calls made through it
+type recordingProxy struct {
+	calls []string
+}
+
+func (r *recordingProxy) GetAllTools() map[string][]*mcp.Tool {
+	return map[string][]*mcp.Tool{"github": {{Name: "delete_repo"}, {Name: "get_issue"}}}
+}
+
+func (r *recordingProxy) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	r.calls = append(r.calls, serverName+"."+toolName)
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+}
+
+func TestGateCallTool(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	cfg := &config.Config{
+		MCPServers: map[string]config.MCPServerConfig{
+			"github": {Command: "test", ApprovalRequired: []string{"delete_*"}},
+		},
+	}
+	inner := &recordingProxy{}
+	gate := NewGate(inner, config.NewLive(cfg))
+
+	// Ungated tools pass straight through
+	if _, err := gate.CallTool("github", "get_issue", nil); err != nil {
+		t.Fatalf("Expected ungated call to succeed, got %v", err)
+	}
+	if len(inner.calls) != 1 {
+		t.Errorf("Expected 1 forwarded call, got %d", len(inner.calls))
+	}
+
+	// Gated tools are queued and not forwarded
+	_, err := gate.CallTool("github", "delete_repo", map[string]interface{}{"repo": "demo"})
+	var pending *PendingError
+	if !errors.As(err, &pending) {
+		t.Fatalf("Expected PendingError, got %v", err)
+	}
+	if len(inner.calls) != 1 {
+		t.Errorf("Gated call should not be forwarded, got %d calls", len(inner.calls))
+	}
+
+	queued, err := List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(queued) != 1 || queued[0].ID != pending.Call.ID {
+		t.Errorf("Expected gated call to be queued, got %+v", queued)
+	}
+
+	if len(gate.GetAllTools()["github"]) != 2 {
+		t.Error("Expected GetAllTools to pass through to the wrapped proxy")
+	}
+}
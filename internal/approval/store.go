@@ -0,0 +1,158 @@
+package approval
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// PendingCall represents an upstream tool call waiting for human approval
+type PendingCall struct {
+	ID          string                 `json:"id"`
+	Server      string                 `json:"server"`
+	Tool        string                 `json:"tool"`
+	Arguments   map[string]interface{} `json:"arguments,omitempty"`
+	RequestedAt time.Time              `json:"requestedAt"`
+}
+
+// Enqueue records a call in the pending approvals store
+func Enqueue(serverName, toolName string, arguments map[string]interface{}) (*PendingCall, error) {
+	approvalsDir, err := paths.GetApprovalsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := newID()
+	if err != nil {
+		return nil, err
+	}
+
+	call := &PendingCall{
+		ID:          id,
+		Server:      serverName,
+		Tool:        toolName,
+		Arguments:   arguments,
+		RequestedAt: time.Now().UTC(),
+	}
+
+	data, err := json.MarshalIndent(call, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal pending call: %w", err)
+	}
+
+	if err := storage.WriteFile(filepath.Join(approvalsDir, id+".json"), data, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write pending call: %w", err)
+	}
+
+	return call, nil
+}
+
+// Load reads a single pending call by ID
+func Load(id string) (*PendingCall, error) {
+	if err := validateID(id); err != nil {
+		return nil, err
+	}
+
+	approvalsDir, err := paths.GetApprovalsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := storage.ReadFile(filepath.Join(approvalsDir, id+".json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no pending call with id '%s'", id)
+		}
+		return nil, fmt.Errorf("failed to read pending call: %w", err)
+	}
+
+	var call PendingCall
+	if err := json.Unmarshal(data, &call); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal pending call: %w", err)
+	}
+
+	return &call, nil
+}
+
+// List returns all pending calls, oldest first
+func List() ([]*PendingCall, error) {
+	approvalsDir, err := paths.GetApprovalsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := storage.ReadDir(approvalsDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read approvals directory: %w", err)
+	}
+
+	var calls []*PendingCall
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+			continue
+		}
+
+		call, err := Load(strings.TrimSuffix(entry.Name(), ".json"))
+		if err != nil {
+			// Skip malformed entries but continue with others
+			continue
+		}
+		calls = append(calls, call)
+	}
+
+	sort.Slice(calls, func(i, j int) bool {
+		return calls[i].RequestedAt.Before(calls[j].RequestedAt)
+	})
+
+	return calls, nil
+}
+
+// Remove deletes a pending call from the store
+func Remove(id string) error {
+	if err := validateID(id); err != nil {
+		return err
+	}
+
+	approvalsDir, err := paths.GetApprovalsDir()
+	if err != nil {
+		return err
+	}
+
+	if err := storage.Remove(filepath.Join(approvalsDir, id+".json")); err != nil {
+		if os.IsNotExist(err) {
+			return fmt.Errorf("no pending call with id '%s'", id)
+		}
+		return fmt.Errorf("failed to remove pending call: %w", err)
+	}
+
+	return nil
+}
+
+// newID generates a short random identifier for a pending call
+func newID() (string, error) {
+	buf := make([]byte, 6)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate approval id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// validateID ensures an approval ID cannot escape the approvals directory
+func validateID(id string) error {
+	if id == "" {
+		return fmt.Errorf("approval id cannot be empty")
+	}
+	if _, err := hex.DecodeString(id); err != nil {
+		return fmt.Errorf("invalid approval id '%s'", id)
+	}
+	return nil
+}
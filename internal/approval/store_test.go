@@ -0,0 +1,89 @@
+package approval
+
+import (
+	"testing"
+)
+
+func TestEnqueueLoadRemove(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	call, err := Enqueue("github", "delete_repo", map[string]interface{}{"repo": "demo"})
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	if call.ID == "" {
+		t.Fatal("Expected pending call to have an ID")
+	}
+
+	loaded, err := Load(call.ID)
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if loaded.Server != "github" || loaded.Tool != "delete_repo" {
+		t.Errorf("Unexpected pending call: %+v", loaded)
+	}
+
+	if loaded.Arguments["repo"] != "demo" {
+		t.Errorf("Expected repo='demo', got %v", loaded.Arguments["repo"])
+	}
+
+	if err := Remove(call.ID); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+
+	if _, err := Load(call.ID); err == nil {
+		t.Error("Expected error loading removed call")
+	}
+
+	if err := Remove(call.ID); err == nil {
+		t.Error("Expected error removing a call twice")
+	}
+}
+
+func TestList(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	calls, err := List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("Expected no pending calls, got %d", len(calls))
+	}
+
+	first, _ := Enqueue("github", "delete_repo", nil)
+	second, _ := Enqueue("slack", "post_message", nil)
+
+	calls, err = List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(calls) != 2 {
+		t.Fatalf("Expected 2 pending calls, got %d", len(calls))
+	}
+	if calls[0].ID != first.ID || calls[1].ID != second.ID {
+		t.Error("Expected pending calls to be ordered oldest first")
+	}
+}
+
+func TestValidateID(t *testing.T) {
+	tests := []struct {
+		id      string
+		wantErr bool
+	}{
+		{"a1b2c3d4e5f6", false},
+		{"", true},
+		{"../servers", true},
+		{"not-hex", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.id, func(t *testing.T) {
+			if err := validateID(tt.id); (err != nil) != tt.wantErr {
+				t.Errorf("validateID(%q) error = %v, wantErr %v", tt.id, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,128 @@
+package artifacts
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// URIPrefix is the resource URI prefix under which artifacts are exposed
+const URIPrefix = "metatool://artifacts/"
+
+// metaSuffix is appended to an artifact's filename to store its metadata
+const metaSuffix = ".meta.json"
+
+// Artifact describes a published result file
+type Artifact struct {
+	Name     string `json:"name"`
+	URI      string `json:"uri"`
+	MIMEType string `json:"mimeType"`
+	Size     int64  `json:"size"`
+}
+
+// Publish stores content as a named artifact, replacing any previous artifact with that name
+func Publish(name, content, mimeType string) (*Artifact, error) {
+	if err := validateName(name); err != nil {
+		return nil, err
+	}
+
+	if mimeType == "" {
+		mimeType = "text/plain"
+	}
+
+	artifactsDir, err := paths.GetArtifactsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	artifact := &Artifact{
+		Name:     name,
+		URI:      URIPrefix + name,
+		MIMEType: mimeType,
+		Size:     int64(len(content)),
+	}
+
+	if err := storage.WriteFile(filepath.Join(artifactsDir, name), []byte(content), 0644); err != nil {
+		return nil, fmt.Errorf("failed to write artifact: %w", err)
+	}
+
+	meta, err := json.MarshalIndent(artifact, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal artifact metadata: %w", err)
+	}
+	if err := storage.WriteFile(filepath.Join(artifactsDir, name+metaSuffix), meta, 0644); err != nil {
+		return nil, fmt.Errorf("failed to write artifact metadata: %w", err)
+	}
+
+	return artifact, nil
+}
+
+// Read loads an artifact's metadata and content
+func Read(name string) (*Artifact, []byte, error) {
+	if err := validateName(name); err != nil {
+		return nil, nil, err
+	}
+
+	artifactsDir, err := paths.GetArtifactsDir()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	content, err := storage.ReadFile(filepath.Join(artifactsDir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil, fmt.Errorf("artifact '%s' does not exist", name)
+		}
+		return nil, nil, fmt.Errorf("failed to read artifact: %w", err)
+	}
+
+	artifact := &Artifact{
+		Name:     name,
+		URI:      URIPrefix + name,
+		MIMEType: "application/octet-stream",
+		Size:     int64(len(content)),
+	}
+	if meta, err := storage.ReadFile(filepath.Join(artifactsDir, name+metaSuffix)); err == nil {
+		json.Unmarshal(meta, artifact)
+	}
+
+	return artifact, content, nil
+}
+
+// NameFromURI extracts the artifact name from a resource URI
+func NameFromURI(uri string) (string, bool) {
+	if !strings.HasPrefix(uri, URIPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(uri, URIPrefix), true
+}
+
+// validateName ensures the artifact name is safe for filesystem use
+func validateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("artifact name cannot be empty")
+	}
+
+	if len(name) > 200 {
+		return fmt.Errorf("artifact name too long (max 200 characters)")
+	}
+
+	if strings.HasSuffix(name, metaSuffix) {
+		return fmt.Errorf("artifact name cannot end with %s", metaSuffix)
+	}
+
+	// Check for filesystem-unsafe characters
+	unsafe := []string{"/", "\\", ":", "*", "?", "\"", "<", ">", "|", "..", " "}
+	for _, char := range unsafe {
+		if strings.Contains(name, char) {
+			return fmt.Errorf("artifact name contains invalid character: %s", char)
+		}
+	}
+
+	return nil
+}
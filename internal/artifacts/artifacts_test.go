@@ -0,0 +1,80 @@
+package artifacts
+
+import (
+	"testing"
+)
+
+func TestPublishAndRead(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	artifact, err := Publish("report.csv", "a,b\n1,2\n", "text/csv")
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	if artifact.URI != "metatool://artifacts/report.csv" {
+		t.Errorf("Unexpected URI: %s", artifact.URI)
+	}
+	if artifact.Size != 8 {
+		t.Errorf("Expected size 8, got %d", artifact.Size)
+	}
+
+	loaded, content, err := Read("report.csv")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if string(content) != "a,b\n1,2\n" {
+		t.Errorf("Unexpected content: %q", content)
+	}
+	if loaded.MIMEType != "text/csv" {
+		t.Errorf("Expected mime type to round-trip, got %s", loaded.MIMEType)
+	}
+
+	if _, _, err := Read("missing.txt"); err == nil {
+		t.Error("Expected error reading missing artifact")
+	}
+}
+
+func TestPublishDefaultMIMEType(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	artifact, err := Publish("notes", "hello", "")
+	if err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+	if artifact.MIMEType != "text/plain" {
+		t.Errorf("Expected text/plain default, got %s", artifact.MIMEType)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"report.csv", false},
+		{"export-2025_01.json", false},
+		{"", true},
+		{"../escape", true},
+		{"dir/file", true},
+		{"has space", true},
+		{"sneaky.meta.json", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("validateName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNameFromURI(t *testing.T) {
+	if name, ok := NameFromURI("metatool://artifacts/report.csv"); !ok || name != "report.csv" {
+		t.Errorf("NameFromURI() = %q, %v", name, ok)
+	}
+	if _, ok := NameFromURI("file:///etc/passwd"); ok {
+		t.Error("Expected foreign URI to be rejected")
+	}
+}
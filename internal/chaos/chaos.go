@@ -0,0 +1,231 @@
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/proxy"
+)
+
+// EnvVar enables fault injection when set, e.g. "delay=0.2,error=0.1,truncate=0.1,maxDelay=2s,seed=42"
+const EnvVar = "MCP_METATOOL_CHAOS"
+
+// Config holds the fault injection rates, each a probability between 0 and 1
+type Config struct {
+	DelayRate    float64
+	MaxDelay     time.Duration
+	ErrorRate    float64
+	TruncateRate float64
+	Seed         int64
+}
+
+// ConfigFromEnv parses the fault injection settings from the environment
+// It returns nil when fault injection is disabled
+func ConfigFromEnv() (*Config, error) {
+	value := os.Getenv(EnvVar)
+	if value == "" {
+		return nil, nil
+	}
+	return ParseConfig(value)
+}
+
+// ParseConfig parses comma-separated key=value settings
+// Keys are delay, maxDelay, error, truncate and seed; maxDelay defaults to 1s
+func ParseConfig(value string) (*Config, error) {
+	cfg := &Config{MaxDelay: time.Second, Seed: time.Now().UnixNano()}
+
+	for _, setting := range strings.Split(value, ",") {
+		setting = strings.TrimSpace(setting)
+		if setting == "" {
+			continue
+		}
+
+		key, raw, ok := strings.Cut(setting, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid chaos setting '%s': expected key=value", setting)
+		}
+
+		var err error
+		switch key {
+		case "delay":
+			cfg.DelayRate, err = parseRate(raw)
+		case "error":
+			cfg.ErrorRate, err = parseRate(raw)
+		case "truncate":
+			cfg.TruncateRate, err = parseRate(raw)
+		case "maxDelay":
+			cfg.MaxDelay, err = time.ParseDuration(raw)
+		case "seed":
+			cfg.Seed, err = strconv.ParseInt(raw, 10, 64)
+		default:
+			return nil, fmt.Errorf("unknown chaos setting '%s'", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid chaos setting '%s': %w", setting, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+// parseRate parses a probability between 0 and 1
+func parseRate(raw string) (float64, error) {
+	rate, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, err
+	}
+	if rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("rate must be between 0 and 1")
+	}
+	return rate, nil
+}
+
+// Injector wraps a proxy manager and randomly delays, fails, or truncates upstream calls
+type Injector struct {
+	proxyManager proxy.ProxyManager
+	config       Config
+	mu           sync.Mutex
+	rand         *rand.Rand
+	sleep        func(time.Duration)
+}
+
+// Wrap places a fault injector in front of the given proxy manager
+func Wrap(proxyManager proxy.ProxyManager, cfg *Config) *Injector {
+	return &Injector{
+		proxyManager: proxyManager,
+		config:       *cfg,
+		rand:         rand.New(rand.NewSource(cfg.Seed)),
+		sleep:        time.Sleep,
+	}
+}
+
+// WrapFromEnv wraps the proxy manager in a fault injector if enabled via the environment,
+// otherwise it returns the proxy manager unchanged
+func WrapFromEnv(proxyManager proxy.ProxyManager) (proxy.ProxyManager, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil || cfg == nil {
+		return proxyManager, err
+	}
+	return Wrap(proxyManager, cfg), nil
+}
+
+// GetAllTools passes through to the wrapped proxy manager
+func (i *Injector) GetAllTools() map[string][]*mcp.Tool {
+	return i.proxyManager.GetAllTools()
+}
+
+// PendingServers passes through to the wrapped proxy manager
+func (i *Injector) PendingServers() []string {
+	return proxy.PendingServers(i.proxyManager)
+}
+
+// EnsureStarted passes through to the wrapped proxy manager
+func (i *Injector) EnsureStarted(serverName string) ([]*mcp.Tool, error) {
+	return proxy.EnsureStarted(i.proxyManager, serverName)
+}
+
+// ServerStatuses passes through to the wrapped proxy manager
+func (i *Injector) ServerStatuses() []proxy.ServerStatus {
+	statuses, _ := proxy.ServerStatuses(i.proxyManager)
+	return statuses
+}
+
+// ToolPrefix passes through to the wrapped proxy manager
+func (i *Injector) ToolPrefix(serverName string) string {
+	return proxy.ToolPrefix(i.proxyManager, serverName)
+}
+
+// ToolAliases passes through to the wrapped proxy manager
+func (i *Injector) ToolAliases(serverName string) map[string]string {
+	return proxy.ToolAliases(i.proxyManager, serverName)
+}
+
+// ServerStderr passes through to the wrapped proxy manager
+func (i *Injector) ServerStderr(serverName string) []string {
+	return proxy.ServerStderr(i.proxyManager, serverName)
+}
+
+// RestartServer passes through to the wrapped proxy manager
+func (i *Injector) RestartServer(serverName string) ([]*mcp.Tool, error) {
+	return proxy.RestartServer(i.proxyManager, serverName)
+}
+
+// InstanceID passes through to the wrapped proxy manager
+func (i *Injector) InstanceID(serverName string) (uint64, bool) {
+	return proxy.InstanceID(i.proxyManager, serverName)
+}
+
+// CheckHealth passes through to the wrapped proxy manager
+func (i *Injector) CheckHealth() {
+	proxy.CheckHealth(i.proxyManager)
+}
+
+// CallTool calls the wrapped proxy manager, injecting faults at the configured rates
+func (i *Injector) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return i.CallToolContext(context.Background(), serverName, toolName, arguments)
+}
+
+// CallToolContext is CallTool, forwarding ctx to the wrapped proxy manager
+func (i *Injector) CallToolContext(ctx context.Context, serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if i.roll(i.config.DelayRate) {
+		i.sleep(i.delay())
+	}
+
+	if i.roll(i.config.ErrorRate) {
+		return nil, fmt.Errorf("chaos: injected failure calling %s.%s", serverName, toolName)
+	}
+
+	result, err := proxy.CallToolContext(ctx, i.proxyManager, serverName, toolName, arguments)
+	if err != nil || result == nil {
+		return result, err
+	}
+
+	if i.roll(i.config.TruncateRate) {
+		return i.truncate(result), nil
+	}
+	return result, nil
+}
+
+// roll reports whether an event with the given probability happens
+func (i *Injector) roll(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.rand.Float64() < rate
+}
+
+// delay picks a random delay up to the configured maximum
+func (i *Injector) delay() time.Duration {
+	if i.config.MaxDelay <= 0 {
+		return 0
+	}
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return time.Duration(i.rand.Int63n(int64(i.config.MaxDelay)))
+}
+
+// truncate returns a copy of the result with its text content cut short and structured content dropped,
+// as if the response had been cut off in transit
+func (i *Injector) truncate(result *mcp.CallToolResult) *mcp.CallToolResult {
+	truncated := &mcp.CallToolResult{IsError: result.IsError}
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok && len(text.Text) > 0 {
+			i.mu.Lock()
+			cut := i.rand.Intn(len(text.Text))
+			i.mu.Unlock()
+			content = &mcp.TextContent{Text: text.Text[:cut]}
+		}
+		truncated.Content = append(truncated.Content, content)
+	}
+	return truncated
+}
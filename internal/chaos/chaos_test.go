@@ -0,0 +1,145 @@
+package chaos
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+type stubProxy struct {
+	calls int
+}
+
+func (s *stubProxy) GetAllTools() map[string][]*mcp.Tool {
+	return map[string][]*mcp.Tool{"github": {{Name: "get_issue"}}}
+}
+
+func (s *stubProxy) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	s.calls++
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: `{"title": "A fairly long issue title"}`}},
+		StructuredContent: map[string]interface{}{"title": "A fairly long issue title"},
+	}, nil
+}
+
+func TestParseConfig(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    Config
+		wantErr bool
+	}{
+		{"all settings", "delay=0.5, error=0.1,truncate=1,maxDelay=2s,seed=7", Config{DelayRate: 0.5, ErrorRate: 0.1, TruncateRate: 1, MaxDelay: 2 * time.Second, Seed: 7}, false},
+		{"default max delay", "delay=1,seed=1", Config{DelayRate: 1, MaxDelay: time.Second, Seed: 1}, false},
+		{"rate out of range", "error=1.5", Config{}, true},
+		{"unknown key", "explode=0.1", Config{}, true},
+		{"missing value", "delay", Config{}, true},
+		{"bad duration", "maxDelay=soon", Config{}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := ParseConfig(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && *cfg != tt.want {
+				t.Errorf("ParseConfig() = %+v, want %+v", *cfg, tt.want)
+			}
+		})
+	}
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	t.Setenv(EnvVar, "")
+	if cfg, err := ConfigFromEnv(); cfg != nil || err != nil {
+		t.Errorf("Expected chaos disabled, got %+v, %v", cfg, err)
+	}
+
+	t.Setenv(EnvVar, "error=0.5")
+	if cfg, err := ConfigFromEnv(); err != nil || cfg.ErrorRate != 0.5 {
+		t.Errorf("Expected error rate 0.5, got %+v, %v", cfg, err)
+	}
+}
+
+func TestInjector(t *testing.T) {
+	t.Run("no faults", func(t *testing.T) {
+		stub := &stubProxy{}
+		injector := Wrap(stub, &Config{Seed: 1})
+		result, err := injector.CallTool("github", "get_issue", nil)
+		if err != nil || result.StructuredContent == nil {
+			t.Errorf("Expected untouched result, got %+v, %v", result, err)
+		}
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		stub := &stubProxy{}
+		injector := Wrap(stub, &Config{ErrorRate: 1, Seed: 1})
+		_, err := injector.CallTool("github", "get_issue", nil)
+		if err == nil || !strings.Contains(err.Error(), "chaos") {
+			t.Errorf("Expected injected error, got %v", err)
+		}
+		if stub.calls != 0 {
+			t.Error("Expected failed call not to reach upstream")
+		}
+	})
+
+	t.Run("truncates", func(t *testing.T) {
+		injector := Wrap(&stubProxy{}, &Config{TruncateRate: 1, Seed: 1})
+		result, err := injector.CallTool("github", "get_issue", nil)
+		if err != nil {
+			t.Fatalf("CallTool() error = %v", err)
+		}
+		if result.StructuredContent != nil {
+			t.Error("Expected structured content to be dropped")
+		}
+		text := result.Content[0].(*mcp.TextContent).Text
+		if len(text) >= len(`{"title": "A fairly long issue title"}`) {
+			t.Errorf("Expected truncated text, got %q", text)
+		}
+	})
+
+	t.Run("delays", func(t *testing.T) {
+		injector := Wrap(&stubProxy{}, &Config{DelayRate: 1, MaxDelay: time.Minute, Seed: 1})
+		var slept time.Duration
+		injector.sleep = func(d time.Duration) { slept = d }
+		if _, err := injector.CallTool("github", "get_issue", nil); err != nil {
+			t.Fatalf("CallTool() error = %v", err)
+		}
+		if slept <= 0 || slept >= time.Minute {
+			t.Errorf("Expected delay within (0, 1m), got %v", slept)
+		}
+	})
+
+	t.Run("passes through tools", func(t *testing.T) {
+		injector := Wrap(&stubProxy{}, &Config{ErrorRate: 1, Seed: 1})
+		if len(injector.GetAllTools()["github"]) != 1 {
+			t.Error("Expected GetAllTools to pass through")
+		}
+	})
+}
+
+func TestWrapFromEnv(t *testing.T) {
+	stub := &stubProxy{}
+
+	t.Setenv(EnvVar, "")
+	if wrapped, err := WrapFromEnv(stub); err != nil || wrapped != stub {
+		t.Errorf("Expected proxy manager unchanged when disabled, got %T, %v", wrapped, err)
+	}
+
+	t.Setenv(EnvVar, "error=1")
+	wrapped, err := WrapFromEnv(stub)
+	if err != nil {
+		t.Fatalf("WrapFromEnv() error = %v", err)
+	}
+	if _, ok := wrapped.(*Injector); !ok {
+		t.Errorf("Expected Injector, got %T", wrapped)
+	}
+
+	t.Setenv(EnvVar, "error=2")
+	if _, err := WrapFromEnv(stub); err == nil {
+		t.Error("Expected error for invalid setting")
+	}
+}
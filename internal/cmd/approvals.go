@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/dslh/mcp-metatool/internal/approval"
+)
+
+// Approvals lists, approves, or denies calls waiting in the approval queue
+func Approvals(args []string) error {
+	if len(args) == 0 || args[0] == "list" {
+		return listApprovals()
+	}
+
+	if len(args) != 2 {
+		return fmt.Errorf("usage: mcp-metatool approvals [list | approve <id> | deny <id>]")
+	}
+
+	switch args[0] {
+	case "approve":
+		return approveCall(args[1])
+	case "deny":
+		return denyCall(args[1])
+	default:
+		return fmt.Errorf("unknown approvals command '%s'", args[0])
+	}
+}
+
+// listApprovals prints every pending call
+func listApprovals() error {
+	calls, err := approval.List()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(colorize("Pending Approvals:", colorCyan))
+	if len(calls) == 0 {
+		fmt.Println("  (none)")
+		return nil
+	}
+
+	for _, call := range calls {
+		fmt.Printf("  • %s - %s.%s (requested %s)\n",
+			colorize(call.ID, colorBoldWhite), call.Server, call.Tool, call.RequestedAt.Format("2006-01-02 15:04:05"))
+		if len(call.Arguments) > 0 {
+			fmt.Printf("      arguments: %v\n", call.Arguments)
+		}
+	}
+	return nil
+}
+
+// approveCall executes a pending call against its upstream server
+func approveCall(id string) error {
+	call, err := approval.Load(id)
+	if err != nil {
+		return err
+	}
+
+	_, proxyManager, err := startProxyManager()
+	if err != nil {
+		return err
+	}
+	defer proxyManager.Stop()
+
+	// Remove before executing so a call can never run twice
+	if err := approval.Remove(call.ID); err != nil {
+		return err
+	}
+
+	result, err := proxyManager.CallTool(call.Server, call.Tool, call.Arguments)
+	if err != nil {
+		return fmt.Errorf("approved call %s failed: %w", call.ID, err)
+	}
+
+	printCallResult(result)
+	return nil
+}
+
+// denyCall discards a pending call
+func denyCall(id string) error {
+	call, err := approval.Load(id)
+	if err != nil {
+		return err
+	}
+
+	if err := approval.Remove(call.ID); err != nil {
+		return err
+	}
+
+	fmt.Printf("Call %s to %s.%s denied\n", call.ID, call.Server, call.Tool)
+	return nil
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/approval"
+)
+
+func TestApprovals_ListAndDeny(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	if err := Approvals(nil); err != nil {
+		t.Errorf("Listing empty approvals should not fail: %v", err)
+	}
+
+	call, err := approval.Enqueue("github", "delete_repo", nil)
+	if err != nil {
+		t.Fatalf("Enqueue() error = %v", err)
+	}
+
+	if err := Approvals([]string{"deny", call.ID}); err != nil {
+		t.Fatalf("Deny should succeed: %v", err)
+	}
+
+	calls, _ := approval.List()
+	if len(calls) != 0 {
+		t.Errorf("Expected no pending calls after deny, got %d", len(calls))
+	}
+}
+
+func TestApprovals_InvalidUsage(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	if err := Approvals([]string{"approve"}); err == nil {
+		t.Error("Expected usage error when id is missing")
+	}
+
+	if err := Approvals([]string{"frobnicate", "abc123"}); err == nil {
+		t.Error("Expected error for unknown command")
+	}
+
+	if err := Approvals([]string{"deny", "abc123"}); err == nil {
+		t.Error("Expected error for unknown id")
+	}
+}
+
+func TestRun_ApprovalsCommand(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	if exitCode := Run([]string{"approvals"}); exitCode != 0 {
+		t.Errorf("Run should succeed for 'approvals', got %d", exitCode)
+	}
+}
@@ -0,0 +1,118 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+)
+
+// Run is the entry point for CLI subcommands
+// It returns the process exit code, or -1 if args don't name a subcommand
+func Run(args []string) int {
+	if len(args) == 0 {
+		return -1
+	}
+
+	var err error
+	switch args[0] {
+	case "list":
+		err = ListTools(args[1:])
+	case "approvals":
+		err = Approvals(args[1:])
+	case "secrets":
+		err = Secrets(args[1:])
+	case "test":
+		err = TestTools(args[1:])
+	case "run":
+		err = RunTool(args[1:])
+	case "delete":
+		err = DeleteSavedTools(args[1:])
+	case "prune":
+		err = Prune(args[1:])
+	case "grant":
+		err = Grant(args[1:])
+	case "eval":
+		err = Eval(args[1:])
+	case "completion-data", "completion_data":
+		err = CompletionData()
+	case "validate":
+		err = ValidateConfig(args[1:])
+	case "init":
+		err = Init(args[1:])
+	case "import-config", "import_config":
+		err = ImportConfig(args[1:])
+	case "context-cost", "context_cost":
+		err = ContextCost(args[1:])
+	case "verify", "--verify":
+		err = Verify(args[1:])
+	case "stats":
+		err = Stats(args[1:])
+	case "sync":
+		err = Sync(args[1:])
+	case "install":
+		err = Install(args[1:])
+	default:
+		return -1 // Not a subcommand
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		var exitErr *ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.Code
+		}
+		return 1
+	}
+	return 0
+}
+
+// ExitError is returned by subcommands that need a specific process exit code
+type ExitError struct {
+	Code int
+	Err  error
+}
+
+// Error implements the error interface
+func (e *ExitError) Error() string {
+	return e.Err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+// startProxyManager loads the default config and connects to its servers quietly
+func startProxyManager() (*config.Config, *proxy.Manager, error) {
+	cfg, err := config.LoadDefaultConfig()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	proxyManager := proxy.NewManager(cfg, proxy.WithQuietMode())
+	if err := proxyManager.Start(); err != nil {
+		return nil, nil, fmt.Errorf("failed to start proxy manager: %w", err)
+	}
+
+	return cfg, proxyManager, nil
+}
+
+// printCallResult prints the text content of a tool call result
+func printCallResult(result *mcp.CallToolResult) {
+	for _, content := range result.Content {
+		if textContent, ok := content.(*mcp.TextContent); ok {
+			fmt.Println(textContent.Text)
+		} else {
+			fmt.Printf("%v\n", content)
+		}
+	}
+}
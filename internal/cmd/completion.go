@@ -0,0 +1,23 @@
+package cmd
+
+import (
+	"github.com/dslh/mcp-metatool/internal/completion"
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+)
+
+// CompletionData prints a JSON bundle of namespaces, tool signatures, modules and snippets for editor plugins
+func CompletionData() error {
+	var proxyManager starlark.ProxyManager
+	var opts []starlark.Option
+	cfg, manager, err := startProxyManager()
+	if err != nil {
+		logging.Warnf("Completion data will not include server namespaces: %v", err)
+	} else {
+		defer manager.Stop()
+		proxyManager = manager
+		opts = starlarkOptions(cfg)
+	}
+
+	return printJSON(completion.Build(proxyManager, opts...))
+}
@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/completion"
+)
+
+func TestCompletionData(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	var err error
+	output := captureStdout(t, func() {
+		err = CompletionData()
+	})
+	if err != nil {
+		t.Fatalf("CompletionData() error = %v", err)
+	}
+
+	var bundle completion.Bundle
+	if err := json.Unmarshal([]byte(output), &bundle); err != nil {
+		t.Fatalf("Expected JSON output, got %q: %v", output, err)
+	}
+	if len(bundle.Modules) == 0 || len(bundle.Snippets) == 0 {
+		t.Errorf("Expected modules and snippets in bundle, got %+v", bundle)
+	}
+}
@@ -0,0 +1,53 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/tools"
+)
+
+// ContextCost estimates how many tokens the tools the metatool would advertise consume
+func ContextCost(args []string) error {
+	flags := flag.NewFlagSet("context-cost", flag.ContinueOnError)
+	jsonOutput := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return fmt.Errorf("usage: mcp-metatool context-cost [--json]")
+	}
+
+	// Register tools the same way the server does, so overrides, filters and limits are reflected
+	server := mcp.NewServer(&mcp.Implementation{Name: "mcp-metatool", Version: "0.1.0"}, nil)
+	var deps tools.Dependencies
+	cfg, manager, err := startProxyManager()
+	if err != nil {
+		logging.Warnf("Estimate will not include proxied tools: %v", err)
+	} else {
+		defer manager.Stop()
+		tools.ConfigureBuiltinTools(cfg.BuiltinTools)
+		tools.ConfigureToolLimits(cfg.ToolLimits)
+		deps.Upstream, deps.GatedUpstream = manager, manager
+		if err := tools.RegisterProxiedTools(server, manager, cfg); err != nil {
+			logging.Warnf("Failed to register proxied tools: %v", err)
+		}
+	}
+	tools.RegisterBuiltinTools(server, deps)
+	if err := tools.RegisterSavedTools(server, deps.GatedUpstream); err != nil {
+		logging.Warnf("Failed to load saved tools: %v", err)
+	}
+
+	advertised, err := tools.AdvertisedTools(context.Background(), server)
+	if err != nil {
+		return fmt.Errorf("failed to list tools: %w", err)
+	}
+
+	report := tools.EstimateContextCost(advertised)
+	if *jsonOutput {
+		return printJSON(report)
+	}
+	fmt.Println(report)
+	return nil
+}
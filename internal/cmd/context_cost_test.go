@@ -0,0 +1,50 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/tools"
+)
+
+func TestContextCost(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	persistence.SaveTool(&persistence.SavedToolDefinition{
+		Name:        "greet",
+		Description: "Greet someone by name",
+		Code:        `"Hello, " + params["name"]`,
+	})
+
+	var err error
+	output := captureStdout(t, func() {
+		err = ContextCost([]string{"--json"})
+	})
+	if err != nil {
+		t.Fatalf("ContextCost() error = %v", err)
+	}
+
+	var report tools.ContextCostReport
+	if err := json.Unmarshal([]byte(output), &report); err != nil {
+		t.Fatalf("Expected JSON report, got %q: %v", output, err)
+	}
+	groups := make(map[string]tools.ContextCostGroup)
+	for _, group := range report.Groups {
+		groups[group.Name] = group
+	}
+	if groups["saved"].Tools != 1 || groups["built-in"].Tools == 0 {
+		t.Errorf("Expected saved and built-in groups, got %+v", report.Groups)
+	}
+	if report.Tokens == 0 {
+		t.Error("Expected a non-zero token estimate")
+	}
+
+	output = captureStdout(t, func() {
+		err = ContextCost(nil)
+	})
+	if err != nil || !strings.Contains(output, "• saved: 1 tool(s)") {
+		t.Errorf("Expected text report, got %q, %v", output, err)
+	}
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+const evalUsage = "usage: mcp-metatool eval [-c CODE | FILE | -] [--params JSON]"
+
+// Eval executes Starlark code from an argument, a file, or stdin and prints the JSON result
+func Eval(args []string) error {
+	flags := flag.NewFlagSet("eval", flag.ContinueOnError)
+	codeFlag := flags.String("c", "", "Starlark code to execute")
+	paramsFlag := flags.String("params", "", "parameters available as params, as a JSON object")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf(evalUsage)
+	}
+
+	// stdin can be read only once, for either the code or the params
+	codeFromStdin := *codeFlag == "" && (flags.NArg() == 0 || flags.Arg(0) == "-")
+	if codeFromStdin && *paramsFlag == "-" {
+		return fmt.Errorf("--params - can't be used when the code is read from stdin")
+	}
+
+	code, err := readCode(*codeFlag, flags.Args(), os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	params, err := readParams(*paramsFlag, os.Stdin)
+	if err != nil {
+		return err
+	}
+
+	return executeAndPrint(code, params, nil)
+}
+
+// readCode returns the code given with -c, or the contents of the named file, or stdin
+func readCode(code string, files []string, stdin io.Reader) (string, error) {
+	if len(files) > 1 || (code != "" && len(files) > 0) {
+		return "", fmt.Errorf(evalUsage)
+	}
+
+	if code != "" {
+		return code, nil
+	}
+
+	if len(files) == 0 || files[0] == "-" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read code from stdin: %w", err)
+		}
+		return string(data), nil
+	}
+
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		return "", fmt.Errorf("failed to read code: %w", err)
+	}
+	return string(data), nil
+}
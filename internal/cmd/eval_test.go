@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestEval(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	var err error
+	output := captureStdout(t, func() {
+		err = Eval([]string{"-c", `params["a"] + params["b"]`, "--params", `{"a": 2, "b": 3}`})
+	})
+	if err != nil {
+		t.Fatalf("Eval() error = %v", err)
+	}
+	if strings.TrimSpace(output) != "5" {
+		t.Errorf("Expected 5, got %q", output)
+	}
+
+	script := filepath.Join(t.TempDir(), "script.star")
+	os.WriteFile(script, []byte("items = [1, 2, 3]\nresult = {\"total\": len(items)}\n"), 0644)
+
+	output = captureStdout(t, func() {
+		err = Eval([]string{script})
+	})
+	if err != nil {
+		t.Fatalf("Eval() from file error = %v", err)
+	}
+	if !strings.Contains(output, `"total": 3`) {
+		t.Errorf("Expected JSON result from file, got %q", output)
+	}
+
+	if err := Eval([]string{"-c", "undefined_name"}); err == nil {
+		t.Error("Expected error for failing code")
+	}
+	if err := Eval([]string{"a.star", "b.star"}); err == nil || !strings.Contains(err.Error(), "usage") {
+		t.Errorf("Expected usage error, got %v", err)
+	}
+}
+
+func TestReadCode(t *testing.T) {
+	code, err := readCode("", nil, strings.NewReader("1 + 1"))
+	if err != nil || code != "1 + 1" {
+		t.Errorf("Expected code from stdin, got %q, %v", code, err)
+	}
+
+	code, err = readCode("", []string{"-"}, strings.NewReader("2 + 2"))
+	if err != nil || code != "2 + 2" {
+		t.Errorf("Expected code from stdin with -, got %q, %v", code, err)
+	}
+
+	if _, err := readCode("1", []string{"file.star"}, nil); err == nil {
+		t.Error("Expected error when both -c and a file are given")
+	}
+
+	if _, err := readCode("", []string{"/nonexistent/file.star"}, nil); err == nil {
+		t.Error("Expected error for missing file")
+	}
+}
+
+// withStdin replaces os.Stdin with the given input while fn runs
+func withStdin(t *testing.T, input string, fn func()) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "stdin")
+	if err := os.WriteFile(path, []byte(input), 0644); err != nil {
+		t.Fatalf("Failed to write stdin: %v", err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatalf("Failed to open stdin: %v", err)
+	}
+	defer file.Close()
+
+	original := os.Stdin
+	os.Stdin = file
+	defer func() { os.Stdin = original }()
+	fn()
+}
+
+func TestEval_ParamsFromStdin(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	var err error
+	output := captureStdout(t, func() {
+		withStdin(t, `{"a": 4, "b": 5}`, func() {
+			err = Eval([]string{"-c", `params["a"] * params["b"]`, "--params", "-"})
+		})
+	})
+	if err != nil {
+		t.Fatalf("Eval() error = %v", err)
+	}
+	if strings.TrimSpace(output) != "20" {
+		t.Errorf("Expected 20, got %q", output)
+	}
+
+	withStdin(t, "1", func() {
+		err = Eval([]string{"--params", "-"})
+	})
+	if err == nil || !strings.Contains(err.Error(), "stdin") {
+		t.Errorf("Expected an error reading both code and params from stdin, got %v", err)
+	}
+}
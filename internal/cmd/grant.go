@@ -0,0 +1,64 @@
+package cmd
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+)
+
+const grantUsage = "usage: mcp-metatool grant <tool> [permission...] [--revoke]"
+
+// Grant adds permissions to a saved tool, or removes them with --revoke. Agents can't grant
+// permissions themselves, so this is how an operator lets a tool use restricted modules.
+// Without permissions it prints the tool's current ones.
+func Grant(args []string) error {
+	var positional []string
+	revoke := false
+	for _, arg := range args {
+		switch arg {
+		case "--revoke", "-revoke":
+			revoke = true
+		default:
+			positional = append(positional, arg)
+		}
+	}
+	if len(positional) == 0 {
+		return fmt.Errorf(grantUsage)
+	}
+
+	tool, err := persistence.LoadTool(positional[0])
+	if err != nil {
+		return err
+	}
+	permissions := positional[1:]
+	if len(permissions) == 0 {
+		printPermissions(tool)
+		return nil
+	}
+
+	for _, permission := range permissions {
+		has := slices.Contains(tool.Permissions, permission)
+		switch {
+		case revoke && has:
+			tool.Permissions = slices.DeleteFunc(tool.Permissions, func(p string) bool { return p == permission })
+		case !revoke && !has:
+			tool.Permissions = append(tool.Permissions, permission)
+		}
+	}
+	if err := persistence.SaveTool(tool); err != nil {
+		return err
+	}
+	printPermissions(tool)
+	return nil
+}
+
+// printPermissions lists the permissions granted to a tool
+func printPermissions(tool *persistence.SavedToolDefinition) {
+	if len(tool.Permissions) == 0 {
+		fmt.Printf("%s has no permissions\n", tool.Name)
+		return
+	}
+	fmt.Printf("%s: %s\n", tool.Name, strings.Join(tool.Permissions, ", "))
+}
@@ -0,0 +1,71 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+)
+
+func TestGrant(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "alert", Description: "Alert", Code: "1"})
+
+	tests := []struct {
+		name    string
+		args    []string
+		want    []string
+		wantErr bool
+	}{
+		{"grant", []string{"alert", "notify", "fs:read"}, []string{"notify", "fs:read"}, false},
+		{"grant again", []string{"alert", "notify"}, []string{"notify", "fs:read"}, false},
+		{"revoke", []string{"alert", "fs:read", "--revoke"}, []string{"notify"}, false},
+		{"invalid permission", []string{"alert", "not a module"}, []string{"notify"}, true},
+		{"missing tool", []string{"missing", "notify"}, []string{"notify"}, true},
+		{"no tool", nil, []string{"notify"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var err error
+			captureStdout(t, func() { err = Grant(tt.args) })
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Grant() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tool, _ := persistence.LoadTool("alert"); !reflect.DeepEqual(tool.Permissions, tt.want) {
+				t.Errorf("Permissions = %v, want %v", tool.Permissions, tt.want)
+			}
+		})
+	}
+
+	output := captureStdout(t, func() { Grant([]string{"alert"}) })
+	if strings.TrimSpace(output) != "alert: notify" {
+		t.Errorf("Expected the current permissions, got %q", output)
+	}
+}
+
+func TestGrantRevokeSurvivesRollback(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "alert", Description: "Alert", Code: "1"})
+
+	captureStdout(t, func() { Grant([]string{"alert", "secrets"}) })
+	tool, _ := persistence.LoadTool("alert")
+	tool.Description = "Alert loudly"
+	if err := persistence.SaveTool(tool); err != nil {
+		t.Fatalf("SaveTool() error = %v", err)
+	}
+	captureStdout(t, func() { Grant([]string{"alert", "secrets", "--revoke"}) })
+
+	// Every earlier version was granted the permission, but the operator has revoked it since
+	for _, undo := range []func() (*persistence.SavedToolDefinition, error){
+		func() (*persistence.SavedToolDefinition, error) { return persistence.RollbackTool("alert", 2) },
+		func() (*persistence.SavedToolDefinition, error) { return persistence.RestoreTool("alert") },
+	} {
+		if _, err := undo(); err != nil {
+			t.Fatalf("undo error = %v", err)
+		}
+		if tool, _ := persistence.LoadTool("alert"); len(tool.Permissions) != 0 {
+			t.Errorf("Expected the revoked permission to stay revoked, got %v", tool.Permissions)
+		}
+	}
+}
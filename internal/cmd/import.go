@@ -0,0 +1,289 @@
+package cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/paths"
+)
+
+const importUsage = "usage: mcp-metatool import-config --from claude-desktop|cursor|cline [--file PATH] [--overwrite] [--disable] [--dry-run]"
+
+// metatoolServerName is the client entry that points at the metatool once servers are disabled
+const metatoolServerName = "mcp-metatool"
+
+// mcpClient describes where an MCP client keeps its server config
+type mcpClient struct {
+	// configPaths returns candidate config file locations, most likely first
+	configPaths func(home, appData string) []string
+	// hasDisabledFlag means servers can be switched off in place rather than removed
+	hasDisabledFlag bool
+}
+
+var mcpClients = map[string]mcpClient{
+	"claude-desktop": {
+		configPaths: func(home, appData string) []string {
+			switch runtime.GOOS {
+			case "darwin":
+				return []string{filepath.Join(home, "Library", "Application Support", "Claude", "claude_desktop_config.json")}
+			case "windows":
+				return []string{filepath.Join(appData, "Claude", "claude_desktop_config.json")}
+			default:
+				return []string{filepath.Join(home, ".config", "Claude", "claude_desktop_config.json")}
+			}
+		},
+	},
+	"cursor": {
+		configPaths: func(home, appData string) []string {
+			return []string{filepath.Join(home, ".cursor", "mcp.json")}
+		},
+	},
+	"cline": {
+		configPaths: func(home, appData string) []string {
+			settings := filepath.Join("User", "globalStorage", "saoudrizwan.claude-dev", "settings", "cline_mcp_settings.json")
+			var roots []string
+			switch runtime.GOOS {
+			case "darwin":
+				roots = []string{filepath.Join(home, "Library", "Application Support")}
+			case "windows":
+				roots = []string{appData}
+			default:
+				roots = []string{filepath.Join(home, ".config")}
+			}
+			var candidates []string
+			for _, root := range roots {
+				for _, editor := range []string{"Code", "Code - Insiders", "VSCodium", "Cursor"} {
+					candidates = append(candidates, filepath.Join(root, editor, settings))
+				}
+			}
+			return candidates
+		},
+		hasDisabledFlag: true,
+	},
+}
+
+// clientServer is a server entry in a client config file
+type clientServer struct {
+	Command  string            `json:"command"`
+	Args     []string          `json:"args"`
+	Env      map[string]string `json:"env"`
+	URL      string            `json:"url"`
+	Disabled bool              `json:"disabled"`
+}
+
+// ImportConfig copies server definitions from another MCP client's config into servers.json
+func ImportConfig(args []string) error {
+	flags := flag.NewFlagSet("import-config", flag.ContinueOnError)
+	from := flags.String("from", "", "client to import from: claude-desktop, cursor or cline")
+	file := flags.String("file", "", "client config file, if not in the default location")
+	overwrite := flags.Bool("overwrite", false, "replace servers already in servers.json")
+	disable := flags.Bool("disable", false, "disable imported servers in the client and point it at the metatool")
+	dryRun := flags.Bool("dry-run", false, "show what would be imported without writing anything")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return fmt.Errorf(importUsage)
+	}
+
+	client, ok := mcpClients[*from]
+	if !ok {
+		return fmt.Errorf(importUsage)
+	}
+
+	clientPath := *file
+	if clientPath == "" {
+		found, err := findClientConfig(client)
+		if err != nil {
+			return fmt.Errorf("no %s config found: %w", *from, err)
+		}
+		clientPath = found
+	}
+
+	servers, err := readClientServers(clientPath)
+	if err != nil {
+		return err
+	}
+
+	configPath, err := paths.GetConfigPath()
+	if err != nil {
+		return err
+	}
+	cfg, err := config.LoadRawConfig(configPath)
+	if errors.Is(err, os.ErrNotExist) {
+		cfg = &config.Config{}
+	} else if err != nil {
+		return err
+	}
+	if cfg.MCPServers == nil {
+		cfg.MCPServers = make(map[string]config.MCPServerConfig)
+	}
+
+	names := make([]string, 0, len(servers))
+	for name := range servers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var imported []string
+	for _, name := range names {
+		server := servers[name]
+		target := importedServerName(name)
+		switch {
+		case name == metatoolServerName || filepath.Base(server.Command) == metatoolServerName:
+			continue
+		case server.Disabled:
+			fmt.Printf("Skipping %s: disabled in %s\n", name, *from)
+			continue
+		case server.Command == "":
+			fmt.Printf("Skipping %s: only servers launched by a command can be proxied\n", name)
+			continue
+		}
+		if _, exists := cfg.MCPServers[target]; exists && !*overwrite {
+			fmt.Printf("Skipping %s: already in servers.json (use --overwrite to replace it)\n", name)
+			continue
+		}
+
+		cfg.MCPServers[target] = config.MCPServerConfig{Command: server.Command, Args: server.Args, Env: server.Env}
+		imported = append(imported, name)
+		if target != name {
+			fmt.Printf("Importing %s as %s\n", name, target)
+		} else {
+			fmt.Printf("Importing %s\n", name)
+		}
+	}
+
+	if len(imported) == 0 {
+		fmt.Println("Nothing to import")
+		return nil
+	}
+	if *dryRun {
+		fmt.Printf("Dry run: %d server(s) would be imported into %s\n", len(imported), configPath)
+		return nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config after import: %w", err)
+	}
+	if err := config.WriteConfig(configPath, cfg); err != nil {
+		return err
+	}
+	fmt.Printf("Imported %d server(s) into %s\n", len(imported), configPath)
+
+	if *disable {
+		backupPath, err := disableClientServers(clientPath, imported, client.hasDisabledFlag)
+		if err != nil {
+			return fmt.Errorf("servers were imported, but disabling them in %s failed: %w", clientPath, err)
+		}
+		fmt.Printf("Disabled them in %s and added a %s entry (original saved as %s)\n", clientPath, metatoolServerName, backupPath)
+	}
+	return nil
+}
+
+// findClientConfig returns the first of a client's config locations that exists
+func findClientConfig(client mcpClient) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	candidates := client.configPaths(home, os.Getenv("APPDATA"))
+	for _, candidate := range candidates {
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate, nil
+		}
+	}
+	return "", fmt.Errorf("looked in %s; pass --file to choose one", strings.Join(candidates, ", "))
+}
+
+// readClientServers parses the mcpServers section of a client config file
+func readClientServers(path string) (map[string]clientServer, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read client config: %w", err)
+	}
+
+	var document struct {
+		MCPServers map[string]clientServer `json:"mcpServers"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return document.MCPServers, nil
+}
+
+// importedServerName turns a client server name into one usable as a Starlark namespace
+func importedServerName(name string) string {
+	var b strings.Builder
+	for i, r := range name {
+		switch {
+		case r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z'):
+			b.WriteRune(r)
+		case r >= '0' && r <= '9':
+			if i == 0 {
+				b.WriteRune('_')
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteRune('_')
+		}
+	}
+	sanitized := b.String()
+	for strings.Contains(sanitized, "__") {
+		sanitized = strings.ReplaceAll(sanitized, "__", "_")
+	}
+	return sanitized
+}
+
+// disableClientServers switches off the named servers in a client config, adds an entry
+// launching the metatool, and returns the path of the backup it made of the original
+func disableClientServers(path string, names []string, hasDisabledFlag bool) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	// Decode generically so settings the metatool doesn't know about are preserved
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return "", err
+	}
+	servers, ok := document["mcpServers"].(map[string]interface{})
+	if !ok {
+		return "", fmt.Errorf("mcpServers is not an object")
+	}
+
+	for _, name := range names {
+		if entry, ok := servers[name].(map[string]interface{}); ok && hasDisabledFlag {
+			entry["disabled"] = true
+		} else {
+			delete(servers, name)
+		}
+	}
+
+	if _, exists := servers[metatoolServerName]; !exists {
+		executable, err := os.Executable()
+		if err != nil {
+			executable = metatoolServerName
+		}
+		servers[metatoolServerName] = map[string]interface{}{"command": executable}
+	}
+
+	updated, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return "", err
+	}
+
+	backupPath := path + ".bak"
+	if err := os.WriteFile(backupPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to back up client config: %w", err)
+	}
+	if err := os.WriteFile(path, append(updated, '\n'), 0644); err != nil {
+		return "", err
+	}
+	return backupPath, nil
+}
@@ -0,0 +1,184 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+const clientConfig = `{
+  "globalShortcut": "Ctrl+Space",
+  "mcpServers": {
+    "github": {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-github"], "env": {"GITHUB_TOKEN": "abc"}},
+    "brave-search": {"command": "npx", "args": ["-y", "@modelcontextprotocol/server-brave-search"]},
+    "remote": {"url": "https://example.com/mcp"},
+    "old": {"command": "old-mcp", "disabled": true}
+  }
+}`
+
+// writeImportFixtures creates a client config and an existing servers.json, returning their paths
+func writeImportFixtures(t *testing.T) (string, string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+
+	clientPath := filepath.Join(dir, "client.json")
+	os.WriteFile(clientPath, []byte(clientConfig), 0644)
+	configPath := filepath.Join(dir, "servers.json")
+	os.WriteFile(configPath, []byte(`{"mcpServers": {"github": {"command": "gh-mcp", "env": {"TOKEN": "${GH_TOKEN}"}}}}`), 0644)
+	return clientPath, configPath
+}
+
+func TestImportConfig(t *testing.T) {
+	tests := []struct {
+		name        string
+		args        []string
+		wantServers map[string]string // server -> command
+		output      []string
+	}{
+		{
+			name:        "skips existing servers",
+			wantServers: map[string]string{"github": "gh-mcp", "brave_search": "npx"},
+			output:      []string{"Importing brave-search as brave_search", "github: already in servers.json", "remote: only servers launched by a command", "old: disabled"},
+		},
+		{
+			name:        "overwrite",
+			args:        []string{"--overwrite"},
+			wantServers: map[string]string{"github": "npx", "brave_search": "npx"},
+			output:      []string{"Imported 2 server(s)"},
+		},
+		{
+			name:        "dry run",
+			args:        []string{"--dry-run"},
+			wantServers: map[string]string{"github": "gh-mcp"},
+			output:      []string{"Dry run: 1 server(s) would be imported"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clientPath, configPath := writeImportFixtures(t)
+
+			var err error
+			output := captureStdout(t, func() {
+				err = ImportConfig(append([]string{"--from", "claude-desktop", "--file", clientPath}, tt.args...))
+			})
+			if err != nil {
+				t.Fatalf("ImportConfig() error = %v", err)
+			}
+			for _, want := range tt.output {
+				if !strings.Contains(output, want) {
+					t.Errorf("Expected output containing %q, got %q", want, output)
+				}
+			}
+
+			cfg, err := config.LoadRawConfig(configPath)
+			if err != nil {
+				t.Fatalf("LoadRawConfig() error = %v", err)
+			}
+			if len(cfg.MCPServers) != len(tt.wantServers) {
+				t.Errorf("Expected servers %v, got %+v", tt.wantServers, cfg.MCPServers)
+			}
+			for name, command := range tt.wantServers {
+				if cfg.MCPServers[name].Command != command {
+					t.Errorf("Expected %s command %q, got %q", name, command, cfg.MCPServers[name].Command)
+				}
+			}
+			if github := cfg.MCPServers["github"]; github.Command == "gh-mcp" && github.Env["TOKEN"] != "${GH_TOKEN}" {
+				t.Errorf("Expected existing variable references to be preserved, got %q", github.Env["TOKEN"])
+			}
+		})
+	}
+}
+
+func TestImportConfig_Disable(t *testing.T) {
+	for _, tt := range []struct {
+		from         string
+		wantDisabled bool
+	}{
+		{"cursor", false},
+		{"cline", true},
+	} {
+		t.Run(tt.from, func(t *testing.T) {
+			clientPath, _ := writeImportFixtures(t)
+
+			var err error
+			captureStdout(t, func() {
+				err = ImportConfig([]string{"--from", tt.from, "--file", clientPath, "--overwrite", "--disable"})
+			})
+			if err != nil {
+				t.Fatalf("ImportConfig() error = %v", err)
+			}
+
+			backup, err := os.ReadFile(clientPath + ".bak")
+			if err != nil || string(backup) != clientConfig {
+				t.Errorf("Expected original client config to be backed up, got %q, %v", backup, err)
+			}
+
+			data, _ := os.ReadFile(clientPath)
+			var document map[string]interface{}
+			if err := json.Unmarshal(data, &document); err != nil {
+				t.Fatalf("Failed to parse rewritten client config: %v", err)
+			}
+			if document["globalShortcut"] != "Ctrl+Space" {
+				t.Error("Expected unrelated client settings to be preserved")
+			}
+
+			servers := document["mcpServers"].(map[string]interface{})
+			if _, ok := servers[metatoolServerName]; !ok {
+				t.Errorf("Expected a %s entry, got %v", metatoolServerName, servers)
+			}
+			if _, ok := servers["remote"]; !ok {
+				t.Error("Expected servers that weren't imported to be left alone")
+			}
+			github, present := servers["github"].(map[string]interface{})
+			if present != tt.wantDisabled {
+				t.Fatalf("Expected github present = %v, got %v", tt.wantDisabled, servers["github"])
+			}
+			if present && github["disabled"] != true {
+				t.Errorf("Expected github to be disabled, got %v", github)
+			}
+		})
+	}
+}
+
+func TestImportConfig_Errors(t *testing.T) {
+	clientPath, _ := writeImportFixtures(t)
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"missing client", nil, "usage"},
+		{"unknown client", []string{"--from", "emacs"}, "usage"},
+		{"missing file", []string{"--from", "cursor", "--file", clientPath + ".missing"}, "failed to read client config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ImportConfig(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestImportedServerName(t *testing.T) {
+	tests := map[string]string{
+		"github":         "github",
+		"brave-search":   "brave_search",
+		"my.server--two": "my_server_two",
+		"1password":      "_1password",
+	}
+	for name, want := range tests {
+		if got := importedServerName(name); got != want {
+			t.Errorf("importedServerName(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,250 @@
+package cmd
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+)
+
+const initUsage = "usage: mcp-metatool init [--force] [--no-check]"
+
+// initProbeTimeout bounds how long init waits for a new server to list its tools
+const initProbeTimeout = 30 * time.Second
+
+// errInputEnded is returned when stdin closes before setup is finished
+var errInputEnded = errors.New("input ended before setup was complete")
+
+// Init interactively creates servers.json, checking that each server can be reached
+func Init(args []string) error {
+	return runInit(args, os.Stdin)
+}
+
+// runInit implements Init, reading answers from input
+func runInit(args []string, input io.Reader) error {
+	flags := flag.NewFlagSet("init", flag.ContinueOnError)
+	force := flags.Bool("force", false, "replace an existing servers.json")
+	noCheck := flags.Bool("no-check", false, "don't try connecting to servers before saving them")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return fmt.Errorf(initUsage)
+	}
+
+	configPath, err := paths.GetConfigPath()
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(configPath); err == nil && !*force {
+		return fmt.Errorf("%s already exists; use --force to replace it", configPath)
+	}
+
+	fmt.Printf("Setting up %s\n", configPath)
+	fmt.Println("Values may reference environment variables as ${VAR}, which are expanded when the config is loaded.")
+
+	p := &prompter{scanner: bufio.NewScanner(input)}
+	cfg := &config.Config{MCPServers: make(map[string]config.MCPServerConfig)}
+	for {
+		fmt.Println()
+		name, serverConfig, err := promptServer(p, cfg)
+		if err != nil {
+			return err
+		}
+
+		keep := true
+		if !*noCheck {
+			keep, err = checkNewServer(p, name, serverConfig)
+			if err != nil {
+				return err
+			}
+		}
+		if keep {
+			cfg.MCPServers[name] = serverConfig
+		}
+
+		another, err := p.confirm("Add another server?")
+		if err != nil {
+			return err
+		}
+		if !another {
+			break
+		}
+	}
+
+	if len(cfg.MCPServers) == 0 {
+		return fmt.Errorf("no servers configured; %s was not written", configPath)
+	}
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("invalid config: %w", err)
+	}
+	if err := config.WriteConfig(configPath, cfg); err != nil {
+		return err
+	}
+
+	fmt.Printf("\nWrote %d server(s) to %s\n", len(cfg.MCPServers), configPath)
+	fmt.Println("Run `mcp-metatool list` to see the available tools.")
+	return nil
+}
+
+// promptServer asks for the name, command, arguments and environment of one server
+func promptServer(p *prompter, cfg *config.Config) (string, config.MCPServerConfig, error) {
+	var serverConfig config.MCPServerConfig
+
+	var name string
+	for name == "" {
+		answer, err := p.ask("Server name (e.g. github)")
+		if err != nil {
+			return "", serverConfig, err
+		}
+		switch {
+		case answer == "":
+		case strings.ContainsAny(answer, " \t"):
+			fmt.Println("Server names cannot contain spaces.")
+		case cfg.MCPServers[answer].Command != "":
+			fmt.Printf("A server named %s has already been added.\n", answer)
+		default:
+			name = answer
+		}
+	}
+
+	for serverConfig.Command == "" {
+		answer, err := p.ask("Command (e.g. npx)")
+		if err != nil {
+			return "", serverConfig, err
+		}
+		serverConfig.Command = answer
+	}
+
+	answer, err := p.ask("Arguments (space separated, quote arguments containing spaces)")
+	if err != nil {
+		return "", serverConfig, err
+	}
+	if serverConfig.Args, err = splitArgs(answer); err != nil {
+		return "", serverConfig, err
+	}
+
+	fmt.Println("Environment variables as KEY=VALUE, one per line; leave blank to finish.")
+	for {
+		answer, err := p.ask("  env")
+		if err != nil {
+			return "", serverConfig, err
+		}
+		if answer == "" {
+			break
+		}
+		key, value, ok := strings.Cut(answer, "=")
+		if !ok || strings.TrimSpace(key) == "" {
+			fmt.Println("  Expected KEY=VALUE.")
+			continue
+		}
+		if serverConfig.Env == nil {
+			serverConfig.Env = make(map[string]string)
+		}
+		serverConfig.Env[strings.TrimSpace(key)] = value
+	}
+
+	return name, serverConfig, nil
+}
+
+// checkNewServer tries connecting to a server and reports whether it should be saved
+func checkNewServer(p *prompter, name string, serverConfig config.MCPServerConfig) (bool, error) {
+	fmt.Printf("Connecting to %s... ", name)
+
+	// Expand variable references the same way loading the config will
+	expanded := serverConfig
+	expanded.Command = os.ExpandEnv(serverConfig.Command)
+	expanded.Args = make([]string, len(serverConfig.Args))
+	for i, arg := range serverConfig.Args {
+		expanded.Args[i] = os.ExpandEnv(arg)
+	}
+	expanded.Env = make(map[string]string, len(serverConfig.Env))
+	for key, value := range serverConfig.Env {
+		expanded.Env[key] = os.ExpandEnv(value)
+	}
+
+	tools, err := proxy.Probe(name, expanded, initProbeTimeout)
+	if err == nil {
+		fmt.Printf("%s, found %d tools\n", colorize("ok", colorGreen), len(tools))
+		return true, nil
+	}
+
+	fmt.Printf("%s: %v\n", colorize("failed", colorRed), err)
+	return p.confirm("Save it anyway?")
+}
+
+// splitArgs splits a line into arguments on whitespace, honoring single and double quotes
+func splitArgs(line string) ([]string, error) {
+	var args []string
+	var current strings.Builder
+	inArg := false
+	var quote rune
+
+	for _, r := range line {
+		switch {
+		case quote != 0 && r == quote:
+			quote = 0
+		case quote != 0:
+			current.WriteRune(r)
+		case r == '"' || r == '\'':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated %c quote in arguments", quote)
+	}
+	if inArg {
+		args = append(args, current.String())
+	}
+	return args, nil
+}
+
+// prompter reads answers to questions one line at a time
+type prompter struct {
+	scanner *bufio.Scanner
+}
+
+// ask prints a question and returns the trimmed answer
+func (p *prompter) ask(question string) (string, error) {
+	fmt.Printf("%s: ", question)
+	if !p.scanner.Scan() {
+		fmt.Println()
+		if err := p.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", errInputEnded
+	}
+	return strings.TrimSpace(p.scanner.Text()), nil
+}
+
+// confirm asks a yes/no question, defaulting to no
+func (p *prompter) confirm(question string) (bool, error) {
+	for {
+		answer, err := p.ask(question + " [y/N]")
+		if err != nil {
+			return false, err
+		}
+		switch strings.ToLower(answer) {
+		case "y", "yes":
+			return true, nil
+		case "", "n", "no":
+			return false, nil
+		}
+	}
+}
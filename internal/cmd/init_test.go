@@ -0,0 +1,164 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+func TestInit(t *testing.T) {
+	tests := []struct {
+		name    string
+		args    []string
+		input   string
+		wantErr string
+		servers map[string]config.MCPServerConfig
+	}{
+		{
+			name:  "single server",
+			args:  []string{"--no-check"},
+			input: "github\nnpx\n-y @modelcontextprotocol/server-github\nGITHUB_TOKEN=${GITHUB_TOKEN}\n\nn\n",
+			servers: map[string]config.MCPServerConfig{
+				"github": {Command: "npx", Args: []string{"-y", "@modelcontextprotocol/server-github"}, Env: map[string]string{"GITHUB_TOKEN": "${GITHUB_TOKEN}"}},
+			},
+		},
+		{
+			name:  "reprompts for missing and duplicate answers",
+			args:  []string{"--no-check"},
+			input: "\nfs\n\nmcp-fs\n'/tmp/my dir'\n\ny\nfs\nother\nnotes\n\n\nbad line\n\nno\n",
+			servers: map[string]config.MCPServerConfig{
+				"fs":    {Command: "mcp-fs", Args: []string{"/tmp/my dir"}},
+				"other": {Command: "notes"},
+			},
+		},
+		{
+			name:  "unreachable server kept on request",
+			input: "broken\nsh\n-c 'exit 1'\n\ny\nn\n",
+			servers: map[string]config.MCPServerConfig{
+				"broken": {Command: "sh", Args: []string{"-c", "exit 1"}},
+			},
+		},
+		{
+			name:    "unreachable server dropped",
+			input:   "broken\nsh\n-c 'exit 1'\n\nn\nn\n",
+			wantErr: "no servers configured",
+		},
+		{
+			name:    "input ends early",
+			args:    []string{"--no-check"},
+			input:   "github\n",
+			wantErr: "input ended",
+		},
+		{
+			name:    "usage",
+			args:    []string{"extra"},
+			wantErr: "usage",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			t.Setenv("MCP_METATOOL_DIR", dir)
+
+			var err error
+			captureStdout(t, func() {
+				err = runInit(tt.args, strings.NewReader(tt.input))
+			})
+
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("Expected error containing %q, got %v", tt.wantErr, err)
+				}
+				if _, statErr := os.Stat(filepath.Join(dir, "servers.json")); statErr == nil {
+					t.Error("Expected servers.json not to be written")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("runInit() error = %v", err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, "servers.json"))
+			if err != nil {
+				t.Fatalf("Failed to read servers.json: %v", err)
+			}
+			if !strings.Contains(string(data), "\n  ") {
+				t.Errorf("Expected indented JSON, got %s", data)
+			}
+
+			// Decode the raw file, since loading would expand variable references
+			var cfg config.Config
+			if err := json.Unmarshal(data, &cfg); err != nil {
+				t.Fatalf("Failed to parse servers.json: %v", err)
+			}
+			for name, want := range tt.servers {
+				got := cfg.MCPServers[name]
+				if got.Command != want.Command || !reflect.DeepEqual(got.Args, want.Args) || !reflect.DeepEqual(got.Env, want.Env) {
+					t.Errorf("Server %s = %+v, want %+v", name, got, want)
+				}
+			}
+			if len(cfg.MCPServers) != len(tt.servers) {
+				t.Errorf("Expected %d servers, got %d", len(tt.servers), len(cfg.MCPServers))
+			}
+		})
+	}
+}
+
+func TestInit_ExistingConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+	configPath := filepath.Join(dir, "servers.json")
+	os.WriteFile(configPath, []byte(`{"mcpServers": {"old": {"command": "echo"}}}`), 0644)
+
+	input := "new\ncat\n\n\nn\n"
+	if err := runInit([]string{"--no-check"}, strings.NewReader(input)); err == nil || !strings.Contains(err.Error(), "--force") {
+		t.Fatalf("Expected error suggesting --force, got %v", err)
+	}
+
+	var err error
+	captureStdout(t, func() {
+		err = runInit([]string{"--force", "--no-check"}, strings.NewReader(input))
+	})
+	if err != nil {
+		t.Fatalf("runInit(--force) error = %v", err)
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if _, ok := cfg.MCPServers["new"]; !ok || len(cfg.MCPServers) != 1 {
+		t.Errorf("Expected config to be replaced, got %+v", cfg.MCPServers)
+	}
+}
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		line    string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{"-y  pkg", []string{"-y", "pkg"}, false},
+		{`--root "/tmp/my dir"`, []string{"--root", "/tmp/my dir"}, false},
+		{`--msg 'it''s' ""`, []string{"--msg", "its", ""}, false},
+		{`"unterminated`, nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.line, func(t *testing.T) {
+			got, err := splitArgs(tt.line)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("splitArgs(%q) error = %v, wantErr %v", tt.line, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("splitArgs(%q) = %q, want %q", tt.line, got, tt.want)
+			}
+		})
+	}
+}
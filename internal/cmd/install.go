@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/dslh/mcp-metatool/internal/packs"
+)
+
+const installUsage = "usage: mcp-metatool install <url|path> [--sha256 HEX] [--public-key KEY [--signature URL]] [--insecure] [--overwrite] [--dry-run]"
+
+// Install fetches a pack of saved tools, verifies it and installs its tools
+func Install(args []string) error {
+	flags := flag.NewFlagSet("install", flag.ContinueOnError)
+	var verification packs.Verification
+	flags.StringVar(&verification.SHA256, "sha256", "", "hex-encoded SHA-256 checksum the pack must have")
+	flags.StringVar(&verification.PublicKey, "public-key", "", "base64-encoded Ed25519 key the pack must be signed with")
+	flags.StringVar(&verification.Signature, "signature", "", "location of the pack's signature (default: the pack's location plus .sig)")
+	flags.BoolVar(&verification.Insecure, "insecure", false, "install without verifying the pack")
+	overwrite := flags.Bool("overwrite", false, "replace saved tools with the same names")
+	dryRun := flags.Bool("dry-run", false, "show what would be installed without installing it")
+
+	// The source may come before or after the flags
+	if err := flags.Parse(args); err != nil || flags.NArg() == 0 {
+		return fmt.Errorf(installUsage)
+	}
+	source := flags.Arg(0)
+	if err := flags.Parse(flags.Args()[1:]); err != nil || flags.NArg() > 0 {
+		return fmt.Errorf(installUsage)
+	}
+
+	ctx := context.Background()
+	data, err := packs.Fetch(ctx, source)
+	if err != nil {
+		return err
+	}
+	if err := packs.Verify(ctx, source, data, verification); err != nil {
+		return err
+	}
+	pack, err := packs.Parse(data)
+	if err != nil {
+		return err
+	}
+	changes, err := packs.Plan(pack, *overwrite)
+	if err != nil {
+		return err
+	}
+
+	title := "Pack " + pack.Name
+	if pack.Version != "" {
+		title += " " + pack.Version
+	}
+	fmt.Printf("%s: %d tool(s)\n", title, len(pack.Tools))
+	for _, change := range changes {
+		switch change.Action {
+		case packs.ActionUpdate:
+			fmt.Printf("  update   %s (replaces version %d)\n", change.Tool.Name, change.Replaces)
+		case packs.ActionSkip:
+			fmt.Printf("  skip     %s (already saved; use --overwrite to replace it)\n", change.Tool.Name)
+		default:
+			fmt.Printf("  install  %s\n", change.Tool.Name)
+		}
+		if len(change.Requested) > 0 && change.Action != packs.ActionSkip {
+			fmt.Printf("           requests permissions: %s (not granted; use mcp-metatool grant %s %s)\n",
+				strings.Join(change.Requested, ", "), change.Tool.Name, strings.Join(change.Requested, " "))
+		}
+	}
+	if *dryRun {
+		fmt.Println("Dry run: nothing was installed")
+		return nil
+	}
+
+	installed, err := packs.Install(changes)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Installed %d tool(s)\n", len(installed))
+	return nil
+}
@@ -0,0 +1,46 @@
+package cmd
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+)
+
+func TestInstall(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	pack := []byte(`{"name": "demo", "tools": [{"name": "double", "description": "Doubles n", "code": "params['n'] * 2", "permissions": ["notify"]}]}`)
+	source := filepath.Join(t.TempDir(), "pack.json")
+	os.WriteFile(source, pack, 0644)
+	sum := sha256.Sum256(pack)
+	checksum := hex.EncodeToString(sum[:])
+
+	for _, args := range [][]string{nil, {"--dry-run"}, {source, "extra"}, {source}} {
+		if err := Install(args); err == nil {
+			t.Errorf("Install(%v) should fail", args)
+		}
+	}
+
+	var err error
+	output := captureStdout(t, func() { err = Install([]string{source, "--sha256", checksum, "--dry-run"}) })
+	if err != nil {
+		t.Fatalf("dry run error = %v", err)
+	}
+	if !strings.Contains(output, "requests permissions: notify (not granted; use mcp-metatool grant double notify)") {
+		t.Errorf("Expected the dry run to list requested permissions, got %q", output)
+	}
+	if tools, _ := persistence.ListTools(); len(tools) != 0 {
+		t.Errorf("dry run installed %d tool(s)", len(tools))
+	}
+
+	if err := Install([]string{"--sha256", checksum, source}); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+	if tool, err := persistence.LoadTool("double"); err != nil || tool.Description != "Doubles n" || len(tool.Permissions) != 0 {
+		t.Errorf("LoadTool() = %+v, %v, want the installed tool", tool, err)
+	}
+}
@@ -1,8 +1,9 @@
 package cmd
 
 import (
+	"errors"
+	"flag"
 	"fmt"
-	"log"
 	"os"
 	"sort"
 	"strings"
@@ -10,6 +11,7 @@ import (
 	"golang.org/x/term"
 
 	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/logging"
 	"github.com/dslh/mcp-metatool/internal/persistence"
 	"github.com/dslh/mcp-metatool/internal/proxy"
 )
@@ -19,6 +21,9 @@ const (
 	colorReset     = "\x1b[0m"
 	colorBoldWhite = "\x1b[1;97m"
 	colorCyan      = "\x1b[36m"
+	colorGreen     = "\x1b[32m"
+	colorRed       = "\x1b[31m"
+	colorYellow    = "\x1b[33m"
 )
 
 // toolInfo represents a tool with its name and description
@@ -48,6 +53,41 @@ func truncateDescription(desc string) string {
 	return desc
 }
 
+// applyBuiltinOverrides renames, re-describes or drops built-in tools per the builtinTools config
+func applyBuiltinOverrides(tools []toolInfo, overrides map[string]config.BuiltinToolConfig) []toolInfo {
+	result := make([]toolInfo, 0, len(tools))
+	for _, tool := range tools {
+		if override, ok := overrides[tool.name]; ok {
+			if override.Hidden {
+				continue
+			}
+			if override.Name != "" {
+				tool.name = override.Name
+			}
+			if override.Description != "" {
+				tool.description = override.Description
+			}
+		}
+		result = append(result, tool)
+	}
+	return result
+}
+
+// savedToolMetadata describes when and by whom a saved tool was last saved, if that is known
+func savedToolMetadata(tool *persistence.SavedToolDefinition) string {
+	var parts []string
+	if tool.UpdatedAt != nil {
+		parts = append(parts, "updated "+tool.UpdatedAt.Local().Format("2006-01-02"))
+	}
+	if tool.Author != "" {
+		parts = append(parts, "by "+tool.Author)
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return colorize(" ("+strings.Join(parts, " ")+")", colorYellow)
+}
+
 // printToolGroup prints a group of tools with aligned columns
 func printToolGroup(tools []toolInfo) {
 	if len(tools) == 0 {
@@ -73,23 +113,32 @@ func printToolGroup(tools []toolInfo) {
 }
 
 // ListTools displays all tools exposed by mcp-metatool
-func ListTools() error {
+func ListTools(args []string) error {
+	flags := flag.NewFlagSet("list", flag.ContinueOnError)
+	sortBy := flags.String("sort", persistence.SortByName, "order saved tools by name, created or updated")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return fmt.Errorf("usage: mcp-metatool list [--sort name|created|updated]")
+	}
+
 	// 1. Load and display saved tools
-	fmt.Println(colorize("Saved Tools:", colorCyan))
 	savedTools, err := persistence.ListTools()
+	if err == nil {
+		err = persistence.SortTools(savedTools, *sortBy)
+		if err != nil {
+			return err
+		}
+	}
+	fmt.Println(colorize("Saved Tools:", colorCyan))
 	if err != nil {
-		log.Printf("Warning: failed to load saved tools: %v", err)
+		logging.Warnf("Failed to load saved tools: %v", err)
 	} else if len(savedTools) == 0 {
 		fmt.Println("  (none)")
 	} else {
-		sort.Slice(savedTools, func(i, j int) bool {
-			return savedTools[i].Name < savedTools[j].Name
-		})
 		tools := make([]toolInfo, len(savedTools))
 		for i, tool := range savedTools {
 			tools[i] = toolInfo{
 				name:        tool.Name,
-				description: tool.Description,
+				description: truncateDescription(tool.Description) + savedToolMetadata(tool),
 			}
 		}
 		printToolGroup(tools)
@@ -101,9 +150,38 @@ func ListTools() error {
 	builtinTools := []toolInfo{
 		{"eval_starlark", "Execute Starlark code with access to proxied MCP tools"},
 		{"save_tool", "Create or update a composite tool definition"},
+		{"patch_saved_tool", "Update only the given fields of a saved tool, such as its code, leaving the rest of its definition unchanged"},
 		{"list_saved_tools", "List all saved composite tool definitions"},
 		{"show_saved_tool", "Show the complete definition of a saved tool"},
 		{"delete_saved_tool", "Delete a saved tool definition from storage"},
+		{"delete_saved_tools", "Delete every saved tool whose name matches a glob pattern, or list the matches with dryRun"},
+		{"prune_saved_tools", "List saved tools that haven't run in a number of days, deleting them when confirmed"},
+		{"export_context", "Package a saved tool's description and latest result as prompt-ready markdown, summarized and chunked"},
+		{"test_saved_tool", "Run the embedded tests of a saved tool"},
+		{"list_tool_versions", "List the prior versions kept for a saved tool"},
+		{"rollback_saved_tool", "Restore a prior version of a saved tool"},
+		{"restore_saved_tool", "Restore the most recent backup of a saved tool"},
+		{"rename_saved_tool", "Rename a saved tool, keeping its definition, creation time and author"},
+		{"duplicate_saved_tool", "Copy a saved tool to a new name, as a starting point for a variant of it"},
+		{"list_pending_approvals", "List upstream tool calls waiting for human approval"},
+		{"deny_call", "Deny a pending upstream tool call and discard it"},
+		{"list_servers", "List configured upstream servers with their transport, connection state, tool count and last error"},
+		{"server_status", "Report the health of upstream servers: ping latency, last successful call and consecutive failures"},
+		{"restart_server", "Restart a single upstream server by name, relaunching its process and rediscovering its tools"},
+		{"reload_config", "Re-read servers.json and reconcile upstream servers and proxied tools, returning what changed"},
+		{"add_server", "Add an upstream MCP server, or replace an existing server's settings, saving it to servers.json and connecting immediately"},
+		{"remove_server", "Remove an upstream MCP server from servers.json and disconnect it"},
+		{"context_cost", "Estimate how many tokens the advertised tools' names, descriptions and schemas consume, broken down by server"},
+		{"tool_stats", "Show how often each saved and proxied tool has been called, how long calls take on average and at most, and how often they fail"},
+		{"curate_tools", "Suggest proxied tools to hide, servers to collapse into a dispatcher and saved tools that look unused, based on recorded usage and context cost, with a servers.json patch that applies them"},
+		{"validate_call", "Check arguments against an upstream tool's input schema without calling it, suggesting corrections for invalid arguments"},
+		{"search_tools", "Search the names and descriptions of every upstream server's tools, including hidden ones, for the tools best matching a description of what they do"},
+		{"describe_tool", "Show the complete description, input schema and annotations of a proxied or saved tool, including hidden ones, to construct valid arguments before calling it"},
+		{"call_tool", "Call any upstream tool by server and tool name, including hidden ones, for when the tool to call is only known at runtime"},
+		{"verify_tools", "Compile saved tools without running them and check the servers and tools they call still exist, listing any problems"},
+	}
+	if cfg, err := config.LoadDefaultConfig(); err == nil {
+		builtinTools = applyBuiltinOverrides(builtinTools, cfg.BuiltinTools)
 	}
 	printToolGroup(builtinTools)
 	fmt.Println()
@@ -112,7 +190,8 @@ func ListTools() error {
 	cfg, err := config.LoadDefaultConfig()
 	if err != nil {
 		// Check if it's just a missing file
-		if _, ok := err.(*os.PathError); ok {
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
 			fmt.Println("Proxied Tools:")
 			fmt.Println("  (no MCP server configuration found)")
 			return nil
@@ -138,10 +217,19 @@ func ListTools() error {
 	}
 	defer proxyManager.Stop()
 
+	// Show server health last, after any proxied tools, with what failing servers wrote to stderr
+	proxyManager.CheckHealth()
+	statuses := proxyManager.ServerStatuses()
+	for i := range statuses {
+		statuses[i].Stderr = proxyManager.ServerStderr(statuses[i].Name)
+	}
+	defer printServerHealth(statuses)
+
 	allTools := proxyManager.GetAllTools()
 	if len(allTools) == 0 {
 		fmt.Println("Proxied Tools:")
 		fmt.Println("  (no tools discovered from MCP servers)")
+		fmt.Println()
 		return nil
 	}
 
@@ -159,7 +247,7 @@ func ListTools() error {
 		// Get server configuration for filtering
 		serverConfig, exists := cfg.MCPServers[serverName]
 		if !exists {
-			log.Printf("Warning: No configuration found for server %s, skipping", serverName)
+			logging.Warnf("No configuration found for server %s, skipping", serverName)
 			continue
 		}
 
@@ -194,14 +282,38 @@ func ListTools() error {
 	return nil
 }
 
-// Run is the entry point for the list command
-func Run(args []string) int {
-	if len(args) > 0 && args[0] == "list" {
-		if err := ListTools(); err != nil {
-			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-			return 1
+// stderrHealthLines is how many of an unhealthy server's latest stderr lines are shown with its health
+const stderrHealthLines = 10
+
+// printServerHealth prints each server's health, colored by how healthy it is,
+// followed by the latest stderr output of unhealthy servers
+func printServerHealth(statuses []proxy.ServerStatus) {
+	fmt.Println(colorize("Server Health:", colorCyan))
+	servers := make([]toolInfo, len(statuses))
+	for i, status := range statuses {
+		summary := truncateDescription(status.HealthSummary())
+		if status.LastError != "" && status.Health == proxy.HealthUnhealthy {
+			summary += ": " + truncateDescription(status.LastError)
 		}
-		return 0
+		switch status.Health {
+		case proxy.HealthHealthy:
+			summary = colorize(summary, colorGreen)
+		case proxy.HealthDegraded:
+			summary = colorize(summary, colorYellow)
+		case proxy.HealthUnhealthy:
+			summary = colorize(summary, colorRed)
+		}
+		servers[i] = toolInfo{name: status.Name, description: summary}
 	}
-	return -1 // Not a list command
-}
\ No newline at end of file
+	printToolGroup(servers)
+
+	for _, status := range statuses {
+		if status.Health != proxy.HealthUnhealthy || len(status.Stderr) == 0 {
+			continue
+		}
+		fmt.Printf("\n  %s\n", colorize(status.Name+" stderr:", colorRed))
+		for _, line := range status.Stderr[max(0, len(status.Stderr)-stderrHealthLines):] {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+}
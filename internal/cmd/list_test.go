@@ -6,6 +6,11 @@ import (
 	"os"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/proxy"
 )
 
 func TestRun_ListCommand(t *testing.T) {
@@ -39,7 +44,7 @@ func TestListTools_SavedToolsSection(t *testing.T) {
 	os.Stdout = w
 
 	// Run list command
-	err := ListTools()
+	err := ListTools(nil)
 
 	// Restore stdout
 	w.Close()
@@ -87,7 +92,7 @@ func TestListTools_OutputFormat(t *testing.T) {
 	os.Stdout = w
 
 	// Run list command
-	ListTools()
+	ListTools(nil)
 
 	// Restore stdout
 	w.Close()
@@ -107,4 +112,86 @@ func TestListTools_OutputFormat(t *testing.T) {
 	if !strings.Contains(output, "Execute Starlark code") {
 		t.Error("Tool descriptions should be included")
 	}
-}
\ No newline at end of file
+}
+func TestListTools_SortsSavedTools(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	for _, tool := range []*persistence.SavedToolDefinition{
+		{Name: "beta", Description: "Second", Code: "1", Author: "ada"},
+		{Name: "alpha", Description: "First", Code: "1"},
+	} {
+		if err := persistence.SaveTool(tool); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// beta was updated last
+	time.Sleep(1100 * time.Millisecond)
+	if err := persistence.SaveTool(&persistence.SavedToolDefinition{Name: "beta", Description: "Second", Code: "2"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		sort      string
+		wantFirst string
+	}{
+		{"name", "alpha"},
+		{"updated", "beta"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			var err error
+			output := captureStdout(t, func() { err = ListTools([]string{"--sort", tt.sort}) })
+			if err != nil {
+				t.Fatalf("ListTools() error = %v", err)
+			}
+			if first := strings.Index(output, "• "+tt.wantFirst); first < 0 || first > strings.Index(output, "• alpha") || first > strings.Index(output, "• beta") {
+				t.Errorf("Expected %s listed first:\n%s", tt.wantFirst, output)
+			}
+			if !strings.Contains(output, "Second (updated ") || !strings.Contains(output, " by ada)") {
+				t.Errorf("Expected beta's update date and author:\n%s", output)
+			}
+		})
+	}
+
+	if err := ListTools([]string{"--sort", "size"}); err == nil {
+		t.Error("Expected an unknown sort to fail")
+	}
+}
+
+func TestApplyBuiltinOverrides(t *testing.T) {
+	tools := []toolInfo{
+		{"eval_starlark", "Execute Starlark code"},
+		{"save_tool", "Create or update a composite tool definition"},
+		{"list_saved_tools", "List all saved composite tool definitions"},
+	}
+
+	result := applyBuiltinOverrides(tools, map[string]config.BuiltinToolConfig{
+		"eval_starlark": {Hidden: true},
+		"save_tool":     {Name: "metatool_save", Description: "Save a tool"},
+	})
+
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 tools, got %d", len(result))
+	}
+	if result[0].name != "metatool_save" || result[0].description != "Save a tool" {
+		t.Errorf("Expected renamed save_tool, got %+v", result[0])
+	}
+	if result[1].name != "list_saved_tools" {
+		t.Errorf("Expected untouched list_saved_tools, got %+v", result[1])
+	}
+}
+
+func TestPrintServerHealth_Stderr(t *testing.T) {
+	output := captureStdout(t, func() {
+		printServerHealth([]proxy.ServerStatus{
+			{Name: "github", State: proxy.StateConnected, Health: proxy.HealthHealthy, Stderr: []string{"listening on stdio"}},
+			{Name: "jira", State: proxy.StateFailed, Health: proxy.HealthUnhealthy, LastError: "connection closed", Stderr: []string{"error: JIRA_TOKEN is not set"}},
+		})
+	})
+
+	if !strings.Contains(output, "jira stderr:") || !strings.Contains(output, "    error: JIRA_TOKEN is not set") {
+		t.Errorf("Expected jira's stderr after its health, got:\n%s", output)
+	}
+	if strings.Contains(output, "listening on stdio") {
+		t.Errorf("Expected no stderr for healthy servers, got:\n%s", output)
+	}
+}
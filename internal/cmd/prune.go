@@ -0,0 +1,106 @@
+package cmd
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/tools"
+)
+
+const deleteUsage = "usage: mcp-metatool delete <pattern>... [--dry-run]"
+
+const pruneUsage = "usage: mcp-metatool prune --days N [--dry-run] [--yes]"
+
+// DeleteSavedTools deletes every saved tool matching one of the glob patterns
+func DeleteSavedTools(args []string) error {
+	var patterns []string
+	dryRun := false
+	for _, arg := range args {
+		switch arg {
+		case "--dry-run", "-dry-run", "-n":
+			dryRun = true
+		default:
+			patterns = append(patterns, arg)
+		}
+	}
+	if len(patterns) == 0 {
+		return fmt.Errorf(deleteUsage)
+	}
+
+	names, err := persistence.MatchTools(patterns)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Println("No saved tools match")
+		return nil
+	}
+	return deleteListed(names, dryRun)
+}
+
+// Prune deletes saved tools that haven't run in a number of days, after confirmation
+func Prune(args []string) error {
+	return runPrune(args, os.Stdin)
+}
+
+// runPrune implements Prune, reading the confirmation from input
+func runPrune(args []string, input io.Reader) error {
+	flags := flag.NewFlagSet("prune", flag.ContinueOnError)
+	days := flags.Int("days", 0, "prune saved tools that haven't run in this many days")
+	dryRun := flags.Bool("dry-run", false, "list the tools that would be pruned without deleting them")
+	yes := flags.Bool("yes", false, "don't ask for confirmation")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 || *days <= 0 {
+		return fmt.Errorf(pruneUsage)
+	}
+
+	names, err := tools.UnusedSavedTools(*days)
+	if err != nil {
+		return err
+	}
+	if len(names) == 0 {
+		fmt.Printf("No saved tools have gone unused for %d day(s)\n", *days)
+		return nil
+	}
+
+	if !*dryRun && !*yes {
+		printToolNames(fmt.Sprintf("Saved tools unused for %d day(s):", *days), names)
+		p := &prompter{scanner: bufio.NewScanner(input)}
+		confirmed, err := p.confirm(fmt.Sprintf("Delete these %d tool(s)?", len(names)))
+		if err != nil {
+			return err
+		}
+		if !confirmed {
+			fmt.Println("Nothing deleted")
+			return nil
+		}
+	}
+	return deleteListed(names, *dryRun)
+}
+
+// deleteListed deletes the named tools and prints them, or only prints them on a dry run
+func deleteListed(names []string, dryRun bool) error {
+	if dryRun {
+		printToolNames(fmt.Sprintf("Would delete %d saved tool(s):", len(names)), names)
+		return nil
+	}
+
+	deleted, err := persistence.DeleteTools(names)
+	printToolNames(fmt.Sprintf("Deleted %d saved tool(s):", len(deleted)), deleted)
+	if err != nil {
+		return err
+	}
+	fmt.Println("Backups are kept; use restore_saved_tool to bring any back.")
+	return nil
+}
+
+// printToolNames prints a heading followed by one tool name per line
+func printToolNames(heading string, names []string) {
+	fmt.Println(colorize(heading, colorCyan))
+	for _, name := range names {
+		fmt.Printf("  • %s\n", name)
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+)
+
+// savedToolNames returns the names of the saved tools, sorted
+func savedToolNames(t *testing.T) string {
+	t.Helper()
+	names, err := persistence.MatchTools([]string{"*"})
+	if err != nil {
+		t.Fatalf("MatchTools() error = %v", err)
+	}
+	return strings.Join(names, ",")
+}
+
+func TestDeleteSavedTools(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	for _, name := range []string{"experiment_a", "experiment_b", "report"} {
+		persistence.SaveTool(&persistence.SavedToolDefinition{Name: name, Description: name, Code: "1"})
+	}
+
+	if err := DeleteSavedTools([]string{"experiment_*", "--dry-run"}); err != nil {
+		t.Fatalf("dry run error = %v", err)
+	}
+	if got := savedToolNames(t); got != "experiment_a,experiment_b,report" {
+		t.Errorf("dry run left %s", got)
+	}
+
+	if err := DeleteSavedTools([]string{"experiment_*"}); err != nil {
+		t.Fatalf("delete error = %v", err)
+	}
+	if got := savedToolNames(t); got != "report" {
+		t.Errorf("delete left %s, want report", got)
+	}
+
+	if err := DeleteSavedTools([]string{"--dry-run"}); err == nil {
+		t.Error("delete without patterns should fail")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+	// Tools saved before timestamps were recorded are as old as their files
+	toolsDir := filepath.Join(dir, "tools")
+	os.MkdirAll(toolsDir, 0755)
+	longAgo := time.Now().AddDate(0, -6, 0)
+	for _, name := range []string{"fresh", "stale", "unused"} {
+		filename := filepath.Join(toolsDir, name+".json")
+		os.WriteFile(filename, []byte(fmt.Sprintf(`{"name": %q, "description": %q, "inputSchema": {}, "code": "1"}`, name, name)), 0644)
+		os.Chtimes(filename, longAgo, longAgo)
+	}
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "brand_new", Description: "brand_new", Code: "1"})
+	stats := fmt.Sprintf(`{"since": "2026-01-01T00:00:00Z", "saved": {"fresh": {"calls": 2, "lastUsed": %q}}}`, time.Now().Format(time.RFC3339))
+	os.WriteFile(filepath.Join(dir, "usage.json"), []byte(stats), 0644)
+
+	for _, args := range [][]string{nil, {"--days", "0"}, {"--days", "30", "extra"}} {
+		if err := runPrune(args, strings.NewReader("")); err == nil {
+			t.Errorf("runPrune(%v) should fail", args)
+		}
+	}
+
+	if err := runPrune([]string{"--days", "30"}, strings.NewReader("n\n")); err != nil {
+		t.Fatalf("declined prune error = %v", err)
+	}
+	if err := runPrune([]string{"--days", "30", "--dry-run"}, strings.NewReader("")); err != nil {
+		t.Fatalf("dry run error = %v", err)
+	}
+	if got := savedToolNames(t); got != "brand_new,fresh,stale,unused" {
+		t.Errorf("prune deleted tools without confirmation, left %s", got)
+	}
+
+	if err := runPrune([]string{"--days", "30"}, strings.NewReader("y\n")); err != nil {
+		t.Fatalf("confirmed prune error = %v", err)
+	}
+	if got := savedToolNames(t); got != "brand_new,fresh" {
+		t.Errorf("prune left %s, want brand_new,fresh", got)
+	}
+}
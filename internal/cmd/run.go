@@ -0,0 +1,240 @@
+package cmd
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dslh/mcp-metatool/internal/approval"
+	"github.com/dslh/mcp-metatool/internal/chaos"
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/notify"
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/tooltest"
+	"github.com/dslh/mcp-metatool/internal/validation"
+)
+
+const runUsage = "usage: mcp-metatool run <tool> [--params JSON | --params -] [--preset NAME] [--record FILE | --replay FILE]"
+
+// RunTool executes a saved tool or a proxied tool, named as it is advertised, once and prints its result
+func RunTool(args []string) error {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return fmt.Errorf(runUsage)
+	}
+	name := args[0]
+
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	paramsFlag := flags.String("params", "", "tool parameters as a JSON object, or - to read them from stdin")
+	presetFlag := flags.String("preset", "", "name of a saved tool preset to fill in parameters from")
+	recordFlag := flags.String("record", "", "record a saved tool's upstream calls to this fixture file")
+	replayFlag := flags.String("replay", "", "answer a saved tool's upstream calls from this fixture file instead of the configured servers")
+	if err := flags.Parse(args[1:]); err != nil || (*recordFlag != "" && *replayFlag != "") {
+		return fmt.Errorf(runUsage)
+	}
+
+	params, err := readParams(*paramsFlag, os.Stdin)
+	if err != nil {
+		return err
+	}
+	if *presetFlag != "" {
+		params[persistence.PresetParam] = *presetFlag
+	}
+
+	if *replayFlag != "" {
+		return replaySavedTool(name, params, *paramsFlag != "", *replayFlag)
+	}
+	if serverName, toolName, ok := resolveProxiedTool(name); ok {
+		if *recordFlag != "" {
+			return fmt.Errorf("only saved tools can be recorded")
+		}
+		return runProxiedTool(serverName, toolName, params)
+	}
+	return runSavedTool(name, params, *recordFlag)
+}
+
+// resolveProxiedTool finds the upstream tool a name refers to, if it is a proxied tool. Unprefixed
+// names that aren't saved tools refer to the primary server's tools, if there is one.
+func resolveProxiedTool(name string) (serverName, toolName string, ok bool) {
+	cfg, err := config.LoadDefaultConfig()
+	if err != nil {
+		// Running it will report the missing config
+		return strings.Cut(name, config.DefaultToolNameSeparator)
+	}
+	if serverName, toolName, ok := cfg.SplitProxiedToolName(name); ok {
+		return serverName, cfg.MCPServers[serverName].UpstreamToolName(toolName), true
+	}
+	if primary := cfg.PrimaryServer(); primary != "" {
+		if _, err := persistence.LoadTool(name); err != nil {
+			return primary, cfg.MCPServers[primary].UpstreamToolName(name), true
+		}
+	}
+	return "", "", false
+}
+
+// readParams decodes tool parameters from the flag value, reading stdin when it is "-"
+func readParams(value string, stdin io.Reader) (map[string]interface{}, error) {
+	if value == "-" {
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read params from stdin: %w", err)
+		}
+		value = string(data)
+	}
+
+	params := make(map[string]interface{})
+	if strings.TrimSpace(value) == "" {
+		return params, nil
+	}
+
+	if err := json.Unmarshal([]byte(value), &params); err != nil {
+		return nil, fmt.Errorf("params must be a JSON object: %w", err)
+	}
+	return params, nil
+}
+
+// runSavedTool executes a saved tool, connecting to upstream servers when they are configured.
+// With a fixture path, the tool's upstream calls are recorded there.
+func runSavedTool(name string, params map[string]interface{}, fixturePath string) error {
+	tool, params, err := loadSavedTool(name, params)
+	if err != nil {
+		return err
+	}
+	if fixturePath == "" {
+		return executeAndPrint(tool.Code, params, nil, starlark.WithPermissions(tool.Permissions...), starlark.WithAllowedServers(tool.AllowedServers...), starlark.WithFileName(tool.Name))
+	}
+
+	var recorder *tooltest.Recorder
+	record := func(upstream proxy.ProxyManager) proxy.ProxyManager {
+		recorder = tooltest.NewRecorder(upstream)
+		return recorder
+	}
+	runErr := executeAndPrint(tool.Code, params, record, starlark.WithPermissions(tool.Permissions...), starlark.WithAllowedServers(tool.AllowedServers...), starlark.WithFileName(tool.Name))
+	if recorder == nil {
+		return fmt.Errorf("no upstream servers to record")
+	}
+	if err := recorder.Fixture(name, params).Save(fixturePath); err != nil {
+		return err
+	}
+	return runErr
+}
+
+// replaySavedTool executes a saved tool offline, answering its upstream calls from a fixture.
+// Unless given explicitly, the params are those the fixture was recorded with.
+func replaySavedTool(name string, params map[string]interface{}, explicitParams bool, fixturePath string) error {
+	fixture, err := tooltest.LoadFixture(fixturePath)
+	if err != nil {
+		return err
+	}
+	if !explicitParams && fixture.Params != nil {
+		for key, value := range fixture.Params {
+			if _, ok := params[key]; !ok {
+				params[key] = value
+			}
+		}
+	}
+
+	tool, params, err := loadSavedTool(name, params)
+	if err != nil {
+		return err
+	}
+	return printResult(starlark.ExecuteWithProxy(tool.Code, params, tooltest.NewReplayer(fixture), starlark.WithPermissions(tool.Permissions...), starlark.WithAllowedServers(tool.AllowedServers...), starlark.WithFileName(tool.Name)))
+}
+
+// loadSavedTool loads a saved tool and fills in and validates the params it is to run with
+func loadSavedTool(name string, params map[string]interface{}) (*persistence.SavedToolDefinition, map[string]interface{}, error) {
+	tool, err := persistence.LoadTool(name)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load tool '%s': %w", name, err)
+	}
+
+	params, err = tool.ApplyPreset(params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := validation.ValidateParams(tool.InputSchema, params); err != nil {
+		return nil, nil, fmt.Errorf("%s", validation.FormatValidationError(err))
+	}
+	return tool, params, nil
+}
+
+// executeAndPrint runs Starlark code with the configured server namespaces and prints the JSON result.
+// If wrap is given, it is placed in front of the upstream servers.
+func executeAndPrint(code string, params map[string]interface{}, wrap func(proxy.ProxyManager) proxy.ProxyManager, extra ...starlark.Option) error {
+	var proxyManager starlark.ProxyManager
+	var opts []starlark.Option
+	cfg, manager, err := startProxyManager()
+	if err != nil {
+		logging.Warnf("Running without proxied servers: %v", err)
+	} else {
+		defer manager.Stop()
+		upstream, err := chaos.WrapFromEnv(manager)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", chaos.EnvVar, err)
+		}
+		if wrap != nil {
+			upstream = wrap(upstream)
+		}
+		proxyManager = approval.NewGate(upstream, config.NewLive(cfg))
+		opts = starlarkOptions(cfg)
+	}
+
+	return printResult(starlark.ExecuteWithProxy(code, params, proxyManager, append(opts, extra...)...))
+}
+
+// printResult prints the JSON result of executed Starlark code, or returns its error
+func printResult(result *starlark.Result, err error) error {
+	if err != nil {
+		return fmt.Errorf("execution failed: %w", err)
+	}
+	if result.Error != "" {
+		return fmt.Errorf("%s", result.Error)
+	}
+
+	return printJSON(result.Result)
+}
+
+// runProxiedTool calls a single tool on an upstream server
+func runProxiedTool(serverName, toolName string, params map[string]interface{}) error {
+	_, manager, err := startProxyManager()
+	if err != nil {
+		return err
+	}
+	defer manager.Stop()
+
+	result, err := manager.CallTool(serverName, toolName, params)
+	if err != nil {
+		return fmt.Errorf("failed to call %s.%s: %w", serverName, toolName, err)
+	}
+
+	printCallResult(result)
+	if result.IsError {
+		return fmt.Errorf("%s.%s returned an error", serverName, toolName)
+	}
+	return nil
+}
+
+// starlarkOptions returns the optional Starlark modules enabled by the config
+func starlarkOptions(cfg *config.Config) []starlark.Option {
+	var opts []starlark.Option
+	if cfg.Notify != nil {
+		opts = append(opts, starlark.WithModule("notify", notify.NewNotifier(cfg.Notify).Module()))
+	}
+	return append(opts, starlark.WithRestrictedModules(cfg.RestrictedModules...))
+}
+
+// printJSON prints a value as indented JSON
+func printJSON(value interface{}) error {
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode result: %w", err)
+	}
+	fmt.Println(string(data))
+	return nil
+}
@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/tooltest"
+)
+
+// captureStdout returns everything written to stdout while fn runs
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+
+	oldStdout := os.Stdout
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatalf("Failed to create pipe: %v", err)
+	}
+	os.Stdout = w
+
+	fn()
+
+	w.Close()
+	os.Stdout = oldStdout
+
+	var buf bytes.Buffer
+	io.Copy(&buf, r)
+	return buf.String()
+}
+
+func TestRunTool_SavedTool(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	persistence.SaveTool(&persistence.SavedToolDefinition{
+		Name: "greet",
+		Code: `{"greeting": "Hello, " + params["name"]}`,
+		InputSchema: map[string]interface{}{
+			"type":       "object",
+			"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+			"required":   []interface{}{"name"},
+		},
+	})
+
+	var err error
+	output := captureStdout(t, func() {
+		err = RunTool([]string{"greet", "--params", `{"name": "Ada"}`})
+	})
+	if err != nil {
+		t.Fatalf("RunTool() error = %v", err)
+	}
+	if !strings.Contains(output, `"greeting": "Hello, Ada"`) {
+		t.Errorf("Expected JSON result in output, got %q", output)
+	}
+
+	if err := RunTool([]string{"greet"}); err == nil {
+		t.Error("Expected validation error when required params are missing")
+	}
+}
+
+func TestRunTool_Errors(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	persistence.SaveTool(&persistence.SavedToolDefinition{
+		Name: "fails",
+		Code: "fail(\"boom\")\nresult = 1",
+	})
+
+	tests := []struct {
+		name    string
+		args    []string
+		wantErr string
+	}{
+		{"no tool", nil, "usage"},
+		{"flag instead of tool", []string{"--params", "{}"}, "usage"},
+		{"bad json", []string{"fails", "--params", "{"}, "JSON object"},
+		{"unknown tool", []string{"missing"}, "failed to load tool"},
+		{"tool error", []string{"fails"}, "boom"},
+		{"proxied without config", []string{"github__get_issue"}, "failed to load config"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := RunTool(tt.args)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestRunTool_Replay(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	persistence.SaveTool(&persistence.SavedToolDefinition{
+		Name: "issue_title",
+		Code: `github.get_issue({"number": params["number"]})["structured"]["title"]`,
+	})
+	fixture := &tooltest.Fixture{
+		Params:  map[string]interface{}{"number": 7},
+		Servers: map[string]tooltest.FixtureServer{"github": {Tools: []*mcp.Tool{{Name: "get_issue"}}}},
+		Calls: []tooltest.FixtureCall{{
+			Server:    "github",
+			Tool:      "get_issue",
+			Arguments: map[string]interface{}{"number": 7},
+			Result:    &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "{}"}}, StructuredContent: map[string]interface{}{"title": "Crash on start"}},
+		}},
+	}
+	path := filepath.Join(t.TempDir(), "issue.json")
+	if err := fixture.Save(path); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	output := captureStdout(t, func() {
+		err = RunTool([]string{"issue_title", "--replay", path})
+	})
+	if err != nil {
+		t.Fatalf("RunTool() error = %v", err)
+	}
+	if !strings.Contains(output, `"Crash on start"`) {
+		t.Errorf("Expected the recorded title, got %q", output)
+	}
+
+	err = RunTool([]string{"issue_title", "--replay", path, "--params", `{"number": 8}`})
+	if err == nil || !strings.Contains(err.Error(), "no recorded response for github.get_issue") {
+		t.Errorf("Expected a missing recording error, got %v", err)
+	}
+	if err := RunTool([]string{"issue_title", "--replay", path, "--record", path}); err == nil || !strings.Contains(err.Error(), "usage") {
+		t.Errorf("Expected a usage error for --record with --replay, got %v", err)
+	}
+}
+
+func TestReadParams(t *testing.T) {
+	params, err := readParams("-", strings.NewReader(`{"n": 3}`))
+	if err != nil {
+		t.Fatalf("readParams() error = %v", err)
+	}
+	if params["n"] != float64(3) {
+		t.Errorf("Expected n=3 from stdin, got %v", params["n"])
+	}
+
+	params, err = readParams("", nil)
+	if err != nil || len(params) != 0 {
+		t.Errorf("Expected empty params, got %v, %v", params, err)
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/dslh/mcp-metatool/internal/secrets"
+)
+
+// secretsUsage describes the secrets subcommands
+const secretsUsage = "usage: mcp-metatool secrets [list | set <name> [value] | unset <name>]"
+
+// Secrets lists, sets, or removes secrets available to Starlark code via secrets.get
+func Secrets(args []string) error {
+	return runSecrets(args, os.Stdin)
+}
+
+// runSecrets implements Secrets, reading values that aren't given as arguments from stdin
+func runSecrets(args []string, stdin io.Reader) error {
+	if len(args) == 0 || (args[0] == "list" && len(args) == 1) {
+		return listSecrets()
+	}
+
+	switch {
+	case args[0] == "set" && len(args) == 2:
+		// Reading the value from stdin keeps it out of shell history
+		data, err := io.ReadAll(stdin)
+		if err != nil {
+			return fmt.Errorf("failed to read secret from stdin: %w", err)
+		}
+		return setSecret(args[1], strings.TrimRight(string(data), "\r\n"))
+	case args[0] == "set" && len(args) == 3:
+		return setSecret(args[1], args[2])
+	case args[0] == "unset" && len(args) == 2:
+		if err := secrets.Delete(args[1]); err != nil {
+			return err
+		}
+		fmt.Printf("Removed secret %s\n", args[1])
+		return nil
+	default:
+		return fmt.Errorf(secretsUsage)
+	}
+}
+
+// listSecrets prints the name of every stored secret
+func listSecrets() error {
+	names, err := secrets.Names()
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(colorize("Secrets:", colorCyan))
+	if len(names) == 0 {
+		fmt.Println("  (none)")
+		return nil
+	}
+	for _, name := range names {
+		fmt.Printf("  • %s\n", name)
+	}
+	return nil
+}
+
+// setSecret stores a secret and confirms without echoing its value
+func setSecret(name, value string) error {
+	if value == "" {
+		return fmt.Errorf("secret value cannot be empty")
+	}
+	if err := secrets.Set(name, value); err != nil {
+		return err
+	}
+	fmt.Printf("Set secret %s\n", name)
+	return nil
+}
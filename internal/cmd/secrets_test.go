@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/secrets"
+)
+
+func TestSecrets(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	if err := runSecrets(nil, strings.NewReader("")); err != nil {
+		t.Errorf("Listing empty secrets should not fail: %v", err)
+	}
+
+	if err := runSecrets([]string{"set", "api_key", "abc"}, strings.NewReader("")); err != nil {
+		t.Fatalf("set with a value error = %v", err)
+	}
+	if err := runSecrets([]string{"set", "token"}, strings.NewReader("from-stdin\n")); err != nil {
+		t.Fatalf("set from stdin error = %v", err)
+	}
+	if value, _ := secrets.Get("token"); value != "from-stdin" {
+		t.Errorf("secret read from stdin = %q, want from-stdin", value)
+	}
+
+	if err := runSecrets([]string{"list"}, strings.NewReader("")); err != nil {
+		t.Errorf("list error = %v", err)
+	}
+
+	if err := runSecrets([]string{"unset", "api_key"}, strings.NewReader("")); err != nil {
+		t.Fatalf("unset error = %v", err)
+	}
+	if _, err := secrets.Get("api_key"); err == nil {
+		t.Error("api_key should be removed")
+	}
+}
+
+func TestSecrets_InvalidUsage(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	invalid := [][]string{
+		{"set"},
+		{"set", "bad name", "value"},
+		{"set", "empty"},
+		{"unset", "missing"},
+		{"frobnicate"},
+	}
+	for _, args := range invalid {
+		if err := runSecrets(args, strings.NewReader("")); err == nil {
+			t.Errorf("runSecrets(%v) should fail", args)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/dslh/mcp-metatool/internal/tools"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+// Stats prints how often each tool has been called, how long calls take and how often they fail
+func Stats(args []string) error {
+	flags := flag.NewFlagSet("stats", flag.ContinueOnError)
+	jsonOutput := flags.Bool("json", false, "print the stats as JSON")
+	server := flags.String("server", "", `only show tools on this server, or "saved" for saved tools`)
+	sortBy := flags.String("sort", "calls", "order tools by calls, errors, errorRate, avg or max")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return fmt.Errorf("usage: mcp-metatool stats [--server <name>] [--sort <key>] [--json]")
+	}
+
+	response, err := tools.LoadToolStats(types.ToolStatsArgs{Server: *server, Sort: *sortBy})
+	if err != nil {
+		return err
+	}
+	if *jsonOutput {
+		return printJSON(response)
+	}
+	fmt.Println(response)
+	return nil
+}
@@ -0,0 +1,54 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/tools"
+)
+
+func TestStats(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+
+	var err error
+	output := captureStdout(t, func() {
+		err = Stats(nil)
+	})
+	if err != nil || !strings.Contains(output, "none recorded") {
+		t.Errorf("Expected no stats, got %q, %v", output, err)
+	}
+
+	stats := `{"since": "2026-01-01T00:00:00Z",
+		"proxied": {"github": {"get_issue": {"calls": 2, "errors": 1, "timed": 2, "totalMs": 30, "maxMs": 20}}},
+		"saved": {"report": {"calls": 1, "timed": 1, "totalMs": 12, "maxMs": 12}}}`
+	os.WriteFile(filepath.Join(dir, "usage.json"), []byte(stats), 0644)
+
+	output = captureStdout(t, func() {
+		err = Stats([]string{"--json", "--server", "github"})
+	})
+	if err != nil {
+		t.Fatalf("Stats() error = %v", err)
+	}
+	var response tools.ToolStatsResponse
+	if err := json.Unmarshal([]byte(output), &response); err != nil {
+		t.Fatalf("Expected JSON stats, got %q: %v", output, err)
+	}
+	if len(response.Saved) != 0 || len(response.Proxied) != 1 || response.Proxied[0].ErrorRate != 0.5 || response.Proxied[0].AvgMs != 15 {
+		t.Errorf("Expected github stats only, got %+v", response)
+	}
+
+	output = captureStdout(t, func() {
+		err = Stats(nil)
+	})
+	if err != nil || !strings.Contains(output, "• report: 1 call(s), 0 error(s) (0%), avg 12ms, max 12ms") {
+		t.Errorf("Expected text stats, got %q, %v", output, err)
+	}
+
+	if err := Stats([]string{"--sort", "name"}); err == nil {
+		t.Error("Expected error for unknown sort")
+	}
+}
@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/gitsync"
+	"github.com/dslh/mcp-metatool/internal/paths"
+)
+
+const syncUsage = "usage: mcp-metatool sync"
+
+// Sync commits changes to saved tools and syncs them with the configured git remote
+func Sync(args []string) error {
+	if len(args) > 0 {
+		return fmt.Errorf(syncUsage)
+	}
+	cfg, err := config.LoadDefaultConfig()
+	if err != nil {
+		return fmt.Errorf("failed to load config: %w", err)
+	}
+	if cfg.ToolSync == nil {
+		return fmt.Errorf("tool sync is not configured: add toolSync with a git remote to servers.json")
+	}
+
+	withheld, err := gitsync.NewRepo(cfg.ToolSync).Sync()
+	for _, w := range withheld {
+		fmt.Printf("%s: permissions %s were not granted here; use mcp-metatool grant %s %s\n",
+			w.Tool, strings.Join(w.Permissions, ", "), w.Tool, strings.Join(w.Permissions, " "))
+	}
+	var conflict *gitsync.ConflictError
+	if errors.As(err, &conflict) {
+		toolsDir, _ := paths.GetToolsDir()
+		return fmt.Errorf("%w\nYour changes are kept; resolve the conflicts in %s with git (e.g. git pull --rebase) and run sync again", err, toolsDir)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Saved tools synced with %s (%s)\n", cfg.ToolSync.Remote, cfg.ToolSync.BranchName())
+	return nil
+}
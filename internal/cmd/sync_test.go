@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+)
+
+func TestSync(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+
+	os.WriteFile(filepath.Join(dir, "servers.json"), []byte(`{"mcpServers": {}}`), 0644)
+	if err := Sync(nil); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("Sync() without toolSync error = %v, want not configured", err)
+	}
+
+	remote := t.TempDir()
+	if out, err := exec.Command("git", "init", "--bare", "--quiet", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, out)
+	}
+	os.WriteFile(filepath.Join(dir, "servers.json"), []byte(`{"mcpServers": {}, "toolSync": {"remote": "`+remote+`"}}`), 0644)
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "greet", Code: "'hello'"})
+	if err := Sync(nil); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	// Changes made without committing them are committed by the sync
+	out, err := exec.Command("git", "-C", remote, "show", "--stat", "--format=%s", "main").CombinedOutput()
+	if err != nil || !strings.Contains(string(out), "Sync saved tools") || !strings.Contains(string(out), "greet.star") {
+		t.Errorf("Remote head = %s, %v, want the synced tool", out, err)
+	}
+	if err := Sync([]string{"extra"}); err == nil {
+		t.Error("Sync() with arguments should fail")
+	}
+}
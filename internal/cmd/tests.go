@@ -0,0 +1,138 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/tooltest"
+)
+
+const testUsage = "usage: mcp-metatool test [--fixtures FILE] [tool...] | test --snapshots DIR [--update]"
+
+// TestTools runs the embedded tests of the named saved tools, or of every saved tool if none are named.
+// With --snapshots it runs golden file snapshot cases instead.
+func TestTools(args []string) error {
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	snapshotDir := flags.String("snapshots", "", "directory of snapshot fixtures to check against golden files")
+	update := flags.Bool("update", false, "rewrite golden files from the current output")
+	fixtures := flags.String("fixtures", os.Getenv(tooltest.FixturesEnvVar), "fixtures file answering upstream calls in tests without mocks, instead of live servers")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf(testUsage)
+	}
+
+	if *snapshotDir != "" {
+		return testSnapshots(*snapshotDir, *update)
+	}
+	if *update {
+		return fmt.Errorf(testUsage)
+	}
+
+	names := flags.Args()
+	var tools []*persistence.SavedToolDefinition
+	if len(names) == 0 {
+		all, err := persistence.ListTools()
+		if err != nil {
+			return fmt.Errorf("failed to list saved tools: %w", err)
+		}
+		tools = all
+	} else {
+		for _, name := range names {
+			tool, err := persistence.LoadTool(name)
+			if err != nil {
+				return fmt.Errorf("failed to load tool '%s': %w", name, err)
+			}
+			tools = append(tools, tool)
+		}
+	}
+
+	// Only connect to real servers if some test doesn't mock its upstream calls and there are no fixtures
+	var proxyManager proxy.ProxyManager
+	if *fixtures != "" {
+		fixtureProxy, err := tooltest.LoadFixtureProxy(*fixtures)
+		if err != nil {
+			return err
+		}
+		proxyManager = fixtureProxy
+	} else if needsUpstream(tools) {
+		_, manager, err := startProxyManager()
+		if err != nil {
+			logging.Warnf("Running tests without proxied servers: %v", err)
+		} else {
+			defer manager.Stop()
+			proxyManager = manager
+		}
+	}
+
+	failed := 0
+	tested := 0
+	for _, tool := range tools {
+		if len(tool.Tests) == 0 {
+			continue
+		}
+		report := tooltest.Run(tool, proxyManager)
+		fmt.Println(report.Summary())
+		failed += report.Failed
+		tested++
+	}
+
+	if tested == 0 {
+		fmt.Println("No saved tools with tests found")
+		return nil
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d test(s) failed", failed)
+	}
+	return nil
+}
+
+// needsUpstream reports whether any test case runs without mocks
+func needsUpstream(tools []*persistence.SavedToolDefinition) bool {
+	for _, tool := range tools {
+		for _, test := range tool.Tests {
+			if len(test.Mocks) == 0 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// testSnapshots checks (or updates) the snapshot fixtures in dir
+func testSnapshots(dir string, update bool) error {
+	results, err := tooltest.RunSnapshots(dir, update)
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Println("No snapshot cases found")
+		return nil
+	}
+
+	failed := 0
+	for _, result := range results {
+		name := result.Tool + "/" + result.Case
+		switch {
+		case result.Error != "":
+			failed++
+			fmt.Printf("  ✗ %s: %s\n", name, result.Error)
+		case !result.Passed:
+			failed++
+			fmt.Printf("  ✗ %s: output does not match golden file\n%s", name, result.Diff)
+		case result.Updated:
+			fmt.Printf("  ✎ %s: golden file updated\n", name)
+		default:
+			fmt.Printf("  ✓ %s\n", name)
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d snapshot(s) failed", failed)
+	}
+	return nil
+}
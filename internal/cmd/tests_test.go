@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/tooltest"
+)
+
+func TestTestTools(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	if err := TestTools(nil); err != nil {
+		t.Errorf("TestTools with no saved tools should not fail: %v", err)
+	}
+
+	persistence.SaveTool(&persistence.SavedToolDefinition{
+		Name: "double",
+		Code: `params["n"] * 2`,
+		Tests: []persistence.ToolTest{
+			{Name: "doubles", Params: map[string]interface{}{"n": 2}, Expected: 4},
+		},
+	})
+
+	if err := TestTools([]string{"double"}); err != nil {
+		t.Errorf("Expected passing tests, got %v", err)
+	}
+
+	persistence.SaveTool(&persistence.SavedToolDefinition{
+		Name: "broken",
+		Code: `params["n"] * 3`,
+		Tests: []persistence.ToolTest{
+			{Name: "doubles", Params: map[string]interface{}{"n": 2}, Expected: 4},
+		},
+	})
+
+	if err := TestTools(nil); err == nil {
+		t.Error("Expected failure when a test fails")
+	}
+
+	if err := TestTools([]string{"missing"}); err == nil {
+		t.Error("Expected error for unknown tool")
+	}
+}
+
+func TestTestTools_Snapshots(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	dir := t.TempDir()
+	toolDir := filepath.Join(dir, "double")
+	os.MkdirAll(toolDir, 0755)
+	os.WriteFile(filepath.Join(toolDir, "tool.json"), []byte(`{"code": "params[\"n\"] * 2"}`), 0644)
+	os.WriteFile(filepath.Join(toolDir, "two.json"), []byte(`{"params": {"n": 2}}`), 0644)
+
+	if err := TestTools([]string{"--snapshots", dir}); err == nil {
+		t.Error("Expected failure without golden files")
+	}
+	if err := TestTools([]string{"--snapshots", dir, "--update"}); err != nil {
+		t.Fatalf("Expected golden files to be written, got %v", err)
+	}
+	if err := TestTools([]string{"--snapshots", dir}); err != nil {
+		t.Errorf("Expected snapshots to pass after update, got %v", err)
+	}
+	if err := TestTools([]string{"--update"}); err == nil {
+		t.Error("Expected usage error for --update without --snapshots")
+	}
+}
+
+func TestTestTools_Fixtures(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	persistence.SaveTool(&persistence.SavedToolDefinition{
+		Name: "issue_title",
+		Code: `github.get_issue({"number": 1})["structured"]["title"]`,
+		Tests: []persistence.ToolTest{
+			{Name: "title", Expected: "Crash on start"},
+		},
+	})
+	fixtures := filepath.Join(t.TempDir(), "fixtures.json")
+	os.WriteFile(fixtures, []byte(`{"github.get_issue": {"title": "Crash on start"}}`), 0644)
+
+	if err := TestTools([]string{"--fixtures", fixtures}); err != nil {
+		t.Errorf("Expected tests to pass against fixtures, got %v", err)
+	}
+
+	t.Setenv(tooltest.FixturesEnvVar, fixtures)
+	if err := TestTools([]string{"issue_title"}); err != nil {
+		t.Errorf("Expected tests to pass against fixtures from the environment, got %v", err)
+	}
+
+	if err := TestTools([]string{"--fixtures", filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Error("Expected error for a missing fixtures file")
+	}
+}
@@ -0,0 +1,67 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/paths"
+)
+
+const validateUsage = "usage: mcp-metatool validate [--schema] [servers.json]"
+
+// Exit codes returned by the validate command
+const (
+	validateExitProblems   = 1 // the config has errors
+	validateExitUnreadable = 2 // the config could not be read or parsed
+)
+
+// ValidateConfig checks a servers.json file and reports problems per server
+func ValidateConfig(args []string) error {
+	flags := flag.NewFlagSet("validate", flag.ContinueOnError)
+	printSchema := flags.Bool("schema", false, "print the servers.json JSON schema and exit")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 1 {
+		return fmt.Errorf(validateUsage)
+	}
+
+	if *printSchema {
+		fmt.Println(string(config.Schema))
+		return nil
+	}
+
+	configPath := flags.Arg(0)
+	if configPath == "" {
+		defaultPath, err := paths.GetConfigPath()
+		if err != nil {
+			return err
+		}
+		configPath = defaultPath
+	}
+
+	problems, err := config.Check(configPath)
+	if err != nil {
+		return &ExitError{Code: validateExitUnreadable, Err: err}
+	}
+
+	errorCount := 0
+	for _, problem := range problems {
+		scope := "config"
+		if problem.Server != "" {
+			scope = problem.Server
+		}
+
+		color := colorYellow
+		if problem.Severity == config.SeverityError {
+			color = colorRed
+			errorCount++
+		}
+		fmt.Printf("%s %s: %s\n", colorize(problem.Severity+":", color), scope, problem.Message)
+	}
+
+	if errorCount > 0 {
+		return &ExitError{Code: validateExitProblems, Err: fmt.Errorf("%s has %d error(s)", configPath, errorCount)}
+	}
+
+	fmt.Printf("%s is valid\n", configPath)
+	return nil
+}
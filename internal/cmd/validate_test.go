@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateConfig(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+
+	valid := filepath.Join(dir, "valid.json")
+	os.WriteFile(valid, []byte(`{"mcpServers": {"echo": {"command": "echo"}}}`), 0644)
+	invalid := filepath.Join(dir, "invalid.json")
+	os.WriteFile(invalid, []byte(`{"mcpServers": {"ghost": {"command": "definitely-not-a-real-command-xyz"}}}`), 0644)
+	broken := filepath.Join(dir, "broken.json")
+	os.WriteFile(broken, []byte(`{`), 0644)
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		output   string
+	}{
+		{"valid", []string{valid}, 0, "is valid"},
+		{"problems", []string{invalid}, validateExitProblems, "ghost: command"},
+		{"unreadable", []string{broken}, validateExitUnreadable, ""},
+		{"default path missing", nil, validateExitUnreadable, ""},
+		{"usage", []string{valid, invalid}, 1, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var code int
+			output := captureStdout(t, func() {
+				code = Run(append([]string{"validate"}, tt.args...))
+			})
+			if code != tt.wantCode {
+				t.Errorf("Expected exit code %d, got %d", tt.wantCode, code)
+			}
+			if !strings.Contains(output, tt.output) {
+				t.Errorf("Expected output containing %q, got %q", tt.output, output)
+			}
+		})
+	}
+}
+
+func TestValidateConfig_PrintSchema(t *testing.T) {
+	var err error
+	output := captureStdout(t, func() {
+		err = ValidateConfig([]string{"--schema"})
+	})
+	if err != nil {
+		t.Fatalf("ValidateConfig(--schema) error = %v", err)
+	}
+	if !json.Valid([]byte(output)) {
+		t.Errorf("Expected schema JSON, got %q", output)
+	}
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/verify"
+)
+
+const verifyUsage = "usage: mcp-metatool verify [--json] [tool...]"
+
+// Verify compiles the named saved tools, or every saved tool if none are named, and checks the
+// servers and tools they call against the config and the servers' discovered tools
+func Verify(args []string) error {
+	flags := flag.NewFlagSet("verify", flag.ContinueOnError)
+	jsonOutput := flags.Bool("json", false, "print the report as JSON")
+	if err := flags.Parse(args); err != nil {
+		return fmt.Errorf(verifyUsage)
+	}
+
+	var tools []*persistence.SavedToolDefinition
+	if flags.NArg() == 0 {
+		all, err := persistence.ListTools()
+		if err != nil {
+			return fmt.Errorf("failed to list saved tools: %w", err)
+		}
+		tools = all
+	} else {
+		for _, name := range flags.Args() {
+			tool, err := persistence.LoadTool(name)
+			if err != nil {
+				return fmt.Errorf("failed to load tool '%s': %w", name, err)
+			}
+			tools = append(tools, tool)
+		}
+	}
+
+	var proxyManager proxy.ProxyManager
+	var opts []starlark.Option
+	cfg, manager, err := startProxyManager()
+	if err != nil {
+		logging.Warnf("Verifying without proxied servers: %v", err)
+	} else {
+		defer manager.Stop()
+		proxyManager = manager
+		opts = starlarkOptions(cfg)
+	}
+
+	report := verify.Tools(tools, proxyManager, opts...)
+	if *jsonOutput {
+		if err := printJSON(report); err != nil {
+			return err
+		}
+	} else {
+		fmt.Println(report.Summary())
+	}
+	if len(report.Problems) > 0 {
+		return &ExitError{Code: 1, Err: fmt.Errorf("%d problem(s) found", len(report.Problems))}
+	}
+	return nil
+}
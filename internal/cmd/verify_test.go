@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+)
+
+func TestVerify(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "greet", Description: "Greet", Code: `"Hello, " + params["name"]`})
+
+	var err error
+	output := captureStdout(t, func() {
+		err = Verify(nil)
+	})
+	if err != nil || !strings.Contains(output, "Verified 1 saved tool(s): no problems found") {
+		t.Errorf("Verify() = %q, %v", output, err)
+	}
+
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "fetch", Description: "Fetch", Code: `github.get_issue(number=1)`})
+	output = captureStdout(t, func() {
+		err = Verify([]string{"--json", "fetch"})
+	})
+	var exitErr *ExitError
+	if !errors.As(err, &exitErr) || exitErr.Code != 1 {
+		t.Errorf("Expected exit code 1 for problems, got %v", err)
+	}
+	if !strings.Contains(output, `"kind": "unknown_server"`) {
+		t.Errorf("Expected a JSON report of the unknown server, got %q", output)
+	}
+
+	if code := Run([]string{"--verify", "missing"}); code != 1 {
+		t.Errorf("Run(--verify missing) = %d, want 1", code)
+	}
+}
@@ -0,0 +1,199 @@
+package completion
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	gostarlark "go.starlark.net/starlark"
+
+	"github.com/dslh/mcp-metatool/internal/starlark"
+)
+
+// Bundle is the completion data consumed by editor plugins
+type Bundle struct {
+	Namespaces []Namespace `json:"namespaces"`
+	Modules    []Module    `json:"modules"`
+	Builtins   []string    `json:"builtins"`
+	Globals    []string    `json:"globals"`
+	Snippets   []Snippet   `json:"snippets"`
+}
+
+// Namespace describes the tools of one upstream server
+type Namespace struct {
+	Name   string          `json:"name"`   // Starlark identifier
+	Server string          `json:"server"` // configured server name
+	Tools  []ToolSignature `json:"tools"`
+}
+
+// ToolSignature describes how to call a tool from Starlark
+type ToolSignature struct {
+	Name        string      `json:"name"`
+	Description string      `json:"description,omitempty"`
+	Signature   string      `json:"signature"`
+	Parameters  []Parameter `json:"parameters"`
+}
+
+// Parameter describes a single tool parameter
+type Parameter struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required"`
+}
+
+// Module describes a predeclared module and its members
+type Module struct {
+	Name    string   `json:"name"`
+	Members []string `json:"members"`
+}
+
+// Snippet is a reusable code template
+type Snippet struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Body        string `json:"body"`
+}
+
+// Build assembles completion data from the connected servers and the Starlark environment
+// The proxyManager parameter is optional; pass nil to omit server namespaces
+func Build(proxyManager starlark.ProxyManager, opts ...starlark.Option) *Bundle {
+	bundle := &Bundle{
+		Namespaces: []Namespace{},
+		Modules:    []Module{},
+		Builtins:   []string{},
+		Globals:    []string{"params"},
+		Snippets:   snippets,
+	}
+
+	if proxyManager != nil {
+		for serverName, tools := range proxyManager.GetAllTools() {
+			bundle.Namespaces = append(bundle.Namespaces, buildNamespace(proxyManager, serverName, tools))
+		}
+		sort.Slice(bundle.Namespaces, func(i, j int) bool {
+			return bundle.Namespaces[i].Name < bundle.Namespaces[j].Name
+		})
+	}
+
+	for name, value := range starlark.Globals(opts...) {
+		if module, ok := value.(gostarlark.HasAttrs); ok {
+			members := module.AttrNames()
+			sort.Strings(members)
+			bundle.Modules = append(bundle.Modules, Module{Name: name, Members: members})
+		} else {
+			bundle.Builtins = append(bundle.Builtins, name)
+		}
+	}
+	sort.Slice(bundle.Modules, func(i, j int) bool {
+		return bundle.Modules[i].Name < bundle.Modules[j].Name
+	})
+	sort.Strings(bundle.Builtins)
+
+	return bundle
+}
+
+// buildNamespace describes a server's tools, sorted by name
+func buildNamespace(proxyManager starlark.ProxyManager, serverName string, tools []*mcp.Tool) Namespace {
+	namespace := Namespace{
+		Name:   starlark.NamespaceName(proxyManager, serverName),
+		Server: serverName,
+		Tools:  make([]ToolSignature, 0, len(tools)),
+	}
+
+	for name, tool := range starlark.NamespaceTools(proxyManager, serverName, tools) {
+		params := parameters(tool.InputSchema)
+		namespace.Tools = append(namespace.Tools, ToolSignature{
+			Name:        name,
+			Description: tool.Description,
+			Signature:   signature(namespace.Name, name, params),
+			Parameters:  params,
+		})
+	}
+
+	sort.Slice(namespace.Tools, func(i, j int) bool {
+		return namespace.Tools[i].Name < namespace.Tools[j].Name
+	})
+	return namespace
+}
+
+// parameters lists the top-level properties of an input schema, required ones first
+func parameters(schema *jsonschema.Schema) []Parameter {
+	params := []Parameter{}
+	if schema == nil {
+		return params
+	}
+
+	required := make(map[string]bool)
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	for name, property := range schema.Properties {
+		param := Parameter{Name: name, Required: required[name]}
+		if property != nil {
+			param.Description = property.Description
+			param.Type = property.Type
+			if param.Type == "" && len(property.Types) > 0 {
+				param.Type = strings.Join(property.Types, "|")
+			}
+		}
+		params = append(params, param)
+	}
+
+	sort.Slice(params, func(i, j int) bool {
+		if params[i].Required != params[j].Required {
+			return params[i].Required
+		}
+		return params[i].Name < params[j].Name
+	})
+	return params
+}
+
+// signature renders a keyword-argument call signature, marking optional parameters with =None
+func signature(namespace, tool string, params []Parameter) string {
+	args := make([]string, len(params))
+	for i, param := range params {
+		if param.Required {
+			args[i] = param.Name
+		} else {
+			args[i] = param.Name + "=None"
+		}
+	}
+	return fmt.Sprintf("%s.%s(%s)", namespace, tool, strings.Join(args, ", "))
+}
+
+// snippets are common patterns when writing composite tools
+var snippets = []Snippet{
+	{
+		Name:        "tool-call",
+		Description: "Call an upstream tool and read its structured result",
+		Body:        "response = ${1:server}.${2:tool}(${3:arg}=${4:value})\nresult = response.get(\"structured\", response.get(\"content\"))",
+	},
+	{
+		Name:        "json-content",
+		Description: "Decode the first text content block of a tool response as JSON",
+		Body:        "data = json.decode(${1:response}[\"content\"][0])",
+	},
+	{
+		Name:        "param-default",
+		Description: "Read an optional parameter with a default value",
+		Body:        "${1:name} = params.get(\"${1:name}\", ${2:default})",
+	},
+	{
+		Name:        "map-results",
+		Description: "Transform each item of a list into a summary dict",
+		Body:        "result = [{\"${2:key}\": item[\"${2:key}\"]} for item in ${1:items}]",
+	},
+	{
+		Name:        "parallel-calls",
+		Description: "Call an upstream tool for each item concurrently, keeping results in order",
+		Body:        "responses = parallel([(${1:server}.${2:tool}, {\"${3:id}\": item}) for item in ${4:items}], max_workers=${5:5})",
+	},
+	{
+		Name:        "publish-csv",
+		Description: "Publish rows as a downloadable CSV artifact",
+		Body:        "rows = [\",\".join([str(v) for v in row]) for row in ${1:rows}]\npublish_artifact(\"${2:report}.csv\", \"\\n\".join(rows), mime=\"text/csv\")",
+	},
+}
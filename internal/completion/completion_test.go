@@ -0,0 +1,100 @@
+package completion
+
+import (
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	gostarlark "go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/dslh/mcp-metatool/internal/starlark"
+)
+
+type fakeProxy struct{}
+
+func (fakeProxy) GetAllTools() map[string][]*mcp.Tool {
+	return map[string][]*mcp.Tool{
+		"git-hub": {
+			{
+				Name:        "get_issue",
+				Description: "Fetch an issue",
+				InputSchema: &jsonschema.Schema{
+					Type: "object",
+					Properties: map[string]*jsonschema.Schema{
+						"repo":         {Type: "string", Description: "Repository name"},
+						"issue_number": {Type: "integer"},
+						"include_body": {Type: "boolean"},
+					},
+					Required: []string{"repo", "issue_number"},
+				},
+			},
+			{Name: "list_repos"},
+		},
+	}
+}
+
+func (fakeProxy) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return nil, nil
+}
+
+func TestBuild_Namespaces(t *testing.T) {
+	bundle := Build(fakeProxy{})
+
+	if len(bundle.Namespaces) != 1 {
+		t.Fatalf("Expected 1 namespace, got %d", len(bundle.Namespaces))
+	}
+	namespace := bundle.Namespaces[0]
+	if namespace.Name != "git_hub" || namespace.Server != "git-hub" {
+		t.Errorf("Expected normalized namespace name, got %+v", namespace)
+	}
+	if len(namespace.Tools) != 2 {
+		t.Fatalf("Expected 2 tools, got %d", len(namespace.Tools))
+	}
+
+	getIssue := namespace.Tools[0]
+	expected := "git_hub.get_issue(issue_number, repo, include_body=None)"
+	if getIssue.Signature != expected {
+		t.Errorf("Expected signature %q, got %q", expected, getIssue.Signature)
+	}
+	if getIssue.Parameters[1].Description != "Repository name" || getIssue.Parameters[1].Type != "string" {
+		t.Errorf("Unexpected parameter: %+v", getIssue.Parameters[1])
+	}
+
+	if namespace.Tools[1].Signature != "git_hub.list_repos()" {
+		t.Errorf("Expected empty signature for schemaless tool, got %q", namespace.Tools[1].Signature)
+	}
+}
+
+func TestBuild_Environment(t *testing.T) {
+	extra := &starlarkstruct.Module{Name: "extra", Members: gostarlark.StringDict{"ping": gostarlark.None}}
+	bundle := Build(nil, starlark.WithModule("extra", extra))
+
+	if len(bundle.Namespaces) != 0 {
+		t.Errorf("Expected no namespaces without a proxy manager, got %d", len(bundle.Namespaces))
+	}
+
+	modules := make(map[string][]string)
+	for _, module := range bundle.Modules {
+		modules[module.Name] = module.Members
+	}
+	for _, name := range []string{"json", "math", "time", "extra"} {
+		if len(modules[name]) == 0 {
+			t.Errorf("Expected module %s with members, got %v", name, modules[name])
+		}
+	}
+
+	builtins := make(map[string]bool)
+	for _, name := range bundle.Builtins {
+		builtins[name] = true
+	}
+	for _, name := range []string{"len", "publish_artifact"} {
+		if !builtins[name] {
+			t.Errorf("Expected builtin %s", name)
+		}
+	}
+
+	if len(bundle.Snippets) == 0 {
+		t.Error("Expected snippets")
+	}
+}
@@ -0,0 +1,196 @@
+package config
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"sort"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// Schema is the published JSON schema for servers.json
+//
+//go:embed servers.schema.json
+var Schema []byte
+
+// Problem severities reported by Check
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Problem is an issue found while checking a configuration file
+type Problem struct {
+	Server   string `json:"server,omitempty"` // empty for problems not specific to one server
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// Check validates a configuration file against the schema, verifies that environment variable
+// references resolve and that server commands exist on PATH. It returns an error only if the
+// file cannot be read or parsed.
+func Check(configPath string) ([]Problem, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	var document interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
+	}
+
+	var problems []Problem
+	if err := validateSchema(document); err != nil {
+		problems = append(problems, Problem{Severity: SeverityError, Message: fmt.Sprintf("schema: %v", err)})
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		// Type mismatches are already reported by the schema check
+		return problems, nil
+	}
+
+	if err := cfg.Validate(); err != nil {
+		problems = append(problems, Problem{Severity: SeverityError, Message: err.Error()})
+	}
+
+	serverNames := make([]string, 0, len(cfg.MCPServers))
+	for name := range cfg.MCPServers {
+		serverNames = append(serverNames, name)
+	}
+	sort.Strings(serverNames)
+
+	for _, name := range serverNames {
+		problems = append(problems, checkServer(name, cfg.MCPServers[name])...)
+	}
+
+	if cfg.Notify != nil {
+		for _, value := range notifyStrings(cfg.Notify) {
+			for _, missing := range unresolvedVars(value) {
+				problems = append(problems, Problem{
+					Severity: SeverityError,
+					Message:  fmt.Sprintf("notify references unset environment variable %s", missing),
+				})
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+// checkServer reports unresolved environment variables and missing commands for one server
+func checkServer(name string, serverConfig MCPServerConfig) []Problem {
+	var problems []Problem
+	report := func(severity, format string, args ...interface{}) {
+		problems = append(problems, Problem{Server: name, Severity: severity, Message: fmt.Sprintf(format, args...)})
+	}
+
+	values := append([]string{serverConfig.Command, serverConfig.Cwd}, serverConfig.Args...)
+	values = append(values, sortedValues(serverConfig.Env)...)
+	if docker := serverConfig.Docker; docker != nil {
+		values = append(values, docker.Image)
+		values = append(values, docker.Volumes...)
+		values = append(values, sortedValues(docker.Env)...)
+	}
+	values = append(values, serverConfig.URL)
+	if oauth := serverConfig.OAuth; oauth != nil {
+		values = append(values, oauth.TokenURL, oauth.DeviceAuthorizationURL, oauth.ClientID, oauth.ClientSecret)
+	}
+
+	seen := make(map[string]bool)
+	for _, value := range values {
+		for _, missing := range unresolvedVars(value) {
+			if !seen[missing] {
+				seen[missing] = true
+				report(SeverityError, "references unset environment variable %s", missing)
+			}
+		}
+	}
+
+	command, _ := expandString(serverConfig.Command)
+	switch {
+	case serverConfig.URL != "":
+		command = "" // remote servers aren't launched
+	case serverConfig.Docker != nil:
+		command = "docker"
+	}
+	if command != "" {
+		if _, err := exec.LookPath(command); err != nil {
+			report(SeverityError, "command %q not found on PATH", command)
+		}
+	}
+
+	cwd, _ := expandString(serverConfig.Cwd)
+	if cwd != "" {
+		if info, err := os.Stat(cwd); err != nil || !info.IsDir() {
+			report(SeverityError, "cwd %q is not a directory", cwd)
+		}
+	}
+
+	if len(serverConfig.PassEnv) > 0 && serverConfig.InheritsEnv() {
+		report(SeverityWarning, "passEnv has no effect unless inheritEnv is false")
+	}
+
+	if serverConfig.Hidden && (len(serverConfig.AllowedTools) > 0 || len(serverConfig.HiddenTools) > 0) {
+		report(SeverityWarning, "tool filtering has no effect on a hidden server")
+	}
+
+	return problems
+}
+
+// sortedValues returns a map's values ordered by key, so problems are reported in a stable order
+func sortedValues(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	values := make([]string, len(keys))
+	for i, key := range keys {
+		values[i] = m[key]
+	}
+	return values
+}
+
+// unresolvedVars returns the ${VAR} references in s whose variables are unset or empty
+func unresolvedVars(s string) []string {
+	var missing []string
+	for _, match := range envVarPattern.FindAllStringSubmatch(s, -1) {
+		if os.Getenv(match[1]) == "" {
+			missing = append(missing, match[1])
+		}
+	}
+	return missing
+}
+
+// notifyStrings returns the notify settings that support ${VAR} expansion
+func notifyStrings(notify *NotifyConfig) []string {
+	var values []string
+	for _, url := range notify.Webhooks {
+		values = append(values, url)
+	}
+	if smtp := notify.SMTP; smtp != nil {
+		values = append(values, smtp.Host, smtp.Username, smtp.Password, smtp.From)
+	}
+	sort.Strings(values)
+	return values
+}
+
+// validateSchema validates a decoded configuration document against the published schema
+func validateSchema(document interface{}) error {
+	var schema jsonschema.Schema
+	if err := json.Unmarshal(Schema, &schema); err != nil {
+		return fmt.Errorf("invalid embedded schema: %w", err)
+	}
+
+	resolved, err := schema.Resolve(nil)
+	if err != nil {
+		return fmt.Errorf("failed to resolve schema: %w", err)
+	}
+
+	return resolved.Validate(document)
+}
@@ -0,0 +1,184 @@
+package config
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	t.Helper()
+	configPath := filepath.Join(t.TempDir(), "servers.json")
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+	return configPath
+}
+
+func TestCheck(t *testing.T) {
+	t.Setenv("CHECK_TEST_TOKEN", "secret")
+	t.Setenv("CHECK_TEST_MISSING", "")
+
+	tests := []struct {
+		name     string
+		config   string
+		expected []string // substrings of "server: severity: message" for each problem
+	}{
+		{
+			"valid",
+			`{"mcpServers": {"echo": {"command": "echo", "env": {"TOKEN": "${CHECK_TEST_TOKEN}"}}}}`,
+			nil,
+		},
+		{
+			"unknown field",
+			`{"mcpServers": {"echo": {"command": "echo", "comand": "x"}}}`,
+			[]string{": error: schema:"},
+		},
+		{
+			"wrong type",
+			`{"mcpServers": {"echo": {"command": "echo", "args": "--flag"}}}`,
+			[]string{": error: schema:"},
+		},
+		{
+			"result limits",
+			`{"mcpServers": {"echo": {"command": "echo"}}, "resultLimits": {"maxBytes": 65536}}`,
+			nil,
+		},
+		{
+			"misspelled result limit",
+			`{"mcpServers": {"echo": {"command": "echo"}}, "resultLimits": {"maxByte": 65536}}`,
+			[]string{": error: schema:"},
+		},
+		{
+			"unset env var",
+			`{"mcpServers": {"echo": {"command": "echo", "args": ["${CHECK_TEST_MISSING}"]}}}`,
+			[]string{"echo: error: references unset environment variable CHECK_TEST_MISSING"},
+		},
+		{
+			"missing command",
+			`{"mcpServers": {"ghost": {"command": "definitely-not-a-real-command-xyz"}}}`,
+			[]string{`ghost: error: command "definitely-not-a-real-command-xyz" not found on PATH`},
+		},
+		{
+			"missing cwd",
+			`{"mcpServers": {"echo": {"command": "echo", "cwd": "/definitely/not/a/real/dir"}}}`,
+			[]string{`echo: error: cwd "/definitely/not/a/real/dir" is not a directory`},
+		},
+		{
+			"passEnv while inheriting",
+			`{"mcpServers": {"echo": {"command": "echo", "passEnv": ["HOME"]}}}`,
+			[]string{"echo: warning: passEnv has no effect unless inheritEnv is false"},
+		},
+		{
+			"filtering on hidden server",
+			`{"mcpServers": {"echo": {"command": "echo", "hidden": true, "hiddenTools": ["x"]}}}`,
+			[]string{"echo: warning: tool filtering has no effect"},
+		},
+		{
+			"semantic error",
+			`{"mcpServers": {}}`,
+			[]string{": error: no MCP servers configured"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problems, err := Check(writeConfig(t, tt.config))
+			if err != nil {
+				t.Fatalf("Check() error = %v", err)
+			}
+
+			if len(problems) != len(tt.expected) {
+				t.Fatalf("Expected %d problems, got %+v", len(tt.expected), problems)
+			}
+			for i, problem := range problems {
+				line := problem.Server + ": " + problem.Severity + ": " + problem.Message
+				if !strings.Contains(line, tt.expected[i]) {
+					t.Errorf("Expected problem containing %q, got %q", tt.expected[i], line)
+				}
+			}
+		})
+	}
+}
+
+func TestCheck_Unreadable(t *testing.T) {
+	if _, err := Check(filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("Expected error for missing file")
+	}
+	if _, err := Check(writeConfig(t, "{not json")); err == nil {
+		t.Error("Expected error for invalid JSON")
+	}
+}
+
+// TestSchemaCoversConfig guards against adding config fields without updating servers.schema.json
+func TestSchemaCoversConfig(t *testing.T) {
+	sampleRatio := 0.5
+	inheritEnv := false
+	cfg := Config{
+		MCPServers: map[string]MCPServerConfig{
+			"full": {
+				Command:            "echo",
+				Args:               []string{"a"},
+				Env:                map[string]string{"A": "b"},
+				InheritEnv:         &inheritEnv,
+				PassEnv:            []string{"PATH"},
+				Cwd:                "/tmp",
+				ShutdownTimeout:    "10s",
+				Hidden:             true,
+				ToolPrefix:         "f",
+				Primary:            true,
+				ToolAliases:        map[string]string{"a_v2": "a"},
+				AllowedTools:       []string{"a"},
+				HiddenTools:        []string{"b"},
+				ApprovalRequired:   []string{"c"},
+				LazyStart:          true,
+				IdleTimeout:        "10m",
+				PingInterval:       "30s",
+				CorrectArguments:   true,
+				Retry:              &RetryConfig{MaxAttempts: 4, Backoff: "1s", MaxBackoff: "30s", RetryOn: []string{"busy"}},
+				Cache:              []CacheRule{{Tools: "get_*", TTL: "5m"}},
+				TextChunkSize:      1024,
+				MinProtocolVersion: "2025-03-26",
+			},
+			"remote": {
+				URL:   "https://mcp.example.com/mcp",
+				OAuth: &OAuthConfig{Flow: OAuthDevice, TokenURL: "https://auth.example.com/token", DeviceAuthorizationURL: "https://auth.example.com/device", ClientID: "id", ClientSecret: "secret", Scopes: []string{"read"}},
+			},
+			"container": {
+				Args:   []string{"stdio"},
+				Docker: &DockerConfig{Image: "ghcr.io/example/server:1", Volumes: []string{"/data:/data:ro"}, Env: map[string]string{"TOKEN": "t"}, Pull: "always"},
+			},
+		},
+		Notify: &NotifyConfig{
+			Webhooks: map[string]string{"a": "https://example.com"},
+			SMTP:     &SMTPConfig{Host: "smtp", Port: 25, Username: "u", Password: "p", From: "a@b"},
+		},
+		BuiltinTools:        map[string]BuiltinToolConfig{"save_tool": {Name: "save", Description: "d", Hidden: true}},
+		Admin:               &AdminConfig{Socket: "/tmp/admin.sock"},
+		ToolLimits:          &ToolLimits{MaxDescriptionLength: 100, MaxSchemaBytes: 1000},
+		Queue:               &QueueConfig{Workers: 4, MaxPending: 20},
+		ParamLimits:         &ParamLimits{MaxBytes: 1024, MaxDepth: 10},
+		ResultLimits:        &ResultLimits{MaxBytes: 65536},
+		RestrictedModules:   []string{"notify"},
+		ReadOnly:            true,
+		Logging:             &LoggingConfig{Level: "warn", Format: "json", File: "/tmp/metatool.log", MaxSizeMB: 5, MaxFiles: 2},
+		Tracing:             &TracingConfig{Endpoint: "http://localhost:4318", Headers: map[string]string{"x-api-key": "k"}, SampleRatio: &sampleRatio},
+		HealthCheckInterval: "2m",
+		ToolNameSeparator:   "_",
+		ToolStorage:         ToolStorageFiles,
+		ToolSync:            &ToolSyncConfig{Remote: "git@example.com:team/tools.git", Branch: "tools"},
+	}
+
+	data, err := json.Marshal(cfg)
+	if err != nil {
+		t.Fatalf("Failed to marshal config: %v", err)
+	}
+	var document interface{}
+	json.Unmarshal(data, &document)
+
+	if err := validateSchema(document); err != nil {
+		t.Errorf("Fully populated config does not match schema: %v", err)
+	}
+}
@@ -3,28 +3,276 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net/url"
 	"os"
 	"regexp"
+	"slices"
+	"sort"
 	"strings"
+	"time"
 
+	"github.com/dslh/mcp-metatool/internal/logging"
 	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/tracing"
+	"github.com/dslh/mcp-metatool/internal/redact"
+	"github.com/dslh/mcp-metatool/internal/storage"
 )
 
+// DefaultHealthCheckInterval is how often servers are pinged when healthCheckInterval isn't set
+const DefaultHealthCheckInterval = time.Minute
+
+// DefaultShutdownTimeout is how long a stopping server is given at each step when shutdownTimeout isn't set
+const DefaultShutdownTimeout = 5 * time.Second
+
+// Defaults for retry policies that leave settings unset
+const (
+	DefaultRetryAttempts   = 3
+	DefaultRetryBackoff    = 500 * time.Millisecond
+	DefaultRetryMaxBackoff = 10 * time.Second
+)
+
+// DefaultRetryOn matches the errors retried when a retry policy doesn't list its own
+var DefaultRetryOn = []string{
+	`time(d)? ?out`,
+	`temporar`,
+	`unavailable`,
+	`rate.?limit`,
+	`too many requests`,
+	`connection (reset|refused|closed)`,
+	`\b(429|502|503|504)\b`,
+}
+
 // MCPServerConfig represents a single MCP server configuration
 type MCPServerConfig struct {
-	Command      string            `json:"command"`
+	Command      string            `json:"command,omitempty"`
 	Args         []string          `json:"args,omitempty"`
 	Env          map[string]string `json:"env,omitempty"`
+	// Docker runs the server in a container instead of running Command, passing Args to the container
+	Docker *DockerConfig `json:"docker,omitempty"`
+	// URL connects to a remote server over the streamable HTTP transport instead of running Command
+	URL string `json:"url,omitempty"`
+	// OAuth authorizes requests to a remote server with access tokens from its authorization server
+	OAuth *OAuthConfig `json:"oauth,omitempty"`
+	// InheritEnv passes the metatool's own environment to the server; set it to false to pass only
+	// PassEnv and Env. Defaults to true.
+	InheritEnv *bool `json:"inheritEnv,omitempty"`
+	// PassEnv lists variables passed from the metatool's environment when InheritEnv is false
+	PassEnv []string `json:"passEnv,omitempty"`
+	// Cwd is the directory the server is launched in; defaults to the metatool's working directory
+	Cwd string `json:"cwd,omitempty"`
+	// ShutdownTimeout is how long (e.g. "30s") a stopping server is given at each step of shutting down:
+	// after its stdin is closed, then after SIGTERM, before it is killed. Defaults to 5s.
+	ShutdownTimeout string `json:"shutdownTimeout,omitempty"`
 	Hidden       bool              `json:"hidden,omitempty"`
 	AllowedTools []string          `json:"allowedTools,omitempty"`
 	HiddenTools  []string          `json:"hiddenTools,omitempty"`
+	// ApprovalRequired lists tool patterns whose calls must be approved by a human
+	ApprovalRequired []string `json:"approvalRequired,omitempty"`
+	// LazyStart defers launching the server until one of its tools is first used
+	LazyStart bool `json:"lazyStart,omitempty"`
+	// IdleTimeout is a duration (e.g. "10m") after which an unused server is shut down until needed again
+	IdleTimeout string `json:"idleTimeout,omitempty"`
+	// PingInterval is a duration between keepalive pings; unanswered pings drop the session for reconnection
+	PingInterval string `json:"pingInterval,omitempty"`
+	// CorrectArguments retries calls the server rejects as invalid once, with arguments coerced to its tool schemas
+	CorrectArguments bool `json:"correctArguments,omitempty"`
+	// Retry retries calls that fail with transient errors, backing off between attempts
+	Retry *RetryConfig `json:"retry,omitempty"`
+	// Cache memoizes the results of idempotent tools; the first rule matching a tool applies
+	Cache []CacheRule `json:"cache,omitempty"`
+	// TextChunkSize splits text content longer than this many bytes into several blocks; 0 leaves it whole
+	TextChunkSize int `json:"textChunkSize,omitempty"`
+	// MinProtocolVersion is the oldest MCP protocol revision (e.g. "2025-06-18") the server may negotiate
+	MinProtocolVersion string `json:"minProtocolVersion,omitempty"`
+	// ToolPrefix replaces the server name in its proxied tool names and as its Starlark namespace
+	ToolPrefix string `json:"toolPrefix,omitempty"`
+	// Primary advertises the server's tools under their own names, without a prefix; only one server may be primary
+	Primary bool `json:"primary,omitempty"`
+	// ToolAliases exposes upstream tools under other names, in proxied tool names and in Starlark
+	ToolAliases map[string]string `json:"toolAliases,omitempty"`
+}
+
+// CacheRule marks the tools matching a pattern as safe to answer from a cache of recent results
+type CacheRule struct {
+	Tools string `json:"tools"` // tool name pattern, e.g. "get_*"
+	TTL   string `json:"ttl"`   // how long a result is reused, e.g. "5m"
+}
+
+// DockerConfig runs a server in a Docker container, which is removed when the server stops
+type DockerConfig struct {
+	Image string `json:"image"`
+	// Volumes are bind mounts in docker's host:container[:options] form
+	Volumes []string `json:"volumes,omitempty"`
+	// Env sets variables inside the container; the server's own env applies to the docker command
+	Env map[string]string `json:"env,omitempty"`
+	// Pull is when the image is pulled: "missing" (the default), "always" or "never"
+	Pull string `json:"pull,omitempty"`
+}
+
+// DockerPullPolicies are the accepted values of DockerConfig.Pull
+var DockerPullPolicies = []string{"missing", "always", "never"}
+
+// OAuthConfig obtains access tokens for a remote server, refreshing them as they expire
+type OAuthConfig struct {
+	// Flow is "client_credentials" (the default) for service accounts, or "device" to have a user sign in
+	Flow     string `json:"flow,omitempty"`
+	TokenURL string `json:"tokenUrl"`
+	// DeviceAuthorizationURL is where the device flow requests a code for the user to enter
+	DeviceAuthorizationURL string   `json:"deviceAuthorizationUrl,omitempty"`
+	ClientID               string   `json:"clientId"`
+	ClientSecret           string   `json:"clientSecret,omitempty"` // required by the client_credentials flow
+	Scopes                 []string `json:"scopes,omitempty"`
+}
+
+// OAuth flows accepted by OAuthConfig.Flow
+const (
+	OAuthClientCredentials = "client_credentials"
+	OAuthDevice            = "device"
+)
+
+// RetryConfig is a policy for retrying calls to a server that fail with transient errors
+type RetryConfig struct {
+	MaxAttempts int    `json:"maxAttempts,omitempty"` // including the first call, defaults to 3
+	Backoff     string `json:"backoff,omitempty"`     // delay before the first retry, doubled for each one after; defaults to 500ms
+	MaxBackoff  string `json:"maxBackoff,omitempty"`  // longest delay between attempts, defaults to 10s
+	// RetryOn lists case-insensitive regular expressions matched against error messages,
+	// defaulting to DefaultRetryOn
+	RetryOn []string `json:"retryOn,omitempty"`
+}
+
+// NotifyConfig configures the optional Starlark notify module
+type NotifyConfig struct {
+	Webhooks map[string]string `json:"webhooks,omitempty"` // webhook name -> URL
+	SMTP     *SMTPConfig       `json:"smtp,omitempty"`
+}
+
+// SMTPConfig describes the relay used by notify.email
+type SMTPConfig struct {
+	Host     string `json:"host"`
+	Port     int    `json:"port,omitempty"`
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+	From     string `json:"from"`
+}
+
+// AdminConfig enables the local admin API
+type AdminConfig struct {
+	Socket string `json:"socket,omitempty"` // unix socket path, defaults to admin.sock in the metatool directory
+}
+
+// ToolSyncConfig keeps the tools directory in a git repository, shared with other machines through a remote
+type ToolSyncConfig struct {
+	Remote string `json:"remote"`           // URL or path of the git remote
+	Branch string `json:"branch,omitempty"` // defaults to main
+}
+
+// DefaultToolSyncBranch is the branch saved tools are synced on unless one is configured
+const DefaultToolSyncBranch = "main"
+
+// BranchName returns the branch saved tools are synced on
+func (s *ToolSyncConfig) BranchName() string {
+	if s.Branch == "" {
+		return DefaultToolSyncBranch
+	}
+	return s.Branch
+}
+
+// ToolLimits caps the size of the metadata advertised for each tool
+type ToolLimits struct {
+	MaxDescriptionLength int `json:"maxDescriptionLength,omitempty"` // characters; 0 means unlimited
+	MaxSchemaBytes       int `json:"maxSchemaBytes,omitempty"`       // bytes of JSON; 0 means unlimited
+}
+
+// ParamLimits guards against params too large or deeply nested to convert quickly
+type ParamLimits struct {
+	MaxBytes int `json:"maxBytes,omitempty"` // bytes of JSON passed to eval_starlark, defaults to 1 MiB
+	MaxDepth int `json:"maxDepth,omitempty"` // levels of nesting in any value converted to Starlark, defaults to 64
+}
+
+// ResultLimits caps the size of Starlark results returned to clients
+type ResultLimits struct {
+	MaxBytes int `json:"maxBytes,omitempty"` // bytes of JSON beyond which a result is truncated, defaults to 1 MiB
+}
+
+// LoggingConfig sets the level, format and destination of the server log
+type LoggingConfig struct {
+	Level     string `json:"level,omitempty"`     // debug, info, warn or error; defaults to info
+	Format    string `json:"format,omitempty"`    // text or json; defaults to text
+	File      string `json:"file,omitempty"`      // log file path, or "stderr"; defaults to logs/mcp-metatool.log
+	MaxSizeMB int    `json:"maxSizeMB,omitempty"` // size at which the log file is rotated, defaults to 10
+	MaxFiles  int    `json:"maxFiles,omitempty"`  // rotated log files kept, defaults to 3
+}
+
+// Options converts the config to logging options; a nil config leaves everything at its default
+func (l *LoggingConfig) Options() logging.Options {
+	if l == nil {
+		return logging.Options{}
+	}
+	return logging.Options{Level: l.Level, Format: l.Format, File: l.File, MaxSizeMB: l.MaxSizeMB, MaxFiles: l.MaxFiles}
+}
+
+// TracingConfig sends OpenTelemetry traces of tool executions to an OTLP endpoint
+type TracingConfig struct {
+	Endpoint    string            `json:"endpoint,omitempty"`    // OTLP/HTTP endpoint URL, e.g. http://localhost:4318
+	Headers     map[string]string `json:"headers,omitempty"`     // sent with every export
+	SampleRatio *float64          `json:"sampleRatio,omitempty"` // fraction of traces recorded, defaults to 1
+}
+
+// Options converts the config to tracing options; a nil config leaves tracing to the OTEL_* environment variables
+func (t *TracingConfig) Options() tracing.Options {
+	if t == nil {
+		return tracing.Options{}
+	}
+	return tracing.Options{Endpoint: t.Endpoint, Headers: t.Headers, SampleRatio: t.SampleRatio}
+}
+
+// QueueConfig bounds concurrent Starlark executions shared between clients
+type QueueConfig struct {
+	Workers    int `json:"workers,omitempty"`    // concurrent executions, defaults to the number of CPUs
+	MaxPending int `json:"maxPending,omitempty"` // executions allowed to wait, defaults to 100
+}
+
+// BuiltinToolConfig overrides how a built-in tool is exposed to clients
+type BuiltinToolConfig struct {
+	Name        string `json:"name,omitempty"`
+	Description string `json:"description,omitempty"`
+	Hidden      bool   `json:"hidden,omitempty"`
 }
 
 // Config represents the full metatool configuration
 type Config struct {
 	MCPServers map[string]MCPServerConfig `json:"mcpServers"`
+	Notify     *NotifyConfig              `json:"notify,omitempty"`
+	// BuiltinTools maps built-in tool names to overrides of their registration
+	BuiltinTools map[string]BuiltinToolConfig `json:"builtinTools,omitempty"`
+	Admin        *AdminConfig                 `json:"admin,omitempty"`
+	ToolLimits   *ToolLimits                  `json:"toolLimits,omitempty"`
+	Queue        *QueueConfig                 `json:"queue,omitempty"`
+	ParamLimits  *ParamLimits                 `json:"paramLimits,omitempty"`
+	ResultLimits *ResultLimits                `json:"resultLimits,omitempty"`
+	Logging      *LoggingConfig               `json:"logging,omitempty"`
+	Tracing      *TracingConfig               `json:"tracing,omitempty"`
+	// RestrictedModules lists Starlark modules only available to saved tools granted them in permissions
+	RestrictedModules []string `json:"restrictedModules,omitempty"`
+	// HealthCheckInterval is a duration between health check pings of servers without a pingInterval
+	HealthCheckInterval string `json:"healthCheckInterval,omitempty"`
+	// ToolNameSeparator joins each server's prefix to its tool names in proxied tool names; defaults to "__"
+	ToolNameSeparator string `json:"toolNameSeparator,omitempty"`
+	// ToolStorage is where saved tools are kept: "files" in the tools directory (the default) or "sqlite"
+	ToolStorage string `json:"toolStorage,omitempty"`
+	// ToolSync commits changes to saved tools to a git repository that can be synced with a remote
+	ToolSync *ToolSyncConfig `json:"toolSync,omitempty"`
+	// ReadOnly disables the built-in tools that change saved tools or servers, and every upstream tool
+	// not annotated as read-only
+	ReadOnly bool `json:"readOnly,omitempty"`
 }
 
+// Tool storage backends accepted by Config.ToolStorage
+const (
+	ToolStorageFiles  = "files"
+	ToolStorageSQLite = "sqlite"
+)
+
 // GetMetatoolDirectory returns the directory where metatool files are stored
 // Deprecated: Use paths.GetMetatoolDir() instead
 func GetMetatoolDirectory() (string, error) {
@@ -33,6 +281,21 @@ func GetMetatoolDirectory() (string, error) {
 
 // LoadConfig loads and parses the MCP configuration file
 func LoadConfig(configPath string) (*Config, error) {
+	config, err := LoadRawConfig(configPath)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expand environment variables
+	if err := expandEnvVars(config); err != nil {
+		return nil, fmt.Errorf("failed to expand environment variables: %w", err)
+	}
+
+	return config, nil
+}
+
+// LoadRawConfig parses the configuration file as written, leaving ${VAR} references unexpanded
+func LoadRawConfig(configPath string) (*Config, error) {
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
@@ -43,11 +306,6 @@ func LoadConfig(configPath string) (*Config, error) {
 		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
 	}
 
-	// Expand environment variables
-	if err := expandEnvVars(&config); err != nil {
-		return nil, fmt.Errorf("failed to expand environment variables: %w", err)
-	}
-
 	return &config, nil
 }
 
@@ -61,6 +319,19 @@ func LoadDefaultConfig() (*Config, error) {
 	return LoadConfig(configPath)
 }
 
+// WriteConfig saves a configuration as indented JSON, without expanding environment variables
+func WriteConfig(configPath string, config *Config) error {
+	data, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode config: %w", err)
+	}
+
+	if err := storage.WriteDiskFile(configPath, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write config file: %w", err)
+	}
+	return nil
+}
+
 // expandEnvVars performs ${VAR} expansion on all string values in the config
 func expandEnvVars(config *Config) error {
 	for serverName, serverConfig := range config.MCPServers {
@@ -89,10 +360,92 @@ func expandEnvVars(config *Config) error {
 			serverConfig.Env[key] = expanded
 		}
 
+		// Expand container settings
+		if serverConfig.Docker != nil {
+			if err := expandDocker(serverConfig.Docker); err != nil {
+				return fmt.Errorf("error expanding docker settings for server %s: %w", serverName, err)
+			}
+		}
+
+		// Expand remote server settings
+		if serverConfig.URL, err = expandString(serverConfig.URL); err != nil {
+			return fmt.Errorf("error expanding url for server %s: %w", serverName, err)
+		}
+		if oauth := serverConfig.OAuth; oauth != nil {
+			for _, field := range []*string{&oauth.TokenURL, &oauth.DeviceAuthorizationURL, &oauth.ClientID, &oauth.ClientSecret} {
+				if *field, err = expandString(*field); err != nil {
+					return fmt.Errorf("error expanding oauth settings for server %s: %w", serverName, err)
+				}
+			}
+		}
+
+		// Expand working directory
+		expanded, err = expandString(serverConfig.Cwd)
+		if err != nil {
+			return fmt.Errorf("error expanding cwd for server %s: %w", serverName, err)
+		}
+		serverConfig.Cwd = expanded
+
 		// Update the config with expanded values
 		config.MCPServers[serverName] = serverConfig
 	}
 
+	if config.Tracing != nil {
+		for key, value := range config.Tracing.Headers {
+			expanded, err := expandString(value)
+			if err != nil {
+				return fmt.Errorf("error expanding tracing header %s: %w", key, err)
+			}
+			config.Tracing.Headers[key] = expanded
+		}
+	}
+
+	if config.Notify != nil {
+		return expandNotifyEnvVars(config.Notify)
+	}
+
+	return nil
+}
+
+// expandDocker performs ${VAR} expansion on a container's image, volumes and env values
+func expandDocker(docker *DockerConfig) error {
+	var err error
+	if docker.Image, err = expandString(docker.Image); err != nil {
+		return fmt.Errorf("image: %w", err)
+	}
+	for i, volume := range docker.Volumes {
+		if docker.Volumes[i], err = expandString(volume); err != nil {
+			return fmt.Errorf("volume %d: %w", i, err)
+		}
+	}
+	for key, value := range docker.Env {
+		if docker.Env[key], err = expandString(value); err != nil {
+			return fmt.Errorf("env var %s: %w", key, err)
+		}
+	}
+	return nil
+}
+
+// expandNotifyEnvVars performs ${VAR} expansion on webhook URLs and SMTP settings
+func expandNotifyEnvVars(notify *NotifyConfig) error {
+	for name, url := range notify.Webhooks {
+		expanded, err := expandString(url)
+		if err != nil {
+			return fmt.Errorf("error expanding webhook %s: %w", name, err)
+		}
+		notify.Webhooks[name] = expanded
+	}
+
+	if smtp := notify.SMTP; smtp != nil {
+		for _, field := range []*string{&smtp.Host, &smtp.Username, &smtp.Password, &smtp.From} {
+			expanded, err := expandString(*field)
+			if err != nil {
+				return fmt.Errorf("error expanding smtp settings: %w", err)
+			}
+			*field = expanded
+		}
+	}
+
 	return nil
 }
 
@@ -111,7 +464,9 @@ func expandString(s string) (string, error) {
 			// For now, we'll allow empty values but this could be made configurable
 			return ""
 		}
-		
+
+		// Expanded values often hold credentials, so keep them out of logs and errors
+		redact.Register(value)
 		return value
 	}), nil
 }
@@ -121,6 +476,28 @@ func ShouldHideProxiedTools() bool {
 	return os.Getenv("MCP_METATOOL_HIDE_PROXIED_TOOLS") != ""
 }
 
+// ShouldUseEphemeralStorage returns true if persistent data should be kept in memory only
+func ShouldUseEphemeralStorage() bool {
+	return os.Getenv("MCP_METATOOL_EPHEMERAL") != ""
+}
+
+// IsReadOnly returns true if MCP_METATOOL_READ_ONLY is set or the config enables readOnly. cfg may be nil.
+func IsReadOnly(cfg *Config) bool {
+	return os.Getenv("MCP_METATOOL_READ_ONLY") != "" || (cfg != nil && cfg.ReadOnly)
+}
+
+// ToolStorageBackend returns the backend saved tools are kept in: MCP_METATOOL_TOOL_STORAGE if set,
+// otherwise the config's toolStorage. cfg may be nil.
+func ToolStorageBackend(cfg *Config) string {
+	if backend := os.Getenv("MCP_METATOOL_TOOL_STORAGE"); backend != "" {
+		return backend
+	}
+	if cfg == nil {
+		return ""
+	}
+	return cfg.ToolStorage
+}
+
 // Validate checks the configuration for basic validity
 func (c *Config) Validate() error {
 	if len(c.MCPServers) == 0 {
@@ -128,14 +505,254 @@ func (c *Config) Validate() error {
 	}
 
 	for serverName, serverConfig := range c.MCPServers {
-		if strings.TrimSpace(serverConfig.Command) == "" {
+		switch {
+		case serverConfig.URL != "":
+			if serverConfig.Command != "" || serverConfig.Docker != nil {
+				return fmt.Errorf("server %s must have only one of command, docker and url configured", serverName)
+			}
+			if err := validateURL("url", serverConfig.URL); err != nil {
+				return fmt.Errorf("server %s has %w", serverName, err)
+			}
+		case serverConfig.Docker != nil:
+			if serverConfig.Command != "" {
+				return fmt.Errorf("server %s must have only one of command, docker and url configured", serverName)
+			}
+			if err := serverConfig.Docker.Validate(); err != nil {
+				return fmt.Errorf("server %s has invalid docker settings: %w", serverName, err)
+			}
+		case strings.TrimSpace(serverConfig.Command) == "":
 			return fmt.Errorf("server %s has empty command", serverName)
 		}
+		if serverConfig.OAuth != nil {
+			if serverConfig.URL == "" {
+				return fmt.Errorf("server %s has oauth settings but no url", serverName)
+			}
+			if err := serverConfig.OAuth.Validate(); err != nil {
+				return fmt.Errorf("server %s has invalid oauth settings: %w", serverName, err)
+			}
+		}
 
 		// Validate tool filtering configuration
 		if len(serverConfig.AllowedTools) > 0 && len(serverConfig.HiddenTools) > 0 {
 			return fmt.Errorf("server %s cannot have both allowedTools and hiddenTools configured", serverName)
 		}
+
+		if err := validateDuration("idleTimeout", serverConfig.IdleTimeout); err != nil {
+			return fmt.Errorf("server %s has %w", serverName, err)
+		}
+		if err := validateDuration("pingInterval", serverConfig.PingInterval); err != nil {
+			return fmt.Errorf("server %s has %w", serverName, err)
+		}
+		if err := validateDuration("shutdownTimeout", serverConfig.ShutdownTimeout); err != nil {
+			return fmt.Errorf("server %s has %w", serverName, err)
+		}
+		if serverConfig.TextChunkSize < 0 {
+			return fmt.Errorf("server %s has negative textChunkSize", serverName)
+		}
+		if v := serverConfig.MinProtocolVersion; v != "" && !protocolVersionPattern.MatchString(v) {
+			return fmt.Errorf("server %s has invalid minProtocolVersion %q, want a revision date such as 2025-06-18", serverName, v)
+		}
+		if err := serverConfig.Retry.Validate(); err != nil {
+			return fmt.Errorf("server %s has invalid retry policy: %w", serverName, err)
+		}
+		for _, rule := range serverConfig.Cache {
+			if rule.Tools == "" {
+				return fmt.Errorf("server %s has a cache rule without a tools pattern", serverName)
+			}
+			if rule.TTL == "" {
+				return fmt.Errorf("server %s has a cache rule for %s without a ttl", serverName, rule.Tools)
+			}
+			if err := validateDuration("cache ttl", rule.TTL); err != nil {
+				return fmt.Errorf("server %s has %w", serverName, err)
+			}
+		}
+	}
+
+	if c.Notify != nil {
+		if err := c.Notify.Validate(); err != nil {
+			return fmt.Errorf("invalid notify config: %w", err)
+		}
+	}
+
+	if err := c.validateToolNames(); err != nil {
+		return err
+	}
+
+	if err := validateBuiltinTools(c.BuiltinTools, c.ToolSeparator()); err != nil {
+		return fmt.Errorf("invalid builtinTools config: %w", err)
+	}
+
+	if limits := c.ToolLimits; limits != nil && (limits.MaxDescriptionLength < 0 || limits.MaxSchemaBytes < 0) {
+		return fmt.Errorf("toolLimits cannot be negative")
+	}
+
+	if q := c.Queue; q != nil && (q.Workers < 0 || q.MaxPending < 0) {
+		return fmt.Errorf("queue settings cannot be negative")
+	}
+
+	if limits := c.ParamLimits; limits != nil && (limits.MaxBytes < 0 || limits.MaxDepth < 0) {
+		return fmt.Errorf("paramLimits cannot be negative")
+	}
+
+	if limits := c.ResultLimits; limits != nil && limits.MaxBytes < 0 {
+		return fmt.Errorf("resultLimits cannot be negative")
+	}
+
+	for _, name := range c.RestrictedModules {
+		if strings.TrimSpace(name) == "" || strings.Contains(name, ":") {
+			return fmt.Errorf("restrictedModules must list module names, got %q", name)
+		}
+	}
+
+	if err := c.Logging.Options().Validate(); err != nil {
+		return fmt.Errorf("invalid logging config: %w", err)
+	}
+	if err := c.Tracing.Options().Validate(); err != nil {
+		return fmt.Errorf("invalid tracing config: %w", err)
+	}
+
+	if err := validateDuration("healthCheckInterval", c.HealthCheckInterval); err != nil {
+		return err
+	}
+
+	switch c.ToolStorage {
+	case "", ToolStorageFiles, ToolStorageSQLite:
+	default:
+		return fmt.Errorf("unknown toolStorage %q, want %s or %s", c.ToolStorage, ToolStorageFiles, ToolStorageSQLite)
+	}
+
+	if sync := c.ToolSync; sync != nil {
+		if strings.TrimSpace(sync.Remote) == "" {
+			return fmt.Errorf("toolSync requires a remote")
+		}
+		if c.ToolStorage == ToolStorageSQLite {
+			return fmt.Errorf("toolSync requires toolStorage %s", ToolStorageFiles)
+		}
+	}
+
+	return nil
+}
+
+// protocolVersionPattern matches MCP protocol revisions, which are dates that sort in release order
+var protocolVersionPattern = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+
+// validateDuration checks that an optional duration setting is a positive Go duration
+func validateDuration(field, value string) error {
+	if value == "" {
+		return nil
+	}
+	if d, err := time.ParseDuration(value); err != nil || d <= 0 {
+		return fmt.Errorf("invalid %s %q: must be a positive duration such as \"10m\"", field, value)
+	}
+	return nil
+}
+
+// Validate checks that a container has an image and a known pull policy
+func (d *DockerConfig) Validate() error {
+	if strings.TrimSpace(d.Image) == "" {
+		return fmt.Errorf("empty image")
+	}
+	if d.Pull != "" && !slices.Contains(DockerPullPolicies, d.Pull) {
+		return fmt.Errorf("invalid pull policy %q, want one of %s", d.Pull, strings.Join(DockerPullPolicies, ", "))
+	}
+	return nil
+}
+
+// Validate checks that a flow has the endpoints and credentials it needs
+func (o *OAuthConfig) Validate() error {
+	switch o.Flow {
+	case "", OAuthClientCredentials:
+		if o.ClientSecret == "" {
+			return fmt.Errorf("the client_credentials flow requires a clientSecret")
+		}
+	case OAuthDevice:
+		if o.DeviceAuthorizationURL == "" {
+			return fmt.Errorf("the device flow requires a deviceAuthorizationUrl")
+		}
+		if err := validateURL("deviceAuthorizationUrl", o.DeviceAuthorizationURL); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unknown flow %q, want %s or %s", o.Flow, OAuthClientCredentials, OAuthDevice)
+	}
+	if o.ClientID == "" {
+		return fmt.Errorf("empty clientId")
+	}
+	if err := validateURL("tokenUrl", o.TokenURL); err != nil {
+		return err
+	}
+	return nil
+}
+
+// validateURL checks that a URL setting is absolute http or https
+func validateURL(field, value string) error {
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("invalid %s %q: must be an http or https URL", field, value)
+	}
+	return nil
+}
+
+// Validate checks a retry policy's limits, durations and patterns
+func (r *RetryConfig) Validate() error {
+	if r == nil {
+		return nil
+	}
+	if r.MaxAttempts < 0 {
+		return fmt.Errorf("maxAttempts cannot be negative")
+	}
+	if err := validateDuration("backoff", r.Backoff); err != nil {
+		return err
+	}
+	if err := validateDuration("maxBackoff", r.MaxBackoff); err != nil {
+		return err
+	}
+	for _, pattern := range r.RetryOn {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("invalid retryOn pattern %q: %w", pattern, err)
+		}
+	}
+	return nil
+}
+
+// validateBuiltinTools ensures renamed built-ins don't collide with each other or with proxied tool names
+func validateBuiltinTools(builtins map[string]BuiltinToolConfig, separator string) error {
+	seen := make(map[string]string)
+	for original, override := range builtins {
+		if override.Hidden {
+			continue
+		}
+
+		name := original
+		if override.Name != "" {
+			name = override.Name
+		}
+		if strings.Contains(name, separator) {
+			return fmt.Errorf("name %s for %s cannot contain '%s' (reserved for proxied tools)", name, original, separator)
+		}
+		if other, exists := seen[name]; exists {
+			return fmt.Errorf("%s and %s are both exposed as %s", other, original, name)
+		}
+		seen[name] = original
+	}
+	return nil
+}
+
+// Validate checks the notify configuration for basic validity
+func (n *NotifyConfig) Validate() error {
+	for name, url := range n.Webhooks {
+		if !strings.HasPrefix(url, "http://") && !strings.HasPrefix(url, "https://") {
+			return fmt.Errorf("webhook %s must be an http(s) URL", name)
+		}
+	}
+
+	if n.SMTP != nil {
+		if strings.TrimSpace(n.SMTP.Host) == "" {
+			return fmt.Errorf("smtp host is required")
+		}
+		if strings.TrimSpace(n.SMTP.From) == "" {
+			return fmt.Errorf("smtp from address is required")
+		}
 	}
 
 	return nil
@@ -184,4 +801,146 @@ func (cfg MCPServerConfig) ShouldIncludeTool(toolName string) bool {
 
 	// No filtering configured or not in denylist - include the tool
 	return true
-}
\ No newline at end of file
+}
+
+// RequiresApproval determines if calls to a tool must be approved before they run
+func (cfg MCPServerConfig) RequiresApproval(toolName string) bool {
+	for _, pattern := range cfg.ApprovalRequired {
+		if MatchesPattern(toolName, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// CacheTTL returns how long results of the tool may be cached, or 0 if they may not be
+func (cfg MCPServerConfig) CacheTTL(toolName string) time.Duration {
+	for _, rule := range cfg.Cache {
+		if MatchesPattern(toolName, rule.Tools) {
+			return parsePositiveDuration(rule.TTL)
+		}
+	}
+	return 0
+}
+
+// IdleTimeoutDuration returns the parsed idle timeout, or 0 if the server is never shut down for idleness
+func (cfg MCPServerConfig) IdleTimeoutDuration() time.Duration {
+	return parsePositiveDuration(cfg.IdleTimeout)
+}
+
+// PingIntervalDuration returns the parsed keepalive interval, or 0 if the server is not pinged
+func (cfg MCPServerConfig) PingIntervalDuration() time.Duration {
+	return parsePositiveDuration(cfg.PingInterval)
+}
+
+// ShutdownTimeoutDuration returns how long a stopping server is given at each step of shutting down
+func (cfg MCPServerConfig) ShutdownTimeoutDuration() time.Duration {
+	if d := parsePositiveDuration(cfg.ShutdownTimeout); d > 0 {
+		return d
+	}
+	return DefaultShutdownTimeout
+}
+
+// HealthCheckIntervalDuration returns the interval between health check pings, defaulting to one minute
+func (c *Config) HealthCheckIntervalDuration() time.Duration {
+	if d := parsePositiveDuration(c.HealthCheckInterval); d > 0 {
+		return d
+	}
+	return DefaultHealthCheckInterval
+}
+
+// parsePositiveDuration parses a duration setting, treating empty or invalid values as unset
+func parsePositiveDuration(value string) time.Duration {
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0
+	}
+	return d
+}
+
+// Attempts returns how many times a call may be made in total, or 1 without a retry policy
+func (r *RetryConfig) Attempts() int {
+	switch {
+	case r == nil:
+		return 1
+	case r.MaxAttempts > 0:
+		return r.MaxAttempts
+	}
+	return DefaultRetryAttempts
+}
+
+// Delay returns how long to wait before the given retry, counting from 1
+func (r *RetryConfig) Delay(retry int) time.Duration {
+	backoff := parsePositiveDuration(r.Backoff)
+	if backoff == 0 {
+		backoff = DefaultRetryBackoff
+	}
+	maxBackoff := parsePositiveDuration(r.MaxBackoff)
+	if maxBackoff == 0 {
+		maxBackoff = DefaultRetryMaxBackoff
+	}
+	for i := 1; i < retry && backoff < maxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, maxBackoff)
+}
+
+// Retryable reports whether an error message matches one of the policy's retryOn patterns
+func (r *RetryConfig) Retryable(message string) bool {
+	patterns := r.RetryOn
+	if len(patterns) == 0 {
+		patterns = DefaultRetryOn
+	}
+	for _, pattern := range patterns {
+		if re, err := regexp.Compile("(?i)" + pattern); err == nil && re.MatchString(message) {
+			return true
+		}
+	}
+	return false
+}
+
+// StartsOnDemand reports whether the server may be (re)started when a call needs it
+func (cfg MCPServerConfig) StartsOnDemand() bool {
+	return cfg.LazyStart || cfg.IdleTimeoutDuration() > 0
+}
+
+// InheritsEnv reports whether the server is given the metatool's whole environment
+func (cfg MCPServerConfig) InheritsEnv() bool {
+	return cfg.InheritEnv == nil || *cfg.InheritEnv
+}
+
+// Environ builds the server's environment from the metatool's: all of it, or only the PassEnv
+// variables if InheritEnv is false, followed by the server's Env settings, which take precedence
+func (cfg MCPServerConfig) Environ(parent []string) []string {
+	env := slices.Clip(parent)
+	if !cfg.InheritsEnv() {
+		env = make([]string, 0, len(cfg.PassEnv)+len(cfg.Env))
+		for _, entry := range parent {
+			key, _, _ := strings.Cut(entry, "=")
+			if slices.Contains(cfg.PassEnv, key) {
+				env = append(env, entry)
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(cfg.Env))
+	for key := range cfg.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		env = append(env, fmt.Sprintf("%s=%s", key, cfg.Env[key]))
+	}
+	return env
+}
+
+// Transport names how the metatool connects to the server
+func (cfg MCPServerConfig) Transport() string {
+	if cfg.URL != "" {
+		return "http"
+	}
+	if cfg.Docker != nil {
+		return "docker"
+	}
+	return "stdio"
+}
@@ -1,10 +1,16 @@
 package config
 
 import (
+	"errors"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/redact"
+	"github.com/dslh/mcp-metatool/internal/storage"
 )
 
 func TestLoadConfig(t *testing.T) {
@@ -120,6 +126,52 @@ func TestLoadDefaultConfig(t *testing.T) {
 	}
 }
 
+func TestWriteConfig(t *testing.T) {
+	t.Setenv("GITHUB_TOKEN", "secret")
+	configPath := filepath.Join(t.TempDir(), "servers.json")
+
+	original := &Config{MCPServers: map[string]MCPServerConfig{
+		"github": {Command: "mcp-server-github", Args: []string{"--org", "acme"}, Env: map[string]string{"GITHUB_TOKEN": "${GITHUB_TOKEN}"}},
+	}}
+	if err := WriteConfig(configPath, original); err != nil {
+		t.Fatalf("WriteConfig failed: %v", err)
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read written config: %v", err)
+	}
+	if !strings.Contains(string(data), "${GITHUB_TOKEN}") {
+		t.Errorf("Expected variable reference to be written unexpanded, got %s", data)
+	}
+
+	loaded, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	github := loaded.MCPServers["github"]
+	if github.Command != "mcp-server-github" || len(github.Args) != 2 {
+		t.Errorf("Unexpected server after round trip: %+v", github)
+	}
+	if github.Env["GITHUB_TOKEN"] != "secret" {
+		t.Errorf("Expected expanded token on load, got %q", github.Env["GITHUB_TOKEN"])
+	}
+}
+
+func TestWriteConfigEphemeral(t *testing.T) {
+	dir := t.TempDir()
+	storage.UseMemory()
+	defer storage.UseDisk()
+
+	err := WriteConfig(filepath.Join(dir, "servers.json"), &Config{MCPServers: map[string]MCPServerConfig{"github": {Command: "mcp-server-github"}}})
+	if !errors.Is(err, storage.ErrEphemeral) {
+		t.Errorf("WriteConfig() in ephemeral mode error = %v, want ErrEphemeral", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected nothing on disk in ephemeral mode, got %v", entries)
+	}
+}
+
 func TestGetMetatoolDirectory(t *testing.T) {
 	// Test with custom directory
 	tmpDir := t.TempDir()
@@ -193,6 +245,18 @@ func TestExpandString(t *testing.T) {
 	}
 }
 
+func TestExpandStringRedactsValues(t *testing.T) {
+	defer redact.Reset()
+	t.Setenv("API_TOKEN", "tok_0123456789")
+
+	if _, err := expandString("--token=${API_TOKEN}"); err != nil {
+		t.Fatalf("expandString failed: %v", err)
+	}
+	if got := redact.String("request failed with tok_0123456789"); got != "request failed with [REDACTED]" {
+		t.Errorf("expanded value should be masked, got %q", got)
+	}
+}
+
 func TestConfigValidate(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -217,6 +281,342 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: false,
 		},
+		{
+			name: "valid idle timeout",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", IdleTimeout: "15m"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid idle timeout",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", IdleTimeout: "soon"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid ping interval",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", PingInterval: "0s"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid health check interval",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command"},
+				},
+				HealthCheckInterval: "often",
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid retry policy",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", Retry: &RetryConfig{MaxAttempts: 5, Backoff: "1s", RetryOn: []string{"busy|overloaded"}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid retry pattern",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", Retry: &RetryConfig{RetryOn: []string{"(busy"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid retry backoff",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", Retry: &RetryConfig{Backoff: "quickly"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative text chunk size",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", TextChunkSize: -1},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid min protocol version",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", MinProtocolVersion: "2025-06-18"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid min protocol version",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", MinProtocolVersion: "latest"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid cache rule",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", Cache: []CacheRule{{Tools: "get_*", TTL: "5m"}}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "cache rule without ttl",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", Cache: []CacheRule{{Tools: "get_*"}}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid cwd and shutdown timeout",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", Cwd: "/srv/app", ShutdownTimeout: "30s"},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid shutdown timeout",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", ShutdownTimeout: "0s"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid docker server",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Docker: &DockerConfig{Image: "ghcr.io/example/server", Pull: "never"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "docker server with command",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", Docker: &DockerConfig{Image: "ghcr.io/example/server"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "docker server without image",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Docker: &DockerConfig{}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid docker pull policy",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Docker: &DockerConfig{Image: "ghcr.io/example/server", Pull: "sometimes"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "valid remote server with oauth",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {URL: "https://mcp.example.com/mcp", OAuth: &OAuthConfig{TokenURL: "https://auth.example.com/token", ClientID: "id", ClientSecret: "secret"}},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "remote server with command",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", URL: "https://mcp.example.com/mcp"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid url",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {URL: "mcp.example.com"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "oauth without url",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", OAuth: &OAuthConfig{TokenURL: "https://auth.example.com/token", ClientID: "id", ClientSecret: "secret"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "client credentials without secret",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {URL: "https://mcp.example.com/mcp", OAuth: &OAuthConfig{TokenURL: "https://auth.example.com/token", ClientID: "id"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "device flow without device authorization url",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {URL: "https://mcp.example.com/mcp", OAuth: &OAuthConfig{Flow: OAuthDevice, TokenURL: "https://auth.example.com/token", ClientID: "id"}},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "custom tool prefixes and separator",
+			config: Config{
+				ToolNameSeparator: ".",
+				MCPServers: map[string]MCPServerConfig{
+					"github-enterprise": {Command: "echo", ToolPrefix: "ghe"},
+					"fs":                {Command: "echo", Primary: true},
+				},
+			},
+			wantErr: false,
+		},
+		{
+			name: "invalid tool name separator",
+			config: Config{
+				ToolNameSeparator: "::",
+				MCPServers:        map[string]MCPServerConfig{"test": {Command: "echo"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid tool prefix",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{"test": {Command: "echo", ToolPrefix: "1st"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "tool prefix taken by another server",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"github":            {Command: "echo"},
+					"github-enterprise": {Command: "echo", ToolPrefix: "github"},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "two primary servers",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"a": {Command: "echo", Primary: true},
+					"b": {Command: "echo", Primary: true},
+				},
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid tool alias",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{"test": {Command: "echo", ToolAliases: map[string]string{"search-v2": "search-issues"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "two tools with the same alias",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{"test": {Command: "echo", ToolAliases: map[string]string{"search_v1": "search", "search_v2": "search"}}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "sqlite tool storage",
+			config: Config{
+				ToolStorage: ToolStorageSQLite,
+				MCPServers:  map[string]MCPServerConfig{"test": {Command: "echo"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "unknown tool storage",
+			config: Config{
+				ToolStorage: "postgres",
+				MCPServers:  map[string]MCPServerConfig{"test": {Command: "echo"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "tool sync",
+			config: Config{
+				ToolSync:   &ToolSyncConfig{Remote: "git@example.com:team/tools.git"},
+				MCPServers: map[string]MCPServerConfig{"test": {Command: "echo"}},
+			},
+			wantErr: false,
+		},
+		{
+			name: "tool sync without remote",
+			config: Config{
+				ToolSync:   &ToolSyncConfig{Branch: "main"},
+				MCPServers: map[string]MCPServerConfig{"test": {Command: "echo"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "tool sync with sqlite storage",
+			config: Config{
+				ToolStorage: ToolStorageSQLite,
+				ToolSync:    &ToolSyncConfig{Remote: "/srv/tools.git"},
+				MCPServers:  map[string]MCPServerConfig{"test": {Command: "echo"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "builtin tool renamed with custom separator",
+			config: Config{
+				ToolNameSeparator: "_",
+				MCPServers:        map[string]MCPServerConfig{"test": {Command: "echo"}},
+				BuiltinTools:      map[string]BuiltinToolConfig{"save_tool": {Name: "save_it"}},
+			},
+			wantErr: true,
+		},
+		{
+			name: "negative idle timeout",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command", IdleTimeout: "-5m"},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "no servers",
 			config: Config{
@@ -242,6 +642,16 @@ func TestConfigValidate(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unknown log level",
+			config: Config{
+				MCPServers: map[string]MCPServerConfig{
+					"test": {Command: "test-command"},
+				},
+				Logging: &LoggingConfig{Level: "verbose"},
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
@@ -254,6 +664,88 @@ func TestConfigValidate(t *testing.T) {
 	}
 }
 
+func TestRetryConfig(t *testing.T) {
+	var none *RetryConfig
+	if none.Attempts() != 1 {
+		t.Errorf("Attempts() without a policy = %d, want 1", none.Attempts())
+	}
+
+	defaults := &RetryConfig{}
+	if defaults.Attempts() != DefaultRetryAttempts {
+		t.Errorf("Attempts() = %d, want %d", defaults.Attempts(), DefaultRetryAttempts)
+	}
+	if !defaults.Retryable("calling \"tools/call\": connection closed") || !defaults.Retryable("HTTP 503 Service Unavailable") {
+		t.Error("default patterns should match transient errors")
+	}
+	if defaults.Retryable("repository not found") {
+		t.Error("default patterns should not match permanent errors")
+	}
+
+	policy := &RetryConfig{Backoff: "100ms", MaxBackoff: "300ms", RetryOn: []string{"BUSY"}}
+	delays := []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 300 * time.Millisecond, 300 * time.Millisecond}
+	for i, want := range delays {
+		if got := policy.Delay(i + 1); got != want {
+			t.Errorf("Delay(%d) = %v, want %v", i+1, got, want)
+		}
+	}
+	if !policy.Retryable("server busy") || policy.Retryable("connection reset") {
+		t.Error("retryOn patterns should replace the defaults and match case-insensitively")
+	}
+}
+
+func TestMCPServerConfig_CacheTTL(t *testing.T) {
+	cfg := MCPServerConfig{Cache: []CacheRule{
+		{Tools: "get_secret", TTL: "1s"},
+		{Tools: "get_*", TTL: "5m"},
+	}}
+	tests := map[string]time.Duration{
+		"get_secret":   time.Second,
+		"get_issue":    5 * time.Minute,
+		"create_issue": 0,
+	}
+	for tool, want := range tests {
+		if got := cfg.CacheTTL(tool); got != want {
+			t.Errorf("CacheTTL(%q) = %v, want %v", tool, got, want)
+		}
+	}
+}
+
+func TestMCPServerConfig_Environ(t *testing.T) {
+	inherit, isolate := true, false
+	parent := []string{"HOME=/home/me", "PATH=/usr/bin", "AWS_SECRET_ACCESS_KEY=secret", "TOKEN=old"}
+
+	tests := []struct {
+		name string
+		cfg  MCPServerConfig
+		want []string
+	}{
+		{"inherits by default", MCPServerConfig{}, parent},
+		{"inherits explicitly", MCPServerConfig{InheritEnv: &inherit, Env: map[string]string{"TOKEN": "new"}}, append(slices.Clone(parent), "TOKEN=new")},
+		{"nothing inherited", MCPServerConfig{InheritEnv: &isolate}, []string{}},
+		{"only passed variables", MCPServerConfig{InheritEnv: &isolate, PassEnv: []string{"HOME", "PATH", "UNSET"}}, []string{"HOME=/home/me", "PATH=/usr/bin"}},
+		{"env added to passed variables", MCPServerConfig{InheritEnv: &isolate, PassEnv: []string{"PATH"}, Env: map[string]string{"B": "2", "A": "1"}}, []string{"PATH=/usr/bin", "A=1", "B=2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.cfg.Environ(parent); !slices.Equal(got, tt.want) {
+				t.Errorf("Environ() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+	if len(parent) != 4 || parent[3] != "TOKEN=old" {
+		t.Errorf("Environ() modified the parent environment: %v", parent)
+	}
+}
+
+func TestMCPServerConfig_ShutdownTimeoutDuration(t *testing.T) {
+	if got := (MCPServerConfig{}).ShutdownTimeoutDuration(); got != DefaultShutdownTimeout {
+		t.Errorf("ShutdownTimeoutDuration() = %v, want default %v", got, DefaultShutdownTimeout)
+	}
+	if got := (MCPServerConfig{ShutdownTimeout: "45s"}).ShutdownTimeoutDuration(); got != 45*time.Second {
+		t.Errorf("ShutdownTimeoutDuration() = %v, want 45s", got)
+	}
+}
+
 func TestShouldHideProxiedTools(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -301,6 +793,30 @@ func TestShouldHideProxiedTools(t *testing.T) {
 	}
 }
 
+func TestIsReadOnly(t *testing.T) {
+	tests := []struct {
+		name     string
+		envValue string
+		config   *Config
+		expected bool
+	}{
+		{"neither set", "", &Config{}, false},
+		{"nil config", "", nil, false},
+		{"config enables", "", &Config{ReadOnly: true}, true},
+		{"environment variable set", "1", &Config{}, true},
+		{"environment variable with nil config", "1", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("MCP_METATOOL_READ_ONLY", tt.envValue)
+			if result := IsReadOnly(tt.config); result != tt.expected {
+				t.Errorf("IsReadOnly() = %v, expected %v", result, tt.expected)
+			}
+		})
+	}
+}
+
 func TestLoadConfigWithHiddenField(t *testing.T) {
 	configContent := `{
   "mcpServers": {
@@ -674,4 +1190,169 @@ func TestLoadConfigWithToolFiltering(t *testing.T) {
 	if len(database.HiddenTools) != 0 {
 		t.Errorf("Expected no hidden tools, got %d", len(database.HiddenTools))
 	}
-}
\ No newline at end of file
+}
+func TestMCPServerConfig_RequiresApproval(t *testing.T) {
+	cfg := MCPServerConfig{
+		Command:          "test",
+		ApprovalRequired: []string{"delete_*", "send_payment"},
+	}
+
+	tests := []struct {
+		toolName string
+		want     bool
+	}{
+		{"delete_repo", true},
+		{"send_payment", true},
+		{"send_payment_later", false},
+		{"get_issue", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.toolName, func(t *testing.T) {
+			if got := cfg.RequiresApproval(tt.toolName); got != tt.want {
+				t.Errorf("RequiresApproval(%q) = %v, want %v", tt.toolName, got, tt.want)
+			}
+		})
+	}
+
+	// No patterns configured means nothing is gated
+	if (MCPServerConfig{Command: "test"}).RequiresApproval("delete_repo") {
+		t.Error("Expected no approval requirement without configured patterns")
+	}
+}
+
+func TestLoadConfigWithNotify(t *testing.T) {
+	os.Setenv("TEST_WEBHOOK_TOKEN", "abc123")
+	defer os.Unsetenv("TEST_WEBHOOK_TOKEN")
+
+	configContent := `{
+		"mcpServers": {"echo": {"command": "echo"}},
+		"notify": {
+			"webhooks": {"alerts": "https://hooks.example.com/${TEST_WEBHOOK_TOKEN}"},
+			"smtp": {"host": "smtp.example.com", "port": 25, "from": "bot@example.com"}
+		}
+	}`
+
+	configPath := filepath.Join(t.TempDir(), "servers.json")
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+
+	if cfg.Notify == nil {
+		t.Fatal("Expected notify config to be loaded")
+	}
+	if got := cfg.Notify.Webhooks["alerts"]; got != "https://hooks.example.com/abc123" {
+		t.Errorf("Expected expanded webhook URL, got %s", got)
+	}
+	if cfg.Notify.SMTP.Port != 25 {
+		t.Errorf("Expected smtp port 25, got %d", cfg.Notify.SMTP.Port)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Expected valid config, got %v", err)
+	}
+}
+
+func TestNotifyConfigValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		notify  NotifyConfig
+		wantErr bool
+	}{
+		{"empty", NotifyConfig{}, false},
+		{"valid webhook", NotifyConfig{Webhooks: map[string]string{"a": "https://example.com"}}, false},
+		{"non-http webhook", NotifyConfig{Webhooks: map[string]string{"a": "ftp://example.com"}}, true},
+		{"valid smtp", NotifyConfig{SMTP: &SMTPConfig{Host: "smtp", From: "a@b"}}, false},
+		{"smtp without host", NotifyConfig{SMTP: &SMTPConfig{From: "a@b"}}, true},
+		{"smtp without from", NotifyConfig{SMTP: &SMTPConfig{Host: "smtp"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.notify.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigValidateWithBuiltinTools(t *testing.T) {
+	servers := map[string]MCPServerConfig{"echo": {Command: "echo"}}
+
+	tests := []struct {
+		name     string
+		builtins map[string]BuiltinToolConfig
+		wantErr  bool
+	}{
+		{"none", nil, false},
+		{"rename", map[string]BuiltinToolConfig{"save_tool": {Name: "metatool_save"}}, false},
+		{"hide", map[string]BuiltinToolConfig{"eval_starlark": {Hidden: true}}, false},
+		{"description only", map[string]BuiltinToolConfig{"save_tool": {Description: "Save things"}}, false},
+		{"reserved separator", map[string]BuiltinToolConfig{"save_tool": {Name: "meta__save"}}, true},
+		{
+			"duplicate names",
+			map[string]BuiltinToolConfig{
+				"save_tool":     {Name: "save"},
+				"eval_starlark": {Name: "save"},
+			},
+			true,
+		},
+		{
+			"hidden tools don't collide",
+			map[string]BuiltinToolConfig{
+				"save_tool":     {Name: "save", Hidden: true},
+				"eval_starlark": {Name: "save"},
+			},
+			false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{MCPServers: servers, BuiltinTools: tt.builtins}
+			if err := cfg.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestShouldUseEphemeralStorage(t *testing.T) {
+	t.Setenv("MCP_METATOOL_EPHEMERAL", "")
+	if ShouldUseEphemeralStorage() {
+		t.Error("Expected disk storage when MCP_METATOOL_EPHEMERAL is unset")
+	}
+
+	t.Setenv("MCP_METATOOL_EPHEMERAL", "1")
+	if !ShouldUseEphemeralStorage() {
+		t.Error("Expected ephemeral storage when MCP_METATOOL_EPHEMERAL is set")
+	}
+}
+
+func TestLoadConfigWithTracing(t *testing.T) {
+	t.Setenv("TEST_TRACING_KEY", "k3y")
+
+	configContent := `{
+		"mcpServers": {"echo": {"command": "echo"}},
+		"tracing": {"endpoint": "http://localhost:4318", "headers": {"x-api-key": "${TEST_TRACING_KEY}"}, "sampleRatio": 2}
+	}`
+	configPath := filepath.Join(t.TempDir(), "servers.json")
+	if err := os.WriteFile(configPath, []byte(configContent), 0644); err != nil {
+		t.Fatalf("Failed to write test config: %v", err)
+	}
+
+	cfg, err := LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	if got := cfg.Tracing.Options().Headers["x-api-key"]; got != "k3y" {
+		t.Errorf("Expected expanded tracing header, got %q", got)
+	}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "sampleRatio") {
+		t.Errorf("Expected an invalid sampleRatio error, got %v", err)
+	}
+}
@@ -0,0 +1,29 @@
+package config
+
+import "sync/atomic"
+
+// Live holds the configuration in effect. A reload replaces it as a whole rather than changing it in
+// place, so readers that load it for each request never see a half-updated configuration.
+type Live struct {
+	current atomic.Pointer[Config]
+}
+
+// NewLive holds cfg as the configuration in effect
+func NewLive(cfg *Config) *Live {
+	l := &Live{}
+	l.Store(cfg)
+	return l
+}
+
+// Load returns the configuration in effect, which must not be modified; nil if there is none
+func (l *Live) Load() *Config {
+	if l == nil {
+		return nil
+	}
+	return l.current.Load()
+}
+
+// Store makes cfg the configuration in effect
+func (l *Live) Store(cfg *Config) {
+	l.current.Store(cfg)
+}
@@ -0,0 +1,161 @@
+package config
+
+import (
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// DefaultToolNameSeparator joins server prefixes to tool names unless toolNameSeparator says otherwise
+const DefaultToolNameSeparator = "__"
+
+// toolNameSeparatorPattern allows only characters that are valid in MCP tool names
+var toolNameSeparatorPattern = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// toolPrefixPattern keeps prefixes usable as Starlark identifiers once hyphens become underscores
+var toolPrefixPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_-]*$`)
+
+// toolAliasPattern keeps aliases usable as Starlark attribute names
+var toolAliasPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// ToolSeparator returns the separator between server prefixes and tool names in proxied tool names
+func (c *Config) ToolSeparator() string {
+	if c.ToolNameSeparator != "" {
+		return c.ToolNameSeparator
+	}
+	return DefaultToolNameSeparator
+}
+
+// ToolPrefix returns the prefix of a server's proxied tool names, which also names its Starlark namespace
+func (c *Config) ToolPrefix(serverName string) string {
+	if prefix := c.MCPServers[serverName].ToolPrefix; prefix != "" {
+		return prefix
+	}
+	return serverName
+}
+
+// PrimaryServer returns the server whose tools are advertised without a prefix, if there is one
+func (c *Config) PrimaryServer() string {
+	for serverName, serverConfig := range c.MCPServers {
+		if serverConfig.Primary {
+			return serverName
+		}
+	}
+	return ""
+}
+
+// ProxiedToolName returns the name under which an upstream tool is advertised
+func (c *Config) ProxiedToolName(serverName, toolName string) string {
+	serverConfig := c.MCPServers[serverName]
+	toolName = serverConfig.ToolAlias(toolName)
+	if serverConfig.Primary {
+		return toolName
+	}
+	return c.ToolPrefix(serverName) + c.ToolSeparator() + toolName
+}
+
+// ToolAlias returns the name an upstream tool is exposed under, which is its own unless aliased
+func (cfg MCPServerConfig) ToolAlias(toolName string) string {
+	if alias := cfg.ToolAliases[toolName]; alias != "" {
+		return alias
+	}
+	return toolName
+}
+
+// UpstreamToolName returns the upstream tool exposed under a name, reversing ToolAlias
+func (cfg MCPServerConfig) UpstreamToolName(name string) string {
+	for toolName, alias := range cfg.ToolAliases {
+		if alias == name {
+			return toolName
+		}
+	}
+	return name
+}
+
+// Shadowed reports whether an upstream tool is hidden by another tool's alias taking its name
+func (cfg MCPServerConfig) Shadowed(toolName string) bool {
+	if _, aliased := cfg.ToolAliases[toolName]; aliased {
+		return false
+	}
+	return cfg.UpstreamToolName(toolName) != toolName
+}
+
+// SplitProxiedToolName finds the server and upstream tool a prefixed proxied tool name refers to.
+// The primary server's tools aren't prefixed, so can't be recognized by name.
+func (c *Config) SplitProxiedToolName(name string) (serverName, toolName string, ok bool) {
+	serverNames := make([]string, 0, len(c.MCPServers))
+	for serverName := range c.MCPServers {
+		serverNames = append(serverNames, serverName)
+	}
+	sort.Strings(serverNames)
+
+	// The longest matching prefix wins, so "gh" doesn't claim the tools of "gh__enterprise"
+	longest := -1
+	for _, candidate := range serverNames {
+		if c.MCPServers[candidate].Primary {
+			continue
+		}
+		prefix := c.ToolPrefix(candidate) + c.ToolSeparator()
+		if len(prefix) > longest && len(name) > len(prefix) && strings.HasPrefix(name, prefix) {
+			serverName, toolName, ok = candidate, name[len(prefix):], true
+			longest = len(prefix)
+		}
+	}
+	return serverName, toolName, ok
+}
+
+// validateToolNames checks that the separator and prefixes give every server distinct tool names
+func (c *Config) validateToolNames() error {
+	if c.ToolNameSeparator != "" && !toolNameSeparatorPattern.MatchString(c.ToolNameSeparator) {
+		return fmt.Errorf("invalid toolNameSeparator %q: use letters, digits, underscores, dots and dashes", c.ToolNameSeparator)
+	}
+
+	serverNames := make([]string, 0, len(c.MCPServers))
+	for serverName := range c.MCPServers {
+		serverNames = append(serverNames, serverName)
+	}
+	sort.Strings(serverNames)
+
+	prefixes := make(map[string]string)
+	primary := ""
+	for _, serverName := range serverNames {
+		serverConfig := c.MCPServers[serverName]
+		if serverConfig.Primary {
+			if primary != "" {
+				return fmt.Errorf("servers %s and %s cannot both be primary", primary, serverName)
+			}
+			primary = serverName
+		}
+		if serverConfig.ToolPrefix != "" && !toolPrefixPattern.MatchString(serverConfig.ToolPrefix) {
+			return fmt.Errorf("server %s has invalid toolPrefix %q: use letters, digits, underscores and dashes, not starting with a digit", serverName, serverConfig.ToolPrefix)
+		}
+		if err := validateToolAliases(serverName, serverConfig.ToolAliases); err != nil {
+			return err
+		}
+		prefix := c.ToolPrefix(serverName)
+		if other, exists := prefixes[prefix]; exists {
+			return fmt.Errorf("servers %s and %s both use the tool prefix %s", other, serverName, prefix)
+		}
+		prefixes[prefix] = serverName
+	}
+	return nil
+}
+
+// validateToolAliases checks that a server's aliases are valid names and don't collide with each other
+func validateToolAliases(serverName string, aliases map[string]string) error {
+	toolNames := make(map[string]string, len(aliases))
+	for _, toolName := range slices.Sorted(maps.Keys(aliases)) {
+		alias := aliases[toolName]
+		if !toolAliasPattern.MatchString(alias) {
+			return fmt.Errorf("server %s has invalid alias %q for tool %s: use letters, digits and underscores, not starting with a digit", serverName, alias, toolName)
+		}
+		if other, exists := toolNames[alias]; exists {
+			return fmt.Errorf("server %s aliases both %s and %s as %s", serverName, other, toolName, alias)
+		}
+		toolNames[alias] = toolName
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import "testing"
+
+func TestProxiedToolNames(t *testing.T) {
+	cfg := &Config{
+		ToolNameSeparator: "_",
+		MCPServers: map[string]MCPServerConfig{
+			"github":            {Command: "echo", ToolPrefix: "gh", ToolAliases: map[string]string{"search_issues_v2": "search_issues"}},
+			"github-enterprise": {Command: "echo", ToolPrefix: "gh_ent"},
+			"fs":                {Command: "echo", Primary: true},
+		},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	tests := []struct {
+		server, tool string
+		name         string
+	}{
+		{"github", "get_issue", "gh_get_issue"},
+		{"github", "search_issues_v2", "gh_search_issues"},
+		{"github-enterprise", "get_issue", "gh_ent_get_issue"},
+		{"fs", "read_file", "read_file"},
+	}
+	for _, tt := range tests {
+		if name := cfg.ProxiedToolName(tt.server, tt.tool); name != tt.name {
+			t.Errorf("ProxiedToolName(%q, %q) = %q, want %q", tt.server, tt.tool, name, tt.name)
+		}
+	}
+
+	splits := []struct {
+		name         string
+		server, tool string
+		ok           bool
+	}{
+		{"gh_get_issue", "github", "get_issue", true},
+		{"gh_ent_get_issue", "github-enterprise", "get_issue", true}, // the longest prefix wins
+		{"read_file", "", "", false},
+		{"gh_", "", "", false},
+		{"github__get_issue", "", "", false},
+	}
+	for _, tt := range splits {
+		server, tool, ok := cfg.SplitProxiedToolName(tt.name)
+		if server != tt.server || tool != tt.tool || ok != tt.ok {
+			t.Errorf("SplitProxiedToolName(%q) = %q, %q, %v, want %q, %q, %v", tt.name, server, tool, ok, tt.server, tt.tool, tt.ok)
+		}
+	}
+
+	if primary := cfg.PrimaryServer(); primary != "fs" {
+		t.Errorf("PrimaryServer() = %q, want fs", primary)
+	}
+}
+
+func TestToolAliases(t *testing.T) {
+	serverConfig := MCPServerConfig{ToolAliases: map[string]string{"search_issues_v2": "search_issues"}}
+
+	tests := []struct {
+		tool     string
+		alias    string
+		shadowed bool
+	}{
+		{"search_issues_v2", "search_issues", false},
+		{"search_issues", "search_issues", true},
+		{"get_issue", "get_issue", false},
+	}
+	for _, tt := range tests {
+		if alias := serverConfig.ToolAlias(tt.tool); alias != tt.alias {
+			t.Errorf("ToolAlias(%q) = %q, want %q", tt.tool, alias, tt.alias)
+		}
+		if shadowed := serverConfig.Shadowed(tt.tool); shadowed != tt.shadowed {
+			t.Errorf("Shadowed(%q) = %v, want %v", tt.tool, shadowed, tt.shadowed)
+		}
+	}
+	if upstream := serverConfig.UpstreamToolName("search_issues"); upstream != "search_issues_v2" {
+		t.Errorf("UpstreamToolName(search_issues) = %q, want search_issues_v2", upstream)
+	}
+}
@@ -0,0 +1,279 @@
+// Package gitsync keeps the saved tools directory in a git repository, committing each change to a
+// saved tool and syncing the repository with a remote so tools can be shared across machines and teams.
+package gitsync
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/persistence"
+)
+
+// Identity commits are made under when git has no user configured
+const (
+	defaultUserName  = "mcp-metatool"
+	defaultUserEmail = "mcp-metatool@localhost"
+)
+
+// Repo is the git repository holding the tools directory
+type Repo struct {
+	remote string
+	branch string
+}
+
+// NewRepo creates the repository described by cfg; it is initialized on first use
+func NewRepo(cfg *config.ToolSyncConfig) *Repo {
+	return &Repo{remote: cfg.Remote, branch: cfg.BranchName()}
+}
+
+// ConflictError reports local changes to saved tools that conflict with changes on the remote
+type ConflictError struct {
+	Files []string
+}
+
+func (e *ConflictError) Error() string {
+	return fmt.Sprintf("saved tools changed both locally and on the remote: %s", strings.Join(e.Files, ", "))
+}
+
+// git runs a git command in the tools directory, returning its trimmed output
+func (r *Repo) git(dir string, args ...string) (string, error) {
+	cmd := exec.Command("git", append([]string{"-C", dir}, args...)...)
+	out, err := cmd.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if err != nil {
+		if output != "" {
+			return output, fmt.Errorf("git %s failed: %s", args[0], output)
+		}
+		return output, fmt.Errorf("git %s failed: %w", args[0], err)
+	}
+	return output, nil
+}
+
+// init makes the tools directory a repository with the remote as its origin, returning the directory
+func (r *Repo) init() (string, error) {
+	dir, err := paths.GetToolsDir()
+	if err != nil {
+		return "", err
+	}
+	if _, err := os.Stat(filepath.Join(dir, ".git")); os.IsNotExist(err) {
+		if _, err := r.git(dir, "init", "--initial-branch", r.branch); err != nil {
+			return "", err
+		}
+	}
+	if url, err := r.git(dir, "remote", "get-url", "origin"); err != nil {
+		_, err = r.git(dir, "remote", "add", "origin", r.remote)
+		if err != nil {
+			return "", err
+		}
+	} else if url != r.remote {
+		if _, err := r.git(dir, "remote", "set-url", "origin", r.remote); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// Commit commits every change in the tools directory, doing nothing if there are none
+func (r *Repo) Commit(message string) error {
+	dir, err := r.init()
+	if err != nil {
+		return err
+	}
+	return r.commit(dir, message)
+}
+
+// commit commits every change in an initialized tools directory
+func (r *Repo) commit(dir, message string) error {
+	if _, err := r.git(dir, "add", "--all"); err != nil {
+		return err
+	}
+	status, err := r.git(dir, "status", "--porcelain")
+	if err != nil || status == "" {
+		return err
+	}
+
+	_, err = r.git(dir, r.withIdentity(dir, "commit", "--quiet", "--message", message)...)
+	return err
+}
+
+// withIdentity prefixes a command that makes commits with a default identity if git has no user configured
+func (r *Repo) withIdentity(dir string, args ...string) []string {
+	if email, _ := r.git(dir, "config", "user.email"); email == "" {
+		return append([]string{"-c", "user.name=" + defaultUserName, "-c", "user.email=" + defaultUserEmail}, args...)
+	}
+	return args
+}
+
+// Withheld lists the permissions a tool pulled from the remote was saved with but not granted here
+type Withheld struct {
+	Tool        string
+	Permissions []string
+}
+
+// Sync commits any local changes, rebases them onto the remote branch and pushes the result. If the
+// rebase conflicts it is abandoned, leaving the local commits as they were, and a *ConflictError
+// names the conflicting files. Permissions aren't synced, since anyone who can push to the remote
+// could otherwise grant them: a pulled tool keeps only permissions it already had here with the
+// same code, and the rest are withheld until the operator grants them.
+func (r *Repo) Sync() ([]Withheld, error) {
+	dir, err := r.init()
+	if err != nil {
+		return nil, err
+	}
+	if err := r.commit(dir, "Sync saved tools"); err != nil {
+		return nil, err
+	}
+	granted, err := persistence.ListTools()
+	if err != nil {
+		return nil, err
+	}
+
+	// A new remote has nothing to pull yet
+	heads, err := r.git(dir, "ls-remote", "--heads", "origin", r.branch)
+	if err != nil {
+		return nil, err
+	}
+	if heads == "" {
+		if _, err := r.git(dir, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+			return nil, nil // nothing to push either
+		}
+		_, err = r.git(dir, "push", "--quiet", "origin", "HEAD:refs/heads/"+r.branch)
+		return nil, err
+	}
+
+	if _, err := r.git(dir, "fetch", "--quiet", "origin", r.branch); err != nil {
+		return nil, err
+	}
+	upstream := "origin/" + r.branch
+	push := true
+	if _, err := r.git(dir, "rev-parse", "--verify", "--quiet", "HEAD"); err != nil {
+		// Nothing has been committed here, so start from the remote's tools
+		if _, err := r.git(dir, "checkout", "--quiet", "-B", r.branch, upstream); err != nil {
+			return nil, err
+		}
+		push = false
+	} else if err := r.rebase(dir, upstream); err != nil {
+		return nil, err
+	}
+
+	withheld, err := withholdPermissions(granted)
+	if err != nil {
+		return withheld, err
+	}
+	if len(withheld) > 0 {
+		if err := r.commit(dir, "Withhold permissions granted elsewhere"); err != nil {
+			return withheld, err
+		}
+		push = true
+	}
+	if !push {
+		return nil, nil
+	}
+	_, err = r.git(dir, "push", "--quiet", "origin", "HEAD:refs/heads/"+r.branch)
+	return withheld, err
+}
+
+// withholdPermissions removes the permissions of saved tools that weren't granted before the pull:
+// a tool keeps only the permissions it had in granted, and only if its code and allowed servers are unchanged
+func withholdPermissions(granted []*persistence.SavedToolDefinition) ([]Withheld, error) {
+	before := make(map[string]*persistence.SavedToolDefinition, len(granted))
+	for _, tool := range granted {
+		before[tool.Name] = tool
+	}
+	tools, err := persistence.ListTools()
+	if err != nil {
+		return nil, err
+	}
+
+	var withheld []Withheld
+	for _, tool := range tools {
+		var kept, removed []string
+		previous, ok := before[tool.Name]
+		for _, permission := range tool.Permissions {
+			if ok && persistence.GrantApplies(previous, tool) && slices.Contains(previous.Permissions, permission) {
+				kept = append(kept, permission)
+			} else {
+				removed = append(removed, permission)
+			}
+		}
+		if len(removed) == 0 {
+			continue
+		}
+		tool.Permissions = kept
+		if err := persistence.SaveTool(tool); err != nil {
+			return withheld, fmt.Errorf("failed to withhold permissions of %s: %w", tool.Name, err)
+		}
+		withheld = append(withheld, Withheld{Tool: tool.Name, Permissions: removed})
+	}
+	return withheld, nil
+}
+
+// rebase replays local commits onto the fetched remote branch
+func (r *Repo) rebase(dir, upstream string) error {
+	_, rebaseErr := r.git(dir, r.withIdentity(dir, "rebase", "--quiet", upstream)...)
+	if rebaseErr == nil {
+		return nil
+	}
+
+	conflicts, err := r.git(dir, "diff", "--name-only", "--diff-filter=U")
+	if _, abortErr := r.git(dir, "rebase", "--abort"); abortErr != nil {
+		return errors.Join(rebaseErr, abortErr)
+	}
+	if err != nil || conflicts == "" {
+		return rebaseErr
+	}
+	return &ConflictError{Files: strings.Split(conflicts, "\n")}
+}
+
+// backend commits each change made through the backend it wraps
+type backend struct {
+	persistence.Backend
+	repo *Repo
+}
+
+// NewBackend wraps a saved tool backend so that each save and delete is committed to repo
+func NewBackend(inner persistence.Backend, repo *Repo) persistence.Backend {
+	return &backend{Backend: inner, repo: repo}
+}
+
+// Save saves a tool and commits it
+func (b *backend) Save(tool *persistence.SavedToolDefinition) error {
+	if err := b.Backend.Save(tool); err != nil {
+		return err
+	}
+	b.commit(fmt.Sprintf("Save %s (version %d)", tool.Name, tool.Version))
+	return nil
+}
+
+// SaveIfVersion saves a tool if it's still at the expected version, and commits it
+func (b *backend) SaveIfVersion(tool *persistence.SavedToolDefinition, expectedVersion int) error {
+	if err := b.Backend.SaveIfVersion(tool, expectedVersion); err != nil {
+		return err
+	}
+	b.commit(fmt.Sprintf("Save %s (version %d)", tool.Name, tool.Version))
+	return nil
+}
+
+// Delete deletes a tool and commits its removal
+func (b *backend) Delete(name string) error {
+	if err := b.Backend.Delete(name); err != nil {
+		return err
+	}
+	b.commit(fmt.Sprintf("Delete %s", name))
+	return nil
+}
+
+// commit records a change; the change itself has been made, so failing to commit it is only logged
+func (b *backend) commit(message string) {
+	if err := b.repo.Commit(message); err != nil {
+		logging.Warnf("Failed to commit saved tools: %v", err)
+	}
+}
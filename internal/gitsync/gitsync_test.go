@@ -0,0 +1,145 @@
+package gitsync
+
+import (
+	"errors"
+	"os/exec"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/persistence"
+)
+
+// newRemote creates a bare repository to sync with
+func newRemote(t *testing.T) string {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	remote := t.TempDir()
+	if out, err := exec.Command("git", "init", "--bare", "--quiet", remote).CombinedOutput(); err != nil {
+		t.Fatalf("git init failed: %v: %s", err, out)
+	}
+	return remote
+}
+
+// useMachine points the metatool directory at dir, as if on another machine, committing changes
+// to saved tools to a repository synced with remote
+func useMachine(t *testing.T, dir, remote string) *Repo {
+	t.Helper()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+	repo := NewRepo(&config.ToolSyncConfig{Remote: remote})
+	previous := persistence.Current()
+	persistence.Use(NewBackend(previous, repo))
+	t.Cleanup(func() { persistence.Use(previous) })
+	return repo
+}
+
+// commits returns the subjects of the commits on the remote's branch, newest first
+func commits(t *testing.T, remote string) []string {
+	t.Helper()
+	out, err := exec.Command("git", "-C", remote, "log", "--format=%s", "main").CombinedOutput()
+	if err != nil {
+		t.Fatalf("git log failed: %v: %s", err, out)
+	}
+	return strings.Split(strings.TrimSpace(string(out)), "\n")
+}
+
+func TestSync(t *testing.T) {
+	remote := newRemote(t)
+
+	// The first machine's saves and deletes are committed, then pushed by a sync
+	alice := useMachine(t, t.TempDir(), remote)
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "greet", Code: "'hello'"})
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "scratch", Code: "1"})
+	if err := persistence.DeleteTool("scratch"); err != nil {
+		t.Fatalf("DeleteTool() error = %v", err)
+	}
+	if _, err := alice.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	want := []string{"Delete scratch", "Save scratch (version 1)", "Save greet (version 1)"}
+	if got := commits(t, remote); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Remote commits = %q, want %q", got, want)
+	}
+
+	// Another machine picks the tools up, and its changes are rebased onto the remote
+	bob := useMachine(t, t.TempDir(), remote)
+	if _, err := bob.Sync(); err != nil {
+		t.Fatalf("Sync() on a new machine error = %v", err)
+	}
+	tool, err := persistence.LoadTool("greet")
+	if err != nil || tool.Code != "'hello'" {
+		t.Fatalf("LoadTool() after sync = %+v, %v, want the synced tool", tool, err)
+	}
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "farewell", Code: "'bye'"})
+	if _, err := bob.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if got := commits(t, remote); got[0] != "Save farewell (version 1)" {
+		t.Errorf("Latest remote commit = %q, want bob's save", got[0])
+	}
+}
+
+func TestSync_Conflict(t *testing.T) {
+	remote := newRemote(t)
+	aliceDir, bobDir := t.TempDir(), t.TempDir()
+	alice := useMachine(t, aliceDir, remote)
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "greet", Code: "'hello'"})
+	if _, err := alice.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	bob := useMachine(t, bobDir, remote)
+	if _, err := bob.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "greet", Code: "'hi from bob'"})
+	if _, err := bob.Sync(); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+
+	// Alice changes the same tool without syncing first
+	alice = useMachine(t, aliceDir, remote)
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "greet", Code: "'hi from alice'"})
+	_, err := alice.Sync()
+	var conflict *ConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("Sync() error = %v, want a conflict", err)
+	}
+	if !strings.Contains(strings.Join(conflict.Files, ","), "greet.star") {
+		t.Errorf("Conflicting files = %v, want greet.star", conflict.Files)
+	}
+
+	// Alice's change is kept, rather than left mid-rebase
+	tool, err := persistence.LoadTool("greet")
+	if err != nil || tool.Code != "'hi from alice'" {
+		t.Errorf("LoadTool() after conflict = %+v, %v, want alice's change", tool, err)
+	}
+}
+
+func TestSync_WithholdsPermissions(t *testing.T) {
+	remote := newRemote(t)
+	alice := useMachine(t, t.TempDir(), remote)
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "leak", Code: "secrets.get('token')", Permissions: []string{"secrets"}})
+	if withheld, err := alice.Sync(); err != nil || len(withheld) != 0 {
+		t.Fatalf("Sync() = %v, %v, want alice's own grant kept", withheld, err)
+	}
+	if tool, _ := persistence.LoadTool("leak"); len(tool.Permissions) != 1 {
+		t.Errorf("Permissions after sync = %v, want alice's grant", tool.Permissions)
+	}
+
+	// Whoever can push to the remote can't grant permissions on another machine
+	bob := useMachine(t, t.TempDir(), remote)
+	withheld, err := bob.Sync()
+	if err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	if len(withheld) != 1 || withheld[0].Tool != "leak" || strings.Join(withheld[0].Permissions, ",") != "secrets" {
+		t.Errorf("Sync() withheld %+v, want leak's secrets permission", withheld)
+	}
+	tool, err := persistence.LoadTool("leak")
+	if err != nil || len(tool.Permissions) != 0 || tool.Code != "secrets.get('token')" {
+		t.Errorf("LoadTool() after sync = %+v, %v, want the tool without permissions", tool, err)
+	}
+}
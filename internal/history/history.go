@@ -0,0 +1,174 @@
+// Package history logs recent tool calls, proxied and saved, so later code can look back over them
+package history
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/redact"
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// maxEntries is how many calls the log keeps; older calls are dropped as new ones are recorded
+var maxEntries = 1000
+
+// Entry is one logged tool call
+type Entry struct {
+	Time       time.Time              `json:"time"`
+	Server     string                 `json:"server,omitempty"` // empty for saved tools
+	Tool       string                 `json:"tool"`
+	Arguments  map[string]interface{} `json:"arguments,omitempty"`
+	DurationMs float64                `json:"durationMs"`
+	Error      string                 `json:"error,omitempty"`
+}
+
+// Query selects entries from the log; empty fields match every entry
+type Query struct {
+	Server string
+	Tool   string // glob pattern, e.g. "create_*"
+	Since  time.Time
+	Errors bool // only failed calls
+	Limit  int  // most recent matches to return; 0 means all
+}
+
+// mu serializes updates to the history file within this process
+var mu sync.Mutex
+
+// enabled turns on recording. Like usage tracking, it stays off unless the metatool is
+// serving clients, so CLI runs and tests aren't logged.
+var enabled atomic.Bool
+
+// Enable starts logging tool calls
+func Enable() {
+	enabled.Store(true)
+}
+
+// RecordProxied logs a finished call to a tool on an upstream server
+func RecordProxied(serverName, toolName string, arguments map[string]interface{}, elapsed time.Duration, failure string) {
+	record(Entry{Server: serverName, Tool: toolName, Arguments: arguments, Error: failure}, elapsed)
+}
+
+// RecordSaved logs a finished run of a saved tool
+func RecordSaved(toolName string, params map[string]interface{}, elapsed time.Duration, failure string) {
+	record(Entry{Tool: toolName, Arguments: params, Error: failure}, elapsed)
+}
+
+// Recent returns the last n logged calls, oldest first
+func Recent(n int) ([]Entry, error) {
+	return Search(Query{Limit: n})
+}
+
+// Search returns the logged calls matching the query, oldest first
+func Search(query Query) ([]Entry, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, _, err := load()
+	if err != nil {
+		return nil, err
+	}
+	matches := []Entry{}
+	for _, entry := range entries {
+		if query.matches(entry) {
+			matches = append(matches, entry)
+		}
+	}
+	if query.Limit > 0 && len(matches) > query.Limit {
+		matches = matches[len(matches)-query.Limit:]
+	}
+	return matches, nil
+}
+
+// matches reports whether an entry satisfies every field of the query
+func (q Query) matches(entry Entry) bool {
+	if q.Server != "" && entry.Server != q.Server {
+		return false
+	}
+	if q.Tool != "" {
+		if matched, _ := path.Match(q.Tool, entry.Tool); !matched {
+			return false
+		}
+	}
+	if entry.Time.Before(q.Since) {
+		return false
+	}
+	return !q.Errors || entry.Error != ""
+}
+
+// record appends an entry to the history file, masking sensitive values. Failures are logged
+// rather than returned, since losing a log entry should never fail the call being logged.
+func record(entry Entry, elapsed time.Duration) {
+	if !enabled.Load() {
+		return
+	}
+	entry.Time = time.Now().Add(-elapsed)
+	entry.DurationMs = float64(elapsed) / float64(time.Millisecond)
+	entry.Arguments = redactArguments(entry.Arguments)
+	entry.Error = redact.String(entry.Error)
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	entries, historyPath, err := load()
+	if err != nil {
+		logging.Warnf("Failed to load tool call history: %v", err)
+		return
+	}
+	entries = append(entries, entry)
+	if len(entries) > maxEntries {
+		entries = entries[len(entries)-maxEntries:]
+	}
+
+	data, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		logging.Warnf("Failed to encode tool call history: %v", err)
+		return
+	}
+	if err := storage.WriteFile(historyPath, data, 0600); err != nil {
+		logging.Warnf("Failed to save tool call history: %v", err)
+	}
+}
+
+// redactArguments masks sensitive values anywhere in a call's arguments
+func redactArguments(arguments map[string]interface{}) map[string]interface{} {
+	if len(arguments) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(arguments)
+	if err != nil {
+		return nil
+	}
+	var redacted map[string]interface{}
+	if err := json.Unmarshal([]byte(redact.String(string(data))), &redacted); err != nil {
+		return nil
+	}
+	return redacted
+}
+
+// load reads the history file, which may not exist yet. The caller must hold mu.
+func load() ([]Entry, string, error) {
+	historyPath, err := paths.GetHistoryPath()
+	if err != nil {
+		return nil, "", err
+	}
+
+	var entries []Entry
+	data, err := storage.ReadFile(historyPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, historyPath, nil
+	} else if err != nil {
+		return nil, "", err
+	}
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s: %w", historyPath, err)
+	}
+	return entries, historyPath, nil
+}
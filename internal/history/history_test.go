@@ -0,0 +1,125 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/redact"
+)
+
+func TestRecordAndSearch(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+
+	// Nothing is recorded until enabled
+	RecordSaved("report", nil, time.Millisecond, "")
+	if _, err := os.Stat(filepath.Join(dir, "history.json")); err == nil {
+		t.Fatal("Expected no history file before Enable")
+	}
+
+	Enable()
+	defer enabled.Store(false)
+	redact.Register("s3cr3t-token")
+	defer redact.Reset()
+
+	start := time.Now()
+	RecordProxied("github", "get_issue", map[string]interface{}{"number": 1}, 10*time.Millisecond, "")
+	RecordProxied("github", "create_issue", map[string]interface{}{"title": "Bug", "token": "s3cr3t-token"}, 20*time.Millisecond, "")
+	RecordProxied("jira", "create_ticket", nil, time.Millisecond, "forbidden")
+	RecordSaved("report", map[string]interface{}{"days": 7}, 50*time.Millisecond, "")
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"all", Query{}, []string{"github/get_issue", "github/create_issue", "jira/create_ticket", "/report"}},
+		{"limit keeps the latest", Query{Limit: 2}, []string{"jira/create_ticket", "/report"}},
+		{"server", Query{Server: "github"}, []string{"github/get_issue", "github/create_issue"}},
+		{"tool pattern", Query{Tool: "create_*"}, []string{"github/create_issue", "jira/create_ticket"}},
+		{"errors", Query{Errors: true}, []string{"jira/create_ticket"}},
+		{"since", Query{Since: start.Add(-time.Hour)}, []string{"github/get_issue", "github/create_issue", "jira/create_ticket", "/report"}},
+		{"since later", Query{Since: time.Now().Add(time.Hour)}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			entries, err := Search(tt.query)
+			if err != nil {
+				t.Fatalf("Search() error = %v", err)
+			}
+			var got []string
+			for _, entry := range entries {
+				got = append(got, entry.Server+"/"+entry.Tool)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Search() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	entries, err := Recent(3)
+	if err != nil || len(entries) != 3 {
+		t.Fatalf("Recent(3) = %v, %v", entries, err)
+	}
+	if token := entries[0].Arguments["token"]; token != redact.Mask {
+		t.Errorf("Expected token argument masked, got %v", token)
+	}
+	if entries[1].Error != "forbidden" || entries[2].DurationMs != 50 {
+		t.Errorf("Expected error and duration recorded, got %+v", entries[1:])
+	}
+}
+
+func TestRecord_RedactsJSONEscapedSecrets(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	Enable()
+	defer enabled.Store(false)
+	secret := `pa"ss\word`
+	redact.Register(secret)
+	defer redact.Reset()
+
+	RecordProxied("db", "connect", map[string]interface{}{"password": secret, "options": []interface{}{"user=me password=" + secret}}, time.Millisecond, "")
+
+	entries, err := Recent(1)
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("Recent(1) = %v, %v", entries, err)
+	}
+	arguments := entries[0].Arguments
+	if arguments["password"] != redact.Mask {
+		t.Errorf("Expected password argument masked, got %v", arguments["password"])
+	}
+	if options := fmt.Sprint(arguments["options"]); options != "[user=me password="+redact.Mask+"]" {
+		t.Errorf("Expected password masked within options, got %v", options)
+	}
+}
+
+func TestRecord_Trims(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	Enable()
+	defer enabled.Store(false)
+	maxEntries = 3
+	defer func() { maxEntries = 1000 }()
+
+	for i := 0; i < 5; i++ {
+		RecordSaved(fmt.Sprintf("tool%d", i), nil, 0, "")
+	}
+	entries, err := Recent(10)
+	if err != nil {
+		t.Fatalf("Recent() error = %v", err)
+	}
+	if len(entries) != 3 || entries[0].Tool != "tool2" || entries[2].Tool != "tool4" {
+		t.Errorf("Expected the last 3 calls, got %+v", entries)
+	}
+}
+
+func TestSearch_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+	os.WriteFile(filepath.Join(dir, "history.json"), []byte("[not json"), 0644)
+
+	if _, err := Recent(1); err == nil {
+		t.Error("Expected error for corrupt history file")
+	}
+}
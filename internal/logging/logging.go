@@ -0,0 +1,279 @@
+// Package logging provides the leveled logger used throughout the metatool, with a debug level
+// that can be switched on and off while the server runs
+package logging
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/redact"
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// EnvVar starts the server with debug logging enabled when set
+const EnvVar = "MCP_METATOOL_DEBUG"
+
+// Environment variables overriding the configured log settings
+const (
+	LevelEnvVar  = "MCP_METATOOL_LOG_LEVEL"
+	FormatEnvVar = "MCP_METATOOL_LOG_FORMAT"
+	FileEnvVar   = "MCP_METATOOL_LOG_FILE"
+)
+
+// Stderr is the log file setting that writes to stderr instead of a file
+const Stderr = "stderr"
+
+// Defaults for rotating the log file
+const (
+	DefaultMaxSizeMB = 10
+	DefaultMaxFiles  = 3
+)
+
+// Options configure the level, format and destination of the log
+type Options struct {
+	Level     string // debug, info, warn or error; defaults to info
+	Format    string // text or json; defaults to text
+	File      string // log file path, or "stderr"; defaults to logs/mcp-metatool.log in the metatool directory
+	MaxSizeMB int    // size at which the log file is rotated
+	MaxFiles  int    // rotated files kept besides the current one
+}
+
+var (
+	mu sync.Mutex
+	// configured is the level restored when debug logging is switched off
+	configured = slog.LevelInfo
+	level      = new(slog.LevelVar)
+	// file is the open log file, if logging to one
+	file io.Closer
+)
+
+func init() {
+	if os.Getenv(EnvVar) != "" {
+		level.Set(slog.LevelDebug)
+	}
+	setOutput(os.Stderr, "text")
+}
+
+// Validate checks that the level and format are recognized and the rotation settings aren't negative
+func (o Options) Validate() error {
+	if _, err := parseLevel(o.Level); err != nil {
+		return err
+	}
+	if o.Format != "" && o.Format != "text" && o.Format != "json" {
+		return fmt.Errorf("unknown log format %q (use text or json)", o.Format)
+	}
+	if o.MaxSizeMB < 0 || o.MaxFiles < 0 {
+		return fmt.Errorf("log rotation settings cannot be negative")
+	}
+	return nil
+}
+
+// Configure directs the log to the configured destination, with environment variables taking
+// precedence over opts. If the log file can't be opened, logging continues on stderr.
+func Configure(opts Options) error {
+	if v := os.Getenv(LevelEnvVar); v != "" {
+		opts.Level = v
+	}
+	if v := os.Getenv(FormatEnvVar); v != "" {
+		opts.Format = v
+	}
+	if v := os.Getenv(FileEnvVar); v != "" {
+		opts.File = v
+	}
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	lvl, _ := parseLevel(opts.Level)
+	if opts.Format == "" {
+		opts.Format = "text"
+	}
+
+	mu.Lock()
+	configured = lvl
+	if os.Getenv(EnvVar) != "" {
+		level.Set(slog.LevelDebug)
+	} else {
+		level.Set(lvl)
+	}
+	mu.Unlock()
+
+	path, err := logPath(opts.File)
+	if err != nil {
+		setOutput(os.Stderr, opts.Format)
+		return err
+	}
+	if path == "" {
+		setOutput(os.Stderr, opts.Format)
+		return nil
+	}
+
+	maxSize := opts.MaxSizeMB
+	if maxSize == 0 {
+		maxSize = DefaultMaxSizeMB
+	}
+	maxFiles := opts.MaxFiles
+	if maxFiles == 0 {
+		maxFiles = DefaultMaxFiles
+	}
+	rotating, err := openRotating(path, int64(maxSize)<<20, maxFiles)
+	if err != nil {
+		setOutput(os.Stderr, opts.Format)
+		return err
+	}
+	setOutput(rotating, opts.Format)
+	return nil
+}
+
+// Close closes the log file, if any, sending later messages to stderr
+func Close() error {
+	mu.Lock()
+	defer mu.Unlock()
+	if file == nil {
+		return nil
+	}
+	slog.SetDefault(newLogger(os.Stderr, "text"))
+	err := file.Close()
+	file = nil
+	return err
+}
+
+// Debug reports whether debug logging is enabled
+func Debug() bool {
+	return level.Level() <= slog.LevelDebug
+}
+
+// SetDebug enables or disables debug logging, logging the change
+func SetDebug(enabled bool) {
+	mu.Lock()
+	changed := setDebug(enabled)
+	mu.Unlock()
+	if changed {
+		logDebugChange(enabled)
+	}
+}
+
+// ToggleDebug flips debug logging on or off and returns the new setting
+func ToggleDebug() bool {
+	mu.Lock()
+	enabled := !Debug()
+	setDebug(enabled)
+	mu.Unlock()
+	logDebugChange(enabled)
+	return enabled
+}
+
+// Debugf logs a message only while debug logging is enabled
+func Debugf(format string, args ...interface{}) {
+	logf(slog.LevelDebug, format, args...)
+}
+
+// Infof logs a routine message
+func Infof(format string, args ...interface{}) {
+	logf(slog.LevelInfo, format, args...)
+}
+
+// Warnf logs a problem the metatool works around
+func Warnf(format string, args ...interface{}) {
+	logf(slog.LevelWarn, format, args...)
+}
+
+// Errorf logs a failure
+func Errorf(format string, args ...interface{}) {
+	logf(slog.LevelError, format, args...)
+}
+
+// logf formats and logs a message if its level is enabled
+func logf(lvl slog.Level, format string, args ...interface{}) {
+	logger := slog.Default()
+	if !logger.Enabled(context.Background(), lvl) {
+		return
+	}
+	logger.Log(context.Background(), lvl, fmt.Sprintf(format, args...))
+}
+
+// setDebug switches the level between debug and the configured level; mu must be held
+func setDebug(enabled bool) bool {
+	if Debug() == enabled {
+		return false
+	}
+	if enabled {
+		level.Set(slog.LevelDebug)
+	} else {
+		level.Set(max(configured, slog.LevelInfo))
+	}
+	return true
+}
+
+// logDebugChange logs debug logging being switched on or off
+func logDebugChange(enabled bool) {
+	if enabled {
+		Infof("Debug logging enabled")
+	} else {
+		Infof("Debug logging disabled")
+	}
+}
+
+// setOutput sends log messages, and those of the standard log package, to w in the given format,
+// closing any previous log file
+func setOutput(w io.Writer, format string) {
+	mu.Lock()
+	defer mu.Unlock()
+	if file != nil {
+		file.Close()
+		file = nil
+	}
+	if closer, ok := w.(*rotatingFile); ok {
+		file = closer
+	}
+	slog.SetDefault(newLogger(w, format))
+}
+
+// newLogger creates a logger writing to w with sensitive values masked
+func newLogger(w io.Writer, format string) *slog.Logger {
+	opts := &slog.HandlerOptions{Level: level}
+	if format == "json" {
+		return slog.New(slog.NewJSONHandler(redact.Writer(w), opts))
+	}
+	return slog.New(slog.NewTextHandler(redact.Writer(w), opts))
+}
+
+// logPath resolves the log file setting, returning "" to log to stderr
+func logPath(setting string) (string, error) {
+	switch {
+	case strings.EqualFold(setting, Stderr):
+		return "", nil
+	case setting != "":
+		return setting, nil
+	case storage.IsEphemeral():
+		// Nothing is written to the metatool directory in ephemeral mode
+		return "", nil
+	}
+
+	dir, err := paths.GetMetatoolDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "logs", "mcp-metatool.log"), nil
+}
+
+// parseLevel reads a level name, defaulting to info
+func parseLevel(name string) (slog.Level, error) {
+	switch strings.ToLower(name) {
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "debug":
+		return slog.LevelDebug, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return 0, fmt.Errorf("unknown log level %q (use debug, info, warn or error)", name)
+}
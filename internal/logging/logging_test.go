@@ -0,0 +1,110 @@
+package logging
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/redact"
+)
+
+func TestDebugf(t *testing.T) {
+	var output bytes.Buffer
+	setOutput(&output, "text")
+	defer setOutput(os.Stderr, "text")
+	defer SetDebug(Debug())
+
+	SetDebug(false)
+	Debugf("hidden %d", 1)
+	if strings.Contains(output.String(), "hidden") {
+		t.Errorf("Debugf() logged while disabled: %q", output.String())
+	}
+
+	if !ToggleDebug() || !Debug() {
+		t.Fatal("ToggleDebug() should enable debug logging")
+	}
+	Debugf("shown %d", 2)
+	if !strings.Contains(output.String(), `level=DEBUG msg="shown 2"`) {
+		t.Errorf("Debugf() output = %q, want the debug message", output.String())
+	}
+
+	if ToggleDebug() || Debug() {
+		t.Error("ToggleDebug() should disable debug logging again")
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	t.Cleanup(func() { Configure(Options{File: Stderr}) })
+	path := filepath.Join(t.TempDir(), "logs", "metatool.log")
+	redact.Register("hunter2-password")
+	defer redact.Reset()
+
+	if err := Configure(Options{Level: "warn", Format: "json", File: path}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	Infof("routine message")
+	Warnf("login failed with %s", "hunter2-password")
+	Errorf("broken %d", 3)
+	if err := Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("log file not written: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines at warn level and above, got %q", lines)
+	}
+	var entry struct {
+		Level string `json:"level"`
+		Msg   string `json:"msg"`
+	}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatalf("log line is not JSON: %v", err)
+	}
+	if entry.Level != "WARN" || entry.Msg != "login failed with "+redact.Mask {
+		t.Errorf("unexpected first entry %+v", entry)
+	}
+
+	// Environment variables take precedence over the options
+	t.Setenv(LevelEnvVar, "error")
+	t.Setenv(FormatEnvVar, "text")
+	t.Setenv(FileEnvVar, path)
+	if err := Configure(Options{Level: "debug", Format: "json"}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	Warnf("suppressed")
+	Errorf("kept")
+	Close()
+	data, _ = os.ReadFile(path)
+	if strings.Contains(string(data), "suppressed") || !strings.Contains(string(data), `level=ERROR msg=kept`) {
+		t.Errorf("environment overrides not applied: %s", data)
+	}
+}
+
+func TestOptionsValidate(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{"defaults", Options{}, false},
+		{"all set", Options{Level: "DEBUG", Format: "json", File: "out.log", MaxSizeMB: 1, MaxFiles: 1}, false},
+		{"warning alias", Options{Level: "warning"}, false},
+		{"unknown level", Options{Level: "verbose"}, true},
+		{"unknown format", Options{Format: "xml"}, true},
+		{"negative size", Options{MaxSizeMB: -1}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,44 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "metatool.log")
+	r, err := openRotating(path, 100, 2)
+	if err != nil {
+		t.Fatalf("openRotating() error = %v", err)
+	}
+	line := strings.Repeat("x", 39) + "\n"
+	for i := 0; i < 10; i++ {
+		if _, err := fmt.Fprint(r, line); err != nil {
+			t.Fatalf("Write() error = %v", err)
+		}
+	}
+	r.Close()
+
+	for _, name := range []string{path, path + ".1", path + ".2"} {
+		info, err := os.Stat(name)
+		if err != nil {
+			t.Fatalf("expected %s: %v", filepath.Base(name), err)
+		}
+		if info.Size() > 100 {
+			t.Errorf("%s is %d bytes, want at most 100", filepath.Base(name), info.Size())
+		}
+	}
+	if _, err := os.Stat(path + ".3"); err == nil {
+		t.Error("expected only 2 rotated files to be kept")
+	}
+
+	// Reopening appends to the existing file
+	r, _ = openRotating(path, 100, 2)
+	defer r.Close()
+	if r.size == 0 {
+		t.Error("expected the size of the existing log to be counted")
+	}
+}
@@ -0,0 +1,27 @@
+//go:build !windows
+
+package logging
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// ToggleOnSignal flips debug logging each time the process receives SIGUSR1, until ctx is done
+func ToggleOnSignal(ctx context.Context) {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGUSR1)
+	go func() {
+		defer signal.Stop(signals)
+		for {
+			select {
+			case <-signals:
+				ToggleDebug()
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
@@ -0,0 +1,30 @@
+//go:build !windows
+
+package logging
+
+import (
+	"context"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestToggleOnSignal(t *testing.T) {
+	defer SetDebug(Debug())
+	SetDebug(false)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	ToggleOnSignal(ctx)
+
+	if err := syscall.Kill(syscall.Getpid(), syscall.SIGUSR1); err != nil {
+		t.Fatalf("failed to signal self: %v", err)
+	}
+	deadline := time.Now().Add(5 * time.Second)
+	for !Debug() {
+		if time.Now().After(deadline) {
+			t.Fatal("SIGUSR1 did not enable debug logging")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
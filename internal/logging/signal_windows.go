@@ -0,0 +1,6 @@
+package logging
+
+import "context"
+
+// ToggleOnSignal does nothing on Windows, which has no SIGUSR1; use the admin API instead
+func ToggleOnSignal(ctx context.Context) {}
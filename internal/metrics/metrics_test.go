@@ -0,0 +1,73 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCounterVec(t *testing.T) {
+	c := &CounterVec{metricName: "test_total", help: "Test counter.", labelNames: []string{"server", "tool"}, values: make(map[string]*counterSeries)}
+	c.Inc("github", "get_issue")
+	c.Inc("github", "get_issue")
+	c.Add(3, "slack", `say "hi"`+"\n")
+
+	if got := c.Value("github", "get_issue"); got != 2 {
+		t.Errorf("Value() = %v, want 2", got)
+	}
+	if got := c.Value("github", "missing"); got != 0 {
+		t.Errorf("Value() for an unseen series = %v, want 0", got)
+	}
+
+	var buf bytes.Buffer
+	c.write(&buf)
+	expected := `# HELP test_total Test counter.
+# TYPE test_total counter
+test_total{server="github",tool="get_issue"} 2
+test_total{server="slack",tool="say \"hi\"\n"} 3
+`
+	if buf.String() != expected {
+		t.Errorf("write() =\n%s\nwant\n%s", buf.String(), expected)
+	}
+}
+
+func TestHistogramVec(t *testing.T) {
+	h := &HistogramVec{metricName: "test_seconds", help: "Test histogram.", labelNames: []string{"tool"}, buckets: []float64{0.1, 1}, values: make(map[string]*histogramSeries)}
+	h.Observe(0.05, "greet")
+	h.Observe(0.5, "greet")
+	h.Observe(2, "greet")
+
+	if got := h.Count("greet"); got != 3 {
+		t.Errorf("Count() = %d, want 3", got)
+	}
+
+	var buf bytes.Buffer
+	h.write(&buf)
+	expected := `# HELP test_seconds Test histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{tool="greet",le="0.1"} 1
+test_seconds_bucket{tool="greet",le="1"} 2
+test_seconds_bucket{tool="greet",le="+Inf"} 3
+test_seconds_sum{tool="greet"} 2.55
+test_seconds_count{tool="greet"} 3
+`
+	if buf.String() != expected {
+		t.Errorf("write() =\n%s\nwant\n%s", buf.String(), expected)
+	}
+}
+
+func TestWrite(t *testing.T) {
+	ActiveSessions.Set(2)
+
+	var buf bytes.Buffer
+	Write(&buf)
+	output := buf.String()
+
+	if !strings.Contains(output, "\nmcp_metatool_active_sessions 2\n") {
+		t.Errorf("Expected the active sessions gauge, got:\n%s", output)
+	}
+	// Metrics are written in name order
+	if strings.Index(output, "mcp_metatool_active_sessions") > strings.Index(output, "mcp_metatool_starlark_errors_total") {
+		t.Errorf("Expected metrics sorted by name, got:\n%s", output)
+	}
+}
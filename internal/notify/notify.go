@@ -0,0 +1,167 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	metastarlark "github.com/dslh/mcp-metatool/internal/starlark"
+)
+
+// defaultSMTPPort is used when the SMTP config doesn't specify a port
+const defaultSMTPPort = 587
+
+// Notifier sends notifications through the configured webhooks and SMTP relay
+type Notifier struct {
+	config     *config.NotifyConfig
+	httpClient *http.Client
+	sendMail   func(addr string, a smtp.Auth, from string, to []string, msg []byte) error
+}
+
+// NewNotifier creates a notifier for the given configuration
+func NewNotifier(cfg *config.NotifyConfig) *Notifier {
+	return &Notifier{
+		config:     cfg,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		sendMail:   smtp.SendMail,
+	}
+}
+
+// Module returns the notify module exposed to Starlark code
+func (n *Notifier) Module() *starlarkstruct.Module {
+	return &starlarkstruct.Module{
+		Name: "notify",
+		Members: starlark.StringDict{
+			"webhook": starlark.NewBuiltin("notify.webhook", n.webhook),
+			"email":   starlark.NewBuiltin("notify.email", n.email),
+		},
+	}
+}
+
+// Webhook POSTs payload as JSON to the named webhook and returns the HTTP status code
+func (n *Notifier) Webhook(name string, payload interface{}) (int, error) {
+	url, exists := n.config.Webhooks[name]
+	if !exists {
+		return 0, fmt.Errorf("webhook '%s' is not configured", name)
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to encode payload: %w", err)
+	}
+
+	resp, err := n.httpClient.Post(url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return 0, fmt.Errorf("webhook '%s' request failed: %w", name, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("webhook '%s' returned status %d", name, resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// Email sends a plain text message through the configured SMTP relay
+func (n *Notifier) Email(to []string, subject, body string) error {
+	smtpConfig := n.config.SMTP
+	if smtpConfig == nil {
+		return fmt.Errorf("smtp is not configured")
+	}
+	if len(to) == 0 {
+		return fmt.Errorf("at least one recipient is required")
+	}
+
+	port := smtpConfig.Port
+	if port == 0 {
+		port = defaultSMTPPort
+	}
+
+	var auth smtp.Auth
+	if smtpConfig.Username != "" {
+		auth = smtp.PlainAuth("", smtpConfig.Username, smtpConfig.Password, smtpConfig.Host)
+	}
+
+	addr := fmt.Sprintf("%s:%d", smtpConfig.Host, port)
+	if err := n.sendMail(addr, auth, smtpConfig.From, to, buildMessage(smtpConfig.From, to, subject, body)); err != nil {
+		return fmt.Errorf("failed to send email: %w", err)
+	}
+
+	return nil
+}
+
+// buildMessage formats an RFC 5322 plain text message
+func buildMessage(from string, to []string, subject, body string) []byte {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(body)
+	return []byte(msg.String())
+}
+
+// webhook implements notify.webhook(name, payload)
+func (n *Notifier) webhook(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	var payload starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "name", &name, "payload?", &payload); err != nil {
+		return nil, err
+	}
+
+	goPayload, err := metastarlark.StarlarkToGoValue(payload)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+
+	status, err := n.Webhook(name, goPayload)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+
+	result := starlark.NewDict(1)
+	result.SetKey(starlark.String("status"), starlark.MakeInt(status))
+	return result, nil
+}
+
+// email implements notify.email(to, subject, body)
+func (n *Notifier) email(thread *starlark.Thread, b *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var to starlark.Value
+	var subject, body string
+	if err := starlark.UnpackArgs(b.Name(), args, kwargs, "to", &to, "subject", &subject, "body", &body); err != nil {
+		return nil, err
+	}
+
+	var recipients []string
+	switch v := to.(type) {
+	case starlark.String:
+		recipients = []string{string(v)}
+	case *starlark.List:
+		for i := 0; i < v.Len(); i++ {
+			s, ok := v.Index(i).(starlark.String)
+			if !ok {
+				return nil, fmt.Errorf("%s: recipients must be strings", b.Name())
+			}
+			recipients = append(recipients, string(s))
+		}
+	default:
+		return nil, fmt.Errorf("%s: to must be a string or list of strings", b.Name())
+	}
+
+	if err := n.Email(recipients, subject, body); err != nil {
+		return nil, fmt.Errorf("%s: %v", b.Name(), err)
+	}
+
+	return starlark.None, nil
+}
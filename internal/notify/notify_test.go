@@ -0,0 +1,138 @@
+package notify
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/smtp"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	metastarlark "github.com/dslh/mcp-metatool/internal/starlark"
+)
+
+func TestWebhook(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Expected JSON content type, got %s", r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	n := NewNotifier(&config.NotifyConfig{
+		Webhooks: map[string]string{"alerts": server.URL, "broken": failing.URL},
+	})
+
+	status, err := n.Webhook("alerts", map[string]interface{}{"text": "hello"})
+	if err != nil {
+		t.Fatalf("Webhook() error = %v", err)
+	}
+	if status != http.StatusNoContent {
+		t.Errorf("Expected status 204, got %d", status)
+	}
+	if received["text"] != "hello" {
+		t.Errorf("Expected payload to be delivered, got %v", received)
+	}
+
+	if _, err := n.Webhook("broken", nil); err == nil || !strings.Contains(err.Error(), "500") {
+		t.Errorf("Expected status error, got %v", err)
+	}
+
+	if _, err := n.Webhook("unknown", nil); err == nil {
+		t.Error("Expected error for unconfigured webhook")
+	}
+}
+
+func TestEmail(t *testing.T) {
+	n := NewNotifier(&config.NotifyConfig{
+		SMTP: &config.SMTPConfig{Host: "smtp.example.com", From: "bot@example.com", Username: "bot", Password: "secret"},
+	})
+
+	var gotAddr string
+	var gotTo []string
+	var gotMsg string
+	n.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		gotAddr, gotTo, gotMsg = addr, to, string(msg)
+		if a == nil {
+			t.Error("Expected auth when username is configured")
+		}
+		return nil
+	}
+
+	if err := n.Email([]string{"a@example.com", "b@example.com"}, "Weekly report", "All good"); err != nil {
+		t.Fatalf("Email() error = %v", err)
+	}
+
+	if gotAddr != "smtp.example.com:587" {
+		t.Errorf("Expected default port, got %s", gotAddr)
+	}
+	if len(gotTo) != 2 {
+		t.Errorf("Expected 2 recipients, got %v", gotTo)
+	}
+	for _, want := range []string{"Subject: Weekly report", "To: a@example.com, b@example.com", "\r\n\r\nAll good"} {
+		if !strings.Contains(gotMsg, want) {
+			t.Errorf("Expected message to contain %q, got %q", want, gotMsg)
+		}
+	}
+
+	if err := n.Email(nil, "s", "b"); err == nil {
+		t.Error("Expected error without recipients")
+	}
+
+	if err := NewNotifier(&config.NotifyConfig{}).Email([]string{"a@example.com"}, "s", "b"); err == nil {
+		t.Error("Expected error without smtp config")
+	}
+}
+
+func TestModuleFromStarlark(t *testing.T) {
+	var received map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewDecoder(r.Body).Decode(&received)
+	}))
+	defer server.Close()
+
+	n := NewNotifier(&config.NotifyConfig{
+		Webhooks: map[string]string{"alerts": server.URL},
+		SMTP:     &config.SMTPConfig{Host: "smtp.example.com", From: "bot@example.com"},
+	})
+	var sent []string
+	n.sendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		sent = append(sent, to...)
+		return nil
+	}
+
+	code := `resp = notify.webhook("alerts", {"count": 3})
+notify.email("ops@example.com", "Subject", "Body")
+result = resp["status"]`
+
+	result, err := metastarlark.Execute(code, nil, metastarlark.WithModule("notify", n.Module()))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("Execute() returned error: %s", result.Error)
+	}
+	if result.Result != int64(200) {
+		t.Errorf("Expected status 200, got %v", result.Result)
+	}
+	if received["count"] != float64(3) {
+		t.Errorf("Expected webhook payload, got %v", received)
+	}
+	if len(sent) != 1 || sent[0] != "ops@example.com" {
+		t.Errorf("Expected email to ops@example.com, got %v", sent)
+	}
+
+	result, _ = metastarlark.Execute(`notify.email(42, "s", "b")`, nil, metastarlark.WithModule("notify", n.Module()))
+	if result.Error == "" {
+		t.Error("Expected error for invalid recipient type")
+	}
+}
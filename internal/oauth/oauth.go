@@ -0,0 +1,402 @@
+// Package oauth obtains access tokens for remote upstream servers with the OAuth client credentials or
+// device flow, keeping them in the secrets store and refreshing them as they expire.
+package oauth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/redact"
+	"github.com/dslh/mcp-metatool/internal/secrets"
+)
+
+// expiryMargin renews tokens this long before they expire, so they don't lapse in flight
+const expiryMargin = 30 * time.Second
+
+// defaultDevicePollInterval is how often the device flow polls for the user's approval if the
+// authorization server doesn't say
+const defaultDevicePollInterval = 5 * time.Second
+
+// sleep waits between device flow polls; replaced in tests
+var sleep = func(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// promptOutput is where the device flow asks the user to approve access. The log may be a file the
+// user never sees, and stdout carries the MCP protocol, so it's stderr; replaced in tests.
+var promptOutput io.Writer = os.Stderr
+
+// Token is an access token and what is needed to renew it
+type Token struct {
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	Expiry       time.Time `json:"expiry,omitempty"`
+}
+
+// valid reports whether the token can still be used
+func (t *Token) valid() bool {
+	return t != nil && t.AccessToken != "" && (t.Expiry.IsZero() || time.Now().Add(expiryMargin).Before(t.Expiry))
+}
+
+// SecretName is the secret a server's tokens are stored under
+func SecretName(serverName string) string {
+	return "oauth-" + serverName
+}
+
+// Source provides a server's access token, renewing it when it has expired
+type Source struct {
+	serverName string
+	config     *config.OAuthConfig
+	httpClient *http.Client
+
+	mu       sync.Mutex
+	token    *Token
+	renewing *renewal // the renewal in progress, if any
+}
+
+// renewal is an attempt to obtain a new token, which other callers wait for rather than starting their own
+type renewal struct {
+	done chan struct{} // closed when the attempt finishes
+	err  error
+}
+
+// NewSource creates a token source for a server, starting from any token stored for it
+func NewSource(serverName string, cfg *config.OAuthConfig) *Source {
+	return &Source{
+		serverName: serverName,
+		config:     cfg,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// Token returns a valid access token: the stored one, a refreshed one, or a new one from the flow.
+// The lock isn't held while renewing, since the device flow can wait minutes for the user.
+func (s *Source) Token(ctx context.Context) (*Token, error) {
+	s.mu.Lock()
+	for {
+		if s.token == nil {
+			s.token = s.load()
+		}
+		if s.token.valid() {
+			token := s.token
+			s.mu.Unlock()
+			return token, nil
+		}
+		if s.renewing == nil {
+			break
+		}
+
+		// Wait for the renewal in progress instead of asking the user to authorize twice
+		pending := s.renewing
+		s.mu.Unlock()
+		select {
+		case <-pending.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		if pending.err != nil {
+			return nil, pending.err
+		}
+		s.mu.Lock()
+	}
+
+	pending := &renewal{done: make(chan struct{})}
+	s.renewing = pending
+	var refreshToken string
+	if s.token != nil {
+		refreshToken = s.token.RefreshToken
+	}
+	s.mu.Unlock()
+
+	token, err := s.renew(ctx, refreshToken)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.renewing = nil
+	pending.err = err
+	close(pending.done)
+	if err != nil {
+		return nil, err
+	}
+	s.token = token
+	s.save(token)
+	return token, nil
+}
+
+// renew obtains a new token with the refresh token if there is one, falling back to the configured flow
+func (s *Source) renew(ctx context.Context, refreshToken string) (*Token, error) {
+	if refreshToken != "" {
+		token, err := s.refresh(ctx, refreshToken)
+		if err == nil {
+			return token, nil
+		}
+		logging.Warnf("Failed to refresh OAuth token for server %s, authorizing again: %v", s.serverName, err)
+	}
+
+	var token *Token
+	var err error
+	if s.config.Flow == config.OAuthDevice {
+		token, err = s.deviceFlow(ctx)
+	} else {
+		token, err = s.clientCredentials(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to obtain OAuth token for server %s: %w", s.serverName, err)
+	}
+	return token, nil
+}
+
+// Invalidate discards a token the server rejected, so the next call to Token renews it
+func (s *Source) Invalidate(token *Token) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.token != nil && s.token.AccessToken == token.AccessToken {
+		s.token.Expiry = time.Unix(1, 0)
+	}
+}
+
+// load reads the token stored for the server, if any
+func (s *Source) load() *Token {
+	data, err := secrets.Get(SecretName(s.serverName))
+	if err != nil {
+		return nil
+	}
+	var token Token
+	if err := json.Unmarshal([]byte(data), &token); err != nil {
+		logging.Warnf("Ignoring unreadable OAuth token stored for server %s: %v", s.serverName, err)
+		return nil
+	}
+	redact.Register(token.AccessToken, token.RefreshToken)
+	return &token
+}
+
+// save stores a token so it survives restarts; failing to is logged rather than fatal
+func (s *Source) save(token *Token) {
+	data, err := json.Marshal(token)
+	if err == nil {
+		err = secrets.Set(SecretName(s.serverName), string(data))
+	}
+	if err != nil {
+		logging.Warnf("Failed to store OAuth token for server %s: %v", s.serverName, err)
+	}
+}
+
+// clientCredentials obtains a token for the client itself
+func (s *Source) clientCredentials(ctx context.Context) (*Token, error) {
+	form := url.Values{"grant_type": {"client_credentials"}}
+	s.addScopes(form)
+	return s.requestToken(ctx, form)
+}
+
+// refresh exchanges a refresh token for a new access token
+func (s *Source) refresh(ctx context.Context, refreshToken string) (*Token, error) {
+	token, err := s.requestToken(ctx, url.Values{"grant_type": {"refresh_token"}, "refresh_token": {refreshToken}})
+	if err == nil && token.RefreshToken == "" {
+		token.RefreshToken = refreshToken // servers needn't issue a new one
+	}
+	return token, err
+}
+
+// deviceAuthorization is the response to a device authorization request
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// deviceFlow asks the user to approve access in a browser, polling until they have
+func (s *Source) deviceFlow(ctx context.Context) (*Token, error) {
+	form := url.Values{"client_id": {s.config.ClientID}}
+	s.addScopes(form)
+	var auth deviceAuthorization
+	if err := s.post(ctx, s.config.DeviceAuthorizationURL, form, &auth); err != nil {
+		return nil, fmt.Errorf("device authorization failed: %w", err)
+	}
+	if auth.DeviceCode == "" {
+		return nil, fmt.Errorf("device authorization returned no device code")
+	}
+
+	verification := auth.VerificationURI
+	if auth.VerificationURIComplete != "" {
+		verification = auth.VerificationURIComplete
+	}
+	logging.Warnf("To authorize server %s, visit %s and enter the code %s", s.serverName, verification, auth.UserCode)
+	fmt.Fprintf(promptOutput, "To authorize server %s, visit %s and enter the code %s\n", s.serverName, verification, auth.UserCode)
+
+	interval := time.Duration(auth.Interval) * time.Second
+	if interval <= 0 {
+		interval = defaultDevicePollInterval
+	}
+	if auth.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(auth.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+	for {
+		if err := sleep(ctx, interval); err != nil {
+			return nil, fmt.Errorf("device authorization was not approved in time: %w", err)
+		}
+		token, err := s.requestToken(ctx, url.Values{
+			"grant_type":  {"urn:ietf:params:oauth:grant-type:device_code"},
+			"device_code": {auth.DeviceCode},
+		})
+		if err == nil {
+			return token, nil
+		}
+		var tokenErr *tokenError
+		if !errors.As(err, &tokenErr) {
+			return nil, err
+		}
+		switch tokenErr.Code {
+		case "authorization_pending":
+		case "slow_down":
+			interval += 5 * time.Second
+		default:
+			return nil, err
+		}
+	}
+}
+
+// addScopes requests the configured scopes, if any
+func (s *Source) addScopes(form url.Values) {
+	if len(s.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(s.config.Scopes, " "))
+	}
+}
+
+// tokenResponse is a token endpoint's successful response
+type tokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+// requestToken makes a token request, authenticating as the client
+func (s *Source) requestToken(ctx context.Context, form url.Values) (*Token, error) {
+	var response tokenResponse
+	if err := s.post(ctx, s.config.TokenURL, form, &response); err != nil {
+		return nil, err
+	}
+	if response.AccessToken == "" {
+		return nil, fmt.Errorf("token response has no access_token")
+	}
+	redact.Register(response.AccessToken, response.RefreshToken)
+	token := &Token{AccessToken: response.AccessToken, RefreshToken: response.RefreshToken}
+	if response.ExpiresIn > 0 {
+		token.Expiry = time.Now().Add(time.Duration(response.ExpiresIn) * time.Second)
+	}
+	return token, nil
+}
+
+// tokenError is an error response from an OAuth endpoint
+type tokenError struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description"`
+}
+
+func (e *tokenError) Error() string {
+	if e.Description != "" {
+		return fmt.Sprintf("%s: %s", e.Code, e.Description)
+	}
+	return e.Code
+}
+
+// post sends a form to an OAuth endpoint, decoding its JSON response into result
+func (s *Source) post(ctx context.Context, endpoint string, form url.Values, result interface{}) error {
+	if s.config.ClientSecret == "" {
+		form.Set("client_id", s.config.ClientID)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	if s.config.ClientSecret != "" {
+		req.SetBasicAuth(url.QueryEscape(s.config.ClientID), url.QueryEscape(s.config.ClientSecret))
+	}
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode != http.StatusOK {
+		var tokenErr tokenError
+		if json.Unmarshal(body, &tokenErr) == nil && tokenErr.Code != "" {
+			return &tokenErr
+		}
+		return fmt.Errorf("%s returned %s", endpoint, resp.Status)
+	}
+	if err := json.Unmarshal(body, result); err != nil {
+		return fmt.Errorf("invalid response from %s: %w", endpoint, err)
+	}
+	return nil
+}
+
+// Transport authorizes each request with the source's access token. If the server rejects a token,
+// it is renewed and the request retried once.
+type Transport struct {
+	Source *Source
+	Base   http.RoundTripper // defaults to http.DefaultTransport
+}
+
+// RoundTrip sends a request with an Authorization header
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resp, token, err := t.send(req)
+	if err != nil || resp.StatusCode != http.StatusUnauthorized || (req.Body != nil && req.GetBody == nil) {
+		return resp, err
+	}
+
+	t.Source.Invalidate(token)
+	retry := req.Clone(req.Context())
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return resp, nil
+		}
+	}
+	resp.Body.Close()
+	resp, _, err = t.send(retry)
+	return resp, err
+}
+
+// send makes one attempt at a request with the current token
+func (t *Transport) send(req *http.Request) (*http.Response, *Token, error) {
+	token, err := t.Source.Token(req.Context())
+	if err != nil {
+		return nil, nil, err
+	}
+	authorized := req.Clone(req.Context())
+	authorized.Header.Set("Authorization", "Bearer "+token.AccessToken)
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	resp, err := base.RoundTrip(authorized)
+	return resp, token, err
+}
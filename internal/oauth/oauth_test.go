@@ -0,0 +1,259 @@
+package oauth
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/secrets"
+)
+
+// authServer is a fake authorization server recording the grants it was asked for
+type authServer struct {
+	*httptest.Server
+	mu      sync.Mutex
+	grants  []string
+	pending int // device token polls answered with authorization_pending
+	issued  int
+	devices int // device authorizations requested
+}
+
+func newAuthServer(t *testing.T) *authServer {
+	a := &authServer{}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/device", func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		a.devices++
+		a.mu.Unlock()
+		json.NewEncoder(w).Encode(map[string]any{
+			"device_code": "dev-123", "user_code": "ABCD-EFGH",
+			"verification_uri": "https://example.com/activate", "expires_in": 600, "interval": 1,
+		})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+		grant := r.FormValue("grant_type")
+		a.grants = append(a.grants, grant)
+		if grant == "urn:ietf:params:oauth:grant-type:device_code" && a.pending > 0 {
+			a.pending--
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "authorization_pending"})
+			return
+		}
+		if grant == "refresh_token" && r.FormValue("refresh_token") != "refresh-1" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant", "error_description": "unknown refresh token"})
+			return
+		}
+		a.issued++
+		json.NewEncoder(w).Encode(map[string]any{
+			"access_token": fmt.Sprintf("access-%d", a.issued), "refresh_token": "refresh-1", "expires_in": 3600,
+		})
+	})
+	a.Server = httptest.NewServer(mux)
+	t.Cleanup(a.Close)
+	return a
+}
+
+func (a *authServer) requestedGrants() []string {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]string(nil), a.grants...)
+}
+
+func TestSource_ClientCredentials(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	auth := newAuthServer(t)
+	cfg := &config.OAuthConfig{TokenURL: auth.URL + "/token", ClientID: "client", ClientSecret: "secret"}
+
+	token, err := NewSource("remote", cfg).Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token.AccessToken != "access-1" || token.Expiry.Before(time.Now().Add(time.Hour-time.Minute)) {
+		t.Errorf("Token() = %+v, want access-1 expiring in an hour", token)
+	}
+
+	// The token is stored, so a new source (as after a restart) reuses it
+	stored, err := secrets.Get(SecretName("remote"))
+	if err != nil || !strings.Contains(stored, "access-1") {
+		t.Errorf("Stored token = %q, %v", stored, err)
+	}
+	token, err = NewSource("remote", cfg).Token(context.Background())
+	if err != nil || token.AccessToken != "access-1" {
+		t.Errorf("Token() after restart = %+v, %v, want the stored token", token, err)
+	}
+	if grants := auth.requestedGrants(); len(grants) != 1 {
+		t.Errorf("Requested grants %v, want one", grants)
+	}
+}
+
+func TestSource_Refresh(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	auth := newAuthServer(t)
+	cfg := &config.OAuthConfig{TokenURL: auth.URL + "/token", ClientID: "client", ClientSecret: "secret"}
+
+	tests := []struct {
+		name         string
+		refreshToken string
+		wantGrants   []string
+	}{
+		{"refreshed", "refresh-1", []string{"refresh_token"}},
+		{"authorized again when refresh fails", "revoked", []string{"refresh_token", "client_credentials"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			auth.mu.Lock()
+			auth.grants = nil
+			auth.mu.Unlock()
+			expired, _ := json.Marshal(Token{AccessToken: "old", RefreshToken: tt.refreshToken, Expiry: time.Now().Add(-time.Minute)})
+			if err := secrets.Set(SecretName("remote"), string(expired)); err != nil {
+				t.Fatal(err)
+			}
+
+			token, err := NewSource("remote", cfg).Token(context.Background())
+			if err != nil {
+				t.Fatalf("Token() error = %v", err)
+			}
+			if token.AccessToken == "old" || token.RefreshToken != "refresh-1" {
+				t.Errorf("Token() = %+v, want a renewed token", token)
+			}
+			if grants := auth.requestedGrants(); strings.Join(grants, ",") != strings.Join(tt.wantGrants, ",") {
+				t.Errorf("Requested grants %v, want %v", grants, tt.wantGrants)
+			}
+		})
+	}
+}
+
+func TestSource_DeviceFlow(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	var waits []time.Duration
+	defer func(s func(context.Context, time.Duration) error) { sleep = s }(sleep)
+	sleep = func(ctx context.Context, d time.Duration) error {
+		waits = append(waits, d)
+		return nil
+	}
+	var prompt bytes.Buffer
+	defer func(w io.Writer) { promptOutput = w }(promptOutput)
+	promptOutput = &prompt
+
+	auth := newAuthServer(t)
+	auth.pending = 2
+	cfg := &config.OAuthConfig{
+		Flow:                   config.OAuthDevice,
+		TokenURL:               auth.URL + "/token",
+		DeviceAuthorizationURL: auth.URL + "/device",
+		ClientID:               "cli",
+	}
+
+	token, err := NewSource("remote", cfg).Token(context.Background())
+	if err != nil {
+		t.Fatalf("Token() error = %v", err)
+	}
+	if token.AccessToken != "access-1" {
+		t.Errorf("Token() = %+v, want access-1", token)
+	}
+	if len(waits) != 3 || waits[0] != time.Second {
+		t.Errorf("Waited %v, want three polls a second apart", waits)
+	}
+	if !strings.Contains(prompt.String(), "visit https://example.com/activate and enter the code ABCD-EFGH") {
+		t.Errorf("Prompt = %q, want the verification URL and user code", prompt.String())
+	}
+}
+
+func TestSource_DeviceFlowReleasesLock(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	polling := make(chan struct{})
+	approved := make(chan struct{})
+	defer func(s func(context.Context, time.Duration) error) { sleep = s }(sleep)
+	sleep = func(ctx context.Context, d time.Duration) error {
+		close(polling)
+		<-approved
+		return nil
+	}
+	defer func(w io.Writer) { promptOutput = w }(promptOutput)
+	promptOutput = io.Discard
+
+	auth := newAuthServer(t)
+	source := NewSource("remote", &config.OAuthConfig{
+		Flow:                   config.OAuthDevice,
+		TokenURL:               auth.URL + "/token",
+		DeviceAuthorizationURL: auth.URL + "/device",
+		ClientID:               "cli",
+	})
+
+	tokens := make(chan *Token, 2)
+	for range 2 {
+		go func() {
+			token, err := source.Token(context.Background())
+			if err != nil {
+				t.Errorf("Token() error = %v", err)
+			}
+			tokens <- token
+		}()
+	}
+
+	// Other callers aren't blocked while the flow waits for the user
+	<-polling
+	invalidated := make(chan struct{})
+	go func() {
+		source.Invalidate(&Token{AccessToken: "unrelated"})
+		close(invalidated)
+	}()
+	select {
+	case <-invalidated:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Invalidate() blocked while the device flow was polling")
+	}
+
+	close(approved)
+	first, second := <-tokens, <-tokens
+	if first == nil || second == nil || first.AccessToken != second.AccessToken {
+		t.Errorf("Token() = %+v and %+v, want the same token", first, second)
+	}
+	if auth.devices != 1 {
+		t.Errorf("Requested %d device authorizations, want 1", auth.devices)
+	}
+}
+
+func TestTransport_RetriesRejectedToken(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	auth := newAuthServer(t)
+	cfg := &config.OAuthConfig{TokenURL: auth.URL + "/token", ClientID: "client", ClientSecret: "secret"}
+
+	// The resource server only accepts the second token issued, as if the first had been revoked
+	var bodies []string
+	resource := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies = append(bodies, string(body))
+		if r.Header.Get("Authorization") != "Bearer access-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.Write([]byte("ok"))
+	}))
+	defer resource.Close()
+
+	client := &http.Client{Transport: &Transport{Source: NewSource("remote", cfg)}}
+	resp, err := client.Post(resource.URL, "application/json", strings.NewReader(`{"id":1}`))
+	if err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("Status = %d, want 200 after renewing the token", resp.StatusCode)
+	}
+	if len(bodies) != 2 || bodies[1] != `{"id":1}` {
+		t.Errorf("Resource received %q, want the body sent twice", bodies)
+	}
+}
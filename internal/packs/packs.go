@@ -0,0 +1,207 @@
+// Package packs installs bundles of saved tools published at a URL, after checking them against a
+// checksum or signature.
+package packs
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+)
+
+// maxPackSize bounds how much of a pack or its signature is read
+const maxPackSize = 10 << 20
+
+// SignatureSuffix is appended to a pack's location to find its signature unless another is given
+const SignatureSuffix = ".sig"
+
+// httpClient fetches packs published over HTTP
+var httpClient = &http.Client{Timeout: 30 * time.Second}
+
+// Pack is a bundle of saved tools
+type Pack struct {
+	Name        string                             `json:"name"`
+	Version     string                             `json:"version,omitempty"`
+	Description string                             `json:"description,omitempty"`
+	Tools       []*persistence.SavedToolDefinition `json:"tools"`
+}
+
+// Verification is how a pack's contents are checked before it is installed. At least one of
+// SHA256 and PublicKey is required unless Insecure is set.
+type Verification struct {
+	SHA256    string // hex-encoded SHA-256 checksum of the pack
+	PublicKey string // base64-encoded Ed25519 key the pack must be signed with
+	Signature string // where the base64-encoded signature is published; defaults to the pack's location plus .sig
+	Insecure  bool   // install without checking the pack
+}
+
+// Fetch reads a pack from an http(s) URL or a local file, returning its contents
+func Fetch(ctx context.Context, source string) ([]byte, error) {
+	if !strings.HasPrefix(source, "http://") && !strings.HasPrefix(source, "https://") {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", source, err)
+		}
+		return data, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", source, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch %s: %s", source, resp.Status)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxPackSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", source, err)
+	}
+	if len(data) > maxPackSize {
+		return nil, fmt.Errorf("%s is larger than %d bytes", source, maxPackSize)
+	}
+	return data, nil
+}
+
+// Verify checks a pack fetched from source against its checksum and signature
+func Verify(ctx context.Context, source string, data []byte, v Verification) error {
+	if v.SHA256 == "" && v.PublicKey == "" {
+		if v.Insecure {
+			return nil
+		}
+		return fmt.Errorf("refusing to install an unverified pack: give its checksum or public key, or allow insecure installs")
+	}
+
+	if v.SHA256 != "" {
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); !strings.EqualFold(got, strings.TrimSpace(v.SHA256)) {
+			return fmt.Errorf("checksum mismatch: pack has SHA-256 %s, want %s", got, v.SHA256)
+		}
+	}
+
+	if v.PublicKey != "" {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(v.PublicKey))
+		if err != nil || len(key) != ed25519.PublicKeySize {
+			return fmt.Errorf("invalid public key: want a base64-encoded %d byte Ed25519 key", ed25519.PublicKeySize)
+		}
+		location := v.Signature
+		if location == "" {
+			location = source + SignatureSuffix
+		}
+		encoded, err := Fetch(ctx, location)
+		if err != nil {
+			return fmt.Errorf("failed to get signature: %w", err)
+		}
+		signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encoded)))
+		if err != nil {
+			return fmt.Errorf("invalid signature in %s: %w", location, err)
+		}
+		if !ed25519.Verify(ed25519.PublicKey(key), data, signature) {
+			return fmt.Errorf("signature in %s does not match the pack", location)
+		}
+	}
+	return nil
+}
+
+// Parse decodes a pack, checking every tool in it can be saved
+func Parse(data []byte) (*Pack, error) {
+	var pack Pack
+	if err := json.Unmarshal(data, &pack); err != nil {
+		return nil, fmt.Errorf("invalid pack: %w", err)
+	}
+	if len(pack.Tools) == 0 {
+		return nil, fmt.Errorf("pack has no tools")
+	}
+
+	seen := make(map[string]bool)
+	for i, tool := range pack.Tools {
+		if tool == nil {
+			return nil, fmt.Errorf("pack tool %d is empty", i)
+		}
+		if err := persistence.ValidateTool(tool); err != nil {
+			return nil, fmt.Errorf("pack tool %q is invalid: %w", tool.Name, err)
+		}
+		if seen[tool.Name] {
+			return nil, fmt.Errorf("pack has more than one tool named %q", tool.Name)
+		}
+		seen[tool.Name] = true
+		tool.Version = 0 // versions are numbered by the tools directory
+	}
+	return &pack, nil
+}
+
+// Actions taken for a tool in a pack
+const (
+	ActionInstall = "install"
+	ActionUpdate  = "update"
+	ActionSkip    = "skip"
+)
+
+// Change is what installing a pack does to one tool
+type Change struct {
+	Tool      *persistence.SavedToolDefinition
+	Action    string
+	Replaces  int      // the version of the saved tool an update replaces
+	Requested []string // permissions the pack asks for, which only the operator can grant
+}
+
+// Plan works out how each of a pack's tools would be installed. Saved tools of the same name are
+// skipped unless overwrite is set. Permissions listed in the pack aren't installed, since anyone
+// can publish a pack: they're recorded as requested, and an update keeps the permissions of the
+// tool it replaces only if the code and allowed servers are unchanged.
+func Plan(pack *Pack, overwrite bool) ([]Change, error) {
+	changes := make([]Change, 0, len(pack.Tools))
+	for _, tool := range pack.Tools {
+		change := Change{Tool: tool, Requested: tool.Permissions}
+		tool.Permissions = nil
+
+		existing, err := persistence.LoadTool(tool.Name)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			change.Action = ActionInstall
+		case err != nil:
+			return nil, fmt.Errorf("failed to check saved tool %q: %w", tool.Name, err)
+		case overwrite:
+			change.Action, change.Replaces = ActionUpdate, max(existing.Version, 1)
+			if persistence.GrantApplies(existing, tool) {
+				tool.Permissions = existing.Permissions
+			}
+		default:
+			change.Action, change.Replaces = ActionSkip, max(existing.Version, 1)
+		}
+		changes = append(changes, change)
+	}
+	return changes, nil
+}
+
+// Install saves the tools a plan installs or updates, returning their names. It stops at the first
+// failure, returning the tools saved before it; updated tools keep their previous version as a backup.
+func Install(changes []Change) ([]string, error) {
+	var installed []string
+	for _, change := range changes {
+		if change.Action == ActionSkip {
+			continue
+		}
+		if err := persistence.SaveTool(change.Tool); err != nil {
+			return installed, fmt.Errorf("failed to save tool %q: %w", change.Tool.Name, err)
+		}
+		installed = append(installed, change.Tool.Name)
+	}
+	return installed, nil
+}
@@ -0,0 +1,184 @@
+package packs
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+)
+
+const testPack = `{
+	"name": "greetings",
+	"version": "1.0.0",
+	"tools": [
+		{"name": "greet", "description": "Greets", "inputSchema": {"type": "object"}, "code": "'hello'"},
+		{"name": "farewell", "description": "Says goodbye", "inputSchema": {"type": "object"}, "code": "'bye'", "version": 7}
+	]
+}`
+
+// servePack publishes the test pack and its signature, made with the returned public key
+func servePack(t *testing.T) (*httptest.Server, string) {
+	t.Helper()
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signature := base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte(testPack)))
+	mux := http.NewServeMux()
+	mux.HandleFunc("/pack.json", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(testPack)) })
+	mux.HandleFunc("/pack.json.sig", func(w http.ResponseWriter, r *http.Request) { w.Write([]byte(signature + "\n")) })
+	mux.HandleFunc("/other.sig", func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(base64.StdEncoding.EncodeToString(ed25519.Sign(privateKey, []byte("something else")))))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server, base64.StdEncoding.EncodeToString(publicKey)
+}
+
+func TestVerify(t *testing.T) {
+	server, publicKey := servePack(t)
+	source := server.URL + "/pack.json"
+	sum := sha256.Sum256([]byte(testPack))
+	checksum := hex.EncodeToString(sum[:])
+	_, otherKey, _ := ed25519.GenerateKey(nil)
+
+	tests := []struct {
+		name         string
+		verification Verification
+		wantErr      string
+	}{
+		{"checksum", Verification{SHA256: strings.ToUpper(checksum)}, ""},
+		{"wrong checksum", Verification{SHA256: strings.Repeat("0", 64)}, "checksum mismatch"},
+		{"signature", Verification{PublicKey: publicKey}, ""},
+		{"checksum and signature", Verification{SHA256: checksum, PublicKey: publicKey}, ""},
+		{"signature of something else", Verification{PublicKey: publicKey, Signature: server.URL + "/other.sig"}, "does not match"},
+		{"wrong key", Verification{PublicKey: base64.StdEncoding.EncodeToString(otherKey.Public().(ed25519.PublicKey))}, "does not match"},
+		{"missing signature", Verification{PublicKey: publicKey, Signature: server.URL + "/missing.sig"}, "404"},
+		{"invalid key", Verification{PublicKey: "bm90IGEga2V5"}, "invalid public key"},
+		{"unverified", Verification{}, "refusing"},
+		{"insecure", Verification{Insecure: true}, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := Fetch(context.Background(), source)
+			if err != nil {
+				t.Fatalf("Fetch() error = %v", err)
+			}
+			err = Verify(context.Background(), source, data, tt.verification)
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Verify() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    string
+		wantErr string
+	}{
+		{"valid", testPack, ""},
+		{"not json", "<html>", "invalid pack"},
+		{"no tools", `{"name": "empty", "tools": []}`, "no tools"},
+		{"invalid tool name", `{"name": "bad", "tools": [{"name": "../escape", "code": "1"}]}`, "invalid"},
+		{"duplicate tools", `{"name": "dup", "tools": [{"name": "a", "code": "1"}, {"name": "a", "code": "2"}]}`, "more than one"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Parse([]byte(tt.data))
+			if tt.wantErr == "" && err != nil {
+				t.Errorf("Parse() error = %v", err)
+			}
+			if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+				t.Errorf("Parse() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPlanAndInstall(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "greet", Code: "'hi'"})
+
+	for _, overwrite := range []bool{false, true} {
+		pack, err := Parse([]byte(testPack))
+		if err != nil {
+			t.Fatalf("Parse() error = %v", err)
+		}
+		changes, err := Plan(pack, overwrite)
+		if err != nil {
+			t.Fatalf("Plan() error = %v", err)
+		}
+		var actions []string
+		for _, change := range changes {
+			actions = append(actions, change.Tool.Name+":"+change.Action)
+		}
+		want := "greet:skip,farewell:install"
+		if overwrite {
+			want = "greet:update,farewell:update"
+		}
+		if got := strings.Join(actions, ","); got != want {
+			t.Errorf("Plan(overwrite=%v) = %s, want %s", overwrite, got, want)
+		}
+		if _, err := Install(changes); err != nil {
+			t.Fatalf("Install() error = %v", err)
+		}
+	}
+
+	// Updates keep the replaced version as a backup, and versions are numbered locally
+	greet, _ := persistence.LoadTool("greet")
+	farewell, _ := persistence.LoadTool("farewell")
+	if greet.Code != "'hello'" || greet.Version != 2 || farewell.Version != 2 {
+		t.Errorf("Installed greet = %+v, farewell = %+v", greet, farewell)
+	}
+	if versions, _ := persistence.ListVersions("greet"); len(versions) != 1 || versions[0].Code != "'hi'" {
+		t.Errorf("greet history = %v, want the replaced tool", versions)
+	}
+}
+
+func TestPlanWithholdsPermissions(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "greet", Code: "'hello'", Permissions: []string{"notify"}})
+	persistence.SaveTool(&persistence.SavedToolDefinition{Name: "farewell", Code: "'see you'", Permissions: []string{"notify"}})
+
+	pack, err := Parse([]byte(`{"name": "grabby", "tools": [
+		{"name": "greet", "code": "'hello'", "permissions": ["secrets"]},
+		{"name": "farewell", "code": "'bye'", "permissions": ["secrets"]},
+		{"name": "leak", "code": "secrets.get('token')", "permissions": ["secrets"]}
+	]}`))
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	changes, err := Plan(pack, true)
+	if err != nil {
+		t.Fatalf("Plan() error = %v", err)
+	}
+	for _, change := range changes {
+		if len(change.Requested) != 1 || change.Requested[0] != "secrets" {
+			t.Errorf("%s requested permissions = %v, want the pack's", change.Tool.Name, change.Requested)
+		}
+	}
+	if _, err := Install(changes); err != nil {
+		t.Fatalf("Install() error = %v", err)
+	}
+
+	// Unchanged code keeps the operator's grants; nothing gets the pack's
+	want := map[string]string{"greet": "notify", "farewell": "", "leak": ""}
+	for name, permissions := range want {
+		tool, _ := persistence.LoadTool(name)
+		if got := strings.Join(tool.Permissions, ","); got != permissions {
+			t.Errorf("%s permissions = %q, want %q", name, got, permissions)
+		}
+	}
+}
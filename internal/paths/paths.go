@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/dslh/mcp-metatool/internal/storage"
 )
 
 // GetMetatoolDir returns the directory where metatool files are stored
@@ -23,7 +25,7 @@ func GetMetatoolDir() (string, error) {
 	}
 
 	// Create directory if it doesn't exist
-	if err := os.MkdirAll(metatoolDir, 0755); err != nil {
+	if err := storage.MkdirAll(metatoolDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create metatool directory: %w", err)
 	}
 
@@ -40,13 +42,22 @@ func GetToolsDir() (string, error) {
 	toolsDir := filepath.Join(metatoolDir, "tools")
 
 	// Create directory if it doesn't exist
-	if err := os.MkdirAll(toolsDir, 0755); err != nil {
+	if err := storage.MkdirAll(toolsDir, 0755); err != nil {
 		return "", fmt.Errorf("failed to create tools directory: %w", err)
 	}
 
 	return toolsDir, nil
 }
 
+// GetToolsDBPath returns the path of the SQLite database saved tools are kept in when that backend is used
+func GetToolsDBPath() (string, error) {
+	metatoolDir, err := GetMetatoolDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(metatoolDir, "tools.db"), nil
+}
+
 // GetConfigPath returns the full path to the servers.json configuration file
 func GetConfigPath() (string, error) {
 	metatoolDir, err := GetMetatoolDir()
@@ -55,4 +66,80 @@ func GetConfigPath() (string, error) {
 	}
 
 	return filepath.Join(metatoolDir, "servers.json"), nil
-}
\ No newline at end of file
+}
+
+// GetApprovalsDir returns the directory where calls awaiting approval are queued
+func GetApprovalsDir() (string, error) {
+	return getSubDir("approvals")
+}
+
+// GetArtifactsDir returns the directory where published result artifacts are stored
+func GetArtifactsDir() (string, error) {
+	return getSubDir("artifacts")
+}
+
+// GetCacheDir returns the directory where discovered upstream tools are cached
+func GetCacheDir() (string, error) {
+	return getSubDir("cache")
+}
+
+// GetCompiledDir returns the directory where compiled Starlark programs of saved tools are kept
+func GetCompiledDir() (string, error) {
+	return getSubDir("compiled")
+}
+
+// GetResultsDir returns the directory where the latest result of each saved tool is recorded
+func GetResultsDir() (string, error) {
+	return getSubDir("results")
+}
+
+// GetAdminSocketPath returns the default path of the admin API unix socket
+func GetAdminSocketPath() (string, error) {
+	metatoolDir, err := GetMetatoolDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(metatoolDir, "admin.sock"), nil
+}
+
+// GetUsagePath returns the path of the file recording how often each tool is called
+func GetUsagePath() (string, error) {
+	metatoolDir, err := GetMetatoolDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(metatoolDir, "usage.json"), nil
+}
+
+// GetHistoryPath returns the path of the file logging recent tool calls
+func GetHistoryPath() (string, error) {
+	metatoolDir, err := GetMetatoolDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(metatoolDir, "history.json"), nil
+}
+
+// GetSecretsPath returns the path of the file holding secrets available to Starlark code
+func GetSecretsPath() (string, error) {
+	metatoolDir, err := GetMetatoolDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(metatoolDir, "secrets.json"), nil
+}
+
+// getSubDir returns a named subdirectory of the metatool directory, creating it if needed
+func getSubDir(name string) (string, error) {
+	metatoolDir, err := GetMetatoolDir()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Join(metatoolDir, name)
+	if err := storage.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s directory: %w", name, err)
+	}
+
+	return dir, nil
+}
@@ -270,4 +270,23 @@ func TestPathsIntegration(t *testing.T) {
 			t.Errorf("ConfigPath = %v, want %v", configPath, expectedConfigPath)
 		}
 	})
-}
\ No newline at end of file
+}
+func TestGetApprovalsDir(t *testing.T) {
+	tempDir := t.TempDir()
+	testDir := filepath.Join(tempDir, "test-metatool")
+	t.Setenv("MCP_METATOOL_DIR", testDir)
+
+	dir, err := GetApprovalsDir()
+	if err != nil {
+		t.Fatalf("GetApprovalsDir() error = %v", err)
+	}
+
+	expectedDir := filepath.Join(testDir, "approvals")
+	if dir != expectedDir {
+		t.Errorf("GetApprovalsDir() = %v, want %v", dir, expectedDir)
+	}
+
+	if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+		t.Errorf("Approvals directory was not created: %v", dir)
+	}
+}
@@ -0,0 +1,59 @@
+package persistence
+
+import "fmt"
+
+// Storage backends for saved tools
+const (
+	FileBackend   = "files"
+	SQLiteBackend = "sqlite"
+)
+
+// Backend stores saved tool definitions along with the archived versions of each
+type Backend interface {
+	// Save archives any current definition of the tool and stores the new one, setting its version
+	Save(tool *SavedToolDefinition) error
+	// SaveIfVersion saves a tool like Save, but only if its current version is expectedVersion (0 if
+	// it mustn't exist yet), checked atomically with the save even against other processes.
+	// Otherwise it returns a *VersionConflictError.
+	SaveIfVersion(tool *SavedToolDefinition, expectedVersion int) error
+	// Load returns a tool's current definition; the error wraps fs.ErrNotExist if it has none
+	Load(name string) (*SavedToolDefinition, error)
+	// List returns the current definition of every tool
+	List() ([]*SavedToolDefinition, error)
+	// Delete archives a tool's current definition and removes it
+	Delete(name string) error
+	// Versions returns the archived version numbers of a tool in ascending order
+	Versions(name string) ([]int, error)
+	// LoadVersion returns an archived version of a tool
+	LoadVersion(name string, version int) (*SavedToolDefinition, error)
+}
+
+// current is the backend used by the package-level functions
+var current Backend = fileBackend{}
+
+// UseBackend selects where saved tools are stored: FileBackend, the default, or SQLiteBackend
+func UseBackend(name string) error {
+	switch name {
+	case "", FileBackend:
+		current = fileBackend{}
+	case SQLiteBackend:
+		current = newSQLiteBackend()
+	default:
+		return fmt.Errorf("unknown tool storage backend %q, want %s or %s", name, FileBackend, SQLiteBackend)
+	}
+	return nil
+}
+
+// Use replaces the backend used by the package-level functions, e.g. with one wrapping Current
+func Use(backend Backend) {
+	current = backend
+}
+
+// Current returns the backend used by the package-level functions
+func Current() Backend {
+	return current
+}
+
+// fileBackend keeps each tool in its own files in the tools directory, with its history in a
+// subdirectory named after it
+type fileBackend struct{}
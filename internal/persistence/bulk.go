@@ -0,0 +1,48 @@
+package persistence
+
+import (
+	"fmt"
+	"path"
+	"sort"
+)
+
+// MatchTools returns the names of saved tools matching any of the glob patterns, sorted
+func MatchTools(patterns []string) ([]string, error) {
+	if len(patterns) == 0 {
+		return nil, fmt.Errorf("at least one pattern is required")
+	}
+	for _, pattern := range patterns {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	tools, err := ListTools()
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, tool := range tools {
+		for _, pattern := range patterns {
+			if matched, _ := path.Match(pattern, tool.Name); matched {
+				names = append(names, tool.Name)
+				break
+			}
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// DeleteTools deletes each named tool, keeping backups as DeleteTool does. It stops at the first
+// failure, returning the tools deleted before it.
+func DeleteTools(names []string) ([]string, error) {
+	deleted := make([]string, 0, len(names))
+	for _, name := range names {
+		if err := DeleteTool(name); err != nil {
+			return deleted, fmt.Errorf("failed to delete tool '%s': %w", name, err)
+		}
+		deleted = append(deleted, name)
+	}
+	return deleted, nil
+}
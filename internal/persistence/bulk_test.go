@@ -0,0 +1,67 @@
+package persistence
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestMatchTools(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	for _, name := range []string{"experiment_b", "experiment_a", "report", "report_weekly"} {
+		if err := SaveTool(&SavedToolDefinition{Name: name, Description: name, Code: "1"}); err != nil {
+			t.Fatalf("SaveTool() error = %v", err)
+		}
+	}
+
+	tests := []struct {
+		name      string
+		patterns  []string
+		want      []string
+		wantError string
+	}{
+		{"prefix glob", []string{"experiment_*"}, []string{"experiment_a", "experiment_b"}, ""},
+		{"exact name", []string{"report"}, []string{"report"}, ""},
+		{"several patterns", []string{"report*", "experiment_?"}, []string{"experiment_a", "experiment_b", "report", "report_weekly"}, ""},
+		{"no matches", []string{"missing_*"}, nil, ""},
+		{"no patterns", nil, nil, "at least one pattern"},
+		{"invalid pattern", []string{"["}, nil, "invalid pattern"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := MatchTools(tt.patterns)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("MatchTools() error = %v, want %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("MatchTools() error = %v", err)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("MatchTools() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDeleteTools(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	SaveTool(&SavedToolDefinition{Name: "one", Description: "one", Code: "1"})
+	SaveTool(&SavedToolDefinition{Name: "two", Description: "two", Code: "2"})
+
+	deleted, err := DeleteTools([]string{"one", "missing", "two"})
+	if err == nil {
+		t.Fatal("DeleteTools() should fail on a missing tool")
+	}
+	if fmt.Sprint(deleted) != "[one]" {
+		t.Errorf("DeleteTools() deleted %v before failing, want [one]", deleted)
+	}
+	if _, err := LoadTool("one"); err == nil {
+		t.Error("one should be deleted")
+	}
+	if _, err := LoadTool("two"); err != nil {
+		t.Error("DeleteTools() should stop at the first failure")
+	}
+}
@@ -0,0 +1,94 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"sync"
+
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// saveMu serializes checking a tool's version with saving it, so concurrent saves in this process
+// can't both pass the check
+var saveMu sync.Mutex
+
+// VersionConflictError reports that a tool changed after the version a save expected was read
+type VersionConflictError struct {
+	Name     string
+	Expected int
+	Current  int // 0 if the tool doesn't exist
+}
+
+func (e *VersionConflictError) Error() string {
+	if e.Current == 0 {
+		return fmt.Sprintf("tool '%s' was expected at version %d but does not exist", e.Name, e.Expected)
+	}
+	if e.Expected == 0 {
+		return fmt.Sprintf("tool '%s' already exists (version %d)", e.Name, e.Current)
+	}
+	return fmt.Sprintf("tool '%s' has changed since version %d was read; it is now version %d", e.Name, e.Expected, e.Current)
+}
+
+// SaveToolIfUnchanged saves a tool only if its current version is expectedVersion, so edits based on
+// an outdated definition don't overwrite newer ones. An expectedVersion of 0 requires that the tool
+// doesn't exist yet. Otherwise it returns a *VersionConflictError. The check holds against saves by
+// other metatool processes too, such as a second agent's.
+func SaveToolIfUnchanged(tool *SavedToolDefinition, expectedVersion int) error {
+	saveMu.Lock()
+	defer saveMu.Unlock()
+
+	if err := ValidateTool(tool); err != nil {
+		return err
+	}
+	stampTool(tool)
+	return current.SaveIfVersion(tool, expectedVersion)
+}
+
+// checkVersion returns a *VersionConflictError unless a tool's current version, as loaded by load,
+// is expectedVersion
+func checkVersion(name string, expectedVersion int, load func(string) (*SavedToolDefinition, error)) error {
+	currentVersion := 0
+	previous, err := load(name)
+	if err == nil {
+		// Definitions saved before versioning count as the first version
+		currentVersion = max(previous.Version, 1)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return err
+	}
+	if currentVersion != expectedVersion {
+		return &VersionConflictError{Name: name, Expected: expectedVersion, Current: currentVersion}
+	}
+	return nil
+}
+
+// lockToolsDir locks the tools directory against changes by other processes, creating it if it
+// doesn't exist, and returns a function that releases it. Nothing is locked in ephemeral mode,
+// where no other process can see the tools.
+func lockToolsDir(toolsDir string) (func(), error) {
+	if storage.IsEphemeral() {
+		return func() {}, nil
+	}
+	if err := storage.MkdirAll(toolsDir, 0755); err != nil {
+		return nil, fmt.Errorf("failed to create tools directory: %w", err)
+	}
+	return lockDir(toolsDir)
+}
+
+// SaveIfVersion checks the tool's version and saves it while holding the tools directory lock
+func (b fileBackend) SaveIfVersion(tool *SavedToolDefinition, expectedVersion int) error {
+	toolsDir, err := GetToolsDirectory()
+	if err != nil {
+		return err
+	}
+	unlock, err := lockToolsDir(toolsDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := checkVersion(tool.Name, expectedVersion, b.Load); err != nil {
+		return err
+	}
+	return b.save(toolsDir, tool)
+}
@@ -0,0 +1,52 @@
+package persistence
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestSaveToolIfUnchanged(t *testing.T) {
+	for _, backend := range []string{FileBackend, SQLiteBackend} {
+		t.Run(backend, func(t *testing.T) {
+			t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+			if backend == SQLiteBackend {
+				useSQLite(t)
+			}
+			testSaveToolIfUnchanged(t)
+		})
+	}
+}
+
+func testSaveToolIfUnchanged(t *testing.T) {
+	tests := []struct {
+		name         string
+		expected     int
+		wantConflict bool
+		wantVersion  int
+	}{
+		{"created when expected not to exist", 0, false, 1},
+		{"refused when it already exists", 0, true, 1},
+		{"saved at the read version", 1, false, 2},
+		{"refused after a newer save", 1, true, 2},
+		{"refused at a future version", 5, true, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SaveToolIfUnchanged(&SavedToolDefinition{Name: "greet", Code: "1"}, tt.expected)
+			var conflict *VersionConflictError
+			if errors.As(err, &conflict) != tt.wantConflict {
+				t.Fatalf("SaveToolIfUnchanged(%d) error = %v, want conflict %v", tt.expected, err, tt.wantConflict)
+			}
+			if !tt.wantConflict && err != nil {
+				t.Fatalf("SaveToolIfUnchanged(%d) error = %v", tt.expected, err)
+			}
+			if tool, _ := LoadTool("greet"); tool.Version != tt.wantVersion {
+				t.Errorf("Version = %d, want %d", tool.Version, tt.wantVersion)
+			}
+		})
+	}
+
+	if err := SaveToolIfUnchanged(&SavedToolDefinition{Name: "missing", Code: "1"}, 3); err == nil {
+		t.Error("Expected a conflict saving a missing tool at version 3")
+	}
+}
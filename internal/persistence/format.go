@@ -0,0 +1,154 @@
+package persistence
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// toolExtensions are the extensions of tool definition files, in order of precedence
+// when a tool has more than one
+var toolExtensions = []string{".json", ".yaml", ".yml"}
+
+// codeSuffix is the extension of the file holding a tool's Starlark code beside its definition
+const codeSuffix = ".star"
+
+// codeFilename returns the path of a tool's code file
+func codeFilename(toolsDir, name string) string {
+	return filepath.Join(toolsDir, name+codeSuffix)
+}
+
+// readTool reads a tool's definition, taking its code from its code file if it has one, and
+// returns the definition's filename
+func readTool(toolsDir, name string) (*SavedToolDefinition, string, error) {
+	filename, err := toolFilename(toolsDir, name)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find tool file: %w", err)
+	}
+	data, err := storage.ReadFile(filename)
+	if err != nil {
+		return nil, filename, fmt.Errorf("failed to read tool file: %w", err)
+	}
+	tool, err := decodeTool(filename, data)
+	if err != nil {
+		return nil, filename, fmt.Errorf("failed to unmarshal tool: %w", err)
+	}
+
+	code, err := storage.ReadFile(codeFilename(toolsDir, name))
+	if err == nil {
+		tool.Code = string(code)
+	} else if !os.IsNotExist(err) {
+		return nil, filename, fmt.Errorf("failed to read tool code: %w", err)
+	}
+	return tool, filename, nil
+}
+
+// writeTool writes a tool's definition, keeping the format of an existing one. The code goes in
+// a code file beside it, except in YAML definitions, which keep it inline unless it has already
+// been moved to a code file.
+func writeTool(toolsDir string, tool *SavedToolDefinition) error {
+	filename, err := toolFilename(toolsDir, tool.Name)
+	if err != nil {
+		return fmt.Errorf("failed to find tool file: %w", err)
+	}
+
+	definition := *tool
+	splitCode := !isYAML(filename)
+	if !splitCode {
+		if _, err := storage.Stat(codeFilename(toolsDir, tool.Name)); err == nil {
+			splitCode = true
+		}
+	}
+	if splitCode {
+		if err := storage.WriteFile(codeFilename(toolsDir, tool.Name), []byte(tool.Code), 0644); err != nil {
+			return fmt.Errorf("failed to write tool code: %w", err)
+		}
+		definition.Code = ""
+	}
+
+	data, err := encodeTool(filename, &definition)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tool: %w", err)
+	}
+	if err := storage.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("failed to write tool file: %w", err)
+	}
+	return nil
+}
+
+// toolFilename returns the path of a tool's definition file, or of a new JSON file if it has none
+func toolFilename(toolsDir, name string) (string, error) {
+	for _, ext := range toolExtensions {
+		filename := filepath.Join(toolsDir, name+ext)
+		if _, err := storage.Stat(filename); err == nil {
+			return filename, nil
+		} else if !os.IsNotExist(err) {
+			return "", err
+		}
+	}
+	return filepath.Join(toolsDir, name+".json"), nil
+}
+
+// toolNameFromFile returns the name of the tool defined by a file, if it is a tool definition file
+func toolNameFromFile(filename string) (string, bool) {
+	for _, ext := range toolExtensions {
+		if strings.HasSuffix(filename, ext) {
+			return strings.TrimSuffix(filename, ext), true
+		}
+	}
+	return "", false
+}
+
+// isYAML reports whether a tool file is written in YAML rather than JSON
+func isYAML(filename string) bool {
+	ext := filepath.Ext(filename)
+	return ext == ".yaml" || ext == ".yml"
+}
+
+// decodeTool parses a tool definition in the format its filename indicates. YAML is converted
+// to JSON first, so values decode to the same types whichever format they were written in.
+func decodeTool(filename string, data []byte) (*SavedToolDefinition, error) {
+	if isYAML(filename) {
+		var document interface{}
+		if err := yaml.Unmarshal(data, &document); err != nil {
+			return nil, err
+		}
+		converted, err := json.Marshal(document)
+		if err != nil {
+			return nil, err
+		}
+		data = converted
+	}
+
+	var tool SavedToolDefinition
+	if err := json.Unmarshal(data, &tool); err != nil {
+		return nil, err
+	}
+	return &tool, nil
+}
+
+// encodeTool formats a tool definition for the file it will be written to. In YAML, multi-line
+// code is written as a block scalar so it reads as it would in a .star file.
+func encodeTool(filename string, tool *SavedToolDefinition) ([]byte, error) {
+	if !isYAML(filename) {
+		return json.MarshalIndent(tool, "", "  ")
+	}
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(tool); err != nil {
+		return nil, fmt.Errorf("failed to encode YAML: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
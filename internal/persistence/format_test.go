@@ -0,0 +1,135 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestYAMLTools(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	toolsDir, err := GetToolsDirectory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(toolsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	definition := `name: double
+description: Doubles a number
+inputSchema:
+  type: object
+  properties:
+    n: {type: integer}
+code: |
+  def main(params):
+      return params["n"] * 2
+tests:
+  - name: two
+    params: {n: 2}
+    expected: 4
+`
+	if err := os.WriteFile(filepath.Join(toolsDir, "double.yaml"), []byte(definition), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tool, err := LoadTool("double")
+	if err != nil {
+		t.Fatalf("LoadTool() error = %v", err)
+	}
+	if tool.Code != "def main(params):\n    return params[\"n\"] * 2\n" {
+		t.Errorf("Code = %q", tool.Code)
+	}
+	// Numbers decode as they would from JSON
+	if expected, ok := tool.Tests[0].Expected.(float64); !ok || expected != 4 {
+		t.Errorf("Expected = %#v, want float64 4", tool.Tests[0].Expected)
+	}
+
+	tools, err := ListTools()
+	if err != nil || len(tools) != 1 || tools[0].Name != "double" {
+		t.Fatalf("ListTools() = %v, %v, want the YAML tool", tools, err)
+	}
+
+	// Saving keeps the tool in YAML, with its code as a block scalar, and archives the old version
+	tool.Description = "Doubles n"
+	if err := SaveTool(tool); err != nil {
+		t.Fatalf("SaveTool() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(toolsDir, "double.json")); !os.IsNotExist(err) {
+		t.Error("Expected no JSON file to be written for a YAML tool")
+	}
+	data, err := os.ReadFile(filepath.Join(toolsDir, "double.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(data), "code: |\n  def main(params):\n      return params[\"n\"] * 2\n") {
+		t.Errorf("Expected code as a block scalar, got:\n%s", data)
+	}
+	versions, err := ListVersions("double")
+	if err != nil || len(versions) != 1 || versions[0].Description != "Doubles a number" {
+		t.Errorf("ListVersions() = %v, %v, want the original definition", versions, err)
+	}
+
+	if err := DeleteTool("double"); err != nil {
+		t.Fatalf("DeleteTool() error = %v", err)
+	}
+	if _, err := LoadTool("double"); err == nil {
+		t.Error("Expected the YAML tool to be deleted")
+	}
+}
+
+func TestCodeFiles(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	toolsDir, err := GetToolsDirectory()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(toolsDir, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	// Definitions written before code files existed keep their code inline
+	legacy := `{"name": "greet", "description": "Greets", "inputSchema": {"type": "object"}, "code": "result = 'hi'"}`
+	if err := os.WriteFile(filepath.Join(toolsDir, "greet.json"), []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+	tool, err := LoadTool("greet")
+	if err != nil || tool.Code != "result = 'hi'" {
+		t.Fatalf("LoadTool() = %+v, %v, want the inline code", tool, err)
+	}
+
+	// Saving moves the code to greet.star
+	if err := SaveTool(tool); err != nil {
+		t.Fatalf("SaveTool() error = %v", err)
+	}
+	definition, _ := os.ReadFile(filepath.Join(toolsDir, "greet.json"))
+	if strings.Contains(string(definition), "code") {
+		t.Errorf("Expected the definition without code, got %s", definition)
+	}
+
+	// Editing the code file changes the tool
+	if err := os.WriteFile(filepath.Join(toolsDir, "greet.star"), []byte("result = 'hello'\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if tool, err := LoadTool("greet"); err != nil || tool.Code != "result = 'hello'\n" {
+		t.Errorf("LoadTool() = %+v, %v, want the edited code", tool, err)
+	}
+
+	// History keeps complete definitions
+	versions, err := ListVersions("greet")
+	if err != nil || len(versions) != 1 || versions[0].Code != "result = 'hi'" {
+		t.Errorf("ListVersions() = %v, %v, want the original code", versions, err)
+	}
+
+	if err := DeleteTool("greet"); err != nil {
+		t.Fatalf("DeleteTool() error = %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(toolsDir, "greet.star")); !os.IsNotExist(err) {
+		t.Errorf("Expected greet.star to be deleted, got %v", err)
+	}
+	if tool, err := RestoreTool("greet"); err != nil || tool.Code != "result = 'hello'\n" {
+		t.Errorf("RestoreTool() = %+v, %v, want the deleted code", tool, err)
+	}
+}
@@ -0,0 +1,42 @@
+//go:build !windows
+
+package persistence
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSaveToolIfUnchangedWaitsForOtherProcesses(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	SaveTool(&SavedToolDefinition{Name: "greet", Code: "1"})
+	toolsDir, _ := GetToolsDirectory()
+
+	// Another process holds the lock while it saves a newer version
+	unlock, err := lockDir(toolsDir)
+	if err != nil {
+		t.Fatalf("lockDir() error = %v", err)
+	}
+	saved := make(chan error, 1)
+	go func() {
+		saved <- SaveToolIfUnchanged(&SavedToolDefinition{Name: "greet", Code: "mine"}, 1)
+	}()
+	select {
+	case err := <-saved:
+		t.Fatalf("SaveToolIfUnchanged() = %v while another process held the lock", err)
+	case <-time.After(100 * time.Millisecond):
+	}
+	if err := (fileBackend{}).save(toolsDir, &SavedToolDefinition{Name: "greet", Code: "theirs"}); err != nil {
+		t.Fatalf("save() error = %v", err)
+	}
+	unlock()
+
+	var conflict *VersionConflictError
+	if err := <-saved; !errors.As(err, &conflict) || conflict.Current != 2 {
+		t.Errorf("SaveToolIfUnchanged() error = %v, want a conflict with version 2", err)
+	}
+	if tool, _ := LoadTool("greet"); tool.Code != "theirs" {
+		t.Errorf("Code = %q, want the other process's save kept", tool.Code)
+	}
+}
@@ -0,0 +1,6 @@
+package persistence
+
+// lockDir does nothing on Windows, which has no flock; saves there are only serialized within a process
+func lockDir(dir string) (func(), error) {
+	return func() {}, nil
+}
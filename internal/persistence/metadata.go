@@ -0,0 +1,89 @@
+package persistence
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// Orders SortTools can list tools in
+const (
+	SortByName    = "name"
+	SortByCreated = "created" // newest first
+	SortByUpdated = "updated" // most recently updated first
+)
+
+// stampTool records when a tool is saved. An update keeps the creation time of the definition it
+// replaces, and its author unless a new one is given.
+func stampTool(tool *SavedToolDefinition) {
+	now := time.Now().UTC().Truncate(time.Second)
+	if previous, err := current.Load(tool.Name); err == nil {
+		if previous.CreatedAt != nil {
+			tool.CreatedAt = previous.CreatedAt
+		}
+		if tool.Author == "" {
+			tool.Author = previous.Author
+		}
+	}
+	if tool.CreatedAt == nil {
+		tool.CreatedAt = &now
+	}
+	tool.UpdatedAt = &now
+}
+
+// SavedAt returns when a tool was last saved: its UpdatedAt or CreatedAt time, or for tools saved
+// before those were recorded, the modification time of its definition file. It is zero if unknown.
+func SavedAt(tool *SavedToolDefinition) time.Time {
+	switch {
+	case tool.UpdatedAt != nil:
+		return *tool.UpdatedAt
+	case tool.CreatedAt != nil:
+		return *tool.CreatedAt
+	}
+	toolsDir, err := GetToolsDirectory()
+	if err != nil {
+		return time.Time{}
+	}
+	filename, err := toolFilename(toolsDir, tool.Name)
+	if err != nil {
+		return time.Time{}
+	}
+	info, err := storage.Stat(filename)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
+
+// SortTools orders tools by name, or by when they were created or updated, newest first.
+// Tools without timestamps sort after those with them; ties are broken by name.
+func SortTools(tools []*SavedToolDefinition, by string) error {
+	var timestamp func(*SavedToolDefinition) *time.Time
+	switch by {
+	case "", SortByName:
+	case SortByCreated:
+		timestamp = func(t *SavedToolDefinition) *time.Time { return t.CreatedAt }
+	case SortByUpdated:
+		timestamp = func(t *SavedToolDefinition) *time.Time { return t.UpdatedAt }
+	default:
+		return fmt.Errorf("unknown sort %q: use %s, %s or %s", by, SortByName, SortByCreated, SortByUpdated)
+	}
+
+	sort.SliceStable(tools, func(i, j int) bool {
+		if timestamp != nil {
+			a, b := timestamp(tools[i]), timestamp(tools[j])
+			switch {
+			case a != nil && b == nil:
+				return true
+			case a == nil && b != nil:
+				return false
+			case a != nil && !a.Equal(*b):
+				return a.After(*b)
+			}
+		}
+		return tools[i].Name < tools[j].Name
+	})
+	return nil
+}
@@ -0,0 +1,120 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSaveToolStampsMetadata(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	if err := SaveTool(&SavedToolDefinition{Name: "greet", Code: "1", Author: "ada"}); err != nil {
+		t.Fatal(err)
+	}
+	first, err := LoadTool("greet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if first.CreatedAt == nil || first.UpdatedAt == nil || !first.CreatedAt.Equal(*first.UpdatedAt) {
+		t.Fatalf("First save = created %v, updated %v, want both set and equal", first.CreatedAt, first.UpdatedAt)
+	}
+	if time.Since(*first.CreatedAt) > time.Minute {
+		t.Errorf("CreatedAt = %v, want now", first.CreatedAt)
+	}
+
+	// An update keeps the creation time and author, and moves the update time on
+	created := first.CreatedAt.Add(-time.Hour)
+	first.CreatedAt = &created
+	if err := current.Save(first); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveTool(&SavedToolDefinition{Name: "greet", Code: "2"}); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := LoadTool("greet")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !updated.CreatedAt.Equal(created) || !updated.UpdatedAt.After(created) || updated.Author != "ada" {
+		t.Errorf("Update = created %v, updated %v, author %q; want created %v, a later update, author ada", updated.CreatedAt, updated.UpdatedAt, updated.Author, created)
+	}
+
+	if err := SaveTool(&SavedToolDefinition{Name: "greet", Code: "3", Author: "grace"}); err != nil {
+		t.Fatal(err)
+	}
+	if tool, _ := LoadTool("greet"); tool.Author != "grace" {
+		t.Errorf("Author = %q, want the new author grace", tool.Author)
+	}
+}
+
+func TestSavedAt(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+	created := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	updated := created.AddDate(0, 1, 0)
+
+	if got := SavedAt(&SavedToolDefinition{Name: "greet", CreatedAt: &created, UpdatedAt: &updated}); !got.Equal(updated) {
+		t.Errorf("SavedAt() = %v, want the update time %v", got, updated)
+	}
+	if got := SavedAt(&SavedToolDefinition{Name: "greet", CreatedAt: &created}); !got.Equal(created) {
+		t.Errorf("SavedAt() = %v, want the creation time %v", got, created)
+	}
+
+	// Tools saved before timestamps were recorded fall back to their file's modification time
+	if got := SavedAt(&SavedToolDefinition{Name: "legacy"}); !got.IsZero() {
+		t.Errorf("SavedAt() of a missing tool = %v, want zero", got)
+	}
+	filename := filepath.Join(dir, "tools", "legacy.json")
+	os.MkdirAll(filepath.Dir(filename), 0755)
+	os.WriteFile(filename, []byte(`{"name": "legacy"}`), 0644)
+	os.Chtimes(filename, created, created)
+	if got := SavedAt(&SavedToolDefinition{Name: "legacy"}); !got.Equal(created) {
+		t.Errorf("SavedAt() = %v, want the file's modification time %v", got, created)
+	}
+}
+
+func TestSortTools(t *testing.T) {
+	at := func(hours int) *time.Time {
+		ts := time.Date(2026, 1, 1, hours, 0, 0, 0, time.UTC)
+		return &ts
+	}
+	tools := []*SavedToolDefinition{
+		{Name: "legacy"},
+		{Name: "beta", CreatedAt: at(1), UpdatedAt: at(5)},
+		{Name: "alpha", CreatedAt: at(2), UpdatedAt: at(3)},
+		{Name: "gamma", CreatedAt: at(2), UpdatedAt: at(2)},
+	}
+
+	tests := []struct {
+		by      string
+		want    string
+		wantErr bool
+	}{
+		{"", "alpha,beta,gamma,legacy", false},
+		{SortByName, "alpha,beta,gamma,legacy", false},
+		{SortByCreated, "alpha,gamma,beta,legacy", false},
+		{SortByUpdated, "beta,alpha,gamma,legacy", false},
+		{"size", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.by, func(t *testing.T) {
+			err := SortTools(tools, tt.by)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("SortTools(%q) error = %v, wantErr %v", tt.by, err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			var names []string
+			for _, tool := range tools {
+				names = append(names, tool.Name)
+			}
+			if got := strings.Join(names, ","); got != tt.want {
+				t.Errorf("SortTools(%q) = %s, want %s", tt.by, got, tt.want)
+			}
+		})
+	}
+}
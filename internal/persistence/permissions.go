@@ -0,0 +1,39 @@
+package persistence
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// permissionPattern matches a module name, optionally followed by a scope, such as "notify" or "fs:read"
+var permissionPattern = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(:[A-Za-z0-9_]+)?$`)
+
+// GrantApplies reports whether permissions granted to a tool still apply once its definition is
+// replaced by after. The operator granted them for the code they reviewed, calling the servers it
+// was allowed to, so changing either voids the grant.
+func GrantApplies(before, after *SavedToolDefinition) bool {
+	return before.Code == after.Code &&
+		slices.Equal(slices.Sorted(slices.Values(before.AllowedServers)), slices.Sorted(slices.Values(after.AllowedServers)))
+}
+
+// validatePermissions checks that each permission names a module and at most one scope
+func validatePermissions(permissions []string) error {
+	for _, permission := range permissions {
+		if !permissionPattern.MatchString(permission) {
+			return fmt.Errorf("invalid permission '%s': expected a module name, optionally with a scope such as fs:read", permission)
+		}
+	}
+	return nil
+}
+
+// validateAllowedServers checks that allowed servers are named
+func validateAllowedServers(servers []string) error {
+	for _, server := range servers {
+		if strings.TrimSpace(server) == "" {
+			return fmt.Errorf("invalid allowed server: names must not be empty")
+		}
+	}
+	return nil
+}
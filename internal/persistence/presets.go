@@ -0,0 +1,76 @@
+package persistence
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PresetParam is the parameter that selects one of a saved tool's presets when it is called
+const PresetParam = "preset"
+
+// PresetNames returns the tool's preset names in sorted order
+func (t *SavedToolDefinition) PresetNames() []string {
+	names := make([]string, 0, len(t.Presets))
+	for name := range t.Presets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ApplyPreset fills in the values of the preset selected by the "preset" parameter.
+// Explicitly passed parameters take precedence over the preset's values. Params are returned
+// unchanged when no preset is selected or the tool declares its own "preset" parameter.
+func (t *SavedToolDefinition) ApplyPreset(params map[string]interface{}) (map[string]interface{}, error) {
+	selected, ok := params[PresetParam]
+	if !ok || t.declaresParam(PresetParam) {
+		return params, nil
+	}
+
+	name, ok := selected.(string)
+	if !ok {
+		return nil, fmt.Errorf("%s must be a string", PresetParam)
+	}
+	preset, exists := t.Presets[name]
+	if !exists {
+		if len(t.Presets) == 0 {
+			return nil, fmt.Errorf("tool '%s' has no presets", t.Name)
+		}
+		return nil, fmt.Errorf("unknown preset '%s' (available: %s)", name, strings.Join(t.PresetNames(), ", "))
+	}
+
+	merged := make(map[string]interface{}, len(preset)+len(params))
+	for key, value := range preset {
+		merged[key] = value
+	}
+	for key, value := range params {
+		if key != PresetParam {
+			merged[key] = value
+		}
+	}
+	return merged, nil
+}
+
+// declaresParam reports whether the tool's input schema defines the named property
+func (t *SavedToolDefinition) declaresParam(name string) bool {
+	properties, ok := t.InputSchema["properties"].(map[string]interface{})
+	if !ok {
+		return false
+	}
+	_, declared := properties[name]
+	return declared
+}
+
+// validatePresets checks that preset names are usable and presets don't nest further presets
+func validatePresets(presets map[string]map[string]interface{}) error {
+	for name, values := range presets {
+		if strings.TrimSpace(name) == "" {
+			return fmt.Errorf("preset name cannot be empty")
+		}
+		if _, nested := values[PresetParam]; nested {
+			return fmt.Errorf("preset '%s' cannot select another preset", name)
+		}
+	}
+	return nil
+}
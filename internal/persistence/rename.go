@@ -0,0 +1,66 @@
+package persistence
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+)
+
+// RenameTool saves a tool under a new name and deletes it under the old one, keeping its creation
+// time and author. Its history stays with the old name, so restore_saved_tool can undo the rename.
+func RenameTool(name, newName string) (*SavedToolDefinition, error) {
+	tool, err := copyTool(name, newName)
+	if err != nil {
+		return nil, err
+	}
+	if err := SaveToolIfUnchanged(tool, 0); err != nil {
+		return nil, err
+	}
+	if err := DeleteTool(name); err != nil {
+		return nil, fmt.Errorf("saved '%s' but failed to delete '%s': %w", newName, name, err)
+	}
+	return tool, nil
+}
+
+// DuplicateTool saves a copy of a tool under a new name, as a new tool with its own history.
+// Permissions aren't copied: they were granted to the original, and the copy's code can be changed
+// without touching it.
+func DuplicateTool(name, newName string) (*SavedToolDefinition, error) {
+	tool, err := copyTool(name, newName)
+	if err != nil {
+		return nil, err
+	}
+	tool.CreatedAt = nil
+	tool.Permissions = nil
+	if err := SaveToolIfUnchanged(tool, 0); err != nil {
+		return nil, err
+	}
+	return tool, nil
+}
+
+// copyTool loads a tool's definition renamed to newName, checking that newName isn't taken
+func copyTool(name, newName string) (*SavedToolDefinition, error) {
+	if err := validateToolName(name); err != nil {
+		return nil, err
+	}
+	if err := validateToolName(newName); err != nil {
+		return nil, err
+	}
+	if name == newName {
+		return nil, fmt.Errorf("the new name is the same as the old one")
+	}
+
+	tool, err := LoadTool(name)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := LoadTool(newName); err == nil {
+		return nil, fmt.Errorf("tool '%s' already exists", newName)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	tool.Name = newName
+	tool.Version = 0
+	return tool, nil
+}
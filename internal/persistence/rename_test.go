@@ -0,0 +1,80 @@
+package persistence
+
+import (
+	"errors"
+	"io/fs"
+	"testing"
+	"time"
+)
+
+func TestRenameAndDuplicateTool(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	save := func(tool *SavedToolDefinition) {
+		t.Helper()
+		if err := SaveTool(tool); err != nil {
+			t.Fatal(err)
+		}
+	}
+	save(&SavedToolDefinition{Name: "greet", Description: "Greet someone", Code: `"hi"`, Author: "ada"})
+	save(&SavedToolDefinition{Name: "greet", Description: "Greet someone", Code: `"hello"`, Permissions: []string{"notify"}})
+	save(&SavedToolDefinition{Name: "taken", Code: "1"})
+	original, _ := LoadTool("greet")
+	created := original.CreatedAt.Add(-time.Hour)
+	original.CreatedAt = &created
+	if err := current.Save(original); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		op      func(string, string) (*SavedToolDefinition, error)
+		from    string
+		to      string
+		wantErr bool
+	}{
+		{"rename to taken name", RenameTool, "greet", "taken", true},
+		{"rename missing tool", RenameTool, "missing", "other", true},
+		{"rename to same name", RenameTool, "greet", "greet", true},
+		{"rename to invalid name", RenameTool, "greet", "bad name", true},
+		{"duplicate to taken name", DuplicateTool, "greet", "taken", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.op(tt.from, tt.to); (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+
+	renamed, err := RenameTool("greet", "welcome")
+	if err != nil {
+		t.Fatalf("RenameTool() error = %v", err)
+	}
+	if _, err := LoadTool("greet"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Old name still loads: %v", err)
+	}
+	if renamed.Code != `"hello"` || renamed.Author != "ada" || !renamed.CreatedAt.Equal(created) || renamed.Version != 1 {
+		t.Errorf("Renamed tool = %+v, want the current code, author and creation time as version 1", renamed)
+	}
+
+	// The rename can be undone from the old name's backups
+	if _, err := RestoreTool("greet"); err != nil {
+		t.Errorf("RestoreTool() after rename error = %v", err)
+	}
+
+	copied, err := DuplicateTool("welcome", "welcome_copy")
+	if err != nil {
+		t.Fatalf("DuplicateTool() error = %v", err)
+	}
+	if copied.Code != `"hello"` || copied.CreatedAt.Equal(created) || copied.Version != 1 {
+		t.Errorf("Copy = %+v, want the same code as a new tool", copied)
+	}
+	if stored, _ := LoadTool("welcome_copy"); stored == nil || len(stored.Permissions) != 0 {
+		t.Errorf("Copy was saved with permissions: %+v", stored)
+	}
+	if original, err := LoadTool("welcome"); err != nil {
+		t.Errorf("Duplicating removed the original: %v", err)
+	} else if len(original.Permissions) != 1 {
+		t.Errorf("Duplicating changed the original's permissions: %v", original.Permissions)
+	}
+}
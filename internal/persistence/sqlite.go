@@ -0,0 +1,376 @@
+package persistence
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sync"
+	"time"
+
+	_ "modernc.org/sqlite"
+
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// sqliteSchema creates the tables of the tool database. Definitions are stored as complete JSON,
+// so they can be queried with SQLite's JSON functions.
+const sqliteSchema = `
+CREATE TABLE IF NOT EXISTS tools (
+	name TEXT PRIMARY KEY,
+	version INTEGER NOT NULL,
+	definition TEXT NOT NULL,
+	updated_at TEXT NOT NULL
+);
+CREATE TABLE IF NOT EXISTS tool_versions (
+	name TEXT NOT NULL,
+	version INTEGER NOT NULL,
+	definition TEXT NOT NULL,
+	archived_at TEXT NOT NULL,
+	PRIMARY KEY (name, version)
+);`
+
+// sqliteSchemaVersion is recorded in the database's user_version once it has been set up
+const sqliteSchemaVersion = 1
+
+// sqliteBackend keeps saved tools and their history in tools.db in the metatool directory, so each
+// save is a single transaction and listing doesn't read a file per tool
+type sqliteBackend struct {
+	mu  sync.Mutex
+	dbs map[string]*sql.DB // by path, as the metatool directory can change
+}
+
+func newSQLiteBackend() *sqliteBackend {
+	return &sqliteBackend{dbs: make(map[string]*sql.DB)}
+}
+
+// db returns the database in the metatool directory, creating it on first use
+func (b *sqliteBackend) db() (*sql.DB, error) {
+	path, err := paths.GetToolsDBPath()
+	if err != nil {
+		return nil, err
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if db := b.dbs[path]; db != nil {
+		return db, nil
+	}
+
+	// Writers take the lock when their transaction begins, and wait for each other rather than failing
+	db, err := sql.Open("sqlite", path+"?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_txlock=immediate")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open tool database: %w", err)
+	}
+	if err := setUpDatabase(db); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to set up tool database: %w", err)
+	}
+	b.dbs[path] = db
+	return db, nil
+}
+
+// setUpDatabase creates the tables of a new database, importing any tools saved as files
+func setUpDatabase(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var version int
+	if err := tx.QueryRow(`PRAGMA user_version`).Scan(&version); err != nil {
+		return err
+	}
+	if version >= sqliteSchemaVersion {
+		return nil
+	}
+	if _, err := tx.Exec(sqliteSchema); err != nil {
+		return err
+	}
+	if err := importFileTools(tx); err != nil {
+		return fmt.Errorf("failed to import saved tools: %w", err)
+	}
+	if _, err := tx.Exec(fmt.Sprintf(`PRAGMA user_version = %d`, sqliteSchemaVersion)); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// importFileTools copies the tools in the tools directory, and the history of each, into a new database
+func importFileTools(tx *sql.Tx) error {
+	toolsDir, err := GetToolsDirectory()
+	if err != nil {
+		return err
+	}
+	entries, err := storage.ReadDir(toolsDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+
+	// Deleted tools survive only as a history directory
+	files := fileBackend{}
+	seen := make(map[string]bool)
+	for _, entry := range entries {
+		name, ok := entry.Name(), entry.IsDir()
+		if !ok {
+			name, ok = toolNameFromFile(entry.Name())
+		}
+		if !ok || seen[name] {
+			continue
+		}
+		seen[name] = true
+
+		versions, err := files.Versions(name)
+		if err != nil {
+			return err
+		}
+		for _, version := range versions {
+			tool, err := files.LoadVersion(name, version)
+			if err != nil {
+				continue
+			}
+			if err := insertTool(tx, "tool_versions", tool); err != nil {
+				return err
+			}
+		}
+		if tool, err := files.Load(name); err == nil {
+			if err := insertTool(tx, "tools", tool); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// insertTool writes a definition to the current tools or their archived versions, replacing any
+// definition of the same version
+func insertTool(tx *sql.Tx, table string, tool *SavedToolDefinition) error {
+	data, err := json.Marshal(tool)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tool: %w", err)
+	}
+	_, err = tx.Exec(`INSERT OR REPLACE INTO `+table+` VALUES (?, ?, ?, ?)`,
+		tool.Name, tool.Version, string(data), time.Now().UTC().Format(time.RFC3339))
+	return err
+}
+
+// archiveCurrentRow copies the current definition of a tool into its history, returning the version
+// number the next saved definition should use and whether there was a current definition
+func archiveCurrentRow(tx *sql.Tx, name string) (int, bool, error) {
+	var version int
+	err := tx.QueryRow(`SELECT version FROM tools WHERE name = ?`, name).Scan(&version)
+	if errors.Is(err, sql.ErrNoRows) {
+		// No current definition; continue numbering after any surviving history
+		err = tx.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM tool_versions WHERE name = ?`, name).Scan(&version)
+		return version + 1, false, err
+	}
+	if err != nil {
+		return 0, false, err
+	}
+
+	// Definitions saved before versioning count as the first version
+	version = max(version, 1)
+	_, err = tx.Exec(`INSERT OR REPLACE INTO tool_versions
+		SELECT name, ?, definition, ? FROM tools WHERE name = ?`, version, time.Now().UTC().Format(time.RFC3339), name)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to archive tool version: %w", err)
+	}
+	return version + 1, true, nil
+}
+
+// Save archives the current definition and stores the new one in a single transaction
+func (b *sqliteBackend) Save(tool *SavedToolDefinition) error {
+	db, err := b.db()
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	version, _, err := archiveCurrentRow(tx, tool.Name)
+	if err != nil {
+		return err
+	}
+	tool.Version = version
+	if err := insertTool(tx, "tools", tool); err != nil {
+		return fmt.Errorf("failed to save tool: %w", err)
+	}
+	return tx.Commit()
+}
+
+// SaveIfVersion archives the current definition and replaces it with a conditional update, so the
+// save fails if another process changed the tool since expectedVersion was read
+func (b *sqliteBackend) SaveIfVersion(tool *SavedToolDefinition, expectedVersion int) error {
+	db, err := b.db()
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	version, exists, err := archiveCurrentRow(tx, tool.Name)
+	if err != nil {
+		return err
+	}
+	tool.Version = version
+	data, err := json.Marshal(tool)
+	if err != nil {
+		return fmt.Errorf("failed to marshal tool: %w", err)
+	}
+	updatedAt := time.Now().UTC().Format(time.RFC3339)
+
+	var result sql.Result
+	if expectedVersion == 0 {
+		result, err = tx.Exec(`INSERT INTO tools SELECT ?, ?, ?, ? WHERE NOT EXISTS (SELECT 1 FROM tools WHERE name = ?)`,
+			tool.Name, tool.Version, string(data), updatedAt, tool.Name)
+	} else {
+		// Definitions saved before versioning count as the first version
+		result, err = tx.Exec(`UPDATE tools SET version = ?, definition = ?, updated_at = ? WHERE name = ? AND MAX(version, 1) = ?`,
+			tool.Version, string(data), updatedAt, tool.Name, expectedVersion)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to save tool: %w", err)
+	}
+	if saved, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to save tool: %w", err)
+	} else if saved == 0 {
+		currentVersion := 0
+		if exists {
+			currentVersion = version - 1
+		}
+		return &VersionConflictError{Name: tool.Name, Expected: expectedVersion, Current: currentVersion}
+	}
+	return tx.Commit()
+}
+
+// Load reads a tool's current definition
+func (b *sqliteBackend) Load(name string) (*SavedToolDefinition, error) {
+	db, err := b.db()
+	if err != nil {
+		return nil, err
+	}
+	var data string
+	err = db.QueryRow(`SELECT definition FROM tools WHERE name = ?`, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("tool '%s' does not exist: %w", name, fs.ErrNotExist)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read tool: %w", err)
+	}
+
+	var tool SavedToolDefinition
+	if err := json.Unmarshal([]byte(data), &tool); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tool: %w", err)
+	}
+	return &tool, nil
+}
+
+// List reads the current definition of every tool in one query, ordered by name
+func (b *sqliteBackend) List() ([]*SavedToolDefinition, error) {
+	db, err := b.db()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT definition FROM tools ORDER BY name`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list tools: %w", err)
+	}
+	defer rows.Close()
+
+	tools := []*SavedToolDefinition{}
+	for rows.Next() {
+		var data string
+		if err := rows.Scan(&data); err != nil {
+			return nil, fmt.Errorf("failed to list tools: %w", err)
+		}
+		var tool SavedToolDefinition
+		if err := json.Unmarshal([]byte(data), &tool); err != nil {
+			// Skip malformed tools but continue with others
+			continue
+		}
+		tools = append(tools, &tool)
+	}
+	return tools, rows.Err()
+}
+
+// Delete archives a tool's current definition and removes it in a single transaction
+func (b *sqliteBackend) Delete(name string) error {
+	db, err := b.db()
+	if err != nil {
+		return err
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, exists, err := archiveCurrentRow(tx, name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return fmt.Errorf("tool '%s' does not exist", name)
+	}
+	if _, err := tx.Exec(`DELETE FROM tools WHERE name = ?`, name); err != nil {
+		return fmt.Errorf("failed to delete tool: %w", err)
+	}
+	return tx.Commit()
+}
+
+// Versions returns the archived version numbers of a tool in ascending order
+func (b *sqliteBackend) Versions(name string) ([]int, error) {
+	db, err := b.db()
+	if err != nil {
+		return nil, err
+	}
+	rows, err := db.Query(`SELECT version FROM tool_versions WHERE name = ? ORDER BY version`, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read tool history: %w", err)
+	}
+	defer rows.Close()
+
+	var versions []int
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			return nil, fmt.Errorf("failed to read tool history: %w", err)
+		}
+		versions = append(versions, version)
+	}
+	return versions, rows.Err()
+}
+
+// LoadVersion reads an archived version of a tool
+func (b *sqliteBackend) LoadVersion(name string, version int) (*SavedToolDefinition, error) {
+	db, err := b.db()
+	if err != nil {
+		return nil, err
+	}
+	var data string
+	err = db.QueryRow(`SELECT definition FROM tool_versions WHERE name = ? AND version = ?`, name, version).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("tool '%s' has no version %d", name, version)
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read tool version: %w", err)
+	}
+
+	var tool SavedToolDefinition
+	if err := json.Unmarshal([]byte(data), &tool); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tool version: %w", err)
+	}
+	tool.Version = version
+	return &tool, nil
+}
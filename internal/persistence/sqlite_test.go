@@ -0,0 +1,125 @@
+package persistence
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// useSQLite switches the package to the SQLite backend for the rest of the test
+func useSQLite(t *testing.T) {
+	t.Helper()
+	if err := UseBackend(SQLiteBackend); err != nil {
+		t.Fatalf("UseBackend() error = %v", err)
+	}
+	t.Cleanup(func() { UseBackend(FileBackend) })
+}
+
+func TestSQLiteBackend(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+	useSQLite(t)
+
+	for _, code := range []string{"1", "2"} {
+		tool := &SavedToolDefinition{Name: "counter", Description: "v" + code, Code: code, Tests: []ToolTest{{Name: "t", Expected: code}}}
+		if err := SaveTool(tool); err != nil {
+			t.Fatalf("SaveTool() error = %v", err)
+		}
+	}
+	SaveTool(&SavedToolDefinition{Name: "another", Code: "0"})
+
+	current, err := LoadTool("counter")
+	if err != nil {
+		t.Fatalf("LoadTool() error = %v", err)
+	}
+	if current.Version != 2 || current.Code != "2" || len(current.Tests) != 1 {
+		t.Errorf("LoadTool() = %+v, want version 2 with its code and tests", current)
+	}
+	tools, err := ListTools()
+	if err != nil || len(tools) != 2 || tools[0].Name != "another" || tools[1].Name != "counter" {
+		t.Errorf("ListTools() = %v, %v, want another and counter", tools, err)
+	}
+	versions, err := ListVersions("counter")
+	if err != nil || len(versions) != 1 || versions[0].Version != 1 || versions[0].Code != "1" {
+		t.Errorf("ListVersions() = %v, %v, want version 1", versions, err)
+	}
+
+	// Deleting keeps a backup that can be restored
+	if err := DeleteTool("counter"); err != nil {
+		t.Fatalf("DeleteTool() error = %v", err)
+	}
+	if _, err := LoadTool("counter"); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("LoadTool() after delete error = %v, want fs.ErrNotExist", err)
+	}
+	if err := DeleteTool("counter"); err == nil {
+		t.Error("DeleteTool() of a deleted tool succeeded")
+	}
+	restored, err := RestoreTool("counter")
+	if err != nil || restored.Code != "2" || restored.Version != 3 {
+		t.Errorf("RestoreTool() = %+v, %v, want the code of version 2 as version 3", restored, err)
+	}
+
+	// Nothing is written to the tools directory
+	entries, _ := os.ReadDir(filepath.Join(dir, "tools"))
+	if len(entries) != 0 {
+		t.Errorf("Tools directory has %d entries, want none", len(entries))
+	}
+}
+
+func TestSQLiteBackend_ConcurrentSaves(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	useSQLite(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := SaveTool(&SavedToolDefinition{Name: "busy", Code: "1"}); err != nil {
+				t.Errorf("SaveTool() error = %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Every save archived the one before it under its own version
+	tool, err := LoadTool("busy")
+	if err != nil || tool.Version != 10 {
+		t.Errorf("LoadTool() = %+v, %v, want version 10", tool, err)
+	}
+	versions, _ := ListVersions("busy")
+	if len(versions) != 9 {
+		t.Errorf("ListVersions() returned %d versions, want 9", len(versions))
+	}
+}
+
+func TestSQLiteBackend_ImportsFileTools(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	SaveTool(&SavedToolDefinition{Name: "kept", Code: "1"})
+	SaveTool(&SavedToolDefinition{Name: "kept", Code: "2"})
+	SaveTool(&SavedToolDefinition{Name: "deleted", Code: "3"})
+	DeleteTool("deleted")
+
+	useSQLite(t)
+	tool, err := LoadTool("kept")
+	if err != nil || tool.Version != 2 || tool.Code != "2" {
+		t.Errorf("LoadTool() = %+v, %v, want version 2 imported from files", tool, err)
+	}
+	if versions, _ := ListVersions("kept"); len(versions) != 1 || versions[0].Code != "1" {
+		t.Errorf("ListVersions() = %v, want the imported history", versions)
+	}
+	restored, err := RestoreTool("deleted")
+	if err != nil || restored.Code != "3" {
+		t.Errorf("RestoreTool() = %+v, %v, want the imported backup", restored, err)
+	}
+}
+
+func TestUseBackend_Unknown(t *testing.T) {
+	if err := UseBackend("postgres"); err == nil {
+		t.Error("UseBackend() of an unknown backend succeeded")
+	}
+}
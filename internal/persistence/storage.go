@@ -1,21 +1,57 @@
 package persistence
 
 import (
-	"encoding/json"
 	"fmt"
 	"os"
-	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/storage"
 )
 
 // SavedToolDefinition represents a saved tool
 type SavedToolDefinition struct {
-	Name        string                 `json:"name"`
-	Description string                 `json:"description"`
-	InputSchema map[string]interface{} `json:"inputSchema"`
-	Code        string                 `json:"code"`
+	Name        string                 `json:"name" yaml:"name"`
+	Description string                 `json:"description" yaml:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema" yaml:"inputSchema"`
+	Code        string                 `json:"code,omitempty" yaml:"code,omitempty"` // kept in <name>.star beside JSON definitions
+	Tests       []ToolTest             `json:"tests,omitempty" yaml:"tests,omitempty"`
+	// Presets maps a preset name to parameter values applied when called with {"preset": name}
+	Presets map[string]map[string]interface{} `json:"presets,omitempty" yaml:"presets,omitempty"`
+	// SessionAffinity fails a run rather than letting its calls reach a reconnected server instance
+	SessionAffinity bool `json:"sessionAffinity,omitempty" yaml:"sessionAffinity,omitempty"`
+	// Permissions grants restricted Starlark modules, such as "notify" or "fs:read", to this tool's code
+	Permissions []string `json:"permissions,omitempty" yaml:"permissions,omitempty"`
+	// AllowedServers limits the upstream servers this tool's code can see and call; empty allows all of them
+	AllowedServers []string `json:"allowedServers,omitempty" yaml:"allowedServers,omitempty"`
+	Version     int                    `json:"version,omitempty" yaml:"version,omitempty"`
+	// CreatedAt and UpdatedAt are set when the tool is saved; tools saved before they were recorded have neither
+	CreatedAt *time.Time `json:"createdAt,omitempty" yaml:"createdAt,omitempty"`
+	UpdatedAt *time.Time `json:"updatedAt,omitempty" yaml:"updatedAt,omitempty"`
+	Author    string     `json:"author,omitempty" yaml:"author,omitempty"`
+	// Annotations are advertised with the tool so clients can decide whether to confirm calls to it
+	Annotations *ToolAnnotations `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+// ToolAnnotations are the MCP tool annotations a saved tool declares
+type ToolAnnotations struct {
+	Title           string `json:"title,omitempty" yaml:"title,omitempty" jsonschema:"Human-readable title"`
+	ReadOnlyHint    bool   `json:"readOnlyHint,omitempty" yaml:"readOnlyHint,omitempty" jsonschema:"The tool doesn't modify its environment"`
+	DestructiveHint *bool  `json:"destructiveHint,omitempty" yaml:"destructiveHint,omitempty" jsonschema:"The tool may make destructive updates, rather than only additive ones; assumed true if not read-only"`
+	IdempotentHint  bool   `json:"idempotentHint,omitempty" yaml:"idempotentHint,omitempty" jsonschema:"Repeating a call with the same arguments has no further effect"`
+	OpenWorldHint   *bool  `json:"openWorldHint,omitempty" yaml:"openWorldHint,omitempty" jsonschema:"The tool interacts with external entities; assumed true"`
+}
+
+// ToolTest is a test case embedded in a saved tool definition
+type ToolTest struct {
+	Name       string                 `json:"name" yaml:"name"`
+	Params     map[string]interface{} `json:"params,omitempty" yaml:"params,omitempty"`
+	Expected   interface{}            `json:"expected,omitempty" yaml:"expected,omitempty"`
+	Assertions []string               `json:"assertions,omitempty" yaml:"assertions,omitempty"`
+	// Mocks maps "server.tool" to the structured response the tool should return;
+	// when empty the test runs against the real proxied servers
+	Mocks map[string]interface{} `json:"mocks,omitempty" yaml:"mocks,omitempty"`
 }
 
 // GetToolsDirectory returns the directory where tools are stored
@@ -24,61 +60,104 @@ func GetToolsDirectory() (string, error) {
 	return paths.GetToolsDir()
 }
 
-// SaveTool saves a tool definition to disk
+// SaveTool validates and saves a tool definition, archiving its previous one
 func SaveTool(tool *SavedToolDefinition) error {
-	toolsDir, err := GetToolsDirectory()
-	if err != nil {
+	saveMu.Lock()
+	defer saveMu.Unlock()
+	return saveTool(tool)
+}
+
+// saveTool implements SaveTool; the caller must hold saveMu
+func saveTool(tool *SavedToolDefinition) error {
+	if err := ValidateTool(tool); err != nil {
 		return err
 	}
-	
+	stampTool(tool)
+	return current.Save(tool)
+}
+
+// ValidateTool checks that a tool definition can be saved
+func ValidateTool(tool *SavedToolDefinition) error {
 	// Validate tool name
 	if err := validateToolName(tool.Name); err != nil {
 		return err
 	}
-	
-	// Write to file
-	filename := filepath.Join(toolsDir, tool.Name+".json")
-	data, err := json.MarshalIndent(tool, "", "  ")
-	if err != nil {
-		return fmt.Errorf("failed to marshal tool: %w", err)
+
+	if err := validatePresets(tool.Presets); err != nil {
+		return err
 	}
-	
-	if err := os.WriteFile(filename, data, 0644); err != nil {
-		return fmt.Errorf("failed to write tool file: %w", err)
+
+	if err := validatePermissions(tool.Permissions); err != nil {
+		return err
 	}
-	
-	return nil
+
+	return validateAllowedServers(tool.AllowedServers)
 }
 
-// LoadTool loads a tool definition from disk
+// LoadTool loads a tool definition
 func LoadTool(name string) (*SavedToolDefinition, error) {
+	return current.Load(name)
+}
+
+// ListTools returns all saved tool definitions
+func ListTools() ([]*SavedToolDefinition, error) {
+	return current.List()
+}
+
+// DeleteTool removes a tool definition, keeping a backup so it can be restored
+func DeleteTool(name string) error {
+	if err := validateToolName(name); err != nil {
+		return err
+	}
+	return current.Delete(name)
+}
+
+// Save writes a tool's definition and code to the tools directory
+func (b fileBackend) Save(tool *SavedToolDefinition) error {
 	toolsDir, err := GetToolsDirectory()
 	if err != nil {
-		return nil, err
+		return err
 	}
-	
-	filename := filepath.Join(toolsDir, name+".json")
-	data, err := os.ReadFile(filename)
+	unlock, err := lockToolsDir(toolsDir)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	return b.save(toolsDir, tool)
+}
+
+// save implements Save; the caller must hold the tools directory lock
+func (fileBackend) save(toolsDir string, tool *SavedToolDefinition) error {
+	// Keep the previous definition in the tool's history
+	version, err := archiveCurrent(toolsDir, tool.Name)
 	if err != nil {
-		return nil, fmt.Errorf("failed to read tool file: %w", err)
+		return err
 	}
+	tool.Version = version
 	
-	var tool SavedToolDefinition
-	if err := json.Unmarshal(data, &tool); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal tool: %w", err)
+	// Write the definition and its code
+	return writeTool(toolsDir, tool)
+}
+
+// Load reads a tool definition from disk
+func (fileBackend) Load(name string) (*SavedToolDefinition, error) {
+	toolsDir, err := GetToolsDirectory()
+	if err != nil {
+		return nil, err
 	}
 	
-	return &tool, nil
+	tool, _, err := readTool(toolsDir, name)
+	return tool, err
 }
 
-// ListTools returns all saved tool definitions
-func ListTools() ([]*SavedToolDefinition, error) {
+// List reads every tool definition in the tools directory
+func (b fileBackend) List() ([]*SavedToolDefinition, error) {
 	toolsDir, err := GetToolsDirectory()
 	if err != nil {
 		return nil, err
 	}
 	
-	entries, err := os.ReadDir(toolsDir)
+	entries, err := storage.ReadDir(toolsDir)
 	if err != nil {
 		if os.IsNotExist(err) {
 			return []*SavedToolDefinition{}, nil
@@ -87,13 +166,19 @@ func ListTools() ([]*SavedToolDefinition, error) {
 	}
 	
 	var tools []*SavedToolDefinition
+	seen := make(map[string]bool)
 	for _, entry := range entries {
-		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".json") {
+		if entry.IsDir() {
 			continue
 		}
 		
-		toolName := strings.TrimSuffix(entry.Name(), ".json")
-		tool, err := LoadTool(toolName)
+		// A tool with both JSON and YAML files is listed once, as Load reads it
+		toolName, ok := toolNameFromFile(entry.Name())
+		if !ok || seen[toolName] {
+			continue
+		}
+		seen[toolName] = true
+		tool, err := b.Load(toolName)
 		if err != nil {
 			// Skip malformed tools but continue with others
 			continue
@@ -104,24 +189,41 @@ func ListTools() ([]*SavedToolDefinition, error) {
 	return tools, nil
 }
 
-// DeleteTool removes a tool definition from disk
-func DeleteTool(name string) error {
-	if err := validateToolName(name); err != nil {
+// Delete removes a tool's files from disk
+func (fileBackend) Delete(name string) error {
+	toolsDir, err := GetToolsDirectory()
+	if err != nil {
 		return err
 	}
 	
-	toolsDir, err := GetToolsDirectory()
+	unlock, err := lockToolsDir(toolsDir)
 	if err != nil {
 		return err
 	}
+	defer unlock()
+
+	filename, err := toolFilename(toolsDir, name)
+	if err != nil {
+		return fmt.Errorf("failed to find tool file: %w", err)
+	}
+	if _, err := storage.Stat(filename); os.IsNotExist(err) {
+		return fmt.Errorf("tool '%s' does not exist", name)
+	}
 	
-	filename := filepath.Join(toolsDir, name+".json")
-	if err := os.Remove(filename); err != nil {
+	// Back up the definition so it can be restored later
+	if _, err := archiveCurrent(toolsDir, name); err != nil {
+		return err
+	}
+	
+	if err := storage.Remove(filename); err != nil {
 		if os.IsNotExist(err) {
 			return fmt.Errorf("tool '%s' does not exist", name)
 		}
 		return fmt.Errorf("failed to delete tool: %w", err)
 	}
+	if err := storage.Remove(codeFilename(toolsDir, name)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete tool code: %w", err)
+	}
 	
 	return nil
 }
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/storage"
 )
 
 func TestValidateToolName(t *testing.T) {
@@ -201,8 +203,13 @@ result = f"Hello, {name}!"`,
 				if savedTool.Description != tt.tool.Description {
 					t.Errorf("SaveTool() saved description = %q, want %q", savedTool.Description, tt.tool.Description)
 				}
-				if savedTool.Code != tt.tool.Code {
-					t.Errorf("SaveTool() saved code = %q, want %q", savedTool.Code, tt.tool.Code)
+				// The code is kept in a .star file beside the definition
+				if savedTool.Code != "" {
+					t.Errorf("SaveTool() saved code in the definition: %q", savedTool.Code)
+				}
+				code, err := os.ReadFile(filepath.Join(toolsDir, tt.tool.Name+".star"))
+				if err != nil || string(code) != tt.tool.Code {
+					t.Errorf("SaveTool() saved code = %q, %v, want %q", code, err, tt.tool.Code)
 				}
 			}
 		})
@@ -510,4 +517,86 @@ result = [message] * count`,
 			t.Errorf("Tool still appears in listing after deletion")
 		}
 	}
-}
\ No newline at end of file
+}
+func TestEphemeralStorage(t *testing.T) {
+	tempDir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", tempDir)
+	storage.UseMemory()
+	defer storage.UseDisk()
+
+	tool := &SavedToolDefinition{Name: "ephemeral", Description: "v1", Code: "1"}
+	if err := SaveTool(tool); err != nil {
+		t.Fatalf("SaveTool() error = %v", err)
+	}
+	tool.Description = "v2"
+	if err := SaveTool(tool); err != nil {
+		t.Fatalf("SaveTool() error = %v", err)
+	}
+
+	loaded, err := LoadTool("ephemeral")
+	if err != nil || loaded.Description != "v2" {
+		t.Fatalf("LoadTool() = %+v, %v", loaded, err)
+	}
+
+	versions, err := ListVersions("ephemeral")
+	if err != nil || len(versions) != 1 {
+		t.Errorf("Expected 1 archived version in memory, got %v, %v", versions, err)
+	}
+
+	if err := DeleteTool("ephemeral"); err != nil {
+		t.Errorf("DeleteTool() error = %v", err)
+	}
+
+	entries, _ := os.ReadDir(tempDir)
+	if len(entries) != 0 {
+		t.Errorf("Expected nothing written to disk, found %d entries", len(entries))
+	}
+}
+
+func TestSaveToolValidatesPresets(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	tests := []struct {
+		name    string
+		presets map[string]map[string]interface{}
+		wantErr bool
+	}{
+		{"no presets", nil, false},
+		{"valid preset", map[string]map[string]interface{}{"weekly": {"days": 7}}, false},
+		{"empty name", map[string]map[string]interface{}{" ": {"days": 7}}, true},
+		{"nested preset", map[string]map[string]interface{}{"weekly": {"preset": "daily"}}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SaveTool(&SavedToolDefinition{Name: "preset_tool", Description: "d", Code: "1", Presets: tt.presets})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SaveTool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSaveToolValidatesPermissions(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	tests := []struct {
+		name        string
+		permissions []string
+		wantErr     bool
+	}{
+		{"none", nil, false},
+		{"modules and scopes", []string{"notify", "fs:read", "http"}, false},
+		{"empty", []string{""}, true},
+		{"two scopes", []string{"fs:read:write"}, true},
+		{"spaces", []string{"fs read"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := SaveTool(&SavedToolDefinition{Name: "trusted", Description: "d", Code: "1", Permissions: tt.permissions})
+			if (err != nil) != tt.wantErr {
+				t.Errorf("SaveTool() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
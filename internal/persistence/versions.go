@@ -0,0 +1,197 @@
+package persistence
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// historyDir returns the directory holding prior versions of a tool
+func historyDir(toolsDir, name string) string {
+	return filepath.Join(toolsDir, name)
+}
+
+// versionFilename returns the path of an archived tool version
+func versionFilename(toolsDir, name string, version int) string {
+	return filepath.Join(historyDir(toolsDir, name), fmt.Sprintf("v%d.json", version))
+}
+
+// archiveCurrent copies the current definition of a tool into its history
+// and returns the version number the next saved definition should use
+func archiveCurrent(toolsDir, name string) (int, error) {
+	tool, _, err := readTool(toolsDir, name)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return 0, err
+		}
+		// No current definition; continue numbering after any surviving history
+		versions, err := archivedVersions(toolsDir, name)
+		if err != nil {
+			return 0, err
+		}
+		if len(versions) == 0 {
+			return 1, nil
+		}
+		return versions[len(versions)-1] + 1, nil
+	}
+
+	version := tool.Version
+	if version == 0 {
+		// Definitions saved before versioning count as the first version
+		version = 1
+	}
+
+	if err := storage.MkdirAll(historyDir(toolsDir, name), 0755); err != nil {
+		return 0, fmt.Errorf("failed to create history directory: %w", err)
+	}
+	// History is kept as complete JSON definitions, whatever format the tool is written in
+	current, err := json.MarshalIndent(tool, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("failed to marshal current tool: %w", err)
+	}
+	if err := storage.WriteFile(versionFilename(toolsDir, name, version), current, 0644); err != nil {
+		return 0, fmt.Errorf("failed to archive tool version: %w", err)
+	}
+
+	return version + 1, nil
+}
+
+// Versions returns the archived version numbers of a tool in ascending order
+func (fileBackend) Versions(name string) ([]int, error) {
+	toolsDir, err := GetToolsDirectory()
+	if err != nil {
+		return nil, err
+	}
+	return archivedVersions(toolsDir, name)
+}
+
+// archivedVersions returns the archived version numbers of a tool in ascending order
+func archivedVersions(toolsDir, name string) ([]int, error) {
+	entries, err := storage.ReadDir(historyDir(toolsDir, name))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read history directory: %w", err)
+	}
+
+	var versions []int
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, "v") || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		version, err := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(name, "v"), ".json"))
+		if err != nil {
+			continue
+		}
+		versions = append(versions, version)
+	}
+
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// ListVersions returns the archived prior versions of a tool, oldest first
+func ListVersions(name string) ([]*SavedToolDefinition, error) {
+	if err := validateToolName(name); err != nil {
+		return nil, err
+	}
+
+	versions, err := current.Versions(name)
+	if err != nil {
+		return nil, err
+	}
+
+	tools := make([]*SavedToolDefinition, 0, len(versions))
+	for _, version := range versions {
+		tool, err := current.LoadVersion(name, version)
+		if err != nil {
+			// Skip malformed versions but continue with others
+			continue
+		}
+		tools = append(tools, tool)
+	}
+
+	return tools, nil
+}
+
+// LoadVersion loads an archived version of a tool
+func LoadVersion(name string, version int) (*SavedToolDefinition, error) {
+	if err := validateToolName(name); err != nil {
+		return nil, err
+	}
+	return current.LoadVersion(name, version)
+}
+
+// LoadVersion reads an archived version of a tool from its history directory
+func (fileBackend) LoadVersion(name string, version int) (*SavedToolDefinition, error) {
+	toolsDir, err := GetToolsDirectory()
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := storage.ReadFile(versionFilename(toolsDir, name, version))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("tool '%s' has no version %d", name, version)
+		}
+		return nil, fmt.Errorf("failed to read tool version: %w", err)
+	}
+
+	var tool SavedToolDefinition
+	if err := json.Unmarshal(data, &tool); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal tool version: %w", err)
+	}
+	tool.Version = version
+
+	return &tool, nil
+}
+
+// RollbackTool restores an archived version of a tool as its current definition
+// The current definition is archived first, so a rollback can itself be rolled back.
+// Permissions aren't restored with the rest of the definition, since that would bring back grants
+// the operator has since revoked: the tool keeps its current permissions if its code and allowed
+// servers are unchanged, and otherwise has none.
+func RollbackTool(name string, version int) (*SavedToolDefinition, error) {
+	tool, err := LoadVersion(name, version)
+	if err != nil {
+		return nil, err
+	}
+	tool.Permissions = nil
+	if existing, err := LoadTool(name); err == nil && GrantApplies(existing, tool) {
+		tool.Permissions = existing.Permissions
+	}
+
+	if err := SaveTool(tool); err != nil {
+		return nil, err
+	}
+
+	return tool, nil
+}
+
+// RestoreTool brings back the most recently archived definition of a tool,
+// undoing the last overwrite or delete
+func RestoreTool(name string) (*SavedToolDefinition, error) {
+	if err := validateToolName(name); err != nil {
+		return nil, err
+	}
+
+	versions, err := current.Versions(name)
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("no backup of tool '%s' found", name)
+	}
+
+	return RollbackTool(name, versions[len(versions)-1])
+}
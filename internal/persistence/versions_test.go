@@ -0,0 +1,183 @@
+package persistence
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSaveToolKeepsHistory(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	for i, code := range []string{"1", "2", "3"} {
+		tool := &SavedToolDefinition{Name: "counter", Description: "v" + code, Code: code}
+		if err := SaveTool(tool); err != nil {
+			t.Fatalf("SaveTool() error = %v", err)
+		}
+		if tool.Version != i+1 {
+			t.Errorf("Expected version %d, got %d", i+1, tool.Version)
+		}
+	}
+
+	current, err := LoadTool("counter")
+	if err != nil {
+		t.Fatalf("LoadTool() error = %v", err)
+	}
+	if current.Version != 3 || current.Code != "3" {
+		t.Errorf("Expected current version 3, got %d with code %q", current.Version, current.Code)
+	}
+
+	versions, err := ListVersions("counter")
+	if err != nil {
+		t.Fatalf("ListVersions() error = %v", err)
+	}
+	if len(versions) != 2 {
+		t.Fatalf("Expected 2 archived versions, got %d", len(versions))
+	}
+	if versions[0].Version != 1 || versions[0].Code != "1" || versions[1].Version != 2 || versions[1].Code != "2" {
+		t.Errorf("Unexpected history: %+v, %+v", versions[0], versions[1])
+	}
+
+	// History must not show up as saved tools
+	tools, err := ListTools()
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	if len(tools) != 1 {
+		t.Errorf("Expected 1 saved tool, got %d", len(tools))
+	}
+}
+
+func TestRollbackTool(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	SaveTool(&SavedToolDefinition{Name: "greet", Description: "good", Code: "'hello'"})
+	SaveTool(&SavedToolDefinition{Name: "greet", Description: "bad", Code: "oops"})
+
+	restored, err := RollbackTool("greet", 1)
+	if err != nil {
+		t.Fatalf("RollbackTool() error = %v", err)
+	}
+	if restored.Version != 3 {
+		t.Errorf("Expected rollback to create version 3, got %d", restored.Version)
+	}
+
+	current, _ := LoadTool("greet")
+	if current.Code != "'hello'" || current.Description != "good" {
+		t.Errorf("Expected version 1 content after rollback, got %+v", current)
+	}
+
+	// The bad version is still recoverable
+	bad, err := LoadVersion("greet", 2)
+	if err != nil || bad.Code != "oops" {
+		t.Errorf("Expected version 2 to remain in history, got %+v, %v", bad, err)
+	}
+
+	if _, err := RollbackTool("greet", 42); err == nil {
+		t.Error("Expected error rolling back to a missing version")
+	}
+}
+
+func TestSaveToolLegacyDefinitionWithoutVersion(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	toolsDir, _ := GetToolsDirectory()
+	legacy := `{"name": "legacy", "description": "old", "code": "1"}`
+	os.WriteFile(filepath.Join(toolsDir, "legacy.json"), []byte(legacy), 0644)
+
+	tool := &SavedToolDefinition{Name: "legacy", Description: "new", Code: "2"}
+	if err := SaveTool(tool); err != nil {
+		t.Fatalf("SaveTool() error = %v", err)
+	}
+	if tool.Version != 2 {
+		t.Errorf("Expected legacy definition to count as version 1, got new version %d", tool.Version)
+	}
+
+	archived, err := LoadVersion("legacy", 1)
+	if err != nil || archived.Description != "old" {
+		t.Errorf("Expected legacy definition archived as v1, got %+v, %v", archived, err)
+	}
+}
+
+func TestVersionNumberingContinuesAfterDelete(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	SaveTool(&SavedToolDefinition{Name: "temp", Code: "1"})
+	SaveTool(&SavedToolDefinition{Name: "temp", Code: "2"})
+	DeleteTool("temp")
+
+	tool := &SavedToolDefinition{Name: "temp", Code: "3"}
+	if err := SaveTool(tool); err != nil {
+		t.Fatalf("SaveTool() error = %v", err)
+	}
+	// The deleted definition was archived as v2, so numbering continues at v3
+	if tool.Version != 3 {
+		t.Errorf("Expected numbering to continue after history, got version %d", tool.Version)
+	}
+
+	if _, err := ListVersions("bad/name"); err == nil {
+		t.Error("Expected error for invalid tool name")
+	}
+}
+
+func TestDeleteToolKeepsBackup(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	SaveTool(&SavedToolDefinition{Name: "precious", Description: "keep me", Code: "1"})
+	if err := DeleteTool("precious"); err != nil {
+		t.Fatalf("DeleteTool() error = %v", err)
+	}
+
+	if _, err := LoadTool("precious"); err == nil {
+		t.Fatal("Expected tool to be deleted")
+	}
+
+	restored, err := RestoreTool("precious")
+	if err != nil {
+		t.Fatalf("RestoreTool() error = %v", err)
+	}
+	if restored.Description != "keep me" {
+		t.Errorf("Expected restored definition, got %+v", restored)
+	}
+
+	current, err := LoadTool("precious")
+	if err != nil || current.Code != "1" {
+		t.Errorf("Expected tool to be back on disk, got %+v, %v", current, err)
+	}
+}
+
+func TestRestoreToolUndoesOverwrite(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	SaveTool(&SavedToolDefinition{Name: "report", Description: "original", Code: "1"})
+	SaveTool(&SavedToolDefinition{Name: "report", Description: "clobbered", Code: "2"})
+
+	if _, err := RestoreTool("report"); err != nil {
+		t.Fatalf("RestoreTool() error = %v", err)
+	}
+
+	current, _ := LoadTool("report")
+	if current.Description != "original" {
+		t.Errorf("Expected original definition after restore, got %q", current.Description)
+	}
+
+	if _, err := RestoreTool("never_saved"); err == nil {
+		t.Error("Expected error restoring a tool without backups")
+	}
+}
+
+func TestRollbackToolPermissions(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	SaveTool(&SavedToolDefinition{Name: "alert", Description: "old", Code: "1", Permissions: []string{"notify"}})
+	SaveTool(&SavedToolDefinition{Name: "alert", Description: "new", Code: "2"})
+	if restored, err := RollbackTool("alert", 1); err != nil || len(restored.Permissions) != 0 {
+		t.Errorf("RollbackTool() = %+v, %v, want no permissions for code that changed", restored, err)
+	}
+
+	SaveTool(&SavedToolDefinition{Name: "alert", Description: "granted", Code: "1", Permissions: []string{"secrets"}})
+	restored, err := RollbackTool("alert", 1)
+	if err != nil || len(restored.Permissions) != 1 || restored.Permissions[0] != "secrets" {
+		t.Errorf("RollbackTool() = %+v, %v, want the current permissions for unchanged code", restored, err)
+	}
+}
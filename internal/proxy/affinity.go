@@ -0,0 +1,111 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// InstanceTracker is implemented by proxy managers that can identify the upstream instance serving a server
+type InstanceTracker interface {
+	// InstanceID identifies the server's current session, changing whenever the server is
+	// relaunched or reconnected. It reports false if the server isn't connected.
+	InstanceID(serverName string) (uint64, bool)
+}
+
+// InstanceID identifies the instance currently serving a server, if the proxy manager tracks instances
+func InstanceID(pm ProxyManager, serverName string) (uint64, bool) {
+	if tracker, ok := pm.(InstanceTracker); ok {
+		return tracker.InstanceID(serverName)
+	}
+	return 0, false
+}
+
+// InstanceID identifies the server's current session
+func (m *Manager) InstanceID(serverName string) (uint64, bool) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if _, connected := m.sessions[serverName]; !connected {
+		return 0, false
+	}
+	return m.instances[serverName], true
+}
+
+// affinityProxy pins each server to the instance that served its first call
+type affinityProxy struct {
+	ProxyManager
+	mu     sync.Mutex
+	pinned map[string]uint64 // server name -> instance ID
+}
+
+// WithAffinity wraps a proxy manager for a single run, so every call to a server goes to the
+// instance that served the run's first call to it. If that instance is replaced (by a restart,
+// reload, idle shutdown or dropped keepalive), later calls fail instead of reaching a new
+// instance that lacks the state built up earlier in the run. Proxy managers that don't track
+// instances are returned unchanged.
+func WithAffinity(pm ProxyManager) ProxyManager {
+	if _, ok := pm.(InstanceTracker); !ok {
+		return pm
+	}
+	return &affinityProxy{ProxyManager: pm, pinned: make(map[string]uint64)}
+}
+
+// CallTool forwards the call as long as the server's pinned instance is still serving it
+func (a *affinityProxy) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return a.CallToolContext(context.Background(), serverName, toolName, arguments)
+}
+
+// CallToolContext forwards the call within ctx as long as the server's pinned instance is still serving it
+func (a *affinityProxy) CallToolContext(ctx context.Context, serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if err := a.checkInstance(serverName, false); err != nil {
+		return nil, err
+	}
+	result, err := CallToolContext(ctx, a.ProxyManager, serverName, toolName, arguments)
+	if err != nil {
+		return nil, err
+	}
+	// The call may have started the server, or landed on a replacement instance
+	if err := a.checkInstance(serverName, true); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// checkInstance fails if the server's instance differs from the pinned one.
+// With pin set, a server without a pinned instance is pinned to its current one.
+func (a *affinityProxy) checkInstance(serverName string, pin bool) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	pinned, isPinned := a.pinned[serverName]
+	current, connected := InstanceID(a.ProxyManager, serverName)
+	switch {
+	case !isPinned:
+		if pin && connected {
+			a.pinned[serverName] = current
+		}
+		return nil
+	case connected && current == pinned:
+		return nil
+	case !connected && !pin:
+		// It will have to be reconnected, so the pinned instance is gone
+		return fmt.Errorf("server %s was disconnected earlier in this run, losing the state of its session", serverName)
+	case !connected:
+		// Dropped during the call; the next call reports it
+		return nil
+	default:
+		return fmt.Errorf("server %s was reconnected earlier in this run, losing the state of its session", serverName)
+	}
+}
+
+// PendingServers forwards to the wrapped proxy manager
+func (a *affinityProxy) PendingServers() []string {
+	return PendingServers(a.ProxyManager)
+}
+
+// EnsureStarted forwards to the wrapped proxy manager
+func (a *affinityProxy) EnsureStarted(serverName string) ([]*mcp.Tool, error) {
+	return EnsureStarted(a.ProxyManager, serverName)
+}
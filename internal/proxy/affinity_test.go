@@ -0,0 +1,75 @@
+package proxy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+func TestWithAffinity(t *testing.T) {
+	manager := NewManager(&config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"upstream": testServerConfig("alpha"),
+		"other":    testServerConfig("beta"),
+	}}, WithQuietMode())
+	defer manager.Stop()
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	run := WithAffinity(manager)
+	if _, err := run.CallTool("upstream", "alpha", nil); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if _, err := run.CallTool("upstream", "alpha", nil); err != nil {
+		t.Fatalf("CallTool() on the same instance error = %v", err)
+	}
+
+	if _, err := manager.RestartServer("upstream"); err != nil {
+		t.Fatalf("RestartServer() error = %v", err)
+	}
+	_, err := run.CallTool("upstream", "alpha", nil)
+	if err == nil || !strings.Contains(err.Error(), "server upstream was reconnected earlier in this run") {
+		t.Errorf("CallTool() after restart error = %v, want affinity failure", err)
+	}
+
+	// Servers the run hasn't called yet aren't affected, and later runs pin the new instance
+	if _, err := run.CallTool("other", "beta", nil); err != nil {
+		t.Errorf("CallTool() to an unpinned server error = %v", err)
+	}
+	if _, err := WithAffinity(manager).CallTool("upstream", "alpha", nil); err != nil {
+		t.Errorf("CallTool() in a new run error = %v", err)
+	}
+
+	// A dropped session fails the run instead of reconnecting
+	run = WithAffinity(manager)
+	if _, err := run.CallTool("other", "beta", nil); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	manager.mu.RLock()
+	session := manager.sessions["other"]
+	manager.mu.RUnlock()
+	manager.dropSession("other", session)
+	_, err = run.CallTool("other", "beta", nil)
+	if err == nil || !strings.Contains(err.Error(), "server other was disconnected earlier in this run") {
+		t.Errorf("CallTool() after drop error = %v, want affinity failure", err)
+	}
+}
+
+// untrackedProxy is a proxy manager that doesn't identify server instances
+type untrackedProxy struct{}
+
+func (untrackedProxy) GetAllTools() map[string][]*mcp.Tool { return nil }
+
+func (untrackedProxy) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return &mcp.CallToolResult{}, nil
+}
+
+func TestWithAffinity_Untracked(t *testing.T) {
+	var pm ProxyManager = untrackedProxy{}
+	if WithAffinity(pm) != pm {
+		t.Error("Expected proxy managers that don't track instances to be returned unchanged")
+	}
+}
@@ -0,0 +1,95 @@
+package proxy
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"path/filepath"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// cachedTools is the on-disk record of a server's discovered tools
+type cachedTools struct {
+	// Fingerprint identifies the server configuration the tools were discovered with
+	Fingerprint string      `json:"fingerprint"`
+	Tools       []*mcp.Tool `json:"tools"`
+}
+
+// cacheFingerprint summarises the settings that determine which server process is launched
+func cacheFingerprint(serverConfig config.MCPServerConfig) string {
+	data, _ := json.Marshal(struct {
+		Command    string               `json:"command"`
+		Args       []string             `json:"args"`
+		Env        map[string]string    `json:"env"`
+		InheritEnv *bool                `json:"inheritEnv,omitempty"`
+		PassEnv    []string             `json:"passEnv,omitempty"`
+		Cwd        string               `json:"cwd,omitempty"`
+		Docker     *config.DockerConfig `json:"docker,omitempty"`
+		URL        string               `json:"url,omitempty"`
+	}{serverConfig.Command, serverConfig.Args, serverConfig.Env, serverConfig.InheritEnv, serverConfig.PassEnv, serverConfig.Cwd, serverConfig.Docker, serverConfig.URL})
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+// cacheFile returns the path of a server's tool cache
+func cacheFile(serverName string) (string, error) {
+	dir, err := paths.GetCacheDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, url.PathEscape(serverName)+".json"), nil
+}
+
+// loadCachedTools returns the cached tools for a server, or nil if there are none
+// or they were discovered with a different configuration
+func loadCachedTools(serverName string, serverConfig config.MCPServerConfig) []*mcp.Tool {
+	filename, err := cacheFile(serverName)
+	if err != nil {
+		return nil
+	}
+	data, err := storage.ReadFile(filename)
+	if err != nil {
+		return nil
+	}
+
+	var cached cachedTools
+	if err := json.Unmarshal(data, &cached); err != nil || cached.Fingerprint != cacheFingerprint(serverConfig) {
+		return nil
+	}
+	return cached.Tools
+}
+
+// saveCachedTools records a server's discovered tools for the next startup
+func saveCachedTools(serverName string, serverConfig config.MCPServerConfig, tools []*mcp.Tool) error {
+	filename, err := cacheFile(serverName)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(cachedTools{
+		Fingerprint: cacheFingerprint(serverConfig),
+		Tools:       tools,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal tool cache: %w", err)
+	}
+	return storage.WriteFile(filename, data, 0644)
+}
+
+// toolsEqual reports whether two tool lists advertise the same tools
+func toolsEqual(a, b []*mcp.Tool) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	encodedA, errA := json.Marshal(a)
+	encodedB, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(encodedA, encodedB)
+}
@@ -0,0 +1,132 @@
+package proxy
+
+import (
+	"context"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+// testServerEnv makes the test binary act as an upstream MCP server offering the listed tools
+const testServerEnv = "PROXY_TEST_SERVER_TOOLS"
+
+// testServerDelayEnv delays the test server's start by a duration, as a slow server's would be
+const testServerDelayEnv = "PROXY_TEST_SERVER_DELAY"
+
+// testServerLingerEnv delays the test server's exit by a duration once its stdin is closed
+const testServerLingerEnv = "PROXY_TEST_SERVER_LINGER"
+
+func TestMain(m *testing.M) {
+	if toolNames := os.Getenv(testServerEnv); toolNames != "" {
+		if delay, err := time.ParseDuration(os.Getenv(testServerDelayEnv)); err == nil {
+			time.Sleep(delay)
+		}
+		server := mcp.NewServer(&mcp.Implementation{Name: "test-upstream", Version: "1.0.0"}, nil)
+		for _, name := range strings.Split(toolNames, ",") {
+			mcp.AddTool(server, &mcp.Tool{Name: name, Description: "Test tool " + name}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil, nil
+			})
+		}
+		err := server.Run(context.Background(), &mcp.StdioTransport{})
+		if linger, parseErr := time.ParseDuration(os.Getenv(testServerLingerEnv)); parseErr == nil {
+			time.Sleep(linger)
+		}
+		if err != nil {
+			os.Exit(1)
+		}
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// testServerConfig configures a server that runs this test binary offering the named tools
+func testServerConfig(toolNames string) config.MCPServerConfig {
+	return config.MCPServerConfig{
+		Command: os.Args[0],
+		Args:    []string{"-test.run=^$"},
+		Env:     map[string]string{testServerEnv: toolNames},
+	}
+}
+
+func TestToolCacheRoundTrip(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	serverConfig := config.MCPServerConfig{Command: "mcp-server-github", Args: []string{"--org", "a"}}
+	tools := []*mcp.Tool{{Name: "get_issue", Description: "Fetch an issue"}}
+
+	if cached := loadCachedTools("github", serverConfig); cached != nil {
+		t.Fatalf("loadCachedTools() before saving = %v, want nil", cached)
+	}
+	if err := saveCachedTools("github", serverConfig, tools); err != nil {
+		t.Fatalf("saveCachedTools() error = %v", err)
+	}
+
+	cached := loadCachedTools("github", serverConfig)
+	if !toolsEqual(cached, tools) {
+		t.Errorf("loadCachedTools() = %v, want %v", cached, tools)
+	}
+
+	// A changed command line invalidates the cache
+	serverConfig.Args = []string{"--org", "b"}
+	if cached := loadCachedTools("github", serverConfig); cached != nil {
+		t.Errorf("loadCachedTools() with changed config = %v, want nil", cached)
+	}
+}
+
+func TestManagerStartsFromToolCache(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	serverConfig := testServerConfig("fresh_tool")
+	stale := []*mcp.Tool{{Name: "stale_tool"}}
+	if err := saveCachedTools("upstream", serverConfig, stale); err != nil {
+		t.Fatalf("saveCachedTools() error = %v", err)
+	}
+
+	var mu sync.Mutex
+	var previous, current []*mcp.Tool
+	changed := make(chan struct{})
+	manager := NewManager(&config.Config{MCPServers: map[string]config.MCPServerConfig{"upstream": serverConfig}},
+		WithQuietMode(), WithToolCache(), WithToolsChangedHandler(func(serverName string, before, after []*mcp.Tool) {
+			mu.Lock()
+			previous, current = before, after
+			mu.Unlock()
+			close(changed)
+		}))
+	defer manager.Stop()
+
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// The cached tools are available before the connection completes
+	if tools := manager.GetAllTools()["upstream"]; len(tools) != 1 || tools[0].Name != "stale_tool" && tools[0].Name != "fresh_tool" {
+		t.Errorf("GetAllTools() right after Start() = %v", tools)
+	}
+
+	// Calls wait for the background connection
+	if _, err := manager.CallTool("upstream", "fresh_tool", nil); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+
+	select {
+	case <-changed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("tools changed handler was not called")
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(previous) != 1 || previous[0].Name != "stale_tool" || len(current) != 1 || current[0].Name != "fresh_tool" {
+		t.Errorf("handler got previous=%v current=%v", previous, current)
+	}
+
+	// The cache now holds the freshly discovered tools
+	if cached := loadCachedTools("upstream", serverConfig); len(cached) != 1 || cached[0].Name != "fresh_tool" {
+		t.Errorf("cache after discovery = %v, want fresh_tool", cached)
+	}
+}
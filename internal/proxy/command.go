@@ -0,0 +1,168 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/jsonrpc"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/logging"
+)
+
+// commandTransport runs a server as a subprocess, exchanging newline-delimited JSON-RPC messages over
+// its stdin and stdout. Unlike mcp.CommandTransport, the time a stopping server is given to exit is configurable.
+type commandTransport struct {
+	cmd             *exec.Cmd
+	shutdownTimeout time.Duration
+	cleanup         func() // run if the process has to be killed, e.g. to remove its container
+}
+
+// Connect starts the server process
+func (t *commandTransport) Connect(ctx context.Context) (mcp.Connection, error) {
+	stdout, err := t.cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdin, err := t.cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := t.cmd.Start(); err != nil {
+		return nil, err
+	}
+	return &commandConn{
+		cmd:             t.cmd,
+		stdin:           stdin,
+		decoder:         json.NewDecoder(stdout),
+		shutdownTimeout: t.shutdownTimeout,
+		cleanup:         t.cleanup,
+	}, nil
+}
+
+// newCommandTransport creates the transport that launches a server. The process isn't tied to m.ctx:
+// closing its session shuts it down gracefully instead.
+func (m *Manager) newCommandTransport(serverName string, serverConfig config.MCPServerConfig) *commandTransport {
+	// The server's environment is the metatool's unless it is configured not to inherit it
+	cmd, cleanup := serverCommand(serverName, serverConfig)
+	cmd.Dir = serverConfig.Cwd
+	logging.Debugf("Launching server %s: %s", serverName, strings.Join(cmd.Args, " "))
+
+	// Keep what the server writes to stderr for diagnostics, without waiting on any
+	// child processes that inherit it once the server itself has exited
+	cmd.Stderr = m.stderrFor(serverName)
+	cmd.WaitDelay = stderrWaitDelay
+
+	return &commandTransport{cmd: cmd, shutdownTimeout: serverConfig.ShutdownTimeoutDuration(), cleanup: cleanup}
+}
+
+// serverCommand builds the command that launches a server, with a function that cleans up after it
+// if the command has to be killed
+func serverCommand(serverName string, serverConfig config.MCPServerConfig) (*exec.Cmd, func()) {
+	if serverConfig.Docker != nil {
+		return dockerCommand(serverName, serverConfig)
+	}
+	cmd := exec.Command(serverConfig.Command, serverConfig.Args...)
+	cmd.Env = serverConfig.Environ(cmd.Environ())
+	return cmd, nil
+}
+
+// commandConn is a connection to a server subprocess
+type commandConn struct {
+	cmd             *exec.Cmd
+	stdin           io.WriteCloser
+	decoder         *json.Decoder
+	shutdownTimeout time.Duration
+	cleanup         func()
+
+	writeMu   sync.Mutex
+	closeOnce sync.Once
+	closeErr  error
+}
+
+// Read decodes the next message the server wrote to stdout. Closing the connection ends the
+// process, which unblocks a pending Read.
+func (c *commandConn) Read(ctx context.Context) (jsonrpc.Message, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var raw json.RawMessage
+	if err := c.decoder.Decode(&raw); err != nil {
+		return nil, err
+	}
+	return jsonrpc.DecodeMessage(raw)
+}
+
+// Write sends a message to the server's stdin
+func (c *commandConn) Write(ctx context.Context, msg jsonrpc.Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := jsonrpc.EncodeMessage(msg)
+	if err != nil {
+		return err
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_, err = c.stdin.Write(append(data, '\n'))
+	return err
+}
+
+// SessionID is empty, as stdio connections have no session IDs
+func (c *commandConn) SessionID() string { return "" }
+
+// Close shuts the server down as the MCP spec describes: it closes the server's stdin and waits for it
+// to exit, sending SIGTERM if it hasn't within the shutdown timeout, then SIGKILL after another.
+func (c *commandConn) Close() error {
+	c.closeOnce.Do(func() {
+		c.closeErr = c.shutdown()
+	})
+	return c.closeErr
+}
+
+// shutdown stops the server process, escalating until it exits
+func (c *commandConn) shutdown() error {
+	if err := c.stdin.Close(); err != nil {
+		return fmt.Errorf("closing stdin: %w", err)
+	}
+	exited := make(chan error, 1)
+	go func() {
+		exited <- c.cmd.Wait()
+	}()
+	wait := func(timeout time.Duration) (error, bool) {
+		select {
+		case err := <-exited:
+			return err, true
+		case <-time.After(timeout):
+			return nil, false
+		}
+	}
+
+	if err, ok := wait(c.shutdownTimeout); ok {
+		return err
+	}
+	if err := c.cmd.Process.Signal(syscall.SIGTERM); err == nil {
+		if err, ok := wait(c.shutdownTimeout); ok {
+			return err
+		}
+	}
+	if c.cleanup != nil {
+		defer c.cleanup()
+	}
+	if err := c.cmd.Process.Kill(); err != nil {
+		return err
+	}
+	if err, ok := wait(c.shutdownTimeout); ok {
+		return err
+	}
+	return errors.New("server process did not exit after SIGKILL")
+}
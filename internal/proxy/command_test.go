@@ -0,0 +1,43 @@
+package proxy
+
+import (
+	"context"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func TestCommandConnClose(t *testing.T) {
+	const timeout = 200 * time.Millisecond
+	tests := []struct {
+		name    string
+		script  string
+		min     time.Duration
+		max     time.Duration
+		wantErr bool
+	}{
+		{"exits when stdin closes", `cat >/dev/null`, 0, timeout, false},
+		{"exits on SIGTERM", `trap 'exit 0' TERM; while :; do sleep 0.05; done`, timeout, 2 * timeout, false},
+		{"killed after ignoring SIGTERM", `trap '' TERM; while :; do sleep 0.05; done`, 2 * timeout, 3 * timeout, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			transport := &commandTransport{cmd: exec.Command("/bin/sh", "-c", tt.script), shutdownTimeout: timeout}
+			conn, err := transport.Connect(context.Background())
+			if err != nil {
+				t.Fatalf("Connect() error = %v", err)
+			}
+			time.Sleep(50 * time.Millisecond) // let the shell install its trap
+
+			start := time.Now()
+			err = conn.Close()
+			elapsed := time.Since(start)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("Close() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if elapsed < tt.min || elapsed > tt.max+time.Second {
+				t.Errorf("Close() took %v, want between %v and %v", elapsed, tt.min, tt.max)
+			}
+		})
+	}
+}
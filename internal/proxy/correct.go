@@ -0,0 +1,68 @@
+package proxy
+
+import (
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/schema"
+)
+
+// validationMarkers are phrases upstream servers commonly use when rejecting malformed arguments
+var validationMarkers = []string{
+	"invalid params",
+	"invalid argument",
+	"invalid input",
+	"invalid_type",
+	"validat",
+	"schema",
+	"unknown field",
+	"unexpected property",
+	"additional propert",
+	"unrecognized key",
+	"cannot unmarshal",
+	"expected",
+}
+
+// isValidationFailure reports whether a failed call looks like the upstream rejected its arguments
+func isValidationFailure(result *mcp.CallToolResult, err error) bool {
+	message := failureMessage(result, err)
+	if message == "" {
+		return false
+	}
+
+	message = strings.ToLower(message)
+	for _, marker := range validationMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// correctArguments fits arguments to the input schema the upstream advertised for a tool,
+// returning false if the tool is unknown or nothing needed correcting
+func (m *Manager) correctArguments(serverName, toolName string, arguments map[string]interface{}) (map[string]interface{}, bool) {
+	m.mu.RLock()
+	var tool *mcp.Tool
+	for _, candidate := range m.tools[serverName] {
+		if candidate.Name == toolName {
+			tool = candidate
+			break
+		}
+	}
+	m.mu.RUnlock()
+	if tool == nil || tool.InputSchema == nil {
+		return nil, false
+	}
+
+	corrected, corrections := schema.Correct(tool.InputSchema, arguments)
+	if len(corrections) == 0 {
+		return nil, false
+	}
+	if !m.quiet {
+		logging.Infof("Retrying %s__%s with corrected arguments: %s", serverName, toolName, strings.Join(corrections, "; "))
+	}
+	return corrected, true
+}
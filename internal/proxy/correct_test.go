@@ -0,0 +1,94 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+type repeatArgs struct {
+	Text  string `json:"text"`
+	Count int    `json:"count"`
+	Loud  bool   `json:"loud,omitempty"`
+}
+
+// attachRepeater connects an upstream whose repeat tool validates its arguments strictly
+func attachRepeater(t *testing.T, m *Manager, serverName string) *int {
+	t.Helper()
+	calls := 0
+	upstream := mcp.NewServer(&mcp.Implementation{Name: serverName, Version: "1.0.0"}, nil)
+	mcp.AddTool(upstream, &mcp.Tool{Name: "repeat"}, func(ctx context.Context, req *mcp.CallToolRequest, args repeatArgs) (*mcp.CallToolResult, any, error) {
+		calls++
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("%s x%d loud=%v", args.Text, args.Count, args.Loud)}}}, nil, nil
+	})
+	attachUpstream(t, m, serverName, upstream)
+	return &calls
+}
+
+func TestManagerCorrectsRejectedArguments(t *testing.T) {
+	cfg := &config.Config{
+		MCPServers: map[string]config.MCPServerConfig{
+			"strict":  {Command: "false"},
+			"lenient": {Command: "false", CorrectArguments: true},
+		},
+	}
+	manager := NewManager(cfg, WithQuietMode())
+	defer manager.Stop()
+	attachRepeater(t, manager, "strict")
+	calls := attachRepeater(t, manager, "lenient")
+
+	drifted := map[string]interface{}{"text": "hi", "count": "3", "loud": "true", "volume": 11}
+
+	if _, err := manager.CallTool("strict", "repeat", drifted); err == nil {
+		t.Error("CallTool() on a server without correctArguments should surface the validation error")
+	}
+
+	result, err := manager.CallTool("lenient", "repeat", drifted)
+	if err != nil {
+		t.Fatalf("CallTool() error = %v, want the corrected retry to succeed", err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "hi x3 loud=true" {
+		t.Errorf("CallTool() result = %q, want %q", text, "hi x3 loud=true")
+	}
+	if _, present := drifted["volume"]; !present || drifted["count"] != "3" {
+		t.Error("correcting arguments should not modify the caller's map")
+	}
+
+	// Arguments that can't be corrected fail without a pointless retry
+	*calls = 0
+	if _, err := manager.CallTool("lenient", "repeat", map[string]interface{}{"text": "hi", "count": "three"}); err == nil {
+		t.Error("CallTool() with uncorrectable arguments should fail")
+	}
+	if *calls != 0 {
+		t.Errorf("upstream handler ran %d time(s), want 0", *calls)
+	}
+}
+
+func TestIsValidationFailure(t *testing.T) {
+	errorResult := func(text string) *mcp.CallToolResult {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: text}}, IsError: true}
+	}
+	tests := []struct {
+		name   string
+		result *mcp.CallToolResult
+		err    error
+		want   bool
+	}{
+		{"protocol error", nil, fmt.Errorf(`calling "tools/call": validating "arguments": unexpected additional properties ["x"]`), true},
+		{"zod style result", errorResult(`Invalid arguments: [{"code":"invalid_type","expected":"number"}]`), nil, true},
+		{"unrelated result", errorResult("repository not found"), nil, false},
+		{"connection error", nil, fmt.Errorf("connection closed"), false},
+		{"success", &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "invalid params"}}}, nil, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isValidationFailure(tt.result, tt.err); got != tt.want {
+				t.Errorf("isValidationFailure() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,62 @@
+package proxy
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"regexp"
+	"sort"
+	"sync/atomic"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/logging"
+)
+
+// dockerContainers numbers the containers this process starts, so each gets a unique name
+var dockerContainers atomic.Uint64
+
+// invalidContainerNameChars matches characters docker doesn't allow in container names
+var invalidContainerNameChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]`)
+
+// dockerCommand builds the docker run command that runs a server in a container, which docker removes
+// once the server exits. The returned cleanup function removes the container if the docker client had
+// to be killed, as the container would otherwise be left running.
+func dockerCommand(serverName string, serverConfig config.MCPServerConfig) (*exec.Cmd, func()) {
+	docker := serverConfig.Docker
+	name := fmt.Sprintf("mcp-metatool-%s-%d-%d", invalidContainerNameChars.ReplaceAllString(serverName, "-"), os.Getpid(), dockerContainers.Add(1))
+	pull := docker.Pull
+	if pull == "" {
+		pull = "missing"
+	}
+
+	args := []string{"run", "--rm", "-i", "--name", name, "--label", "mcp-metatool.server=" + serverName, "--pull", pull}
+	for _, volume := range docker.Volumes {
+		args = append(args, "-v", volume)
+	}
+	keys := make([]string, 0, len(docker.Env))
+	for key := range docker.Env {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		// Values are passed through docker's environment so they don't appear in process listings
+		args = append(args, "-e", key)
+	}
+	args = append(args, docker.Image)
+	args = append(args, serverConfig.Args...)
+
+	cmd := exec.Command("docker", args...)
+	cmd.Env = serverConfig.Environ(cmd.Environ())
+	for _, key := range keys {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", key, docker.Env[key]))
+	}
+
+	cleanup := func() {
+		rm := exec.Command("docker", "rm", "-f", name)
+		rm.Env = cmd.Env // the same docker host as the container was run on
+		if output, err := rm.CombinedOutput(); err != nil {
+			logging.Warnf("Failed to remove container %s for server %s: %v: %s", name, serverName, err, output)
+		}
+	}
+	return cmd, cleanup
+}
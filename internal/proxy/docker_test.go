@@ -0,0 +1,107 @@
+package proxy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+// fakeDocker puts a docker script running body first on PATH
+func fakeDocker(t *testing.T, body string) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "docker"), []byte("#!/bin/sh\n"+body), 0755); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+}
+
+func TestDockerCommand(t *testing.T) {
+	serverConfig := config.MCPServerConfig{
+		Args: []string{"stdio", "--verbose"},
+		Env:  map[string]string{"DOCKER_HOST": "unix:///tmp/docker.sock"},
+		Docker: &config.DockerConfig{
+			Image:   "ghcr.io/example/server:1",
+			Volumes: []string{"/data:/data:ro"},
+			Env:     map[string]string{"TOKEN": "secret", "MODE": "fast"},
+		},
+	}
+	cmd, cleanup := dockerCommand("my server", serverConfig)
+	if cleanup == nil {
+		t.Error("Expected a cleanup function")
+	}
+
+	args := strings.Join(cmd.Args, " ")
+	want := regexp.MustCompile(`^docker run --rm -i --name mcp-metatool-my-server-\d+-\d+ --label mcp-metatool.server=my server --pull missing -v /data:/data:ro -e MODE -e TOKEN ghcr.io/example/server:1 stdio --verbose$`)
+	if !want.MatchString(args) {
+		t.Errorf("Command = %q, want it to match %s", args, want)
+	}
+	if strings.Contains(args, "secret") {
+		t.Errorf("Command %q exposes a container env value", args)
+	}
+	for _, entry := range []string{"DOCKER_HOST=unix:///tmp/docker.sock", "TOKEN=secret", "MODE=fast"} {
+		if !slices.Contains(cmd.Env, entry) {
+			t.Errorf("Environment is missing %s", entry)
+		}
+	}
+
+	// Each launch gets its own container
+	other, _ := dockerCommand("my server", serverConfig)
+	if slices.Equal(other.Args, cmd.Args) {
+		t.Error("Expected a different container name for a second launch")
+	}
+}
+
+func TestManagerDockerServer(t *testing.T) {
+	fakeDocker(t, `echo "$1 token=$TOKEN" >&2; exit 1`)
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"container": {Docker: &config.DockerConfig{Image: "example", Env: map[string]string{"TOKEN": "t"}}},
+	}}
+	m := NewManager(cfg, WithQuietMode())
+	defer m.Stop()
+	m.Start()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(m.ServerStderr("container")) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := m.ServerStderr("container"); len(got) != 1 || got[0] != "run token=t" {
+		t.Errorf("Fake docker reported %q, want %q", got, "run token=t")
+	}
+	if statuses := m.ServerStatuses(); len(statuses) != 1 || statuses[0].Transport != "docker" {
+		t.Errorf("ServerStatuses() = %+v, want docker transport", statuses)
+	}
+}
+
+func TestDockerCleanupAfterKill(t *testing.T) {
+	marker := filepath.Join(t.TempDir(), "removed")
+	fakeDocker(t, `if [ "$1" = rm ]; then echo "$@" > "$MARKER"; exit 0; fi
+trap '' TERM; while :; do sleep 0.05; done`)
+	serverConfig := config.MCPServerConfig{
+		Env:    map[string]string{"MARKER": marker},
+		Docker: &config.DockerConfig{Image: "example"},
+	}
+
+	cmd, cleanup := dockerCommand("stubborn", serverConfig)
+	transport := &commandTransport{cmd: cmd, shutdownTimeout: 100 * time.Millisecond, cleanup: cleanup}
+	conn, err := transport.Connect(context.Background())
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	time.Sleep(50 * time.Millisecond) // let the script install its trap
+	conn.Close()
+
+	data, err := os.ReadFile(marker)
+	if err != nil {
+		t.Fatalf("Container was not removed: %v", err)
+	}
+	if !regexp.MustCompile(`^rm -f mcp-metatool-stubborn-\d+-\d+\n$`).Match(data) {
+		t.Errorf("Removed with %q", data)
+	}
+}
@@ -0,0 +1,161 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/logging"
+)
+
+// healthCheckTimeout bounds each ping made by CheckHealth
+const healthCheckTimeout = 5 * time.Second
+
+// HealthChecker is implemented by proxy managers that can check their servers' health on demand
+type HealthChecker interface {
+	// CheckHealth pings every connected server once, updating the health in ServerStatuses
+	CheckHealth()
+}
+
+// CheckHealth pings the proxy manager's servers, returning false if it can't check them
+func CheckHealth(pm ProxyManager) bool {
+	if checker, ok := pm.(HealthChecker); ok {
+		checker.CheckHealth()
+		return true
+	}
+	return false
+}
+
+// serverHealth tracks the outcome of recent pings and calls to a server
+type serverHealth struct {
+	latency     time.Duration // of the last successful ping
+	checkedAt   time.Time     // when the last ping was made
+	lastSuccess time.Time     // of a tool call
+	failures    int           // consecutive failed pings and calls
+}
+
+// CheckHealth pings every connected server once, concurrently
+func (m *Manager) CheckHealth() {
+	m.mu.RLock()
+	sessions := make(map[string]*mcp.ClientSession, len(m.sessions))
+	for serverName, session := range m.sessions {
+		sessions[serverName] = session
+	}
+	m.mu.RUnlock()
+
+	var wg sync.WaitGroup
+	for serverName, session := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			m.pingSession(serverName, session, healthCheckTimeout)
+		}()
+	}
+	wg.Wait()
+}
+
+// pingSession pings a server's session and records the outcome
+func (m *Manager) pingSession(serverName string, session *mcp.ClientSession, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(m.ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	err := session.Ping(ctx, nil)
+	latency := time.Since(start)
+	if err != nil {
+		logging.Debugf("Ping to server %s failed after %v: %v", serverName, latency, err)
+	} else {
+		logging.Debugf("Pinged server %s in %v", serverName, latency)
+	}
+
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	health := m.healthLocked(serverName)
+	health.checkedAt = start
+	if err != nil {
+		health.failures++
+	} else {
+		health.latency = latency
+		health.failures = 0
+	}
+	return err
+}
+
+// recordCall records the outcome of a tool call for health reporting
+func (m *Manager) recordCall(serverName string, err error) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	health := m.healthLocked(serverName)
+	if err != nil {
+		health.failures++
+	} else {
+		health.lastSuccess = time.Now()
+		health.failures = 0
+	}
+}
+
+// healthLocked returns a server's health record, creating it if needed. The caller must hold m.statusMu.
+func (m *Manager) healthLocked(serverName string) *serverHealth {
+	health := m.health[serverName]
+	if health == nil {
+		health = &serverHealth{}
+		m.health[serverName] = health
+	}
+	return health
+}
+
+// describe fills in the health of a status whose connection state is already set
+func (h *serverHealth) describe(status *ServerStatus) {
+	if h != nil {
+		status.ConsecutiveFailures = h.failures
+		if !h.checkedAt.IsZero() {
+			checkedAt := h.checkedAt
+			status.LastCheckAt = &checkedAt
+		}
+		if h.latency > 0 {
+			status.LatencyMs = float64(h.latency.Microseconds()) / 1000
+		}
+		if !h.lastSuccess.IsZero() {
+			lastSuccess := h.lastSuccess
+			status.LastSuccessAt = &lastSuccess
+		}
+	}
+
+	switch {
+	case status.State == StateFailed || status.State == StateDisconnected:
+		status.Health = HealthUnhealthy
+	case status.State != StateConnected:
+		status.Health = HealthUnknown
+	case status.ConsecutiveFailures >= pingFailureThreshold:
+		status.Health = HealthUnhealthy
+	case status.ConsecutiveFailures > 0:
+		status.Health = HealthDegraded
+	default:
+		status.Health = HealthHealthy
+	}
+}
+
+// HealthSummary describes the server's health on one line
+func (s ServerStatus) HealthSummary() string {
+	parts := []string{s.Health}
+	if s.State != StateConnected {
+		parts[0] += " (" + s.State + ")"
+	}
+	if s.ProtocolVersion != "" {
+		parts = append(parts, "protocol "+s.ProtocolVersion)
+	}
+	if s.LatencyMs > 0 {
+		parts = append(parts, fmt.Sprintf("latency %.1fms", s.LatencyMs))
+	}
+	if s.LastSuccessAt != nil {
+		parts = append(parts, "last successful call "+s.LastSuccessAt.Format("2006-01-02 15:04:05"))
+	}
+	if s.ConsecutiveFailures > 0 {
+		parts = append(parts, fmt.Sprintf("%d consecutive failure(s)", s.ConsecutiveFailures))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,93 @@
+package proxy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+func TestManagerHealth(t *testing.T) {
+	cfg := &config.Config{
+		MCPServers: map[string]config.MCPServerConfig{
+			"live":  {Command: "false"},
+			"lazy":  {Command: "false", LazyStart: true},
+			"quiet": {Command: "false"},
+		},
+	}
+
+	manager := NewManager(cfg, WithQuietMode())
+	defer manager.Stop()
+	upstream := connectInMemory(t, manager, "live")
+	connectInMemory(t, manager, "quiet")
+
+	health := func() map[string]ServerStatus {
+		statuses := make(map[string]ServerStatus)
+		for _, status := range manager.ServerStatuses() {
+			statuses[status.Name] = status
+		}
+		return statuses
+	}
+
+	statuses := health()
+	if live := statuses["live"]; live.Health != HealthHealthy || live.LastCheckAt != nil || live.LastSuccessAt != nil {
+		t.Errorf("Expected unchecked healthy server, got %+v", live)
+	}
+	if lazy := statuses["lazy"]; lazy.Health != HealthUnknown {
+		t.Errorf("Expected stopped server health to be unknown, got %+v", lazy)
+	}
+
+	if _, err := manager.CallTool("live", "ping", nil); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	manager.CheckHealth()
+	statuses = health()
+	live := statuses["live"]
+	if live.LastSuccessAt == nil || live.LastCheckAt == nil || live.LatencyMs <= 0 || live.ConsecutiveFailures != 0 {
+		t.Errorf("Expected successful call and ping to be recorded, got %+v", live)
+	}
+	if quiet := statuses["quiet"]; quiet.LastCheckAt == nil || quiet.LastSuccessAt != nil {
+		t.Errorf("Expected only a ping for the uncalled server, got %+v", quiet)
+	}
+
+	// Failed pings degrade the server until it is unreachable
+	upstream.Close()
+	manager.CheckHealth()
+	if live := health()["live"]; live.Health != HealthDegraded || live.ConsecutiveFailures != 1 {
+		t.Errorf("Expected degraded server after one failed ping, got %+v", live)
+	}
+	manager.recordCall("live", errors.New("broken pipe"))
+	manager.CheckHealth()
+	live = health()["live"]
+	if live.Health != HealthUnhealthy || live.ConsecutiveFailures != 3 {
+		t.Errorf("Expected unhealthy server after three failures, got %+v", live)
+	}
+	if summary := live.HealthSummary(); !strings.Contains(summary, "unhealthy") || !strings.Contains(summary, "3 consecutive failure(s)") {
+		t.Errorf("Unexpected health summary: %s", summary)
+	}
+
+	// Forgetting a server discards its health
+	manager.forgetErrors("live")
+	if live := health()["live"]; live.ConsecutiveFailures != 0 || live.LastCheckAt != nil {
+		t.Errorf("Expected health to be forgotten, got %+v", live)
+	}
+}
+
+func TestHealthSummary(t *testing.T) {
+	calledAt := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	tests := []struct {
+		status ServerStatus
+		want   string
+	}{
+		{ServerStatus{State: StateConnected, Health: HealthHealthy, LatencyMs: 1.25, LastSuccessAt: &calledAt}, "healthy, latency 1.2ms, last successful call 2025-01-15 10:30:00"},
+		{ServerStatus{State: StateConnected, Health: HealthDegraded, ConsecutiveFailures: 2}, "degraded, 2 consecutive failure(s)"},
+		{ServerStatus{State: StateStopped, Health: HealthUnknown}, "unknown (stopped)"},
+	}
+	for _, tt := range tests {
+		if got := tt.status.HealthSummary(); got != tt.want {
+			t.Errorf("HealthSummary() = %q, want %q", got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,22 @@
+package proxy
+
+import (
+	"net/http"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/oauth"
+)
+
+// newHTTPTransport connects to a remote server over the streamable HTTP transport, authorizing
+// requests with OAuth access tokens if the server is configured to
+func newHTTPTransport(serverName string, serverConfig config.MCPServerConfig) *mcp.StreamableClientTransport {
+	transport := &mcp.StreamableClientTransport{Endpoint: serverConfig.URL}
+	if serverConfig.OAuth != nil {
+		transport.HTTPClient = &http.Client{
+			Transport: &oauth.Transport{Source: oauth.NewSource(serverName, serverConfig.OAuth)},
+		}
+	}
+	return transport
+}
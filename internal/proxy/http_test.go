@@ -0,0 +1,68 @@
+package proxy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+func TestManagerRemoteServerWithOAuth(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	upstream := mcp.NewServer(&mcp.Implementation{Name: "remote", Version: "1.0.0"}, nil)
+	mcp.AddTool(upstream, &mcp.Tool{Name: "ping"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "pong"}}}, nil, nil
+	})
+	handler := mcp.NewStreamableHTTPHandler(func(*http.Request) *mcp.Server { return upstream }, nil)
+
+	var tokensIssued atomic.Int32
+	mux := http.NewServeMux()
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		if id, secret, _ := r.BasicAuth(); id != "client" || secret != "s3cret" || r.FormValue("grant_type") != "client_credentials" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		tokensIssued.Add(1)
+		json.NewEncoder(w).Encode(map[string]any{"access_token": "remote-access-token", "expires_in": 3600})
+	})
+	mux.HandleFunc("/mcp", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer remote-access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"remote": {
+			URL:   server.URL + "/mcp",
+			OAuth: &config.OAuthConfig{TokenURL: server.URL + "/token", ClientID: "client", ClientSecret: "s3cret"},
+		},
+	}}
+	m := NewManager(cfg, WithQuietMode())
+	defer m.Stop()
+	m.Start()
+
+	result, err := m.CallTool("remote", "ping", nil)
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "pong" {
+		t.Errorf("CallTool() = %q, want pong", text)
+	}
+	if n := tokensIssued.Load(); n != 1 {
+		t.Errorf("Issued %d tokens, want 1 reused for every request", n)
+	}
+	if statuses := m.ServerStatuses(); statuses[0].Transport != "http" {
+		t.Errorf("Transport = %q, want http", statuses[0].Transport)
+	}
+}
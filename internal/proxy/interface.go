@@ -1,6 +1,11 @@
 package proxy
 
-import "github.com/modelcontextprotocol/go-sdk/mcp"
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
 
 // ProxyManager defines the interface for accessing upstream MCP servers
 // This is the canonical definition used throughout the codebase
@@ -10,4 +15,81 @@ type ProxyManager interface {
 
 	// CallTool invokes a tool on the specified upstream server
 	CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error)
-}
\ No newline at end of file
+}
+
+// ContextCaller is implemented by proxy managers that carry a context, such as the caller's trace,
+// into tool calls
+type ContextCaller interface {
+	// CallToolContext invokes a tool on the specified upstream server within ctx
+	CallToolContext(ctx context.Context, serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error)
+}
+
+// CallToolContext calls a tool through the proxy manager, passing ctx along if it accepts one
+func CallToolContext(ctx context.Context, pm ProxyManager, serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if caller, ok := pm.(ContextCaller); ok {
+		return caller.CallToolContext(ctx, serverName, toolName, arguments)
+	}
+	return pm.CallTool(serverName, toolName, arguments)
+}
+
+// LazyStarter is implemented by proxy managers that can defer launching servers until first use
+type LazyStarter interface {
+	// PendingServers returns the configured servers that have not been started yet
+	PendingServers() []string
+
+	// EnsureStarted launches the named server if necessary and returns its tools
+	EnsureStarted(serverName string) ([]*mcp.Tool, error)
+}
+
+// PendingServers returns the servers the proxy manager has yet to start, if it starts them lazily
+func PendingServers(pm ProxyManager) []string {
+	if starter, ok := pm.(LazyStarter); ok {
+		return starter.PendingServers()
+	}
+	return nil
+}
+
+// EnsureStarted starts a pending server through the proxy manager, if it starts servers lazily
+func EnsureStarted(pm ProxyManager, serverName string) ([]*mcp.Tool, error) {
+	if starter, ok := pm.(LazyStarter); ok {
+		return starter.EnsureStarted(serverName)
+	}
+	return nil, fmt.Errorf("server %s not connected", serverName)
+}
+// Restarter is implemented by proxy managers that can restart individual servers
+type Restarter interface {
+	// RestartServer relaunches the named server and returns its rediscovered tools
+	RestartServer(serverName string) ([]*mcp.Tool, error)
+}
+
+// RestartServer restarts a server through the proxy manager, if it can restart servers
+func RestartServer(pm ProxyManager, serverName string) ([]*mcp.Tool, error) {
+	if restarter, ok := pm.(Restarter); ok {
+		return restarter.RestartServer(serverName)
+	}
+	return nil, fmt.Errorf("restarting servers is not supported")
+}
+
+// ToolNamer is implemented by proxy managers whose servers' tools may be named other than by server and tool name
+type ToolNamer interface {
+	// ToolPrefix returns the prefix of the named server's proxied tool names
+	ToolPrefix(serverName string) string
+	// ToolAliases returns the names the named server's tools are exposed under, keyed by upstream tool name
+	ToolAliases(serverName string) map[string]string
+}
+
+// ToolPrefix returns the prefix of a server's proxied tool names, which is the server name unless configured otherwise
+func ToolPrefix(pm ProxyManager, serverName string) string {
+	if namer, ok := pm.(ToolNamer); ok {
+		return namer.ToolPrefix(serverName)
+	}
+	return serverName
+}
+
+// ToolAliases returns the names a server's tools are exposed under, keyed by upstream tool name
+func ToolAliases(pm ProxyManager, serverName string) map[string]string {
+	if namer, ok := pm.(ToolNamer); ok {
+		return namer.ToolAliases(serverName)
+	}
+	return nil
+}
@@ -3,15 +3,29 @@ package proxy
 import (
 	"context"
 	"fmt"
-	"log"
-	"os/exec"
+	"sort"
+	"strings"
 	"sync"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
 	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/history"
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/metrics"
+	"github.com/dslh/mcp-metatool/internal/tracing"
+	"github.com/dslh/mcp-metatool/internal/usage"
 )
 
+// startupConcurrency is the number of servers connected at once by Start
+const startupConcurrency = 8
+
+// pingFailureThreshold is the number of consecutive failed pings after which a session is dropped
+const pingFailureThreshold = 3
+
 // Manager manages connections to upstream MCP servers
 type Manager struct {
 	config    *config.Config
@@ -22,6 +36,39 @@ type Manager struct {
 	ctx       context.Context
 	cancel    context.CancelFunc
 	quiet     bool // suppress logging output
+	dropped   map[string]bool // servers disconnected after failed pings, reconnected on next call
+
+	// instances identifies each server's current session; see InstanceID
+	instances    map[string]uint64
+	nextInstance uint64
+
+	// Tool caching lets servers be advertised from their last known tools while they connect
+	useCache     bool
+	connecting   map[string]chan struct{} // closed once a connection attempt in progress finishes
+	toolsChanged ToolsChangedFunc
+
+	idleWatching bool // whether watchIdle is running
+
+	// refreshMu serializes rediscovery after servers announce their tool lists changed
+	refreshMu sync.Mutex
+
+	// lastErrors holds the most recent error per server for status reporting
+	statusMu   sync.Mutex
+	lastErrors map[string]serverError
+	health     map[string]*serverHealth
+	stderr     map[string]*stderrBuffer
+
+	// results caches the results of tools configured as cacheable, keyed by resultCacheKey
+	cacheMu sync.Mutex
+	results map[string]cachedResult
+
+	// usage tracks in-flight calls and last use per server for idle shutdown
+	usageMu  sync.Mutex
+	active   map[string]int
+	lastUsed map[string]time.Time
+
+	// progress forwards upstream progress notifications to the calls that asked for them
+	progress progressRelay
 }
 
 // Option is a functional option for configuring Manager
@@ -34,6 +81,23 @@ func WithQuietMode() Option {
 	}
 }
 
+// ToolsChangedFunc is called when a server's discovered tools differ from those previously known
+type ToolsChangedFunc func(serverName string, previous, current []*mcp.Tool)
+
+// WithToolCache serves each server's tools from the on-disk cache at startup and connects in the background
+func WithToolCache() Option {
+	return func(m *Manager) {
+		m.useCache = true
+	}
+}
+
+// WithToolsChangedHandler registers a callback for servers whose tools change after startup
+func WithToolsChangedHandler(handler ToolsChangedFunc) Option {
+	return func(m *Manager) {
+		m.toolsChanged = handler
+	}
+}
+
 // NewManager creates a new proxy manager with optional configuration
 func NewManager(cfg *config.Config, opts ...Option) *Manager {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -45,6 +109,15 @@ func NewManager(cfg *config.Config, opts ...Option) *Manager {
 		ctx:      ctx,
 		cancel:   cancel,
 		quiet:    false, // default to verbose
+		dropped:  make(map[string]bool),
+		connecting: make(map[string]chan struct{}),
+		instances:  make(map[string]uint64),
+		lastErrors: make(map[string]serverError),
+		health:     make(map[string]*serverHealth),
+		stderr:     make(map[string]*stderrBuffer),
+		results:    make(map[string]cachedResult),
+		active:   make(map[string]int),
+		lastUsed: make(map[string]time.Time),
 	}
 
 	// Apply options
@@ -55,111 +128,507 @@ func NewManager(cfg *config.Config, opts ...Option) *Manager {
 	return m
 }
 
-// Start initializes connections to all configured upstream servers
+// Start initializes connections to all configured upstream servers,
+// connecting up to startupConcurrency of them at a time. With the tool cache enabled,
+// servers with cached tools are connected in the background and Start doesn't wait for them.
 func (m *Manager) Start() error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	var wg sync.WaitGroup
+	var failuresMu sync.Mutex
+	var failures []string
+	slots := make(chan struct{}, startupConcurrency)
+	attempted := 0
 
 	for serverName, serverConfig := range m.config.MCPServers {
-		if err := m.connectServer(serverName, serverConfig); err != nil {
+		var cached []*mcp.Tool
+		if m.useCache {
+			cached = loadCachedTools(serverName, serverConfig)
+		}
+		if cached != nil {
+			m.mu.Lock()
+			m.tools[serverName] = cached
+			m.mu.Unlock()
+		}
+
+		if serverConfig.LazyStart {
 			if !m.quiet {
-				log.Printf("Warning: Failed to connect to server %s: %v", serverName, err)
+				logging.Infof("Deferring start of server %s until first use", serverName)
 			}
-			// Continue with other servers instead of failing completely
 			continue
 		}
+
+		if cached != nil {
+			if !m.quiet {
+				logging.Infof("Using %d cached tools for server %s while it connects", len(cached), serverName)
+			}
+			done := make(chan struct{})
+			m.mu.Lock()
+			m.connecting[serverName] = done
+			m.mu.Unlock()
+			go m.connectInBackground(serverName, serverConfig, slots, done)
+			continue
+		}
+
+		attempted++
+		wg.Add(1)
+		go func(serverName string, serverConfig config.MCPServerConfig) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			conn, err := m.dialServer(serverName, serverConfig)
+			if err != nil {
+				// Continue with other servers instead of failing completely
+				failuresMu.Lock()
+				failures = append(failures, fmt.Sprintf("%s: %v", serverName, err))
+				failuresMu.Unlock()
+				return
+			}
+
+			m.mu.Lock()
+			previous := m.storeConnection(serverName, serverConfig, conn)
+			m.mu.Unlock()
+			m.notifyToolsChanged(serverName, previous, conn.tools)
+		}(serverName, serverConfig)
+	}
+	wg.Wait()
+
+	if len(failures) > 0 && !m.quiet {
+		sort.Strings(failures)
+		logging.Warnf("Failed to connect to %d of %d servers:\n  %s", len(failures), attempted, strings.Join(failures, "\n  "))
 	}
 
+	m.startIdleWatcher()
+
 	return nil
 }
 
-// Stop closes all connections and cleans up resources
-func (m *Manager) Stop() {
+// startIdleWatcher begins shutting down idle servers if any have an idle timeout and it isn't running yet
+func (m *Manager) startIdleWatcher() {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
-	// Cancel the context to signal shutdown
-	m.cancel()
+	if m.idleWatching {
+		return
+	}
+	if interval := m.idleCheckInterval(); interval > 0 {
+		m.idleWatching = true
+		go m.watchIdle(interval)
+	}
+}
+
+// connectInBackground connects a server whose tools were served from the cache,
+// signalling done when the attempt finishes. A failed attempt is retried on the next call.
+func (m *Manager) connectInBackground(serverName string, serverConfig config.MCPServerConfig, slots chan struct{}, done chan struct{}) {
+	slots <- struct{}{}
+	defer func() { <-slots }()
+
+	conn, err := m.dialServer(serverName, serverConfig)
+
+	m.mu.Lock()
+	var previous []*mcp.Tool
+	if err == nil {
+		previous = m.storeConnection(serverName, serverConfig, conn)
+	} else {
+		m.dropped[serverName] = true
+	}
+	delete(m.connecting, serverName)
+	close(done)
+	m.mu.Unlock()
+
+	if err != nil {
+		if !m.quiet {
+			logging.Warnf("Failed to connect to server %s: %v", serverName, err)
+		}
+		return
+	}
+	m.notifyToolsChanged(serverName, previous, conn.tools)
+}
+
+// notifyToolsChanged reports newly discovered tools to the registered handler if they differ from before
+func (m *Manager) notifyToolsChanged(serverName string, previous, current []*mcp.Tool) {
+	if m.toolsChanged == nil || current == nil || toolsEqual(previous, current) {
+		return
+	}
+	if !m.quiet {
+		logging.Infof("Tools for server %s changed", serverName)
+	}
+	m.toolsChanged(serverName, previous, current)
+}
+
+// idleCheckInterval returns how often to look for idle servers, or 0 if none have an idle timeout
+func (m *Manager) idleCheckInterval() time.Duration {
+	var shortest time.Duration
+	for _, serverConfig := range m.config.MCPServers {
+		if timeout := serverConfig.IdleTimeoutDuration(); timeout > 0 && (shortest == 0 || timeout < shortest) {
+			shortest = timeout
+		}
+	}
+	if shortest == 0 {
+		return 0
+	}
+	return max(shortest/2, time.Second)
+}
+
+// watchIdle periodically shuts down idle servers until the manager is stopped
+func (m *Manager) watchIdle(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.stopIdleServers(now)
+		}
+	}
+}
+
+// stopIdleServers closes sessions that have been unused for longer than their idle timeout.
+// Discovered tools are kept so the server stays visible, and the next call restarts it.
+func (m *Manager) stopIdleServers(now time.Time) {
+	// Servers can take a while to exit, so they're closed once the manager is unlocked
+	m.closeSessions(m.detachIdleSessions(now))
+}
 
-	// Close all sessions
+// detachIdleSessions forgets the sessions that have been unused for longer than their idle timeout,
+// returning them to be closed
+func (m *Manager) detachIdleSessions(now time.Time) map[string]*mcp.ClientSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.usageMu.Lock()
+	defer m.usageMu.Unlock()
+
+	idle := make(map[string]*mcp.ClientSession)
 	for serverName, session := range m.sessions {
-		if err := session.Close(); err != nil {
+		timeout := m.config.MCPServers[serverName].IdleTimeoutDuration()
+		if timeout <= 0 || m.active[serverName] > 0 {
+			continue
+		}
+		lastUsed, ok := m.lastUsed[serverName]
+		if !ok {
+			// Never called since it connected; start the clock now
+			m.lastUsed[serverName] = now
+			continue
+		}
+		if now.Sub(lastUsed) < timeout {
+			continue
+		}
+
+		m.detachSessionLocked(serverName)
+		idle[serverName] = session
+		delete(m.lastUsed, serverName)
+		if !m.quiet {
+			logging.Infof("Stopping server %s after %s idle", serverName, timeout)
+		}
+	}
+	return idle
+}
+
+// detachSessionLocked forgets a server's session, keeping its discovered tools, so the caller can
+// close it once m.mu is released. The caller must hold m.mu.
+func (m *Manager) detachSessionLocked(serverName string) {
+	delete(m.sessions, serverName)
+	delete(m.clients, serverName)
+}
+
+// closeSessions closes sessions all at once, so servers that are slow to exit don't hold up the
+// others. The caller must not hold m.mu, since closing waits for each server to exit.
+func (m *Manager) closeSessions(sessions map[string]*mcp.ClientSession) {
+	var wg sync.WaitGroup
+	for serverName, session := range sessions {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := session.Close(); err != nil && !m.quiet {
+				logging.Warnf("Failed to close session for server %s: %v", serverName, err)
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// keepAlive pings the server's session at the given interval until it is closed or replaced,
+// recording each ping's outcome for health reporting. If dropUnresponsive is set, the session is
+// dropped after pingFailureThreshold consecutive failures and reconnected on the next call.
+func (m *Manager) keepAlive(serverName string, session *mcp.ClientSession, interval time.Duration, dropUnresponsive bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	failures := 0
+	for {
+		select {
+		case <-m.ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		m.mu.RLock()
+		current := m.sessions[serverName]
+		m.mu.RUnlock()
+		if current != session {
+			return
+		}
+
+		err := m.pingSession(serverName, session, interval/2)
+		if err == nil {
+			failures = 0
+			continue
+		}
+
+		failures++
+		if !dropUnresponsive {
 			if !m.quiet {
-				log.Printf("Error closing session for server %s: %v", serverName, err)
+				logging.Warnf("Health check of server %s failed: %v", serverName, err)
 			}
+			continue
+		}
+		if !m.quiet {
+			logging.Warnf("Ping to server %s failed (%d/%d): %v", serverName, failures, pingFailureThreshold, err)
+		}
+		if failures >= pingFailureThreshold {
+			m.dropSession(serverName, session)
+			return
 		}
 	}
+}
 
-	// Clear all state
+// dropSession disconnects an unresponsive session so the next call reconnects the server.
+// An unresponsive server is likely to be slow to exit, so it's closed once the manager is unlocked.
+func (m *Manager) dropSession(serverName string, session *mcp.ClientSession) {
+	if !m.detachDroppedSession(serverName, session) {
+		return
+	}
+	m.closeSessions(map[string]*mcp.ClientSession{serverName: session})
+}
+
+// detachDroppedSession forgets an unresponsive session, if it's still the server's, reporting whether it was
+func (m *Manager) detachDroppedSession(serverName string, session *mcp.ClientSession) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.sessions[serverName] != session {
+		return false
+	}
+	m.detachSessionLocked(serverName)
+	m.dropped[serverName] = true
+	m.recordError(serverName, fmt.Errorf("no response to %d keepalive pings", pingFailureThreshold), false)
+	if !m.quiet {
+		logging.Infof("Disconnected unresponsive server %s; it will be reconnected on next use", serverName)
+	}
+	return true
+}
+
+// beginCall marks a call to the server as in flight so it is not stopped for idleness
+func (m *Manager) beginCall(serverName string) {
+	m.usageMu.Lock()
+	defer m.usageMu.Unlock()
+	m.active[serverName]++
+}
+
+// endCall records the end of a call to the server
+func (m *Manager) endCall(serverName string) {
+	m.usageMu.Lock()
+	defer m.usageMu.Unlock()
+	m.active[serverName]--
+	m.lastUsed[serverName] = time.Now()
+}
+
+// Stop closes all connections and cleans up resources
+func (m *Manager) Stop() {
+	m.mu.Lock()
+
+	// Cancel the context to signal shutdown, abandoning calls in progress
+	m.cancel()
+
+	// Clear all state, then close the sessions without holding the lock, since servers can be slow to exit
+	sessions := m.sessions
 	m.clients = make(map[string]*mcp.Client)
 	m.sessions = make(map[string]*mcp.ClientSession)
 	m.tools = make(map[string][]*mcp.Tool)
+	m.mu.Unlock()
+
+	m.closeSessions(sessions)
 }
 
-// connectServer establishes a connection to a single upstream server
-func (m *Manager) connectServer(serverName string, serverConfig config.MCPServerConfig) error {
-	// Create the command
-	cmd := exec.CommandContext(m.ctx, serverConfig.Command, serverConfig.Args...)
-	
-	// Set environment variables
-	if len(serverConfig.Env) > 0 {
-		env := cmd.Environ()
-		for key, value := range serverConfig.Env {
-			env = append(env, fmt.Sprintf("%s=%s", key, value))
+// connectReleasingLock connects to a server without holding m.mu across the slow launch and
+// handshake, returning the connection along with the tools previously known for the server. The
+// caller must hold m.mu, which is released while dialing and held again on return; meanwhile the
+// server is marked as connecting, so other callers wait for the attempt rather than starting their own.
+// A session being replaced, which the caller has already removed from the manager, is closed first.
+func (m *Manager) connectReleasingLock(serverName string, serverConfig config.MCPServerConfig, replaced *mcp.ClientSession) (*serverConnection, []*mcp.Tool, error) {
+	done := make(chan struct{})
+	m.connecting[serverName] = done
+	m.mu.Unlock()
+
+	if replaced != nil {
+		if err := replaced.Close(); err != nil && !m.quiet {
+			logging.Warnf("Failed to close session for server %s: %v", serverName, err)
 		}
-		cmd.Env = env
 	}
+	conn, err := m.dialServer(serverName, serverConfig)
 
-	// Create MCP client
-	client := mcp.NewClient(&mcp.Implementation{
-		Name:    "mcp-metatool",
-		Version: "0.1.0",
-	}, nil)
+	m.mu.Lock()
+	delete(m.connecting, serverName)
+	close(done)
+	if err != nil {
+		return nil, nil, err
+	}
+	// The manager may have been stopped, or the server removed by a reload, while it connected
+	if _, configured := m.config.MCPServers[serverName]; !configured || m.ctx.Err() != nil {
+		if closeErr := conn.session.Close(); closeErr != nil && !m.quiet {
+			logging.Warnf("Failed to close session for server %s: %v", serverName, closeErr)
+		}
+		return nil, nil, fmt.Errorf("server %s was removed or stopped while connecting", serverName)
+	}
+	return conn, m.storeConnection(serverName, serverConfig, conn), nil
+}
+
+// serverConnection is an established session with an upstream server and the tools it offers
+type serverConnection struct {
+	client  *mcp.Client
+	session *mcp.ClientSession
+	tools   []*mcp.Tool // nil if discovery failed
+}
+
+// dialServer launches a server, connects to it and discovers its tools without touching manager state
+func (m *Manager) dialServer(serverName string, serverConfig config.MCPServerConfig) (*serverConnection, error) {
+	// Create the transport: a remote server's URL, or a command that launches the server
+	var transport mcp.Transport
+	if serverConfig.URL != "" {
+		logging.Debugf("Connecting to server %s at %s", serverName, serverConfig.URL)
+		transport = newHTTPTransport(serverName, serverConfig)
+	} else {
+		transport = m.newCommandTransport(serverName, serverConfig)
+	}
 
-	// Create transport and connect
-	transport := mcp.NewCommandTransport(cmd)
+	// Create MCP client and connect
+	client := m.newClient(serverName)
 	session, err := client.Connect(m.ctx, transport, &mcp.ClientSessionOptions{})
 	if err != nil {
-		return fmt.Errorf("failed to connect to server: %w", err)
+		err = fmt.Errorf("failed to connect to server: %w", err)
+		m.recordError(serverName, err, true)
+		return nil, err
+	}
+	if err := checkProtocolVersion(session, serverConfig); err != nil {
+		session.Close()
+		m.recordError(serverName, err, true)
+		return nil, err
 	}
-
-	// Store client and session
-	m.clients[serverName] = client
-	m.sessions[serverName] = session
 
 	// Discover tools
-	if err := m.discoverTools(serverName, session); err != nil {
+	tools, err := m.discoverTools(serverName, session)
+	if err != nil {
+		m.recordError(serverName, err, false)
 		if !m.quiet {
-			log.Printf("Warning: Failed to discover tools for server %s: %v", serverName, err)
+			logging.Warnf("Failed to discover tools for server %s: %v", serverName, err)
 		}
 		// Don't fail the connection for tool discovery issues
 	}
 
 	if !m.quiet {
-		log.Printf("Successfully connected to MCP server: %s", serverName)
+		logging.Infof("Successfully connected to MCP server: %s", serverName)
 	}
-	return nil
+	return &serverConnection{client: client, session: session, tools: tools}, nil
+}
+
+// newClient creates the client used to connect to an upstream server
+func (m *Manager) newClient(serverName string) *mcp.Client {
+	return mcp.NewClient(&mcp.Implementation{
+		Name:    "mcp-metatool",
+		Version: "0.1.0",
+	}, &mcp.ClientOptions{
+		ProgressNotificationHandler: m.progress.handle,
+		// Rediscover in the background, as the notification is handled on the session's read loop
+		ToolListChangedHandler: func(ctx context.Context, req *mcp.ToolListChangedRequest) {
+			go m.refreshTools(serverName, req.Session)
+		},
+	})
+}
+
+// refreshTools rediscovers the tools of a server that announced its tool list changed,
+// reporting any changes to the registered handler
+func (m *Manager) refreshTools(serverName string, session *mcp.ClientSession) {
+	m.refreshMu.Lock()
+	defer m.refreshMu.Unlock()
+
+	m.mu.RLock()
+	current := m.sessions[serverName] == session
+	m.mu.RUnlock()
+	if !current {
+		return
+	}
+
+	tools, err := m.discoverTools(serverName, session)
+	if err != nil {
+		if !m.quiet {
+			logging.Warnf("Failed to rediscover tools for server %s: %v", serverName, err)
+		}
+		return
+	}
+
+	m.mu.Lock()
+	if m.sessions[serverName] != session {
+		m.mu.Unlock()
+		return
+	}
+	previous := m.tools[serverName]
+	m.tools[serverName] = tools
+	if m.useCache && !toolsEqual(previous, tools) {
+		if err := saveCachedTools(serverName, m.config.MCPServers[serverName], tools); err != nil && !m.quiet {
+			logging.Warnf("Failed to cache tools for server %s: %v", serverName, err)
+		}
+	}
+	m.mu.Unlock()
+
+	m.notifyToolsChanged(serverName, previous, tools)
+}
+
+// storeConnection records a new connection and starts its keepalive, returning the tools
+// previously known for the server. The caller must hold m.mu.
+func (m *Manager) storeConnection(serverName string, serverConfig config.MCPServerConfig, conn *serverConnection) []*mcp.Tool {
+	previous := m.tools[serverName]
+	m.clients[serverName] = conn.client
+	m.sessions[serverName] = conn.session
+	m.nextInstance++
+	m.instances[serverName] = m.nextInstance
+	if conn.tools != nil {
+		m.tools[serverName] = conn.tools
+		if m.useCache && !toolsEqual(previous, conn.tools) {
+			if err := saveCachedTools(serverName, serverConfig, conn.tools); err != nil && !m.quiet {
+				logging.Warnf("Failed to cache tools for server %s: %v", serverName, err)
+			}
+		}
+	}
+	delete(m.dropped, serverName)
+	m.clearFailure(serverName)
+
+	// Servers with a keepalive interval are dropped when unresponsive; others are only health checked
+	if interval := serverConfig.PingIntervalDuration(); interval > 0 {
+		go m.keepAlive(serverName, conn.session, interval, true)
+	} else {
+		go m.keepAlive(serverName, conn.session, m.config.HealthCheckIntervalDuration(), false)
+	}
+
+	return previous
 }
 
 // discoverTools queries a server for its available tools
-func (m *Manager) discoverTools(serverName string, session *mcp.ClientSession) error {
+func (m *Manager) discoverTools(serverName string, session *mcp.ClientSession) ([]*mcp.Tool, error) {
 	// List tools from the upstream server
 	result, err := session.ListTools(m.ctx, &mcp.ListToolsParams{})
 	if err != nil {
-		return fmt.Errorf("failed to list tools: %w", err)
+		return nil, fmt.Errorf("failed to list tools: %w", err)
 	}
 
-	// Store the tools
-	m.tools[serverName] = result.Tools
-
 	if !m.quiet {
-		log.Printf("Discovered %d tools from server %s", len(result.Tools), serverName)
+		logging.Infof("Discovered %d tools from server %s", len(result.Tools), serverName)
 		for _, tool := range result.Tools {
-			log.Printf("  - %s: %s", tool.Name, tool.Description)
+			logging.Debugf("Discovered tool %s.%s: %s", serverName, tool.Name, tool.Description)
 		}
 	}
 
-	return nil
+	return result.Tools, nil
 }
 
 // GetAllTools returns all discovered tools from all servers
@@ -179,26 +648,304 @@ func (m *Manager) GetAllTools() map[string][]*mcp.Tool {
 
 // CallTool calls a tool on the specified upstream server
 func (m *Manager) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return m.CallToolContext(context.Background(), serverName, toolName, arguments)
+}
+
+// CallToolContext calls a tool on the specified upstream server, recording the call as a span within ctx
+func (m *Manager) CallToolContext(ctx context.Context, serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	ctx, span := tracing.Start(ctx, "proxy.call_tool", attribute.String("mcp.server", serverName), attribute.String("mcp.tool", toolName))
+	result, err := m.callTool(ctx, span, serverName, toolName, arguments)
+	if err == nil && result.IsError {
+		tracing.Fail(span, failureMessage(result, nil))
+	}
+	tracing.End(span, err)
+	return result, err
+}
+
+// callTool implements CallToolContext, noting cache hits and retries on the span.
+// The call is abandoned if ctx is cancelled, as when the client cancels its request.
+func (m *Manager) callTool(ctx context.Context, span trace.Span, serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	called := time.Now()
+	ctx, cancel := m.callContext(ctx)
+	defer cancel()
+
+	// Idempotent tools may be answered from recent results without reaching the server
+	m.mu.RLock()
+	cacheTTL := m.config.MCPServers[serverName].CacheTTL(toolName)
+	m.mu.RUnlock()
+	var cacheKey string
+	if cacheTTL > 0 {
+		var ok bool
+		if cacheKey, ok = resultCacheKey(serverName, toolName, arguments); !ok {
+			cacheTTL = 0
+		} else if result, hit := m.cachedCall(cacheKey); hit {
+			metrics.ResultCacheLookups.Inc(serverName, "hit")
+			metrics.ProxiedCalls.Inc(serverName, toolName, "cached")
+			span.SetAttributes(attribute.Bool("metatool.cache_hit", true))
+			usage.RecordProxied(serverName, toolName, time.Since(called), false)
+			history.RecordProxied(serverName, toolName, arguments, time.Since(called), "")
+			logging.Debugf("Answered %s__%s from the result cache", serverName, toolName)
+			return result, nil
+		} else {
+			metrics.ResultCacheLookups.Inc(serverName, "miss")
+		}
+	}
+
+	m.beginCall(serverName)
+	defer m.endCall(serverName)
+	m.waitForConnection(serverName)
+
 	m.mu.RLock()
 	session, exists := m.sessions[serverName]
 	m.mu.RUnlock()
 
 	if !exists {
-		return nil, fmt.Errorf("server %s not connected", serverName)
+		m.mu.RLock()
+		dropped := m.dropped[serverName]
+		serverConfig, configured := m.config.MCPServers[serverName]
+		m.mu.RUnlock()
+		if !configured || !(serverConfig.StartsOnDemand() || dropped) {
+			metrics.ProxiedCalls.Inc(serverName, toolName, "error")
+			return nil, fmt.Errorf("server %s not connected", serverName)
+		}
+		if _, err := m.EnsureStarted(serverName); err != nil {
+			metrics.ProxiedCalls.Inc(serverName, toolName, "error")
+			return nil, err
+		}
+		m.mu.RLock()
+		session = m.sessions[serverName]
+		m.mu.RUnlock()
 	}
 
-	// Call the tool
-	result, err := session.CallTool(m.ctx, &mcp.CallToolParams{
-		Name:      toolName,
-		Arguments: arguments,
-	})
+	// Call the tool, retrying transient failures if the server has a retry policy
+	m.mu.RLock()
+	serverConfig := m.config.MCPServers[serverName]
+	m.mu.RUnlock()
+
+	logging.Debugf("Calling %s__%s with arguments %v", serverName, toolName, argumentNames(arguments))
+	start := time.Now()
+	result, err := m.attemptCall(ctx, serverName, session, toolName, arguments, serverConfig.CorrectArguments)
+	retries := 0
+	for policy := serverConfig.Retry; retries+1 < policy.Attempts(); {
+		message := failureMessage(result, err)
+		if message == "" || !policy.Retryable(message) {
+			break
+		}
+		retries++
+		if !m.backOff(ctx, serverName, toolName, policy.Delay(retries), message) {
+			break
+		}
+		m.mu.RLock()
+		if current, connected := m.sessions[serverName]; connected {
+			session = current
+		}
+		m.mu.RUnlock()
+		result, err = m.attemptCall(ctx, serverName, session, toolName, arguments, serverConfig.CorrectArguments)
+	}
+
+	// A call the caller cancelled says nothing about the server's health
+	if ctx.Err() == nil {
+		m.recordCall(serverName, err)
+	}
+	translateContent(result, serverConfig.TextChunkSize)
+	span.SetAttributes(attribute.Int("metatool.retries", retries))
+	metrics.ProxiedCallDuration.Observe(time.Since(start).Seconds(), serverName, toolName)
+	usage.RecordProxied(serverName, toolName, time.Since(called), failureMessage(result, err) != "")
+	history.RecordProxied(serverName, toolName, arguments, time.Since(called), failureMessage(result, err))
+	if failureMessage(result, err) != "" {
+		metrics.ProxiedCalls.Inc(serverName, toolName, "error")
+	} else {
+		metrics.ProxiedCalls.Inc(serverName, toolName, "success")
+	}
+	logging.Debugf("Call to %s__%s finished in %v after %d retries: %s", serverName, toolName, time.Since(start).Round(time.Millisecond), retries, outcome(result, err))
 	if err != nil {
+		if retries > 0 {
+			return nil, fmt.Errorf("tool call failed after %d attempts: %w", retries+1, err)
+		}
 		return nil, fmt.Errorf("tool call failed: %w", err)
 	}
+	if cacheTTL > 0 {
+		m.cacheResult(cacheKey, result, cacheTTL)
+	}
+	if retries > 0 {
+		if result.Meta == nil {
+			result.Meta = mcp.Meta{}
+		}
+		result.Meta[RetriesMetaKey] = retries
+	}
 
 	return result, nil
 }
 
+// callContext returns a context for an upstream call that ends when either ctx or the manager does
+func (m *Manager) callContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	callCtx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(m.ctx, cancel)
+	return callCtx, func() {
+		stop()
+		cancel()
+	}
+}
+
+// attemptCall makes one call to an upstream tool, retrying it once with corrected arguments
+// if correct is set and the server rejects the arguments as invalid. Progress notifications
+// are forwarded if ctx asks for them.
+func (m *Manager) attemptCall(ctx context.Context, serverName string, session *mcp.ClientSession, toolName string, arguments map[string]interface{}, correct bool) (*mcp.CallToolResult, error) {
+	params, release := m.callParams(ctx, toolName, arguments)
+	result, err := session.CallTool(ctx, params)
+	release()
+	if correct && isValidationFailure(result, err) {
+		if corrected, ok := m.correctArguments(serverName, toolName, arguments); ok {
+			params, release := m.callParams(ctx, toolName, corrected)
+			retryResult, retryErr := session.CallTool(ctx, params)
+			release()
+			if retryErr == nil && !retryResult.IsError {
+				return retryResult, nil
+			}
+		}
+	}
+	return result, err
+}
+
+// ToolPrefix returns the configured prefix of a server's proxied tool names
+func (m *Manager) ToolPrefix(serverName string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config.ToolPrefix(serverName)
+}
+
+// ToolAliases returns the configured aliases of a server's tools
+func (m *Manager) ToolAliases(serverName string) map[string]string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.config.MCPServers[serverName].ToolAliases
+}
+
+// PendingServers returns the lazily started servers that have not been launched yet
+func (m *Manager) PendingServers() []string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var pending []string
+	for serverName, serverConfig := range m.config.MCPServers {
+		if _, connected := m.sessions[serverName]; serverConfig.LazyStart && !connected {
+			pending = append(pending, serverName)
+		}
+	}
+	sort.Strings(pending)
+	return pending
+}
+
+// EnsureStarted connects to a lazily started or idle-stopped server and returns its tools
+func (m *Manager) EnsureStarted(serverName string) ([]*mcp.Tool, error) {
+	m.mu.Lock()
+	m.awaitConnectionLocked(serverName)
+
+	var conn *serverConnection
+	var previous []*mcp.Tool
+	if _, connected := m.sessions[serverName]; !connected {
+		serverConfig, exists := m.config.MCPServers[serverName]
+		if !exists {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("server %s not configured", serverName)
+		}
+		if m.ctx.Err() != nil {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("server %s not connected: proxy manager stopped", serverName)
+		}
+		if !m.quiet {
+			logging.Infof("Starting server %s on demand", serverName)
+		}
+		var err error
+		if conn, previous, err = m.connectReleasingLock(serverName, serverConfig, nil); err != nil {
+			m.mu.Unlock()
+			return nil, fmt.Errorf("failed to start server %s: %w", serverName, err)
+		}
+	}
+
+	tools := make([]*mcp.Tool, len(m.tools[serverName]))
+	copy(tools, m.tools[serverName])
+	m.mu.Unlock()
+
+	if conn != nil {
+		m.notifyToolsChanged(serverName, previous, conn.tools)
+	}
+	return tools, nil
+}
+
+// RestartServer closes a server's session, relaunches it and rediscovers its tools,
+// reporting changed tools to the registered handler. Calls in progress on the old session fail.
+func (m *Manager) RestartServer(serverName string) ([]*mcp.Tool, error) {
+	m.mu.Lock()
+	m.awaitConnectionLocked(serverName)
+
+	serverConfig, exists := m.config.MCPServers[serverName]
+	if !exists {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("server %s not configured", serverName)
+	}
+	if m.ctx.Err() != nil {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("server %s not restarted: proxy manager stopped", serverName)
+	}
+	session, connected := m.sessions[serverName]
+	if connected {
+		delete(m.sessions, serverName)
+		delete(m.clients, serverName)
+	}
+	if !m.quiet {
+		logging.Infof("Restarting server %s", serverName)
+	}
+	conn, previous, err := m.connectReleasingLock(serverName, serverConfig, session)
+	if err != nil {
+		// Let the next call try again
+		m.dropped[serverName] = true
+		m.mu.Unlock()
+		return nil, fmt.Errorf("failed to restart server %s: %w", serverName, err)
+	}
+
+	tools := make([]*mcp.Tool, len(m.tools[serverName]))
+	copy(tools, m.tools[serverName])
+	m.mu.Unlock()
+
+	m.notifyToolsChanged(serverName, previous, conn.tools)
+	return tools, nil
+}
+
+// awaitConnectionLocked waits for any attempt to connect the server that is in progress to finish,
+// releasing m.mu while it waits. The caller must hold m.mu.
+func (m *Manager) awaitConnectionLocked(serverName string) {
+	for {
+		done, connecting := m.connecting[serverName]
+		if !connecting {
+			return
+		}
+		m.mu.Unlock()
+		select {
+		case <-done:
+		case <-m.ctx.Done():
+			m.mu.Lock()
+			return
+		}
+		m.mu.Lock()
+	}
+}
+
+// waitForConnection blocks while the server is being connected in the background
+func (m *Manager) waitForConnection(serverName string) {
+	m.mu.RLock()
+	done, connecting := m.connecting[serverName]
+	m.mu.RUnlock()
+	if !connecting {
+		return
+	}
+
+	select {
+	case <-done:
+	case <-m.ctx.Done():
+	}
+}
+
 // GetConnectedServers returns the names of all connected servers
 func (m *Manager) GetConnectedServers() []string {
 	m.mu.RLock()
@@ -210,4 +957,22 @@ func (m *Manager) GetConnectedServers() []string {
 	}
 
 	return servers
-}
\ No newline at end of file
+}
+
+// outcome summarizes how a call ended for debug logging
+func outcome(result *mcp.CallToolResult, err error) string {
+	if message := failureMessage(result, err); message != "" {
+		return "failed: " + message
+	}
+	return "succeeded"
+}
+
+// argumentNames lists the names of a call's arguments, leaving out values that may be sensitive
+func argumentNames(arguments map[string]interface{}) []string {
+	names := make([]string, 0, len(arguments))
+	for name := range arguments {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
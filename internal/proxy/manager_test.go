@@ -1,7 +1,14 @@
 package proxy
 
 import (
+	"context"
+	"path/filepath"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/dslh/mcp-metatool/internal/config"
 )
@@ -162,4 +169,535 @@ func TestMultipleOptions(t *testing.T) {
 		t.Error("Options not applied correctly")
 	}
 	manager.Stop()
-}
\ No newline at end of file
+}
+func TestManagerLazyStart(t *testing.T) {
+	cfg := &config.Config{
+		MCPServers: map[string]config.MCPServerConfig{
+			"lazy": {
+				Command:   "false", // exits immediately, so starting always fails
+				LazyStart: true,
+			},
+		},
+	}
+
+	manager := NewManager(cfg, WithQuietMode())
+	defer manager.Stop()
+
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if pending := manager.PendingServers(); len(pending) != 1 || pending[0] != "lazy" {
+		t.Errorf("PendingServers() = %v, want [lazy]", pending)
+	}
+
+	// The first call attempts to start the server
+	_, err := manager.CallTool("lazy", "anything", nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to start server lazy") {
+		t.Errorf("CallTool() error = %v, want start failure", err)
+	}
+
+	// A failed start leaves the server pending so a later call can retry
+	if pending := manager.PendingServers(); len(pending) != 1 {
+		t.Errorf("PendingServers() after failed start = %v, want [lazy]", pending)
+	}
+
+	if _, err := manager.EnsureStarted("unknown"); err == nil {
+		t.Error("EnsureStarted() should fail for an unconfigured server")
+	}
+}
+
+func TestManagerEnsureStartedReleasesLock(t *testing.T) {
+	slow := testServerConfig("alpha")
+	slow.Env[testServerDelayEnv] = "500ms"
+	slow.LazyStart = true
+	manager := NewManager(&config.Config{MCPServers: map[string]config.MCPServerConfig{"slow": slow}}, WithQuietMode())
+	defer manager.Stop()
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	// Concurrent first calls share one connection attempt
+	var wg sync.WaitGroup
+	errs := make(chan error, 2)
+	for range 2 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := manager.EnsureStarted("slow")
+			errs <- err
+		}()
+	}
+
+	waitForConnecting(t, manager, "slow")
+
+	// The manager stays usable while the slow server starts
+	start := time.Now()
+	manager.PendingServers()
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("PendingServers() blocked for %v while a server was starting", elapsed)
+	}
+
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Errorf("EnsureStarted() error = %v", err)
+		}
+	}
+	if instance, _ := manager.InstanceID("slow"); instance != 1 {
+		t.Errorf("Expected one connection to be made, got instance %d", instance)
+	}
+}
+
+func TestManagerRestartServerReleasesLock(t *testing.T) {
+	slow := testServerConfig("alpha")
+	slow.Env[testServerDelayEnv] = "500ms"
+	manager := NewManager(&config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"slow": slow,
+		"fast": testServerConfig("alpha"),
+	}}, WithQuietMode())
+	defer manager.Stop()
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	restarted := make(chan error, 1)
+	go func() {
+		_, err := manager.RestartServer("slow")
+		restarted <- err
+	}()
+	waitForConnecting(t, manager, "slow")
+
+	// Other servers stay usable while the slow one restarts
+	start := time.Now()
+	if _, err := manager.CallTool("fast", "alpha", nil); err != nil {
+		t.Errorf("CallTool() error = %v", err)
+	}
+	manager.GetConnectedServers()
+	if elapsed := time.Since(start); elapsed > 250*time.Millisecond {
+		t.Errorf("Other servers blocked for %v while a server restarted", elapsed)
+	}
+
+	if err := <-restarted; err != nil {
+		t.Errorf("RestartServer() error = %v", err)
+	}
+	if instance, _ := manager.InstanceID("slow"); instance != 3 {
+		t.Errorf("Expected the restart to make a new connection, got instance %d", instance)
+	}
+}
+
+// slowExit is how long servers from slowToExitConfig take to exit
+const slowExit = time.Second
+
+// slowToExitConfig configures a test server that takes slowExit to exit once it's closed
+func slowToExitConfig(toolNames string) config.MCPServerConfig {
+	serverConfig := testServerConfig(toolNames)
+	serverConfig.Env[testServerLingerEnv] = slowExit.String()
+	return serverConfig
+}
+
+// assertUsableWhileClosing checks that the "fast" server stays usable while closeServer closes a
+// server from slowToExitConfig, rather than the manager being locked until it exits
+func assertUsableWhileClosing(t *testing.T, m *Manager, closeServer func()) {
+	t.Helper()
+	start := time.Now()
+	closed := make(chan struct{})
+	go func() {
+		closeServer()
+		close(closed)
+	}()
+	time.Sleep(100 * time.Millisecond)
+
+	callStart := time.Now()
+	if _, err := m.CallTool("fast", "alpha", nil); err != nil {
+		t.Errorf("CallTool() error = %v", err)
+	}
+	m.GetConnectedServers()
+	if elapsed := time.Since(callStart); elapsed > 250*time.Millisecond {
+		t.Errorf("Other servers blocked for %v while a server was closed", elapsed)
+	}
+
+	<-closed
+	if elapsed := time.Since(start); elapsed < slowExit/2 {
+		t.Errorf("Closing took %v, too quick for the server to have been waited for", elapsed)
+	}
+}
+
+// waitForConnecting waits until the manager is connecting to the server
+func waitForConnecting(t *testing.T, m *Manager, serverName string) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		m.mu.RLock()
+		_, connecting := m.connecting[serverName]
+		m.mu.RUnlock()
+		if connecting {
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("Timed out waiting for the server to start connecting")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// connectInMemory attaches an in-memory upstream server to the manager under the given name
+// and returns the upstream's side of the connection
+func connectInMemory(t *testing.T, m *Manager, serverName string) *mcp.ServerSession {
+	t.Helper()
+	upstream := mcp.NewServer(&mcp.Implementation{Name: serverName, Version: "1.0.0"}, nil)
+	mcp.AddTool(upstream, &mcp.Tool{Name: "ping"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "pong"}}}, nil, nil
+	})
+	return attachUpstream(t, m, serverName, upstream)
+}
+
+// attachUpstream connects the manager to an in-memory upstream server under the given name
+func attachUpstream(t *testing.T, m *Manager, serverName string, upstream *mcp.Server) *mcp.ServerSession {
+	t.Helper()
+	ctx := context.Background()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := upstream.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect upstream: %v", err)
+	}
+	client := m.newClient(serverName)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.clients[serverName] = client
+	m.sessions[serverName] = session
+	tools, err := m.discoverTools(serverName, session)
+	if err != nil {
+		t.Fatalf("discoverTools() error = %v", err)
+	}
+	m.tools[serverName] = tools
+	return serverSession
+}
+
+func TestManagerIdleShutdownReleasesLock(t *testing.T) {
+	slow := slowToExitConfig("alpha")
+	slow.IdleTimeout = "1m"
+	manager := NewManager(&config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"slow": slow,
+		"fast": testServerConfig("alpha"),
+	}}, WithQuietMode())
+	defer manager.Stop()
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if _, err := manager.CallTool("slow", "alpha", nil); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+
+	assertUsableWhileClosing(t, manager, func() { manager.stopIdleServers(time.Now().Add(2 * time.Minute)) })
+	if connected := manager.GetConnectedServers(); len(connected) != 1 || connected[0] != "fast" {
+		t.Errorf("Expected only fast connected, got %v", connected)
+	}
+}
+
+func TestManagerDropSessionReleasesLock(t *testing.T) {
+	manager := NewManager(&config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"slow": slowToExitConfig("alpha"),
+		"fast": testServerConfig("alpha"),
+	}}, WithQuietMode())
+	defer manager.Stop()
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	manager.mu.RLock()
+	session := manager.sessions["slow"]
+	manager.mu.RUnlock()
+
+	assertUsableWhileClosing(t, manager, func() { manager.dropSession("slow", session) })
+	if connected := manager.GetConnectedServers(); len(connected) != 1 || connected[0] != "fast" {
+		t.Errorf("Expected only fast connected, got %v", connected)
+	}
+}
+
+func TestManagerReconcileReleasesLock(t *testing.T) {
+	fast := testServerConfig("alpha")
+	manager := NewManager(&config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"slow": slowToExitConfig("alpha"),
+		"fast": fast,
+	}}, WithQuietMode())
+	defer manager.Stop()
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	var summary *ReloadSummary
+	assertUsableWhileClosing(t, manager, func() {
+		summary = manager.Reconcile(&config.Config{MCPServers: map[string]config.MCPServerConfig{"fast": fast}})
+	})
+	if len(summary.Removed) != 1 || summary.Removed[0] != "slow" {
+		t.Errorf("Expected slow to be removed, got %+v", summary)
+	}
+}
+
+func TestManagerIdleShutdown(t *testing.T) {
+	cfg := &config.Config{
+		MCPServers: map[string]config.MCPServerConfig{
+			"idle":   {Command: "false", IdleTimeout: "1m"},
+			"steady": {Command: "false"},
+		},
+	}
+
+	manager := NewManager(cfg, WithQuietMode())
+	defer manager.Stop()
+	connectInMemory(t, manager, "idle")
+	connectInMemory(t, manager, "steady")
+
+	if _, err := manager.CallTool("idle", "ping", nil); err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+
+	// Within the timeout nothing is stopped
+	manager.stopIdleServers(time.Now().Add(30 * time.Second))
+	if len(manager.GetConnectedServers()) != 2 {
+		t.Fatalf("Expected both servers connected, got %v", manager.GetConnectedServers())
+	}
+
+	// Past the timeout only the server with an idle timeout is stopped, keeping its tools
+	manager.stopIdleServers(time.Now().Add(2 * time.Minute))
+	if connected := manager.GetConnectedServers(); len(connected) != 1 || connected[0] != "steady" {
+		t.Errorf("Expected only steady connected, got %v", connected)
+	}
+	if tools := manager.GetAllTools()["idle"]; len(tools) != 1 {
+		t.Errorf("Expected idle server tools to be kept, got %v", tools)
+	}
+
+	// The next call restarts the server from its config
+	_, err := manager.CallTool("idle", "ping", nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to start server idle") {
+		t.Errorf("CallTool() error = %v, want restart attempt", err)
+	}
+}
+
+func TestManagerIdleCheckInterval(t *testing.T) {
+	tests := []struct {
+		name     string
+		servers  map[string]config.MCPServerConfig
+		expected time.Duration
+	}{
+		{"no timeouts", map[string]config.MCPServerConfig{"a": {Command: "x"}}, 0},
+		{"half the shortest", map[string]config.MCPServerConfig{"a": {Command: "x", IdleTimeout: "10m"}, "b": {Command: "x", IdleTimeout: "4m"}}, 2 * time.Minute},
+		{"at least a second", map[string]config.MCPServerConfig{"a": {Command: "x", IdleTimeout: "1s"}}, time.Second},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := NewManager(&config.Config{MCPServers: tt.servers})
+			defer manager.Stop()
+			if got := manager.idleCheckInterval(); got != tt.expected {
+				t.Errorf("idleCheckInterval() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestManagerKeepAliveDropsUnresponsiveServer(t *testing.T) {
+	cfg := &config.Config{
+		MCPServers: map[string]config.MCPServerConfig{
+			"flaky": {Command: "false", PingInterval: "10ms"},
+		},
+	}
+
+	manager := NewManager(cfg, WithQuietMode())
+	defer manager.Stop()
+	upstream := connectInMemory(t, manager, "flaky")
+
+	manager.mu.RLock()
+	session := manager.sessions["flaky"]
+	manager.mu.RUnlock()
+	go manager.keepAlive("flaky", session, 10*time.Millisecond, true)
+
+	// Healthy pings keep the session
+	time.Sleep(50 * time.Millisecond)
+	if len(manager.GetConnectedServers()) != 1 {
+		t.Fatal("Expected responsive server to stay connected")
+	}
+
+	// Once the upstream goes away the session is dropped
+	upstream.Close()
+	deadline := time.Now().Add(2 * time.Second)
+	for len(manager.GetConnectedServers()) > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if len(manager.GetConnectedServers()) != 0 {
+		t.Fatal("Expected unresponsive server to be disconnected")
+	}
+	if tools := manager.GetAllTools()["flaky"]; len(tools) != 1 {
+		t.Errorf("Expected dropped server tools to be kept, got %v", tools)
+	}
+
+	// The next call tries to reconnect rather than reporting it unconnected
+	_, err := manager.CallTool("flaky", "ping", nil)
+	if err == nil || !strings.Contains(err.Error(), "failed to start server flaky") {
+		t.Errorf("CallTool() error = %v, want reconnect attempt", err)
+	}
+}
+
+func TestManagerStartConnectsConcurrently(t *testing.T) {
+	// Each server takes a while to fail, so connecting one at a time would be slow
+	servers := make(map[string]config.MCPServerConfig)
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		servers[name] = config.MCPServerConfig{Command: "sh", Args: []string{"-c", "sleep 0.3; exit 1"}}
+	}
+
+	manager := NewManager(&config.Config{MCPServers: servers}, WithQuietMode())
+	defer manager.Stop()
+
+	start := time.Now()
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 1200*time.Millisecond {
+		t.Errorf("Start() took %v; servers were not connected concurrently", elapsed)
+	}
+	if connected := manager.GetConnectedServers(); len(connected) != 0 {
+		t.Errorf("Expected no connected servers, got %v", connected)
+	}
+}
+
+func TestManagerRestartServer(t *testing.T) {
+	var changedTo []*mcp.Tool
+	manager := NewManager(&config.Config{MCPServers: map[string]config.MCPServerConfig{"upstream": testServerConfig("alpha")}},
+		WithQuietMode(), WithToolsChangedHandler(func(serverName string, previous, current []*mcp.Tool) {
+			changedTo = current
+		}))
+	defer manager.Stop()
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	manager.mu.Lock()
+	before := manager.sessions["upstream"]
+	// The relaunched server offers another tool
+	manager.config.MCPServers["upstream"] = testServerConfig("alpha,beta")
+	manager.mu.Unlock()
+
+	tools, err := manager.RestartServer("upstream")
+	if err != nil {
+		t.Fatalf("RestartServer() error = %v", err)
+	}
+	if len(tools) != 2 || len(changedTo) != 2 {
+		t.Errorf("Expected rediscovered tools to be returned and reported, got %v and %v", tools, changedTo)
+	}
+	manager.mu.RLock()
+	after := manager.sessions["upstream"]
+	manager.mu.RUnlock()
+	if after == nil || after == before {
+		t.Error("Expected a new session after restart")
+	}
+	if _, err := manager.CallTool("upstream", "beta", nil); err != nil {
+		t.Errorf("CallTool() after restart error = %v", err)
+	}
+
+	if _, err := manager.RestartServer("unknown"); err == nil || !strings.Contains(err.Error(), "not configured") {
+		t.Errorf("RestartServer(unknown) error = %v, want not configured", err)
+	}
+
+	// A server that fails to come back is retried on the next call
+	manager.mu.Lock()
+	manager.config.MCPServers["upstream"] = config.MCPServerConfig{Command: "false"}
+	manager.mu.Unlock()
+	if _, err := manager.RestartServer("upstream"); err == nil || !strings.Contains(err.Error(), "failed to restart server upstream") {
+		t.Errorf("RestartServer() error = %v, want restart failure", err)
+	}
+	if len(manager.GetConnectedServers()) != 0 {
+		t.Error("Expected failed server to be disconnected")
+	}
+	if _, err := manager.CallTool("upstream", "alpha", nil); err == nil || !strings.Contains(err.Error(), "failed to start server upstream") {
+		t.Errorf("CallTool() error = %v, want reconnect attempt", err)
+	}
+}
+
+func TestManagerToolListChanged(t *testing.T) {
+	changed := make(chan []*mcp.Tool, 1)
+	manager := NewManager(&config.Config{MCPServers: map[string]config.MCPServerConfig{"upstream": {Command: "false"}}},
+		WithQuietMode(), WithToolsChangedHandler(func(serverName string, previous, current []*mcp.Tool) {
+			changed <- current
+		}))
+	defer manager.Stop()
+
+	upstream := mcp.NewServer(&mcp.Implementation{Name: "upstream", Version: "1.0.0"}, nil)
+	handler := func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	mcp.AddTool(upstream, &mcp.Tool{Name: "alpha"}, handler)
+	attachUpstream(t, manager, "upstream", upstream)
+
+	// Adding a tool notifies connected clients that the tool list changed
+	mcp.AddTool(upstream, &mcp.Tool{Name: "beta"}, handler)
+	select {
+	case current := <-changed:
+		if len(current) != 2 {
+			t.Errorf("Expected both tools to be reported, got %v", current)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Expected the tool list change to be reported")
+	}
+	if tools := manager.GetAllTools()["upstream"]; len(tools) != 2 {
+		t.Errorf("Expected GetAllTools() to include the new tool, got %v", tools)
+	}
+}
+
+func TestManagerServerEnvironment(t *testing.T) {
+	t.Setenv("PROXY_TEST_SECRET", "secret")
+	t.Setenv("PROXY_TEST_PASSED", "passed")
+	isolate := false
+	script := `echo "secret=$PROXY_TEST_SECRET passed=$PROXY_TEST_PASSED set=$PROXY_TEST_SET" >&2; exit 1`
+
+	tests := []struct {
+		name   string
+		config config.MCPServerConfig
+		want   string
+	}{
+		{"inherited", config.MCPServerConfig{}, "secret=secret passed=passed set="},
+		{"isolated", config.MCPServerConfig{InheritEnv: &isolate, PassEnv: []string{"PATH", "PROXY_TEST_PASSED"}}, "secret= passed=passed set="},
+		{"isolated with env", config.MCPServerConfig{InheritEnv: &isolate, Env: map[string]string{"PROXY_TEST_SET": "set"}}, "secret= passed= set=set"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverConfig := tt.config
+			serverConfig.Command, serverConfig.Args = "/bin/sh", []string{"-c", script}
+			m := NewManager(&config.Config{MCPServers: map[string]config.MCPServerConfig{"env": serverConfig}}, WithQuietMode())
+			defer m.Stop()
+			m.Start()
+
+			// The server reports its environment on stderr before exiting
+			deadline := time.Now().Add(5 * time.Second)
+			for len(m.ServerStderr("env")) == 0 && time.Now().Before(deadline) {
+				time.Sleep(10 * time.Millisecond)
+			}
+			if got := m.ServerStderr("env"); len(got) != 1 || got[0] != tt.want {
+				t.Errorf("Server saw environment %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestManagerServerCwd(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	serverConfig := config.MCPServerConfig{Command: "/bin/sh", Args: []string{"-c", "pwd >&2; exit 1"}, Cwd: dir}
+	m := NewManager(&config.Config{MCPServers: map[string]config.MCPServerConfig{"cwd": serverConfig}}, WithQuietMode())
+	defer m.Stop()
+	m.Start()
+
+	deadline := time.Now().Add(5 * time.Second)
+	for len(m.ServerStderr("cwd")) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := m.ServerStderr("cwd"); len(got) != 1 || got[0] != dir {
+		t.Errorf("Server ran in %q, want %q", got, dir)
+	}
+}
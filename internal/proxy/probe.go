@@ -0,0 +1,34 @@
+package proxy
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+// Probe launches a single server, lists its tools and shuts it down again,
+// giving up once timeout has elapsed
+func Probe(serverName string, serverConfig config.MCPServerConfig, timeout time.Duration) ([]*mcp.Tool, error) {
+	m := NewManager(&config.Config{MCPServers: map[string]config.MCPServerConfig{serverName: serverConfig}}, WithQuietMode())
+	defer m.Stop()
+
+	timer := time.AfterFunc(timeout, m.cancel)
+	defer timer.Stop()
+
+	conn, err := m.dialServer(serverName, serverConfig)
+	if err != nil {
+		if m.ctx.Err() != nil {
+			return nil, fmt.Errorf("no response within %s", timeout)
+		}
+		return nil, err
+	}
+	defer conn.session.Close()
+
+	if conn.tools != nil {
+		return conn.tools, nil
+	}
+	return m.discoverTools(serverName, conn.session)
+}
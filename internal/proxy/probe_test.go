@@ -0,0 +1,26 @@
+package proxy
+
+import (
+	"testing"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+func TestProbe(t *testing.T) {
+	tools, err := Probe("test", testServerConfig("alpha,beta"), 10*time.Second)
+	if err != nil {
+		t.Fatalf("Probe() error = %v", err)
+	}
+	if len(tools) != 2 {
+		t.Errorf("Expected 2 tools, got %d", len(tools))
+	}
+
+	if _, err := Probe("broken", config.MCPServerConfig{Command: "sh", Args: []string{"-c", "exit 1"}}, 10*time.Second); err == nil {
+		t.Error("Expected error probing a server that exits immediately")
+	}
+
+	if _, err := Probe("slow", config.MCPServerConfig{Command: "sleep", Args: []string{"5"}}, 100*time.Millisecond); err == nil {
+		t.Error("Expected error probing a server that never responds")
+	}
+}
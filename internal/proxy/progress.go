@@ -0,0 +1,95 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/logging"
+)
+
+// ProgressFunc receives the progress notifications an upstream server sends while handling a call
+type ProgressFunc func(params *mcp.ProgressNotificationParams)
+
+// progressDrain is how long a call's progress token stays registered after the call returns. The
+// SDK handles notifications concurrently with responses, so progress an upstream server sent before
+// its response may only be handled after the call has returned.
+const progressDrain = time.Second
+
+// progressKey is the context key under which a call's ProgressFunc is stored
+type progressKey struct{}
+
+// WithProgress returns a context in which upstream calls ask their server for progress notifications,
+// passing each one to fn. A nil fn leaves ctx unchanged.
+func WithProgress(ctx context.Context, fn ProgressFunc) context.Context {
+	if fn == nil {
+		return ctx
+	}
+	return context.WithValue(ctx, progressKey{}, fn)
+}
+
+// ProgressFromContext returns the function progress notifications are passed to within ctx, if any
+func ProgressFromContext(ctx context.Context) ProgressFunc {
+	fn, _ := ctx.Value(progressKey{}).(ProgressFunc)
+	return fn
+}
+
+// progressRelay routes progress notifications from upstream servers to the calls that asked for them,
+// by giving each call its own progress token. The zero value is ready to use.
+type progressRelay struct {
+	mu    sync.Mutex
+	next  uint64
+	calls map[string]ProgressFunc
+}
+
+// register allocates a progress token for a call, returning a function that releases it
+func (r *progressRelay) register(fn ProgressFunc) (string, func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.calls == nil {
+		r.calls = make(map[string]ProgressFunc)
+	}
+	r.next++
+	token := fmt.Sprintf("mcp-metatool-%d", r.next)
+	r.calls[token] = fn
+	return token, func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		delete(r.calls, token)
+	}
+}
+
+// handle passes a progress notification from an upstream server to the call it belongs to,
+// dropping notifications for calls that have already finished
+func (r *progressRelay) handle(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+	token, ok := req.Params.ProgressToken.(string)
+	if !ok {
+		return
+	}
+	r.mu.Lock()
+	fn := r.calls[token]
+	r.mu.Unlock()
+	if fn == nil {
+		logging.Debugf("Dropped progress notification for finished call %s", token)
+		return
+	}
+	fn(req.Params)
+}
+
+// callParams builds the parameters for an upstream call, with a progress token if ctx forwards
+// progress. The returned function must be called once the call has finished, and releases the
+// token once notifications sent during the call have had time to arrive.
+func (m *Manager) callParams(ctx context.Context, toolName string, arguments map[string]interface{}) (*mcp.CallToolParams, func()) {
+	params := &mcp.CallToolParams{Name: toolName, Arguments: arguments}
+	fn := ProgressFromContext(ctx)
+	if fn == nil {
+		return params, func() {}
+	}
+	token, release := m.progress.register(fn)
+	params.Meta = mcp.Meta{} // SetProgressToken only sets an existing _meta
+	params.SetProgressToken(token)
+	return params, func() { time.AfterFunc(progressDrain, release) }
+}
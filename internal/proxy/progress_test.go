@@ -0,0 +1,114 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+func TestManagerForwardsProgress(t *testing.T) {
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{"slow": {Command: "false"}}}
+	manager := NewManager(cfg, WithQuietMode())
+	defer manager.Stop()
+
+	upstream := mcp.NewServer(&mcp.Implementation{Name: "slow", Version: "1.0.0"}, nil)
+	mcp.AddTool(upstream, &mcp.Tool{Name: "build"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		token := req.Params.GetProgressToken()
+		if token == nil {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "no token"}}}, nil, nil
+		}
+		for i, message := range []string{"compiling", "linking"} {
+			if err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{ProgressToken: token, Message: message, Progress: float64(i + 1), Total: 2}); err != nil {
+				return nil, nil, err
+			}
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "built"}}}, nil, nil
+	})
+	attachUpstream(t, manager, "slow", upstream)
+
+	tests := []struct {
+		name    string
+		forward bool
+		want    []string
+	}{
+		{"forwarded", true, []string{"compiling 1/2", "linking 2/2"}},
+		{"not requested", false, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			received := make(chan string, 10)
+			ctx := context.Background()
+			if tt.forward {
+				ctx = WithProgress(ctx, func(params *mcp.ProgressNotificationParams) {
+					received <- fmt.Sprintf("%s %v/%v", params.Message, params.Progress, params.Total)
+				})
+			}
+			if _, err := manager.CallToolContext(ctx, "slow", "build", nil); err != nil {
+				t.Fatalf("CallToolContext() error = %v", err)
+			}
+
+			// Notifications are handled concurrently with the call's response, so may arrive after it
+			var got []string
+			for len(got) < len(tt.want) {
+				select {
+				case message := <-received:
+					got = append(got, message)
+				case <-time.After(5 * time.Second):
+					t.Fatalf("Got progress %v, want %v", got, tt.want)
+				}
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Got progress %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestProgressRelay(t *testing.T) {
+	var relay progressRelay
+	var got []string
+	token, release := relay.register(func(params *mcp.ProgressNotificationParams) {
+		got = append(got, params.Message)
+	})
+	other, releaseOther := relay.register(func(*mcp.ProgressNotificationParams) {
+		t.Error("notification delivered to the wrong call")
+	})
+	defer releaseOther()
+	if token == other {
+		t.Fatalf("Calls were given the same token %q", token)
+	}
+
+	notify := func(token any, message string) {
+		relay.handle(context.Background(), &mcp.ProgressNotificationClientRequest{Params: &mcp.ProgressNotificationParams{ProgressToken: token, Message: message}})
+	}
+	notify(token, "first")
+	notify("unknown", "stray")
+	notify(42, "not a string")
+	release()
+	notify(token, "after release")
+
+	if fmt.Sprint(got) != "[first]" {
+		t.Errorf("Got notifications %v, want [first]", got)
+	}
+}
+
+func TestCallParamsDrainsProgress(t *testing.T) {
+	var m Manager
+	var got []string
+	ctx := WithProgress(context.Background(), func(params *mcp.ProgressNotificationParams) {
+		got = append(got, params.Message)
+	})
+	params, release := m.callParams(ctx, "build", nil)
+	release()
+
+	// Notifications sent before the response are still delivered if handled after the call returns
+	m.progress.handle(context.Background(), &mcp.ProgressNotificationClientRequest{Params: &mcp.ProgressNotificationParams{ProgressToken: params.GetProgressToken(), Message: "late"}})
+	if fmt.Sprint(got) != "[late]" {
+		t.Errorf("Got notifications %v, want [late]", got)
+	}
+}
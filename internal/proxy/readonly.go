@@ -0,0 +1,124 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// IsReadOnlyTool reports whether a tool's annotations declare that it doesn't modify its environment
+func IsReadOnlyTool(tool *mcp.Tool) bool {
+	return tool != nil && tool.Annotations != nil && tool.Annotations.ReadOnlyHint
+}
+
+// readOnlyProxy exposes only the tools of a proxy manager that are annotated as read-only
+type readOnlyProxy struct {
+	proxyManager ProxyManager
+}
+
+// ReadOnly wraps a proxy manager so only tools annotated as read-only are listed or can be called.
+// Tools without annotations are assumed to have side effects.
+func ReadOnly(pm ProxyManager) ProxyManager {
+	return &readOnlyProxy{proxyManager: pm}
+}
+
+// readOnlyTools returns the read-only tools among a server's tools
+func readOnlyTools(tools []*mcp.Tool) []*mcp.Tool {
+	var readOnly []*mcp.Tool
+	for _, tool := range tools {
+		if IsReadOnlyTool(tool) {
+			readOnly = append(readOnly, tool)
+		}
+	}
+	return readOnly
+}
+
+// GetAllTools returns the read-only tools of each server
+func (r *readOnlyProxy) GetAllTools() map[string][]*mcp.Tool {
+	tools := make(map[string][]*mcp.Tool)
+	for serverName, serverTools := range r.proxyManager.GetAllTools() {
+		tools[serverName] = readOnlyTools(serverTools)
+	}
+	return tools
+}
+
+// PendingServers passes through to the wrapped proxy manager
+func (r *readOnlyProxy) PendingServers() []string {
+	return PendingServers(r.proxyManager)
+}
+
+// EnsureStarted starts a server, returning its read-only tools
+func (r *readOnlyProxy) EnsureStarted(serverName string) ([]*mcp.Tool, error) {
+	tools, err := EnsureStarted(r.proxyManager, serverName)
+	if err != nil {
+		return nil, err
+	}
+	return readOnlyTools(tools), nil
+}
+
+// ServerStatuses passes through to the wrapped proxy manager
+func (r *readOnlyProxy) ServerStatuses() []ServerStatus {
+	statuses, _ := ServerStatuses(r.proxyManager)
+	return statuses
+}
+
+// ToolPrefix passes through to the wrapped proxy manager
+func (r *readOnlyProxy) ToolPrefix(serverName string) string {
+	return ToolPrefix(r.proxyManager, serverName)
+}
+
+// ToolAliases passes through to the wrapped proxy manager
+func (r *readOnlyProxy) ToolAliases(serverName string) map[string]string {
+	return ToolAliases(r.proxyManager, serverName)
+}
+
+// ServerStderr passes through to the wrapped proxy manager
+func (r *readOnlyProxy) ServerStderr(serverName string) []string {
+	return ServerStderr(r.proxyManager, serverName)
+}
+
+// RestartServer relaunches a server, returning its read-only tools
+func (r *readOnlyProxy) RestartServer(serverName string) ([]*mcp.Tool, error) {
+	tools, err := RestartServer(r.proxyManager, serverName)
+	if err != nil {
+		return nil, err
+	}
+	return readOnlyTools(tools), nil
+}
+
+// InstanceID passes through to the wrapped proxy manager
+func (r *readOnlyProxy) InstanceID(serverName string) (uint64, bool) {
+	return InstanceID(r.proxyManager, serverName)
+}
+
+// CheckHealth passes through to the wrapped proxy manager
+func (r *readOnlyProxy) CheckHealth() {
+	CheckHealth(r.proxyManager)
+}
+
+// CallTool calls a read-only tool
+func (r *readOnlyProxy) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return r.CallToolContext(context.Background(), serverName, toolName, arguments)
+}
+
+// CallToolContext is CallTool, forwarding ctx to the wrapped proxy manager
+func (r *readOnlyProxy) CallToolContext(ctx context.Context, serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	tools, ok := r.proxyManager.GetAllTools()[serverName]
+	if !ok {
+		// Lazily started servers' tools aren't known until they're running
+		var err error
+		if tools, err = EnsureStarted(r.proxyManager, serverName); err != nil {
+			return nil, err
+		}
+	}
+	for _, tool := range tools {
+		if tool.Name == toolName {
+			if !IsReadOnlyTool(tool) {
+				return nil, fmt.Errorf("tool %s.%s is not marked read-only, so can't be called in read-only mode", serverName, toolName)
+			}
+			return CallToolContext(ctx, r.proxyManager, serverName, toolName, arguments)
+		}
+	}
+	return nil, fmt.Errorf("tool %s not found on server %s", toolName, serverName)
+}
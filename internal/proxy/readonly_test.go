@@ -0,0 +1,45 @@
+package proxy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// annotatedProxy is a proxy manager serving one server with a read-only and a mutating tool
+type annotatedProxy struct{}
+
+func (annotatedProxy) GetAllTools() map[string][]*mcp.Tool {
+	return map[string][]*mcp.Tool{"github": {
+		{Name: "get_issue", Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}},
+		{Name: "create_issue"},
+	}}
+}
+
+func (annotatedProxy) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return &mcp.CallToolResult{}, nil
+}
+
+func TestReadOnly(t *testing.T) {
+	pm := ReadOnly(annotatedProxy{})
+
+	tools := pm.GetAllTools()["github"]
+	if len(tools) != 1 || tools[0].Name != "get_issue" {
+		t.Errorf("GetAllTools() = %v, want only get_issue", tools)
+	}
+
+	if _, err := pm.CallTool("github", "get_issue", nil); err != nil {
+		t.Errorf("CallTool() of a read-only tool error = %v", err)
+	}
+	_, err := pm.CallTool("github", "create_issue", nil)
+	if err == nil || !strings.Contains(err.Error(), "not marked read-only") {
+		t.Errorf("CallTool() of a mutating tool error = %v, want read-only refusal", err)
+	}
+	if _, err := pm.CallTool("github", "missing", nil); err == nil {
+		t.Error("Expected an error calling an unknown tool")
+	}
+	if _, err := pm.CallTool("jira", "search", nil); err == nil {
+		t.Error("Expected an error calling an unknown server")
+	}
+}
@@ -0,0 +1,146 @@
+package proxy
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/logging"
+)
+
+// ReloadSummary describes how a new configuration changed the set of upstream servers
+type ReloadSummary struct {
+	Added     []string `json:"added,omitempty"`     // newly configured servers
+	Removed   []string `json:"removed,omitempty"`   // servers no longer configured, now disconnected
+	Restarted []string `json:"restarted,omitempty"` // servers whose command, args or env changed
+	Updated   []string `json:"updated,omitempty"`   // servers with other setting changes, left connected
+	Failed    []string `json:"failed,omitempty"`    // added or restarted servers that failed to connect
+}
+
+// Changed reports whether the reload made any difference
+func (s *ReloadSummary) Changed() bool {
+	return len(s.Added)+len(s.Removed)+len(s.Restarted)+len(s.Updated) > 0
+}
+
+// String summarises the changes in one line
+func (s *ReloadSummary) String() string {
+	if !s.Changed() {
+		return "no server changes"
+	}
+
+	var parts []string
+	for _, group := range []struct {
+		label   string
+		servers []string
+	}{
+		{"added", s.Added},
+		{"removed", s.Removed},
+		{"restarted", s.Restarted},
+		{"updated", s.Updated},
+		{"failed to connect", s.Failed},
+	} {
+		if len(group.servers) > 0 {
+			parts = append(parts, fmt.Sprintf("%s: %s", group.label, strings.Join(group.servers, ", ")))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Reconcile switches the manager to a new configuration, disconnecting removed servers,
+// reconnecting servers whose launch settings changed and connecting newly added ones
+func (m *Manager) Reconcile(cfg *config.Config) *ReloadSummary {
+	summary := &ReloadSummary{}
+	toConnect := make(map[string]config.MCPServerConfig)
+	toClose := make(map[string]*mcp.ClientSession)
+	forget := func(serverName string) {
+		if session := m.forgetServerLocked(serverName); session != nil {
+			toClose[serverName] = session
+		}
+	}
+
+	m.mu.Lock()
+	previous := m.config.MCPServers
+	for serverName := range previous {
+		if _, kept := cfg.MCPServers[serverName]; !kept {
+			forget(serverName)
+			summary.Removed = append(summary.Removed, serverName)
+		}
+	}
+	for serverName, serverConfig := range cfg.MCPServers {
+		oldConfig, existed := previous[serverName]
+		switch {
+		case !existed:
+			summary.Added = append(summary.Added, serverName)
+		case cacheFingerprint(oldConfig) != cacheFingerprint(serverConfig):
+			forget(serverName)
+			summary.Restarted = append(summary.Restarted, serverName)
+		case !reflect.DeepEqual(oldConfig, serverConfig):
+			summary.Updated = append(summary.Updated, serverName)
+			continue
+		default:
+			continue
+		}
+		if !serverConfig.LazyStart {
+			toConnect[serverName] = serverConfig
+		}
+	}
+	m.config = cfg
+	m.mu.Unlock()
+
+	// Servers can take a while to exit, so they're closed once the manager is unlocked, and before
+	// those being restarted are launched again
+	m.closeSessions(toClose)
+
+	// Connect new and changed servers concurrently, as at startup
+	var wg sync.WaitGroup
+	var failedMu sync.Mutex
+	slots := make(chan struct{}, startupConcurrency)
+	for serverName, serverConfig := range toConnect {
+		wg.Add(1)
+		go func(serverName string, serverConfig config.MCPServerConfig) {
+			defer wg.Done()
+			slots <- struct{}{}
+			defer func() { <-slots }()
+
+			conn, err := m.dialServer(serverName, serverConfig)
+			if err != nil {
+				if !m.quiet {
+					logging.Warnf("Failed to connect to server %s: %v", serverName, err)
+				}
+				failedMu.Lock()
+				summary.Failed = append(summary.Failed, serverName)
+				failedMu.Unlock()
+				return
+			}
+
+			m.mu.Lock()
+			m.storeConnection(serverName, serverConfig, conn)
+			m.mu.Unlock()
+		}(serverName, serverConfig)
+	}
+	wg.Wait()
+
+	m.startIdleWatcher()
+
+	for _, names := range [][]string{summary.Added, summary.Removed, summary.Restarted, summary.Updated, summary.Failed} {
+		sort.Strings(names)
+	}
+	return summary
+}
+
+// forgetServerLocked disconnects a server and discards its tools, returning its session, if it
+// had one, for the caller to close once m.mu is released. The caller must hold m.mu.
+func (m *Manager) forgetServerLocked(serverName string) *mcp.ClientSession {
+	session := m.sessions[serverName]
+	m.detachSessionLocked(serverName)
+	delete(m.tools, serverName)
+	delete(m.dropped, serverName)
+	m.forgetErrors(serverName)
+	m.forgetResults(serverName)
+	return session
+}
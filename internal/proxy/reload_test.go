@@ -0,0 +1,80 @@
+package proxy
+
+import (
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+func TestManagerReconcile(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	kept := testServerConfig("kept_tool")
+	changing := testServerConfig("old_tool")
+	manager := NewManager(&config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"kept":     kept,
+		"changing": changing,
+		"removed":  testServerConfig("removed_tool"),
+		"tweaked":  testServerConfig("tweaked_tool"),
+	}}, WithQuietMode())
+	defer manager.Stop()
+	if err := manager.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	tweaked := testServerConfig("tweaked_tool")
+	tweaked.HiddenTools = []string{"tweaked_tool"}
+	summary := manager.Reconcile(&config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"kept":     kept,
+		"changing": testServerConfig("new_tool"),
+		"tweaked":  tweaked,
+		"added":    testServerConfig("added_tool"),
+		"broken":   {Command: "false"},
+	}})
+
+	expected := ReloadSummary{
+		Added:     []string{"added", "broken"},
+		Removed:   []string{"removed"},
+		Restarted: []string{"changing"},
+		Updated:   []string{"tweaked"},
+		Failed:    []string{"broken"},
+	}
+	for _, check := range []struct {
+		name      string
+		got, want []string
+	}{
+		{"added", summary.Added, expected.Added},
+		{"removed", summary.Removed, expected.Removed},
+		{"restarted", summary.Restarted, expected.Restarted},
+		{"updated", summary.Updated, expected.Updated},
+		{"failed", summary.Failed, expected.Failed},
+	} {
+		if len(check.got) != len(check.want) {
+			t.Errorf("%s = %v, want %v", check.name, check.got, check.want)
+			continue
+		}
+		for i := range check.want {
+			if check.got[i] != check.want[i] {
+				t.Errorf("%s = %v, want %v", check.name, check.got, check.want)
+			}
+		}
+	}
+
+	tools := manager.GetAllTools()
+	if _, exists := tools["removed"]; exists {
+		t.Error("Expected tools of removed server to be discarded")
+	}
+	if changed := tools["changing"]; len(changed) != 1 || changed[0].Name != "new_tool" {
+		t.Errorf("Expected restarted server to offer new_tool, got %v", changed)
+	}
+	if added := tools["added"]; len(added) != 1 || added[0].Name != "added_tool" {
+		t.Errorf("Expected added server to be connected, got %v", added)
+	}
+
+	if summary.String() != "added: added, broken; removed: removed; restarted: changing; updated: tweaked; failed to connect: broken" {
+		t.Errorf("String() = %q", summary.String())
+	}
+	if again := manager.Reconcile(manager.config); again.Changed() {
+		t.Errorf("Reconcile() with the same config = %v, want no changes", again)
+	}
+}
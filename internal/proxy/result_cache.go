@@ -0,0 +1,94 @@
+package proxy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// CachedMetaKey is the result _meta field set on results answered from the result cache
+const CachedMetaKey = "mcp-metatool/cached"
+
+// cachedResult is a successful tool result kept for reuse until it expires
+type cachedResult struct {
+	result  *mcp.CallToolResult
+	expires time.Time
+}
+
+// resultCacheKey identifies a call by server, tool and arguments. Maps are encoded with sorted
+// keys, so equal arguments always produce the same key.
+func resultCacheKey(serverName, toolName string, arguments map[string]interface{}) (string, bool) {
+	data, err := json.Marshal(arguments)
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+	return serverName + "\x00" + toolName + "\x00" + hex.EncodeToString(sum[:]), true
+}
+
+// cachedCall returns an unexpired cached result for the call, marked as coming from the cache
+func (m *Manager) cachedCall(key string) (*mcp.CallToolResult, bool) {
+	m.cacheMu.Lock()
+	defer m.cacheMu.Unlock()
+	entry, found := m.results[key]
+	if !found {
+		return nil, false
+	}
+	if time.Now().After(entry.expires) {
+		delete(m.results, key)
+		return nil, false
+	}
+
+	result := copyResult(entry.result)
+	if result.Meta == nil {
+		result.Meta = mcp.Meta{}
+	}
+	result.Meta[CachedMetaKey] = true
+	return result, true
+}
+
+// cacheResult keeps a successful result for reuse, discarding any entries that have expired
+func (m *Manager) cacheResult(key string, result *mcp.CallToolResult, ttl time.Duration) {
+	if result == nil || result.IsError {
+		return
+	}
+	now := time.Now()
+
+	m.cacheMu.Lock()
+	defer m.cacheMu.Unlock()
+	for existing, entry := range m.results {
+		if now.After(entry.expires) {
+			delete(m.results, existing)
+		}
+	}
+	m.results[key] = cachedResult{result: copyResult(result), expires: now.Add(ttl)}
+}
+
+// forgetResults discards the cached results of a server's tools
+func (m *Manager) forgetResults(serverName string) {
+	m.cacheMu.Lock()
+	defer m.cacheMu.Unlock()
+	for key := range m.results {
+		if strings.HasPrefix(key, serverName+"\x00") {
+			delete(m.results, key)
+		}
+	}
+}
+
+// copyResult copies a result so that callers changing it don't affect the cached original
+func copyResult(result *mcp.CallToolResult) *mcp.CallToolResult {
+	copied := *result
+	copied.Content = slices.Clone(result.Content)
+	if result.Meta != nil {
+		copied.Meta = make(mcp.Meta, len(result.Meta))
+		for key, value := range result.Meta {
+			copied.Meta[key] = value
+		}
+	}
+	return &copied
+}
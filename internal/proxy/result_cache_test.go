@@ -0,0 +1,83 @@
+package proxy
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+// attachCounter connects an upstream whose tools report how many times they've been called
+func attachCounter(t *testing.T, m *Manager, serverName string, toolNames ...string) {
+	t.Helper()
+	upstream := mcp.NewServer(&mcp.Implementation{Name: serverName, Version: "1.0.0"}, nil)
+	calls := 0
+	for _, name := range toolNames {
+		mcp.AddTool(upstream, &mcp.Tool{Name: name}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+			calls++
+			if args["fail"] == true {
+				return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "failed"}}, IsError: true}, nil, nil
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("call %d", calls)}}}, nil, nil
+		})
+	}
+	attachUpstream(t, m, serverName, upstream)
+}
+
+func TestManagerCachesResults(t *testing.T) {
+	cfg := &config.Config{
+		MCPServers: map[string]config.MCPServerConfig{
+			"github": {Command: "false", Cache: []config.CacheRule{{Tools: "get_*", TTL: "1h"}}},
+		},
+	}
+	manager := NewManager(cfg, WithQuietMode())
+	defer manager.Stop()
+	attachCounter(t, manager, "github", "get_issue", "create_issue")
+
+	call := func(tool string, arguments map[string]interface{}) *mcp.CallToolResult {
+		t.Helper()
+		result, err := manager.CallTool("github", tool, arguments)
+		if err != nil {
+			t.Fatalf("CallTool(%s) error = %v", tool, err)
+		}
+		return result
+	}
+	text := func(result *mcp.CallToolResult) string {
+		return result.Content[0].(*mcp.TextContent).Text
+	}
+
+	first := call("get_issue", map[string]interface{}{"number": 1, "repo": "app"})
+	again := call("get_issue", map[string]interface{}{"repo": "app", "number": 1})
+	if text(again) != text(first) || again.Meta[CachedMetaKey] != true {
+		t.Errorf("repeated call = %q (meta %v), want cached %q", text(again), again.Meta, text(first))
+	}
+	if _, marked := first.Meta[CachedMetaKey]; marked {
+		t.Error("the original result should not be marked as cached")
+	}
+
+	if other := call("get_issue", map[string]interface{}{"number": 2, "repo": "app"}); text(other) == text(first) {
+		t.Error("calls with different arguments should not share a cached result")
+	}
+	if created := call("create_issue", nil); text(call("create_issue", nil)) == text(created) {
+		t.Error("tools without a cache rule should always reach the server")
+	}
+
+	failed := call("get_issue", map[string]interface{}{"fail": true})
+	if retried := call("get_issue", map[string]interface{}{"fail": true}); !failed.IsError || retried.Meta[CachedMetaKey] == true {
+		t.Error("error results should not be cached")
+	}
+
+	// Removing the server discards its cached results
+	manager.mu.Lock()
+	session := manager.forgetServerLocked("github")
+	manager.mu.Unlock()
+	if session != nil {
+		session.Close()
+	}
+	if len(manager.results) != 0 {
+		t.Errorf("results after forgetting server = %d, want 0", len(manager.results))
+	}
+}
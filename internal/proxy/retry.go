@@ -0,0 +1,50 @@
+package proxy
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/logging"
+)
+
+// RetriesMetaKey is the result _meta field reporting how many times a call was retried
+const RetriesMetaKey = "mcp-metatool/retries"
+
+// failureMessage returns the text of a failed call's error or error result, or "" if the call succeeded
+func failureMessage(result *mcp.CallToolResult, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	if result == nil || !result.IsError {
+		return ""
+	}
+	var texts []string
+	for _, content := range result.Content {
+		if text, ok := content.(*mcp.TextContent); ok {
+			texts = append(texts, text.Text)
+		}
+	}
+	if len(texts) == 0 {
+		return "tool returned an error"
+	}
+	return strings.Join(texts, "\n")
+}
+
+// backOff waits before retrying a call, returning false if the call is cancelled or the manager
+// stops in the meantime
+func (m *Manager) backOff(ctx context.Context, serverName, toolName string, delay time.Duration, reason string) bool {
+	if !m.quiet {
+		logging.Infof("Retrying %s__%s in %v after transient error: %s", serverName, toolName, delay, reason)
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
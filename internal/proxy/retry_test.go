@@ -0,0 +1,123 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+// attachFlaky connects an upstream whose flaky tool fails with the given message until it has been called succeedOn times
+func attachFlaky(t *testing.T, m *Manager, serverName, message string, succeedOn int) *int {
+	t.Helper()
+	calls := 0
+	upstream := mcp.NewServer(&mcp.Implementation{Name: serverName, Version: "1.0.0"}, nil)
+	mcp.AddTool(upstream, &mcp.Tool{Name: "flaky"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		calls++
+		if calls < succeedOn {
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: message}}, IsError: true}, nil, nil
+		}
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil, nil
+	})
+	attachUpstream(t, m, serverName, upstream)
+	return &calls
+}
+
+func TestManagerRetriesTransientErrors(t *testing.T) {
+	retry := &config.RetryConfig{MaxAttempts: 3, Backoff: "1ms"}
+	cfg := &config.Config{
+		MCPServers: map[string]config.MCPServerConfig{
+			"recovers":  {Command: "false", Retry: retry},
+			"exhausted": {Command: "false", Retry: retry},
+			"permanent": {Command: "false", Retry: retry},
+			"noretry":   {Command: "false"},
+		},
+	}
+	manager := NewManager(cfg, WithQuietMode())
+	defer manager.Stop()
+
+	recovers := attachFlaky(t, manager, "recovers", "503 Service Unavailable", 3)
+	exhausted := attachFlaky(t, manager, "exhausted", "rate limit exceeded", 10)
+	permanent := attachFlaky(t, manager, "permanent", "repository not found", 10)
+	noretry := attachFlaky(t, manager, "noretry", "503 Service Unavailable", 3)
+
+	result, err := manager.CallTool("recovers", "flaky", nil)
+	if err != nil || result.IsError {
+		t.Fatalf("CallTool() = %v, %v; want success on the third attempt", result, err)
+	}
+	if *recovers != 3 || result.Meta[RetriesMetaKey] != 2 {
+		t.Errorf("calls = %d, retries meta = %v; want 3 calls and 2 retries", *recovers, result.Meta[RetriesMetaKey])
+	}
+
+	result, _ = manager.CallTool("exhausted", "flaky", nil)
+	if !result.IsError || *exhausted != 3 {
+		t.Errorf("exhausted retries: IsError = %v after %d calls, want the error after 3", result.IsError, *exhausted)
+	}
+	if result.Meta[RetriesMetaKey] != 2 {
+		t.Errorf("retries meta = %v, want 2 on the final error result", result.Meta[RetriesMetaKey])
+	}
+
+	for name, calls := range map[string]*int{"permanent": permanent, "noretry": noretry} {
+		result, _ := manager.CallTool(name, "flaky", nil)
+		if !result.IsError || *calls != 1 {
+			t.Errorf("%s: IsError = %v after %d calls, want a single failed call", name, result.IsError, *calls)
+		}
+	}
+}
+
+func TestManagerBackOffStopsWithManager(t *testing.T) {
+	manager := NewManager(&config.Config{}, WithQuietMode())
+	manager.Stop()
+
+	ctx, cancel := manager.callContext(context.Background())
+	defer cancel()
+	finished := make(chan bool)
+	go func() { finished <- manager.backOff(ctx, "slow", "flaky", time.Hour, "temporarily unavailable") }()
+	select {
+	case waited := <-finished:
+		if waited {
+			t.Error("backOff() should report that the manager stopped")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("backOff() kept waiting after the manager stopped")
+	}
+}
+
+func TestManagerCallToolCancelled(t *testing.T) {
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{"slow": {Command: "false"}}}
+	manager := NewManager(cfg, WithQuietMode())
+	defer manager.Stop()
+
+	started := make(chan struct{})
+	stopped := make(chan struct{})
+	upstream := mcp.NewServer(&mcp.Implementation{Name: "slow", Version: "1.0.0"}, nil)
+	mcp.AddTool(upstream, &mcp.Tool{Name: "wait"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		close(started)
+		<-ctx.Done()
+		close(stopped)
+		return nil, nil, ctx.Err()
+	})
+	attachUpstream(t, manager, "slow", upstream)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-started
+		cancel()
+	}()
+	if _, err := manager.CallToolContext(ctx, "slow", "wait", nil); err == nil {
+		t.Fatal("CallToolContext() succeeded after being cancelled")
+	}
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatal("upstream call kept running after the caller cancelled")
+	}
+	manager.statusMu.Lock()
+	defer manager.statusMu.Unlock()
+	if health := manager.healthLocked("slow"); health.failures != 0 {
+		t.Errorf("cancelled call counted as %d server failure(s)", health.failures)
+	}
+}
@@ -0,0 +1,166 @@
+package proxy
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+// Connection states reported in ServerStatus
+const (
+	StateConnected    = "connected"
+	StateConnecting   = "connecting"   // connecting in the background while cached tools are served
+	StateFailed       = "failed"       // the last connection attempt failed
+	StateDisconnected = "disconnected" // dropped after failed keepalive pings, reconnected on next use
+	StateStopped      = "stopped"      // not started yet, or shut down while idle
+)
+
+// Health assessments reported in ServerStatus
+const (
+	HealthHealthy   = "healthy"
+	HealthDegraded  = "degraded"  // recent pings or calls failed
+	HealthUnhealthy = "unhealthy" // not reachable
+	HealthUnknown   = "unknown"   // not running, so not checked
+)
+
+// ServerStatus describes the runtime state of one configured upstream server
+type ServerStatus struct {
+	Name      string `json:"name"`
+	Transport string `json:"transport"`
+	State     string `json:"state"`
+	Hidden    bool   `json:"hidden"`
+	Tools     int    `json:"tools"`
+	// ProtocolVersion is the MCP protocol revision negotiated with the server, while it's connected
+	ProtocolVersion string     `json:"protocolVersion,omitempty"`
+	LastError       string     `json:"lastError,omitempty"`
+	LastErrorAt     *time.Time `json:"lastErrorAt,omitempty"`
+	Health          string     `json:"health"`
+	// LatencyMs is the round trip of the last successful health check ping
+	LatencyMs     float64    `json:"latencyMs,omitempty"`
+	LastCheckAt   *time.Time `json:"lastCheckAt,omitempty"`
+	LastSuccessAt *time.Time `json:"lastSuccessAt,omitempty"` // last successful tool call
+	// ConsecutiveFailures counts the pings and calls that have failed since the last success
+	ConsecutiveFailures int `json:"consecutiveFailures"`
+	// Stderr holds the latest lines the server wrote to stderr, reported by server_status
+	Stderr []string `json:"stderr,omitempty"`
+}
+
+// StatusReporter is implemented by proxy managers that can report the state of their servers
+type StatusReporter interface {
+	// ServerStatuses returns the state of every configured server, sorted by name
+	ServerStatuses() []ServerStatus
+}
+
+// ServerStatuses reports the state of the proxy manager's servers, if it can
+func ServerStatuses(pm ProxyManager) ([]ServerStatus, bool) {
+	if reporter, ok := pm.(StatusReporter); ok {
+		return reporter.ServerStatuses(), true
+	}
+	return nil, false
+}
+
+// protocolVersion returns the MCP protocol revision negotiated for a session
+func protocolVersion(session *mcp.ClientSession) string {
+	if result := session.InitializeResult(); result != nil {
+		return result.ProtocolVersion
+	}
+	return ""
+}
+
+// checkProtocolVersion fails if a session negotiated an older protocol revision than the server's
+// config requires. Revisions are dates, so they compare in release order as strings.
+func checkProtocolVersion(session *mcp.ClientSession, serverConfig config.MCPServerConfig) error {
+	required := serverConfig.MinProtocolVersion
+	if negotiated := protocolVersion(session); required != "" && negotiated < required {
+		return fmt.Errorf("server negotiated MCP protocol version %s, but minProtocolVersion requires %s or later", negotiated, required)
+	}
+	return nil
+}
+
+// serverError is the most recent error seen for a server
+type serverError struct {
+	message string
+	at      time.Time
+	failing bool // no connection has succeeded since
+}
+
+// recordError remembers an error seen for a server; failing means it could not be connected
+func (m *Manager) recordError(serverName string, err error, failing bool) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	m.lastErrors[serverName] = serverError{message: err.Error(), at: time.Now(), failing: failing}
+}
+
+// clearFailure notes that a server connected successfully, keeping its last error for reference
+func (m *Manager) clearFailure(serverName string) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	if lastError, exists := m.lastErrors[serverName]; exists {
+		lastError.failing = false
+		m.lastErrors[serverName] = lastError
+	}
+	if health := m.health[serverName]; health != nil {
+		health.failures = 0
+	}
+}
+
+// forgetErrors discards the error, health and stderr history of a server that is no longer configured
+func (m *Manager) forgetErrors(serverName string) {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	delete(m.lastErrors, serverName)
+	delete(m.health, serverName)
+	delete(m.stderr, serverName)
+}
+
+// ServerStatuses returns the state of every configured server, sorted by name
+func (m *Manager) ServerStatuses() []ServerStatus {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+
+	statuses := make([]ServerStatus, 0, len(m.config.MCPServers))
+	for serverName, serverConfig := range m.config.MCPServers {
+		status := ServerStatus{
+			Name:      serverName,
+			Transport: serverConfig.Transport(),
+			Hidden:    serverConfig.Hidden,
+			Tools:     len(m.tools[serverName]),
+		}
+
+		lastError, hasError := m.lastErrors[serverName]
+		session, connected := m.sessions[serverName]
+		if connected {
+			status.ProtocolVersion = protocolVersion(session)
+		}
+		_, connecting := m.connecting[serverName]
+		switch {
+		case connected:
+			status.State = StateConnected
+		case connecting:
+			status.State = StateConnecting
+		case hasError && lastError.failing:
+			status.State = StateFailed
+		case m.dropped[serverName]:
+			status.State = StateDisconnected
+		default:
+			status.State = StateStopped
+		}
+
+		if hasError {
+			at := lastError.at
+			status.LastError = lastError.message
+			status.LastErrorAt = &at
+		}
+		m.health[serverName].describe(&status)
+		statuses = append(statuses, status)
+	}
+
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
@@ -0,0 +1,102 @@
+package proxy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+func TestServerStatuses(t *testing.T) {
+	hidden := testServerConfig("alpha,beta")
+	hidden.Hidden = true
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"broken": {Command: "sh", Args: []string{"-c", "exit 1"}},
+		"lazy":   {Command: "false", LazyStart: true},
+		"live":   hidden,
+	}}
+	m := NewManager(cfg, WithQuietMode())
+	defer m.Stop()
+	if err := m.Start(); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	statuses := m.ServerStatuses()
+	if len(statuses) != 3 {
+		t.Fatalf("Expected 3 statuses, got %+v", statuses)
+	}
+	byName := make(map[string]ServerStatus)
+	for _, status := range statuses {
+		byName[status.Name] = status
+	}
+	if statuses[0].Name != "broken" || statuses[2].Name != "live" {
+		t.Errorf("Expected statuses sorted by name, got %+v", statuses)
+	}
+
+	tests := []struct {
+		name      string
+		state     string
+		tools     int
+		hidden    bool
+		lastError bool
+	}{
+		{"broken", StateFailed, 0, false, true},
+		{"lazy", StateStopped, 0, false, false},
+		{"live", StateConnected, 2, true, false},
+	}
+	for _, tt := range tests {
+		status := byName[tt.name]
+		if status.State != tt.state || status.Tools != tt.tools || status.Hidden != tt.hidden || (status.LastError != "") != tt.lastError {
+			t.Errorf("Unexpected status for %s: %+v", tt.name, status)
+		}
+		if status.Transport != "stdio" {
+			t.Errorf("Expected stdio transport for %s, got %q", tt.name, status.Transport)
+		}
+		if tt.lastError && status.LastErrorAt == nil {
+			t.Errorf("Expected a last error time for %s", tt.name)
+		}
+		if (status.ProtocolVersion != "") != (tt.state == StateConnected) {
+			t.Errorf("Expected a protocol version only for connected servers, got %q for %s", status.ProtocolVersion, tt.name)
+		}
+	}
+
+	// A server dropped after failed pings is reported as disconnected, keeping the reason
+	m.mu.RLock()
+	session := m.sessions["live"]
+	m.mu.RUnlock()
+	m.dropSession("live", session)
+	for _, status := range m.ServerStatuses() {
+		if status.Name == "live" && (status.State != StateDisconnected || status.LastError == "") {
+			t.Errorf("Expected live to be disconnected with a last error, got %+v", status)
+		}
+	}
+}
+
+func TestMinProtocolVersion(t *testing.T) {
+	tests := []struct {
+		name      string
+		required  string
+		wantState string
+	}{
+		{"no minimum", "", StateConnected},
+		{"older minimum", "2024-11-05", StateConnected},
+		{"newer minimum", "2099-01-01", StateFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			serverConfig := testServerConfig("alpha")
+			serverConfig.MinProtocolVersion = tt.required
+			m := NewManager(&config.Config{MCPServers: map[string]config.MCPServerConfig{"pinned": serverConfig}}, WithQuietMode())
+			defer m.Stop()
+			m.Start()
+
+			status := m.ServerStatuses()[0]
+			if status.State != tt.wantState {
+				t.Fatalf("Expected %s, got %+v", tt.wantState, status)
+			}
+			if tt.wantState == StateFailed && !strings.Contains(status.LastError, "minProtocolVersion requires 2099-01-01 or later") {
+				t.Errorf("Expected the last error to explain the version mismatch, got %q", status.LastError)
+			}
+		})
+	}
+}
@@ -0,0 +1,110 @@
+package proxy
+
+import (
+	"bytes"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/logging"
+)
+
+// stderrLines is how many of the latest lines each server wrote to stderr are kept for diagnostics
+const stderrLines = 50
+
+// stderrWaitDelay bounds how long stopping a server waits for its stderr to be closed
+const stderrWaitDelay = time.Second
+
+// maxStderrLineLength truncates lines so a server writing without newlines can't grow the buffer unbounded
+const maxStderrLineLength = 4096
+
+// StderrReporter is implemented by proxy managers that keep what their servers wrote to stderr
+type StderrReporter interface {
+	// ServerStderr returns the latest lines a server wrote to stderr, oldest first
+	ServerStderr(serverName string) []string
+}
+
+// ServerStderr returns the latest lines a server wrote to stderr, if the proxy manager keeps them
+func ServerStderr(pm ProxyManager, serverName string) []string {
+	if reporter, ok := pm.(StderrReporter); ok {
+		return reporter.ServerStderr(serverName)
+	}
+	return nil
+}
+
+// stderrBuffer keeps the latest lines a server wrote to stderr, logging each at debug level.
+// It outlives the server's process, so the output of one that failed to start can be seen.
+type stderrBuffer struct {
+	serverName string
+	mu         sync.Mutex
+	lines      []string
+	partial    []byte // an unfinished last line
+}
+
+// Write records complete lines from a chunk of output
+func (b *stderrBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	data := append(b.partial, p...)
+	for {
+		i := bytes.IndexByte(data, '\n')
+		if i < 0 {
+			break
+		}
+		b.add(string(bytes.TrimRight(data[:i], "\r")))
+		data = data[i+1:]
+	}
+	if len(data) >= maxStderrLineLength {
+		b.add(string(data[:maxStderrLineLength]))
+		data = nil
+	}
+	b.partial = slices.Clone(data)
+	return len(p), nil
+}
+
+// add keeps a line, dropping the oldest once the buffer is full. The caller must hold b.mu.
+func (b *stderrBuffer) add(line string) {
+	if len(line) > maxStderrLineLength {
+		line = line[:maxStderrLineLength]
+	}
+	logging.Debugf("[%s stderr] %s", b.serverName, line)
+	if len(b.lines) == stderrLines {
+		b.lines = append(b.lines[:0], b.lines[1:]...)
+	}
+	b.lines = append(b.lines, line)
+}
+
+// Lines returns the kept lines, including any unfinished last line, oldest first
+func (b *stderrBuffer) Lines() []string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	lines := slices.Clone(b.lines)
+	if len(b.partial) > 0 {
+		lines = append(lines, string(b.partial))
+	}
+	return lines
+}
+
+// stderrFor returns the buffer a server's stderr is written to, creating it on first use
+func (m *Manager) stderrFor(serverName string) *stderrBuffer {
+	m.statusMu.Lock()
+	defer m.statusMu.Unlock()
+	buffer := m.stderr[serverName]
+	if buffer == nil {
+		buffer = &stderrBuffer{serverName: serverName}
+		m.stderr[serverName] = buffer
+	}
+	return buffer
+}
+
+// ServerStderr returns the latest lines a server wrote to stderr, oldest first,
+// including those written by earlier processes if it has been restarted
+func (m *Manager) ServerStderr(serverName string) []string {
+	m.statusMu.Lock()
+	buffer := m.stderr[serverName]
+	m.statusMu.Unlock()
+	if buffer == nil {
+		return nil
+	}
+	return buffer.Lines()
+}
@@ -0,0 +1,67 @@
+package proxy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+func TestStderrBuffer(t *testing.T) {
+	many := make([]string, stderrLines+5)
+	for i := range many {
+		many[i] = fmt.Sprintf("line %d", i)
+	}
+
+	tests := []struct {
+		name   string
+		writes []string
+		want   []string
+	}{
+		{"lines", []string{"one\ntwo\n"}, []string{"one", "two"}},
+		{"lines split across writes", []string{"par", "tial\nnext", " line\n"}, []string{"partial", "next line"}},
+		{"unfinished last line", []string{"done\nwaiting"}, []string{"done", "waiting"}},
+		{"windows line endings", []string{"one\r\ntwo\r\n"}, []string{"one", "two"}},
+		{"oldest lines dropped", []string{strings.Join(many, "\n") + "\n"}, many[5:]},
+		{"long lines truncated", []string{strings.Repeat("x", maxStderrLineLength+10) + "\n"}, []string{strings.Repeat("x", maxStderrLineLength)}},
+		{"no newlines", []string{strings.Repeat("y", maxStderrLineLength), "y"}, []string{strings.Repeat("y", maxStderrLineLength), "y"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buffer := &stderrBuffer{serverName: "test"}
+			for _, write := range tt.writes {
+				if n, err := buffer.Write([]byte(write)); n != len(write) || err != nil {
+					t.Fatalf("Write() = %d, %v", n, err)
+				}
+			}
+			if got := buffer.Lines(); !slices.Equal(got, tt.want) {
+				t.Errorf("Lines() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestServerStderr(t *testing.T) {
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"broken": {Command: "sh", Args: []string{"-c", "echo 'error: API_TOKEN is not set' >&2; exit 1"}},
+	}}
+	m := NewManager(cfg, WithQuietMode())
+	defer m.Stop()
+	m.Start()
+
+	// The failed server's output is kept after its process exits
+	want := []string{"error: API_TOKEN is not set"}
+	deadline := time.Now().Add(5 * time.Second)
+	for !slices.Equal(m.ServerStderr("broken"), want) && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if got := ServerStderr(m, "broken"); !slices.Equal(got, want) {
+		t.Errorf("ServerStderr() = %q, want %q", got, want)
+	}
+	if got := m.ServerStderr("unknown"); got != nil {
+		t.Errorf("ServerStderr() = %q for an unknown server, want nil", got)
+	}
+}
@@ -0,0 +1,127 @@
+package proxy
+
+import (
+	"encoding/base64"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// Content _meta fields set on text blocks the metatool has translated
+const (
+	EncodingMetaKey = "mcp-metatool/encoding" // "latin1" if re-encoded as UTF-8, "base64" if binary
+	ChunkMetaKey    = "mcp-metatool/chunk"    // index of the block among those split from one text
+	ChunksMetaKey   = "mcp-metatool/chunks"   // how many blocks the text was split into
+)
+
+// maxControlShare is the largest fraction of control bytes invalid UTF-8 may contain and still be
+// treated as Latin-1 text rather than binary data
+const maxControlShare = 0.1
+
+// translateContent repairs text blocks that aren't valid UTF-8 and splits those longer than
+// chunkSize bytes into several blocks, so every block is usable as a string. A chunkSize of
+// zero leaves long text whole.
+func translateContent(result *mcp.CallToolResult, chunkSize int) {
+	if result == nil {
+		return
+	}
+	var translated []mcp.Content
+	for i, content := range result.Content {
+		text, ok := content.(*mcp.TextContent)
+		if !ok || (utf8.ValidString(text.Text) && (chunkSize <= 0 || len(text.Text) <= chunkSize)) {
+			if translated != nil {
+				translated = append(translated, content)
+			}
+			continue
+		}
+		if translated == nil {
+			translated = append([]mcp.Content{}, result.Content[:i]...)
+		}
+		translated = append(translated, translateText(text, chunkSize)...)
+	}
+	if translated != nil {
+		result.Content = translated
+	}
+}
+
+// translateText re-encodes one text block as UTF-8 and splits it into chunks
+func translateText(text *mcp.TextContent, chunkSize int) []mcp.Content {
+	value, encoding := text.Text, ""
+	if !utf8.ValidString(value) {
+		if looksBinary(value) {
+			value, encoding = base64.StdEncoding.EncodeToString([]byte(value)), "base64"
+		} else {
+			value, encoding = latin1ToUTF8(value), "latin1"
+		}
+	}
+
+	chunks := []string{value}
+	if chunkSize > 0 {
+		chunks = splitText(value, chunkSize)
+	}
+	blocks := make([]mcp.Content, len(chunks))
+	for i, chunk := range chunks {
+		meta := mcp.Meta{}
+		for key, value := range text.Meta {
+			meta[key] = value
+		}
+		if encoding != "" {
+			meta[EncodingMetaKey] = encoding
+		}
+		if len(chunks) > 1 {
+			meta[ChunkMetaKey] = i
+			meta[ChunksMetaKey] = len(chunks)
+		}
+		blocks[i] = &mcp.TextContent{Text: chunk, Meta: meta, Annotations: text.Annotations}
+	}
+	return blocks
+}
+
+// looksBinary reports whether invalid UTF-8 is likely binary data rather than text in a
+// single-byte encoding: it contains a NUL byte or many control characters
+func looksBinary(value string) bool {
+	controls := 0
+	for i := 0; i < len(value); i++ {
+		b := value[i]
+		if b == 0 {
+			return true
+		}
+		if (b < 0x20 && b != '\t' && b != '\n' && b != '\r') || b == 0x7f {
+			controls++
+		}
+	}
+	return float64(controls) > maxControlShare*float64(len(value))
+}
+
+// latin1ToUTF8 decodes each byte as the Unicode code point of the same value
+func latin1ToUTF8(value string) string {
+	var b strings.Builder
+	b.Grow(len(value) * 2)
+	for i := 0; i < len(value); i++ {
+		b.WriteRune(rune(value[i]))
+	}
+	return b.String()
+}
+
+// splitText cuts UTF-8 text into chunks of at most size bytes, never inside a character, and at
+// the end of a line when one falls in the last quarter of a chunk
+func splitText(value string, size int) []string {
+	var chunks []string
+	for len(value) > size {
+		cut := size
+		for cut > 0 && !utf8.RuneStart(value[cut]) {
+			cut--
+		}
+		if newline := strings.LastIndexByte(value[:cut], '\n'); newline >= cut*3/4 {
+			cut = newline + 1
+		}
+		if cut == 0 {
+			// size is smaller than the first character, so the character is its own chunk
+			_, cut = utf8.DecodeRuneInString(value)
+		}
+		chunks = append(chunks, value[:cut])
+		value = value[cut:]
+	}
+	return append(chunks, value)
+}
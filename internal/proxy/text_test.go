@@ -0,0 +1,108 @@
+package proxy
+
+import (
+	"encoding/base64"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestTranslateContent(t *testing.T) {
+	binary := "PNG\x00\x01\x02\xff"
+	image := &mcp.ImageContent{Data: []byte("img"), MIMEType: "image/png"}
+
+	tests := []struct {
+		name      string
+		content   []mcp.Content
+		chunkSize int
+		wantText  []string
+		wantMeta  []mcp.Meta
+	}{
+		{
+			name:     "valid text is untouched",
+			content:  []mcp.Content{&mcp.TextContent{Text: "héllo"}},
+			wantText: []string{"héllo"},
+			wantMeta: []mcp.Meta{nil},
+		},
+		{
+			name:     "latin-1 is re-encoded",
+			content:  []mcp.Content{&mcp.TextContent{Text: "caf\xe9\n"}},
+			wantText: []string{"café\n"},
+			wantMeta: []mcp.Meta{{EncodingMetaKey: "latin1"}},
+		},
+		{
+			name:     "binary is base64 encoded",
+			content:  []mcp.Content{&mcp.TextContent{Text: binary, Meta: mcp.Meta{"source": "x"}}},
+			wantText: []string{base64.StdEncoding.EncodeToString([]byte(binary))},
+			wantMeta: []mcp.Meta{{EncodingMetaKey: "base64", "source": "x"}},
+		},
+		{
+			name:      "long text is chunked",
+			content:   []mcp.Content{&mcp.TextContent{Text: "abcdefghij"}},
+			chunkSize: 4,
+			wantText:  []string{"abcd", "efgh", "ij"},
+			wantMeta:  []mcp.Meta{{ChunkMetaKey: 0, ChunksMetaKey: 3}, {ChunkMetaKey: 1, ChunksMetaKey: 3}, {ChunkMetaKey: 2, ChunksMetaKey: 3}},
+		},
+		{
+			name:      "chunks never split characters",
+			content:   []mcp.Content{&mcp.TextContent{Text: "aébc"}},
+			chunkSize: 2,
+			wantText:  []string{"a", "é", "bc"},
+			wantMeta:  []mcp.Meta{{ChunkMetaKey: 0, ChunksMetaKey: 3}, {ChunkMetaKey: 1, ChunksMetaKey: 3}, {ChunkMetaKey: 2, ChunksMetaKey: 3}},
+		},
+		{
+			name:      "chunks end at lines",
+			content:   []mcp.Content{&mcp.TextContent{Text: "line one\nline two\n"}},
+			chunkSize: 10,
+			wantText:  []string{"line one\n", "line two\n"},
+			wantMeta:  []mcp.Meta{{ChunkMetaKey: 0, ChunksMetaKey: 2}, {ChunkMetaKey: 1, ChunksMetaKey: 2}},
+		},
+		{
+			name:      "short text and other content are kept in place",
+			content:   []mcp.Content{&mcp.TextContent{Text: "ok"}, image, &mcp.TextContent{Text: "abcdef"}},
+			chunkSize: 4,
+			wantText:  []string{"ok", "", "abcd", "ef"},
+			wantMeta:  []mcp.Meta{nil, nil, {ChunkMetaKey: 0, ChunksMetaKey: 2}, {ChunkMetaKey: 1, ChunksMetaKey: 2}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := &mcp.CallToolResult{Content: tt.content}
+			translateContent(result, tt.chunkSize)
+			if len(result.Content) != len(tt.wantText) {
+				t.Fatalf("Got %d content blocks, want %d", len(result.Content), len(tt.wantText))
+			}
+			for i, content := range result.Content {
+				text, ok := content.(*mcp.TextContent)
+				if !ok {
+					if content != image {
+						t.Errorf("Block %d = %#v, want the original image", i, content)
+					}
+					continue
+				}
+				if text.Text != tt.wantText[i] {
+					t.Errorf("Block %d text = %q, want %q", i, text.Text, tt.wantText[i])
+				}
+				if len(text.Meta) > 0 || len(tt.wantMeta[i]) > 0 {
+					if !reflect.DeepEqual(text.Meta, tt.wantMeta[i]) {
+						t.Errorf("Block %d meta = %v, want %v", i, text.Meta, tt.wantMeta[i])
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestTranslateContent_Nil(t *testing.T) {
+	translateContent(nil, 10)
+
+	long := strings.Repeat("x", 100)
+	result := &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: long}}}
+	translateContent(result, 0)
+	if len(result.Content) != 1 || result.Content[0].(*mcp.TextContent).Text != long {
+		t.Errorf("Expected long text left whole without a chunk size, got %v", result.Content)
+	}
+}
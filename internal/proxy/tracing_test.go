@@ -0,0 +1,75 @@
+package proxy
+
+import (
+	"context"
+	"testing"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/tracing"
+)
+
+func TestManagerTracesCalls(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	cfg := &config.Config{
+		MCPServers: map[string]config.MCPServerConfig{
+			"github": {Command: "false", Cache: []config.CacheRule{{Tools: "get_*", TTL: "1h"}}},
+		},
+	}
+	manager := NewManager(cfg, WithQuietMode())
+	defer manager.Stop()
+	attachCounter(t, manager, "github", "get_issue")
+
+	ctx, root := tracing.Start(context.Background(), "test")
+	for _, arguments := range []map[string]interface{}{{"number": 1}, {"number": 1}, {"fail": true}} {
+		if _, err := manager.CallToolContext(ctx, "github", "get_issue", arguments); err != nil {
+			t.Fatalf("CallToolContext() error = %v", err)
+		}
+	}
+	root.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 4 {
+		t.Fatalf("Expected 3 call spans and the test span, got %d spans", len(spans))
+	}
+	for i, span := range spans[:3] {
+		if span.Name() != "proxy.call_tool" || span.Parent().SpanID() != root.SpanContext().SpanID() {
+			t.Errorf("Span %d = %s, want proxy.call_tool within the caller's span", i, span.Name())
+		}
+	}
+	cacheHit := func(span sdktrace.ReadOnlySpan) bool {
+		for _, attr := range span.Attributes() {
+			if attr.Key == "metatool.cache_hit" {
+				return attr.Value.AsBool()
+			}
+		}
+		return false
+	}
+	if cacheHit(spans[0]) || !cacheHit(spans[1]) {
+		t.Errorf("Expected only the repeated call to be marked as a cache hit")
+	}
+	if !hasAttribute(spans[0].Attributes(), attribute.String("mcp.tool", "get_issue")) {
+		t.Errorf("Expected the span to name the tool, got %v", spans[0].Attributes())
+	}
+	if spans[2].Status().Code != codes.Error {
+		t.Errorf("Expected the failed call's span to be marked as an error, got %v", spans[2].Status())
+	}
+}
+
+func hasAttribute(attrs []attribute.KeyValue, want attribute.KeyValue) bool {
+	for _, attr := range attrs {
+		if attr == want {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,186 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// Priority orders waiting executions; higher priorities are always dispatched first
+type Priority int
+
+const (
+	// PriorityBatch is for saved tool and test runs that can wait
+	PriorityBatch Priority = iota
+	// PriorityInteractive is for ad-hoc evaluation a user is waiting on
+	PriorityInteractive
+
+	numPriorities = int(PriorityInteractive) + 1
+)
+
+// ErrFull is returned when the queue already holds its maximum number of waiting executions
+var ErrFull = errors.New("execution queue is full")
+
+// Queue limits concurrent executions and dispatches waiting ones by priority,
+// taking turns between clients within a priority so no single client can starve the others
+type Queue struct {
+	mu         sync.Mutex
+	workers    int
+	maxPending int
+	running    int
+	pending    int
+	levels     [numPriorities]level
+}
+
+// level holds the executions waiting at one priority, grouped by client
+type level struct {
+	clients []string             // round-robin order of clients with waiting executions
+	waiting map[string][]*ticket // client -> waiting executions, oldest first
+}
+
+// ticket is a waiting execution, signalled when it may run
+type ticket struct {
+	ready chan struct{}
+}
+
+// New creates a queue running at most workers executions at once with up to maxPending waiting
+func New(workers, maxPending int) *Queue {
+	if workers < 1 {
+		workers = 1
+	}
+	q := &Queue{workers: workers, maxPending: maxPending}
+	for i := range q.levels {
+		q.levels[i].waiting = make(map[string][]*ticket)
+	}
+	return q
+}
+
+// Do runs fn once a worker is free, returning ErrFull if the queue is full
+// or the context's error if it is cancelled before fn starts
+func (q *Queue) Do(ctx context.Context, client string, priority Priority, fn func()) error {
+	if priority < 0 || int(priority) >= numPriorities {
+		priority = PriorityBatch
+	}
+
+	q.mu.Lock()
+	if q.running < q.workers && q.pending == 0 {
+		q.running++
+		q.mu.Unlock()
+		defer q.release()
+		fn()
+		return nil
+	}
+	if q.pending >= q.maxPending {
+		q.mu.Unlock()
+		return ErrFull
+	}
+
+	t := &ticket{ready: make(chan struct{})}
+	q.enqueue(client, priority, t)
+	q.mu.Unlock()
+
+	select {
+	case <-t.ready:
+	case <-ctx.Done():
+		q.mu.Lock()
+		removed := q.remove(client, priority, t)
+		q.mu.Unlock()
+		if removed {
+			return ctx.Err()
+		}
+		// Dispatched while being cancelled; give the slot back
+		q.release()
+		return ctx.Err()
+	}
+
+	defer q.release()
+	fn()
+	return nil
+}
+
+// Stats returns the number of running and waiting executions
+func (q *Queue) Stats() (running, pending int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.running, q.pending
+}
+
+// enqueue adds a waiting execution; the caller must hold q.mu
+func (q *Queue) enqueue(client string, priority Priority, t *ticket) {
+	l := &q.levels[priority]
+	if len(l.waiting[client]) == 0 {
+		l.clients = append(l.clients, client)
+	}
+	l.waiting[client] = append(l.waiting[client], t)
+	q.pending++
+}
+
+// remove drops a waiting execution, reporting whether it was still waiting; the caller must hold q.mu
+func (q *Queue) remove(client string, priority Priority, t *ticket) bool {
+	l := &q.levels[priority]
+	tickets := l.waiting[client]
+	for i, waiting := range tickets {
+		if waiting != t {
+			continue
+		}
+		l.waiting[client] = append(tickets[:i], tickets[i+1:]...)
+		if len(l.waiting[client]) == 0 {
+			delete(l.waiting, client)
+			l.removeClient(client)
+		}
+		q.pending--
+		return true
+	}
+	return false
+}
+
+// release frees a worker and dispatches waiting executions into any free slots
+func (q *Queue) release() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.running--
+	for q.running < q.workers {
+		t := q.next()
+		if t == nil {
+			return
+		}
+		q.running++
+		close(t.ready)
+	}
+}
+
+// next pops the next execution to run: highest priority first, then the next client in turn.
+// The caller must hold q.mu.
+func (q *Queue) next() *ticket {
+	for priority := numPriorities - 1; priority >= 0; priority-- {
+		l := &q.levels[priority]
+		if len(l.clients) == 0 {
+			continue
+		}
+
+		client := l.clients[0]
+		l.clients = l.clients[1:]
+		tickets := l.waiting[client]
+		t := tickets[0]
+		if len(tickets) > 1 {
+			l.waiting[client] = tickets[1:]
+			l.clients = append(l.clients, client)
+		} else {
+			delete(l.waiting, client)
+		}
+		q.pending--
+		return t
+	}
+	return nil
+}
+
+// removeClient drops a client from the round-robin order
+func (l *level) removeClient(client string) {
+	for i, name := range l.clients {
+		if name == client {
+			l.clients = append(l.clients[:i], l.clients[i+1:]...)
+			return
+		}
+	}
+}
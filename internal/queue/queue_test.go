@@ -0,0 +1,146 @@
+package queue
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// waitForPending blocks until the queue holds the given number of waiting executions
+func waitForPending(t *testing.T, q *Queue, want int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if _, pending := q.Stats(); pending == want {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("timed out waiting for %d pending executions", want)
+}
+
+func TestQueueDispatchOrder(t *testing.T) {
+	q := New(1, 10)
+
+	// Occupy the only worker so everything else has to wait
+	block := make(chan struct{})
+	started := make(chan struct{})
+	go q.Do(context.Background(), "blocker", PriorityBatch, func() {
+		close(started)
+		<-block
+	})
+	<-started
+
+	var mu sync.Mutex
+	var order []string
+	var wg sync.WaitGroup
+	submit := func(client string, priority Priority, label string) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := q.Do(context.Background(), client, priority, func() {
+				mu.Lock()
+				order = append(order, label)
+				mu.Unlock()
+			})
+			if err != nil {
+				t.Errorf("Do(%s) error = %v", label, err)
+			}
+		}()
+	}
+
+	submissions := []struct {
+		client   string
+		priority Priority
+		label    string
+	}{
+		{"batcher", PriorityBatch, "batch-1"},
+		{"batcher", PriorityBatch, "batch-2"},
+		{"batcher", PriorityBatch, "batch-3"},
+		{"other", PriorityBatch, "other-1"},
+		{"user", PriorityInteractive, "interactive-1"},
+	}
+	for i, s := range submissions {
+		submit(s.client, s.priority, s.label)
+		waitForPending(t, q, i+1)
+	}
+
+	close(block)
+	wg.Wait()
+
+	expected := []string{"interactive-1", "batch-1", "other-1", "batch-2", "batch-3"}
+	if len(order) != len(expected) {
+		t.Fatalf("order = %v, want %v", order, expected)
+	}
+	for i := range expected {
+		if order[i] != expected[i] {
+			t.Fatalf("order = %v, want %v", order, expected)
+		}
+	}
+}
+
+func TestQueueFull(t *testing.T) {
+	q := New(1, 1)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	go q.Do(context.Background(), "a", PriorityBatch, func() {
+		close(started)
+		<-block
+	})
+	<-started
+
+	done := make(chan error)
+	go func() {
+		done <- q.Do(context.Background(), "b", PriorityBatch, func() {})
+	}()
+	waitForPending(t, q, 1)
+
+	if err := q.Do(context.Background(), "c", PriorityInteractive, func() {}); !errors.Is(err, ErrFull) {
+		t.Errorf("Do() on full queue error = %v, want ErrFull", err)
+	}
+
+	close(block)
+	if err := <-done; err != nil {
+		t.Errorf("Do() for waiting execution error = %v", err)
+	}
+}
+
+func TestQueueCancelWhileWaiting(t *testing.T) {
+	q := New(1, 5)
+
+	block := make(chan struct{})
+	started := make(chan struct{})
+	go q.Do(context.Background(), "a", PriorityBatch, func() {
+		close(started)
+		<-block
+	})
+	<-started
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	ran := false
+	go func() {
+		done <- q.Do(ctx, "b", PriorityBatch, func() { ran = true })
+	}()
+	waitForPending(t, q, 1)
+
+	cancel()
+	if err := <-done; !errors.Is(err, context.Canceled) {
+		t.Errorf("Do() error = %v, want context.Canceled", err)
+	}
+	if ran {
+		t.Error("cancelled execution should not run")
+	}
+	if _, pending := q.Stats(); pending != 0 {
+		t.Errorf("pending = %d after cancel, want 0", pending)
+	}
+
+	close(block)
+	// The worker is free again once the blocker finishes
+	if err := q.Do(context.Background(), "c", PriorityBatch, func() {}); err != nil {
+		t.Errorf("Do() after cancel error = %v", err)
+	}
+}
@@ -0,0 +1,123 @@
+// Package redact masks sensitive values, such as secrets and expanded environment variables,
+// in logs and error messages
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Mask replaces sensitive values
+const Mask = "[REDACTED]"
+
+// MinLength is the shortest value that is masked; shorter values, such as ports or flags,
+// would mask too much unrelated text
+const MinLength = 6
+
+var (
+	mu       sync.RWMutex
+	values   = make(map[string]bool)
+	replacer *strings.Replacer
+)
+
+// Register marks values as sensitive so they are masked from now on, including where they
+// appear JSON-encoded, such as in recorded call arguments or JSON logs
+func Register(sensitive ...string) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	changed := false
+	for _, value := range sensitive {
+		if len(value) < MinLength {
+			continue
+		}
+		for _, form := range append([]string{value}, jsonForms(value)...) {
+			if !values[form] {
+				values[form] = true
+				changed = true
+			}
+		}
+	}
+	if !changed {
+		return
+	}
+
+	// Longer values go first so a value containing another is masked whole
+	sorted := make([]string, 0, len(values))
+	for value := range values {
+		sorted = append(sorted, value)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if len(sorted[i]) != len(sorted[j]) {
+			return len(sorted[i]) > len(sorted[j])
+		}
+		return sorted[i] < sorted[j]
+	})
+	pairs := make([]string, 0, 2*len(sorted))
+	for _, value := range sorted {
+		pairs = append(pairs, value, Mask)
+	}
+	replacer = strings.NewReplacer(pairs...)
+}
+
+// jsonForms returns how a value may appear inside a JSON string, with and without HTML characters
+// escaped, where that differs from the value itself
+func jsonForms(value string) []string {
+	var forms []string
+	for _, escapeHTML := range []bool{true, false} {
+		var buf bytes.Buffer
+		encoder := json.NewEncoder(&buf)
+		encoder.SetEscapeHTML(escapeHTML)
+		if err := encoder.Encode(value); err != nil {
+			continue
+		}
+		// Drop the quotes and trailing newline the encoder adds
+		form := strings.TrimSuffix(buf.String(), "\n")
+		form = form[1 : len(form)-1]
+		if form != value && (len(forms) == 0 || forms[0] != form) {
+			forms = append(forms, form)
+		}
+	}
+	return forms
+}
+
+// Reset forgets every registered value
+func Reset() {
+	mu.Lock()
+	defer mu.Unlock()
+	values = make(map[string]bool)
+	replacer = nil
+}
+
+// String masks every registered value in s
+func String(s string) string {
+	mu.RLock()
+	defer mu.RUnlock()
+	if replacer == nil {
+		return s
+	}
+	return replacer.Replace(s)
+}
+
+// writer masks registered values in everything written through it
+type writer struct {
+	w io.Writer
+}
+
+// Writer wraps w so registered values are masked before being written; intended for log output,
+// where each write is a complete line
+func Writer(w io.Writer) io.Writer {
+	return writer{w: w}
+}
+
+// Write implements io.Writer, reporting the length of p on success even if masking changed it
+func (r writer) Write(p []byte) (int, error) {
+	if _, err := io.WriteString(r.w, String(string(p))); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
@@ -0,0 +1,72 @@
+package redact
+
+import (
+	"bytes"
+	"log"
+	"testing"
+)
+
+func TestString(t *testing.T) {
+	defer Reset()
+	Register("ghp_secret123", "ghp_secret123456", "short", "")
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"no sensitive values", "connection refused", "connection refused"},
+		{"masks value", "auth failed for ghp_secret123", "auth failed for [REDACTED]"},
+		{"masks longer value whole", "token=ghp_secret123456;", "token=[REDACTED];"},
+		{"masks repeated values", "ghp_secret123 ghp_secret123", "[REDACTED] [REDACTED]"},
+		{"ignores short values", "short", "short"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := String(tt.input); got != tt.expected {
+				t.Errorf("String(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+
+	Reset()
+	if got := String("ghp_secret123"); got != "ghp_secret123" {
+		t.Errorf("String() after Reset = %q, want the value unmasked", got)
+	}
+}
+
+func TestString_JSONEncoded(t *testing.T) {
+	defer Reset()
+	Register(`pa"ss\word<1>`)
+
+	tests := []struct {
+		name     string
+		input    string
+		expected string
+	}{
+		{"raw", `secret pa"ss\word<1>`, "secret [REDACTED]"},
+		{"JSON", `{"secret":"pa\"ss\\word\u003c1\u003e"}`, `{"secret":"[REDACTED]"}`},
+		{"JSON without HTML escaping", `{"secret":"pa\"ss\\word<1>"}`, `{"secret":"[REDACTED]"}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := String(tt.input); got != tt.expected {
+				t.Errorf("String(%q) = %q, want %q", tt.input, got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestWriter(t *testing.T) {
+	defer Reset()
+	Register("hunter2hunter2")
+
+	var buf bytes.Buffer
+	logger := log.New(Writer(&buf), "", 0)
+	logger.Printf("launching with password %s", "hunter2hunter2")
+
+	if got := buf.String(); got != "launching with password [REDACTED]\n" {
+		t.Errorf("logged %q, want the password masked", got)
+	}
+}
@@ -0,0 +1,139 @@
+package reload
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/storage"
+	"github.com/dslh/mcp-metatool/internal/tools"
+)
+
+// DefaultInterval is how often Watch checks servers.json for changes
+const DefaultInterval = 2 * time.Second
+
+// Reloader re-reads servers.json and applies server changes to a running metatool
+type Reloader struct {
+	mu       sync.Mutex
+	path     string
+	cfg      *config.Live // shared with the rest of the process, replaced on each reload
+	manager  *proxy.Manager
+	server   *mcp.Server
+	upstream tools.ProxyManager // what proxied tool handlers call through
+	modTime  time.Time
+}
+
+// New creates a reloader for the config file at path, currently loaded as cfg
+func New(path string, cfg *config.Live, manager *proxy.Manager, server *mcp.Server, upstream tools.ProxyManager) *Reloader {
+	r := &Reloader{
+		path:     path,
+		cfg:      cfg,
+		manager:  manager,
+		server:   server,
+		upstream: upstream,
+	}
+	if info, err := storage.Stat(path); err == nil {
+		r.modTime = info.ModTime()
+	}
+	return r
+}
+
+// Reload reads the config file and reconciles upstream connections and proxied tool registrations.
+// An invalid config is rejected and the running configuration is left untouched.
+func (r *Reloader) Reload() (*proxy.ReloadSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.reloadLocked()
+}
+
+// Edit applies a change to the config file as written, saves it and reloads.
+// The file is left untouched if edit fails or the edited config is invalid.
+func (r *Reloader) Edit(edit func(cfg *config.Config) error) (*proxy.ReloadSummary, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	cfg, err := config.LoadRawConfig(r.path)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.MCPServers == nil {
+		cfg.MCPServers = make(map[string]config.MCPServerConfig)
+	}
+	if err := edit(cfg); err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+	if err := config.WriteConfig(r.path, cfg); err != nil {
+		return nil, err
+	}
+
+	return r.reloadLocked()
+}
+
+// reloadLocked implements Reload. The caller must hold r.mu.
+func (r *Reloader) reloadLocked() (*proxy.ReloadSummary, error) {
+	if info, err := storage.Stat(r.path); err == nil {
+		r.modTime = info.ModTime()
+	}
+
+	cfg, err := config.LoadConfig(r.path)
+	if err != nil {
+		return nil, err
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	previous := tools.ProxiedToolNames(r.cfg.Load(), r.manager.GetAllTools())
+	summary := r.manager.Reconcile(cfg)
+	r.cfg.Store(cfg)
+
+	tools.ConfigureToolLimits(cfg.ToolLimits)
+	tools.ConfigureParamLimits(cfg.ParamLimits)
+	tools.ConfigureResultLimits(cfg.ResultLimits)
+	if err := tools.ReregisterProxiedTools(r.server, r.upstream, cfg, previous); err != nil {
+		return summary, fmt.Errorf("failed to register proxied tools: %w", err)
+	}
+	return summary, nil
+}
+
+// Watch reloads the config whenever the file's modification time changes, until ctx is done
+func (r *Reloader) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		info, err := storage.Stat(r.path)
+		if err != nil {
+			continue
+		}
+		r.mu.Lock()
+		changed := !info.ModTime().Equal(r.modTime)
+		r.mu.Unlock()
+		if !changed {
+			continue
+		}
+
+		summary, err := r.Reload()
+		if err != nil {
+			logging.Warnf("Failed to reload %s: %v", r.path, err)
+			continue
+		}
+		logging.Infof("Reloaded %s: %s", r.path, summary)
+	}
+}
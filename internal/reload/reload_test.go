@@ -0,0 +1,137 @@
+package reload
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+)
+
+func TestReload(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+	configPath := filepath.Join(dir, "servers.json")
+
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"first":  {Command: "false"},
+		"second": {Command: "false"},
+	}}
+	manager := proxy.NewManager(cfg, proxy.WithQuietMode())
+	defer manager.Stop()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	live := config.NewLive(cfg)
+	reloader := New(configPath, live, manager, server, manager)
+
+	tests := []struct {
+		name        string
+		content     string
+		wantErr     bool
+		wantServers []string
+	}{
+		{
+			name:        "invalid config is rejected",
+			content:     `{"mcpServers": {"first": {"command": ""}}}`,
+			wantErr:     true,
+			wantServers: []string{"first", "second"},
+		},
+		{
+			name:        "server removed and added",
+			content:     `{"mcpServers": {"first": {"command": "false"}, "third": {"command": "false", "lazyStart": true}}}`,
+			wantServers: []string{"first", "third"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := os.WriteFile(configPath, []byte(tt.content), 0644); err != nil {
+				t.Fatalf("failed to write config: %v", err)
+			}
+
+			_, err := reloader.Reload()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Reload() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			// The shared config reflects what is running
+			current := live.Load()
+			if len(current.MCPServers) != len(tt.wantServers) {
+				t.Fatalf("config servers = %v, want %v", current.MCPServers, tt.wantServers)
+			}
+			for _, name := range tt.wantServers {
+				if _, exists := current.MCPServers[name]; !exists {
+					t.Errorf("config is missing server %s", name)
+				}
+			}
+		})
+	}
+
+	if pending := manager.PendingServers(); len(pending) != 1 || pending[0] != "third" {
+		t.Errorf("PendingServers() = %v, want [third]", pending)
+	}
+
+	// Reloads replace the config rather than changing it under anyone still reading it
+	if len(cfg.MCPServers) != 2 || cfg.MCPServers["second"].Command != "false" {
+		t.Errorf("original config was modified: %v", cfg.MCPServers)
+	}
+}
+
+func TestEdit(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+	t.Setenv("EDIT_TEST_TOKEN", "secret")
+	configPath := filepath.Join(dir, "servers.json")
+
+	content := `{"mcpServers": {"first": {"command": "false", "env": {"TOKEN": "${EDIT_TEST_TOKEN}"}, "lazyStart": true}}}`
+	if err := os.WriteFile(configPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := config.LoadConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadConfig() error = %v", err)
+	}
+	manager := proxy.NewManager(cfg, proxy.WithQuietMode())
+	defer manager.Stop()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	live := config.NewLive(cfg)
+	reloader := New(configPath, live, manager, server, manager)
+
+	summary, err := reloader.Edit(func(raw *config.Config) error {
+		raw.MCPServers["second"] = config.MCPServerConfig{Command: "false", LazyStart: true}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("Edit() error = %v", err)
+	}
+	if len(summary.Added) != 1 || summary.Added[0] != "second" {
+		t.Errorf("Expected second to be added, got %s", summary)
+	}
+
+	// Untouched servers keep their variable references on disk, and are expanded in memory
+	raw, err := config.LoadRawConfig(configPath)
+	if err != nil {
+		t.Fatalf("LoadRawConfig() error = %v", err)
+	}
+	if raw.MCPServers["first"].Env["TOKEN"] != "${EDIT_TEST_TOKEN}" {
+		t.Errorf("Expected variable reference to be preserved, got %q", raw.MCPServers["first"].Env["TOKEN"])
+	}
+	if live.Load().MCPServers["first"].Env["TOKEN"] != "secret" {
+		t.Errorf("Expected expanded token in running config, got %q", live.Load().MCPServers["first"].Env["TOKEN"])
+	}
+
+	// Invalid edits leave the file alone
+	before, _ := os.ReadFile(configPath)
+	if _, err := reloader.Edit(func(raw *config.Config) error {
+		raw.MCPServers["broken"] = config.MCPServerConfig{}
+		return nil
+	}); err == nil {
+		t.Error("Expected error for invalid edit")
+	}
+	after, _ := os.ReadFile(configPath)
+	if string(before) != string(after) {
+		t.Errorf("Expected config file to be unchanged, got %s", after)
+	}
+}
@@ -0,0 +1,150 @@
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// DefaultChunkSize is the most characters of result a bundle chunk holds unless another size is given
+const DefaultChunkSize = 8000
+
+// maxSummaryFields limits how many field names a summary lists
+const maxSummaryFields = 10
+
+// Bundle renders a saved tool's description and latest run as prompt-ready markdown chunks.
+// The first chunk introduces the tool and summarizes its result; every chunk carries a part of the result.
+func Bundle(description string, run *Run, chunkSize int) []string {
+	body, fence := render(run.Result)
+	parts := Chunk(body, chunkSize)
+
+	chunks := make([]string, len(parts))
+	for i, part := range parts {
+		var b strings.Builder
+		if i == 0 {
+			fmt.Fprintf(&b, "# %s\n\n", run.Tool)
+			if description != "" {
+				fmt.Fprintf(&b, "%s\n\n", description)
+			}
+			fmt.Fprintf(&b, "Last run: %s", run.RanAt.Format(time.RFC3339))
+			if len(run.Params) > 0 {
+				params, _ := json.Marshal(run.Params)
+				fmt.Fprintf(&b, " with parameters %s", params)
+			}
+			fmt.Fprintf(&b, "\nSummary: %s\n\n## Result", Summarize(run.Result))
+		} else {
+			fmt.Fprintf(&b, "# %s result", run.Tool)
+		}
+		if len(parts) > 1 {
+			fmt.Fprintf(&b, " (part %d of %d)", i+1, len(parts))
+		}
+		if fence != "" {
+			fmt.Fprintf(&b, "\n\n```%s\n%s\n```\n", fence, part)
+		} else {
+			fmt.Fprintf(&b, "\n\n%s\n", part)
+		}
+		chunks[i] = b.String()
+	}
+	return chunks
+}
+
+// Summarize describes the shape of a result in a short phrase, such as "list of 12 items with fields id, title"
+func Summarize(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "no result"
+	case string:
+		lines := strings.Count(strings.TrimRight(v, "\n"), "\n") + 1
+		return fmt.Sprintf("text of %d characters over %d line(s)", utf8.RuneCountInString(v), lines)
+	case []interface{}:
+		summary := fmt.Sprintf("list of %d item(s)", len(v))
+		fields := make(map[string]bool)
+		for _, item := range v {
+			if object, ok := item.(map[string]interface{}); ok {
+				for key := range object {
+					fields[key] = true
+				}
+			}
+		}
+		if len(fields) > 0 {
+			names := make([]string, 0, len(fields))
+			for name := range fields {
+				names = append(names, name)
+			}
+			summary += " with fields " + listFields(names)
+		}
+		return summary
+	case map[string]interface{}:
+		names := make([]string, 0, len(v))
+		for key, field := range v {
+			if list, ok := field.([]interface{}); ok {
+				key = fmt.Sprintf("%s (%d items)", key, len(list))
+			}
+			names = append(names, key)
+		}
+		return "object with fields " + listFields(names)
+	default:
+		return fmt.Sprintf("the value %v", v)
+	}
+}
+
+// Chunk splits text into pieces of at most size bytes, breaking between lines where it can
+// and never inside a UTF-8 character
+func Chunk(text string, size int) []string {
+	var chunks []string
+	var current strings.Builder
+	flush := func() {
+		chunks = append(chunks, strings.TrimSuffix(current.String(), "\n"))
+		current.Reset()
+	}
+
+	for _, line := range strings.SplitAfter(text, "\n") {
+		for len(line) > size {
+			if current.Len() > 0 {
+				flush()
+			}
+			cut := size
+			for cut > 0 && !utf8.RuneStart(line[cut]) {
+				cut--
+			}
+			if cut == 0 {
+				cut = size
+			}
+			current.WriteString(line[:cut])
+			flush()
+			line = line[cut:]
+		}
+		if current.Len() > 0 && current.Len()+len(line) > size {
+			flush()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 || len(chunks) == 0 {
+		flush()
+	}
+	return chunks
+}
+
+// render formats a result for a bundle, returning the code fence language to wrap it in, if any
+func render(value interface{}) (string, string) {
+	if text, ok := value.(string); ok {
+		return text, ""
+	}
+	data, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return fmt.Sprint(value), ""
+	}
+	return string(data), "json"
+}
+
+// listFields joins sorted field names, eliding any past maxSummaryFields
+func listFields(names []string) string {
+	sort.Strings(names)
+	if len(names) > maxSummaryFields {
+		return fmt.Sprintf("%s and %d more", strings.Join(names[:maxSummaryFields], ", "), len(names)-maxSummaryFields)
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,89 @@
+package results
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  string
+	}{
+		{"nil", nil, "no result"},
+		{"text", "line one\nline two\n", "text of 18 characters over 2 line(s)"},
+		{"number", 42.0, "the value 42"},
+		{"list of objects", []interface{}{map[string]interface{}{"id": 1.0, "title": "a"}, map[string]interface{}{"id": 2.0, "state": "open"}}, "list of 2 item(s) with fields id, state, title"},
+		{"list of scalars", []interface{}{1.0, 2.0}, "list of 2 item(s)"},
+		{"object", map[string]interface{}{"total": 3.0, "issues": []interface{}{1.0, 2.0, 3.0}}, "object with fields issues (3 items), total"},
+		{"many fields", map[string]interface{}{"a": 1, "b": 1, "c": 1, "d": 1, "e": 1, "f": 1, "g": 1, "h": 1, "i": 1, "j": 1, "k": 1, "l": 1}, "object with fields a, b, c, d, e, f, g, h, i, j and 2 more"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Summarize(tt.value); got != tt.want {
+				t.Errorf("Summarize() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestChunk(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		size int
+		want []string
+	}{
+		{"fits", "a\nb", 10, []string{"a\nb"}},
+		{"empty", "", 10, []string{""}},
+		{"breaks between lines", "aaaa\nbbbb\ncccc", 10, []string{"aaaa\nbbbb", "cccc"}},
+		{"splits long lines", "abcdefghij\nk", 4, []string{"abcd", "efgh", "ij\nk"}},
+		{"keeps characters whole", "ééé", 3, []string{"é", "é", "é"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Chunk(tt.text, tt.size)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") || len(got) != len(tt.want) {
+				t.Errorf("Chunk() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBundle(t *testing.T) {
+	run := &Run{
+		Tool:   "weekly_report",
+		Params: map[string]interface{}{"team": "web"},
+		Result: []interface{}{"closed 4 issues", "opened 2 issues"},
+		RanAt:  time.Date(2026, 10, 12, 9, 0, 0, 0, time.UTC),
+	}
+
+	chunks := Bundle("Summarize the week's issues", run, DefaultChunkSize)
+	if len(chunks) != 1 {
+		t.Fatalf("Bundle() returned %d chunks, want 1", len(chunks))
+	}
+	for _, want := range []string{
+		"# weekly_report\n\nSummarize the week's issues",
+		`Last run: 2026-10-12T09:00:00Z with parameters {"team":"web"}`,
+		"Summary: list of 2 item(s)",
+		"## Result\n\n```json\n[\n  \"closed 4 issues\"",
+	} {
+		if !strings.Contains(chunks[0], want) {
+			t.Errorf("Bundle() = %q, want it to contain %q", chunks[0], want)
+		}
+	}
+
+	run.Result = strings.Repeat("a line of text\n", 50)
+	chunks = Bundle("", run, 300)
+	if len(chunks) != 3 {
+		t.Fatalf("Bundle() returned %d chunks, want 3", len(chunks))
+	}
+	if !strings.Contains(chunks[0], "## Result (part 1 of 3)\n\na line of text") {
+		t.Errorf("first chunk = %q", chunks[0])
+	}
+	if !strings.HasPrefix(chunks[2], "# weekly_report result (part 3 of 3)\n\n") || strings.Contains(chunks[2], "```") {
+		t.Errorf("last chunk = %q", chunks[2])
+	}
+}
@@ -0,0 +1,73 @@
+package results
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// Run is the latest successful run of a saved tool
+type Run struct {
+	Tool   string                 `json:"tool"`
+	Params map[string]interface{} `json:"params,omitempty"`
+	Result interface{}            `json:"result"`
+	RanAt  time.Time              `json:"ranAt"`
+}
+
+// Record stores a saved tool's result, replacing the one recorded for its previous run
+func Record(tool string, params map[string]interface{}, result interface{}) error {
+	path, err := resultPath(tool)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(Run{Tool: tool, Params: params, Result: result, RanAt: time.Now().UTC()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal result: %w", err)
+	}
+	if err := storage.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write result: %w", err)
+	}
+	return nil
+}
+
+// Latest loads the result recorded for a saved tool's most recent successful run
+func Latest(tool string) (*Run, error) {
+	path, err := resultPath(tool)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := storage.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("tool '%s' has no recorded result; run it first", tool)
+		}
+		return nil, fmt.Errorf("failed to read result: %w", err)
+	}
+
+	var run Run
+	if err := json.Unmarshal(data, &run); err != nil {
+		return nil, fmt.Errorf("failed to parse result: %w", err)
+	}
+	return &run, nil
+}
+
+// resultPath returns the file holding a tool's latest result
+func resultPath(tool string) (string, error) {
+	if tool == "" || strings.ContainsAny(tool, `/\`) || strings.Contains(tool, "..") {
+		return "", fmt.Errorf("invalid tool name '%s'", tool)
+	}
+
+	dir, err := paths.GetResultsDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, tool+".json"), nil
+}
@@ -0,0 +1,36 @@
+package results
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestRecordAndLatest(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	if _, err := Latest("report"); err == nil || !strings.Contains(err.Error(), "no recorded result") {
+		t.Errorf("Latest() before any run error = %v", err)
+	}
+
+	Record("report", map[string]interface{}{"team": "web"}, "first")
+	if err := Record("report", map[string]interface{}{"team": "api"}, []interface{}{"second"}); err != nil {
+		t.Fatalf("Record() error = %v", err)
+	}
+
+	run, err := Latest("report")
+	if err != nil {
+		t.Fatalf("Latest() error = %v", err)
+	}
+	if run.Tool != "report" || run.Params["team"] != "api" || run.RanAt.IsZero() {
+		t.Errorf("Latest() = %+v, want the second run", run)
+	}
+	if list, ok := run.Result.([]interface{}); !ok || len(list) != 1 || list[0] != "second" {
+		t.Errorf("Latest() result = %#v, want [second]", run.Result)
+	}
+
+	for _, name := range []string{"", "../report", `a\b`} {
+		if err := Record(name, nil, "x"); err == nil {
+			t.Errorf("Record(%q) should fail", name)
+		}
+	}
+}
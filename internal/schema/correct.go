@@ -0,0 +1,180 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// Correct adapts arguments to fit a tool's input schema, coercing values whose type is obviously
+// wrong (such as "5" for an integer) and dropping properties the schema doesn't declare. It returns
+// the corrected arguments and a description of each change; the original arguments are not modified.
+func Correct(schema *jsonschema.Schema, arguments map[string]interface{}) (map[string]interface{}, []string) {
+	if schema == nil {
+		return arguments, nil
+	}
+	var corrections []string
+	corrected := correctObject(schema, arguments, "", &corrections)
+	return corrected, corrections
+}
+
+// correctObject corrects each property of an object against the schema's property definitions
+func correctObject(schema *jsonschema.Schema, object map[string]interface{}, path string, corrections *[]string) map[string]interface{} {
+	corrected := make(map[string]interface{}, len(object))
+	keys := make([]string, 0, len(object))
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		value := object[key]
+		property, declared := schema.Properties[key]
+		if !declared {
+			if schema.Properties != nil && !allowsAdditional(schema.AdditionalProperties) {
+				*corrections = append(*corrections, fmt.Sprintf("dropped unknown field %q", joinPath(path, key)))
+				continue
+			}
+			property = schema.AdditionalProperties
+		}
+		corrected[key] = correctValue(property, value, joinPath(path, key), corrections)
+	}
+	return corrected
+}
+
+// correctValue coerces a value to the type its schema expects, where the conversion is unambiguous
+func correctValue(schema *jsonschema.Schema, value interface{}, path string, corrections *[]string) interface{} {
+	if schema == nil {
+		return value
+	}
+	types := schemaTypes(schema)
+
+	if len(types) > 0 && !slices.Contains(types, jsonType(value)) && !(slices.Contains(types, "number") && jsonType(value) == "integer") {
+		for _, target := range types {
+			if coerced, ok := coerce(value, target); ok {
+				*corrections = append(*corrections, fmt.Sprintf("converted %q from %s to %s", path, jsonType(value), target))
+				value = coerced
+				break
+			}
+		}
+	}
+
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return correctObject(schema, v, path, corrections)
+	case []interface{}:
+		if schema.Items == nil {
+			return v
+		}
+		items := make([]interface{}, len(v))
+		for i, item := range v {
+			items[i] = correctValue(schema.Items, item, fmt.Sprintf("%s[%d]", path, i), corrections)
+		}
+		return items
+	}
+	return value
+}
+
+// coerce converts a value to a JSON type, reporting false if there's no obvious conversion
+func coerce(value interface{}, target string) (interface{}, bool) {
+	switch target {
+	case "string":
+		switch v := value.(type) {
+		case float64:
+			return strconv.FormatFloat(v, 'f', -1, 64), true
+		case bool:
+			return strconv.FormatBool(v), true
+		}
+	case "integer":
+		// Fractional numbers are left alone; rounding would change the meaning
+		if v, ok := value.(string); ok {
+			if n, err := strconv.ParseInt(strings.TrimSpace(v), 10, 64); err == nil {
+				return float64(n), true
+			}
+		}
+	case "number":
+		if v, ok := value.(string); ok {
+			if n, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err == nil {
+				return n, true
+			}
+		}
+	case "boolean":
+		if v, ok := value.(string); ok {
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true":
+				return true, true
+			case "false":
+				return false, true
+			}
+		}
+	case "array":
+		if v, ok := value.(string); ok {
+			var parsed []interface{}
+			if err := json.Unmarshal([]byte(v), &parsed); err == nil {
+				return parsed, true
+			}
+		}
+		if value != nil {
+			return []interface{}{value}, true
+		}
+	case "object":
+		if v, ok := value.(string); ok {
+			var parsed map[string]interface{}
+			if err := json.Unmarshal([]byte(v), &parsed); err == nil && parsed != nil {
+				return parsed, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// schemaTypes lists the JSON types a schema accepts, or nothing if it doesn't restrict them
+func schemaTypes(schema *jsonschema.Schema) []string {
+	if schema.Type != "" {
+		return []string{schema.Type}
+	}
+	return schema.Types
+}
+
+// jsonType names the JSON type of a decoded value
+func jsonType(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case string:
+		return "string"
+	case float64:
+		if v == float64(int64(v)) {
+			return "integer"
+		}
+		return "number"
+	case int, int32, int64:
+		return "integer"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return reflect.TypeOf(value).String()
+}
+
+// allowsAdditional reports whether an additionalProperties schema explicitly admits undeclared properties
+func allowsAdditional(schema *jsonschema.Schema) bool {
+	return schema != nil && !reflect.DeepEqual(schema, &jsonschema.Schema{Not: &jsonschema.Schema{}})
+}
+
+// joinPath extends a dotted property path
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
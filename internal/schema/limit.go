@@ -0,0 +1,99 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+)
+
+// Size returns the length in bytes of the schema's JSON encoding
+func Size(schema *jsonschema.Schema) int {
+	if schema == nil {
+		return 0
+	}
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// Limit shrinks a schema until its JSON encoding fits in maxBytes, and reports whether it was reduced.
+// It first drops descriptions and examples, then nested property details, and finally falls back to
+// a bare object schema whose description notes the truncation where room allows. A maxBytes of 0 disables the limit.
+func Limit(schema *jsonschema.Schema, maxBytes int) (*jsonschema.Schema, bool) {
+	if schema == nil || maxBytes <= 0 || Size(schema) <= maxBytes {
+		return schema, false
+	}
+	originalSize := Size(schema)
+
+	// Pass 1: strip documentation from every nested schema
+	stripped := stripDocs(schema)
+	if Size(stripped) <= maxBytes {
+		return stripped, true
+	}
+
+	// Pass 2: keep only the top-level property names and types
+	shallow := &jsonschema.Schema{
+		Type:     schema.Type,
+		Types:    schema.Types,
+		Required: schema.Required,
+	}
+	if len(schema.Properties) > 0 {
+		shallow.Properties = make(map[string]*jsonschema.Schema, len(schema.Properties))
+		for name, property := range schema.Properties {
+			shallow.Properties[name] = &jsonschema.Schema{}
+			if property != nil {
+				shallow.Properties[name].Type = property.Type
+				shallow.Properties[name].Types = property.Types
+			}
+		}
+	}
+	if Size(shallow) <= maxBytes {
+		return shallow, true
+	}
+
+	// Pass 3: an open object schema noting what was removed, if the note itself fits
+	placeholder := &jsonschema.Schema{
+		Type:        "object",
+		Description: fmt.Sprintf("[schema truncated from %d bytes]", originalSize),
+	}
+	if Size(placeholder) > maxBytes {
+		placeholder.Description = ""
+	}
+	return placeholder, true
+}
+
+// stripDocs returns a copy of the schema without descriptions, titles or examples at any depth
+func stripDocs(schema *jsonschema.Schema) *jsonschema.Schema {
+	if schema == nil {
+		return nil
+	}
+
+	stripped := *schema
+	stripped.Description = ""
+	stripped.Title = ""
+	stripped.Examples = nil
+
+	if schema.Properties != nil {
+		stripped.Properties = make(map[string]*jsonschema.Schema, len(schema.Properties))
+		for name, property := range schema.Properties {
+			stripped.Properties[name] = stripDocs(property)
+		}
+	}
+	if schema.Items != nil {
+		stripped.Items = stripDocs(schema.Items)
+	}
+	if schema.AdditionalProperties != nil {
+		stripped.AdditionalProperties = stripDocs(schema.AdditionalProperties)
+	}
+	if schema.Defs != nil {
+		stripped.Defs = make(map[string]*jsonschema.Schema, len(schema.Defs))
+		for name, def := range schema.Defs {
+			stripped.Defs[name] = stripDocs(def)
+		}
+	}
+
+	return &stripped
+}
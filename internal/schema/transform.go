@@ -1,9 +1,9 @@
 package schema
 
 import (
-	"log"
-
 	"github.com/google/jsonschema-go/jsonschema"
+
+	"github.com/dslh/mcp-metatool/internal/logging"
 )
 
 // Transform converts JSON Schema draft-07 to draft-2020-12 for compatibility
@@ -49,7 +49,7 @@ func SafeTransform(schema *jsonschema.Schema, context string) *jsonschema.Schema
 	var result *jsonschema.Schema
 	defer func() {
 		if r := recover(); r != nil {
-			log.Printf("Warning: Schema transformation failed for %s: %v. Proceeding without schema validation.", context, r)
+			logging.Warnf("Schema transformation failed for %s: %v. Proceeding without schema validation.", context, r)
 			result = nil
 		}
 	}()
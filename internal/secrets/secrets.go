@@ -0,0 +1,145 @@
+package secrets
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/redact"
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// fileMode keeps the secrets file readable only by its owner
+const fileMode = 0600
+
+// mu serializes read-modify-write cycles of the secrets file
+var mu sync.Mutex
+
+// Get returns the value of a secret
+func Get(name string) (string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	values, err := load()
+	if err != nil {
+		return "", err
+	}
+	value, exists := values[name]
+	if !exists {
+		return "", fmt.Errorf("secret '%s' is not set", name)
+	}
+	return value, nil
+}
+
+// Set stores a secret, replacing any previous value
+func Set(name, value string) error {
+	if err := ValidateName(name); err != nil {
+		return err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	values, err := load()
+	if err != nil {
+		return err
+	}
+	values[name] = value
+	redact.Register(value)
+	return save(values)
+}
+
+// Delete removes a secret
+func Delete(name string) error {
+	mu.Lock()
+	defer mu.Unlock()
+
+	values, err := load()
+	if err != nil {
+		return err
+	}
+	if _, exists := values[name]; !exists {
+		return fmt.Errorf("secret '%s' is not set", name)
+	}
+	delete(values, name)
+	return save(values)
+}
+
+// Names lists the names of stored secrets, sorted; values are never listed
+func Names() ([]string, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	values, err := load()
+	if err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// ValidateName checks that a secret name uses letters, digits, underscores and dashes
+func ValidateName(name string) error {
+	if name == "" {
+		return fmt.Errorf("secret name cannot be empty")
+	}
+	for _, r := range name {
+		if !(r == '_' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return fmt.Errorf("invalid secret name %q: use letters, digits, underscores and dashes", name)
+		}
+	}
+	return nil
+}
+
+// load reads every stored secret; a missing file means no secrets.
+// Like servers.json, the file is read from disk even in ephemeral mode.
+func load() (map[string]string, error) {
+	path, err := paths.GetSecretsPath()
+	if err != nil {
+		return nil, err
+	}
+
+	values := make(map[string]string)
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return values, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read secrets: %w", err)
+	}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for _, value := range values {
+		redact.Register(value)
+	}
+	return values, nil
+}
+
+// save writes every secret, readable only by the current user
+func save(values map[string]string) error {
+	path, err := paths.GetSecretsPath()
+	if err != nil {
+		return err
+	}
+
+	data, err := json.MarshalIndent(values, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode secrets: %w", err)
+	}
+	if err := storage.WriteDiskFile(path, data, fileMode); err != nil {
+		return fmt.Errorf("failed to write secrets: %w", err)
+	}
+	// WriteFile only applies the mode to new files, so tighten it in case the file was created by hand
+	if err := os.Chmod(path, fileMode); err != nil {
+		return fmt.Errorf("failed to restrict secrets file permissions: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+package secrets
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+func TestSetGetDelete(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+
+	if _, err := Get("github_token"); err == nil {
+		t.Error("Get() of a missing secret should fail")
+	}
+
+	if err := Set("github_token", "ghp_123"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := Set("slack-hook", "https://hooks.example.com/abc"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := Set("github_token", "ghp_456"); err != nil {
+		t.Fatalf("Set() replacing a secret error = %v", err)
+	}
+
+	value, err := Get("github_token")
+	if err != nil || value != "ghp_456" {
+		t.Errorf("Get() = %q, %v, want ghp_456", value, err)
+	}
+
+	names, err := Names()
+	if err != nil {
+		t.Fatalf("Names() error = %v", err)
+	}
+	if want := []string{"github_token", "slack-hook"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Names() = %v, want %v", names, want)
+	}
+
+	info, err := os.Stat(filepath.Join(dir, "secrets.json"))
+	if err != nil {
+		t.Fatalf("secrets file not written: %v", err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("secrets file mode = %o, want 600", perm)
+	}
+
+	if err := Delete("github_token"); err != nil {
+		t.Fatalf("Delete() error = %v", err)
+	}
+	if _, err := Get("github_token"); err == nil {
+		t.Error("Get() of a deleted secret should fail")
+	}
+	if err := Delete("github_token"); err == nil {
+		t.Error("Delete() of a missing secret should fail")
+	}
+}
+
+func TestSetEphemeral(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+	storage.UseMemory()
+	defer storage.UseDisk()
+
+	if err := Set("github_token", "ghp_123"); !errors.Is(err, storage.ErrEphemeral) {
+		t.Errorf("Set() in ephemeral mode error = %v, want ErrEphemeral", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("Expected nothing on disk in ephemeral mode, got %v", entries)
+	}
+}
+
+func TestValidateName(t *testing.T) {
+	tests := []struct {
+		name    string
+		wantErr bool
+	}{
+		{"API_KEY", false},
+		{"slack-hook-2", false},
+		{"", true},
+		{"has space", true},
+		{"../escape", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateName(tt.name); (err != nil) != tt.wantErr {
+				t.Errorf("ValidateName(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+		})
+	}
+}
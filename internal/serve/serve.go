@@ -0,0 +1,121 @@
+package serve
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"flag"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/metrics"
+)
+
+// TokenEnvVar supplies the bearer token when --token isn't given
+const TokenEnvVar = "MCP_METATOOL_HTTP_TOKEN"
+
+const usage = "usage: mcp-metatool serve [--http ADDR] [--token TOKEN]"
+
+// Options controls how the metatool is served
+type Options struct {
+	// HTTPAddr is the address to listen on; empty means serve over stdio
+	HTTPAddr string
+	// Token, if set, must be presented as "Authorization: Bearer <token>" on every HTTP request
+	Token string
+}
+
+// ParseArgs parses the arguments of the serve subcommand
+func ParseArgs(args []string) (*Options, error) {
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	httpAddr := flags.String("http", "", "serve Streamable HTTP and SSE on this address (e.g. :8080) instead of stdio")
+	token := flags.String("token", "", "require this bearer token on HTTP requests (default $"+TokenEnvVar+")")
+	if err := flags.Parse(args); err != nil || flags.NArg() > 0 {
+		return nil, fmt.Errorf(usage)
+	}
+
+	opts := &Options{HTTPAddr: *httpAddr, Token: *token}
+	if opts.Token == "" {
+		opts.Token = os.Getenv(TokenEnvVar)
+	}
+	return opts, nil
+}
+
+// NewMux returns the HTTP routes for the server: Streamable HTTP at /mcp, SSE at /sse,
+// and Prometheus metrics at /metrics
+func NewMux(server *mcp.Server, opts Options) *http.ServeMux {
+	getServer := func(*http.Request) *mcp.Server { return server }
+
+	mux := http.NewServeMux()
+	mux.Handle("/mcp", RequireToken(opts.Token, mcp.NewStreamableHTTPHandler(getServer, nil)))
+	mux.Handle("/sse", RequireToken(opts.Token, mcp.NewSSEHandler(getServer)))
+	mux.Handle("/metrics", RequireToken(opts.Token, metricsHandler(server)))
+	return mux
+}
+
+// metricsHandler serves the metrics, counting the server's sessions as they are scraped
+func metricsHandler(server *mcp.Server) http.Handler {
+	handler := metrics.Handler()
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		sessions := 0
+		for range server.Sessions() {
+			sessions++
+		}
+		metrics.ActiveSessions.Set(float64(sessions))
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// RequireToken rejects requests without the bearer token; an empty token disables the check
+func RequireToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+
+	expected := []byte("Bearer " + token)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided := []byte(strings.TrimSpace(r.Header.Get("Authorization")))
+		if subtle.ConstantTimeCompare(provided, expected) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mcp-metatool"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// ListenAndServe serves the handler over HTTP until ctx is cancelled
+func ListenAndServe(ctx context.Context, handler http.Handler, opts Options) error {
+	httpServer := &http.Server{
+		Addr:              opts.HTTPAddr,
+		Handler:           handler,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.ListenAndServe()
+	}()
+
+	if opts.Token == "" {
+		logging.Warnf("Serving over HTTP without authentication; set --token or %s", TokenEnvVar)
+	}
+	logging.Infof("Serving MCP over HTTP on %s (Streamable HTTP at /mcp, SSE at /sse, metrics at /metrics)", opts.HTTPAddr)
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := httpServer.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			return err
+		}
+		return nil
+	}
+}
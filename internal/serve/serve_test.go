@@ -0,0 +1,143 @@
+package serve
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// bearerTransport adds an Authorization header to every request
+type bearerTransport struct {
+	token string
+}
+
+func (b bearerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+func newTestServer() *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "ping", Description: "Ping"}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "pong"}}}, nil, nil
+	})
+	return server
+}
+
+func TestParseArgs(t *testing.T) {
+	t.Setenv(TokenEnvVar, "from-env")
+
+	opts, err := ParseArgs([]string{"--http", ":9000"})
+	if err != nil {
+		t.Fatalf("ParseArgs() error = %v", err)
+	}
+	if opts.HTTPAddr != ":9000" || opts.Token != "from-env" {
+		t.Errorf("Unexpected options: %+v", opts)
+	}
+
+	opts, _ = ParseArgs([]string{"--http", ":9000", "--token", "flag"})
+	if opts.Token != "flag" {
+		t.Errorf("Expected --token to take precedence, got %q", opts.Token)
+	}
+
+	opts, _ = ParseArgs(nil)
+	if opts.HTTPAddr != "" {
+		t.Errorf("Expected stdio by default, got %q", opts.HTTPAddr)
+	}
+
+	if _, err := ParseArgs([]string{"extra"}); err == nil {
+		t.Error("Expected usage error for positional arguments")
+	}
+}
+
+func TestNewMux_RequiresToken(t *testing.T) {
+	httpServer := httptest.NewServer(NewMux(newTestServer(), Options{Token: "secret"}))
+	defer httpServer.Close()
+
+	for _, path := range []string{"/mcp", "/sse", "/metrics"} {
+		resp, err := http.Get(httpServer.URL + path)
+		if err != nil {
+			t.Fatalf("GET %s error = %v", path, err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusUnauthorized {
+			t.Errorf("Expected 401 for %s without token, got %d", path, resp.StatusCode)
+		}
+	}
+
+	req, _ := http.NewRequest(http.MethodGet, httpServer.URL+"/mcp", nil)
+	req.Header.Set("Authorization", "Bearer wrong")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET error = %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Expected 401 for wrong token, got %d", resp.StatusCode)
+	}
+}
+
+func TestNewMux_StreamableClient(t *testing.T) {
+	httpServer := httptest.NewServer(NewMux(newTestServer(), Options{Token: "secret"}))
+	defer httpServer.Close()
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   httpServer.URL + "/mcp",
+		HTTPClient: &http.Client{Transport: bearerTransport{token: "secret"}},
+		MaxRetries: -1,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer session.Close()
+
+	result, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "ping"})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; text != "pong" {
+		t.Errorf("Expected pong, got %q", text)
+	}
+}
+
+func TestNewMux_Metrics(t *testing.T) {
+	httpServer := httptest.NewServer(NewMux(newTestServer(), Options{Token: "secret"}))
+	defer httpServer.Close()
+
+	ctx := context.Background()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	httpClient := &http.Client{Transport: bearerTransport{token: "secret"}}
+	session, err := client.Connect(ctx, &mcp.StreamableClientTransport{
+		Endpoint:   httpServer.URL + "/mcp",
+		HTTPClient: httpClient,
+		MaxRetries: -1,
+	}, nil)
+	if err != nil {
+		t.Fatalf("Connect() error = %v", err)
+	}
+	defer session.Close()
+
+	resp, err := httpClient.Get(httpServer.URL + "/metrics")
+	if err != nil {
+		t.Fatalf("GET /metrics error = %v", err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected 200 for /metrics, got %d", resp.StatusCode)
+	}
+	if !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/plain") {
+		t.Errorf("Expected text/plain, got %q", resp.Header.Get("Content-Type"))
+	}
+	if !strings.Contains(string(body), "\nmcp_metatool_active_sessions 1\n") {
+		t.Errorf("Expected one active session, got:\n%s", body)
+	}
+}
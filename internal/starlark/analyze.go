@@ -0,0 +1,74 @@
+package starlark
+
+import (
+	"go.starlark.net/resolve"
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+)
+
+// Reference is a name used by code that the code doesn't define and isn't predeclared,
+// such as a server namespace in github.get_issue(...)
+type Reference struct {
+	Name string // the undefined name, e.g. github
+	Attr string // the attribute taken from it, e.g. get_issue; empty if the name is used on its own
+	Line int
+}
+
+// Analyze compiles code without running it, returning the names it uses besides its own and those
+// known reports as predeclared. These are calls to upstream servers, or mistakes.
+func Analyze(code string, known func(name string) bool) ([]Reference, error) {
+	fileOptions := newFileOptions()
+	// Every unknown name is accepted as predeclared, so it can be reported as a reference
+	// rather than failing compilation
+	anyName := func(string) bool { return true }
+
+	var root syntax.Node
+	if isMultiLineCode(code) {
+		f, err := fileOptions.Parse("<eval>", code, 0)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := starlark.FileProgram(f, anyName); err != nil {
+			return nil, err
+		}
+		root = f
+	} else {
+		expr, err := fileOptions.ParseExpr("<eval>", code, 0)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := resolve.ExprOptions(fileOptions, expr, anyName, starlark.Universe.Has); err != nil {
+			return nil, err
+		}
+		root = expr
+	}
+
+	unknown := func(id *syntax.Ident) bool {
+		binding, ok := id.Binding.(*resolve.Binding)
+		return ok && binding.Scope == resolve.Predeclared && !known(id.Name) && !starlark.Universe.Has(id.Name)
+	}
+	var references []Reference
+	attributed := make(map[*syntax.Ident]bool)
+	syntax.Walk(root, func(n syntax.Node) bool {
+		switch n := n.(type) {
+		case *syntax.DotExpr:
+			if id, ok := n.X.(*syntax.Ident); ok && unknown(id) {
+				references = append(references, Reference{Name: id.Name, Attr: n.Name.Name, Line: int(id.NamePos.Line)})
+				attributed[id] = true
+			}
+		case *syntax.Ident:
+			if !attributed[n] && unknown(n) {
+				references = append(references, Reference{Name: n.Name, Line: int(n.NamePos.Line)})
+			}
+		}
+		return true
+	})
+	return references, nil
+}
+
+// CheckSyntax compiles code without running it, as it would be compiled when called, reporting
+// any syntax error. Names it doesn't define are assumed to be predeclared.
+func CheckSyntax(code string) error {
+	_, err := Analyze(code, func(string) bool { return true })
+	return err
+}
@@ -0,0 +1,93 @@
+package starlark
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	known := func(name string) bool { return name == "params" || name == "json" }
+	tests := []struct {
+		name      string
+		code      string
+		expected  []Reference
+		wantError string
+	}{
+		{
+			name:     "expression",
+			code:     `github.get_issue(number=params["n"])`,
+			expected: []Reference{{Name: "github", Attr: "get_issue", Line: 1}},
+		},
+		{
+			name: "program",
+			code: "issue = github.get_issue(number=1)\nnames = [c.strip() for c in issue.get('content', [])]\nslack.post(text=json.encode(names))\nresult = missing",
+			expected: []Reference{
+				{Name: "github", Attr: "get_issue", Line: 1},
+				{Name: "slack", Attr: "post", Line: 3},
+				{Name: "missing", Line: 4},
+			},
+		},
+		{
+			name: "locals and functions",
+			code: "def f(x):\n    return len(x)\nresult = f([1])",
+		},
+		{
+			name:      "syntax error",
+			code:      "def f(:\n    pass",
+			wantError: "got ':', want ')'",
+		},
+		{
+			name:      "invalid statement",
+			code:      "result = 1\nbreak",
+			wantError: "break not in a loop",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			references, err := Analyze(tt.code, known)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("Analyze() error = %v, want it to contain %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Analyze() error = %v", err)
+			}
+			if !reflect.DeepEqual(references, tt.expected) {
+				t.Errorf("Analyze() = %+v, want %+v", references, tt.expected)
+			}
+		})
+	}
+}
+
+func TestCheckSyntax(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		wantError string
+	}{
+		{"expression", `github.get_issue(number=params["n"])`, ""},
+		{"program", "issue = github.get_issue(number=1)\nresult = issue", ""},
+		{"syntax error", "def main(:\n    pass", "<eval>:1:11"},
+		{"statement on one line", "result = 1", "got '=' after expression"},
+		{"invalid statement", "result = 1\nbreak", "break not in a loop"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckSyntax(tt.code)
+			if tt.wantError == "" {
+				if err != nil {
+					t.Errorf("CheckSyntax() error = %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+				t.Errorf("CheckSyntax() error = %v, want it to contain %q", err, tt.wantError)
+			}
+		})
+	}
+}
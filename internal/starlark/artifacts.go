@@ -0,0 +1,46 @@
+package starlark
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+
+	"github.com/dslh/mcp-metatool/internal/artifacts"
+)
+
+// artifactsLocalKey is the thread-local key under which published artifacts are collected
+const artifactsLocalKey = "artifacts"
+
+// newPublishArtifactBuiltin creates the publish_artifact builtin, which stores content
+// as a downloadable artifact and records it on the executing thread
+func newPublishArtifactBuiltin() *starlark.Builtin {
+	return starlark.NewBuiltin("publish_artifact", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var name string
+		var content starlark.Value
+		mimeType := "text/plain"
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "name", &name, "content", &content, "mime?", &mimeType); err != nil {
+			return nil, err
+		}
+
+		var data string
+		switch c := content.(type) {
+		case starlark.String:
+			data = string(c)
+		case starlark.Bytes:
+			data = string(c)
+		default:
+			return nil, fmt.Errorf("%s: content must be a string or bytes, got %s", fn.Name(), content.Type())
+		}
+
+		artifact, err := artifacts.Publish(name, data, mimeType)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+		}
+
+		if published, ok := thread.Local(artifactsLocalKey).(*[]*artifacts.Artifact); ok {
+			*published = append(*published, artifact)
+		}
+
+		return starlark.String(artifact.URI), nil
+	})
+}
@@ -0,0 +1,100 @@
+package starlark
+
+import (
+	"strings"
+	"testing"
+
+	"go.starlark.net/starlark"
+
+	"github.com/dslh/mcp-metatool/internal/artifacts"
+)
+
+func TestPublishArtifact(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	code := `uri = publish_artifact("report.csv", "a,b\n1,2\n", mime="text/csv")
+result = uri`
+
+	result, err := Execute(code, nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("Unexpected execution error: %s", result.Error)
+	}
+
+	if result.Result != "metatool://artifacts/report.csv" {
+		t.Errorf("Expected artifact URI as result, got %v", result.Result)
+	}
+
+	if len(result.Artifacts) != 1 {
+		t.Fatalf("Expected 1 published artifact, got %d", len(result.Artifacts))
+	}
+	if result.Artifacts[0].MIMEType != "text/csv" {
+		t.Errorf("Expected text/csv, got %s", result.Artifacts[0].MIMEType)
+	}
+
+	_, content, err := artifacts.Read("report.csv")
+	if err != nil {
+		t.Fatalf("Read() error = %v", err)
+	}
+	if string(content) != "a,b\n1,2\n" {
+		t.Errorf("Unexpected artifact content: %q", content)
+	}
+}
+
+func TestPublishArtifact_Errors(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	tests := []struct {
+		name    string
+		code    string
+		wantErr string
+	}{
+		{"invalid name", `publish_artifact("../escape", "x")`, "invalid character"},
+		{"non-string content", `publish_artifact("data.json", {"a": 1})`, "must be a string or bytes"},
+		{"missing content", `publish_artifact("data.json")`, "missing argument"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Execute(tt.code, nil)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !strings.Contains(result.Error, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %q", tt.wantErr, result.Error)
+			}
+		})
+	}
+}
+
+func TestPublishArtifactReadOnly(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	result, err := Execute(`publish_artifact("report.csv", "a,b")`, nil, WithReadOnly())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(result.Error, "undefined: publish_artifact") {
+		t.Errorf("Expected publish_artifact to be undefined in read-only mode, got error %q", result.Error)
+	}
+	if _, _, err := artifacts.Read("report.csv"); err == nil {
+		t.Error("Expected no artifact to be published in read-only mode")
+	}
+}
+
+func TestNotifyReadOnly(t *testing.T) {
+	notify := starlark.NewBuiltin("notify", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		t.Error("Expected notify not to be called in read-only mode")
+		return starlark.None, nil
+	})
+
+	result, err := Execute(`notify("done")`, nil, WithModule("notify", notify), WithReadOnly())
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(result.Error, "undefined: notify") {
+		t.Errorf("Expected notify to be undefined in read-only mode, got error %q", result.Error)
+	}
+}
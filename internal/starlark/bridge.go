@@ -1,6 +1,8 @@
 package starlark
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"strings"
 
@@ -17,7 +19,8 @@ type ProxyManager = proxy.ProxyManager
 type ServerNamespace struct {
 	serverName   string
 	proxyManager ProxyManager
-	tools        map[string]*mcp.Tool // toolName -> tool definition
+	tools        map[string]*mcp.Tool // attribute name (the tool's alias, if any) -> tool definition
+	pending      bool                 // server not started yet; tools are loaded on first access
 }
 
 // String implements starlark.Value
@@ -35,7 +38,7 @@ func (s *ServerNamespace) Freeze() {}
 
 // Truth implements starlark.Value
 func (s *ServerNamespace) Truth() starlark.Bool {
-	return len(s.tools) > 0
+	return starlark.Bool(s.pending || len(s.tools) > 0)
 }
 
 // Hash implements starlark.Value
@@ -43,17 +46,37 @@ func (s *ServerNamespace) Hash() (uint32, error) {
 	return starlark.String(s.serverName).Hash()
 }
 
+// start launches a lazily started server on first access and loads its tools
+func (s *ServerNamespace) start() error {
+	if !s.pending {
+		return nil
+	}
+
+	tools, err := proxy.EnsureStarted(s.proxyManager, s.serverName)
+	if err != nil {
+		return err
+	}
+
+	s.tools = NamespaceTools(s.proxyManager, s.serverName, tools)
+	s.pending = false
+	return nil
+}
+
 // Attr implements starlark.HasAttrs to provide tool access via dot notation
 func (s *ServerNamespace) Attr(name string) (starlark.Value, error) {
+	if err := s.start(); err != nil {
+		return nil, err
+	}
+
 	tool, exists := s.tools[name]
 	if !exists {
 		return nil, starlark.NoSuchAttrError(fmt.Sprintf("server '%s' has no tool '%s'", s.serverName, name))
 	}
 	
-	// Return a callable function for this tool
+	// Return a callable function for this tool, which calls it by its upstream name
 	return &ToolFunction{
 		serverName:   s.serverName,
-		toolName:     name,
+		toolName:     tool.Name,
 		tool:         tool,
 		proxyManager: s.proxyManager,
 	}, nil
@@ -61,6 +84,10 @@ func (s *ServerNamespace) Attr(name string) (starlark.Value, error) {
 
 // AttrNames implements starlark.HasAttrs
 func (s *ServerNamespace) AttrNames() []string {
+	if err := s.start(); err != nil {
+		return nil
+	}
+
 	names := make([]string, 0, len(s.tools))
 	for name := range s.tools {
 		names = append(names, name)
@@ -106,6 +133,33 @@ func (t *ToolFunction) Name() string {
 
 // CallInternal implements starlark.Callable
 func (t *ToolFunction) CallInternal(thread *starlark.Thread, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	params, raw, err := t.arguments(args, kwargs)
+	if err != nil {
+		return nil, err
+	}
+	return t.call(threadContext(thread), params, raw)
+}
+
+// arguments converts the Starlark arguments of a call to tool parameters, separating out the raw option
+func (t *ToolFunction) arguments(args starlark.Tuple, kwargs []starlark.Tuple) (map[string]interface{}, bool, error) {
+	// raw=True is a call option rather than a tool parameter, unless the tool declares its own raw parameter
+	raw := false
+	if !t.declaresParam(rawOption) {
+		var remaining []starlark.Tuple
+		for _, kw := range kwargs {
+			if key, ok := kw[0].(starlark.String); ok && string(key) == rawOption {
+				value, ok := kw[1].(starlark.Bool)
+				if !ok {
+					return nil, false, fmt.Errorf("%s: %s must be a bool, got %s", t.Name(), rawOption, kw[1].Type())
+				}
+				raw = bool(value)
+				continue
+			}
+			remaining = append(remaining, kw)
+		}
+		kwargs = remaining
+	}
+
 	// Convert arguments to Go map
 	var params map[string]interface{}
 	
@@ -116,43 +170,50 @@ func (t *ToolFunction) CallInternal(thread *starlark.Thread, args starlark.Tuple
 		if dict, ok := args[0].(*starlark.Dict); ok {
 			convertedVal, err := StarlarkToGoValue(dict)
 			if err != nil {
-				return nil, fmt.Errorf("failed to convert arguments: %v", err)
+				return nil, false, fmt.Errorf("failed to convert arguments: %v", err)
 			}
 			// Ensure it's a map
 			if paramMap, ok := convertedVal.(map[string]interface{}); ok {
 				params = paramMap
 			} else {
-				return nil, fmt.Errorf("argument must be a dict/map")
+				return nil, false, fmt.Errorf("argument must be a dict/map")
 			}
 		} else {
-			return nil, fmt.Errorf("single argument must be a dict")
+			return nil, false, fmt.Errorf("single argument must be a dict")
 		}
 	} else if len(args) == 0 && len(kwargs) > 0 {
 		// Keyword arguments
 		params = make(map[string]interface{})
 		for _, kw := range kwargs {
 			if len(kw) != 2 {
-				return nil, fmt.Errorf("invalid keyword argument")
+				return nil, false, fmt.Errorf("invalid keyword argument")
 			}
 			key, ok := kw[0].(starlark.String)
 			if !ok {
-				return nil, fmt.Errorf("keyword argument key must be string")
+				return nil, false, fmt.Errorf("keyword argument key must be string")
 			}
 			value, err := StarlarkToGoValue(kw[1])
 			if err != nil {
-				return nil, fmt.Errorf("failed to convert keyword argument: %v", err)
+				return nil, false, fmt.Errorf("failed to convert keyword argument: %v", err)
 			}
 			params[string(key)] = value
 		}
 	} else {
-		return nil, fmt.Errorf("tool functions accept either a single dict argument or keyword arguments")
+		return nil, false, fmt.Errorf("tool functions accept either a single dict argument or keyword arguments")
 	}
-	
+	return params, raw, nil
+}
+
+// call invokes the proxied tool and converts its result to Starlark
+func (t *ToolFunction) call(ctx context.Context, params map[string]interface{}, raw bool) (starlark.Value, error) {
 	// Call the proxied tool
-	result, err := t.proxyManager.CallTool(t.serverName, t.toolName, params)
+	result, err := proxy.CallToolContext(ctx, t.proxyManager, t.serverName, t.toolName, params)
 	if err != nil {
 		return nil, fmt.Errorf("tool call failed: %v", err)
 	}
+	if raw {
+		return rawResult(result)
+	}
 	
 	// Convert result back to Starlark
 	// For now, we'll return a simple dict with the content
@@ -183,6 +244,62 @@ func (t *ToolFunction) CallInternal(thread *starlark.Thread, args starlark.Tuple
 	return resultDict, nil
 }
 
+// rawOption is the keyword argument that asks for the complete upstream result
+const rawOption = "raw"
+
+// declaresParam reports whether the tool's input schema defines the named parameter
+func (t *ToolFunction) declaresParam(name string) bool {
+	if t.tool == nil || t.tool.InputSchema == nil {
+		return false
+	}
+	_, declared := t.tool.InputSchema.Properties[name]
+	return declared
+}
+
+// rawResult converts the complete upstream result, with every content block's type and fields,
+// isError and _meta, to a Starlark dict
+func rawResult(result *mcp.CallToolResult) (starlark.Value, error) {
+	data, err := json.Marshal(result)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode tool result: %v", err)
+	}
+	var decoded map[string]interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode tool result: %v", err)
+	}
+
+	// Fields omitted from the JSON when empty are always present in the raw result
+	decoded["isError"] = result.IsError
+	if decoded["content"] == nil {
+		decoded["content"] = []interface{}{}
+	}
+	return GoToStarlarkValue(decoded)
+}
+
+// NamespaceName returns the Starlark identifier under which a server's tools are exposed,
+// derived from the same prefix as its proxied tool names
+func NamespaceName(proxyManager ProxyManager, serverName string) string {
+	return normalizeServerName(proxy.ToolPrefix(proxyManager, serverName))
+}
+
+// NamespaceTools maps the attribute names of a server's namespace to its tools. Aliased tools are
+// exposed under their aliases, which hide any other tool with the same name.
+func NamespaceTools(proxyManager ProxyManager, serverName string, tools []*mcp.Tool) map[string]*mcp.Tool {
+	aliases := proxy.ToolAliases(proxyManager, serverName)
+	toolMap := make(map[string]*mcp.Tool, len(tools))
+	for _, tool := range tools {
+		if alias := aliases[tool.Name]; alias != "" {
+			toolMap[alias] = tool
+		}
+	}
+	for _, tool := range tools {
+		if _, taken := toolMap[tool.Name]; !taken && aliases[tool.Name] == "" {
+			toolMap[tool.Name] = tool
+		}
+	}
+	return toolMap
+}
+
 // normalizeServerName converts server names to valid Starlark identifiers
 // by replacing hyphens with underscores
 func normalizeServerName(name string) string {
@@ -199,19 +316,26 @@ func CreateServerNamespaces(proxyManager ProxyManager) starlark.StringDict {
 	namespaces := make(starlark.StringDict)
 
 	for serverName, tools := range allTools {
-		toolMap := make(map[string]*mcp.Tool)
-		for _, tool := range tools {
-			toolMap[tool.Name] = tool
-		}
-
 		namespace := &ServerNamespace{
 			serverName:   serverName,
 			proxyManager: proxyManager,
-			tools:        toolMap,
+			tools:        NamespaceTools(proxyManager, serverName, tools),
 		}
 
-		// Use normalized name as Starlark identifier (replace hyphens with underscores)
-		namespaces[normalizeServerName(serverName)] = namespace
+		// Use the normalized tool prefix as Starlark identifier (replace hyphens with underscores)
+		namespaces[NamespaceName(proxyManager, serverName)] = namespace
+	}
+
+	// Servers that start lazily get a namespace that launches them on first access
+	for _, serverName := range proxy.PendingServers(proxyManager) {
+		if _, exists := allTools[serverName]; exists {
+			continue
+		}
+		namespaces[NamespaceName(proxyManager, serverName)] = &ServerNamespace{
+			serverName:   serverName,
+			proxyManager: proxyManager,
+			pending:      true,
+		}
 	}
 
 	return namespaces
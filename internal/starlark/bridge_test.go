@@ -3,14 +3,16 @@ package starlark
 import (
 	"testing"
 
+	"github.com/google/jsonschema-go/jsonschema"
 	"go.starlark.net/starlark"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
 // MockProxyManager for testing
 type MockProxyManager struct {
-	tools map[string][]*mcp.Tool
-	calls []MockCall
+	tools  map[string][]*mcp.Tool
+	calls  []MockCall
+	result *mcp.CallToolResult // returned instead of the default mock response when set
 }
 
 type MockCall struct {
@@ -41,6 +43,10 @@ func (m *MockProxyManager) CallTool(serverName, toolName string, arguments map[s
 		Arguments:  arguments,
 	})
 
+	if m.result != nil {
+		return m.result, nil
+	}
+
 	// Mock response
 	result := &mcp.CallToolResult{
 		Content: []mcp.Content{
@@ -240,6 +246,67 @@ func TestToolFunctionCallErrors(t *testing.T) {
 	}
 }
 
+func TestToolFunctionRawResult(t *testing.T) {
+	mockProxy := NewMockProxyManager()
+	mockProxy.AddServer("files", []*mcp.Tool{
+		{Name: "read", Description: "Read a file"},
+		{Name: "render", Description: "Render a page", InputSchema: &jsonschema.Schema{
+			Type:       "object",
+			Properties: map[string]*jsonschema.Schema{"raw": {Type: "boolean"}},
+		}},
+	})
+	mockProxy.result = &mcp.CallToolResult{
+		Meta: mcp.Meta{"cursor": "abc"},
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: "partial"},
+			&mcp.ImageContent{Data: []byte("png"), MIMEType: "image/png"},
+		},
+		IsError: true,
+	}
+
+	code := `
+raw = files.read({"path": "a.png"}, raw=True)
+simple = files.read(path="a.png", raw=False)
+result = {
+    "types": [block["type"] for block in raw["content"]],
+    "mime": raw["content"][1]["mimeType"],
+    "is_error": raw["isError"],
+    "meta": raw["_meta"]["cursor"],
+    "simple": simple["content"][0],
+}
+`
+	result, err := ExecuteWithProxy(code, nil, mockProxy)
+	if err != nil || result.Error != "" {
+		t.Fatalf("ExecuteWithProxy() error = %v, %s", err, result.Error)
+	}
+	got := result.Result.(map[string]interface{})
+	if types := got["types"].([]interface{}); len(types) != 2 || types[0] != "text" || types[1] != "image" {
+		t.Errorf("content block types = %v, want [text image]", types)
+	}
+	if got["mime"] != "image/png" || got["is_error"] != true || got["meta"] != "abc" || got["simple"] != "partial" {
+		t.Errorf("unexpected raw result: %v", got)
+	}
+	for _, call := range mockProxy.calls {
+		if _, present := call.Arguments["raw"]; present {
+			t.Errorf("raw option was passed to the tool: %v", call.Arguments)
+		}
+	}
+
+	// A tool that declares its own raw parameter receives it
+	mockProxy.calls = nil
+	if _, err := ExecuteWithProxy(`files.render(raw=True)["content"]`, nil, mockProxy); err != nil {
+		t.Fatalf("ExecuteWithProxy() error = %v", err)
+	}
+	if len(mockProxy.calls) != 1 || mockProxy.calls[0].Arguments["raw"] != true {
+		t.Errorf("render calls = %+v, want raw=True passed through", mockProxy.calls)
+	}
+
+	result, _ = ExecuteWithProxy(`files.read(raw="yes")`, nil, mockProxy)
+	if result == nil || result.Error == "" {
+		t.Error("a non-bool raw option should be rejected")
+	}
+}
+
 func TestNormalizeServerName(t *testing.T) {
 	tests := []struct {
 		input    string
@@ -333,4 +400,117 @@ func TestServerNamePreservedInToolCalls(t *testing.T) {
 	if call.ToolName != "get_me" {
 		t.Errorf("Expected ToolName='get_me', got %q", call.ToolName)
 	}
-}
\ No newline at end of file
+}
+
+// prefixedProxyManager prefixes and aliases its servers' tools as configured
+type prefixedProxyManager struct {
+	*MockProxyManager
+	prefixes map[string]string
+	aliases  map[string]map[string]string
+}
+
+func (p *prefixedProxyManager) ToolAliases(serverName string) map[string]string {
+	return p.aliases[serverName]
+}
+
+func (p *prefixedProxyManager) ToolPrefix(serverName string) string {
+	if prefix, ok := p.prefixes[serverName]; ok {
+		return prefix
+	}
+	return serverName
+}
+
+func TestNamespacesFollowToolNaming(t *testing.T) {
+	prefixed := &prefixedProxyManager{
+		MockProxyManager: NewMockProxyManager(),
+		prefixes:         map[string]string{"github-enterprise": "ghe"},
+		aliases:          map[string]map[string]string{"zenhub-graphql": {"execute_query_v2": "execute_query"}},
+	}
+	prefixed.AddServer("github-enterprise", []*mcp.Tool{{Name: "get_me"}})
+	prefixed.AddServer("zenhub-graphql", []*mcp.Tool{{Name: "execute_query"}, {Name: "execute_query_v2"}})
+
+	namespaces := CreateServerNamespaces(prefixed)
+	if _, exists := namespaces["ghe"]; !exists {
+		t.Errorf("Expected the github-enterprise namespace to be named by its prefix, got %v", namespaces.Keys())
+	}
+	if _, exists := namespaces["zenhub_graphql"]; !exists {
+		t.Errorf("Expected zenhub_graphql without a configured prefix, got %v", namespaces.Keys())
+	}
+
+	getMe, _ := namespaces["ghe"].(*ServerNamespace).Attr("get_me")
+	if _, err := getMe.(*ToolFunction).CallInternal(&starlark.Thread{Name: "test"}, starlark.Tuple{starlark.NewDict(0)}, nil); err != nil {
+		t.Fatalf("Tool call failed: %v", err)
+	}
+	if call := prefixed.calls[0]; call.ServerName != "github-enterprise" {
+		t.Errorf("Expected the call to go to github-enterprise, got %q", call.ServerName)
+	}
+
+	// An alias takes the place of the tool it renames to, and calls go to the aliased tool
+	zenhub := namespaces["zenhub_graphql"].(*ServerNamespace)
+	if names := zenhub.AttrNames(); len(names) != 1 || names[0] != "execute_query" {
+		t.Errorf("Expected only execute_query, got %v", names)
+	}
+	query, _ := zenhub.Attr("execute_query")
+	if _, err := query.(*ToolFunction).CallInternal(&starlark.Thread{Name: "test"}, starlark.Tuple{starlark.NewDict(0)}, nil); err != nil {
+		t.Fatalf("Tool call failed: %v", err)
+	}
+	if call := prefixed.calls[1]; call.ToolName != "execute_query_v2" {
+		t.Errorf("Expected the call to go to execute_query_v2, got %q", call.ToolName)
+	}
+}
+
+// lazyProxyManager holds back some servers until they are started on first use
+type lazyProxyManager struct {
+	*MockProxyManager
+	pending map[string][]*mcp.Tool
+	started []string
+}
+
+func (l *lazyProxyManager) PendingServers() []string {
+	var names []string
+	for name := range l.pending {
+		names = append(names, name)
+	}
+	return names
+}
+
+func (l *lazyProxyManager) EnsureStarted(serverName string) ([]*mcp.Tool, error) {
+	if tools, ok := l.pending[serverName]; ok {
+		l.started = append(l.started, serverName)
+		l.AddServer(serverName, tools)
+		delete(l.pending, serverName)
+	}
+	return l.tools[serverName], nil
+}
+
+func TestLazyServerNamespace(t *testing.T) {
+	lazy := &lazyProxyManager{
+		MockProxyManager: NewMockProxyManager(),
+		pending: map[string][]*mcp.Tool{
+			"slow-server": {{Name: "query", Description: "Run a query"}},
+		},
+	}
+	lazy.AddServer("github", []*mcp.Tool{{Name: "get_me"}})
+
+	namespaces := CreateServerNamespaces(lazy)
+	if len(namespaces) != 2 {
+		t.Fatalf("Expected 2 namespaces, got %d", len(namespaces))
+	}
+	if len(lazy.started) != 0 {
+		t.Fatalf("Creating namespaces should not start servers, started %v", lazy.started)
+	}
+
+	result, err := ExecuteWithProxy(`slow_server.query()["content"][0]`, nil, lazy)
+	if err != nil {
+		t.Fatalf("ExecuteWithProxy() error = %v", err)
+	}
+	if result.Error != "" {
+		t.Fatalf("ExecuteWithProxy() script error = %s", result.Error)
+	}
+	if result.Result != "mock response" {
+		t.Errorf("Expected mock response, got %v", result.Result)
+	}
+	if len(lazy.started) != 1 || lazy.started[0] != "slow-server" {
+		t.Errorf("Expected slow-server to be started once, started %v", lazy.started)
+	}
+}
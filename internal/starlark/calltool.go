@@ -0,0 +1,91 @@
+package starlark
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.starlark.net/starlark"
+
+	"github.com/dslh/mcp-metatool/internal/proxy"
+)
+
+// proxyLocalKey is the thread-local key holding the proxy manager upstream calls are made through
+const proxyLocalKey = "metatool.proxy"
+
+// LookupTool finds the tool a call to server.tool refers to, so tools can be called by names computed
+// at runtime. The server can be its configured name or its namespace, and the tool its upstream name or
+// alias. Lazily started servers are started. It returns the configured server name and the tool.
+func LookupTool(proxyManager ProxyManager, server, tool string) (string, *mcp.Tool, error) {
+	if proxyManager == nil {
+		return "", nil, fmt.Errorf("server %s is not configured", server)
+	}
+	allTools := proxyManager.GetAllTools()
+	serverName := server
+	if _, connected := allTools[server]; !connected && !slices.Contains(proxy.PendingServers(proxyManager), server) {
+		serverName = ""
+		for name := range allTools {
+			if NamespaceName(proxyManager, name) == server {
+				serverName = name
+			}
+		}
+		for _, name := range proxy.PendingServers(proxyManager) {
+			if NamespaceName(proxyManager, name) == server {
+				serverName = name
+			}
+		}
+		if serverName == "" {
+			return "", nil, fmt.Errorf("server %s is not configured", server)
+		}
+	}
+
+	tools, connected := allTools[serverName]
+	if !connected {
+		var err error
+		if tools, err = proxy.EnsureStarted(proxyManager, serverName); err != nil {
+			return "", nil, err
+		}
+	}
+	if found, ok := NamespaceTools(proxyManager, serverName, tools)[tool]; ok {
+		return serverName, found, nil
+	}
+	for _, found := range tools {
+		if found.Name == tool {
+			return serverName, found, nil
+		}
+	}
+	return "", nil, fmt.Errorf("server %s has no tool named %s", server, tool)
+}
+
+// newCallToolBuiltin creates the call_tool builtin, which calls an upstream tool by server and tool
+// name, for when the names are computed at runtime and dot notation can't be used
+func newCallToolBuiltin() *starlark.Builtin {
+	return starlark.NewBuiltin("call_tool", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var server, tool string
+		var arguments *starlark.Dict
+		raw := false
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "server", &server, "tool", &tool, "args?", &arguments, "raw?", &raw); err != nil {
+			return nil, err
+		}
+
+		proxyManager, _ := thread.Local(proxyLocalKey).(ProxyManager)
+		serverName, found, err := LookupTool(proxyManager, server, tool)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+		}
+
+		params := make(map[string]interface{})
+		if arguments != nil {
+			converted, err := StarlarkToGoValue(arguments)
+			if err != nil {
+				return nil, fmt.Errorf("%s: failed to convert arguments: %v", fn.Name(), err)
+			}
+			if params, _ = converted.(map[string]interface{}); params == nil {
+				return nil, fmt.Errorf("%s: args must be a dict with string keys", fn.Name())
+			}
+		}
+
+		function := &ToolFunction{serverName: serverName, toolName: found.Name, tool: found, proxyManager: proxyManager}
+		return function.call(threadContext(thread), params, raw)
+	})
+}
@@ -0,0 +1,61 @@
+package starlark
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestCallToolBuiltin(t *testing.T) {
+	upstream := &lazyProxyManager{
+		MockProxyManager: NewMockProxyManager(),
+		pending:          map[string][]*mcp.Tool{"slow-server": {{Name: "query"}}},
+	}
+	upstream.AddServer("zenhub-graphql", []*mcp.Tool{{Name: "execute_query"}})
+	prefixed := &prefixedProxyManager{
+		MockProxyManager: upstream.MockProxyManager,
+		aliases:          map[string]map[string]string{"zenhub-graphql": {"execute_query": "query"}},
+	}
+
+	tests := []struct {
+		name      string
+		proxy     ProxyManager
+		code      string
+		wantCall  MockCall
+		wantError string
+	}{
+		{"computed names", upstream, `call_tool("zenhub-" + "graphql", "execute_query", {"q": 1})["content"][0]`, MockCall{"zenhub-graphql", "execute_query", map[string]interface{}{"q": int64(1)}}, ""},
+		{"namespace and alias", prefixed, `call_tool("zenhub_graphql", "query")`, MockCall{ServerName: "zenhub-graphql", ToolName: "execute_query"}, ""},
+		{"lazily started", upstream, `call_tool("slow-server", "query", raw=True)`, MockCall{ServerName: "slow-server", ToolName: "query"}, ""},
+		{"unknown tool", upstream, `call_tool("zenhub-graphql", "mutate")`, MockCall{}, "has no tool named mutate"},
+		{"unknown server", upstream, `call_tool("jira", "get_issue")`, MockCall{}, "server jira is not configured"},
+		{"without servers", nil, `call_tool("jira", "get_issue")`, MockCall{}, "server jira is not configured"},
+		{"args not a dict", upstream, `call_tool("zenhub-graphql", "execute_query", [1])`, MockCall{}, "args"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			upstream.calls = nil
+			result, err := ExecuteWithProxy(tt.code, nil, tt.proxy)
+			if err != nil {
+				t.Fatalf("ExecuteWithProxy() error = %v", err)
+			}
+			if tt.wantError != "" {
+				if !strings.Contains(result.Error, tt.wantError) {
+					t.Errorf("Error = %q, want it to mention %q", result.Error, tt.wantError)
+				}
+				return
+			}
+			if result.Error != "" {
+				t.Fatalf("Script error = %s", result.Error)
+			}
+			if len(upstream.calls) != 1 {
+				t.Fatalf("Made %d calls, want 1", len(upstream.calls))
+			}
+			call := upstream.calls[0]
+			if call.ServerName != tt.wantCall.ServerName || call.ToolName != tt.wantCall.ToolName || len(call.Arguments) != len(tt.wantCall.Arguments) {
+				t.Errorf("Called %+v, want %+v", call, tt.wantCall)
+			}
+		})
+	}
+}
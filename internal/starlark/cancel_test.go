@@ -0,0 +1,72 @@
+package starlark
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// blockingProxyManager holds every call until its context is cancelled
+type blockingProxyManager struct {
+	started chan struct{}
+}
+
+func (b *blockingProxyManager) GetAllTools() map[string][]*mcp.Tool {
+	return map[string][]*mcp.Tool{"github": {{Name: "get_issue"}}}
+}
+
+func (b *blockingProxyManager) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return b.CallToolContext(context.Background(), serverName, toolName, arguments)
+}
+
+func (b *blockingProxyManager) CallToolContext(ctx context.Context, serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	close(b.started)
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestExecuteCancelled(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"loop", "while True:\n    pass"},
+		{"retry backoff", `retry(lambda: fail("flaky"), attempts=3, backoff=30)`},
+		{"upstream call", `github.get_issue(number=1)`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy := &blockingProxyManager{started: make(chan struct{})}
+			ctx, cancel := context.WithCancel(context.Background())
+			go func() {
+				if tt.name == "upstream call" {
+					<-proxy.started
+				} else {
+					time.Sleep(50 * time.Millisecond)
+				}
+				cancel()
+			}()
+
+			done := make(chan *Result)
+			go func() {
+				result, err := ExecuteWithProxy(tt.code, nil, proxy, WithContext(ctx))
+				if err != nil {
+					t.Errorf("ExecuteWithProxy() error = %v", err)
+				}
+				done <- result
+			}()
+			select {
+			case result := <-done:
+				if result == nil || !strings.Contains(result.Error, "cancel") {
+					t.Errorf("Expected a cancellation error, got %+v", result)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatal("execution kept running after its context was cancelled")
+			}
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package starlark
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// compiledSuffix is the extension of compiled program files
+const compiledSuffix = ".starc"
+
+// programs holds programs compiled or loaded during this run, keyed by programKey
+var programs sync.Map
+
+// interpreterVersion identifies the Starlark interpreter and Go runtime, so compiled programs
+// written by a different build are ignored and eventually removed
+var interpreterVersion = sync.OnceValue(func() string {
+	version := "unknown"
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if dep.Path == "go.starlark.net" {
+				version = dep.Version
+				if dep.Replace != nil {
+					version = dep.Replace.Path + "@" + dep.Replace.Version
+				}
+			}
+		}
+	}
+	sum := sha256.Sum256([]byte(version + " " + runtime.Version()))
+	return hex.EncodeToString(sum[:4])
+})
+
+// WithCompiledCache reuses compiled programs between runs, and across restarts by keeping them
+// in the metatool directory, so large tool libraries aren't recompiled on every cold start
+func WithCompiledCache() Option {
+	return func(o *options) {
+		o.compiledCache = true
+	}
+}
+
+// programKey identifies a compilation of code. Compiled programs bind each name either to a
+// predeclared value or to a global, so the set of predeclared names is part of the key, and
+// record the file name in the positions of their errors.
+func programKey(code, fileName string, fileOptions *syntax.FileOptions, predeclared starlark.StringDict) string {
+	names := make([]string, 0, len(predeclared))
+	for name := range predeclared {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "%+v\x00%s\x00%s\x00%s", *fileOptions, strings.Join(names, ","), fileName, code)
+	return interpreterVersion() + "-" + hex.EncodeToString(hash.Sum(nil))
+}
+
+// compiledProgram returns the compiled form of code, loading it from memory or disk if it has
+// been compiled before and otherwise compiling and storing it
+func compiledProgram(code, fileName string, fileOptions *syntax.FileOptions, predeclared starlark.StringDict) (*starlark.Program, error) {
+	key := programKey(code, fileName, fileOptions, predeclared)
+	if program, ok := programs.Load(key); ok {
+		return program.(*starlark.Program), nil
+	}
+
+	dir, dirErr := paths.GetCompiledDir()
+	if dirErr == nil {
+		if data, err := storage.ReadFile(filepath.Join(dir, key+compiledSuffix)); err == nil {
+			// Unreadable files, e.g. from an incompatible compiler, are simply recompiled
+			if program, err := starlark.CompiledProgram(bytes.NewReader(data)); err == nil {
+				programs.Store(key, program)
+				return program, nil
+			}
+		}
+	}
+
+	_, program, err := starlark.SourceProgramOptions(fileOptions, fileName, code, predeclared.Has)
+	if err != nil {
+		return nil, err
+	}
+	programs.Store(key, program)
+
+	if dirErr == nil {
+		if err := writeCompiledProgram(dir, key, program); err != nil {
+			logging.Warnf("Failed to cache compiled program: %v", err)
+		}
+	}
+	return program, nil
+}
+
+// writeCompiledProgram stores a compiled program, removing any written by other interpreter versions
+func writeCompiledProgram(dir, key string, program *starlark.Program) error {
+	var buf bytes.Buffer
+	if err := program.Write(&buf); err != nil {
+		return err
+	}
+	if err := storage.WriteFile(filepath.Join(dir, key+compiledSuffix), buf.Bytes(), 0644); err != nil {
+		return err
+	}
+
+	entries, err := storage.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if isStaleProgram(entry) {
+			storage.Remove(filepath.Join(dir, entry.Name()))
+		}
+	}
+	return nil
+}
+
+// isStaleProgram reports whether a directory entry is a program compiled by another interpreter version
+func isStaleProgram(entry fs.DirEntry) bool {
+	name := entry.Name()
+	return !entry.IsDir() && strings.HasSuffix(name, compiledSuffix) && !strings.HasPrefix(name, interpreterVersion()+"-")
+}
@@ -0,0 +1,92 @@
+package starlark
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/syntax"
+
+	"github.com/dslh/mcp-metatool/internal/paths"
+)
+
+// forgetPrograms clears the in-memory program cache, as a restart would
+func forgetPrograms() {
+	programs.Range(func(key, value any) bool {
+		programs.Delete(key)
+		return true
+	})
+}
+
+func TestCompiledCache(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	forgetPrograms()
+	dir, err := paths.GetCompiledDir()
+	if err != nil {
+		t.Fatalf("GetCompiledDir() error = %v", err)
+	}
+
+	// A program compiled by another interpreter version is cleaned up when a new one is written
+	stale := filepath.Join(dir, "00000000-stale"+compiledSuffix)
+	os.WriteFile(stale, []byte("old"), 0644)
+
+	code := "def double(n):\n    return n * 2\nresult = double(params[\"n\"])"
+	run := func(n int) interface{} {
+		t.Helper()
+		result, err := Execute(code, map[string]interface{}{"n": n}, WithCompiledCache())
+		if err != nil || result.Error != "" {
+			t.Fatalf("Execute() error = %v, %s", err, result.Error)
+		}
+		return result.Result
+	}
+
+	if got := run(2); got != int64(4) {
+		t.Errorf("first run = %v, want 4", got)
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "*"+compiledSuffix))
+	if len(files) != 1 || !strings.HasPrefix(filepath.Base(files[0]), interpreterVersion()+"-") {
+		t.Fatalf("compiled files = %v, want one program for the current interpreter", files)
+	}
+
+	// After a restart the program is loaded from disk
+	forgetPrograms()
+	if got := run(5); got != int64(10) {
+		t.Errorf("run from disk = %v, want 10", got)
+	}
+
+	// Unreadable programs are recompiled
+	forgetPrograms()
+	os.WriteFile(files[0], []byte("corrupt"), 0644)
+	if got := run(7); got != int64(14) {
+		t.Errorf("run after corruption = %v, want 14", got)
+	}
+
+	result, _ := Execute("x = 1\nresult = (", nil, WithCompiledCache())
+	if !strings.Contains(result.Error, "Execution error") {
+		t.Errorf("syntax error = %q, want an execution error", result.Error)
+	}
+}
+
+func TestProgramKey(t *testing.T) {
+	options := &syntax.FileOptions{While: true}
+	base := starlark.StringDict{"params": starlark.None}
+	withServer := starlark.StringDict{"params": starlark.None, "github": starlark.None}
+
+	if programKey("x = 1", "<eval>", options, base) != programKey("x = 1", "<eval>", options, starlark.StringDict{"params": starlark.True}) {
+		t.Error("keys should depend on predeclared names, not their values")
+	}
+	if programKey("x = 1", "<eval>", options, base) == programKey("x = 1", "<eval>", options, withServer) {
+		t.Error("keys should change when the predeclared names do")
+	}
+	if programKey("x = 1", "<eval>", options, base) == programKey("x = 2", "<eval>", options, base) {
+		t.Error("keys should change with the code")
+	}
+	if programKey("x = 1", "<eval>", options, base) == programKey("x = 1", "<eval>", &syntax.FileOptions{}, base) {
+		t.Error("keys should change with the file options")
+	}
+	if programKey("x = 1", "<eval>", options, base) == programKey("x = 1", "weekly_report", options, base) {
+		t.Error("keys should change with the file name")
+	}
+}
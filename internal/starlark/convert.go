@@ -2,12 +2,88 @@ package starlark
 
 import (
 	"fmt"
+	"sync/atomic"
 
 	"go.starlark.net/starlark"
 )
 
-// GoToStarlarkValue converts a Go value to a Starlark value
+// DefaultMaxDepth is how deeply values converted to Starlark may be nested unless SetMaxDepth is called
+const DefaultMaxDepth = 64
+
+// maxDepth bounds the nesting of values converted by GoToStarlarkValue
+var maxDepth atomic.Int64
+
+func init() {
+	maxDepth.Store(DefaultMaxDepth)
+}
+
+// SetMaxDepth sets how many levels of lists and dicts a value converted to Starlark may have;
+// zero or less restores DefaultMaxDepth
+func SetMaxDepth(depth int) {
+	if depth <= 0 {
+		depth = DefaultMaxDepth
+	}
+	maxDepth.Store(int64(depth))
+}
+
+// goTask is a Go value waiting to be converted, with where to store the result
+type goTask struct {
+	value interface{}
+	depth int64 // lists and dicts enclosing the value
+	store func(starlark.Value)
+}
+
+// GoToStarlarkValue converts a Go value to a Starlark value, failing if it's nested too deeply.
+// Values are converted with an explicit stack rather than recursion, so pathological nesting
+// fails cleanly instead of exhausting the goroutine stack.
 func GoToStarlarkValue(v interface{}) (starlark.Value, error) {
+	limit := maxDepth.Load()
+	var root starlark.Value
+	stack := []goTask{{value: v, store: func(val starlark.Value) { root = val }}}
+
+	for len(stack) > 0 {
+		task := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch val := task.value.(type) {
+		case []interface{}:
+			if task.depth >= limit {
+				return nil, errTooDeep()
+			}
+			list := starlark.NewList(make([]starlark.Value, len(val)))
+			for i, item := range val {
+				stack = append(stack, goTask{value: item, depth: task.depth + 1, store: func(converted starlark.Value) {
+					list.SetIndex(i, converted)
+				}})
+			}
+			task.store(list)
+		case map[string]interface{}:
+			if task.depth >= limit {
+				return nil, errTooDeep()
+			}
+			dict := starlark.NewDict(len(val))
+			for k, item := range val {
+				key := starlark.String(k)
+				// Insert a placeholder now so the dict keeps the order keys were visited in
+				dict.SetKey(key, starlark.None)
+				stack = append(stack, goTask{value: item, depth: task.depth + 1, store: func(converted starlark.Value) {
+					dict.SetKey(key, converted)
+				}})
+			}
+			task.store(dict)
+		default:
+			scalar, err := goScalarToStarlark(val)
+			if err != nil {
+				return nil, err
+			}
+			task.store(scalar)
+		}
+	}
+	return root, nil
+}
+
+// goScalarToStarlark converts a Go value that isn't a list or dict
+func goScalarToStarlark(v interface{}) (starlark.Value, error) {
 	switch val := v.(type) {
 	case nil:
 		return starlark.None, nil
@@ -21,83 +97,87 @@ func GoToStarlarkValue(v interface{}) (starlark.Value, error) {
 		return starlark.Float(val), nil
 	case string:
 		return starlark.String(val), nil
-	case []interface{}:
-		list := starlark.NewList(make([]starlark.Value, len(val)))
-		for i, item := range val {
-			starVal, err := GoToStarlarkValue(item)
-			if err != nil {
-				return nil, err
-			}
-			list.SetIndex(i, starVal)
-		}
-		return list, nil
-	case map[string]interface{}:
-		dict := starlark.NewDict(len(val))
-		for k, item := range val {
-			starVal, err := GoToStarlarkValue(item)
-			if err != nil {
-				return nil, err
-			}
-			dict.SetKey(starlark.String(k), starVal)
-		}
-		return dict, nil
 	default:
 		return nil, fmt.Errorf("unsupported type: %T", v)
 	}
 }
 
-// StarlarkToGoValue converts a Starlark value to a Go value
+// errTooDeep reports a value nested beyond the configured depth
+func errTooDeep() error {
+	return fmt.Errorf("value is nested more than %d levels deep (see paramLimits.maxDepth)", maxDepth.Load())
+}
+
+// starlarkTask is a Starlark value waiting to be converted, with where to store the result
+type starlarkTask struct {
+	value starlark.Value
+	depth int64 // lists, tuples and dicts enclosing the value
+	store func(interface{})
+}
+
+// StarlarkToGoValue converts a Starlark value to a Go value, failing if it's nested too deeply.
+// Like GoToStarlarkValue it uses an explicit stack, which also stops self-referencing lists
+// and dicts from looping forever.
 func StarlarkToGoValue(v starlark.Value) (interface{}, error) {
+	limit := maxDepth.Load()
+	var root interface{}
+	stack := []starlarkTask{{value: v, store: func(val interface{}) { root = val }}}
+
+	for len(stack) > 0 {
+		task := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+
+		switch val := task.value.(type) {
+		case *starlark.List, starlark.Tuple:
+			if task.depth >= limit {
+				return nil, errTooDeep()
+			}
+			seq := val.(starlark.Indexable)
+			result := make([]interface{}, seq.Len())
+			for i := 0; i < seq.Len(); i++ {
+				stack = append(stack, starlarkTask{value: seq.Index(i), depth: task.depth + 1, store: func(converted interface{}) {
+					result[i] = converted
+				}})
+			}
+			task.store(result)
+		case *starlark.Dict:
+			if task.depth >= limit {
+				return nil, errTooDeep()
+			}
+			result := make(map[string]interface{})
+			for _, item := range val.Items() {
+				key, ok := item[0].(starlark.String)
+				if !ok {
+					continue // Skip non-string keys
+				}
+				stack = append(stack, starlarkTask{value: item[1], depth: task.depth + 1, store: func(converted interface{}) {
+					result[string(key)] = converted
+				}})
+			}
+			task.store(result)
+		default:
+			task.store(starlarkScalarToGo(val))
+		}
+	}
+	return root, nil
+}
+
+// starlarkScalarToGo converts a Starlark value that isn't a list, tuple or dict
+func starlarkScalarToGo(v starlark.Value) interface{} {
 	switch val := v.(type) {
 	case starlark.NoneType:
-		return nil, nil
+		return nil
 	case starlark.Bool:
-		return bool(val), nil
+		return bool(val)
 	case starlark.Int:
 		if i, ok := val.Int64(); ok {
-			return i, nil
+			return i
 		}
-		return val.String(), nil // Large integer as string
+		return val.String() // Large integer as string
 	case starlark.Float:
-		return float64(val), nil
+		return float64(val)
 	case starlark.String:
-		return string(val), nil
-	case *starlark.List:
-		result := make([]interface{}, val.Len())
-		for i := 0; i < val.Len(); i++ {
-			item, err := StarlarkToGoValue(val.Index(i))
-			if err != nil {
-				return nil, err
-			}
-			result[i] = item
-		}
-		return result, nil
-	case *starlark.Dict:
-		result := make(map[string]interface{})
-		for _, k := range val.Keys() {
-			key, ok := k.(starlark.String)
-			if !ok {
-				continue // Skip non-string keys
-			}
-			v, _, _ := val.Get(k)
-			goVal, err := StarlarkToGoValue(v)
-			if err != nil {
-				return nil, err
-			}
-			result[string(key)] = goVal
-		}
-		return result, nil
-	case starlark.Tuple:
-		result := make([]interface{}, val.Len())
-		for i := 0; i < val.Len(); i++ {
-			item, err := StarlarkToGoValue(val.Index(i))
-			if err != nil {
-				return nil, err
-			}
-			result[i] = item
-		}
-		return result, nil
+		return string(val)
 	default:
-		return val.String(), nil // Fallback to string representation
+		return val.String() // Fallback to string representation
 	}
-}
\ No newline at end of file
+}
@@ -1,7 +1,11 @@
 package starlark
 
 import (
+	"bytes"
+	"fmt"
 	"math"
+	"reflect"
+	"strings"
 	"testing"
 
 	"go.starlark.net/starlark"
@@ -75,6 +79,37 @@ func TestGoToStarlarkValue_SpecialFloats(t *testing.T) {
 	}
 }
 
+func TestGoToStarlarkValue_MaxDepth(t *testing.T) {
+	defer SetMaxDepth(0)
+	SetMaxDepth(3)
+
+	nest := func(levels int) interface{} {
+		var value interface{} = "leaf"
+		for i := 0; i < levels; i++ {
+			if i%2 == 0 {
+				value = []interface{}{value}
+			} else {
+				value = map[string]interface{}{"child": value}
+			}
+		}
+		return value
+	}
+
+	if _, err := GoToStarlarkValue(nest(3)); err != nil {
+		t.Errorf("GoToStarlarkValue() at the depth limit error = %v", err)
+	}
+	_, err := GoToStarlarkValue(nest(4))
+	if err == nil || !strings.Contains(err.Error(), "more than 3 levels") {
+		t.Errorf("GoToStarlarkValue() beyond the depth limit error = %v, want a nesting error", err)
+	}
+
+	// Far deeper values fail quickly rather than exhausting the stack
+	SetMaxDepth(0)
+	if _, err := GoToStarlarkValue(nest(100000)); err == nil {
+		t.Error("GoToStarlarkValue() should reject values nested beyond DefaultMaxDepth")
+	}
+}
+
 func TestStarlarkToGoValue(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -304,4 +339,159 @@ func equalValues(a, b interface{}) bool {
 	}
 
 	return a == b
-}
\ No newline at end of file
+}
+func TestStarlarkToGoValue_MaxDepth(t *testing.T) {
+	defer SetMaxDepth(0)
+	SetMaxDepth(3)
+
+	nested := starlark.Value(starlark.String("leaf"))
+	for i := 0; i < 3; i++ {
+		nested = starlark.NewList([]starlark.Value{nested})
+	}
+	if _, err := StarlarkToGoValue(nested); err != nil {
+		t.Errorf("StarlarkToGoValue() at the depth limit error = %v", err)
+	}
+	if _, err := StarlarkToGoValue(starlark.Tuple{nested}); err == nil || !strings.Contains(err.Error(), "more than 3 levels") {
+		t.Errorf("StarlarkToGoValue() beyond the depth limit error = %v, want a nesting error", err)
+	}
+}
+
+func TestStarlarkToGoValue_Cycle(t *testing.T) {
+	list := starlark.NewList(nil)
+	list.Append(list)
+	dict := starlark.NewDict(1)
+	dict.SetKey(starlark.String("self"), dict)
+
+	for _, value := range []starlark.Value{list, dict} {
+		if _, err := StarlarkToGoValue(value); err == nil || !strings.Contains(err.Error(), "levels deep") {
+			t.Errorf("StarlarkToGoValue(%s) error = %v, want a nesting error", value.Type(), err)
+		}
+	}
+}
+
+func TestConversionWithoutRecursion(t *testing.T) {
+	// Nesting far deeper than recursive conversion could comfortably handle
+	const depth = 200000
+	defer SetMaxDepth(0)
+	SetMaxDepth(depth)
+
+	var value interface{} = "leaf"
+	for i := 0; i < depth; i++ {
+		value = []interface{}{value}
+	}
+
+	starVal, err := GoToStarlarkValue(value)
+	if err != nil {
+		t.Fatalf("GoToStarlarkValue() error = %v", err)
+	}
+	goVal, err := StarlarkToGoValue(starVal)
+	if err != nil {
+		t.Fatalf("StarlarkToGoValue() error = %v", err)
+	}
+	for i := 0; i < depth; i++ {
+		list, ok := goVal.([]interface{})
+		if !ok || len(list) != 1 {
+			t.Fatalf("level %d = %v, want a single-item list", i, goVal)
+		}
+		goVal = list[0]
+	}
+	if goVal != "leaf" {
+		t.Errorf("innermost value = %v, want leaf", goVal)
+	}
+}
+
+// nestedValue builds a value from fuzz input, where each byte opens a list or dict, closes one,
+// or adds a scalar. It returns the value, always a list, and how many levels deep it is nested.
+func nestedValue(data []byte) (interface{}, int) {
+	type frame struct {
+		list []interface{}
+		dict map[string]interface{}
+	}
+	stack := []frame{{list: []interface{}{}}}
+	depth := 1
+
+	add := func(value interface{}) {
+		top := &stack[len(stack)-1]
+		if top.dict != nil {
+			top.dict[fmt.Sprintf("k%d", len(top.dict))] = value
+		} else {
+			top.list = append(top.list, value)
+		}
+	}
+	closeFrame := func() {
+		top := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if top.dict != nil {
+			add(top.dict)
+		} else {
+			add(top.list)
+		}
+	}
+
+	for _, b := range data {
+		switch b % 4 {
+		case 0:
+			stack = append(stack, frame{list: []interface{}{}})
+		case 1:
+			stack = append(stack, frame{dict: map[string]interface{}{}})
+		case 2:
+			switch (b / 4) % 5 {
+			case 0:
+				add(nil)
+			case 1:
+				add(b%8 == 2)
+			case 2:
+				add(int64(b) - 128)
+			case 3:
+				add(float64(b) / 3)
+			default:
+				add(strings.Repeat("x", int(b%7)))
+			}
+		case 3:
+			if len(stack) > 1 {
+				closeFrame()
+			}
+		}
+		depth = max(depth, len(stack))
+	}
+	for len(stack) > 1 {
+		closeFrame()
+	}
+	return stack[0].list, depth
+}
+
+func FuzzConvertNested(f *testing.F) {
+	f.Add([]byte{}, uint8(0))
+	f.Add([]byte{0, 2, 1, 6, 3, 10, 3}, uint8(2))
+	f.Add([]byte{1, 1, 1, 1, 1, 1, 1, 1, 2}, uint8(4))
+	f.Add(bytes.Repeat([]byte{0}, 500), uint8(0))
+
+	f.Fuzz(func(t *testing.T, data []byte, limit uint8) {
+		defer SetMaxDepth(0)
+		SetMaxDepth(int(limit))
+		effective := int(limit)
+		if effective == 0 {
+			effective = DefaultMaxDepth
+		}
+
+		value, depth := nestedValue(data)
+		starVal, err := GoToStarlarkValue(value)
+		if depth > effective {
+			if err == nil || !strings.Contains(err.Error(), "levels deep") {
+				t.Fatalf("GoToStarlarkValue() of a value %d levels deep with limit %d: error = %v, want a nesting error", depth, effective, err)
+			}
+			return
+		}
+		if err != nil {
+			t.Fatalf("GoToStarlarkValue() of a value %d levels deep with limit %d: error = %v", depth, effective, err)
+		}
+
+		result, err := StarlarkToGoValue(starVal)
+		if err != nil {
+			t.Fatalf("StarlarkToGoValue() error = %v", err)
+		}
+		if !reflect.DeepEqual(value, result) {
+			t.Fatalf("round trip changed the value: %v became %v", value, result)
+		}
+	})
+}
@@ -1,9 +1,17 @@
 package starlark
 
 import (
+	"context"
 	"fmt"
+	"slices"
 	"strings"
 
+	"go.opentelemetry.io/otel/attribute"
+
+	"github.com/dslh/mcp-metatool/internal/artifacts"
+	"github.com/dslh/mcp-metatool/internal/redact"
+	"github.com/dslh/mcp-metatool/internal/tracing"
+
 	"go.starlark.net/lib/json"
 	"go.starlark.net/lib/math"
 	"go.starlark.net/lib/time"
@@ -16,27 +24,138 @@ type Result struct {
 	Result interface{} `json:"result,omitempty"`
 	Error  string      `json:"error,omitempty"`
 	Logs   []string    `json:"logs,omitempty"`
+
+	// Traceback lists the calls in progress when execution failed, outermost first
+	Traceback []Frame `json:"traceback,omitempty"`
+
+	// Truncated reports what was cut from a result larger than the configured limit
+	Truncated *Truncation `json:"truncated,omitempty"`
+
+	// Artifacts lists files published via publish_artifact during execution
+	Artifacts []*artifacts.Artifact `json:"artifacts,omitempty"`
+}
+
+// Option is a functional option for configuring an execution
+type Option func(*options)
+
+// options holds the optional settings for an execution
+type options struct {
+	modules        starlark.StringDict
+	compiledCache  bool
+	maxSteps       uint64
+	restricted     []string
+	permissions    []string
+	allowedServers []string
+	fileName       string
+	readOnly       bool
+	ctx            context.Context
+	progress       ProgressFunc
+}
+
+// contextLocalKey is the thread-local key holding the context the code runs in
+const contextLocalKey = "metatool.context"
+
+// defaultFileName names code that wasn't given a name with WithFileName
+const defaultFileName = "<eval>"
+
+// applyOptions collects the settings made by opts
+func applyOptions(opts []Option) *options {
+	execOpts := &options{ctx: context.Background(), fileName: defaultFileName}
+	for _, opt := range opts {
+		opt(execOpts)
+	}
+	return execOpts
+}
+
+// WithModule makes an additional module available to the executed code under the given name
+func WithModule(name string, module starlark.Value) Option {
+	return func(o *options) {
+		if o.modules == nil {
+			o.modules = make(starlark.StringDict)
+		}
+		o.modules[name] = module
+	}
+}
+
+// WithFileName names the code in error positions and tracebacks, e.g. after the saved tool it
+// implements; it defaults to <eval>
+func WithFileName(name string) Option {
+	return func(o *options) {
+		if name != "" {
+			o.fileName = name
+		}
+	}
+}
+
+// readOnlyModules are the optional modules withheld in read-only mode, since they act outside the metatool
+var readOnlyModules = []string{"notify"}
+
+// WithReadOnly withholds the built-ins and modules that change anything outside the execution,
+// such as publish_artifact and notify, for read-only mode
+func WithReadOnly() Option {
+	return func(o *options) {
+		o.readOnly = true
+	}
+}
+
+// WithMaxSteps stops execution with an error once the code has taken the given number of steps
+func WithMaxSteps(steps uint64) Option {
+	return func(o *options) {
+		o.maxSteps = steps
+	}
+}
+
+// WithContext runs the code within ctx, so its execution and upstream tool calls join any trace in ctx
+// and stop when ctx is cancelled
+func WithContext(ctx context.Context) Option {
+	return func(o *options) {
+		o.ctx = ctx
+	}
+}
+
+// threadContext returns the context the thread's code runs in
+func threadContext(thread *starlark.Thread) context.Context {
+	if ctx, ok := thread.Local(contextLocalKey).(context.Context); ok {
+		return ctx
+	}
+	return context.Background()
 }
 
 // Execute runs Starlark code with optional parameters and returns the result
-func Execute(code string, params map[string]interface{}) (*Result, error) {
-	return ExecuteWithProxy(code, params, nil)
+func Execute(code string, params map[string]interface{}, opts ...Option) (*Result, error) {
+	return ExecuteWithProxy(code, params, nil, opts...)
 }
 
-// ExecuteWithProxy runs Starlark code with optional parameters and proxy manager access
-func ExecuteWithProxy(code string, params map[string]interface{}, proxyManager ProxyManager) (*Result, error) {
-	thread := &starlark.Thread{Name: "eval_starlark"}
-	
-	// Set up predeclared identifiers (built-ins + params)
-	predeclared := make(starlark.StringDict)
-	for name, value := range starlark.Universe {
-		predeclared[name] = value
+// ExecuteWithProxy runs Starlark code with optional parameters and proxy manager access.
+// Secrets and expanded environment variables are masked in error messages.
+func ExecuteWithProxy(code string, params map[string]interface{}, proxyManager ProxyManager, opts ...Option) (*Result, error) {
+	ctx, span := tracing.Start(applyOptions(opts).ctx, "starlark.execute", attribute.Int("starlark.code_bytes", len(code)))
+	result, err := execute(ctx, code, params, proxyManager, opts...)
+	if result != nil && result.Error != "" {
+		result.Error = redact.String(result.Error)
+		tracing.Fail(span, result.Error)
 	}
+	tracing.End(span, err)
+	return result, err
+}
 
-	// Add standard library modules
-	predeclared["time"] = time.Module
-	predeclared["math"] = math.Module
-	predeclared["json"] = json.Module
+// execute implements ExecuteWithProxy
+func execute(ctx context.Context, code string, params map[string]interface{}, proxyManager ProxyManager, opts ...Option) (*Result, error) {
+	proxyManager = filterServers(proxyManager, applyOptions(opts).allowedServers)
+	thread := &starlark.Thread{Name: "eval_starlark"}
+	var published []*artifacts.Artifact
+	thread.SetLocal(artifactsLocalKey, &published)
+	thread.SetLocal(contextLocalKey, ctx)
+	thread.SetLocal(proxyLocalKey, proxyManager)
+
+	// Stop the code if ctx is cancelled, as when the client cancels its request
+	stop := context.AfterFunc(ctx, func() {
+		thread.Cancel(context.Cause(ctx).Error())
+	})
+	defer stop()
+
+	// Set up predeclared identifiers (built-ins + params)
+	predeclared := Globals(opts...)
 
 	// Convert params to Starlark values if provided
 	if params != nil {
@@ -63,19 +182,26 @@ func ExecuteWithProxy(code string, params map[string]interface{}, proxyManager P
 	var result starlark.Value
 	var err error
 
-	// Configure Starlark with full language features
-	fileOptions := &syntax.FileOptions{
-		Set:             true, // Enable set literals and comprehensions
-		While:           true, // Enable while loops
-		TopLevelControl: true, // Enable for loops and if statements at top level
-		GlobalReassign:  true, // Allow reassignment of global variables
-		LoadBindsGlobally: true, // Load statements bind globally
-	}
+	fileOptions := newFileOptions()
+
+	execOpts := applyOptions(opts)
+	// Spawned threads draw on the same step budget as the main one
+	budget := newStepBudget(execOpts.maxSteps)
+	budget.attach(thread)
+	thread.SetLocal(permissionsLocalKey, execOpts.permissions)
+	thread.SetLocal(progressLocalKey, execOpts.progress)
+	spawns := newSpawner(ctx, budget)
+	defer spawns.stop()
+	thread.SetLocal(spawnerLocalKey, spawns)
 
 	// Execute the code and extract result
-	result, err = executeCode(code, fileOptions, thread, predeclared)
+	if execOpts.compiledCache && isMultiLineCode(code) {
+		result, err = executeCompiled(code, execOpts.fileName, fileOptions, thread, predeclared)
+	} else {
+		result, err = executeCode(code, execOpts.fileName, fileOptions, thread, predeclared)
+	}
 	if err != nil {
-		return &Result{Error: err.Error()}, nil
+		return failedResult(err), nil
 	}
 
 	// Convert result back to Go value
@@ -84,16 +210,105 @@ func ExecuteWithProxy(code string, params map[string]interface{}, proxyManager P
 		return &Result{Error: fmt.Sprintf("Result conversion error: %v", err)}, nil
 	}
 
-	return &Result{Result: goResult}, nil
+	goResult, truncated := limitResult(goResult)
+	return &Result{Result: goResult, Truncated: truncated, Artifacts: published}, nil
+}
+
+// mainFunction is the entry point called, if a program defines it, to produce the result
+const mainFunction = "main"
+
+// newFileOptions configures Starlark with full language features
+func newFileOptions() *syntax.FileOptions {
+	return &syntax.FileOptions{
+		Set:               true, // Enable set literals and comprehensions
+		While:             true, // Enable while loops
+		TopLevelControl:   true, // Enable for loops and if statements at top level
+		GlobalReassign:    true, // Allow reassignment of global variables
+		LoadBindsGlobally: true, // Load statements bind globally
+	}
+}
+
+// Globals returns the predeclared names available to executed code:
+// the standard library, metatool built-ins, and any optional modules (but not params or server namespaces)
+func Globals(opts ...Option) starlark.StringDict {
+	execOpts := applyOptions(opts)
+
+	globals := newPredeclared()
+	if !execOpts.readOnly {
+		globals["publish_artifact"] = newPublishArtifactBuiltin()
+	}
+	globals["parallel"] = newParallelBuiltin()
+	globals["retry"] = newRetryBuiltin()
+	globals["progress"] = newProgressBuiltin()
+	globals["spawn"] = newSpawnBuiltin()
+	globals["call_tool"] = newCallToolBuiltin()
+	globals["secrets"] = SecretsModule
+	globals["history"] = HistoryModule
+
+	// Add optional modules
+	for name, module := range execOpts.modules {
+		globals[name] = module
+	}
+	if execOpts.readOnly {
+		for _, name := range readOnlyModules {
+			delete(globals, name)
+		}
+	}
+
+	// Withhold restricted modules the code hasn't been granted
+	for _, name := range append(slices.Clip(DefaultRestrictedModules), execOpts.restricted...) {
+		if _, ok := globals[name]; ok && !Permitted(execOpts.permissions, name) {
+			globals[name] = &deniedModule{name: name}
+		}
+	}
+
+	return globals
+}
+
+// EvalCondition evaluates a Starlark expression with the given variables predeclared
+// and reports whether the result is truthy
+func EvalCondition(expr string, vars map[string]interface{}) (bool, error) {
+	predeclared := newPredeclared()
+	for name, value := range vars {
+		val, err := GoToStarlarkValue(value)
+		if err != nil {
+			return false, fmt.Errorf("failed to convert %s: %v", name, err)
+		}
+		predeclared[name] = val
+	}
+
+	thread := &starlark.Thread{Name: "eval_condition"}
+	result, err := starlark.Eval(thread, "<condition>", expr, predeclared)
+	if err != nil {
+		return false, err
+	}
+
+	return bool(result.Truth()), nil
+}
+
+// newPredeclared returns the Starlark universe plus the standard library modules
+func newPredeclared() starlark.StringDict {
+	predeclared := make(starlark.StringDict)
+	for name, value := range starlark.Universe {
+		predeclared[name] = value
+	}
+
+	// Add standard library modules
+	predeclared["time"] = time.Module
+	predeclared["math"] = math.Module
+	predeclared["json"] = json.Module
+	predeclared["format"] = FormatModule
+
+	return predeclared
 }
 
 // executeCode runs Starlark code and extracts the result
-func executeCode(code string, fileOptions *syntax.FileOptions, thread *starlark.Thread, predeclared starlark.StringDict) (starlark.Value, error) {
+func executeCode(code, fileName string, fileOptions *syntax.FileOptions, thread *starlark.Thread, predeclared starlark.StringDict) (starlark.Value, error) {
 	// Check if code should be executed as a program or expression
 	if isMultiLineCode(code) {
-		return executeAsProgram(code, fileOptions, thread, predeclared)
+		return executeAsProgram(code, fileName, fileOptions, thread, predeclared)
 	}
-	return executeAsExpression(code, fileOptions, thread, predeclared)
+	return executeAsExpression(code, fileName, fileOptions, thread, predeclared)
 }
 
 // isMultiLineCode determines if code should be executed as a program
@@ -102,10 +317,33 @@ func isMultiLineCode(code string) bool {
 }
 
 // executeAsProgram executes code as a Starlark program and extracts the result
-func executeAsProgram(code string, fileOptions *syntax.FileOptions, thread *starlark.Thread, predeclared starlark.StringDict) (starlark.Value, error) {
-	modGlobals, err := starlark.ExecFileOptions(fileOptions, thread, "<eval>", code, predeclared)
+func executeAsProgram(code, fileName string, fileOptions *syntax.FileOptions, thread *starlark.Thread, predeclared starlark.StringDict) (starlark.Value, error) {
+	modGlobals, err := starlark.ExecFileOptions(fileOptions, thread, fileName, code, predeclared)
+	if err != nil {
+		return nil, fmt.Errorf("Execution error: %w", err)
+	}
+	return programResult(thread, modGlobals, predeclared)
+}
+
+// executeCompiled executes code as a program like executeAsProgram, reusing its compiled form if possible
+func executeCompiled(code, fileName string, fileOptions *syntax.FileOptions, thread *starlark.Thread, predeclared starlark.StringDict) (starlark.Value, error) {
+	program, err := compiledProgram(code, fileName, fileOptions, predeclared)
+	if err != nil {
+		return nil, fmt.Errorf("Execution error: %w", err)
+	}
+	modGlobals, err := program.Init(thread, predeclared)
+	modGlobals.Freeze()
 	if err != nil {
-		return nil, fmt.Errorf("Execution error: %v", err)
+		return nil, fmt.Errorf("Execution error: %w", err)
+	}
+	return programResult(thread, modGlobals, predeclared)
+}
+
+// programResult extracts the result of an executed program: the return value of its main
+// function if it defines one, otherwise its 'result' variable, otherwise its other globals
+func programResult(thread *starlark.Thread, modGlobals, predeclared starlark.StringDict) (starlark.Value, error) {
+	if main, ok := modGlobals[mainFunction].(*starlark.Function); ok {
+		return callMain(thread, main, predeclared)
 	}
 
 	// Look for explicit 'result' variable first
@@ -117,11 +355,28 @@ func executeAsProgram(code string, fileOptions *syntax.FileOptions, thread *star
 	return extractResultFromGlobals(modGlobals, predeclared), nil
 }
 
+// callMain calls a program's main function, passing the params if it takes an argument
+func callMain(thread *starlark.Thread, main *starlark.Function, predeclared starlark.StringDict) (starlark.Value, error) {
+	var args starlark.Tuple
+	if main.NumParams() > 0 {
+		params, ok := predeclared["params"]
+		if !ok {
+			params = starlark.NewDict(0)
+		}
+		args = starlark.Tuple{params}
+	}
+	result, err := starlark.Call(thread, main, args, nil)
+	if err != nil {
+		return nil, fmt.Errorf("Execution error: %w", err)
+	}
+	return result, nil
+}
+
 // executeAsExpression evaluates code as a single expression
-func executeAsExpression(code string, fileOptions *syntax.FileOptions, thread *starlark.Thread, predeclared starlark.StringDict) (starlark.Value, error) {
-	result, err := starlark.EvalOptions(fileOptions, thread, "<eval>", code, predeclared)
+func executeAsExpression(code, fileName string, fileOptions *syntax.FileOptions, thread *starlark.Thread, predeclared starlark.StringDict) (starlark.Value, error) {
+	result, err := starlark.EvalOptions(fileOptions, thread, fileName, code, predeclared)
 	if err != nil {
-		return nil, fmt.Errorf("Evaluation error: %v", err)
+		return nil, fmt.Errorf("Evaluation error: %w", err)
 	}
 	return result, nil
 }
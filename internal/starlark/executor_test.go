@@ -123,6 +123,94 @@ result = x * y * 3`,
 	}
 }
 
+func TestExecute_Programs_WithMain(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		params  map[string]interface{}
+		want    interface{}
+		wantErr string
+	}{
+		{
+			"early return",
+			`def main(params):
+    if params["n"] < 0:
+        return "negative"
+    return params["n"] * 2`,
+			map[string]interface{}{"n": -1},
+			"negative",
+			"",
+		},
+		{
+			"takes precedence over result",
+			`result = "ignored"
+def main(params):
+    return helper(params["n"])
+def helper(n):
+    return n + 1`,
+			map[string]interface{}{"n": 41},
+			int64(42),
+			"",
+		},
+		{
+			"without params argument",
+			`def main():
+    return "ok"`,
+			nil,
+			"ok",
+			"",
+		},
+		{
+			"params defaults to empty",
+			`def main(params):
+    return len(params)`,
+			nil,
+			int64(0),
+			"",
+		},
+		{
+			"no return value",
+			`def main(params):
+    pass`,
+			nil,
+			nil,
+			"",
+		},
+		{
+			"error raised in main",
+			`def main(params):
+    fail("boom")`,
+			nil,
+			nil,
+			"boom",
+		},
+	}
+
+	for _, tt := range tests {
+		for _, opts := range [][]Option{nil, {WithCompiledCache()}} {
+			t.Run(tt.name, func(t *testing.T) {
+				t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+				result, err := Execute(tt.code, tt.params, opts...)
+				if err != nil {
+					t.Fatalf("Execute() error = %v", err)
+				}
+				if tt.wantErr != "" {
+					if !strings.Contains(result.Error, tt.wantErr) {
+						t.Errorf("Execute() error = %q, want %q", result.Error, tt.wantErr)
+					}
+					return
+				}
+				if result.Error != "" {
+					t.Fatalf("Execute() error in result: %s", result.Error)
+				}
+				if !equalValues(result.Result, tt.want) {
+					t.Errorf("Execute() result = %v, want %v", result.Result, tt.want)
+				}
+			})
+		}
+	}
+}
+
 func TestExecute_Programs_WithoutExplicitResult(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -1755,3 +1843,36 @@ func deepEqual(a, b interface{}) bool {
 	// Default comparison
 	return a == b
 }
+
+func TestEvalCondition(t *testing.T) {
+	vars := map[string]interface{}{
+		"result": map[string]interface{}{"count": 3, "name": "Alice"},
+		"params": map[string]interface{}{"limit": 5},
+	}
+
+	tests := []struct {
+		name    string
+		expr    string
+		want    bool
+		wantErr bool
+	}{
+		{"true comparison", `result["count"] < params["limit"]`, true, false},
+		{"false comparison", `result["name"] == "Bob"`, false, false},
+		{"truthy value", `result["name"]`, true, false},
+		{"stdlib available", `math.floor(2.5) == 2`, true, false},
+		{"undefined variable", `missing > 1`, false, true},
+		{"syntax error", `result[`, false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := EvalCondition(tt.expr, vars)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("EvalCondition() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("EvalCondition() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
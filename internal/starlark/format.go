@@ -0,0 +1,277 @@
+package starlark
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	gotime "time"
+
+	startime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+// defaultLocale is used when no locale is passed to the format builtins
+const defaultLocale = "en"
+
+// localeFormat holds the conventions used to render numbers and dates for one locale
+type localeFormat struct {
+	decimal     string
+	group       string
+	months      [12]string
+	shortMonths [12]string
+	days        [7]string
+	shortDays   [7]string
+}
+
+var englishNames = localeFormat{
+	months:      [12]string{"January", "February", "March", "April", "May", "June", "July", "August", "September", "October", "November", "December"},
+	shortMonths: [12]string{"Jan", "Feb", "Mar", "Apr", "May", "Jun", "Jul", "Aug", "Sep", "Oct", "Nov", "Dec"},
+	days:        [7]string{"Sunday", "Monday", "Tuesday", "Wednesday", "Thursday", "Friday", "Saturday"},
+	shortDays:   [7]string{"Sun", "Mon", "Tue", "Wed", "Thu", "Fri", "Sat"},
+}
+
+// locales is keyed by lowercase language code; region suffixes fall back to the language
+var locales = map[string]localeFormat{
+	"en": withSeparators(englishNames, ".", ","),
+	"de": {
+		decimal: ",", group: ".",
+		months:      [12]string{"Januar", "Februar", "März", "April", "Mai", "Juni", "Juli", "August", "September", "Oktober", "November", "Dezember"},
+		shortMonths: [12]string{"Jan.", "Feb.", "März", "Apr.", "Mai", "Juni", "Juli", "Aug.", "Sept.", "Okt.", "Nov.", "Dez."},
+		days:        [7]string{"Sonntag", "Montag", "Dienstag", "Mittwoch", "Donnerstag", "Freitag", "Samstag"},
+		shortDays:   [7]string{"So.", "Mo.", "Di.", "Mi.", "Do.", "Fr.", "Sa."},
+	},
+	"fr": {
+		decimal: ",", group: "\u202f",
+		months:      [12]string{"janvier", "février", "mars", "avril", "mai", "juin", "juillet", "août", "septembre", "octobre", "novembre", "décembre"},
+		shortMonths: [12]string{"janv.", "févr.", "mars", "avr.", "mai", "juin", "juil.", "août", "sept.", "oct.", "nov.", "déc."},
+		days:        [7]string{"dimanche", "lundi", "mardi", "mercredi", "jeudi", "vendredi", "samedi"},
+		shortDays:   [7]string{"dim.", "lun.", "mar.", "mer.", "jeu.", "ven.", "sam."},
+	},
+	"es": {
+		decimal: ",", group: ".",
+		months:      [12]string{"enero", "febrero", "marzo", "abril", "mayo", "junio", "julio", "agosto", "septiembre", "octubre", "noviembre", "diciembre"},
+		shortMonths: [12]string{"ene", "feb", "mar", "abr", "may", "jun", "jul", "ago", "sept", "oct", "nov", "dic"},
+		days:        [7]string{"domingo", "lunes", "martes", "miércoles", "jueves", "viernes", "sábado"},
+		shortDays:   [7]string{"dom", "lun", "mar", "mié", "jue", "vie", "sáb"},
+	},
+	"it": {
+		decimal: ",", group: ".",
+		months:      [12]string{"gennaio", "febbraio", "marzo", "aprile", "maggio", "giugno", "luglio", "agosto", "settembre", "ottobre", "novembre", "dicembre"},
+		shortMonths: [12]string{"gen", "feb", "mar", "apr", "mag", "giu", "lug", "ago", "set", "ott", "nov", "dic"},
+		days:        [7]string{"domenica", "lunedì", "martedì", "mercoledì", "giovedì", "venerdì", "sabato"},
+		shortDays:   [7]string{"dom", "lun", "mar", "mer", "gio", "ven", "sab"},
+	},
+	"nl": {
+		decimal: ",", group: ".",
+		months:      [12]string{"januari", "februari", "maart", "april", "mei", "juni", "juli", "augustus", "september", "oktober", "november", "december"},
+		shortMonths: [12]string{"jan", "feb", "mrt", "apr", "mei", "jun", "jul", "aug", "sep", "okt", "nov", "dec"},
+		days:        [7]string{"zondag", "maandag", "dinsdag", "woensdag", "donderdag", "vrijdag", "zaterdag"},
+		shortDays:   [7]string{"zo", "ma", "di", "wo", "do", "vr", "za"},
+	},
+	"pt": {
+		decimal: ",", group: ".",
+		months:      [12]string{"janeiro", "fevereiro", "março", "abril", "maio", "junho", "julho", "agosto", "setembro", "outubro", "novembro", "dezembro"},
+		shortMonths: [12]string{"jan", "fev", "mar", "abr", "mai", "jun", "jul", "ago", "set", "out", "nov", "dez"},
+		days:        [7]string{"domingo", "segunda-feira", "terça-feira", "quarta-feira", "quinta-feira", "sexta-feira", "sábado"},
+		shortDays:   [7]string{"dom", "seg", "ter", "qua", "qui", "sex", "sáb"},
+	},
+	"sv": {
+		decimal: ",", group: "\u00a0",
+		months:      [12]string{"januari", "februari", "mars", "april", "maj", "juni", "juli", "augusti", "september", "oktober", "november", "december"},
+		shortMonths: [12]string{"jan.", "feb.", "mars", "apr.", "maj", "juni", "juli", "aug.", "sep.", "okt.", "nov.", "dec."},
+		days:        [7]string{"söndag", "måndag", "tisdag", "onsdag", "torsdag", "fredag", "lördag"},
+		shortDays:   [7]string{"sön", "mån", "tis", "ons", "tors", "fre", "lör"},
+	},
+}
+
+// regionOverrides covers regions whose number separators differ from their language default
+var regionOverrides = map[string]localeFormat{
+	"de-ch": withSeparators(locales["de"], ".", "’"),
+	"en-za": withSeparators(englishNames, ",", "\u00a0"),
+}
+
+func withSeparators(base localeFormat, decimal, group string) localeFormat {
+	base.decimal = decimal
+	base.group = group
+	return base
+}
+
+// FormatModule provides locale-aware number and date formatting
+var FormatModule = &starlarkstruct.Module{
+	Name: "format",
+	Members: starlark.StringDict{
+		"number":  starlark.NewBuiltin("number", formatNumber),
+		"date":    starlark.NewBuiltin("date", formatDate),
+		"locales": starlark.NewBuiltin("locales", formatLocales),
+	},
+}
+
+// lookupLocale resolves tags like "de", "de-DE" or "pt_BR" to a known locale
+func lookupLocale(tag string) (localeFormat, error) {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	if loc, ok := regionOverrides[normalized]; ok {
+		return loc, nil
+	}
+	language, _, _ := strings.Cut(normalized, "-")
+	if loc, ok := locales[language]; ok {
+		return loc, nil
+	}
+	return localeFormat{}, fmt.Errorf("unsupported locale %q", tag)
+}
+
+// formatNumber implements format.number(n, locale="en", decimals=None)
+func formatNumber(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var n starlark.Value
+	locale := defaultLocale
+	var decimals starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "n", &n, "locale?", &locale, "decimals?", &decimals); err != nil {
+		return nil, err
+	}
+
+	loc, err := lookupLocale(locale)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+
+	places := -1
+	if decimals != starlark.None {
+		if places, err = starlark.AsInt32(decimals); err != nil {
+			return nil, fmt.Errorf("%s: decimals: %v", fn.Name(), err)
+		}
+		if places < 0 {
+			return nil, fmt.Errorf("%s: decimals must be non-negative, got %d", fn.Name(), places)
+		}
+	}
+
+	var digits string
+	switch v := n.(type) {
+	case starlark.Int:
+		digits = v.String()
+		if places > 0 {
+			digits += "." + strings.Repeat("0", places)
+		}
+	case starlark.Float:
+		f := float64(v)
+		if math.IsNaN(f) || math.IsInf(f, 0) {
+			return nil, fmt.Errorf("%s: cannot format %s", fn.Name(), v.String())
+		}
+		digits = strconv.FormatFloat(f, 'f', places, 64)
+	default:
+		return nil, fmt.Errorf("%s: got %s, want int or float", fn.Name(), n.Type())
+	}
+
+	return starlark.String(localizeDigits(digits, loc)), nil
+}
+
+// localizeDigits applies a locale's group and decimal separators to a plain decimal string
+func localizeDigits(digits string, loc localeFormat) string {
+	sign := ""
+	if strings.HasPrefix(digits, "-") {
+		sign, digits = "-", digits[1:]
+	}
+	whole, frac, hasFrac := strings.Cut(digits, ".")
+
+	var b strings.Builder
+	b.WriteString(sign)
+	for i, r := range whole {
+		if i > 0 && (len(whole)-i)%3 == 0 {
+			b.WriteString(loc.group)
+		}
+		b.WriteRune(r)
+	}
+	if hasFrac {
+		b.WriteString(loc.decimal)
+		b.WriteString(frac)
+	}
+	return b.String()
+}
+
+// formatDate implements format.date(t, layout, locale="en")
+func formatDate(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var t starlark.Value
+	var layout string
+	locale := defaultLocale
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "t", &t, "layout", &layout, "locale?", &locale); err != nil {
+		return nil, err
+	}
+
+	loc, err := lookupLocale(locale)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+
+	var when gotime.Time
+	switch v := t.(type) {
+	case startime.Time:
+		when = gotime.Time(v)
+	case starlark.String:
+		when, err = gotime.Parse(gotime.RFC3339, string(v))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+		}
+	default:
+		return nil, fmt.Errorf("%s: got %s, want time or RFC3339 string", fn.Name(), t.Type())
+	}
+
+	return starlark.String(localizeLayout(when, layout, loc)), nil
+}
+
+// nameTokens are the Go layout elements that spell out month and weekday names, longest first
+var nameTokens = []string{"January", "Monday", "Jan", "Mon"}
+
+// localizeLayout formats t with a Go layout, substituting the locale's month and weekday names.
+// Name tokens are rendered separately so localized names are never reinterpreted as layout elements.
+func localizeLayout(t gotime.Time, layout string, loc localeFormat) string {
+	var b strings.Builder
+	for layout != "" {
+		index, token := -1, ""
+		for _, candidate := range nameTokens {
+			if i := strings.Index(layout, candidate); i >= 0 && (index < 0 || i < index) {
+				index, token = i, candidate
+			}
+		}
+		if index < 0 {
+			b.WriteString(t.Format(layout))
+			break
+		}
+
+		b.WriteString(t.Format(layout[:index]))
+		switch token {
+		case "January":
+			b.WriteString(loc.months[t.Month()-1])
+		case "Jan":
+			b.WriteString(loc.shortMonths[t.Month()-1])
+		case "Monday":
+			b.WriteString(loc.days[t.Weekday()])
+		case "Mon":
+			b.WriteString(loc.shortDays[t.Weekday()])
+		}
+		layout = layout[index+len(token):]
+	}
+	return b.String()
+}
+
+// formatLocales implements format.locales(), listing the supported locale codes
+func formatLocales(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(locales)+len(regionOverrides))
+	for name := range locales {
+		names = append(names, name)
+	}
+	for name := range regionOverrides {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	values := make([]starlark.Value, len(names))
+	for i, name := range names {
+		values[i] = starlark.String(name)
+	}
+	return starlark.NewList(values), nil
+}
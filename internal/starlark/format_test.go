@@ -0,0 +1,97 @@
+package starlark
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatNumber(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{"default locale", `format.number(1234567)`, "1,234,567"},
+		{"small int", `format.number(999)`, "999"},
+		{"negative", `format.number(-1234.5)`, "-1,234.5"},
+		{"german", `format.number(1234567.891, "de")`, "1.234.567,891"},
+		{"region falls back to language", `format.number(1234.5, "de-DE")`, "1.234,5"},
+		{"underscore tag", `format.number(1234.5, "pt_BR")`, "1.234,5"},
+		{"french", `format.number(1234.5, "fr")`, "1 234,5"},
+		{"swiss override", `format.number(1234567.5, "de-CH")`, "1’234’567.5"},
+		{"fixed decimals", `format.number(1234.5678, "en", decimals=2)`, "1,234.57"},
+		{"int with decimals", `format.number(42, "de", decimals=2)`, "42,00"},
+		{"zero decimals rounds", `format.number(2.5, decimals=0)`, "2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Execute(tt.code, nil)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if result.Error != "" {
+				t.Fatalf("Unexpected execution error: %s", result.Error)
+			}
+			if result.Result != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, result.Result)
+			}
+		})
+	}
+}
+
+func TestFormatDate(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+		want string
+	}{
+		{"english default", `format.date("2025-03-03T10:30:00Z", "Monday, 2 January 2006")`, "Monday, 3 March 2025"},
+		{"german long names", `format.date("2025-03-03T10:30:00Z", "Monday, 2. January 2006", "de")`, "Montag, 3. März 2025"},
+		{"french short names", `format.date("2025-07-05T00:00:00Z", "Mon 2 Jan", "fr")`, "sam. 5 juil."},
+		{"time value", `format.date(time.time(year=2025, month=12, day=24), "2 January 2006 15:04", "es")`, "24 diciembre 2025 00:00"},
+		{"names never reparsed", `format.date("2025-03-03T00:00:00Z", "Monday Jan", "de")`, "Montag März"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Execute(tt.code, nil)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if result.Error != "" {
+				t.Fatalf("Unexpected execution error: %s", result.Error)
+			}
+			if result.Result != tt.want {
+				t.Errorf("Expected %q, got %q", tt.want, result.Result)
+			}
+		})
+	}
+}
+
+func TestFormat_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		code    string
+		wantErr string
+	}{
+		{"unknown locale", `format.number(1, "xx")`, "unsupported locale"},
+		{"non-number", `format.number("12")`, "want int or float"},
+		{"negative decimals", `format.number(1.5, decimals=-1)`, "non-negative"},
+		{"infinite", `format.number(float("inf"))`, "cannot format"},
+		{"bad date string", `format.date("yesterday", "2006")`, "cannot parse"},
+		{"bad date type", `format.date(12, "2006")`, "want time or RFC3339 string"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Execute(tt.code, nil)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !strings.Contains(result.Error, tt.wantErr) {
+				t.Errorf("Expected error containing %q, got %q", tt.wantErr, result.Error)
+			}
+		})
+	}
+}
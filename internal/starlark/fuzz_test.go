@@ -0,0 +1,100 @@
+package starlark
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fuzzMaxSteps bounds each fuzzed execution so generated infinite loops finish quickly
+const fuzzMaxSteps = 100000
+
+// largeNumber matches number literals big enough to build enormous strings or lists in a single step,
+// which step limits don't bound
+var largeNumber = regexp.MustCompile(`\d{5,}|\de\d`)
+
+func FuzzExecute(f *testing.F) {
+	// Keep published artifacts and compiled programs out of the real home directory
+	f.Setenv("MCP_METATOOL_DIR", f.TempDir())
+	defer func(sleep func(context.Context, time.Duration) error) { retrySleep = sleep }(retrySleep)
+	retrySleep = func(context.Context, time.Duration) error { return nil }
+
+	seeds := []string{
+		"1 + 2",
+		"result = [x * x for x in range(10)]",
+		"def f(n):\n    return n * f(n - 1) if n > 1 else 1\nresult = f(10)",
+		"while True:\n    pass",
+		"x = {}\nx['self'] = x\nresult = x",
+		"l = []\nl.append(l)\nresult = l",
+		"result = json.decode('[[[[[[1]]]]]]')",
+		"result = format.number(1234.5, 'de')",
+		"result = time.now().unix > 0",
+		"result = math.sqrt(-1)",
+		"result = retry(lambda: fail('boom'), attempts=2)",
+		"result = parallel([])",
+		"result = params['missing']",
+		"result = 1 // 0",
+		"def f(:",
+		"result = '%d' % 'x'",
+		"load('missing.star', 'x')",
+		"result = publish_artifact('fuzz.txt', 'hello')",
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, code string) {
+		if largeNumber.MatchString(code) || strings.Contains(code, "**") {
+			t.Skip("code could allocate more memory than step limits bound")
+		}
+		// Errors are expected; panics are not
+		result, err := ExecuteWithProxy(code, map[string]interface{}{"n": 3}, nil, WithMaxSteps(fuzzMaxSteps))
+		if err != nil {
+			t.Fatalf("ExecuteWithProxy() error = %v", err)
+		}
+		if result == nil {
+			t.Fatal("ExecuteWithProxy() returned no result")
+		}
+	})
+}
+
+func FuzzConvertJSON(f *testing.F) {
+	seeds := []string{
+		`null`,
+		`true`,
+		`-0.5e10`,
+		`"text with é and 😀"`,
+		`[1, "two", [3.5, null], {"four": false}]`,
+		`{"a": {"b": {"c": {"d": []}}}, "e": {}}`,
+		strings.Repeat("[", 100) + strings.Repeat("]", 100),
+	}
+	for _, seed := range seeds {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, data string) {
+		var value interface{}
+		if err := json.Unmarshal([]byte(data), &value); err != nil {
+			t.Skip("not valid JSON")
+		}
+
+		starVal, err := GoToStarlarkValue(value)
+		if err != nil {
+			if !strings.Contains(err.Error(), "levels deep") {
+				t.Fatalf("GoToStarlarkValue() error = %v", err)
+			}
+			return
+		}
+		result, err := StarlarkToGoValue(starVal)
+		if err != nil {
+			t.Fatalf("StarlarkToGoValue() error = %v", err)
+		}
+		if !reflect.DeepEqual(value, result) {
+			t.Fatalf("round trip changed %s: %#v became %#v", data, value, result)
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package starlark
+
+import (
+	"fmt"
+	gotime "time"
+
+	startime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/dslh/mcp-metatool/internal/history"
+)
+
+// defaultRecentCalls is how many calls history.recent returns when not told
+const defaultRecentCalls = 10
+
+// HistoryModule lets code look back over the tool calls made recently, proxied and saved
+var HistoryModule = &starlarkstruct.Module{
+	Name: "history",
+	Members: starlark.StringDict{
+		"recent": starlark.NewBuiltin("history.recent", historyRecent),
+		"search": starlark.NewBuiltin("history.search", historySearch),
+	},
+}
+
+// historyRecent returns the last n logged calls, oldest first
+func historyRecent(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	n := defaultRecentCalls
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "n?", &n); err != nil {
+		return nil, err
+	}
+	if n < 1 {
+		return nil, fmt.Errorf("%s: n must be at least 1, got %d", fn.Name(), n)
+	}
+	entries, err := history.Recent(n)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+	return historyEntries(entries)
+}
+
+// historySearch returns the logged calls matching the given filters, oldest first
+func historySearch(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var query history.Query
+	var since starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "tool?", &query.Tool, "server?", &query.Server, "since?", &since, "errors?", &query.Errors, "limit?", &query.Limit); err != nil {
+		return nil, err
+	}
+	if query.Limit < 0 {
+		return nil, fmt.Errorf("%s: limit cannot be negative", fn.Name())
+	}
+	var err error
+	if query.Since, err = historySince(since); err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+	entries, err := history.Search(query)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+	return historyEntries(entries)
+}
+
+// historySince converts the since argument: a time, an RFC 3339 timestamp, or a duration
+// meaning that long ago
+func historySince(since starlark.Value) (gotime.Time, error) {
+	switch v := since.(type) {
+	case starlark.NoneType:
+		return gotime.Time{}, nil
+	case startime.Time:
+		return gotime.Time(v), nil
+	case startime.Duration:
+		return gotime.Now().Add(-gotime.Duration(v)), nil
+	case starlark.String:
+		if t, err := gotime.Parse(gotime.RFC3339, string(v)); err == nil {
+			return t, nil
+		}
+		if d, err := gotime.ParseDuration(string(v)); err == nil {
+			return gotime.Now().Add(-d), nil
+		}
+		return gotime.Time{}, fmt.Errorf("since must be an RFC 3339 time or a duration such as \"24h\", got %q", string(v))
+	default:
+		return gotime.Time{}, fmt.Errorf("since must be a time, duration or string, got %s", since.Type())
+	}
+}
+
+// historyEntries converts logged calls to a list of dicts
+func historyEntries(entries []history.Entry) (starlark.Value, error) {
+	values := make([]starlark.Value, len(entries))
+	for i, entry := range entries {
+		arguments, err := GoToStarlarkValue(map[string]interface{}(entry.Arguments))
+		if err != nil {
+			return nil, err
+		}
+		dict := starlark.NewDict(6)
+		dict.SetKey(starlark.String("time"), startime.Time(entry.Time))
+		dict.SetKey(starlark.String("server"), starlark.String(entry.Server))
+		dict.SetKey(starlark.String("tool"), starlark.String(entry.Tool))
+		dict.SetKey(starlark.String("arguments"), arguments)
+		dict.SetKey(starlark.String("duration"), startime.Duration(gotime.Duration(entry.DurationMs*float64(gotime.Millisecond))))
+		dict.SetKey(starlark.String("error"), starlark.String(entry.Error))
+		values[i] = dict
+	}
+	return starlark.NewList(values), nil
+}
@@ -0,0 +1,64 @@
+package starlark
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryModule(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+	recent := time.Now().Add(-time.Hour).Format(time.RFC3339)
+	log := `[
+		{"time": "2026-01-01T09:00:00Z", "server": "github", "tool": "get_issue", "arguments": {"number": 1}, "durationMs": 12},
+		{"time": "` + recent + `", "server": "github", "tool": "create_issue", "arguments": {"title": "Bug"}, "durationMs": 40},
+		{"time": "` + recent + `", "server": "jira", "tool": "create_ticket", "durationMs": 5, "error": "forbidden"},
+		{"time": "` + recent + `", "tool": "report", "arguments": {"days": 7}, "durationMs": 1500}
+	]`
+	os.WriteFile(filepath.Join(dir, "history.json"), []byte(log), 0600)
+
+	tests := []struct {
+		name      string
+		code      string
+		expected  interface{}
+		wantError string
+	}{
+		{"recent", `[c["tool"] for c in history.recent(2)]`, []interface{}{"create_ticket", "report"}, ""},
+		{"recent default", `len(history.recent())`, int64(4), ""},
+		{"fields", `[(c["server"], c["arguments"]["number"], c["time"].year, c["duration"].milliseconds, c["error"]) for c in history.recent(4)[:1]][0]`,
+			[]interface{}{"github", 1.0, int64(2026), int64(12), ""}, ""},
+		{"search tool", `[c["server"] for c in history.search(tool="create_*")]`, []interface{}{"github", "jira"}, ""},
+		{"search server", `len(history.search(server="github"))`, int64(2), ""},
+		{"search errors", `[c["error"] for c in history.search(errors=True)]`, []interface{}{"forbidden"}, ""},
+		{"since duration string", `len(history.search(since="24h"))`, int64(3), ""},
+		{"since duration", `len(history.search(since=time.hour * 24))`, int64(3), ""},
+		{"since time", `len(history.search(since=time.time(year=2026, month=1, day=1, hour=10)))`, int64(3), ""},
+		{"since timestamp", `len(history.search(since="2025-12-31T00:00:00Z", limit=1))`, int64(1), ""},
+		{"invalid since", `history.search(since="yesterday")`, nil, "since must be an RFC 3339 time or a duration"},
+		{"invalid n", `history.recent(0)`, nil, "n must be at least 1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Execute(tt.code, nil)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if tt.wantError != "" {
+				if !strings.Contains(result.Error, tt.wantError) {
+					t.Errorf("Execute() error = %q, want it to contain %q", result.Error, tt.wantError)
+				}
+				return
+			}
+			if result.Error != "" {
+				t.Fatalf("Execute() script error = %s", result.Error)
+			}
+			if !equalValues(tt.expected, result.Result) {
+				t.Errorf("Execute() = %#v, want %#v", result.Result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package starlark
+
+import (
+	"fmt"
+	"sync"
+
+	"go.starlark.net/starlark"
+)
+
+// DefaultParallelWorkers is how many calls parallel runs at once unless max_workers is given
+const DefaultParallelWorkers = 5
+
+// parallelCall is one tool call requested of parallel, with its arguments already converted
+type parallelCall struct {
+	tool   *ToolFunction
+	params map[string]interface{}
+}
+
+// newParallelBuiltin creates the parallel builtin, which calls upstream tools concurrently
+// and returns their results in the order the calls were given
+func newParallelBuiltin() *starlark.Builtin {
+	return starlark.NewBuiltin("parallel", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var calls starlark.Iterable
+		maxWorkers := DefaultParallelWorkers
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "calls", &calls, "max_workers?", &maxWorkers); err != nil {
+			return nil, err
+		}
+		if maxWorkers < 1 {
+			return nil, fmt.Errorf("%s: max_workers must be at least 1, got %d", fn.Name(), maxWorkers)
+		}
+
+		// Arguments are converted up front, since Starlark values can't safely be read from other goroutines
+		var pending []parallelCall
+		iter := calls.Iterate()
+		defer iter.Done()
+		var item starlark.Value
+		for i := 0; iter.Next(&item); i++ {
+			call, err := parseParallelCall(item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: call %d: %v", fn.Name(), i, err)
+			}
+			pending = append(pending, call)
+		}
+
+		results := make([]starlark.Value, len(pending))
+		errs := make([]error, len(pending))
+		ctx := threadContext(thread)
+		slots := make(chan struct{}, maxWorkers)
+		var wg sync.WaitGroup
+		for i, call := range pending {
+			wg.Add(1)
+			slots <- struct{}{}
+			go func(i int, call parallelCall) {
+				defer wg.Done()
+				defer func() { <-slots }()
+				results[i], errs[i] = call.tool.call(ctx, call.params, false)
+			}(i, call)
+		}
+		wg.Wait()
+
+		// Report the first failure in call order so errors are deterministic
+		for i, err := range errs {
+			if err != nil {
+				return nil, fmt.Errorf("%s: call %d (%s): %v", fn.Name(), i, pending[i].tool.Name(), err)
+			}
+		}
+		return starlark.NewList(results), nil
+	})
+}
+
+// parseParallelCall reads a (tool, args) pair, where args is an optional dict of tool parameters
+func parseParallelCall(item starlark.Value) (parallelCall, error) {
+	var callable, arguments starlark.Value
+	switch v := item.(type) {
+	case starlark.Tuple:
+		if len(v) < 1 || len(v) > 2 {
+			return parallelCall{}, fmt.Errorf("expected (tool, args), got a tuple of %d", len(v))
+		}
+		callable = v[0]
+		if len(v) == 2 {
+			arguments = v[1]
+		}
+	case *starlark.List:
+		if v.Len() < 1 || v.Len() > 2 {
+			return parallelCall{}, fmt.Errorf("expected (tool, args), got a list of %d", v.Len())
+		}
+		callable = v.Index(0)
+		if v.Len() == 2 {
+			arguments = v.Index(1)
+		}
+	default:
+		callable = item
+	}
+
+	tool, ok := callable.(*ToolFunction)
+	if !ok {
+		return parallelCall{}, fmt.Errorf("expected an upstream tool such as server.tool, got %s", callable.Type())
+	}
+
+	var positional starlark.Tuple
+	switch arguments.(type) {
+	case nil, starlark.NoneType:
+	case *starlark.Dict:
+		positional = starlark.Tuple{arguments}
+	default:
+		return parallelCall{}, fmt.Errorf("%s: args must be a dict, got %s", tool.Name(), arguments.Type())
+	}
+	params, _, err := tool.arguments(positional, nil)
+	if err != nil {
+		return parallelCall{}, err
+	}
+	return parallelCall{tool: tool, params: params}, nil
+}
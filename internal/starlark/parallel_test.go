@@ -0,0 +1,121 @@
+package starlark
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// concurrentProxyManager echoes each call's id argument after a short delay, tracking how many calls overlap
+type concurrentProxyManager struct {
+	mu       sync.Mutex
+	inFlight int
+	peak     int
+}
+
+func (c *concurrentProxyManager) GetAllTools() map[string][]*mcp.Tool {
+	return map[string][]*mcp.Tool{"github": {{Name: "get_issue"}, {Name: "fail"}}}
+}
+
+func (c *concurrentProxyManager) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	c.mu.Lock()
+	c.inFlight++
+	c.peak = max(c.peak, c.inFlight)
+	c.mu.Unlock()
+	defer func() {
+		c.mu.Lock()
+		c.inFlight--
+		c.mu.Unlock()
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	if toolName == "fail" {
+		return nil, fmt.Errorf("upstream unavailable")
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: fmt.Sprintf("issue %v", arguments["id"])}}}, nil
+}
+
+func TestParallel(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		expected  interface{}
+		wantError string
+		maxPeak   int
+	}{
+		{
+			name:     "results in call order",
+			code:     `[r["content"][0] for r in parallel([(github.get_issue, {"id": i}) for i in range(8)])]`,
+			expected: []interface{}{"issue 0", "issue 1", "issue 2", "issue 3", "issue 4", "issue 5", "issue 6", "issue 7"},
+			maxPeak:  DefaultParallelWorkers,
+		},
+		{
+			name:     "max_workers bounds concurrency",
+			code:     `len(parallel([(github.get_issue, {"id": i}) for i in range(6)], max_workers=2))`,
+			expected: int64(6),
+			maxPeak:  2,
+		},
+		{
+			name:     "bare tools and missing args",
+			code:     `[r["content"][0] for r in parallel([github.get_issue, (github.get_issue,), (github.get_issue, None)])]`,
+			expected: []interface{}{"issue <nil>", "issue <nil>", "issue <nil>"},
+		},
+		{
+			name:     "empty list",
+			code:     `parallel([])`,
+			expected: []interface{}{},
+		},
+		{
+			name:      "failed call",
+			code:      `parallel([(github.get_issue, {"id": 1}), (github.fail, {})])`,
+			wantError: "call 1 (github.fail): tool call failed: upstream unavailable",
+		},
+		{
+			name:      "not a tool",
+			code:      `parallel([(len, {})])`,
+			wantError: "call 0: expected an upstream tool",
+		},
+		{
+			name:      "args not a dict",
+			code:      `parallel([(github.get_issue, [1])])`,
+			wantError: "args must be a dict",
+		},
+		{
+			name:      "invalid max_workers",
+			code:      `parallel([], max_workers=0)`,
+			wantError: "max_workers must be at least 1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			proxy := &concurrentProxyManager{}
+			result, err := ExecuteWithProxy(tt.code, nil, proxy)
+			if err != nil {
+				t.Fatalf("ExecuteWithProxy() error = %v", err)
+			}
+			if tt.wantError != "" {
+				if !strings.Contains(result.Error, tt.wantError) {
+					t.Errorf("ExecuteWithProxy() error = %q, want it to contain %q", result.Error, tt.wantError)
+				}
+				return
+			}
+			if result.Error != "" {
+				t.Fatalf("ExecuteWithProxy() script error = %s", result.Error)
+			}
+			if fmt.Sprint(result.Result) != fmt.Sprint(tt.expected) {
+				t.Errorf("ExecuteWithProxy() = %v, want %v", result.Result, tt.expected)
+			}
+			if tt.maxPeak > 0 && proxy.peak > tt.maxPeak {
+				t.Errorf("%d calls ran at once, want at most %d", proxy.peak, tt.maxPeak)
+			}
+			if tt.maxPeak > 1 && proxy.peak < 2 {
+				t.Errorf("calls ran one at a time, want them to overlap")
+			}
+		})
+	}
+}
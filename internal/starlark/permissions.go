@@ -0,0 +1,79 @@
+package starlark
+
+import (
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+)
+
+// permissionsLocalKey is the thread-local key holding the permissions granted to an execution
+const permissionsLocalKey = "metatool.permissions"
+
+// DefaultRestrictedModules are always restricted, whatever the config says: secrets would let any
+// code read stored credentials, such as OAuth tokens, and return them in its result
+var DefaultRestrictedModules = []string{"secrets"}
+
+// WithRestrictedModules makes the named modules, such as notify or secrets, available only to
+// executions granted permission for them with WithPermissions
+func WithRestrictedModules(names ...string) Option {
+	return func(o *options) {
+		o.restricted = append(o.restricted, names...)
+	}
+}
+
+// WithPermissions grants the executed code permissions such as "notify" or "fs:read"
+func WithPermissions(permissions ...string) Option {
+	return func(o *options) {
+		o.permissions = append(o.permissions, permissions...)
+	}
+}
+
+// Permitted reports whether the granted permissions cover the required one. A permission names a
+// module, optionally followed by a scope ("fs:read"); an unscoped permission covers every scope,
+// and any permission naming a module makes the module available.
+func Permitted(granted []string, required string) bool {
+	module, scope, scoped := strings.Cut(required, ":")
+	for _, permission := range granted {
+		grantedModule, grantedScope, grantedScoped := strings.Cut(permission, ":")
+		if grantedModule != module {
+			continue
+		}
+		if !grantedScoped || !scoped || grantedScope == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// HasPermission reports whether the code running on thread has been granted a permission,
+// letting restricted modules check scopes such as "fs:write" when they are called
+func HasPermission(thread *starlark.Thread, permission string) bool {
+	granted, _ := thread.Local(permissionsLocalKey).([]string)
+	return Permitted(granted, permission)
+}
+
+// PermissionError is the error a restricted module's function returns when called without a permission it needs
+func PermissionError(fn, permission string) error {
+	return fmt.Errorf("%s requires permission %s, which this code hasn't been granted", fn, permission)
+}
+
+// deniedModule stands in for a restricted module the executed code hasn't been granted,
+// explaining how to grant it when used
+type deniedModule struct {
+	name string
+}
+
+var _ starlark.HasAttrs = (*deniedModule)(nil)
+
+func (d *deniedModule) String() string        { return fmt.Sprintf("<restricted module %s>", d.name) }
+func (d *deniedModule) Type() string          { return "module" }
+func (d *deniedModule) Freeze()               {}
+func (d *deniedModule) Truth() starlark.Bool  { return starlark.False }
+func (d *deniedModule) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable: %s", d.Type()) }
+func (d *deniedModule) AttrNames() []string   { return nil }
+
+// Attr fails, since nothing in the module may be used
+func (d *deniedModule) Attr(name string) (starlark.Value, error) {
+	return nil, fmt.Errorf("%s.%s: %s is a restricted module; save the code as a tool with permissions: [%q] to use it", d.name, name, d.name, d.name)
+}
@@ -0,0 +1,74 @@
+package starlark
+
+import (
+	"strings"
+	"testing"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+)
+
+func TestPermitted(t *testing.T) {
+	tests := []struct {
+		granted  []string
+		required string
+		want     bool
+	}{
+		{[]string{"http"}, "http", true},
+		{[]string{"http"}, "fs", false},
+		{nil, "http", false},
+		{[]string{"fs"}, "fs:write", true},
+		{[]string{"fs:read"}, "fs", true},
+		{[]string{"fs:read"}, "fs:read", true},
+		{[]string{"fs:read"}, "fs:write", false},
+		{[]string{"notify", "fs:read"}, "fs:read", true},
+	}
+	for _, tt := range tests {
+		if got := Permitted(tt.granted, tt.required); got != tt.want {
+			t.Errorf("Permitted(%v, %q) = %v, want %v", tt.granted, tt.required, got, tt.want)
+		}
+	}
+}
+
+func TestRestrictedModules(t *testing.T) {
+	// A stand-in for a module with a scope checked when it is called
+	fs := &starlarkstruct.Module{Name: "fs", Members: starlark.StringDict{
+		"write": starlark.NewBuiltin("fs.write", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+			if !HasPermission(thread, "fs:write") {
+				return nil, PermissionError(fn.Name(), "fs:write")
+			}
+			return starlark.String("written"), nil
+		}),
+	}}
+
+	tests := []struct {
+		name        string
+		code        string
+		permissions []string
+		want        interface{}
+		wantError   string
+	}{
+		{"granted", `fs.write()`, []string{"fs"}, "written", ""},
+		{"scope granted", `fs.write()`, []string{"fs:write"}, "written", ""},
+		{"not granted", `fs.write()`, nil, nil, `fs is a restricted module; save the code as a tool with permissions: ["fs"]`},
+		{"wrong scope", `fs.write()`, []string{"fs:read"}, nil, "fs.write requires permission fs:write"},
+		{"unrestricted modules unaffected", `math.floor(1.5)`, nil, int64(1), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Execute(tt.code, nil, WithModule("fs", fs), WithRestrictedModules("fs", "missing"), WithPermissions(tt.permissions...))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if tt.wantError != "" {
+				if !strings.Contains(result.Error, tt.wantError) {
+					t.Errorf("Execute() error = %q, want it to contain %q", result.Error, tt.wantError)
+				}
+				return
+			}
+			if result.Error != "" || result.Result != tt.want {
+				t.Errorf("Execute() = %v (error %q), want %v", result.Result, result.Error, tt.want)
+			}
+		})
+	}
+}
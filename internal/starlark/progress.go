@@ -0,0 +1,48 @@
+package starlark
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+)
+
+// ProgressFunc receives progress reported by executing code. percent is negative when the code
+// didn't give one.
+type ProgressFunc func(message string, percent float64)
+
+// progressLocalKey is the thread-local key holding the ProgressFunc, if any
+const progressLocalKey = "metatool.progress"
+
+// WithProgress passes progress reported with the progress builtin to fn
+func WithProgress(fn ProgressFunc) Option {
+	return func(o *options) {
+		o.progress = fn
+	}
+}
+
+// newProgressBuiltin creates the progress builtin, which reports how far long-running code has got.
+// It does nothing when no one is listening, so code using it runs anywhere.
+func newProgressBuiltin() *starlark.Builtin {
+	return starlark.NewBuiltin("progress", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var message string
+		var percentValue starlark.Value = starlark.None
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "message", &message, "percent?", &percentValue); err != nil {
+			return nil, err
+		}
+		percent := -1.0
+		if percentValue != starlark.None {
+			value, ok := starlark.AsFloat(percentValue)
+			if !ok {
+				return nil, fmt.Errorf("%s: percent must be a number, got %s", fn.Name(), percentValue.Type())
+			}
+			if value < 0 || value > 100 {
+				return nil, fmt.Errorf("%s: percent must be between 0 and 100, got %v", fn.Name(), value)
+			}
+			percent = value
+		}
+		if report, ok := thread.Local(progressLocalKey).(ProgressFunc); ok && report != nil {
+			report(message, percent)
+		}
+		return starlark.None, nil
+	})
+}
@@ -0,0 +1,54 @@
+package starlark
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestProgress(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		want      []string
+		wantError string
+	}{
+		{"percent", `progress("fetching", 50)`, []string{"fetching 50"}, ""},
+		{"message only", `progress("starting")`, []string{"starting -1"}, ""},
+		{"fraction", `progress("almost", percent=99.5)`, []string{"almost 99.5"}, ""},
+		{"loop", "for i in range(3):\n    progress('item %d' % i, i * 50)", []string{"item 0 0", "item 1 50", "item 2 100"}, ""},
+		{"out of range", `progress("too far", 150)`, nil, "percent must be between 0 and 100"},
+		{"not a number", `progress("what", "half")`, nil, "percent must be a number"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			report := func(message string, percent float64) {
+				got = append(got, fmt.Sprintf("%s %v", message, percent))
+			}
+			result, err := Execute(tt.code, nil, WithProgress(report))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if tt.wantError != "" {
+				if !strings.Contains(result.Error, tt.wantError) {
+					t.Errorf("Execute() error = %q, want it to contain %q", result.Error, tt.wantError)
+				}
+				return
+			}
+			if result.Error != "" {
+				t.Fatalf("Execute() script error = %s", result.Error)
+			}
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Reported %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// Without a listener, progress does nothing
+	result, err := Execute(`progress("unheard", 10)`, nil)
+	if err != nil || result.Error != "" {
+		t.Errorf("Execute() without a listener = %v, %v", result, err)
+	}
+}
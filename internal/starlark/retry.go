@@ -0,0 +1,105 @@
+package starlark
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	"go.starlark.net/starlark"
+)
+
+// maxRetryDelay caps the backoff between attempts made by retry, however many there are
+const maxRetryDelay = 30 * time.Second
+
+// retrySleep waits between attempts, returning early with an error if ctx is cancelled;
+// tests replace it to avoid real delays
+var retrySleep = func(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return context.Cause(ctx)
+	}
+}
+
+// newRetryBuiltin creates the retry builtin, which calls a function until it succeeds,
+// backing off exponentially between failed attempts
+func newRetryBuiltin() *starlark.Builtin {
+	return starlark.NewBuiltin("retry", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		var callable starlark.Callable
+		attempts := 3
+		var backoffValue starlark.Value = starlark.Float(1.0)
+		var retryOn *starlark.List
+		if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "fn", &callable, "attempts?", &attempts, "backoff?", &backoffValue, "retry_on?", &retryOn); err != nil {
+			return nil, err
+		}
+		if attempts < 1 {
+			return nil, fmt.Errorf("%s: attempts must be at least 1, got %d", fn.Name(), attempts)
+		}
+		backoff, ok := starlark.AsFloat(backoffValue)
+		if !ok {
+			return nil, fmt.Errorf("%s: backoff must be a number of seconds, got %s", fn.Name(), backoffValue.Type())
+		}
+		if backoff < 0 {
+			return nil, fmt.Errorf("%s: backoff cannot be negative", fn.Name())
+		}
+		patterns, err := retryPatterns(retryOn)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+		}
+
+		delay := time.Duration(min(backoff, maxRetryDelay.Seconds()) * float64(time.Second))
+		for attempt := 1; ; attempt++ {
+			result, err := starlark.Call(thread, callable, nil, nil)
+			if err == nil {
+				return result, nil
+			}
+			if !retryable(err, patterns) {
+				return nil, err
+			}
+			if attempt == attempts {
+				return nil, fmt.Errorf("%s: giving up after %d attempts: %v", fn.Name(), attempts, err)
+			}
+			if err := retrySleep(threadContext(thread), delay); err != nil {
+				return nil, fmt.Errorf("%s: cancelled while waiting to retry: %v", fn.Name(), err)
+			}
+			delay = min(delay*2, maxRetryDelay)
+		}
+	})
+}
+
+// retryPatterns compiles retry_on into case-insensitive regular expressions
+func retryPatterns(retryOn *starlark.List) ([]*regexp.Regexp, error) {
+	if retryOn == nil {
+		return nil, nil
+	}
+	patterns := make([]*regexp.Regexp, retryOn.Len())
+	for i := 0; i < retryOn.Len(); i++ {
+		pattern, ok := starlark.AsString(retryOn.Index(i))
+		if !ok {
+			return nil, fmt.Errorf("retry_on must be a list of strings, got %s", retryOn.Index(i).Type())
+		}
+		re, err := regexp.Compile("(?i)" + pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid retry_on pattern %q: %v", pattern, err)
+		}
+		patterns[i] = re
+	}
+	return patterns, nil
+}
+
+// retryable reports whether an error matches one of the patterns; with no patterns every error is retried
+func retryable(err error, patterns []*regexp.Regexp) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, re := range patterns {
+		if re.MatchString(err.Error()) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,130 @@
+package starlark
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// flakyProxyManager fails the first failures calls with the given error, then succeeds
+type flakyProxyManager struct {
+	failures int
+	message  string
+	calls    int
+}
+
+func (f *flakyProxyManager) GetAllTools() map[string][]*mcp.Tool {
+	return map[string][]*mcp.Tool{"github": {{Name: "get_issue"}}}
+}
+
+func (f *flakyProxyManager) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	f.calls++
+	if f.calls <= f.failures {
+		return nil, fmt.Errorf("%s", f.message)
+	}
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+}
+
+func TestRetry(t *testing.T) {
+	var delays []time.Duration
+	defer func(sleep func(context.Context, time.Duration) error) { retrySleep = sleep }(retrySleep)
+	retrySleep = func(ctx context.Context, d time.Duration) error {
+		delays = append(delays, d)
+		return nil
+	}
+
+	tests := []struct {
+		name       string
+		code       string
+		failures   int
+		message    string
+		wantCalls  int
+		wantDelays []time.Duration
+		wantError  string
+	}{
+		{
+			name:       "succeeds after failures",
+			code:       `retry(lambda: github.get_issue(number=1))["content"][0]`,
+			failures:   2,
+			message:    "connection reset",
+			wantCalls:  3,
+			wantDelays: []time.Duration{time.Second, 2 * time.Second},
+		},
+		{
+			name:      "succeeds first time",
+			code:      `retry(lambda: github.get_issue(number=1))["content"][0]`,
+			wantCalls: 1,
+		},
+		{
+			name:       "gives up after attempts",
+			code:       `retry(lambda: github.get_issue(number=1), attempts=2, backoff=0.5)`,
+			failures:   5,
+			message:    "connection reset",
+			wantCalls:  2,
+			wantDelays: []time.Duration{500 * time.Millisecond},
+			wantError:  "giving up after 2 attempts",
+		},
+		{
+			name:       "retries matching errors",
+			code:       `retry(lambda: github.get_issue(number=1), backoff=0, retry_on=["RESET", "timeout"])["content"][0]`,
+			failures:   1,
+			message:    "connection reset",
+			wantCalls:  2,
+			wantDelays: []time.Duration{0},
+		},
+		{
+			name:      "fails fast on other errors",
+			code:      `retry(lambda: github.get_issue(number=1), retry_on=["timeout"])`,
+			failures:  1,
+			message:   "not found",
+			wantCalls: 1,
+			wantError: "not found",
+		},
+		{
+			name:       "delays are capped",
+			code:       `retry(lambda: github.get_issue(number=1), attempts=3, backoff=20.0)["content"][0]`,
+			failures:   2,
+			message:    "connection reset",
+			wantCalls:  3,
+			wantDelays: []time.Duration{20 * time.Second, maxRetryDelay},
+		},
+		{
+			name:      "invalid attempts",
+			code:      `retry(lambda: 1, attempts=0)`,
+			wantError: "attempts must be at least 1",
+		},
+		{
+			name:      "invalid pattern",
+			code:      `retry(lambda: 1, retry_on=["("])`,
+			wantError: "invalid retry_on pattern",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delays = nil
+			proxy := &flakyProxyManager{failures: tt.failures, message: tt.message}
+			result, err := ExecuteWithProxy(tt.code, nil, proxy)
+			if err != nil {
+				t.Fatalf("ExecuteWithProxy() error = %v", err)
+			}
+			if tt.wantError != "" {
+				if !strings.Contains(result.Error, tt.wantError) {
+					t.Errorf("ExecuteWithProxy() error = %q, want it to contain %q", result.Error, tt.wantError)
+				}
+			} else if result.Error != "" || result.Result != "ok" {
+				t.Errorf("ExecuteWithProxy() = %v (error %q), want ok", result.Result, result.Error)
+			}
+			if proxy.calls != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", proxy.calls, tt.wantCalls)
+			}
+			if fmt.Sprint(delays) != fmt.Sprint(tt.wantDelays) {
+				t.Errorf("slept %v, want %v", delays, tt.wantDelays)
+			}
+		})
+	}
+}
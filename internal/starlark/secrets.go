@@ -0,0 +1,32 @@
+package starlark
+
+import (
+	"fmt"
+
+	"go.starlark.net/starlark"
+	"go.starlark.net/starlarkstruct"
+
+	"github.com/dslh/mcp-metatool/internal/secrets"
+)
+
+// SecretsModule gives code access to stored secrets by name; it deliberately can't list them
+var SecretsModule = &starlarkstruct.Module{
+	Name: "secrets",
+	Members: starlark.StringDict{
+		"get": starlark.NewBuiltin("secrets.get", secretsGet),
+	},
+}
+
+// secretsGet returns the value of a named secret, failing if it isn't set
+func secretsGet(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	if err := starlark.UnpackPositionalArgs(fn.Name(), args, kwargs, 1, &name); err != nil {
+		return nil, err
+	}
+
+	value, err := secrets.Get(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+	}
+	return starlark.String(value), nil
+}
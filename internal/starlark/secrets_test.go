@@ -0,0 +1,59 @@
+package starlark
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/redact"
+	"github.com/dslh/mcp-metatool/internal/secrets"
+)
+
+func TestSecretsModule(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	defer redact.Reset()
+	if err := secrets.Set("api_key", "s3cret"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+	if err := secrets.Set("api_key_long", "sk_live_abcdef"); err != nil {
+		t.Fatalf("Set() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		code      string
+		expected  interface{}
+		wantError string
+	}{
+		{"get", `secrets.get("api_key")`, "s3cret", ""},
+		{"missing", `secrets.get("other")`, nil, "secret 'other' is not set"},
+		{"no listing", `dir(secrets)`, []interface{}{"get"}, ""},
+		{"masked in errors", `fail("token " + secrets.get("api_key_long"))`, nil, "token [REDACTED]"},
+	}
+
+	// Code that hasn't been granted the module can't read secrets
+	result, err := Execute(`secrets.get("api_key")`, nil)
+	if err != nil || !strings.Contains(result.Error, "secrets is a restricted module") {
+		t.Errorf("Execute() without permission = %+v, %v, want a permission error", result, err)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Execute(tt.code, nil, WithPermissions("secrets"))
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if tt.wantError != "" {
+				if !strings.Contains(result.Error, tt.wantError) {
+					t.Errorf("Execute() error = %q, want it to contain %q", result.Error, tt.wantError)
+				}
+				return
+			}
+			if result.Error != "" {
+				t.Fatalf("Execute() script error = %s", result.Error)
+			}
+			if !equalValues(tt.expected, result.Result) {
+				t.Errorf("Execute() = %v, want %v", result.Result, tt.expected)
+			}
+		})
+	}
+}
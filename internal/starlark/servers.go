@@ -0,0 +1,102 @@
+package starlark
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/proxy"
+)
+
+// WithAllowedServers limits the code to the named upstream servers, given by their configured or
+// namespace names. Other servers get no namespace and can't be called. No names allows every server.
+func WithAllowedServers(names ...string) Option {
+	return func(o *options) {
+		o.allowedServers = append(o.allowedServers, names...)
+	}
+}
+
+// serverFilter exposes only the allowed servers of a proxy manager
+type serverFilter struct {
+	proxyManager ProxyManager
+	allowed      map[string]bool
+}
+
+// filterServers restricts a proxy manager to the allowed servers, unless none are named
+func filterServers(proxyManager ProxyManager, names []string) ProxyManager {
+	if proxyManager == nil || len(names) == 0 {
+		return proxyManager
+	}
+	allowed := make(map[string]bool, len(names))
+	for _, name := range names {
+		allowed[name] = true
+	}
+	return &serverFilter{proxyManager: proxyManager, allowed: allowed}
+}
+
+// allows reports whether a server may be used, by its configured or namespace name
+func (f *serverFilter) allows(serverName string) bool {
+	return f.allowed[serverName] || f.allowed[NamespaceName(f.proxyManager, serverName)]
+}
+
+// check returns an error for servers that may not be used
+func (f *serverFilter) check(serverName string) error {
+	if !f.allows(serverName) {
+		return fmt.Errorf("server %s is not in this tool's allowed servers", serverName)
+	}
+	return nil
+}
+
+// GetAllTools returns the tools of the allowed servers
+func (f *serverFilter) GetAllTools() map[string][]*mcp.Tool {
+	tools := make(map[string][]*mcp.Tool)
+	for serverName, serverTools := range f.proxyManager.GetAllTools() {
+		if f.allows(serverName) {
+			tools[serverName] = serverTools
+		}
+	}
+	return tools
+}
+
+// PendingServers returns the allowed servers that have yet to be started
+func (f *serverFilter) PendingServers() []string {
+	var pending []string
+	for _, serverName := range proxy.PendingServers(f.proxyManager) {
+		if f.allows(serverName) {
+			pending = append(pending, serverName)
+		}
+	}
+	return pending
+}
+
+// EnsureStarted starts an allowed server
+func (f *serverFilter) EnsureStarted(serverName string) ([]*mcp.Tool, error) {
+	if err := f.check(serverName); err != nil {
+		return nil, err
+	}
+	return proxy.EnsureStarted(f.proxyManager, serverName)
+}
+
+// ToolPrefix passes through to the wrapped proxy manager
+func (f *serverFilter) ToolPrefix(serverName string) string {
+	return proxy.ToolPrefix(f.proxyManager, serverName)
+}
+
+// ToolAliases passes through to the wrapped proxy manager
+func (f *serverFilter) ToolAliases(serverName string) map[string]string {
+	return proxy.ToolAliases(f.proxyManager, serverName)
+}
+
+// CallTool calls a tool on an allowed server
+func (f *serverFilter) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return f.CallToolContext(context.Background(), serverName, toolName, arguments)
+}
+
+// CallToolContext is CallTool, forwarding ctx to the wrapped proxy manager
+func (f *serverFilter) CallToolContext(ctx context.Context, serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if err := f.check(serverName); err != nil {
+		return nil, err
+	}
+	return proxy.CallToolContext(ctx, f.proxyManager, serverName, toolName, arguments)
+}
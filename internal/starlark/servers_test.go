@@ -0,0 +1,44 @@
+package starlark
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestWithAllowedServers(t *testing.T) {
+	upstream := NewMockProxyManager()
+	upstream.AddServer("github", []*mcp.Tool{{Name: "get_issue"}})
+	upstream.AddServer("payments-api", []*mcp.Tool{{Name: "refund"}})
+
+	tests := []struct {
+		name      string
+		code      string
+		allowed   []string
+		wantError string
+	}{
+		{"all servers by default", `payments_api.refund(id=1)`, nil, ""},
+		{"allowed server", `github.get_issue(number=1)`, []string{"github"}, ""},
+		{"allowed by namespace", `payments_api.refund(id=1)`, []string{"payments_api"}, ""},
+		{"hidden namespace", `payments_api.refund(id=1)`, []string{"github"}, "undefined: payments_api"},
+		{"call_tool", `call_tool("payments-api", "refund", {"id": 1})`, []string{"github"}, "server payments-api is not configured"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := ExecuteWithProxy(tt.code, nil, upstream, WithAllowedServers(tt.allowed...))
+			if err != nil {
+				t.Fatalf("ExecuteWithProxy() error = %v", err)
+			}
+			if tt.wantError == "" {
+				if result.Error != "" {
+					t.Errorf("Unexpected error: %s", result.Error)
+				}
+				return
+			}
+			if !strings.Contains(result.Error, tt.wantError) {
+				t.Errorf("Error = %q, want it to contain %q", result.Error, tt.wantError)
+			}
+		})
+	}
+}
@@ -0,0 +1,299 @@
+package starlark
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	gotime "time"
+
+	startime "go.starlark.net/lib/time"
+	"go.starlark.net/starlark"
+
+	"github.com/dslh/mcp-metatool/internal/artifacts"
+)
+
+// maxFutures is the most futures one execution may have running at once
+const maxFutures = 16
+
+// spawnerLocalKey is the thread-local key holding the execution's spawner
+const spawnerLocalKey = "metatool.spawner"
+
+// stepQuantum is how many steps a thread draws from its execution's step budget at a time
+const stepQuantum = 1000
+
+// stepBudget is the step limit of an execution, shared by its main thread and every thread it
+// spawns. Threads draw steps from it a quantum at a time and return what they don't use.
+type stepBudget struct {
+	remaining atomic.Int64
+}
+
+// newStepBudget creates a budget of the given number of steps, or nil if steps is 0 (unlimited)
+func newStepBudget(steps uint64) *stepBudget {
+	if steps == 0 {
+		return nil
+	}
+	budget := &stepBudget{}
+	budget.remaining.Store(int64(steps))
+	return budget
+}
+
+// take draws up to a quantum of steps from the budget, returning 0 once it's spent
+func (b *stepBudget) take() uint64 {
+	for {
+		remaining := b.remaining.Load()
+		if remaining <= 0 {
+			return 0
+		}
+		grant := min(remaining, stepQuantum)
+		if b.remaining.CompareAndSwap(remaining, remaining-grant) {
+			return uint64(grant)
+		}
+	}
+}
+
+// attach limits thread to the budget, cancelling it with "too many steps" once the budget is
+// spent. The returned function gives back the steps the thread drew but didn't take once it's finished.
+func (b *stepBudget) attach(thread *starlark.Thread) (release func()) {
+	if b == nil {
+		return func() {}
+	}
+	limit := b.take()
+	thread.OnMaxSteps = func(thread *starlark.Thread) {
+		grant := b.take()
+		if grant == 0 {
+			thread.Cancel("too many steps")
+			return
+		}
+		limit = thread.ExecutionSteps() + grant
+		thread.SetMaxExecutionSteps(limit)
+	}
+	// A limit of 0 means none at all, so a spent budget stops the thread at its first step instead
+	thread.SetMaxExecutionSteps(max(limit, 1))
+	return func() {
+		if steps := thread.ExecutionSteps(); limit > steps {
+			b.remaining.Add(int64(limit - steps))
+		}
+	}
+}
+
+// spawner runs the functions an execution spawns, each on its own thread drawing on the
+// execution's step budget. Every spawned thread is cancelled when the execution finishes.
+type spawner struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	budget  *stepBudget
+	running chan struct{}
+	wg      sync.WaitGroup
+}
+
+// newSpawner creates a spawner whose threads stop when ctx is cancelled
+func newSpawner(ctx context.Context, budget *stepBudget) *spawner {
+	ctx, cancel := context.WithCancel(ctx)
+	return &spawner{ctx: ctx, cancel: cancel, budget: budget, running: make(chan struct{}, maxFutures)}
+}
+
+// stop cancels every spawned thread still running and waits for them to finish
+func (s *spawner) stop() {
+	s.cancel()
+	s.wg.Wait()
+}
+
+// spawn starts calling fn with args on a new thread, inheriting the parent thread's permissions
+// and progress reporting
+func (s *spawner) spawn(parent *starlark.Thread, fn starlark.Callable, args starlark.Tuple, kwargs []starlark.Tuple) (*Future, error) {
+	select {
+	case s.running <- struct{}{}:
+	default:
+		return nil, fmt.Errorf("too many futures running at once (at most %d)", maxFutures)
+	}
+
+	future := &Future{name: fn.Name(), done: make(chan struct{})}
+	thread := &starlark.Thread{Name: "spawn " + fn.Name()}
+	thread.SetLocal(contextLocalKey, s.ctx)
+	thread.SetLocal(spawnerLocalKey, s)
+	thread.SetLocal(permissionsLocalKey, parent.Local(permissionsLocalKey))
+	thread.SetLocal(progressLocalKey, parent.Local(progressLocalKey))
+	thread.SetLocal(proxyLocalKey, parent.Local(proxyLocalKey))
+	thread.SetLocal(artifactsLocalKey, &future.published)
+	release := s.budget.attach(thread)
+	future.thread = thread
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		defer func() { <-s.running }()
+		defer release()
+		defer close(future.done)
+		stop := context.AfterFunc(s.ctx, func() {
+			thread.Cancel(context.Cause(s.ctx).Error())
+		})
+		defer stop()
+
+		future.result, future.err = starlark.Call(thread, fn, args, kwargs)
+		if future.result != nil {
+			future.result.Freeze()
+		}
+	}()
+	return future, nil
+}
+
+// Future is the eventual result of a function started with spawn
+type Future struct {
+	name      string
+	thread    *starlark.Thread
+	done      chan struct{}
+	result    starlark.Value
+	err       error
+	published []*artifacts.Artifact
+
+	collect sync.Once
+}
+
+var _ starlark.HasAttrs = (*Future)(nil)
+
+func (f *Future) String() string        { return fmt.Sprintf("<future %s>", f.name) }
+func (f *Future) Type() string          { return "future" }
+func (f *Future) Freeze()               {}
+func (f *Future) Truth() starlark.Bool  { return starlark.True }
+func (f *Future) Hash() (uint32, error) { return 0, fmt.Errorf("unhashable type: future") }
+
+// Attr returns the future's methods
+func (f *Future) Attr(name string) (starlark.Value, error) {
+	switch name {
+	case "wait":
+		return starlark.NewBuiltin("future.wait", f.wait).BindReceiver(f), nil
+	case "done":
+		return starlark.NewBuiltin("future.done", f.isDone).BindReceiver(f), nil
+	case "cancel":
+		return starlark.NewBuiltin("future.cancel", f.cancelThread).BindReceiver(f), nil
+	}
+	return nil, nil
+}
+
+// AttrNames lists the future's methods
+func (f *Future) AttrNames() []string {
+	return []string{"cancel", "done", "wait"}
+}
+
+// wait blocks until the function returns, then returns its result or raises its error.
+// With a timeout, it fails if the function hasn't returned in time but leaves it running.
+func (f *Future) wait(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var timeoutValue starlark.Value = starlark.None
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "timeout?", &timeoutValue); err != nil {
+		return nil, err
+	}
+	var expired <-chan gotime.Time
+	if timeoutValue != starlark.None {
+		timeout, err := waitTimeout(timeoutValue)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+		}
+		timer := gotime.NewTimer(timeout)
+		defer timer.Stop()
+		expired = timer.C
+	}
+
+	ctx := threadContext(thread)
+	select {
+	case <-f.done:
+	case <-expired:
+		return nil, fmt.Errorf("%s: %s didn't finish within %v", fn.Name(), f.name, timeoutValue)
+	case <-ctx.Done():
+		return nil, fmt.Errorf("%s: %v", fn.Name(), context.Cause(ctx))
+	}
+	if f.err != nil {
+		return nil, fmt.Errorf("%s: %s failed: %v", fn.Name(), f.name, f.err)
+	}
+
+	// Artifacts the function published belong to the code that waits for it
+	f.collect.Do(func() {
+		if published, ok := thread.Local(artifactsLocalKey).(*[]*artifacts.Artifact); ok {
+			*published = append(*published, f.published...)
+		}
+	})
+	return f.result, nil
+}
+
+// isDone reports whether the function has returned or failed
+func (f *Future) isDone(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+	select {
+	case <-f.done:
+		return starlark.True, nil
+	default:
+		return starlark.False, nil
+	}
+}
+
+// cancelThread stops the function at its next step; waiting for it then raises an error
+func (f *Future) cancelThread(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs); err != nil {
+		return nil, err
+	}
+	f.thread.Cancel("cancelled by future.cancel")
+	return starlark.None, nil
+}
+
+// waitTimeout converts a timeout given in seconds or as a duration
+func waitTimeout(value starlark.Value) (gotime.Duration, error) {
+	if d, ok := value.(startime.Duration); ok {
+		return gotime.Duration(d), nil
+	}
+	seconds, ok := starlark.AsFloat(value)
+	if !ok {
+		return 0, fmt.Errorf("timeout must be a number of seconds or a duration, got %s", value.Type())
+	}
+	if seconds < 0 {
+		return 0, fmt.Errorf("timeout cannot be negative")
+	}
+	return gotime.Duration(seconds * float64(gotime.Second)), nil
+}
+
+// newSpawnBuiltin creates the spawn builtin, which starts calling a function in the background
+// and returns a future for its result. The function, its arguments and the globals of the module
+// it's spawned from are frozen, since they're shared between threads.
+func newSpawnBuiltin() *starlark.Builtin {
+	return starlark.NewBuiltin("spawn", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+		if len(args) < 1 {
+			return nil, fmt.Errorf("%s: missing function to call", fn.Name())
+		}
+		callable, ok := args[0].(starlark.Callable)
+		if !ok {
+			return nil, fmt.Errorf("%s: expected a function, got %s", fn.Name(), args[0].Type())
+		}
+		s, ok := thread.Local(spawnerLocalKey).(*spawner)
+		if !ok {
+			return nil, fmt.Errorf("%s: not available here", fn.Name())
+		}
+
+		callArgs := args[1:]
+		freezeModule(callable)
+		if thread.CallStackDepth() > 1 {
+			freezeModule(thread.DebugFrame(1).Callable())
+		}
+		callable.Freeze()
+		callArgs.Freeze()
+		for _, kwarg := range kwargs {
+			kwarg.Freeze()
+		}
+		future, err := s.spawn(thread, callable, callArgs, kwargs)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", fn.Name(), err)
+		}
+		return future, nil
+	})
+}
+
+// freezeModule freezes the globals and predeclared values, such as params, of the module fn was
+// defined in, so threads running its functions can read but not change them
+func freezeModule(fn starlark.Callable) {
+	function, ok := fn.(*starlark.Function)
+	if !ok || function.Module() == nil {
+		return
+	}
+	function.Globals().Freeze()
+	function.Module().Predeclared().Freeze()
+}
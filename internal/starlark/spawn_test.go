@@ -0,0 +1,171 @@
+package starlark
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSpawn(t *testing.T) {
+	tests := []struct {
+		name      string
+		code      string
+		expected  interface{}
+		wantError string
+	}{
+		{
+			name:     "wait for result",
+			code:     "def square(n):\n    return n * n\nf = spawn(square, 7)\nresult = f.wait()",
+			expected: int64(49),
+		},
+		{
+			name:     "keyword arguments",
+			code:     "def greet(name, greeting='Hello'):\n    return greeting + ', ' + name\nresult = spawn(greet, 'Ada', greeting='Hi').wait(timeout=5)",
+			expected: "Hi, Ada",
+		},
+		{
+			name:     "overlapping work",
+			code:     "def total(n):\n    t = 0\n    for i in range(n):\n        t += i\n    return t\nfutures = [spawn(total, n) for n in [10, 100, 1000]]\nlocal = len(futures)\nresult = [f.wait() for f in futures] + [local]",
+			expected: []interface{}{int64(45), int64(4950), int64(499500), int64(3)},
+		},
+		{
+			name:     "done",
+			code:     "def quick():\n    return 1\nf = spawn(quick)\nf.wait()\nresult = f.done()",
+			expected: true,
+		},
+		{
+			name:      "errors are raised by wait",
+			code:      "def broken():\n    fail('boom')\nresult = spawn(broken).wait()",
+			wantError: "broken failed: fail: boom",
+		},
+		{
+			name:      "timeout",
+			code:      "def forever():\n    while True:\n        pass\nresult = spawn(forever).wait(timeout=0.05)",
+			wantError: "forever didn't finish within 0.05",
+		},
+		{
+			name:      "duration timeout",
+			code:      "def forever():\n    while True:\n        pass\nresult = spawn(forever).wait(timeout=time.millisecond * 50)",
+			wantError: "didn't finish within 50ms",
+		},
+		{
+			name:      "cancel",
+			code:      "def forever():\n    while True:\n        pass\nf = spawn(forever)\nf.cancel()\nresult = f.wait()",
+			wantError: "cancelled by future.cancel",
+		},
+		{
+			name:      "arguments are frozen",
+			code:      "def grow(items):\n    items.append(1)\nresult = spawn(grow, [1, 2]).wait()",
+			wantError: "frozen list",
+		},
+		{
+			name:      "limit on running futures",
+			code:      "def forever():\n    while True:\n        pass\nresult = [spawn(forever) for i in range(20)]",
+			wantError: "too many futures running at once",
+		},
+		{
+			name:      "not a function",
+			code:      "spawn(42)",
+			wantError: "expected a function, got int",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Execute(tt.code, nil)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if tt.wantError != "" {
+				if !strings.Contains(result.Error, tt.wantError) {
+					t.Errorf("Execute() error = %q, want it to contain %q", result.Error, tt.wantError)
+				}
+				return
+			}
+			if result.Error != "" {
+				t.Fatalf("Execute() script error = %s", result.Error)
+			}
+			if !equalValues(tt.expected, result.Result) {
+				t.Errorf("Execute() = %v, want %v", result.Result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSpawn_StopsWithExecution(t *testing.T) {
+	// A future nobody waits for is cancelled when the execution finishes, so it can't outlive it
+	code := "def forever():\n    while True:\n        pass\nspawn(forever)\nresult = 'finished'"
+	done := make(chan *Result)
+	go func() {
+		result, _ := Execute(code, nil)
+		done <- result
+	}()
+	select {
+	case result := <-done:
+		if result.Result != "finished" {
+			t.Errorf("Execute() = %+v, want finished", result)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("execution waited for a spawned function that never returns")
+	}
+}
+
+func TestSpawn_StepLimit(t *testing.T) {
+	code := "def forever():\n    while True:\n        pass\nresult = spawn(forever).wait()"
+	result, err := Execute(code, nil, WithMaxSteps(10000))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(result.Error, "too many steps") {
+		t.Errorf("Expected the spawned function to hit the step limit, got %q", result.Error)
+	}
+}
+
+func TestSpawn_FreezesGlobals(t *testing.T) {
+	tests := []struct {
+		name string
+		code string
+	}{
+		{
+			name: "spawned functions can't change globals",
+			code: "seen = []\ndef record(n):\n    seen.append(n)\nresult = [f.wait() for f in [spawn(record, n) for n in range(4)]]",
+		},
+		{
+			name: "the spawning code can't change globals",
+			code: "seen = []\ndef quick():\n    return 1\nf = spawn(quick)\nseen.append(f.wait())\nresult = seen",
+		},
+		{
+			name: "params",
+			code: "def main(params):\n    f = spawn(len, params)\n    params['extra'] = f.wait()\n    return params",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Execute(tt.code, map[string]interface{}{"n": 1})
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+			if !strings.Contains(result.Error, "frozen") {
+				t.Errorf("Expected a frozen value error, got %+v", result)
+			}
+		})
+	}
+}
+
+func TestSpawn_SharesStepLimit(t *testing.T) {
+	// Each spawned loop fits the limit on its own, but not all of them together
+	code := "def count():\n    t = 0\n    for i in range(500):\n        t += i\n    return t\nresult = [f.wait() for f in [spawn(count) for n in range(8)]]"
+	result, err := Execute(code, nil)
+	if err != nil || result.Error != "" {
+		t.Fatalf("Execute() without a limit = %+v, %v", result, err)
+	}
+
+	result, err = Execute(code, nil, WithMaxSteps(20000))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if !strings.Contains(result.Error, "too many steps") {
+		t.Errorf("Expected the spawned functions to share the step limit, got %+v", result)
+	}
+}
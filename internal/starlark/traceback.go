@@ -0,0 +1,59 @@
+package starlark
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.starlark.net/starlark"
+)
+
+// Frame is a call in progress when execution failed
+type Frame struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Function string `json:"function"`
+}
+
+// traceback returns the call stack of a failed execution, outermost call first,
+// or nil if the error didn't occur while running code (e.g. a syntax error)
+func traceback(err error) []Frame {
+	var evalErr *starlark.EvalError
+	if !errors.As(err, &evalErr) {
+		return nil
+	}
+	frames := make([]Frame, 0, len(evalErr.CallStack))
+	for _, call := range evalErr.CallStack {
+		frames = append(frames, Frame{
+			File:     call.Pos.Filename(),
+			Line:     int(call.Pos.Line),
+			Column:   int(call.Pos.Col),
+			Function: call.Name,
+		})
+	}
+	return frames
+}
+
+// formatTraceback renders a call stack the way Python does, most recent call last
+func formatTraceback(frames []Frame) string {
+	var b strings.Builder
+	b.WriteString("Traceback (most recent call last):")
+	for _, frame := range frames {
+		if frame.Line == 0 {
+			fmt.Fprintf(&b, "\n  %s: in %s", frame.File, frame.Function)
+			continue
+		}
+		fmt.Fprintf(&b, "\n  %s:%d:%d: in %s", frame.File, frame.Line, frame.Column, frame.Function)
+	}
+	return b.String()
+}
+
+// failedResult reports an execution error along with the traceback of where it occurred
+func failedResult(err error) *Result {
+	frames := traceback(err)
+	if len(frames) == 0 {
+		return &Result{Error: err.Error()}
+	}
+	return &Result{Error: err.Error() + "\n" + formatTraceback(frames), Traceback: frames}
+}
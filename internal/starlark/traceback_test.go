@@ -0,0 +1,73 @@
+package starlark
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestExecute_Traceback(t *testing.T) {
+	code := `def inner(items):
+    return items[5]
+
+def outer():
+    return inner([1, 2])
+
+result = outer()`
+
+	tests := []struct {
+		name     string
+		opts     []Option
+		file     string
+		compiled bool
+	}{
+		{"default file name", nil, "<eval>", false},
+		{"named file", []Option{WithFileName("lookup")}, "lookup", false},
+		{"compiled", []Option{WithFileName("lookup"), WithCompiledCache()}, "lookup", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.compiled {
+				t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+			}
+			result, err := Execute(code, nil, tt.opts...)
+			if err != nil {
+				t.Fatalf("Execute() error = %v", err)
+			}
+
+			want := []Frame{
+				{File: tt.file, Line: 7, Column: 15, Function: "<toplevel>"},
+				{File: tt.file, Line: 5, Column: 17, Function: "outer"},
+				{File: tt.file, Line: 2, Column: 17, Function: "inner"},
+			}
+			if len(result.Traceback) != len(want) {
+				t.Fatalf("Traceback = %+v, want %+v", result.Traceback, want)
+			}
+			for i := range want {
+				if result.Traceback[i] != want[i] {
+					t.Errorf("Traceback[%d] = %+v, want %+v", i, result.Traceback[i], want[i])
+				}
+			}
+
+			if !strings.HasPrefix(result.Error, "Execution error: list index 5 out of range") {
+				t.Errorf("Expected the error message first, got:\n%s", result.Error)
+			}
+			if !strings.Contains(result.Error, "Traceback (most recent call last):\n  "+tt.file+":7:15: in <toplevel>") {
+				t.Errorf("Expected a formatted traceback, got:\n%s", result.Error)
+			}
+		})
+	}
+}
+
+func TestExecute_SyntaxErrorHasNoTraceback(t *testing.T) {
+	result, err := Execute("x = (\ny = 1", nil, WithFileName("broken"))
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Traceback != nil {
+		t.Errorf("Expected no traceback for a syntax error, got %+v", result.Traceback)
+	}
+	if !strings.Contains(result.Error, "broken:") {
+		t.Errorf("Expected the error position to name the file, got %q", result.Error)
+	}
+}
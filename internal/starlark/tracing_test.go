@@ -0,0 +1,71 @@
+package starlark
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+
+	"github.com/dslh/mcp-metatool/internal/tracing"
+)
+
+// tracedProxyManager records a span for each call within the context it was given
+type tracedProxyManager struct {
+	mu      sync.Mutex
+	parents []trace.SpanID
+}
+
+func (p *tracedProxyManager) GetAllTools() map[string][]*mcp.Tool {
+	return map[string][]*mcp.Tool{"github": {{Name: "get_issue"}}}
+}
+
+func (p *tracedProxyManager) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return p.CallToolContext(context.Background(), serverName, toolName, arguments)
+}
+
+func (p *tracedProxyManager) CallToolContext(ctx context.Context, serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	p.mu.Lock()
+	p.parents = append(p.parents, trace.SpanContextFromContext(ctx).SpanID())
+	p.mu.Unlock()
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "ok"}}}, nil
+}
+
+func TestExecuteTracing(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder)))
+	defer otel.SetTracerProvider(previous)
+
+	ctx, root := tracing.Start(context.Background(), "test")
+	proxy := &tracedProxyManager{}
+	code := "a = github.get_issue(number=1)\nb = parallel([(github.get_issue, {'number': 2}), (github.get_issue, {'number': 3})])\nresult = fail('boom')"
+	if _, err := ExecuteWithProxy(code, nil, proxy, WithContext(ctx)); err != nil {
+		t.Fatalf("ExecuteWithProxy() error = %v", err)
+	}
+	root.End()
+
+	spans := recorder.Ended()
+	if len(spans) != 2 || spans[0].Name() != "starlark.execute" {
+		t.Fatalf("Expected a starlark.execute span within the test span, got %d spans", len(spans))
+	}
+	execute := spans[0]
+	if execute.Parent().SpanID() != root.SpanContext().SpanID() {
+		t.Errorf("Expected starlark.execute to be a child of the caller's span")
+	}
+	if execute.Status().Description == "" {
+		t.Errorf("Expected the failed execution to mark its span as failed")
+	}
+	if len(proxy.parents) != 3 {
+		t.Fatalf("Expected 3 upstream calls, got %d", len(proxy.parents))
+	}
+	for i, parent := range proxy.parents {
+		if parent != execute.SpanContext().SpanID() {
+			t.Errorf("Call %d was not made within the starlark.execute span", i)
+		}
+	}
+}
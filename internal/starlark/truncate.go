@@ -0,0 +1,179 @@
+package starlark
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+// DefaultMaxResultBytes is the largest result, as JSON, returned whole unless SetMaxResultBytes is called
+const DefaultMaxResultBytes = 1 << 20
+
+// markerReserve is the room kept for a truncation marker when shrinking a list, dict or string
+const markerReserve = 48
+
+// maxResultBytes bounds the JSON encoding of execution results
+var maxResultBytes atomic.Int64
+
+func init() {
+	maxResultBytes.Store(DefaultMaxResultBytes)
+}
+
+// SetMaxResultBytes sets the size, in bytes of JSON, beyond which results are truncated;
+// zero or less restores DefaultMaxResultBytes
+func SetMaxResultBytes(size int) {
+	if size <= 0 {
+		size = DefaultMaxResultBytes
+	}
+	maxResultBytes.Store(int64(size))
+}
+
+// Truncation describes what was cut from a result too large to return whole
+type Truncation struct {
+	OriginalBytes int `json:"originalBytes"`          // size of the full result as JSON
+	OmittedItems  int `json:"omittedItems"`           // list items and dict entries left out
+	OmittedChars  int `json:"omittedChars,omitempty"` // characters cut from the ends of strings
+}
+
+// limitResult truncates a result whose JSON encoding exceeds the configured limit. Lists and dicts
+// keep the items that fit, followed by a marker counting those left out; long strings are cut short.
+func limitResult(value interface{}) (interface{}, *Truncation) {
+	limit := int(maxResultBytes.Load())
+	size := encodedSize(value)
+	if size <= limit {
+		return value, nil
+	}
+
+	t := &truncator{}
+	limited, ok := t.fit(value, limit)
+	if !ok {
+		limited = fmt.Sprintf("… [result of %d bytes truncated]", size)
+	}
+	return limited, &Truncation{OriginalBytes: size, OmittedItems: t.omittedItems, OmittedChars: t.omittedChars}
+}
+
+// encodedSize returns the length of a value's JSON encoding, or 0 if it can't be encoded
+func encodedSize(value interface{}) int {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
+
+// truncator shrinks values to fit a byte budget, counting what it leaves out
+type truncator struct {
+	omittedItems int
+	omittedChars int
+}
+
+// fit returns the value, or a shrunken copy of it, whose encoding fits the budget.
+// It reports false if nothing useful fits.
+func (t *truncator) fit(value interface{}, budget int) (interface{}, bool) {
+	if encodedSize(value) <= budget {
+		return value, true
+	}
+	if budget <= markerReserve {
+		return nil, false
+	}
+	switch v := value.(type) {
+	case []interface{}:
+		return t.fitList(v, budget), true
+	case map[string]interface{}:
+		return t.fitDict(v, budget), true
+	case string:
+		return t.fitString(v, budget), true
+	default:
+		return nil, false
+	}
+}
+
+// fitList keeps the leading items that fit, shrinking the first one that doesn't if there's room
+func (t *truncator) fitList(items []interface{}, budget int) []interface{} {
+	budget -= len("[]") + markerReserve
+	kept := make([]interface{}, 0)
+	for _, item := range items {
+		size := encodedSize(item) + len(",")
+		if size <= budget {
+			kept = append(kept, item)
+			budget -= size
+			continue
+		}
+		if shrunk, ok := t.fit(item, budget-len(",")); ok {
+			kept = append(kept, shrunk)
+		}
+		break
+	}
+
+	if omitted := len(items) - len(kept); omitted > 0 {
+		t.omittedItems += omitted
+		kept = append(kept, fmt.Sprintf("… [%d more items truncated]", omitted))
+	}
+	return kept
+}
+
+// fitDict keeps the entries that fit, in key order, shrinking the first one that doesn't if there's room
+func (t *truncator) fitDict(dict map[string]interface{}, budget int) map[string]interface{} {
+	keys := make([]string, 0, len(dict))
+	for key := range dict {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	marker := markerKey(dict)
+	budget -= len("{}") + markerReserve + encodedSize(marker)
+	kept := make(map[string]interface{})
+	for _, key := range keys {
+		keySize := encodedSize(key) + len(":,")
+		size := keySize + encodedSize(dict[key])
+		if size <= budget {
+			kept[key] = dict[key]
+			budget -= size
+			continue
+		}
+		if shrunk, ok := t.fit(dict[key], budget-keySize); ok {
+			kept[key] = shrunk
+		}
+		break
+	}
+
+	if omitted := len(dict) - len(kept); omitted > 0 {
+		t.omittedItems += omitted
+		kept[marker] = fmt.Sprintf("[%d more keys truncated]", omitted)
+	}
+	return kept
+}
+
+// markerKey returns the key counting a dict's truncated entries: "…", lengthened until it's not one of the dict's own keys
+func markerKey(dict map[string]interface{}) string {
+	key := "…"
+	for {
+		if _, exists := dict[key]; !exists {
+			return key
+		}
+		key += "…"
+	}
+}
+
+// fitString cuts a string short at a character boundary, marking how much was cut. Quotes, control
+// characters and the like take more room once encoded, so it's the encoded size that must fit.
+func (t *truncator) fitString(s string, budget int) string {
+	runeStart := func(keep int) int {
+		for keep > 0 && keep < len(s) && !utf8.RuneStart(s[keep]) {
+			keep--
+		}
+		return keep
+	}
+	cut := func(keep int) string {
+		return fmt.Sprintf("%s… [%d more characters truncated]", s[:keep], utf8.RuneCountInString(s[keep:]))
+	}
+
+	// Keeping more of the string never makes it shorter encoded, so the longest prefix that fits is found by bisection
+	keep := runeStart(sort.Search(len(s), func(i int) bool {
+		return encodedSize(cut(runeStart(i+1))) > budget
+	}))
+	t.omittedChars += utf8.RuneCountInString(s[keep:])
+	return cut(keep)
+}
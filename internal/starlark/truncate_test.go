@@ -0,0 +1,86 @@
+package starlark
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestLimitResult(t *testing.T) {
+	defer SetMaxResultBytes(0)
+	SetMaxResultBytes(200)
+
+	items := make([]interface{}, 100)
+	for i := range items {
+		items[i] = int64(i)
+	}
+
+	tests := []struct {
+		name      string
+		value     interface{}
+		truncated bool
+		marker    string
+	}{
+		{"small result", []interface{}{"a", "b"}, false, ""},
+		{"long list", items, true, "more items truncated]"},
+		{"large dict", map[string]interface{}{"items": items, "name": "report"}, true, "more items truncated]"},
+		{"long string", strings.Repeat("é", 500), true, "more characters truncated]"},
+		{"string of escaped characters", strings.Repeat(`"<`, 200), true, "more characters truncated]"},
+		{"dict with a marker-like key", map[string]interface{}{"…": "real", "…a": strings.Repeat("x", 300), "…b": "y"}, true, `"……":"[1 more keys truncated]"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limited, truncation := limitResult(tt.value)
+			if !tt.truncated {
+				if truncation != nil {
+					t.Errorf("limitResult() truncated a small result: %+v", truncation)
+				}
+				return
+			}
+			if truncation == nil {
+				t.Fatal("limitResult() didn't truncate a large result")
+			}
+			if truncation.OriginalBytes != encodedSize(tt.value) {
+				t.Errorf("OriginalBytes = %d, want %d", truncation.OriginalBytes, encodedSize(tt.value))
+			}
+			if truncation.OmittedItems == 0 && truncation.OmittedChars == 0 {
+				t.Errorf("Expected omitted items or characters to be counted, got %+v", truncation)
+			}
+
+			data, _ := json.Marshal(limited)
+			if len(data) > 200 {
+				t.Errorf("Truncated result is %d bytes, more than the 200 allowed: %s", len(data), data)
+			}
+			if !strings.Contains(string(data), tt.marker) {
+				t.Errorf("Expected a truncation marker %q, got %s", tt.marker, data)
+			}
+			if dict, ok := tt.value.(map[string]interface{}); ok && dict["…"] != nil {
+				if kept := limited.(map[string]interface{}); kept["…"] != dict["…"] {
+					t.Errorf("Expected the dict's own \"…\" entry to be kept, got %v", kept["…"])
+				}
+			}
+		})
+	}
+}
+
+func TestExecute_TruncatesResult(t *testing.T) {
+	defer SetMaxResultBytes(0)
+	SetMaxResultBytes(100)
+
+	result, err := Execute("list(range(1000))", nil)
+	if err != nil {
+		t.Fatalf("Execute() error = %v", err)
+	}
+	if result.Truncated == nil || result.Truncated.OmittedItems == 0 {
+		t.Fatalf("Expected the result to be truncated, got %+v", result.Truncated)
+	}
+	items := result.Result.([]interface{})
+	kept := len(items) - 1
+	if kept+result.Truncated.OmittedItems != 1000 {
+		t.Errorf("Kept %d and omitted %d items, want 1000 in all", kept, result.Truncated.OmittedItems)
+	}
+	if marker, _ := items[kept].(string); !strings.Contains(marker, "more items truncated") {
+		t.Errorf("Expected the last item to be a truncation marker, got %v", items[kept])
+	}
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMemFS_ReadWrite(t *testing.T) {
+	m := NewMemFS()
+	dir := filepath.Join("/data", "tools")
+
+	if err := m.WriteFile(filepath.Join(dir, "a.json"), []byte("a"), 0644); !os.IsNotExist(err) {
+		t.Errorf("Expected not-exist error writing into missing directory, got %v", err)
+	}
+
+	if err := m.MkdirAll(dir, 0755); err != nil {
+		t.Fatalf("MkdirAll() error = %v", err)
+	}
+	if err := m.WriteFile(filepath.Join(dir, "a.json"), []byte("a"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	data, err := m.ReadFile(filepath.Join(dir, "a.json"))
+	if err != nil || string(data) != "a" {
+		t.Errorf("ReadFile() = %q, %v", data, err)
+	}
+
+	if _, err := m.ReadFile(filepath.Join(dir, "missing.json")); !os.IsNotExist(err) {
+		t.Errorf("Expected not-exist error, got %v", err)
+	}
+
+	info, err := m.Stat(filepath.Join(dir, "a.json"))
+	if err != nil || info.Size() != 1 || info.IsDir() {
+		t.Errorf("Stat() = %+v, %v", info, err)
+	}
+}
+
+func TestMemFS_ReadDir(t *testing.T) {
+	m := NewMemFS()
+	m.MkdirAll("/data/tools/history", 0755)
+	m.WriteFile("/data/tools/b.json", []byte("b"), 0644)
+	m.WriteFile("/data/tools/a.json", []byte("a"), 0644)
+	m.WriteFile("/data/tools/history/v1.json", []byte("v1"), 0644)
+
+	entries, err := m.ReadDir("/data/tools")
+	if err != nil {
+		t.Fatalf("ReadDir() error = %v", err)
+	}
+
+	var names []string
+	for _, entry := range entries {
+		names = append(names, entry.Name())
+	}
+	expected := []string{"a.json", "b.json", "history"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("Expected %v, got %v", expected, names)
+		}
+	}
+	if !entries[2].IsDir() {
+		t.Error("Expected history to be a directory")
+	}
+
+	if _, err := m.ReadDir("/missing"); !os.IsNotExist(err) {
+		t.Errorf("Expected not-exist error, got %v", err)
+	}
+}
+
+func TestMemFS_Remove(t *testing.T) {
+	m := NewMemFS()
+	m.MkdirAll("/data", 0755)
+	m.WriteFile("/data/a.json", []byte("a"), 0644)
+
+	if err := m.Remove("/data"); err == nil {
+		t.Error("Expected error removing non-empty directory")
+	}
+	if err := m.Remove("/data/a.json"); err != nil {
+		t.Errorf("Remove() error = %v", err)
+	}
+	if err := m.Remove("/data/a.json"); !os.IsNotExist(err) {
+		t.Errorf("Expected not-exist error, got %v", err)
+	}
+	if err := m.Remove("/data"); err != nil {
+		t.Errorf("Expected empty directory to be removable, got %v", err)
+	}
+}
+
+func TestUseMemory(t *testing.T) {
+	dir := t.TempDir()
+	UseMemory()
+	defer UseDisk()
+
+	if !IsEphemeral() {
+		t.Error("Expected IsEphemeral() after UseMemory()")
+	}
+
+	MkdirAll(dir, 0755)
+	if err := WriteFile(filepath.Join(dir, "note.txt"), []byte("hi"), 0644); err != nil {
+		t.Fatalf("WriteFile() error = %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "note.txt")); !os.IsNotExist(err) {
+		t.Error("Expected nothing to be written to disk in memory mode")
+	}
+
+	UseDisk()
+	if IsEphemeral() {
+		t.Error("Expected disk storage after UseDisk()")
+	}
+}
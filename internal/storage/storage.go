@@ -0,0 +1,97 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+)
+
+// FS is the file system holding the metatool's persistent data (saved tools, approvals, artifacts)
+type FS interface {
+	ReadFile(name string) ([]byte, error)
+	WriteFile(name string, data []byte, perm fs.FileMode) error
+	ReadDir(name string) ([]fs.DirEntry, error)
+	Stat(name string) (fs.FileInfo, error)
+	MkdirAll(path string, perm fs.FileMode) error
+	Remove(name string) error
+}
+
+// osFS is the default FS backed by the real file system
+type osFS struct{}
+
+func (osFS) ReadFile(name string) ([]byte, error) { return os.ReadFile(name) }
+func (osFS) WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return os.WriteFile(name, data, perm)
+}
+func (osFS) ReadDir(name string) ([]fs.DirEntry, error)   { return os.ReadDir(name) }
+func (osFS) Stat(name string) (fs.FileInfo, error)        { return os.Stat(name) }
+func (osFS) MkdirAll(path string, perm fs.FileMode) error { return os.MkdirAll(path, perm) }
+func (osFS) Remove(name string) error                     { return os.Remove(name) }
+
+// current is the FS used by the package-level helpers
+var current FS = osFS{}
+
+// Use replaces the FS used for persistent data
+func Use(fsys FS) {
+	current = fsys
+}
+
+// UseMemory switches persistent data to a fresh in-memory FS, so nothing is written to disk
+func UseMemory() {
+	current = NewMemFS()
+}
+
+// UseDisk restores the default disk-backed FS
+func UseDisk() {
+	current = osFS{}
+}
+
+// IsEphemeral reports whether persistent data is currently held in memory
+func IsEphemeral() bool {
+	_, ok := current.(*MemFS)
+	return ok
+}
+
+// ReadFile reads the named file from the current FS
+func ReadFile(name string) ([]byte, error) {
+	return current.ReadFile(name)
+}
+
+// WriteFile writes data to the named file on the current FS
+func WriteFile(name string, data []byte, perm fs.FileMode) error {
+	return current.WriteFile(name, data, perm)
+}
+
+// ReadDir lists the named directory on the current FS
+func ReadDir(name string) ([]fs.DirEntry, error) {
+	return current.ReadDir(name)
+}
+
+// Stat describes the named file on the current FS
+func Stat(name string) (fs.FileInfo, error) {
+	return current.Stat(name)
+}
+
+// MkdirAll creates a directory and any missing parents on the current FS
+func MkdirAll(path string, perm fs.FileMode) error {
+	return current.MkdirAll(path, perm)
+}
+
+// Remove deletes the named file on the current FS
+func Remove(name string) error {
+	return current.Remove(name)
+}
+
+// ErrEphemeral is returned for writes that can't be kept in memory in ephemeral mode
+var ErrEphemeral = errors.New("nothing is written to disk in ephemeral mode")
+
+// WriteDiskFile writes a file that has to be on disk whatever FS is current, such as servers.json,
+// secrets, or a file named by the user. In ephemeral mode it fails instead, since the file would
+// otherwise land in the real metatool directory or outlive the session.
+func WriteDiskFile(name string, data []byte, perm fs.FileMode) error {
+	if IsEphemeral() {
+		return fmt.Errorf("not writing %s: %w", name, ErrEphemeral)
+	}
+	return os.WriteFile(name, data, perm)
+}
@@ -0,0 +1,29 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteDiskFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "servers.json")
+
+	UseMemory()
+	err := WriteDiskFile(path, []byte("{}"), 0644)
+	UseDisk()
+	if !errors.Is(err, ErrEphemeral) {
+		t.Errorf("WriteDiskFile() in ephemeral mode error = %v, want ErrEphemeral", err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("Expected nothing on disk in ephemeral mode, got %v", err)
+	}
+
+	if err := WriteDiskFile(path, []byte("{}"), 0644); err != nil {
+		t.Fatalf("WriteDiskFile() error = %v", err)
+	}
+	if data, err := os.ReadFile(path); err != nil || string(data) != "{}" {
+		t.Errorf("ReadFile() = %q, %v", data, err)
+	}
+}
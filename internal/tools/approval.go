@@ -0,0 +1,105 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/approval"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+// PendingApprovalsResponse wraps the pending call list in an object structure expected by MCP
+type PendingApprovalsResponse struct {
+	Calls []*approval.PendingCall `json:"calls"`
+}
+
+// RegisterListPendingApprovals registers the list_pending_approvals tool with the MCP server
+func RegisterListPendingApprovals(server *mcp.Server) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:        "list_pending_approvals",
+		Description: "List upstream tool calls waiting for human approval",
+	}, handleListPendingApprovals)
+}
+
+// RegisterDenyCall registers the deny_call tool with the MCP server. There is no tool to approve calls:
+// approval is up to a human, with mcp-metatool approvals or the admin API, never the agent that made them.
+func RegisterDenyCall(server *mcp.Server) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:        "deny_call",
+		Description: "Deny a pending upstream tool call and discard it",
+	}, handleDenyCall)
+}
+
+func handleListPendingApprovals(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+	calls, err := approval.List()
+	if err != nil {
+		return ErrorResponse("Failed to list pending approvals: %v", err), nil, nil
+	}
+
+	response := PendingApprovalsResponse{Calls: calls}
+
+	if len(calls) == 0 {
+		return SuccessResponse("No calls awaiting approval"), response, nil
+	}
+
+	var callList []string
+	for _, call := range calls {
+		callList = append(callList, fmt.Sprintf("• %s: %s.%s (requested %s)", call.ID, call.Server, call.Tool, call.RequestedAt.Format("2006-01-02 15:04:05")))
+	}
+
+	listText := fmt.Sprintf("Found %d call(s) awaiting approval:\n\n%s", len(calls), strings.Join(callList, "\n"))
+
+	return SuccessResponse(listText), response, nil
+}
+
+func handleDenyCall(ctx context.Context, req *mcp.CallToolRequest, args types.ApprovalArgs) (*mcp.CallToolResult, any, error) {
+	if args.ID == "" {
+		return ErrorResponse("Error: approval id is required"), nil, nil
+	}
+
+	call, err := approval.Load(args.ID)
+	if err != nil {
+		return ErrorResponse("Failed to load pending call: %v", err), nil, nil
+	}
+
+	if err := approval.Remove(call.ID); err != nil {
+		return ErrorResponse("Failed to deny pending call: %v", err), nil, nil
+	}
+
+	return SuccessResponse("Call %s to %s.%s denied", call.ID, call.Server, call.Tool), map[string]string{"denied": call.ID}, nil
+}
+
+// supportsElicitation reports whether the calling client can answer elicitation requests
+func supportsElicitation(req *mcp.CallToolRequest) bool {
+	if req == nil || req.Session == nil {
+		return false
+	}
+	params := req.Session.InitializeParams()
+	return params != nil && params.Capabilities != nil && params.Capabilities.Elicitation != nil
+}
+
+// handleGatedProxiedTool asks the client to approve a gated call, or queues it if the client can't be asked
+func handleGatedProxiedTool(ctx context.Context, req *mcp.CallToolRequest, proxyManager ProxyManager, serverName, toolName string, args ProxiedToolArgs) (*mcp.CallToolResult, any, error) {
+	if supportsElicitation(req) {
+		result, err := req.Session.Elicit(ctx, &mcp.ElicitParams{
+			Message: fmt.Sprintf("Allow call to %s.%s with arguments %v?", serverName, toolName, map[string]interface{}(args)),
+		})
+		if err != nil {
+			return ErrorResponse("Failed to request approval: %v", err), nil, nil
+		}
+		if result.Action != "accept" {
+			return ErrorResponse("Call to %s.%s was not approved (%s)", serverName, toolName, result.Action), nil, nil
+		}
+		return handleProxiedTool(ctx, proxyManager, serverName, toolName, args)
+	}
+
+	call, err := approval.Enqueue(serverName, toolName, map[string]interface{}(args))
+	if err != nil {
+		return ErrorResponse("Failed to queue call for approval: %v", err), nil, nil
+	}
+
+	return SuccessResponse("Call to %s.%s requires approval and has been queued as %s. A human can approve it with mcp-metatool approvals approve %s; deny_call withdraws it.", serverName, toolName, call.ID, call.ID), call, nil
+}
@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/approval"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+func TestHandleGatedProxiedToolQueuesCall(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	mockProxy := NewMockProxyManager()
+
+	// Without a session there is no way to elicit approval, so the call is queued
+	result, structured, err := handleGatedProxiedTool(context.Background(), nil, mockProxy, "github", "delete_repo", ProxiedToolArgs{"repo": "demo"})
+	if err != nil {
+		t.Fatalf("handleGatedProxiedTool failed: %v", err)
+	}
+
+	verifyTextContent(t, result, "requires approval")
+
+	call, ok := structured.(*approval.PendingCall)
+	if !ok {
+		t.Fatalf("Expected *approval.PendingCall, got %T", structured)
+	}
+
+	calls, err := approval.List()
+	if err != nil {
+		t.Fatalf("approval.List() error = %v", err)
+	}
+	if len(calls) != 1 || calls[0].ID != call.ID {
+		t.Errorf("Expected the call to be queued, got %+v", calls)
+	}
+}
+
+func TestHandleListPendingApprovals(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	result, _, err := handleListPendingApprovals(context.Background(), nil, struct{}{})
+	if err != nil {
+		t.Fatalf("handleListPendingApprovals failed: %v", err)
+	}
+	verifyTextContent(t, result, "No calls awaiting approval")
+
+	approval.Enqueue("github", "delete_repo", nil)
+
+	result, structured, err := handleListPendingApprovals(context.Background(), nil, struct{}{})
+	if err != nil {
+		t.Fatalf("handleListPendingApprovals failed: %v", err)
+	}
+	verifyTextContent(t, result, "github.delete_repo")
+
+	response, ok := structured.(PendingApprovalsResponse)
+	if !ok || len(response.Calls) != 1 {
+		t.Errorf("Expected 1 pending call in response, got %+v", structured)
+	}
+}
+
+func TestApproveCallNotOffered(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	// Agents may withdraw calls they queued, but only a human may approve them
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterBuiltinTools(server, Dependencies{Upstream: NewMockProxyManager()})
+	tools := listServerTools(t, server)
+	if _, ok := tools["approve_call"]; ok {
+		t.Error("Expected approve_call not to be offered to agents")
+	}
+	if _, ok := tools["deny_call"]; !ok {
+		t.Error("Expected deny_call to be offered")
+	}
+}
+
+func TestHandleDenyCall(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	call, _ := approval.Enqueue("github", "delete_repo", nil)
+
+	result, _, err := handleDenyCall(context.Background(), nil, types.ApprovalArgs{ID: call.ID})
+	if err != nil {
+		t.Fatalf("handleDenyCall failed: %v", err)
+	}
+	verifyTextContent(t, result, "denied")
+
+	calls, _ := approval.List()
+	if len(calls) != 0 {
+		t.Errorf("Expected denied call to be removed, got %d pending", len(calls))
+	}
+}
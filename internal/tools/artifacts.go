@@ -0,0 +1,56 @@
+package tools
+
+import (
+	"context"
+	"unicode/utf8"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/artifacts"
+)
+
+// RegisterArtifactResources registers the published artifacts resource template with the MCP server
+func RegisterArtifactResources(server *mcp.Server) {
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "artifacts",
+		Description: "Files published by Starlark code via publish_artifact",
+		URITemplate: artifacts.URIPrefix + "{name}",
+	}, handleReadArtifact)
+}
+
+// handleReadArtifact serves the content of a published artifact
+func handleReadArtifact(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	name, ok := artifacts.NameFromURI(uri)
+	if !ok {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	artifact, content, err := artifacts.Read(name)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	contents := &mcp.ResourceContents{URI: artifact.URI, MIMEType: artifact.MIMEType}
+	if utf8.Valid(content) {
+		contents.Text = string(content)
+	} else {
+		contents.Blob = content
+	}
+
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{contents}}, nil
+}
+
+// withArtifactLinks appends a resource link to the response for each published artifact
+func withArtifactLinks(response *mcp.CallToolResult, published []*artifacts.Artifact) *mcp.CallToolResult {
+	for _, artifact := range published {
+		size := artifact.Size
+		response.Content = append(response.Content, &mcp.ResourceLink{
+			URI:      artifact.URI,
+			Name:     artifact.Name,
+			MIMEType: artifact.MIMEType,
+			Size:     &size,
+		})
+	}
+	return response
+}
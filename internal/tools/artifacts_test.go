@@ -0,0 +1,66 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/artifacts"
+)
+
+func TestHandleEvalStarlark_ArtifactLinks(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	args := EvalStarlarkArgs{Code: `uri = publish_artifact("export.json", json.encode({"rows": 3}), mime="application/json")
+result = "published"`}
+
+	result, _, err := handleEvalStarlark(context.Background(), nil, args, nil)
+	if err != nil {
+		t.Fatalf("handleEvalStarlark() error = %v", err)
+	}
+
+	if len(result.Content) != 2 {
+		t.Fatalf("Expected text content plus resource link, got %d blocks", len(result.Content))
+	}
+
+	link, ok := result.Content[1].(*mcp.ResourceLink)
+	if !ok {
+		t.Fatalf("Expected ResourceLink, got %T", result.Content[1])
+	}
+	if link.URI != "metatool://artifacts/export.json" {
+		t.Errorf("Unexpected link URI: %s", link.URI)
+	}
+	if link.MIMEType != "application/json" {
+		t.Errorf("Unexpected link MIME type: %s", link.MIMEType)
+	}
+	if link.Size == nil || *link.Size != int64(len(`{"rows":3}`)) {
+		t.Errorf("Unexpected link size: %v", link.Size)
+	}
+}
+
+func TestHandleReadArtifact(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	if _, err := artifacts.Publish("report.csv", "a,b\n", "text/csv"); err != nil {
+		t.Fatalf("Publish() error = %v", err)
+	}
+
+	req := &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "metatool://artifacts/report.csv"}}
+	result, err := handleReadArtifact(context.Background(), req)
+	if err != nil {
+		t.Fatalf("handleReadArtifact() error = %v", err)
+	}
+
+	if len(result.Contents) != 1 {
+		t.Fatalf("Expected 1 content entry, got %d", len(result.Contents))
+	}
+	if result.Contents[0].Text != "a,b\n" || result.Contents[0].MIMEType != "text/csv" {
+		t.Errorf("Unexpected contents: %+v", result.Contents[0])
+	}
+
+	missing := &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "metatool://artifacts/missing.txt"}}
+	if _, err := handleReadArtifact(context.Background(), missing); err == nil {
+		t.Error("Expected error for missing artifact")
+	}
+}
@@ -0,0 +1,125 @@
+package tools
+
+import (
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+)
+
+// builtinOverrides holds the configured renames, descriptions and visibility of built-in tools
+var builtinOverrides map[string]config.BuiltinToolConfig
+
+// builtinNames records the advertised names of the registered built-in tools
+var builtinNames = make(map[string]bool)
+
+// readOnly disables the built-in tools that change saved tools, pending calls or servers
+var readOnly bool
+
+// mutatingBuiltins are the built-in tools not registered in read-only mode
+var mutatingBuiltins = map[string]bool{
+	"save_tool":            true,
+	"patch_saved_tool":     true,
+	"delete_saved_tool":    true,
+	"delete_saved_tools":   true,
+	"prune_saved_tools":    true,
+	"rollback_saved_tool":  true,
+	"restore_saved_tool":   true,
+	"rename_saved_tool":    true,
+	"duplicate_saved_tool": true,
+	"deny_call":            true,
+	"restart_server":       true,
+	"reload_config":        true,
+	"add_server":           true,
+	"remove_server":        true,
+	"curate_tools":         true,
+}
+
+// Dependencies are what built-in tools need from the running metatool.
+// Nil fields leave the tools that need them registered but reporting that they're unavailable.
+type Dependencies struct {
+	Upstream        ProxyManager // ungated, used to manage servers
+	GatedUpstream   ProxyManager // used by Starlark code, subject to approval gating
+	StarlarkOptions []starlark.Option
+	Reloader        ConfigReloader
+	Editor          ConfigEditor
+}
+
+// RegisterBuiltinTools registers every built-in tool with the MCP server
+func RegisterBuiltinTools(server *mcp.Server, deps Dependencies) {
+	RegisterEvalStarlark(server, deps.GatedUpstream, deps.StarlarkOptions...)
+	RegisterSaveTool(server, deps.GatedUpstream, deps.StarlarkOptions...)
+	RegisterPatchSavedTool(server, deps.GatedUpstream, deps.StarlarkOptions...)
+	RegisterListSavedTools(server)
+	RegisterShowSavedTool(server)
+	RegisterDeleteSavedTool(server)
+	RegisterDeleteSavedTools(server)
+	RegisterPruneSavedTools(server)
+	RegisterExportContext(server)
+	RegisterTestSavedTool(server, deps.GatedUpstream, deps.StarlarkOptions...)
+	RegisterListToolVersions(server)
+	RegisterRollbackSavedTool(server)
+	RegisterRestoreSavedTool(server)
+	RegisterRenameSavedTool(server, deps.GatedUpstream, deps.StarlarkOptions...)
+	RegisterDuplicateSavedTool(server, deps.GatedUpstream, deps.StarlarkOptions...)
+	RegisterListPendingApprovals(server)
+	RegisterDenyCall(server)
+	RegisterListServers(server, deps.Upstream)
+	RegisterServerStatus(server, deps.Upstream)
+	RegisterRestartServer(server, deps.Upstream)
+	RegisterReloadConfig(server, deps.Reloader)
+	RegisterAddServer(server, deps.Editor)
+	RegisterRemoveServer(server, deps.Editor)
+	RegisterContextCost(server)
+	RegisterToolStats(server)
+	RegisterCurateTools(server, deps.Editor)
+	RegisterValidateCall(server, deps.Upstream)
+	RegisterSearchTools(server, deps.Upstream)
+	RegisterDescribeTool(server, deps.Upstream)
+	RegisterCallTool(server, deps.Upstream, deps.GatedUpstream)
+	RegisterVerifyTools(server, deps.Upstream, deps.StarlarkOptions...)
+}
+
+// ConfigureBuiltinTools sets the overrides applied to built-in tools as they are registered
+func ConfigureBuiltinTools(overrides map[string]config.BuiltinToolConfig) {
+	builtinOverrides = overrides
+}
+
+// ConfigureReadOnly sets whether built-in tools that make changes are left unregistered
+func ConfigureReadOnly(enabled bool) {
+	readOnly = enabled
+}
+
+// addBuiltinTool registers a built-in tool, applying any configured override
+func addBuiltinTool[In any](server *mcp.Server, tool *mcp.Tool, handler mcp.ToolHandlerFor[In, any]) {
+	if readOnly && mutatingBuiltins[tool.Name] {
+		return
+	}
+	if override, ok := builtinOverrides[tool.Name]; ok {
+		if override.Hidden {
+			return
+		}
+		if override.Name != "" {
+			tool.Name = override.Name
+		}
+		if override.Description != "" {
+			tool.Description = override.Description
+		}
+	}
+	applyToolLimits[In](tool)
+	builtinNames[tool.Name] = true
+	mcp.AddTool(server, tool, handler)
+}
+
+// outputSchema infers the schema of a built-in tool's structured content from the Go type it returns.
+// Declaring it lets clients rely on typed results instead of parsing the text content.
+func outputSchema[Out any]() *jsonschema.Schema {
+	schema, err := jsonschema.For[Out](nil)
+	if err != nil {
+		panic(fmt.Sprintf("output schema for %T: %v", *new(Out), err))
+	}
+	return schema
+}
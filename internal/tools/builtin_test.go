@@ -0,0 +1,193 @@
+package tools
+
+import (
+	"context"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/results"
+)
+
+// listServerTools connects an in-memory client to the server and returns its tools by name
+func listServerTools(t *testing.T, server *mcp.Server) map[string]*mcp.Tool {
+	t.Helper()
+	ctx := context.Background()
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer clientSession.Close()
+
+	result, err := clientSession.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+
+	tools := make(map[string]*mcp.Tool)
+	for _, tool := range result.Tools {
+		tools[tool.Name] = tool
+	}
+	return tools
+}
+
+func TestBuiltinToolOverrides(t *testing.T) {
+	ConfigureBuiltinTools(map[string]config.BuiltinToolConfig{
+		"save_tool":        {Name: "metatool_save", Description: "Save a metatool"},
+		"eval_starlark":    {Hidden: true},
+		"list_saved_tools": {Description: "Custom listing"},
+	})
+	defer ConfigureBuiltinTools(nil)
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterEvalStarlark(server, nil)
+	RegisterSaveTool(server, nil)
+	RegisterListSavedTools(server)
+	RegisterShowSavedTool(server)
+
+	tools := listServerTools(t, server)
+
+	var names []string
+	for name := range tools {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	expected := []string{"list_saved_tools", "metatool_save", "show_saved_tool"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected tools %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Fatalf("Expected tools %v, got %v", expected, names)
+		}
+	}
+
+	if tools["metatool_save"].Description != "Save a metatool" {
+		t.Errorf("Expected overridden description, got %q", tools["metatool_save"].Description)
+	}
+	if tools["list_saved_tools"].Description != "Custom listing" {
+		t.Errorf("Expected overridden description, got %q", tools["list_saved_tools"].Description)
+	}
+	if tools["show_saved_tool"].Description != "Show the complete definition of a saved tool" {
+		t.Errorf("Expected default description, got %q", tools["show_saved_tool"].Description)
+	}
+}
+
+func TestBuiltinOutputSchemas(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	ctx := context.Background()
+	calledAt := time.Now()
+	upstream := &statusProxyManager{MockProxyManager: NewMockProxyManager(), statuses: []proxy.ServerStatus{
+		{Name: "github", Transport: "stdio", State: proxy.StateConnected, Health: proxy.HealthHealthy, LatencyMs: 2.5, LastSuccessAt: &calledAt},
+	}}
+	upstream.AddMockTool("github", &mcp.Tool{Name: "get_issue", InputSchema: &jsonschema.Schema{Type: "object", Required: []string{"number"}}})
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterBuiltinTools(server, Dependencies{Upstream: upstream, GatedUpstream: upstream})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer clientSession.Close()
+
+	listed, err := clientSession.ListTools(ctx, nil)
+	if err != nil {
+		t.Fatalf("ListTools() error = %v", err)
+	}
+	schemas := make(map[string]*jsonschema.Schema)
+	for _, tool := range listed.Tools {
+		if tool.OutputSchema != nil {
+			schemas[tool.Name] = tool.OutputSchema
+		}
+	}
+
+	calls := []struct {
+		tool string
+		args map[string]any
+	}{
+		{"list_saved_tools", nil}, // before any are saved, so the list is empty
+		{"save_tool", map[string]any{"name": "greet", "description": "Greet someone", "code": `"hello " + params["name"]`, "inputSchema": map[string]any{"type": "object"}}},
+		{"list_saved_tools", nil},
+		{"show_saved_tool", map[string]any{"name": "greet"}},
+		{"patch_saved_tool", map[string]any{"name": "greet", "code": `"hi " + params["name"]`}},
+		{"duplicate_saved_tool", map[string]any{"name": "greet", "newName": "greet_copy"}},
+		{"rename_saved_tool", map[string]any{"name": "greet_copy", "newName": "greet_variant"}},
+		{"eval_starlark", map[string]any{"code": `{"answer": 42}`}},
+		{"list_servers", nil},
+		{"server_status", map[string]any{"name": "github"}},
+		{"context_cost", nil},
+		{"tool_stats", nil},
+		{"curate_tools", nil},
+		{"validate_call", map[string]any{"server": "github", "tool": "get_issue", "arguments": map[string]any{}}},
+		{"search_tools", map[string]any{"query": "get issue"}},
+		{"describe_tool", map[string]any{"name": "greet"}},
+		{"export_context", map[string]any{"name": "greet"}},
+		{"verify_tools", nil},
+	}
+	results.Record("greet", map[string]interface{}{"name": "Ada"}, "hello Ada")
+	for _, call := range calls {
+		result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: call.tool, Arguments: call.args})
+		if err != nil || result.IsError {
+			t.Fatalf("CallTool(%s) = %+v, %v", call.tool, result, err)
+		}
+		schema, declared := schemas[call.tool]
+		if !declared {
+			if call.tool != "save_tool" {
+				t.Errorf("Expected %s to declare an output schema", call.tool)
+			}
+			continue
+		}
+		resolved, err := schema.Resolve(nil)
+		if err != nil {
+			t.Fatalf("Output schema of %s does not resolve: %v", call.tool, err)
+		}
+		if err := resolved.Validate(result.StructuredContent); err != nil {
+			t.Errorf("Structured content of %s does not match its output schema: %v\n%+v", call.tool, err, result.StructuredContent)
+		}
+	}
+}
+
+func TestBuiltinToolsReadOnly(t *testing.T) {
+	ConfigureReadOnly(true)
+	defer ConfigureReadOnly(false)
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterBuiltinTools(server, Dependencies{})
+
+	tools := listServerTools(t, server)
+	for name := range mutatingBuiltins {
+		if _, ok := tools[name]; ok {
+			t.Errorf("Expected %s to be disabled in read-only mode", name)
+		}
+	}
+	if _, ok := tools["reload_config"]; ok {
+		t.Error("Expected reload_config to be disabled in read-only mode, since it changes the running servers")
+	}
+	for _, name := range []string{"eval_starlark", "list_saved_tools", "show_saved_tool", "call_tool"} {
+		if _, ok := tools[name]; !ok {
+			t.Errorf("Expected %s to stay available in read-only mode", name)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/results"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+// ContextURIPrefix is the resource URI prefix under which context bundles are exposed
+const ContextURIPrefix = "metatool://context/"
+
+// minChunkSize keeps bundle chunks large enough to be worth attaching
+const minChunkSize = 200
+
+// ContextBundleResponse describes a context bundle returned by export_context
+type ContextBundleResponse struct {
+	Tool    string    `json:"tool"`
+	URI     string    `json:"uri"`
+	RanAt   time.Time `json:"ranAt"`
+	Summary string    `json:"summary"`
+	Chunks  int       `json:"chunks"`
+}
+
+// RegisterExportContext registers the export_context tool with the MCP server
+func RegisterExportContext(server *mcp.Server) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "export_context",
+		Description:  "Package a saved tool's description and latest result as prompt-ready markdown, summarized and chunked, without rerunning it",
+		OutputSchema: outputSchema[ContextBundleResponse](),
+	}, handleExportContext)
+}
+
+// RegisterContextResources registers the context bundle resource template with the MCP server
+func RegisterContextResources(server *mcp.Server) {
+	server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "context",
+		Description: "The latest result of a saved tool with its description, ready to attach to a conversation",
+		URITemplate: ContextURIPrefix + "{name}",
+		MIMEType:    "text/markdown",
+	}, handleReadContext)
+}
+
+func handleExportContext(ctx context.Context, req *mcp.CallToolRequest, args types.ExportContextArgs) (*mcp.CallToolResult, any, error) {
+	chunkSize := args.ChunkSize
+	if chunkSize == 0 {
+		chunkSize = results.DefaultChunkSize
+	}
+	if chunkSize < minChunkSize {
+		return ErrorResponse("Error: chunkSize must be at least %d", minChunkSize), nil, nil
+	}
+
+	run, chunks, err := contextBundle(args.Name, chunkSize)
+	if err != nil {
+		return ErrorResponse("Error: %v", err), nil, nil
+	}
+
+	uri := ContextURIPrefix + run.Tool
+	response := SuccessResponse("Context bundle for '%s', last run %s, in %d chunk(s). Attach %s to include it in a conversation.",
+		run.Tool, run.RanAt.Format(time.RFC3339), len(chunks), uri)
+	for _, chunk := range chunks {
+		response.Content = append(response.Content, &mcp.EmbeddedResource{
+			Resource: &mcp.ResourceContents{URI: uri, MIMEType: "text/markdown", Text: chunk},
+		})
+	}
+
+	return response, ContextBundleResponse{
+		Tool:    run.Tool,
+		URI:     uri,
+		RanAt:   run.RanAt,
+		Summary: results.Summarize(run.Result),
+		Chunks:  len(chunks),
+	}, nil
+}
+
+// handleReadContext serves a saved tool's context bundle, one resource content per chunk
+func handleReadContext(ctx context.Context, req *mcp.ReadResourceRequest) (*mcp.ReadResourceResult, error) {
+	uri := req.Params.URI
+	if !strings.HasPrefix(uri, ContextURIPrefix) {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	_, chunks, err := contextBundle(strings.TrimPrefix(uri, ContextURIPrefix), results.DefaultChunkSize)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(uri)
+	}
+
+	contents := make([]*mcp.ResourceContents, len(chunks))
+	for i, chunk := range chunks {
+		contents[i] = &mcp.ResourceContents{URI: uri, MIMEType: "text/markdown", Text: chunk}
+	}
+	return &mcp.ReadResourceResult{Contents: contents}, nil
+}
+
+// contextBundle loads a saved tool and its latest result, rendered as bundle chunks
+func contextBundle(name string, chunkSize int) (*results.Run, []string, error) {
+	tool, err := persistence.LoadTool(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	run, err := results.Latest(tool.Name)
+	if err != nil {
+		return nil, nil, err
+	}
+	return run, results.Bundle(tool.Description, run, chunkSize), nil
+}
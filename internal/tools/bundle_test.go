@@ -0,0 +1,61 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+func TestHandleExportContext(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	tool := &persistence.SavedToolDefinition{Name: "weekly_report", Description: "Summarize the week", Code: `"closed " + str(params["count"]) + " issues"`}
+	persistence.SaveTool(tool)
+	ctx := context.Background()
+
+	result, _, _ := handleExportContext(ctx, nil, types.ExportContextArgs{Name: "weekly_report"})
+	if !result.IsError {
+		t.Error("Expected an error before the tool has run")
+	}
+
+	if result, _, _ := handleSavedTool(tool, types.SavedToolParams{"count": 4}, nil); result.IsError {
+		t.Fatalf("handleSavedTool() failed: %+v", result.Content)
+	}
+
+	result, structured, _ := handleExportContext(ctx, nil, types.ExportContextArgs{Name: "weekly_report"})
+	verifyTextContent(t, result, "in 1 chunk(s)")
+	response := structured.(ContextBundleResponse)
+	if response.URI != "metatool://context/weekly_report" || response.Chunks != 1 || response.Summary != "text of 15 characters over 1 line(s)" {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+	embedded, ok := result.Content[1].(*mcp.EmbeddedResource)
+	if !ok {
+		t.Fatalf("Expected an embedded resource, got %T", result.Content[1])
+	}
+	if !strings.Contains(embedded.Resource.Text, "Summarize the week") || !strings.Contains(embedded.Resource.Text, "closed 4 issues") {
+		t.Errorf("Unexpected bundle: %s", embedded.Resource.Text)
+	}
+
+	result, _, _ = handleExportContext(ctx, nil, types.ExportContextArgs{Name: "weekly_report", ChunkSize: 10})
+	if !result.IsError {
+		t.Error("Expected an error for a tiny chunk size")
+	}
+
+	req := &mcp.ReadResourceRequest{Params: &mcp.ReadResourceParams{URI: "metatool://context/weekly_report"}}
+	read, err := handleReadContext(ctx, req)
+	if err != nil {
+		t.Fatalf("handleReadContext() error = %v", err)
+	}
+	if len(read.Contents) != 1 || read.Contents[0].Text != embedded.Resource.Text {
+		t.Errorf("Resource contents differ from the exported bundle: %+v", read.Contents)
+	}
+
+	req.Params.URI = "metatool://context/missing"
+	if _, err := handleReadContext(ctx, req); err == nil {
+		t.Error("Expected an error for a tool without a result")
+	}
+}
@@ -0,0 +1,47 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+// approvalChecker is implemented by gated proxy managers, which know the calls that require approval
+type approvalChecker interface {
+	RequiresApproval(serverName, toolName string) bool
+}
+
+// RegisterCallTool registers the call_tool tool with the MCP server. Calls are made through the
+// ungated proxyManager, after approval if gated says the tool requires it.
+func RegisterCallTool(server *mcp.Server, proxyManager, gated ProxyManager) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:        "call_tool",
+		Description: "Call any upstream tool by server and tool name, including hidden ones, for when the tool to call is only known at runtime",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.CallToolArgs) (*mcp.CallToolResult, any, error) {
+		return handleCallTool(ctx, req, args, proxyManager, gated)
+	})
+}
+
+func handleCallTool(ctx context.Context, req *mcp.CallToolRequest, args types.CallToolArgs, proxyManager, gated ProxyManager) (*mcp.CallToolResult, any, error) {
+	if args.Server == "" || args.Tool == "" {
+		return ErrorResponse("Error: server and tool are required"), nil, nil
+	}
+	serverName, tool, err := starlark.LookupTool(proxyManager, args.Server, args.Tool)
+	if err != nil {
+		return ErrorResponse("Error: %v", err), nil, nil
+	}
+
+	arguments := ProxiedToolArgs(args.Arguments)
+	if arguments == nil {
+		arguments = ProxiedToolArgs{}
+	}
+	ctx = proxy.WithProgress(ctx, upstreamProgress(ctx, req))
+	if checker, ok := gated.(approvalChecker); ok && checker.RequiresApproval(serverName, tool.Name) {
+		return handleGatedProxiedTool(ctx, req, proxyManager, serverName, tool.Name, arguments)
+	}
+	return handleProxiedTool(ctx, proxyManager, serverName, tool.Name, arguments)
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/approval"
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+func TestHandleCallTool(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	upstream := NewMockProxyManager()
+	upstream.AddMockTool("github", &mcp.Tool{Name: "get_issue"})
+	upstream.AddMockTool("github", &mcp.Tool{Name: "delete_repo"})
+	upstream.AddMockTool("my-slack", &mcp.Tool{Name: "post_message"})
+	upstream.SetMockResult("github", "get_issue", &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "issue 1"}}})
+	gated := approval.NewGate(upstream, config.NewLive(&config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"github": {Command: "test", ApprovalRequired: []string{"delete_*"}},
+	}}))
+
+	tests := []struct {
+		name     string
+		args     types.CallToolArgs
+		wantText string
+		wantErr  bool
+	}{
+		{"called", types.CallToolArgs{Server: "github", Tool: "get_issue", Arguments: map[string]interface{}{"number": 1}}, "issue 1", false},
+		{"by namespace", types.CallToolArgs{Server: "my_slack", Tool: "post_message"}, "Mock result", false},
+		{"queued for approval", types.CallToolArgs{Server: "github", Tool: "delete_repo"}, "requires approval", false},
+		{"unknown tool", types.CallToolArgs{Server: "github", Tool: "create_issue"}, "has no tool", true},
+		{"unknown server", types.CallToolArgs{Server: "jira", Tool: "get_issue"}, "not configured", true},
+		{"missing tool", types.CallToolArgs{Server: "github"}, "required", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, _ := handleCallTool(context.Background(), nil, tt.args, upstream, gated)
+			text := result.Content[0].(*mcp.TextContent).Text
+			if result.IsError != tt.wantErr || !strings.Contains(text, tt.wantText) {
+				t.Errorf("call_tool = %q (IsError %v), want %q (IsError %v)", text, result.IsError, tt.wantText, tt.wantErr)
+			}
+		})
+	}
+}
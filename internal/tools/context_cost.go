@@ -0,0 +1,165 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+)
+
+// charsPerToken approximates how many characters of tool metadata make up one model token
+const charsPerToken = 4
+
+// largestToolsShown is how many of the most expensive tools are listed per group
+const largestToolsShown = 3
+
+// Group names for tools that don't belong to an upstream server
+const (
+	builtinGroup = "built-in"
+	savedGroup   = "saved"
+)
+
+// ToolCost is the estimated context cost of advertising one tool
+type ToolCost struct {
+	Name   string `json:"name"`
+	Tokens int    `json:"tokens"`
+}
+
+// ContextCostGroup totals the cost of the tools from one server, or of the built-in or saved tools
+type ContextCostGroup struct {
+	Name    string     `json:"name"`
+	Tools   int        `json:"tools"`
+	Tokens  int        `json:"tokens"`
+	Largest []ToolCost `json:"largest"`
+}
+
+// ContextCostReport estimates how many tokens the advertised tool inventory consumes
+type ContextCostReport struct {
+	Tools  int                `json:"tools"`
+	Tokens int                `json:"tokens"`
+	Groups []ContextCostGroup `json:"groups"` // most expensive first
+}
+
+// RegisterContextCost registers the context_cost tool with the MCP server
+func RegisterContextCost(server *mcp.Server) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "context_cost",
+		Description:  "Estimate how many tokens the advertised tools' names, descriptions and schemas consume, broken down by server",
+		OutputSchema: outputSchema[ContextCostReport](),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		tools, err := AdvertisedTools(ctx, server)
+		if err != nil {
+			return ErrorResponse("Failed to list advertised tools: %v", err), nil, nil
+		}
+		report := EstimateContextCost(tools)
+		return SuccessResponse("%s", report), report, nil
+	})
+}
+
+// AdvertisedTools returns the tools the server currently offers to clients, exactly as they're listed
+func AdvertisedTools(ctx context.Context, server *mcp.Server) ([]*mcp.Tool, error) {
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer serverSession.Close()
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "mcp-metatool", Version: "0.1.0"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer clientSession.Close()
+
+	var tools []*mcp.Tool
+	for tool, err := range clientSession.Tools(ctx, nil) {
+		if err != nil {
+			return nil, err
+		}
+		tools = append(tools, tool)
+	}
+	return tools, nil
+}
+
+// EstimateTokens approximates the tokens a tool's listing occupies in a model's context
+func EstimateTokens(tool *mcp.Tool) int {
+	data, err := json.Marshal(tool)
+	if err != nil {
+		return 0
+	}
+	return (len(data) + charsPerToken - 1) / charsPerToken
+}
+
+// EstimateContextCost totals the estimated cost of a tool inventory by group
+func EstimateContextCost(tools []*mcp.Tool) *ContextCostReport {
+	report := &ContextCostReport{Tools: len(tools), Groups: []ContextCostGroup{}}
+	costs := make(map[string][]ToolCost)
+	for _, tool := range tools {
+		cost := ToolCost{Name: tool.Name, Tokens: EstimateTokens(tool)}
+		group := toolGroup(tool.Name)
+		costs[group] = append(costs[group], cost)
+		report.Tokens += cost.Tokens
+	}
+
+	for name, groupCosts := range costs {
+		sort.Slice(groupCosts, func(i, j int) bool {
+			if groupCosts[i].Tokens != groupCosts[j].Tokens {
+				return groupCosts[i].Tokens > groupCosts[j].Tokens
+			}
+			return groupCosts[i].Name < groupCosts[j].Name
+		})
+
+		group := ContextCostGroup{Name: name, Tools: len(groupCosts)}
+		for _, cost := range groupCosts {
+			group.Tokens += cost.Tokens
+		}
+		group.Largest = groupCosts[:min(largestToolsShown, len(groupCosts))]
+		report.Groups = append(report.Groups, group)
+	}
+
+	sort.Slice(report.Groups, func(i, j int) bool {
+		if report.Groups[i].Tokens != report.Groups[j].Tokens {
+			return report.Groups[i].Tokens > report.Groups[j].Tokens
+		}
+		return report.Groups[i].Name < report.Groups[j].Name
+	})
+	return report
+}
+
+// toolGroup names the group an advertised tool is counted under
+func toolGroup(name string) string {
+	if builtinNames[name] {
+		return builtinGroup
+	}
+	if upstream, ok := lookupProxiedTool(name); ok {
+		return upstream.server
+	}
+	if serverName, _, ok := strings.Cut(name, config.DefaultToolNameSeparator); ok {
+		return serverName
+	}
+	return savedGroup
+}
+
+// String renders the report as a summary with one line per group
+func (r *ContextCostReport) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d advertised tools use about %d tokens (estimated at %d characters per token)", r.Tools, r.Tokens, charsPerToken)
+	for _, group := range r.Groups {
+		share := 0
+		if r.Tokens > 0 {
+			share = group.Tokens * 100 / r.Tokens
+		}
+		largest := make([]string, len(group.Largest))
+		for i, cost := range group.Largest {
+			largest[i] = fmt.Sprintf("%s ~%d", cost.Name, cost.Tokens)
+		}
+		fmt.Fprintf(&b, "\n• %s: %d tool(s), ~%d tokens (%d%%); largest: %s", group.Name, group.Tools, group.Tokens, share, strings.Join(largest, ", "))
+	}
+	return b.String()
+}
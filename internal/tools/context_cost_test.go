@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+func TestEstimateContextCost(t *testing.T) {
+	builtinNames["eval_starlark"] = true
+	defer delete(builtinNames, "eval_starlark")
+	longDescription := strings.Repeat("Creates an issue. ", 50)
+
+	report := EstimateContextCost([]*mcp.Tool{
+		{Name: "eval_starlark", Description: "Execute Starlark code"},
+		{Name: "github__get_issue", Description: "Fetch an issue"},
+		{Name: "github__create_issue", Description: longDescription},
+		{Name: "jira__search", Description: "Search"},
+		{Name: "weekly_report", Description: "Summarise the week"},
+	})
+
+	if report.Tools != 5 {
+		t.Errorf("Expected 5 tools, got %d", report.Tools)
+	}
+
+	groups := make(map[string]ContextCostGroup)
+	total := 0
+	for _, group := range report.Groups {
+		groups[group.Name] = group
+		total += group.Tokens
+	}
+	if total != report.Tokens {
+		t.Errorf("Group tokens sum to %d, report total is %d", total, report.Tokens)
+	}
+	if report.Groups[0].Name != "github" {
+		t.Errorf("Expected github to be the most expensive group, got %s", report.Groups[0].Name)
+	}
+	if github := groups["github"]; github.Tools != 2 || github.Largest[0].Name != "github__create_issue" {
+		t.Errorf("Unexpected github group: %+v", github)
+	}
+	if github := groups["github"]; github.Largest[0].Tokens < len(longDescription)/charsPerToken {
+		t.Errorf("Expected the long description to be counted, got %d tokens", github.Largest[0].Tokens)
+	}
+	for _, name := range []string{builtinGroup, savedGroup, "jira"} {
+		if groups[name].Tools != 1 {
+			t.Errorf("Expected 1 tool in group %s, got %+v", name, groups[name])
+		}
+	}
+
+	if text := report.String(); !strings.Contains(text, "5 advertised tools") || !strings.Contains(text, "• github: 2 tool(s)") {
+		t.Errorf("Unexpected report text: %s", text)
+	}
+}
+
+func TestContextCostTool(t *testing.T) {
+	ctx := context.Background()
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterContextCost(server)
+	mcp.AddTool(server, &mcp.Tool{Name: "github__get_issue", Description: "Fetch an issue"}, func(ctx context.Context, req *mcp.CallToolRequest, args map[string]any) (*mcp.CallToolResult, any, error) {
+		return nil, nil, nil
+	})
+
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer clientSession.Close()
+
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "context_cost"})
+	if err != nil {
+		t.Fatalf("CallTool() error = %v", err)
+	}
+	verifyTextContent(t, result, "2 advertised tools")
+	verifyTextContent(t, result, "• github: 1 tool(s)")
+}
@@ -0,0 +1,311 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/types"
+	"github.com/dslh/mcp-metatool/internal/usage"
+)
+
+// collapseMinTools is the fewest advertised tools a server needs before collapsing it into a dispatcher is suggested
+const collapseMinTools = 5
+
+// collapseMaxUsedPercent is the largest share of a server's tools that may be in use for it to be collapsed
+const collapseMaxUsedPercent = 25
+
+// Kinds of curation suggestion
+const (
+	curateHideTools   = "hide_tools"
+	curateHideServer  = "hide_server"
+	curateCollapse    = "collapse"
+	curateUnusedSaved = "unused_saved_tool"
+)
+
+// CurationSuggestion is one change that would shrink the advertised tool inventory
+type CurationSuggestion struct {
+	Kind       string   `json:"kind"` // hide_tools, hide_server, collapse or unused_saved_tool
+	Server     string   `json:"server,omitempty"`
+	Tools      []string `json:"tools"`           // tools no longer advertised
+	InUse      []string `json:"inUse,omitempty"` // the server's tools that have been called
+	Tokens     int      `json:"tokens"`          // estimated tokens no longer advertised
+	Reason     string   `json:"reason"`
+	Dispatcher string   `json:"dispatcher,omitempty"` // Starlark for a saved tool replacing a collapsed server
+}
+
+// CurateToolsResponse lists curation suggestions with a servers.json patch applying them
+type CurateToolsResponse struct {
+	TrackedSince time.Time            `json:"trackedSince"`
+	Suggestions  []CurationSuggestion `json:"suggestions"`
+	Tokens       int                  `json:"tokens"`          // estimated savings of the patch
+	Patch        map[string]any       `json:"patch,omitempty"` // JSON merge patch for servers.json
+	Applied      bool                 `json:"applied"`
+}
+
+// RegisterCurateTools registers the curate_tools tool with the MCP server
+// editor may be nil if no valid config was loaded at startup
+func RegisterCurateTools(server *mcp.Server, editor ConfigEditor) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "curate_tools",
+		Description:  "Suggest proxied tools to hide, servers to collapse into a dispatcher and saved tools that look unused, based on recorded usage and context cost, with a servers.json patch that applies them",
+		OutputSchema: outputSchema[CurateToolsResponse](),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.CurateToolsArgs) (*mcp.CallToolResult, any, error) {
+		advertised, err := AdvertisedTools(ctx, server)
+		if err != nil {
+			return ErrorResponse("Failed to list advertised tools: %v", err), nil, nil
+		}
+		return handleCurateTools(args, advertised, editor)
+	})
+}
+
+func handleCurateTools(args types.CurateToolsArgs, advertised []*mcp.Tool, editor ConfigEditor) (*mcp.CallToolResult, any, error) {
+	if args.MaxCalls < 0 {
+		return ErrorResponse("Error: maxCalls must not be negative"), nil, nil
+	}
+	if args.Apply && editor == nil {
+		return ErrorResponse("Error: applying suggestions requires a valid servers.json at startup"), nil, nil
+	}
+
+	stats, err := usage.Load()
+	if err != nil {
+		return ErrorResponse("Failed to load tool usage: %v", err), nil, nil
+	}
+
+	// Read the file rather than the running config, so the patch doesn't contain expanded secrets
+	cfg := &config.Config{}
+	if configPath, err := paths.GetConfigPath(); err != nil {
+		return ErrorResponse("Failed to locate servers.json: %v", err), nil, nil
+	} else if loaded, err := config.LoadRawConfig(configPath); err == nil {
+		cfg = loaded
+	}
+
+	response := CurateToolsResponse{
+		TrackedSince: stats.Since,
+		Suggestions:  SuggestCurations(advertised, cfg, stats, args.MaxCalls),
+	}
+	if response.Suggestions == nil {
+		response.Suggestions = []CurationSuggestion{}
+	}
+	for _, suggestion := range response.Suggestions {
+		if suggestion.Kind != curateUnusedSaved {
+			response.Tokens += suggestion.Tokens
+		}
+	}
+
+	patches := curationPatches(cfg, response.Suggestions)
+	if len(patches) > 0 {
+		response.Patch = map[string]any{"mcpServers": patches}
+	}
+
+	if args.Apply && len(patches) > 0 {
+		summary, err := editor.Edit(func(cfg *config.Config) error {
+			return applyServerPatches(cfg, patches)
+		})
+		if err != nil {
+			return serverChangeError("curate", "tools", summary, err)
+		}
+		response.Applied = true
+	}
+
+	return SuccessResponse("%s", formatCurations(response)), response, nil
+}
+
+// SuggestCurations compares each advertised tool's usage against its context cost
+// Tools called at most maxCalls times since tracking began count as unused.
+func SuggestCurations(advertised []*mcp.Tool, cfg *config.Config, stats *usage.Stats, maxCalls int64) []CurationSuggestion {
+	serverTools := make(map[string][]ToolCost)
+	var savedTools []ToolCost
+	for _, tool := range advertised {
+		cost := ToolCost{Name: tool.Name, Tokens: EstimateTokens(tool)}
+		switch group := toolGroup(tool.Name); group {
+		case builtinGroup:
+		case savedGroup:
+			savedTools = append(savedTools, cost)
+		default:
+			if _, configured := cfg.MCPServers[group]; configured {
+				if upstream, ok := lookupProxiedTool(tool.Name); ok {
+					cost.Name = upstream.tool
+				} else {
+					cost.Name = strings.TrimPrefix(tool.Name, group+config.DefaultToolNameSeparator)
+				}
+				serverTools[group] = append(serverTools[group], cost)
+			}
+		}
+	}
+
+	serverNames := make([]string, 0, len(serverTools))
+	for name := range serverTools {
+		serverNames = append(serverNames, name)
+	}
+	sort.Strings(serverNames)
+
+	var suggestions []CurationSuggestion
+	for _, serverName := range serverNames {
+		var used, unused []string
+		total, unusedTokens := 0, 0
+		for _, cost := range serverTools[serverName] {
+			total += cost.Tokens
+			if stats.ProxiedCalls(serverName, cost.Name) > maxCalls {
+				used = append(used, cost.Name)
+			} else {
+				unused = append(unused, cost.Name)
+				unusedTokens += cost.Tokens
+			}
+		}
+		sort.Strings(used)
+		sort.Strings(unused)
+		count := len(used) + len(unused)
+
+		switch {
+		case len(unused) == 0:
+		case len(used) == 0:
+			suggestions = append(suggestions, CurationSuggestion{
+				Kind:   curateHideServer,
+				Server: serverName,
+				Tools:  unused,
+				Tokens: total,
+				Reason: fmt.Sprintf("none of its %d tool(s) have been called directly; Starlark code can still use them", count),
+			})
+		case count >= collapseMinTools && len(used)*100 <= count*collapseMaxUsedPercent:
+			suggestions = append(suggestions, CurationSuggestion{
+				Kind:       curateCollapse,
+				Server:     serverName,
+				Tools:      slices.Sorted(slices.Values(append(append([]string{}, used...), unused...))),
+				InUse:      used,
+				Tokens:     total,
+				Reason:     fmt.Sprintf("only %d of its %d tools are called; hide the server and save the dispatcher as a tool taking tool and arguments parameters", len(used), count),
+				Dispatcher: fmt.Sprintf(`result = getattr(%s, params["tool"])(params.get("arguments", {}))`, serverName),
+			})
+		default:
+			suggestions = append(suggestions, CurationSuggestion{
+				Kind:   curateHideTools,
+				Server: serverName,
+				Tools:  unused,
+				InUse:  used,
+				Tokens: unusedTokens,
+				Reason: fmt.Sprintf("%d of its %d tools have not been called", len(unused), count),
+			})
+		}
+	}
+
+	sort.Slice(savedTools, func(i, j int) bool { return savedTools[i].Name < savedTools[j].Name })
+	for _, cost := range savedTools {
+		if stats.SavedCalls(cost.Name) <= maxCalls {
+			suggestions = append(suggestions, CurationSuggestion{
+				Kind:   curateUnusedSaved,
+				Tools:  []string{cost.Name},
+				Tokens: cost.Tokens,
+				Reason: "not run since usage tracking began; consider deleting it with delete_saved_tool",
+			})
+		}
+	}
+	return suggestions
+}
+
+// curationPatches returns the per-server settings that apply the suggestions, keyed by server name.
+// Saved tools aren't configured in servers.json, so their suggestions aren't patched.
+func curationPatches(cfg *config.Config, suggestions []CurationSuggestion) map[string]map[string]any {
+	patches := make(map[string]map[string]any)
+	for _, suggestion := range suggestions {
+		serverConfig := cfg.MCPServers[suggestion.Server]
+		switch suggestion.Kind {
+		case curateHideServer, curateCollapse:
+			patches[suggestion.Server] = map[string]any{"hidden": true}
+		case curateHideTools:
+			if len(serverConfig.AllowedTools) > 0 {
+				// An allowlist can't be combined with hiddenTools, so narrow it to the tools still in use
+				allowed := allowedAfterHiding(serverConfig.AllowedTools, suggestion.Tools, suggestion.InUse)
+				patches[suggestion.Server] = map[string]any{"allowedTools": allowed}
+			} else {
+				hidden := append(append([]string{}, serverConfig.HiddenTools...), suggestion.Tools...)
+				patches[suggestion.Server] = map[string]any{"hiddenTools": hidden}
+			}
+		}
+	}
+	return patches
+}
+
+// allowedAfterHiding drops allowlist patterns matching hidden tools,
+// listing by name the tools in use that a dropped pattern also matched
+func allowedAfterHiding(allowed, hidden, inUse []string) []string {
+	var kept []string
+	for _, pattern := range allowed {
+		matchesHidden := false
+		for _, name := range hidden {
+			if config.MatchesPattern(name, pattern) {
+				matchesHidden = true
+				break
+			}
+		}
+		if !matchesHidden {
+			kept = append(kept, pattern)
+			continue
+		}
+		for _, name := range inUse {
+			if config.MatchesPattern(name, pattern) && !slices.Contains(kept, name) {
+				kept = append(kept, name)
+			}
+		}
+	}
+	return kept
+}
+
+// applyServerPatches merges per-server patches into the config, as a JSON merge patch would
+func applyServerPatches(cfg *config.Config, patches map[string]map[string]any) error {
+	for serverName, patch := range patches {
+		serverConfig, exists := cfg.MCPServers[serverName]
+		if !exists {
+			return fmt.Errorf("server %s is not configured", serverName)
+		}
+		data, err := json.Marshal(patch)
+		if err != nil {
+			return err
+		}
+		if err := json.Unmarshal(data, &serverConfig); err != nil {
+			return err
+		}
+		cfg.MCPServers[serverName] = serverConfig
+	}
+	return nil
+}
+
+// formatCurations renders the suggestions for display
+func formatCurations(response CurateToolsResponse) string {
+	if len(response.Suggestions) == 0 {
+		return fmt.Sprintf("No suggestions: every advertised tool has been used since %s", response.TrackedSince.Format("2006-01-02"))
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d suggestion(s) from usage recorded since %s:\n", len(response.Suggestions), response.TrackedSince.Format("2006-01-02"))
+	for _, suggestion := range response.Suggestions {
+		switch suggestion.Kind {
+		case curateHideServer:
+			fmt.Fprintf(&b, "\n• Hide server %s (~%d tokens): %s", suggestion.Server, suggestion.Tokens, suggestion.Reason)
+		case curateCollapse:
+			fmt.Fprintf(&b, "\n• Collapse server %s into a dispatcher (~%d tokens): %s; in use: %s\n    %s", suggestion.Server, suggestion.Tokens, suggestion.Reason, strings.Join(suggestion.InUse, ", "), suggestion.Dispatcher)
+		case curateHideTools:
+			fmt.Fprintf(&b, "\n• Hide %s tools (~%d tokens): %s; unused: %s", suggestion.Server, suggestion.Tokens, suggestion.Reason, strings.Join(suggestion.Tools, ", "))
+		case curateUnusedSaved:
+			fmt.Fprintf(&b, "\n• Saved tool %s (~%d tokens): %s", suggestion.Tools[0], suggestion.Tokens, suggestion.Reason)
+		}
+	}
+
+	if response.Patch != nil {
+		patch, _ := json.MarshalIndent(response.Patch, "", "  ")
+		if response.Applied {
+			fmt.Fprintf(&b, "\n\nApplied to servers.json, saving ~%d tokens:\n%s", response.Tokens, patch)
+		} else {
+			fmt.Fprintf(&b, "\n\nservers.json patch saving ~%d tokens (call again with apply=true to apply it):\n%s", response.Tokens, patch)
+		}
+	}
+	return b.String()
+}
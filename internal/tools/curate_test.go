@@ -0,0 +1,135 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+func TestCurateTools(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+	builtinNames["eval_starlark"] = true
+	defer delete(builtinNames, "eval_starlark")
+
+	servers := `{"mcpServers": {
+		"github": {"command": "gh-mcp", "env": {"TOKEN": "${GITHUB_TOKEN}"}, "hiddenTools": ["admin_*"]},
+		"jira": {"command": "jira-mcp", "allowedTools": ["search", "create_*"]},
+		"slack": {"command": "slack-mcp"},
+		"notes": {"command": "notes-mcp"}
+	}}`
+	os.WriteFile(filepath.Join(dir, "servers.json"), []byte(servers), 0644)
+	stats := `{"since": "2026-01-01T00:00:00Z",
+		"proxied": {"github": {"get_issue": {"calls": 3}}, "jira": {"search": {"calls": 1}, "create_issue": {"calls": 5}}, "notes": {"read": {"calls": 2}}},
+		"saved": {"weekly_report": {"calls": 4}}}`
+	os.WriteFile(filepath.Join(dir, "usage.json"), []byte(stats), 0644)
+
+	var advertised []*mcp.Tool
+	for _, name := range []string{
+		"eval_starlark",
+		"github__get_issue", "github__create_issue",
+		"jira__search", "jira__create_issue", "jira__create_epic",
+		"slack__post", "slack__history",
+		"notes__read", "notes__write", "notes__list", "notes__delete", "notes__search",
+		"weekly_report", "old_report",
+	} {
+		advertised = append(advertised, &mcp.Tool{Name: name, Description: "Does " + name})
+	}
+
+	result, structured, err := handleCurateTools(types.CurateToolsArgs{}, advertised, nil)
+	if err != nil || result.IsError {
+		t.Fatalf("handleCurateTools() = %v, %v", result, err)
+	}
+	response := structured.(CurateToolsResponse)
+
+	suggestions := make(map[string]CurationSuggestion)
+	for _, suggestion := range response.Suggestions {
+		suggestions[suggestion.Kind+":"+suggestion.Server+":"+strings.Join(suggestion.Tools, ",")] = suggestion
+	}
+	wantKeys := []string{
+		"hide_tools:github:create_issue",
+		"hide_tools:jira:create_epic",
+		"collapse:notes:delete,list,read,search,write",
+		"hide_server:slack:history,post",
+		"unused_saved_tool::old_report",
+	}
+	for _, key := range wantKeys {
+		if _, ok := suggestions[key]; !ok {
+			t.Errorf("Missing suggestion %s in %+v", key, response.Suggestions)
+		}
+	}
+	if len(response.Suggestions) != len(wantKeys) {
+		t.Errorf("Expected %d suggestions, got %+v", len(wantKeys), response.Suggestions)
+	}
+	if collapse := suggestions["collapse:notes:delete,list,read,search,write"]; !strings.Contains(collapse.Dispatcher, "getattr(notes,") || !reflect.DeepEqual(collapse.InUse, []string{"read"}) {
+		t.Errorf("Unexpected collapse suggestion: %+v", collapse)
+	}
+
+	patches := response.Patch["mcpServers"].(map[string]map[string]any)
+	wantPatches := map[string]map[string]any{
+		"github": {"hiddenTools": []string{"admin_*", "create_issue"}},
+		"jira":   {"allowedTools": []string{"search", "create_issue"}},
+		"notes":  {"hidden": true},
+		"slack":  {"hidden": true},
+	}
+	if !reflect.DeepEqual(patches, wantPatches) {
+		t.Errorf("Patch = %v, want %v", patches, wantPatches)
+	}
+	if response.Applied || response.Tokens == 0 {
+		t.Errorf("Expected unapplied patch with savings, got %+v", response)
+	}
+	if text := result.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "apply=true") || !strings.Contains(text, "2026-01-01") {
+		t.Errorf("Unexpected text: %s", text)
+	}
+
+	// A higher threshold counts rarely used tools as unused too
+	_, structured, _ = handleCurateTools(types.CurateToolsArgs{MaxCalls: 3}, advertised, nil)
+	for _, suggestion := range structured.(CurateToolsResponse).Suggestions {
+		if suggestion.Server == "github" && suggestion.Kind != curateHideServer {
+			t.Errorf("Expected github to be hidden with maxCalls 3, got %+v", suggestion)
+		}
+	}
+
+	result, _, _ = handleCurateTools(types.CurateToolsArgs{Apply: true}, advertised, nil)
+	if !result.IsError {
+		t.Error("Expected error applying without an editor")
+	}
+
+	cfg, err := config.LoadRawConfig(filepath.Join(dir, "servers.json"))
+	if err != nil {
+		t.Fatalf("LoadRawConfig() error = %v", err)
+	}
+	editor := &fakeEditor{cfg: cfg}
+	result, structured, _ = handleCurateTools(types.CurateToolsArgs{Apply: true}, advertised, editor)
+	if result.IsError || !structured.(CurateToolsResponse).Applied {
+		t.Fatalf("Expected patch to be applied, got %v", result.Content[0].(*mcp.TextContent).Text)
+	}
+	if github := editor.cfg.MCPServers["github"]; !reflect.DeepEqual(github.HiddenTools, []string{"admin_*", "create_issue"}) || github.Env["TOKEN"] != "${GITHUB_TOKEN}" {
+		t.Errorf("Unexpected github config after apply: %+v", github)
+	}
+	if !editor.cfg.MCPServers["notes"].Hidden || !editor.cfg.MCPServers["slack"].Hidden {
+		t.Errorf("Expected notes and slack to be hidden, got %+v", editor.cfg.MCPServers)
+	}
+}
+
+func TestAllowedAfterHiding(t *testing.T) {
+	tests := []struct {
+		allowed, hidden, inUse, want []string
+	}{
+		{[]string{"search", "create_*"}, []string{"create_epic"}, []string{"search", "create_issue"}, []string{"search", "create_issue"}},
+		{[]string{"search", "old"}, []string{"old"}, []string{"search"}, []string{"search"}},
+		{[]string{"*"}, []string{"b"}, []string{"a", "c"}, []string{"a", "c"}},
+	}
+	for _, tt := range tests {
+		if got := allowedAfterHiding(tt.allowed, tt.hidden, tt.inUse); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("allowedAfterHiding(%v, %v, %v) = %v, want %v", tt.allowed, tt.hidden, tt.inUse, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/schema"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+// Kinds of tool describe_tool describes
+const (
+	describeProxied = "proxied"
+	describeSaved   = "saved"
+)
+
+// DescribeToolResponse is the complete definition of a proxied or saved tool
+type DescribeToolResponse struct {
+	Kind         string               `json:"kind"` // proxied or saved
+	Name         string               `json:"name"`
+	Server       string               `json:"server,omitempty"`
+	Tool         string               `json:"tool,omitempty"`        // upstream name of a proxied tool
+	Call         string               `json:"call,omitempty"`        // how Starlark code calls a proxied tool
+	ProxiedName  string               `json:"proxiedName,omitempty"` // advertised name, unless the tool is hidden
+	Description  string               `json:"description,omitempty"`
+	InputSchema  any                  `json:"inputSchema,omitempty"`
+	OutputSchema any                  `json:"outputSchema,omitempty"`
+	Annotations  *mcp.ToolAnnotations `json:"annotations,omitempty"`
+	Presets      []string             `json:"presets,omitempty"`
+}
+
+// RegisterDescribeTool registers the describe_tool tool with the MCP server
+func RegisterDescribeTool(server *mcp.Server, proxyManager ProxyManager) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "describe_tool",
+		Description:  "Show the complete description, input schema and annotations of a proxied or saved tool, including hidden ones, to construct valid arguments before calling it",
+		OutputSchema: outputSchema[DescribeToolResponse](),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.DescribeToolArgs) (*mcp.CallToolResult, any, error) {
+		return handleDescribeTool(args, proxyManager)
+	})
+}
+
+func handleDescribeTool(args types.DescribeToolArgs, proxyManager ProxyManager) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" {
+		return ErrorResponse("Error: tool name is required"), nil, nil
+	}
+
+	if args.Server != "" {
+		if proxyManager == nil {
+			return ErrorResponse("Error: server %s is not configured", args.Server), nil, nil
+		}
+		response, err := describeUpstreamTool(proxyManager, args.Server, args.Name)
+		if err != nil {
+			return ErrorResponse("Error: %v", err), nil, nil
+		}
+		return describeResponse(response)
+	}
+
+	// Proxied tools by their advertised names, then by how Starlark calls them
+	if proxyManager != nil {
+		if upstream, ok := lookupProxiedTool(args.Name); ok {
+			response, err := describeUpstreamTool(proxyManager, upstream.server, upstream.tool)
+			if err != nil {
+				return ErrorResponse("Error: %v", err), nil, nil
+			}
+			return describeResponse(response)
+		}
+		if namespace, attr, ok := strings.Cut(args.Name, "."); ok {
+			if response, found := describeStarlarkCall(proxyManager, namespace, attr); found {
+				return describeResponse(response)
+			}
+		}
+	}
+
+	tool, err := persistence.LoadTool(args.Name)
+	if errors.Is(err, fs.ErrNotExist) {
+		return ErrorResponse("Error: no proxied or saved tool named '%s'; search_tools finds tools by what they do", args.Name), nil, nil
+	} else if err != nil {
+		return ErrorResponse("Error: failed to load tool '%s': %v", args.Name, err), nil, nil
+	}
+	return describeResponse(&DescribeToolResponse{
+		Kind:        describeSaved,
+		Name:        tool.Name,
+		Description: tool.Description,
+		InputSchema: tool.InputSchema,
+		Annotations: savedToolAnnotations(tool.Annotations),
+		Presets:     tool.PresetNames(),
+	})
+}
+
+// describeUpstreamTool describes a server's tool, given its upstream name or alias
+func describeUpstreamTool(proxyManager ProxyManager, serverName, toolName string) (*DescribeToolResponse, error) {
+	if _, connected := proxyManager.GetAllTools()[serverName]; connected {
+		namespace := starlark.NamespaceName(proxyManager, serverName)
+		if response, found := describeStarlarkCall(proxyManager, namespace, toolName); found {
+			return response, nil
+		}
+	}
+	tool, err := findUpstreamTool(proxyManager, serverName, toolName)
+	if err != nil {
+		return nil, err
+	}
+	return newProxiedDescription(proxyManager, serverName, toolName, tool), nil
+}
+
+// describeStarlarkCall describes the tool Starlark code calls as namespace.attr, if there is one
+func describeStarlarkCall(proxyManager ProxyManager, namespace, attr string) (*DescribeToolResponse, bool) {
+	for serverName, tools := range proxyManager.GetAllTools() {
+		if starlark.NamespaceName(proxyManager, serverName) != namespace {
+			continue
+		}
+		if tool, ok := starlark.NamespaceTools(proxyManager, serverName, tools)[attr]; ok {
+			return newProxiedDescription(proxyManager, serverName, attr, tool), true
+		}
+	}
+	return nil, false
+}
+
+// newProxiedDescription describes an upstream tool, which Starlark calls by attr
+func newProxiedDescription(proxyManager ProxyManager, serverName, attr string, tool *mcp.Tool) *DescribeToolResponse {
+	response := &DescribeToolResponse{
+		Kind:        describeProxied,
+		Name:        tool.Name,
+		Server:      serverName,
+		Tool:        tool.Name,
+		Call:        starlark.NamespaceName(proxyManager, serverName) + "." + attr,
+		Description: tool.Description,
+		Annotations: tool.Annotations,
+	}
+	response.ProxiedName, _ = proxiedToolName(serverName, tool.Name)
+	if response.ProxiedName != "" {
+		response.Name = response.ProxiedName
+	}
+	// The schema as it is advertised, after the same transformation proxied tools get
+	if inputSchema := schema.SafeTransform(tool.InputSchema, fmt.Sprintf("tool %s", tool.Name)); inputSchema != nil {
+		response.InputSchema = inputSchema
+	}
+	if tool.OutputSchema != nil {
+		response.OutputSchema = tool.OutputSchema
+	}
+	return response
+}
+
+// describeResponse formats a description with its schema as indented JSON
+func describeResponse(response *DescribeToolResponse) (*mcp.CallToolResult, any, error) {
+	text := fmt.Sprintf("%s (%s)", response.Name, response.Kind)
+	if response.Call != "" {
+		text = fmt.Sprintf("%s (%s, call as %s)", response.Name, response.Kind, response.Call)
+	}
+	if response.Description != "" {
+		text += "\n\n" + response.Description
+	}
+	if response.InputSchema != nil {
+		if data, err := json.MarshalIndent(response.InputSchema, "", "  "); err == nil {
+			text += "\n\nInput schema:\n" + string(data)
+		}
+	}
+	if len(response.Presets) > 0 {
+		text += "\n\nPresets: " + strings.Join(response.Presets, ", ")
+	}
+	return SuccessResponse("%s", text), *response, nil
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+func TestHandleDescribeTool(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	upstream := NewMockProxyManager()
+	readOnly := &mcp.ToolAnnotations{ReadOnlyHint: true}
+	upstream.AddMockTool("github", &mcp.Tool{
+		Name:        "get_issue",
+		Description: "Get an issue",
+		InputSchema: &jsonschema.Schema{Type: "object", Properties: map[string]*jsonschema.Schema{"number": {Type: "integer"}}, Required: []string{"number"}},
+		Annotations: readOnly,
+	})
+	upstream.AddMockTool("my-slack", &mcp.Tool{Name: "post_message", Description: "Post a message", InputSchema: &jsonschema.Schema{Type: "object"}})
+	if err := persistence.SaveTool(&persistence.SavedToolDefinition{
+		Name:        "greet",
+		Description: "Greet someone",
+		Code:        `"hello " + params["name"]`,
+		InputSchema: map[string]any{"type": "object"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		args     types.DescribeToolArgs
+		wantKind string
+		wantCall string
+		wantErr  bool
+	}{
+		{"upstream name with server", types.DescribeToolArgs{Name: "get_issue", Server: "github"}, describeProxied, "github.get_issue", false},
+		{"starlark call", types.DescribeToolArgs{Name: "my_slack.post_message"}, describeProxied, "my_slack.post_message", false},
+		{"saved tool", types.DescribeToolArgs{Name: "greet"}, describeSaved, "", false},
+		{"unknown tool", types.DescribeToolArgs{Name: "create_issue"}, "", "", true},
+		{"unknown tool on server", types.DescribeToolArgs{Name: "create_issue", Server: "github"}, "", "", true},
+		{"no name", types.DescribeToolArgs{}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, structured, _ := handleDescribeTool(tt.args, upstream)
+			if result.IsError != tt.wantErr {
+				t.Fatalf("IsError = %v, want %v: %s", result.IsError, tt.wantErr, result.Content[0].(*mcp.TextContent).Text)
+			}
+			if tt.wantErr {
+				return
+			}
+			response := structured.(DescribeToolResponse)
+			if response.Kind != tt.wantKind || response.Call != tt.wantCall {
+				t.Errorf("Described %s as %q, want %s as %q", response.Kind, response.Call, tt.wantKind, tt.wantCall)
+			}
+			if response.InputSchema == nil {
+				t.Errorf("Description has no input schema")
+			}
+		})
+	}
+
+	result, structured, _ := handleDescribeTool(types.DescribeToolArgs{Name: "get_issue", Server: "github"}, upstream)
+	if result.IsError || structured.(DescribeToolResponse).Annotations != readOnly {
+		t.Errorf("Description of github.get_issue = %+v, want its annotations", structured)
+	}
+}
@@ -2,9 +2,11 @@ package tools
 
 import (
 	"context"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
+	"github.com/dslh/mcp-metatool/internal/queue"
 	"github.com/dslh/mcp-metatool/internal/starlark"
 )
 
@@ -16,23 +18,33 @@ type EvalStarlarkArgs struct {
 
 // RegisterEvalStarlark registers the eval_starlark tool with the MCP server
 // The proxyManager parameter is optional; pass nil to register without proxy support
-func RegisterEvalStarlark(server *mcp.Server, proxyManager ProxyManager) {
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "eval_starlark",
-		Description: "Execute Starlark code and return the result",
+// Any execution options (such as optional modules) are applied to every evaluation
+func RegisterEvalStarlark(server *mcp.Server, proxyManager ProxyManager, opts ...starlark.Option) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "eval_starlark",
+		Description:  "Execute Starlark code and return the result",
+		OutputSchema: outputSchema[starlark.Result](),
 	}, func(ctx context.Context, req *mcp.CallToolRequest, args EvalStarlarkArgs) (*mcp.CallToolResult, any, error) {
-		return handleEvalStarlark(ctx, req, args, proxyManager)
+		return runQueued(ctx, req, queue.PriorityInteractive, func() (*mcp.CallToolResult, any, error) {
+			return handleEvalStarlark(ctx, req, args, proxyManager, append([]starlark.Option{starlark.WithContext(ctx), progressOption(ctx, req)}, opts...)...)
+		})
 	})
 }
 
-func handleEvalStarlark(ctx context.Context, req *mcp.CallToolRequest, args EvalStarlarkArgs, proxyManager ProxyManager) (*mcp.CallToolResult, any, error) {
+func handleEvalStarlark(ctx context.Context, req *mcp.CallToolRequest, args EvalStarlarkArgs, proxyManager ProxyManager, opts ...starlark.Option) (*mcp.CallToolResult, any, error) {
+	if err := checkParamsSize(args.Params); err != nil {
+		return ErrorResponse("Error: %v", err), nil, nil
+	}
+
 	// Cast proxyManager to starlark.ProxyManager interface
 	var starlarkProxy starlark.ProxyManager
 	if proxyManager != nil {
 		starlarkProxy = proxyManager
 	}
 
-	result, err := starlark.ExecuteWithProxy(args.Code, args.Params, starlarkProxy)
+	start := time.Now()
+	result, err := starlark.ExecuteWithProxy(args.Code, args.Params, starlarkProxy, opts...)
+	observeExecution("eval_starlark", start, result, err)
 	if err != nil {
 		return ErrorResponse("Execution failed: %v", err), nil, nil
 	}
@@ -42,5 +54,6 @@ func handleEvalStarlark(ctx context.Context, req *mcp.CallToolRequest, args Eval
 		return ErrorResponse("Starlark Error: %s", result.Error), nil, nil
 	}
 
-	return SuccessResponse("Result: %v", result.Result), result, nil
-}
\ No newline at end of file
+	response := withTruncationMeta(JSONResponse(result.Result, result), result.Truncated)
+	return withArtifactLinks(response, result.Artifacts), result, nil
+}
@@ -2,10 +2,13 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"strings"
 	"testing"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
-	
+
+	"github.com/dslh/mcp-metatool/internal/config"
 	"github.com/dslh/mcp-metatool/internal/starlark"
 )
 
@@ -109,6 +112,10 @@ result = {"original": data, "processed": processed}`,
 				return
 			}
 
+			if result.IsError != tt.wantError {
+				t.Errorf("handleEvalStarlark() IsError = %v, want %v", result.IsError, tt.wantError)
+			}
+
 			if tt.wantError {
 				// Should contain error message
 				if !containsAny(textContent.Text, []string{"error", "Error", "failed", "Failed"}) {
@@ -121,12 +128,16 @@ result = {"original": data, "processed": processed}`,
 				return
 			}
 
-			// Should contain "Result:" for successful execution
-			if !contains(textContent.Text, "Result:") {
-				t.Errorf("handleEvalStarlark() expected success message, got: %s", textContent.Text)
+			// Successful execution should return the result as JSON
+			if !json.Valid([]byte(textContent.Text)) {
+				t.Errorf("handleEvalStarlark() expected JSON result, got: %s", textContent.Text)
 				return
 			}
 
+			if result.StructuredContent != returnValue {
+				t.Errorf("handleEvalStarlark() expected structured content to be the result envelope")
+			}
+
 			// Check return value matches expected result (returnValue is the Result struct)
 			if resultStruct, ok := returnValue.(*starlark.Result); ok {
 				if !equalValues(resultStruct.Result, tt.wantResult) {
@@ -245,8 +256,8 @@ result = {
 				},
 			},
 			map[string]interface{}{
-				"total":      int64(3),
-				"adults":     int64(2),
+				"total":       int64(3),
+				"adults":      int64(2),
 				"adult_names": []interface{}{"Alice", "Charlie"},
 			},
 		},
@@ -287,8 +298,8 @@ result = {
 			}
 
 			textContent := result.Content[0].(*mcp.TextContent)
-			if !contains(textContent.Text, "Result:") {
-				t.Errorf("handleEvalStarlark() expected success, got: %s", textContent.Text)
+			if !json.Valid([]byte(textContent.Text)) {
+				t.Errorf("handleEvalStarlark() expected JSON result, got: %s", textContent.Text)
 				return
 			}
 
@@ -306,7 +317,7 @@ result = {
 // Helper functions
 
 func contains(s, substr string) bool {
-	return len(s) >= len(substr) && (s == substr || 
+	return len(s) >= len(substr) && (s == substr ||
 		(len(s) > len(substr) && containsSubstring(s, substr)))
 }
 
@@ -382,4 +393,53 @@ func equalValues(a, b interface{}) bool {
 	}
 
 	return a == b
-}
\ No newline at end of file
+}
+
+func TestHandleEvalStarlarkJSONContent(t *testing.T) {
+	args := EvalStarlarkArgs{Code: `tags = ["a", "b"]
+result = {"name": "Alice", "tags": tags, "count": len(tags)}`}
+
+	result, _, err := handleEvalStarlark(context.Background(), &mcp.CallToolRequest{}, args, nil)
+	if err != nil {
+		t.Fatalf("handleEvalStarlark() error = %v", err)
+	}
+
+	textContent := result.Content[0].(*mcp.TextContent)
+
+	var decoded map[string]interface{}
+	if err := json.Unmarshal([]byte(textContent.Text), &decoded); err != nil {
+		t.Fatalf("Expected text content to be JSON, got %q: %v", textContent.Text, err)
+	}
+
+	if decoded["name"] != "Alice" || decoded["count"] != float64(2) {
+		t.Errorf("Unexpected decoded result: %v", decoded)
+	}
+
+	envelope, ok := result.StructuredContent.(*starlark.Result)
+	if !ok {
+		t.Fatalf("Expected structured content to be *starlark.Result, got %T", result.StructuredContent)
+	}
+	if envelope.Result.(map[string]interface{})["name"] != "Alice" {
+		t.Errorf("Unexpected structured result: %v", envelope.Result)
+	}
+}
+
+func TestHandleEvalStarlarkTruncatedResult(t *testing.T) {
+	ConfigureResultLimits(&config.ResultLimits{MaxBytes: 100})
+	defer ConfigureResultLimits(nil)
+
+	args := EvalStarlarkArgs{Code: `["item %d" % i for i in range(100)]`}
+	result, _, err := handleEvalStarlark(context.Background(), &mcp.CallToolRequest{}, args, nil)
+	if err != nil {
+		t.Fatalf("handleEvalStarlark() error = %v", err)
+	}
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	if !strings.Contains(text, "more items truncated") {
+		t.Errorf("Expected a truncation marker in the response, got %s", text)
+	}
+	truncated, ok := result.Meta[TruncatedMetaKey].(*starlark.Truncation)
+	if !ok || truncated.OmittedItems == 0 || truncated.OriginalBytes <= 100 {
+		t.Errorf("Expected the full size and omitted items in _meta, got %v", result.Meta)
+	}
+}
@@ -185,12 +185,18 @@ func TestEvalStarlarkWithoutProxy(t *testing.T) {
 		t.Fatal("Expected TextContent")
 	}
 
-	if textContent.Text != "Result: 14" {
-		t.Errorf("Expected 'Result: 14', got %s", textContent.Text)
+	if textContent.Text != "14" {
+		t.Errorf("Expected '14', got %s", textContent.Text)
+	}
+
+	if result.StructuredContent == nil {
+		t.Error("Expected structured content to be set")
 	}
 }
 
 func TestSavedToolsWithProxyIntegration(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
 	// Test that saved tools can also use proxy manager
 	mockProxy := newMockProxyManager()
 	mockProxy.AddServer("api", []*mcp.Tool{
@@ -0,0 +1,122 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/schema"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+)
+
+// truncationMarker is appended to descriptions that were shortened to fit the configured limit
+const truncationMarker = "… [truncated]"
+
+// toolLimits holds the configured caps on advertised tool metadata
+var toolLimits config.ToolLimits
+
+// ConfigureToolLimits sets the limits applied to every tool as it is registered; nil removes them
+func ConfigureToolLimits(limits *config.ToolLimits) {
+	if limits == nil {
+		toolLimits = config.ToolLimits{}
+		return
+	}
+	toolLimits = *limits
+}
+
+// defaultMaxParamsBytes is the largest params payload eval_starlark accepts when the config doesn't say
+const defaultMaxParamsBytes = 1 << 20
+
+// maxParamsBytes is the largest params payload, as JSON, that eval_starlark accepts
+var maxParamsBytes = defaultMaxParamsBytes
+
+// ConfigureParamLimits sets the size and nesting limits on params; nil restores the defaults
+func ConfigureParamLimits(limits *config.ParamLimits) {
+	if limits == nil {
+		limits = &config.ParamLimits{}
+	}
+	maxParamsBytes = defaultMaxParamsBytes
+	if limits.MaxBytes > 0 {
+		maxParamsBytes = limits.MaxBytes
+	}
+	starlark.SetMaxDepth(limits.MaxDepth)
+}
+
+// ConfigureResultLimits sets the size beyond which Starlark results are truncated; nil restores the default
+func ConfigureResultLimits(limits *config.ResultLimits) {
+	if limits == nil {
+		limits = &config.ResultLimits{}
+	}
+	starlark.SetMaxResultBytes(limits.MaxBytes)
+}
+
+// TruncatedMetaKey is the result _meta field describing a Starlark result cut short by resultLimits
+const TruncatedMetaKey = "mcp-metatool/truncated"
+
+// withTruncationMeta reports in the response's _meta what was cut from a truncated result
+func withTruncationMeta(response *mcp.CallToolResult, truncated *starlark.Truncation) *mcp.CallToolResult {
+	if truncated == nil {
+		return response
+	}
+	if response.Meta == nil {
+		response.Meta = mcp.Meta{}
+	}
+	response.Meta[TruncatedMetaKey] = truncated
+	return response
+}
+
+// checkParamsSize rejects params whose JSON encoding exceeds the configured limit
+func checkParamsSize(params map[string]interface{}) error {
+	if len(params) == 0 {
+		return nil
+	}
+	data, err := json.Marshal(params)
+	if err != nil {
+		return fmt.Errorf("params can't be encoded: %v", err)
+	}
+	if len(data) > maxParamsBytes {
+		return fmt.Errorf("params are %d bytes, more than the %d allowed (see paramLimits.maxBytes)", len(data), maxParamsBytes)
+	}
+	return nil
+}
+
+// applyToolLimits truncates the tool's description and input schema to the configured limits.
+// When the schema would otherwise be inferred from In at registration, it is inferred here instead.
+func applyToolLimits[In any](tool *mcp.Tool) {
+	tool.Description = truncateDescription(tool.Description, toolLimits.MaxDescriptionLength)
+
+	if toolLimits.MaxSchemaBytes <= 0 {
+		return
+	}
+	if tool.InputSchema == nil {
+		inferred, err := jsonschema.For[In](nil)
+		if err != nil {
+			return
+		}
+		tool.InputSchema = inferred
+	}
+
+	limited, reduced := schema.Limit(tool.InputSchema, toolLimits.MaxSchemaBytes)
+	if reduced {
+		logging.Infof("Truncated input schema of tool %s from %d to %d bytes", tool.Name, schema.Size(tool.InputSchema), schema.Size(limited))
+		tool.InputSchema = limited
+	}
+}
+
+// truncateDescription shortens a description to at most maxLength characters, marking the cut
+func truncateDescription(description string, maxLength int) string {
+	runes := []rune(description)
+	if maxLength <= 0 || len(runes) <= maxLength {
+		return description
+	}
+
+	keep := maxLength - len([]rune(truncationMarker))
+	if keep <= 0 {
+		return string(runes[:maxLength])
+	}
+	return string(runes[:keep]) + truncationMarker
+}
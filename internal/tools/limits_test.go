@@ -0,0 +1,170 @@
+package tools
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/schema"
+)
+
+func TestTruncateDescription(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		maxLength   int
+		expected    string
+	}{
+		{"unlimited", "a long description", 0, "a long description"},
+		{"within limit", "short", 10, "short"},
+		{"truncated with marker", "abcdefghijklmnopqrstuvwxyz", 20, "abcdefg" + truncationMarker},
+		{"limit shorter than marker", "abcdefghijklmnopqrstuvwxyz", 5, "abcde"},
+		{"multibyte runes", strings.Repeat("é", 30), 20, strings.Repeat("é", 7) + truncationMarker},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := truncateDescription(tt.description, tt.maxLength)
+			if result != tt.expected {
+				t.Errorf("truncateDescription() = %q, want %q", result, tt.expected)
+			}
+			if tt.maxLength > 0 && utf8.RuneCountInString(result) > tt.maxLength {
+				t.Errorf("truncateDescription() returned %d characters, limit %d", utf8.RuneCountInString(result), tt.maxLength)
+			}
+		})
+	}
+}
+
+func TestSchemaLimit(t *testing.T) {
+	large := &jsonschema.Schema{
+		Type:        "object",
+		Description: strings.Repeat("Describes the tool input. ", 10),
+		Properties: map[string]*jsonschema.Schema{
+			"query": {Type: "string", Description: strings.Repeat("Search query. ", 10)},
+			"filters": {
+				Type: "object",
+				Properties: map[string]*jsonschema.Schema{
+					"label":  {Type: "string"},
+					"author": {Type: "string"},
+					"state":  {Type: "string", Enum: []any{"open", "closed", "merged"}},
+				},
+			},
+		},
+		Required: []string{"query"},
+	}
+
+	tests := []struct {
+		name         string
+		maxBytes     int
+		wantReduced  bool
+		wantProperty bool
+		wantNested   bool
+	}{
+		{"unlimited", 0, false, true, true},
+		{"fits", 100000, false, true, true},
+		{"docs stripped", 250, true, true, true},
+		{"nested dropped", 120, true, true, false},
+		{"replaced", 20, true, false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			limited, reduced := schema.Limit(large, tt.maxBytes)
+			if reduced != tt.wantReduced {
+				t.Fatalf("Limit() reduced = %v, want %v", reduced, tt.wantReduced)
+			}
+			if reduced && limited.Description != "" && !strings.Contains(limited.Description, "truncated") {
+				t.Errorf("Limit() kept description %q", limited.Description)
+			}
+			if _, ok := limited.Properties["query"]; ok != tt.wantProperty {
+				t.Errorf("Limit() has query property = %v, want %v", ok, tt.wantProperty)
+			}
+			nested := limited.Properties["filters"] != nil && limited.Properties["filters"].Properties != nil
+			if nested != tt.wantNested {
+				t.Errorf("Limit() kept nested properties = %v, want %v", nested, tt.wantNested)
+			}
+		})
+	}
+
+	if large.Description == "" || large.Properties["query"].Description == "" {
+		t.Error("Limit() modified the original schema")
+	}
+}
+
+func TestToolLimitsApplied(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	ConfigureToolLimits(&config.ToolLimits{MaxDescriptionLength: 40, MaxSchemaBytes: 60})
+	defer ConfigureToolLimits(nil)
+
+	mockProxy := NewMockProxyManager()
+	mockProxy.AddMockTool("github", &mcp.Tool{
+		Name:        "search_issues",
+		Description: strings.Repeat("Searches issues in a repository. ", 5),
+		InputSchema: &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"query": {Type: "string", Description: strings.Repeat("Search query. ", 10)},
+			},
+		},
+	})
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{"github": {Command: "echo"}}}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterEvalStarlark(server, nil)
+	if err := RegisterProxiedTools(server, mockProxy, cfg); err != nil {
+		t.Fatalf("RegisterProxiedTools() error = %v", err)
+	}
+
+	for name, tool := range listServerTools(t, server) {
+		if n := utf8.RuneCountInString(tool.Description); n > 40 {
+			t.Errorf("tool %s description has %d characters, limit 40", name, n)
+		}
+		if !strings.HasSuffix(tool.Description, truncationMarker) {
+			t.Errorf("tool %s description %q missing truncation marker", name, tool.Description)
+		}
+		if size := schema.Size(tool.InputSchema); size > 60 {
+			t.Errorf("tool %s schema is %d bytes, limit 60", name, size)
+		}
+	}
+}
+
+func TestParamLimits(t *testing.T) {
+	ConfigureParamLimits(&config.ParamLimits{MaxBytes: 100, MaxDepth: 2})
+	defer ConfigureParamLimits(nil)
+
+	tests := []struct {
+		name      string
+		params    map[string]interface{}
+		wantError string
+	}{
+		{"within limits", map[string]interface{}{"name": "small"}, ""},
+		{"too large", map[string]interface{}{"text": strings.Repeat("x", 200)}, "paramLimits.maxBytes"},
+		{"too deep", map[string]interface{}{"a": []interface{}{[]interface{}{[]interface{}{"x"}}}}, "paramLimits.maxDepth"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := EvalStarlarkArgs{Code: "len(params)", Params: tt.params}
+			result, _, err := handleEvalStarlark(context.Background(), &mcp.CallToolRequest{}, args, nil)
+			if err != nil {
+				t.Fatalf("handleEvalStarlark() error = %v", err)
+			}
+			text := result.Content[0].(*mcp.TextContent).Text
+			if tt.wantError == "" {
+				if result.IsError {
+					t.Errorf("handleEvalStarlark() unexpected error: %s", text)
+				}
+				return
+			}
+			if !result.IsError || !strings.Contains(text, tt.wantError) {
+				t.Errorf("handleEvalStarlark() = %q, want an error mentioning %s", text, tt.wantError)
+			}
+		})
+	}
+}
@@ -4,17 +4,22 @@ import (
 	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/dslh/mcp-metatool/internal/persistence"
 	"github.com/dslh/mcp-metatool/internal/types"
+	"github.com/dslh/mcp-metatool/internal/usage"
 )
 
 // ToolSummary represents a summary of a saved tool for list_saved_tools
 type ToolSummary struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	CreatedAt   *time.Time `json:"createdAt,omitempty"`
+	UpdatedAt   *time.Time `json:"updatedAt,omitempty"`
+	Author      string     `json:"author,omitempty"`
 }
 
 // ToolListResponse wraps the tool list in an object structure expected by MCP
@@ -22,43 +27,77 @@ type ToolListResponse struct {
 	Tools []ToolSummary `json:"tools"`
 }
 
+// BulkDeleteResponse lists the saved tools a bulk operation deleted, or would delete
+type BulkDeleteResponse struct {
+	Tools   []string `json:"tools"`
+	Deleted bool     `json:"deleted"`
+}
+
 // RegisterListSavedTools registers the list_saved_tools tool with the MCP server
 func RegisterListSavedTools(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "list_saved_tools",
-		Description: "List all saved composite tool definitions",
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "list_saved_tools",
+		Description:  "List all saved composite tool definitions",
+		OutputSchema: outputSchema[ToolListResponse](),
 	}, handleListSavedTools)
 }
 
 // RegisterShowSavedTool registers the show_saved_tool tool with the MCP server
 func RegisterShowSavedTool(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
-		Name:        "show_saved_tool",
-		Description: "Show the complete definition of a saved tool",
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "show_saved_tool",
+		Description:  "Show the complete definition of a saved tool",
+		OutputSchema: outputSchema[persistence.SavedToolDefinition](),
 	}, handleShowSavedTool)
 }
 
 // RegisterDeleteSavedTool registers the delete_saved_tool tool with the MCP server
 func RegisterDeleteSavedTool(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
+	addBuiltinTool(server, &mcp.Tool{
 		Name:        "delete_saved_tool",
 		Description: "Delete a saved tool definition",
-	}, handleDeleteSavedTool)
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.DeleteToolArgs) (*mcp.CallToolResult, any, error) {
+		return handleDeleteSavedTool(server, args)
+	})
+}
+
+// RegisterDeleteSavedTools registers the delete_saved_tools tool with the MCP server
+func RegisterDeleteSavedTools(server *mcp.Server) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "delete_saved_tools",
+		Description:  "Delete every saved tool whose name matches a glob pattern, or list the matches with dryRun",
+		OutputSchema: outputSchema[BulkDeleteResponse](),
+	}, handleDeleteSavedTools)
+}
+
+// RegisterPruneSavedTools registers the prune_saved_tools tool with the MCP server
+func RegisterPruneSavedTools(server *mcp.Server) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "prune_saved_tools",
+		Description:  "List saved tools that haven't run in a number of days, deleting them when confirmed",
+		OutputSchema: outputSchema[BulkDeleteResponse](),
+	}, handlePruneSavedTools)
 }
 
-func handleListSavedTools(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+func handleListSavedTools(ctx context.Context, req *mcp.CallToolRequest, args types.ListSavedToolsArgs) (*mcp.CallToolResult, any, error) {
 	// Get all saved tools
 	tools, err := persistence.ListTools()
 	if err != nil {
 		return ErrorResponse("Failed to list saved tools: %v", err), nil, nil
 	}
+	if err := persistence.SortTools(tools, args.Sort); err != nil {
+		return ErrorResponse("Error: %v", err), nil, nil
+	}
 
 	// Convert to summary format
-	var summaries []ToolSummary
+	summaries := []ToolSummary{}
 	for _, tool := range tools {
 		summaries = append(summaries, ToolSummary{
 			Name:        tool.Name,
 			Description: tool.Description,
+			CreatedAt:   tool.CreatedAt,
+			UpdatedAt:   tool.UpdatedAt,
+			Author:      tool.Author,
 		})
 	}
 
@@ -92,20 +131,109 @@ func handleShowSavedTool(ctx context.Context, req *mcp.CallToolRequest, args typ
 		return ErrorResponse("Failed to load tool '%s': %v", args.Name, err), nil, nil
 	}
 
-	return SuccessResponse(tool.Code), tool, nil
+	return SuccessResponse("%s", metadataHeader(tool)+tool.Code), tool, nil
+}
+
+// metadataHeader describes when and by whom a tool was saved as Starlark comments, so the code shown
+// stays valid Starlark
+func metadataHeader(tool *persistence.SavedToolDefinition) string {
+	var header string
+	if tool.Author != "" {
+		header += fmt.Sprintf("# Author: %s\n", tool.Author)
+	}
+	if tool.CreatedAt != nil {
+		header += fmt.Sprintf("# Created: %s\n", tool.CreatedAt.Format(time.RFC3339))
+	}
+	if tool.UpdatedAt != nil {
+		header += fmt.Sprintf("# Updated: %s\n", tool.UpdatedAt.Format(time.RFC3339))
+	}
+	if header != "" {
+		header += "\n"
+	}
+	return header
 }
 
-func handleDeleteSavedTool(ctx context.Context, req *mcp.CallToolRequest, args types.DeleteToolArgs) (*mcp.CallToolResult, any, error) {
+func handleDeleteSavedTool(server *mcp.Server, args types.DeleteToolArgs) (*mcp.CallToolResult, any, error) {
 	// Validate arguments
 	if args.Name == "" {
 		return ErrorResponse("Error: tool name is required"), nil, nil
 	}
 
 	// Delete the tool
-	err := persistence.DeleteTool(args.Name)
-	if err != nil {
+	if err := DeleteTool(server, args.Name); err != nil {
 		return ErrorResponse("Failed to delete tool '%s': %v", args.Name, err), nil, nil
 	}
 
-	return SuccessResponse("Tool '%s' deleted successfully. Restart server to remove from available tools.", args.Name), map[string]string{"deleted": args.Name}, nil
-}
\ No newline at end of file
+	return SuccessResponse("Tool '%s' deleted successfully. Use restore_saved_tool to bring it back.", args.Name), map[string]string{"deleted": args.Name}, nil
+}
+
+// DeleteTool deletes a saved tool as delete_saved_tool does, keeping a backup that can be restored,
+// and removes it from server's tool list if server isn't nil
+func DeleteTool(server *mcp.Server, name string) error {
+	if err := persistence.DeleteTool(name); err != nil {
+		return err
+	}
+	if server != nil {
+		server.RemoveTools(name)
+	}
+	return nil
+}
+
+func handleDeleteSavedTools(ctx context.Context, req *mcp.CallToolRequest, args types.DeleteToolsArgs) (*mcp.CallToolResult, any, error) {
+	names, err := persistence.MatchTools(args.Patterns)
+	if err != nil {
+		return ErrorResponse("Error: %v", err), nil, nil
+	}
+	if len(names) == 0 {
+		return SuccessResponse("No saved tools match %s", strings.Join(args.Patterns, ", ")), BulkDeleteResponse{Tools: []string{}}, nil
+	}
+	return bulkDelete(names, !args.DryRun)
+}
+
+func handlePruneSavedTools(ctx context.Context, req *mcp.CallToolRequest, args types.PruneToolsArgs) (*mcp.CallToolResult, any, error) {
+	if args.Days <= 0 {
+		return ErrorResponse("Error: days must be positive"), nil, nil
+	}
+
+	names, err := UnusedSavedTools(args.Days)
+	if err != nil {
+		return ErrorResponse("Error: %v", err), nil, nil
+	}
+	if len(names) == 0 {
+		return SuccessResponse("No saved tools have gone unused for %d day(s)", args.Days), BulkDeleteResponse{Tools: []string{}}, nil
+	}
+	return bulkDelete(names, args.Confirm)
+}
+
+// UnusedSavedTools returns the saved tools that haven't run in the given number of days, per usage.json
+func UnusedSavedTools(days int) ([]string, error) {
+	tools, err := persistence.ListTools()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list saved tools: %w", err)
+	}
+	stats, err := usage.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tool usage: %w", err)
+	}
+
+	// Tools saved or updated recently haven't had the chance to be used
+	saved := make(map[string]time.Time, len(tools))
+	for _, tool := range tools {
+		saved[tool.Name] = persistence.SavedAt(tool)
+	}
+	return stats.UnusedSaved(saved, time.Now().AddDate(0, 0, -days)), nil
+}
+
+// bulkDelete deletes the named tools, or only lists them if remove is false
+func bulkDelete(names []string, remove bool) (*mcp.CallToolResult, any, error) {
+	list := "• " + strings.Join(names, "\n• ")
+	if !remove {
+		return SuccessResponse("Would delete %d saved tool(s):\n\n%s", len(names), list), BulkDeleteResponse{Tools: names}, nil
+	}
+
+	deleted, err := persistence.DeleteTools(names)
+	if err != nil {
+		return ErrorResponse("Deleted %d of %d saved tool(s) before failing: %v", len(deleted), len(names), err), nil, nil
+	}
+	return SuccessResponse("Deleted %d saved tool(s). Restart server to remove them from available tools. Use restore_saved_tool to bring any back.\n\n%s", len(names), list), BulkDeleteResponse{Tools: deleted, Deleted: true}, nil
+}
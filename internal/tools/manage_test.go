@@ -2,10 +2,12 @@ package tools
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
@@ -132,7 +134,7 @@ func TestHandleListSavedTools(t *testing.T) {
 			ctx := context.Background()
 			req := &mcp.CallToolRequest{}
 
-			result, returnValue, err := handleListSavedTools(ctx, req, struct{}{})
+			result, returnValue, err := handleListSavedTools(ctx, req, types.ListSavedToolsArgs{})
 
 			// Check for framework errors
 			if err != nil {
@@ -243,10 +245,14 @@ func TestHandleShowSavedTool(t *testing.T) {
 				return
 			}
 
+			if result.IsError == tt.wantSuccess {
+				t.Errorf("handleShowSavedTool() IsError = %v, want %v", result.IsError, !tt.wantSuccess)
+			}
+
 			if tt.wantSuccess {
-				// Should return the tool's Starlark code
-				if textContent.Text != testToolCode {
-					t.Errorf("handleShowSavedTool() expected Starlark code '%s', got: %s", testToolCode, textContent.Text)
+				// Should return the tool's Starlark code, after comments saying when it was saved
+				if !strings.HasSuffix(textContent.Text, "\n\n"+testToolCode) || !strings.Contains(textContent.Text, "# Created: ") {
+					t.Errorf("handleShowSavedTool() expected Starlark code '%s' after its timestamps, got: %s", testToolCode, textContent.Text)
 					return
 				}
 
@@ -334,10 +340,7 @@ func TestHandleDeleteSavedTool(t *testing.T) {
 				createTestTool(t, tt.args.Name, "A tool for testing delete functionality", "result = 'delete test'")
 			}
 
-			ctx := context.Background()
-			req := &mcp.CallToolRequest{}
-
-			result, returnValue, err := handleDeleteSavedTool(ctx, req, tt.args)
+			result, returnValue, err := handleDeleteSavedTool(nil, tt.args)
 
 			// Check for framework errors
 			if err != nil {
@@ -362,6 +365,10 @@ func TestHandleDeleteSavedTool(t *testing.T) {
 				return
 			}
 
+			if result.IsError == tt.wantSuccess {
+				t.Errorf("handleDeleteSavedTool() IsError = %v, want %v", result.IsError, !tt.wantSuccess)
+			}
+
 			if tt.wantSuccess {
 				// Should contain success message
 				expectedMsg := "deleted successfully"
@@ -370,9 +377,9 @@ func TestHandleDeleteSavedTool(t *testing.T) {
 					return
 				}
 
-				// Should mention restart requirement
-				if !strings.Contains(textContent.Text, "Restart server") {
-					t.Errorf("handleDeleteSavedTool() expected restart message in: %s", textContent.Text)
+				// Should mention how to undo the deletion
+				if !strings.Contains(textContent.Text, "restore_saved_tool") {
+					t.Errorf("handleDeleteSavedTool() expected restore hint in: %s", textContent.Text)
 				}
 
 				// Return value should contain deletion confirmation
@@ -416,4 +423,91 @@ func TestHandleDeleteSavedTool(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestHandleDeleteSavedTools(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	for _, name := range []string{"experiment_a", "experiment_b", "report"} {
+		createTestTool(t, name, "A tool for testing bulk deletion", "1")
+	}
+	ctx := context.Background()
+
+	// A dry run lists the matches without deleting them
+	result, structured, _ := handleDeleteSavedTools(ctx, nil, types.DeleteToolsArgs{Patterns: []string{"experiment_*"}, DryRun: true})
+	verifyTextContent(t, result, "Would delete 2 saved tool(s)")
+	response := structured.(BulkDeleteResponse)
+	if response.Deleted || strings.Join(response.Tools, ",") != "experiment_a,experiment_b" {
+		t.Errorf("dry run response = %+v", response)
+	}
+	if _, err := persistence.LoadTool("experiment_a"); err != nil {
+		t.Errorf("dry run deleted experiment_a: %v", err)
+	}
+
+	result, structured, _ = handleDeleteSavedTools(ctx, nil, types.DeleteToolsArgs{Patterns: []string{"experiment_*"}})
+	verifyTextContent(t, result, "Deleted 2 saved tool(s)")
+	if !structured.(BulkDeleteResponse).Deleted {
+		t.Error("expected tools to be deleted")
+	}
+	remaining, _ := persistence.ListTools()
+	if len(remaining) != 1 || remaining[0].Name != "report" {
+		t.Errorf("expected only report to remain, got %d tools", len(remaining))
+	}
+
+	result, _, _ = handleDeleteSavedTools(ctx, nil, types.DeleteToolsArgs{Patterns: []string{"experiment_*"}})
+	verifyTextContent(t, result, "No saved tools match")
+
+	result, _, _ = handleDeleteSavedTools(ctx, nil, types.DeleteToolsArgs{Patterns: []string{"[bad"}})
+	if !result.IsError {
+		t.Error("expected an error for an invalid pattern")
+	}
+}
+
+// writeUntimedTool writes a tool definition without the timestamps saving records, as tools saved
+// before they were recorded have, last modified at modTime
+func writeUntimedTool(t *testing.T, dir, name string, modTime time.Time) {
+	t.Helper()
+	filename := filepath.Join(dir, "tools", name+".json")
+	if err := os.MkdirAll(filepath.Dir(filename), 0755); err != nil {
+		t.Fatal(err)
+	}
+	definition := fmt.Sprintf(`{"name": %q, "description": "A tool for testing pruning", "inputSchema": {}, "code": "1"}`, name)
+	if err := os.WriteFile(filename, []byte(definition), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chtimes(filename, modTime, modTime); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHandlePruneSavedTools(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+	longAgo := time.Now().AddDate(0, -6, 0)
+	for _, name := range []string{"fresh", "stale", "unused"} {
+		writeUntimedTool(t, dir, name, longAgo)
+	}
+	// Saved just now, so it hasn't had the chance to be used
+	createTestTool(t, "brand_new", "A tool for testing pruning", "1")
+	stats := fmt.Sprintf(`{"since": "2026-01-01T00:00:00Z", "saved": {"fresh": {"calls": 2, "lastUsed": %q}, "stale": {"calls": 1, "lastUsed": "2026-01-02T00:00:00Z"}}}`,
+		time.Now().Format(time.RFC3339))
+	os.WriteFile(filepath.Join(dir, "usage.json"), []byte(stats), 0644)
+	ctx := context.Background()
+
+	result, _, _ := handlePruneSavedTools(ctx, nil, types.PruneToolsArgs{Days: 0})
+	if !result.IsError {
+		t.Error("expected an error for zero days")
+	}
+
+	// Without confirm the unused tools are only listed
+	result, structured, _ := handlePruneSavedTools(ctx, nil, types.PruneToolsArgs{Days: 30})
+	verifyTextContent(t, result, "Would delete 2 saved tool(s)")
+	if tools := structured.(BulkDeleteResponse).Tools; strings.Join(tools, ",") != "stale,unused" {
+		t.Errorf("prune candidates = %v, want [stale unused]", tools)
+	}
+
+	result, _, _ = handlePruneSavedTools(ctx, nil, types.PruneToolsArgs{Days: 30, Confirm: true})
+	verifyTextContent(t, result, "Deleted 2 saved tool(s)")
+	remaining, _ := persistence.ListTools()
+	if len(remaining) != 2 {
+		t.Errorf("expected brand_new and fresh to remain, got %d tools", len(remaining))
+	}
+}
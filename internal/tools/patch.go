@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"context"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+// RegisterPatchSavedTool registers the patch_saved_tool tool with the MCP server. The updated tool
+// replaces the old definition in the tool list straight away, running with proxyManager and opts.
+func RegisterPatchSavedTool(server *mcp.Server, proxyManager ProxyManager, opts ...starlark.Option) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "patch_saved_tool",
+		Description:  "Update only the given fields of a saved tool, such as its code, leaving the rest of its definition unchanged",
+		OutputSchema: outputSchema[persistence.SavedToolDefinition](),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.PatchToolArgs) (*mcp.CallToolResult, any, error) {
+		return handlePatchSavedTool(server, args, proxyManager, opts...)
+	})
+}
+
+func handlePatchSavedTool(server *mcp.Server, args types.PatchToolArgs, proxyManager ProxyManager, opts ...starlark.Option) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" {
+		return ErrorResponse("Error: tool name is required"), nil, nil
+	}
+
+	tool, err := persistence.LoadTool(args.Name)
+	if err != nil {
+		return ErrorResponse("Failed to load tool '%s': %v", args.Name, err), nil, nil
+	}
+	// Concurrent saves between loading the tool and saving it are refused rather than overwritten
+	readVersion := max(tool.Version, 1)
+	if args.ExpectedVersion != nil && *args.ExpectedVersion != readVersion {
+		return saveErrorResponse(&persistence.VersionConflictError{Name: tool.Name, Expected: *args.ExpectedVersion, Current: readVersion}), nil, nil
+	}
+	previous := *tool
+	if args.Code != "" {
+		if err := starlark.CheckSyntax(args.Code); err != nil {
+			return ErrorResponse("Error: tool code does not compile: %v", err), nil, nil
+		}
+	}
+	changed := patchTool(tool, args)
+	if len(changed) == 0 {
+		return ErrorResponse("Error: no fields to update were given"), nil, nil
+	}
+	// Permissions are granted by the operator for the code they reviewed and the servers it could call
+	var revoked []string
+	if !persistence.GrantApplies(&previous, tool) {
+		revoked, tool.Permissions = tool.Permissions, nil
+	}
+
+	if err := persistence.SaveToolIfUnchanged(tool, readVersion); err != nil {
+		return saveErrorResponse(err), nil, nil
+	}
+	if server != nil {
+		registerSavedTool(server, tool, proxyManager, opts...)
+	}
+
+	return SuccessResponse("Tool '%s' updated (%s), saved as version %d%s", tool.Name, strings.Join(changed, ", "), tool.Version, dependencyWarnings(tool, proxyManager, opts...)+revokedPermissionsNote(revoked)), tool, nil
+}
+
+// patchTool applies the fields given in args to a tool's definition, returning the names of those it set
+func patchTool(tool *persistence.SavedToolDefinition, args types.PatchToolArgs) []string {
+	var changed []string
+	if args.Description != "" {
+		tool.Description = args.Description
+		changed = append(changed, "description")
+	}
+	if args.InputSchema != nil {
+		tool.InputSchema = args.InputSchema
+		changed = append(changed, "inputSchema")
+	}
+	if args.Code != "" {
+		tool.Code = args.Code
+		changed = append(changed, "code")
+	}
+	if args.Tests != nil {
+		tool.Tests = args.Tests
+		changed = append(changed, "tests")
+	}
+	if args.Presets != nil {
+		tool.Presets = args.Presets
+		changed = append(changed, "presets")
+	}
+	if args.SessionAffinity != nil {
+		tool.SessionAffinity = *args.SessionAffinity
+		changed = append(changed, "sessionAffinity")
+	}
+	if args.AllowedServers != nil {
+		tool.AllowedServers = args.AllowedServers
+		changed = append(changed, "allowedServers")
+	}
+	if args.Author != "" {
+		tool.Author = args.Author
+		changed = append(changed, "author")
+	}
+	if args.Annotations != nil {
+		tool.Annotations = args.Annotations
+		if *args.Annotations == (persistence.ToolAnnotations{}) {
+			tool.Annotations = nil
+		}
+		changed = append(changed, "annotations")
+	}
+	return changed
+}
@@ -0,0 +1,101 @@
+package tools
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+func TestHandlePatchSavedTool(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	schema := map[string]interface{}{"type": "object", "properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}}}
+	original := &persistence.SavedToolDefinition{
+		Name:           "greet",
+		Description:    "Greet someone",
+		InputSchema:    schema,
+		Code:           `"hello " + params["name"]`,
+		Presets:        map[string]map[string]interface{}{"ada": {"name": "Ada"}},
+		Permissions:    []string{"notify"},
+		AllowedServers: []string{"github", "slack"},
+	}
+	affinity := true
+
+	tests := []struct {
+		name    string
+		args    types.PatchToolArgs
+		check   func(*persistence.SavedToolDefinition) bool
+		wantErr bool
+	}{
+		{"code only", types.PatchToolArgs{Name: "greet", Code: `"hi " + params["name"]`}, func(tool *persistence.SavedToolDefinition) bool {
+			return tool.Code == `"hi " + params["name"]` && tool.Description == "Greet someone" && reflect.DeepEqual(tool.InputSchema, schema) && len(tool.Presets) == 1 && len(tool.Permissions) == 0
+		}, false},
+		{"description and affinity", types.PatchToolArgs{Name: "greet", Description: "Say hello", SessionAffinity: &affinity}, func(tool *persistence.SavedToolDefinition) bool {
+			return tool.Description == "Say hello" && tool.SessionAffinity && tool.Code == original.Code && reflect.DeepEqual(tool.Permissions, []string{"notify"})
+		}, false},
+		{"unchanged code", types.PatchToolArgs{Name: "greet", Code: original.Code}, func(tool *persistence.SavedToolDefinition) bool {
+			return reflect.DeepEqual(tool.Permissions, []string{"notify"})
+		}, false},
+		{"allowed servers changed", types.PatchToolArgs{Name: "greet", AllowedServers: []string{"github", "linear"}}, func(tool *persistence.SavedToolDefinition) bool {
+			return reflect.DeepEqual(tool.AllowedServers, []string{"github", "linear"}) && len(tool.Permissions) == 0
+		}, false},
+		{"allowed servers cleared", types.PatchToolArgs{Name: "greet", AllowedServers: []string{}}, func(tool *persistence.SavedToolDefinition) bool {
+			return len(tool.AllowedServers) == 0 && len(tool.Permissions) == 0
+		}, false},
+		{"allowed servers reordered", types.PatchToolArgs{Name: "greet", AllowedServers: []string{"slack", "github"}}, func(tool *persistence.SavedToolDefinition) bool {
+			return reflect.DeepEqual(tool.Permissions, []string{"notify"})
+		}, false},
+		{"empty values clear", types.PatchToolArgs{Name: "greet", Presets: map[string]map[string]interface{}{}}, func(tool *persistence.SavedToolDefinition) bool {
+			return len(tool.Presets) == 0 && tool.Code == original.Code
+		}, false},
+		{"at the read version", types.PatchToolArgs{Name: "greet", Code: "2", ExpectedVersion: intPtr(-1)}, func(tool *persistence.SavedToolDefinition) bool {
+			return tool.Code == "2"
+		}, false},
+		{"outdated version", types.PatchToolArgs{Name: "greet", Code: "2", ExpectedVersion: intPtr(1)}, nil, true},
+		{"annotations", types.PatchToolArgs{Name: "greet", Annotations: &persistence.ToolAnnotations{ReadOnlyHint: true}}, func(tool *persistence.SavedToolDefinition) bool {
+			return tool.Annotations != nil && tool.Annotations.ReadOnlyHint
+		}, false},
+		{"empty annotations", types.PatchToolArgs{Name: "greet", Annotations: &persistence.ToolAnnotations{}}, func(tool *persistence.SavedToolDefinition) bool {
+			return tool.Annotations == nil
+		}, false},
+		{"syntax error", types.PatchToolArgs{Name: "greet", Code: "def main(:\n    pass"}, nil, true},
+		{"nothing to update", types.PatchToolArgs{Name: "greet"}, nil, true},
+		{"missing tool", types.PatchToolArgs{Name: "missing", Code: "1"}, nil, true},
+		{"no name", types.PatchToolArgs{Code: "1"}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saved := *original
+			if err := persistence.SaveTool(&saved); err != nil {
+				t.Fatal(err)
+			}
+			if tt.args.ExpectedVersion != nil && *tt.args.ExpectedVersion < 0 {
+				tt.args.ExpectedVersion = intPtr(saved.Version)
+			}
+			result, _, _ := handlePatchSavedTool(nil, tt.args, nil)
+			if result.IsError != tt.wantErr {
+				t.Fatalf("IsError = %v, want %v: %s", result.IsError, tt.wantErr, result.Content[0].(*mcp.TextContent).Text)
+			}
+			if tt.wantErr {
+				return
+			}
+			tool, err := persistence.LoadTool("greet")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !tt.check(tool) {
+				t.Errorf("Patched tool = %+v", tool)
+			}
+			if tool.Version != saved.Version+1 {
+				t.Errorf("Version = %d, want %d", tool.Version, saved.Version+1)
+			}
+		})
+	}
+}
+
+func intPtr(n int) *int {
+	return &n
+}
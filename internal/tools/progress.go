@@ -0,0 +1,62 @@
+package tools
+
+import (
+	"context"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+)
+
+// progressOption forwards progress reported by Starlark code to the client as MCP progress
+// notifications, if the client asked for them by sending a progress token with the request
+func progressOption(ctx context.Context, req *mcp.CallToolRequest) starlark.Option {
+	if req == nil || req.Session == nil || req.Params == nil || req.Params.GetProgressToken() == nil {
+		return starlark.WithProgress(nil)
+	}
+	token := req.Params.GetProgressToken()
+
+	// Progress must increase with each notification: report percentages once code gives them,
+	// and until then count the updates. Spawned functions may report progress concurrently.
+	var mu sync.Mutex
+	var updates, last float64
+	percentages := false
+	return starlark.WithProgress(func(message string, percent float64) {
+		mu.Lock()
+		defer mu.Unlock()
+		updates++
+		params := &mcp.ProgressNotificationParams{ProgressToken: token, Message: message, Progress: updates}
+		if percent >= 0 {
+			last, percentages = percent, true
+		}
+		if percentages {
+			params.Progress, params.Total = last, 100
+		}
+		if err := req.Session.NotifyProgress(ctx, params); err != nil {
+			logging.Debugf("Failed to send progress notification: %v", err)
+		}
+	})
+}
+
+// upstreamProgress relays the progress notifications an upstream server sends during a proxied call
+// to the client, under the progress token the client sent with its request
+func upstreamProgress(ctx context.Context, req *mcp.CallToolRequest) proxy.ProgressFunc {
+	if req == nil || req.Session == nil || req.Params == nil || req.Params.GetProgressToken() == nil {
+		return nil
+	}
+	token := req.Params.GetProgressToken()
+	return func(params *mcp.ProgressNotificationParams) {
+		err := req.Session.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+			ProgressToken: token,
+			Message:       params.Message,
+			Progress:      params.Progress,
+			Total:         params.Total,
+		})
+		if err != nil {
+			logging.Debugf("Failed to forward progress notification: %v", err)
+		}
+	}
+}
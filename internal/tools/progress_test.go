@@ -0,0 +1,159 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+)
+
+func TestProgressNotifications(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	ctx := context.Background()
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterEvalStarlark(server, nil)
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+
+	var mu sync.Mutex
+	var got []string
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, fmt.Sprintf("%v %s %v/%v", req.Params.ProgressToken, req.Params.Message, req.Params.Progress, req.Params.Total))
+		},
+	})
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer clientSession.Close()
+
+	code := "progress('starting')\nprogress('halfway', 50)\nprogress('still going')\nresult = 'done'"
+	tests := []struct {
+		name  string
+		token any
+		want  []string
+	}{
+		{"with token", "job-1", []string{"job-1 starting 1/0", "job-1 halfway 50/100", "job-1 still going 50/100"}},
+		{"without token", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mu.Lock()
+			got = nil
+			mu.Unlock()
+
+			params := &mcp.CallToolParams{Name: "eval_starlark", Arguments: map[string]any{"code": code}}
+			if tt.token != nil {
+				params.Meta = mcp.Meta{} // SetProgressToken only sets an existing _meta
+				params.SetProgressToken(tt.token)
+			}
+			result, err := clientSession.CallTool(ctx, params)
+			if err != nil || result.IsError {
+				t.Fatalf("CallTool() = %v, %v", result, err)
+			}
+
+			// Notifications are handled concurrently with the call's response
+			deadline := time.Now().Add(time.Second)
+			for time.Now().Before(deadline) {
+				mu.Lock()
+				n := len(got)
+				mu.Unlock()
+				if n >= len(tt.want) {
+					break
+				}
+				time.Sleep(10 * time.Millisecond)
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("Got notifications %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// progressProxyManager reports progress from its upstream calls, as a server sending notifications would
+type progressProxyManager struct {
+	*MockProxyManager
+}
+
+func (p progressProxyManager) CallToolContext(ctx context.Context, serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	if report := proxy.ProgressFromContext(ctx); report != nil {
+		report(&mcp.ProgressNotificationParams{Message: "indexing", Progress: 3, Total: 10})
+		report(&mcp.ProgressNotificationParams{Message: "indexed", Progress: 10, Total: 10})
+	}
+	return p.CallTool(serverName, toolName, arguments)
+}
+
+func TestUpstreamProgressNotifications(t *testing.T) {
+	ctx := context.Background()
+	proxyManager := progressProxyManager{NewMockProxyManager()}
+	proxyManager.AddMockTool("search", &mcp.Tool{Name: "reindex", InputSchema: &jsonschema.Schema{Type: "object"}})
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{"search": {Command: "search"}}}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	if err := RegisterProxiedTools(server, proxyManager, cfg); err != nil {
+		t.Fatalf("RegisterProxiedTools() error = %v", err)
+	}
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect server: %v", err)
+	}
+	defer serverSession.Close()
+
+	var mu sync.Mutex
+	var got []string
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, &mcp.ClientOptions{
+		ProgressNotificationHandler: func(ctx context.Context, req *mcp.ProgressNotificationClientRequest) {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, fmt.Sprintf("%v %s %v/%v", req.Params.ProgressToken, req.Params.Message, req.Params.Progress, req.Params.Total))
+		},
+	})
+	clientSession, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer clientSession.Close()
+
+	params := &mcp.CallToolParams{Name: "search__reindex", Arguments: map[string]any{}}
+	params.Meta = mcp.Meta{}
+	params.SetProgressToken("reindex-1")
+	result, err := clientSession.CallTool(ctx, params)
+	if err != nil || result.IsError {
+		t.Fatalf("CallTool() = %v, %v", result, err)
+	}
+
+	want := []string{"reindex-1 indexing 3/10", "reindex-1 indexed 10/10"}
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		mu.Lock()
+		n := len(got)
+		mu.Unlock()
+		if n >= len(want) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("Got notifications %v, want %v", got, want)
+	}
+}
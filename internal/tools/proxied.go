@@ -3,11 +3,12 @@ package tools
 import (
 	"context"
 	"fmt"
-	"log"
+	"sync"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/logging"
 	"github.com/dslh/mcp-metatool/internal/proxy"
 	"github.com/dslh/mcp-metatool/internal/schema"
 )
@@ -18,11 +19,55 @@ type ProxyManager = proxy.ProxyManager
 // ProxiedToolArgs represents the arguments for a proxied tool call
 type ProxiedToolArgs map[string]interface{}
 
+// upstreamTool identifies the upstream tool a proxied tool forwards to
+type upstreamTool struct {
+	server string
+	tool   string
+}
+
+// proxiedNames records the upstream tool behind each registered proxied tool's advertised name,
+// since names can't be split reliably once prefixes and separators are configurable
+var (
+	proxiedNamesMu sync.Mutex
+	proxiedNames   = make(map[string]upstreamTool)
+)
+
+// lookupProxiedTool returns the upstream tool a registered proxied tool forwards to
+func lookupProxiedTool(name string) (upstreamTool, bool) {
+	proxiedNamesMu.Lock()
+	defer proxiedNamesMu.Unlock()
+	upstream, ok := proxiedNames[name]
+	return upstream, ok
+}
+
+// proxiedToolName returns the advertised name of the proxied tool forwarding to an upstream tool,
+// if it is registered
+func proxiedToolName(serverName, toolName string) (string, bool) {
+	proxiedNamesMu.Lock()
+	defer proxiedNamesMu.Unlock()
+	for name, upstream := range proxiedNames {
+		if upstream.server == serverName && upstream.tool == toolName {
+			return name, true
+		}
+	}
+	return "", false
+}
+
+// removeProxiedTools unregisters proxied tools from the server
+func removeProxiedTools(server *mcp.Server, names []string) {
+	server.RemoveTools(names...)
+	proxiedNamesMu.Lock()
+	defer proxiedNamesMu.Unlock()
+	for _, name := range names {
+		delete(proxiedNames, name)
+	}
+}
+
 // RegisterProxiedTools registers all discovered tools from upstream MCP servers
 func RegisterProxiedTools(server *mcp.Server, proxyManager ProxyManager, cfg *config.Config) error {
 	// Check if proxied tools should be hidden globally
 	if config.ShouldHideProxiedTools() {
-		log.Printf("Proxied tools are hidden via MCP_METATOOL_HIDE_PROXIED_TOOLS environment variable")
+		logging.Infof("Proxied tools are hidden via MCP_METATOOL_HIDE_PROXIED_TOOLS environment variable")
 		return nil
 	}
 
@@ -33,54 +78,137 @@ func RegisterProxiedTools(server *mcp.Server, proxyManager ProxyManager, cfg *co
 		// Get server configuration
 		serverConfig, exists := cfg.MCPServers[serverName]
 		if !exists {
-			log.Printf("Warning: No configuration found for server %s, skipping tools", serverName)
+			logging.Warnf("No configuration found for server %s, skipping tools", serverName)
 			continue
 		}
 
 		// Check if this specific server should be hidden
 		if serverConfig.Hidden {
-			log.Printf("Skipping tools from hidden server: %s", serverName)
+			logging.Infof("Skipping tools from hidden server: %s", serverName)
 			continue
 		}
 
-		for _, tool := range tools {
-			// Check if this tool should be included based on server configuration
-			if !serverConfig.ShouldIncludeTool(tool.Name) {
-				log.Printf("Filtered out tool: %s.%s", serverName, tool.Name)
-				continue
-			}
+		totalRegistered += registerServerTools(server, proxyManager, cfg, serverName, tools)
+	}
+
+	logging.Infof("Successfully registered %d proxied tools from %d servers", totalRegistered, len(allTools))
+	return nil
+}
 
-			// Create a prefixed tool name to avoid conflicts
-			prefixedName := fmt.Sprintf("%s__%s", serverName, tool.Name)
+// ProxiedToolNames returns the names under which upstream tools are advertised with the given configuration
+func ProxiedToolNames(cfg *config.Config, tools map[string][]*mcp.Tool) []string {
+	var names []string
+	for serverName, serverTools := range tools {
+		for _, tool := range serverTools {
+			if name := cfg.ProxiedToolName(serverName, tool.Name); !builtinNames[name] && !cfg.MCPServers[serverName].Shadowed(tool.Name) {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
 
-			// Create a closure to capture the server and tool names
-			capturedServerName := serverName
-			capturedToolName := tool.Name
+// ReregisterProxiedTools replaces the previously registered proxied tools, named as ProxiedToolNames
+// returned them, with registrations for the proxy manager's current tools, applying the given configuration
+func ReregisterProxiedTools(server *mcp.Server, proxyManager ProxyManager, cfg *config.Config, previous []string) error {
+	if len(previous) > 0 {
+		removeProxiedTools(server, previous)
+	}
 
-			// Transform the schema to ensure compatibility with draft-2020-12
-			transformedSchema := schema.SafeTransform(tool.InputSchema, fmt.Sprintf("tool %s", tool.Name))
+	return RegisterProxiedTools(server, proxyManager, cfg)
+}
 
-			mcp.AddTool(server, &mcp.Tool{
-				Name:        prefixedName,
-				Description: fmt.Sprintf("[%s] %s", serverName, tool.Description),
-				InputSchema: transformedSchema,
-			}, func(ctx context.Context, req *mcp.CallToolRequest, args ProxiedToolArgs) (*mcp.CallToolResult, any, error) {
-				return handleProxiedTool(proxyManager, capturedServerName, capturedToolName, args)
-			})
+// UpdateProxiedTools re-registers a server's proxied tools after they change upstream,
+// removing tools that are no longer offered
+func UpdateProxiedTools(server *mcp.Server, proxyManager ProxyManager, cfg *config.Config, serverName string, previous, current []*mcp.Tool) {
+	serverConfig, exists := cfg.MCPServers[serverName]
+	if !exists || serverConfig.Hidden || config.ShouldHideProxiedTools() {
+		return
+	}
 
-			log.Printf("Registered proxied tool: %s -> %s.%s", prefixedName, serverName, tool.Name)
-			totalRegistered++
+	offered := make(map[string]bool, len(current))
+	for _, tool := range current {
+		offered[tool.Name] = true
+	}
+	var removed []string
+	for _, tool := range previous {
+		if !offered[tool.Name] {
+			removed = append(removed, cfg.ProxiedToolName(serverName, tool.Name))
 		}
 	}
+	if len(removed) > 0 {
+		removeProxiedTools(server, removed)
+		logging.Infof("Removed proxied tools no longer offered by %s: %v", serverName, removed)
+	}
 
-	log.Printf("Successfully registered %d proxied tools from %d servers", totalRegistered, len(allTools))
-	return nil
+	registerServerTools(server, proxyManager, cfg, serverName, current)
+}
+
+// registerServerTools registers one server's tools as proxied tools, returning how many were registered
+func registerServerTools(server *mcp.Server, proxyManager ProxyManager, cfg *config.Config, serverName string, tools []*mcp.Tool) int {
+	serverConfig := cfg.MCPServers[serverName]
+	registered := 0
+	for _, tool := range tools {
+		// Check if this tool should be included based on server configuration
+		if !serverConfig.ShouldIncludeTool(tool.Name) {
+			logging.Debugf("Filtered out tool: %s.%s", serverName, tool.Name)
+			continue
+		}
+		if serverConfig.Shadowed(tool.Name) {
+			logging.Debugf("Skipping tool %s.%s: another tool is aliased to its name", serverName, tool.Name)
+			continue
+		}
+		if readOnly && !proxy.IsReadOnlyTool(tool) {
+			logging.Debugf("Skipping tool %s.%s: it isn't marked read-only", serverName, tool.Name)
+			continue
+		}
+
+		// Prefix the tool name to avoid conflicts, unless the server is primary
+		prefixedName := cfg.ProxiedToolName(serverName, tool.Name)
+		if builtinNames[prefixedName] {
+			logging.Warnf("Skipping tool %s.%s: its name is taken by a built-in tool", serverName, tool.Name)
+			continue
+		}
+
+		// Create a closure to capture the server and tool names
+		capturedServerName := serverName
+		capturedToolName := tool.Name
+
+		// Check if calls to this tool must be approved first
+		requiresApproval := serverConfig.RequiresApproval(tool.Name)
+
+		// Transform the schema to ensure compatibility with draft-2020-12
+		transformedSchema := schema.SafeTransform(tool.InputSchema, fmt.Sprintf("tool %s", tool.Name))
+
+		mcpTool := &mcp.Tool{
+			Name:        prefixedName,
+			Description: fmt.Sprintf("[%s] %s", serverName, tool.Description),
+			InputSchema: transformedSchema,
+			Annotations: tool.Annotations,
+		}
+		applyToolLimits[ProxiedToolArgs](mcpTool)
+		mcp.AddTool(server, mcpTool, func(ctx context.Context, req *mcp.CallToolRequest, args ProxiedToolArgs) (*mcp.CallToolResult, any, error) {
+			ctx = proxy.WithProgress(ctx, upstreamProgress(ctx, req))
+			if requiresApproval {
+				return handleGatedProxiedTool(ctx, req, proxyManager, capturedServerName, capturedToolName, args)
+			}
+			return handleProxiedTool(ctx, proxyManager, capturedServerName, capturedToolName, args)
+		})
+
+		proxiedNamesMu.Lock()
+		proxiedNames[prefixedName] = upstreamTool{server: serverName, tool: tool.Name}
+		proxiedNamesMu.Unlock()
+
+		logging.Debugf("Registered proxied tool: %s -> %s.%s", prefixedName, serverName, tool.Name)
+		registered++
+	}
+	return registered
 }
 
-// handleProxiedTool forwards a tool call to the appropriate upstream server
-func handleProxiedTool(proxyManager ProxyManager, serverName, toolName string, args ProxiedToolArgs) (*mcp.CallToolResult, any, error) {
+// handleProxiedTool forwards a tool call to the appropriate upstream server within ctx
+func handleProxiedTool(ctx context.Context, proxyManager ProxyManager, serverName, toolName string, args ProxiedToolArgs) (*mcp.CallToolResult, any, error) {
 	// Forward the call to the upstream server
-	result, err := proxyManager.CallTool(serverName, toolName, map[string]interface{}(args))
+	result, err := proxy.CallToolContext(ctx, proxyManager, serverName, toolName, map[string]interface{}(args))
 	if err != nil {
 		return ErrorResponse("Proxied tool call failed: %v", err), nil, nil
 	}
@@ -1,6 +1,8 @@
 package tools
 
 import (
+	"context"
+	"errors"
 	"os"
 	"testing"
 
@@ -8,6 +10,7 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/persistence"
 	"github.com/dslh/mcp-metatool/internal/schema"
 )
 
@@ -164,7 +167,7 @@ func TestHandleProxiedTool(t *testing.T) {
 		"body":  "This is a test issue",
 	}
 
-	result, _, err := handleProxiedTool(mockProxy, "github", "create_issue", args)
+	result, _, err := handleProxiedTool(context.Background(), mockProxy, "github", "create_issue", args)
 	if err != nil {
 		t.Fatalf("handleProxiedTool failed: %v", err)
 	}
@@ -187,6 +190,31 @@ func TestHandleProxiedTool(t *testing.T) {
 	}
 }
 
+// failingProxyManager is a proxy manager whose upstream calls always fail
+type failingProxyManager struct {
+	*MockProxyManager
+}
+
+func (f failingProxyManager) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestHandleProxiedToolError(t *testing.T) {
+	result, _, err := handleProxiedTool(context.Background(), failingProxyManager{NewMockProxyManager()}, "github", "create_issue", ProxiedToolArgs{})
+	if err != nil {
+		t.Fatalf("handleProxiedTool returned framework error: %v", err)
+	}
+
+	if !result.IsError {
+		t.Error("Expected IsError to be set for a failed upstream call")
+	}
+
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	if !ok || textContent.Text != "Proxied tool call failed: connection refused" {
+		t.Errorf("Unexpected error content: %v", result.Content[0])
+	}
+}
+
 func TestHandleProxiedToolWithStructuredContent(t *testing.T) {
 	// Test that structured content is properly extracted and no circular reference occurs
 	mockProxy := NewMockProxyManager()
@@ -212,7 +240,7 @@ func TestHandleProxiedToolWithStructuredContent(t *testing.T) {
 		"message": "Hello from test!",
 	}
 
-	result, structuredContent, err := handleProxiedTool(mockProxy, "echo", "echo", args)
+	result, structuredContent, err := handleProxiedTool(context.Background(), mockProxy, "echo", "echo", args)
 	if err != nil {
 		t.Fatalf("handleProxiedTool failed: %v", err)
 	}
@@ -495,4 +523,157 @@ func TestSafeTransformSchema(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+func TestUpdateProxiedTools(t *testing.T) {
+	mockProxy := NewMockProxyManager()
+	previous := []*mcp.Tool{
+		{Name: "get_issue", Description: "Fetch an issue", InputSchema: &jsonschema.Schema{Type: "object"}},
+		{Name: "old_tool", Description: "Going away", InputSchema: &jsonschema.Schema{Type: "object"}},
+	}
+	for _, tool := range previous {
+		mockProxy.AddMockTool("github", tool)
+	}
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{"github": {Command: "echo"}}}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	if err := RegisterProxiedTools(server, mockProxy, cfg); err != nil {
+		t.Fatalf("RegisterProxiedTools() error = %v", err)
+	}
+
+	current := []*mcp.Tool{
+		{Name: "get_issue", Description: "Fetch an issue by number", InputSchema: &jsonschema.Schema{Type: "object"}},
+		{Name: "new_tool", Description: "Just added", InputSchema: &jsonschema.Schema{Type: "object"}},
+	}
+	UpdateProxiedTools(server, mockProxy, cfg, "github", previous, current)
+
+	tools := listServerTools(t, server)
+	if _, exists := tools["github__old_tool"]; exists {
+		t.Error("Expected github__old_tool to be removed")
+	}
+	if _, exists := tools["github__new_tool"]; !exists {
+		t.Error("Expected github__new_tool to be registered")
+	}
+	if tool := tools["github__get_issue"]; tool == nil || tool.Description != "[github] Fetch an issue by number" {
+		t.Errorf("Expected github__get_issue to be updated, got %v", tool)
+	}
+}
+
+func TestReregisterProxiedToolsWithCustomNames(t *testing.T) {
+	mockProxy := NewMockProxyManager()
+	mockProxy.AddMockTool("github", &mcp.Tool{Name: "get_issue", InputSchema: &jsonschema.Schema{Type: "object"}})
+	mockProxy.AddMockTool("filesystem", &mcp.Tool{Name: "read_file", InputSchema: &jsonschema.Schema{Type: "object"}})
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"github":     {Command: "echo"},
+		"filesystem": {Command: "echo"},
+	}}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	if err := RegisterProxiedTools(server, mockProxy, cfg); err != nil {
+		t.Fatalf("RegisterProxiedTools() error = %v", err)
+	}
+
+	// Names are computed with the old config before it's replaced, as on reload
+	previous := ProxiedToolNames(cfg, mockProxy.GetAllTools())
+	cfg = &config.Config{
+		ToolNameSeparator: ".",
+		MCPServers: map[string]config.MCPServerConfig{
+			"github":     {Command: "echo", ToolPrefix: "gh"},
+			"filesystem": {Command: "echo", Primary: true},
+		},
+	}
+	if err := ReregisterProxiedTools(server, mockProxy, cfg, previous); err != nil {
+		t.Fatalf("ReregisterProxiedTools() error = %v", err)
+	}
+
+	tools := listServerTools(t, server)
+	if len(tools) != 2 || tools["gh.get_issue"] == nil || tools["read_file"] == nil {
+		t.Errorf("Expected gh.get_issue and read_file, got %v", tools)
+	}
+	if upstream, ok := lookupProxiedTool("gh.get_issue"); !ok || upstream.server != "github" || upstream.tool != "get_issue" {
+		t.Errorf("lookupProxiedTool(gh.get_issue) = %+v, %v", upstream, ok)
+	}
+	if toolGroup("read_file") != "filesystem" {
+		t.Errorf("Expected read_file to be counted under filesystem, got %s", toolGroup("read_file"))
+	}
+}
+
+func TestRegisterProxiedToolsWithAliases(t *testing.T) {
+	mockProxy := NewMockProxyManager()
+	mockProxy.AddMockTool("github", &mcp.Tool{Name: "search_issues", InputSchema: &jsonschema.Schema{Type: "object"}})
+	mockProxy.AddMockTool("github", &mcp.Tool{Name: "search_issues_v2", InputSchema: &jsonschema.Schema{Type: "object"}})
+	mockProxy.SetMockResult("github", "search_issues_v2", &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: "v2"}}})
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"github": {Command: "echo", ToolAliases: map[string]string{"search_issues_v2": "search_issues"}},
+	}}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	if err := RegisterProxiedTools(server, mockProxy, cfg); err != nil {
+		t.Fatalf("RegisterProxiedTools() error = %v", err)
+	}
+
+	tools := listServerTools(t, server)
+	if len(tools) != 1 || tools["github__search_issues"] == nil {
+		t.Fatalf("Expected only github__search_issues, got %v", tools)
+	}
+	if upstream, _ := lookupProxiedTool("github__search_issues"); upstream.tool != "search_issues_v2" {
+		t.Errorf("Expected github__search_issues to forward to search_issues_v2, got %+v", upstream)
+	}
+}
+
+func TestRegisterToolAnnotations(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	destructive := false
+	mockProxy := NewMockProxyManager()
+	mockProxy.AddMockTool("github", &mcp.Tool{
+		Name:        "get_issue",
+		InputSchema: &jsonschema.Schema{Type: "object"},
+		Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true, Title: "Get issue"},
+	})
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{"github": {Command: "echo"}}}
+	persistence.SaveTool(&persistence.SavedToolDefinition{
+		Name:        "close_stale",
+		Description: "Closes stale issues",
+		Code:        "1",
+		Annotations: &persistence.ToolAnnotations{DestructiveHint: &destructive, IdempotentHint: true},
+	})
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	if err := RegisterProxiedTools(server, mockProxy, cfg); err != nil {
+		t.Fatalf("RegisterProxiedTools() error = %v", err)
+	}
+	if err := RegisterSavedTools(server, mockProxy); err != nil {
+		t.Fatalf("RegisterSavedTools() error = %v", err)
+	}
+
+	tools := listServerTools(t, server)
+	if annotations := tools["github__get_issue"].Annotations; annotations == nil || !annotations.ReadOnlyHint || annotations.Title != "Get issue" {
+		t.Errorf("Expected upstream annotations to be passed through, got %+v", annotations)
+	}
+	annotations := tools["close_stale"].Annotations
+	if annotations == nil || !annotations.IdempotentHint || annotations.DestructiveHint == nil || *annotations.DestructiveHint {
+		t.Errorf("Expected the saved tool's annotations, got %+v", annotations)
+	}
+}
+
+func TestRegisterProxiedToolsReadOnly(t *testing.T) {
+	ConfigureReadOnly(true)
+	defer ConfigureReadOnly(false)
+
+	mockProxy := NewMockProxyManager()
+	mockProxy.AddMockTool("github", &mcp.Tool{Name: "get_issue", InputSchema: &jsonschema.Schema{Type: "object"}, Annotations: &mcp.ToolAnnotations{ReadOnlyHint: true}})
+	mockProxy.AddMockTool("github", &mcp.Tool{Name: "create_issue", InputSchema: &jsonschema.Schema{Type: "object"}})
+	cfg := &config.Config{MCPServers: map[string]config.MCPServerConfig{"github": {Command: "echo"}}}
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	if err := RegisterProxiedTools(server, mockProxy, cfg); err != nil {
+		t.Fatalf("RegisterProxiedTools() error = %v", err)
+	}
+
+	tools := listServerTools(t, server)
+	if _, ok := tools["github__get_issue"]; !ok {
+		t.Error("Expected the read-only tool to be registered")
+	}
+	if _, ok := tools["github__create_issue"]; ok {
+		t.Error("Expected the tool not marked read-only to be skipped")
+	}
+}
@@ -0,0 +1,58 @@
+package tools
+
+import (
+	"context"
+	"runtime"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/queue"
+)
+
+// defaultMaxPending is the number of executions allowed to wait when the config doesn't say
+const defaultMaxPending = 100
+
+// executionQueue schedules Starlark executions between clients; nil runs them immediately
+var executionQueue *queue.Queue
+
+// ConfigureQueue enables the shared execution queue; nil disables it
+func ConfigureQueue(cfg *config.QueueConfig) {
+	if cfg == nil {
+		executionQueue = nil
+		return
+	}
+
+	workers := cfg.Workers
+	if workers == 0 {
+		workers = runtime.NumCPU()
+	}
+	maxPending := cfg.MaxPending
+	if maxPending == 0 {
+		maxPending = defaultMaxPending
+	}
+	executionQueue = queue.New(workers, maxPending)
+}
+
+// runQueued runs a tool handler through the execution queue, if one is configured,
+// treating each MCP session as a separate client
+func runQueued(ctx context.Context, req *mcp.CallToolRequest, priority queue.Priority, handler func() (*mcp.CallToolResult, any, error)) (*mcp.CallToolResult, any, error) {
+	if executionQueue == nil {
+		return handler()
+	}
+
+	client := ""
+	if req != nil && req.Session != nil {
+		client = req.Session.ID()
+	}
+
+	var result *mcp.CallToolResult
+	var structured any
+	var err error
+	if queueErr := executionQueue.Do(ctx, client, priority, func() {
+		result, structured, err = handler()
+	}); queueErr != nil {
+		return ErrorResponse("Server busy, try again later: %v", queueErr), nil, nil
+	}
+	return result, structured, err
+}
@@ -0,0 +1,70 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/queue"
+)
+
+func TestRunQueued(t *testing.T) {
+	tests := []struct {
+		name   string
+		config *config.QueueConfig
+	}{
+		{"without queue", nil},
+		{"with queue", &config.QueueConfig{Workers: 2}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ConfigureQueue(tt.config)
+			defer ConfigureQueue(nil)
+
+			result, structured, err := runQueued(context.Background(), &mcp.CallToolRequest{}, queue.PriorityInteractive, func() (*mcp.CallToolResult, any, error) {
+				return SuccessResponse("done"), "value", nil
+			})
+			if err != nil {
+				t.Fatalf("runQueued() error = %v", err)
+			}
+			if result.IsError || result.Content[0].(*mcp.TextContent).Text != "done" {
+				t.Errorf("runQueued() result = %v", result.Content[0])
+			}
+			if structured != "value" {
+				t.Errorf("runQueued() structured = %v, want value", structured)
+			}
+		})
+	}
+}
+
+func TestRunQueuedCancelled(t *testing.T) {
+	ConfigureQueue(&config.QueueConfig{Workers: 1})
+	defer ConfigureQueue(nil)
+
+	// Hold the only worker while a cancelled request waits
+	block := make(chan struct{})
+	started := make(chan struct{})
+	go runQueued(context.Background(), nil, queue.PriorityBatch, func() (*mcp.CallToolResult, any, error) {
+		close(started)
+		<-block
+		return nil, nil, nil
+	})
+	<-started
+	defer close(block)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	result, _, err := runQueued(ctx, nil, queue.PriorityInteractive, func() (*mcp.CallToolResult, any, error) {
+		t.Error("handler should not run for a cancelled request")
+		return nil, nil, nil
+	})
+	if err != nil {
+		t.Fatalf("runQueued() error = %v", err)
+	}
+	if !result.IsError {
+		t.Error("runQueued() should return an error response when the request can't be scheduled")
+	}
+}
@@ -0,0 +1,48 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/proxy"
+)
+
+// ConfigReloader re-reads servers.json and applies it to the running server
+type ConfigReloader interface {
+	Reload() (*proxy.ReloadSummary, error)
+}
+
+// ReloadConfigResponse reports how a reload changed the upstream servers
+type ReloadConfigResponse struct {
+	Changed bool `json:"changed"`
+	*proxy.ReloadSummary
+}
+
+// RegisterReloadConfig registers the reload_config tool with the MCP server
+// reloader may be nil if no valid config was loaded at startup
+func RegisterReloadConfig(server *mcp.Server, reloader ConfigReloader) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:        "reload_config",
+		Description: "Re-read servers.json and reconcile upstream servers and proxied tools, returning what changed",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		return handleReloadConfig(reloader)
+	})
+}
+
+func handleReloadConfig(reloader ConfigReloader) (*mcp.CallToolResult, any, error) {
+	if reloader == nil {
+		return ErrorResponse("Error: reloading requires a valid servers.json at startup; restart the metatool instead"), nil, nil
+	}
+
+	summary, err := reloader.Reload()
+	if err != nil {
+		if summary != nil {
+			return ErrorResponse("Reload partially applied (%s): %v", summary, err), nil, nil
+		}
+		return ErrorResponse("Failed to reload config: %v", err), nil, nil
+	}
+
+	response := ReloadConfigResponse{Changed: summary.Changed(), ReloadSummary: summary}
+	return SuccessResponse("Reloaded config: %s", summary), response, nil
+}
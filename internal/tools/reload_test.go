@@ -0,0 +1,83 @@
+package tools
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/proxy"
+)
+
+// fakeReloader returns a canned reload outcome
+type fakeReloader struct {
+	summary *proxy.ReloadSummary
+	err     error
+	calls   int
+}
+
+func (r *fakeReloader) Reload() (*proxy.ReloadSummary, error) {
+	r.calls++
+	return r.summary, r.err
+}
+
+func TestHandleReloadConfig(t *testing.T) {
+	tests := []struct {
+		name     string
+		reloader ConfigReloader
+		wantErr  bool
+		wantText string
+		changed  bool
+	}{
+		{
+			name:     "changes applied",
+			reloader: &fakeReloader{summary: &proxy.ReloadSummary{Added: []string{"jira"}, Removed: []string{"old"}}},
+			wantText: "added: jira; removed: old",
+			changed:  true,
+		},
+		{
+			name:     "nothing changed",
+			reloader: &fakeReloader{summary: &proxy.ReloadSummary{}},
+			wantText: "no server changes",
+		},
+		{
+			name:     "invalid config",
+			reloader: &fakeReloader{err: errors.New("invalid config: no MCP servers configured")},
+			wantErr:  true,
+			wantText: "Failed to reload config: invalid config",
+		},
+		{
+			name:     "partially applied",
+			reloader: &fakeReloader{summary: &proxy.ReloadSummary{Added: []string{"jira"}}, err: errors.New("registration failed")},
+			wantErr:  true,
+			wantText: "partially applied (added: jira)",
+		},
+		{
+			name:     "unavailable",
+			wantErr:  true,
+			wantText: "requires a valid servers.json",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, structured, err := handleReloadConfig(tt.reloader)
+			if err != nil {
+				t.Fatalf("handleReloadConfig() error = %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Errorf("Expected IsError = %v, got %v", tt.wantErr, result.IsError)
+			}
+			verifyTextContent(t, result, tt.wantText)
+
+			if tt.wantErr {
+				return
+			}
+			response, ok := structured.(ReloadConfigResponse)
+			if !ok {
+				t.Fatalf("Expected ReloadConfigResponse, got %T", structured)
+			}
+			if response.Changed != tt.changed {
+				t.Errorf("Expected Changed = %v, got %v", tt.changed, response.Changed)
+			}
+		})
+	}
+}
@@ -0,0 +1,63 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+// RegisterRenameSavedTool registers the rename_saved_tool tool with the MCP server. The renamed tool
+// replaces the old one in the tool list straight away, running with proxyManager and opts.
+func RegisterRenameSavedTool(server *mcp.Server, proxyManager ProxyManager, opts ...starlark.Option) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "rename_saved_tool",
+		Description:  "Rename a saved tool, keeping its definition, creation time and author",
+		OutputSchema: outputSchema[persistence.SavedToolDefinition](),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.RenameToolArgs) (*mcp.CallToolResult, any, error) {
+		return handleRenameSavedTool(server, args, proxyManager, opts...)
+	})
+}
+
+// RegisterDuplicateSavedTool registers the duplicate_saved_tool tool with the MCP server
+func RegisterDuplicateSavedTool(server *mcp.Server, proxyManager ProxyManager, opts ...starlark.Option) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "duplicate_saved_tool",
+		Description:  "Copy a saved tool to a new name, as a starting point for a variant of it",
+		OutputSchema: outputSchema[persistence.SavedToolDefinition](),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.RenameToolArgs) (*mcp.CallToolResult, any, error) {
+		return handleDuplicateSavedTool(server, args, proxyManager, opts...)
+	})
+}
+
+func handleRenameSavedTool(server *mcp.Server, args types.RenameToolArgs, proxyManager ProxyManager, opts ...starlark.Option) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" || args.NewName == "" {
+		return ErrorResponse("Error: name and newName are required"), nil, nil
+	}
+
+	tool, err := persistence.RenameTool(args.Name, args.NewName)
+	if err != nil {
+		return ErrorResponse("Failed to rename tool '%s': %v", args.Name, err), nil, nil
+	}
+	server.RemoveTools(args.Name)
+	registerSavedTool(server, tool, proxyManager, opts...)
+
+	return SuccessResponse("Tool '%s' renamed to '%s'. Use restore_saved_tool on '%s' to undo.", args.Name, args.NewName, args.Name), tool, nil
+}
+
+func handleDuplicateSavedTool(server *mcp.Server, args types.RenameToolArgs, proxyManager ProxyManager, opts ...starlark.Option) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" || args.NewName == "" {
+		return ErrorResponse("Error: name and newName are required"), nil, nil
+	}
+
+	tool, err := persistence.DuplicateTool(args.Name, args.NewName)
+	if err != nil {
+		return ErrorResponse("Failed to duplicate tool '%s': %v", args.Name, err), nil, nil
+	}
+	registerSavedTool(server, tool, proxyManager, opts...)
+
+	return SuccessResponse("Tool '%s' copied to '%s'", args.Name, args.NewName), tool, nil
+}
@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+func TestRenameAndDuplicateReregister(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	ctx := context.Background()
+	createTestTool(t, "greet", "Greet someone", `"hello"`)
+
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	if err := RegisterSavedTools(server, nil); err != nil {
+		t.Fatal(err)
+	}
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.Connect(ctx, serverTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer serverSession.Close()
+	clientSession, err := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil).Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer clientSession.Close()
+	listed := func() []string {
+		result, err := clientSession.ListTools(ctx, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var names []string
+		for _, tool := range result.Tools {
+			names = append(names, tool.Name)
+		}
+		slices.Sort(names)
+		return names
+	}
+
+	tests := []struct {
+		name      string
+		handler   func(*mcp.Server, types.RenameToolArgs, ProxyManager, ...starlark.Option) (*mcp.CallToolResult, any, error)
+		args      types.RenameToolArgs
+		wantTools []string
+		wantErr   bool
+	}{
+		{"duplicate", handleDuplicateSavedTool, types.RenameToolArgs{Name: "greet", NewName: "greet_loudly"}, []string{"greet", "greet_loudly"}, false},
+		{"rename", handleRenameSavedTool, types.RenameToolArgs{Name: "greet", NewName: "welcome"}, []string{"greet_loudly", "welcome"}, false},
+		{"rename onto existing tool", handleRenameSavedTool, types.RenameToolArgs{Name: "welcome", NewName: "greet_loudly"}, []string{"greet_loudly", "welcome"}, true},
+		{"missing new name", handleDuplicateSavedTool, types.RenameToolArgs{Name: "welcome"}, []string{"greet_loudly", "welcome"}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, _ := tt.handler(server, tt.args, nil)
+			if result.IsError != tt.wantErr {
+				t.Fatalf("IsError = %v, want %v: %s", result.IsError, tt.wantErr, result.Content[0].(*mcp.TextContent).Text)
+			}
+			if names := listed(); !slices.Equal(names, tt.wantTools) {
+				t.Errorf("Listed tools = %v, want %v", names, tt.wantTools)
+			}
+		})
+	}
+
+	// The renamed tool runs its original code
+	result, err := clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "welcome", Arguments: map[string]any{}})
+	if err != nil || result.IsError {
+		t.Fatalf("CallTool(welcome) = %+v, %v", result, err)
+	}
+	if tool, err := persistence.LoadTool("welcome"); err != nil || tool.Code != `"hello"` {
+		t.Errorf("LoadTool(welcome) = %+v, %v", tool, err)
+	}
+}
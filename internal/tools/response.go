@@ -1,18 +1,24 @@
 package tools
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/redact"
 )
 
-// ErrorResponse creates a standardized error response for tool calls
+// ErrorResponse creates a standardized error response for tool calls, flagged as an error
+// so clients can tell it apart from a successful result without parsing the text.
+// Secrets and expanded environment variables in the message are masked.
 func ErrorResponse(format string, args ...interface{}) *mcp.CallToolResult {
-	message := fmt.Sprintf(format, args...)
+	message := redact.String(fmt.Sprintf(format, args...))
 	return &mcp.CallToolResult{
 		Content: []mcp.Content{
 			&mcp.TextContent{Text: message},
 		},
+		IsError: true,
 	}
 }
 
@@ -24,4 +30,20 @@ func SuccessResponse(format string, args ...interface{}) *mcp.CallToolResult {
 			&mcp.TextContent{Text: message},
 		},
 	}
-}
\ No newline at end of file
+}
+
+// JSONResponse creates a success response whose text content is the JSON encoding of value
+// and whose structured content is set to the given envelope
+func JSONResponse(value interface{}, structured interface{}) *mcp.CallToolResult {
+	text, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		// Fall back to Go formatting for values JSON can't represent
+		text = []byte(fmt.Sprintf("%v", value))
+	}
+	return &mcp.CallToolResult{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: string(text)},
+		},
+		StructuredContent: structured,
+	}
+}
@@ -2,47 +2,169 @@ package tools
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/tooltest"
 	"github.com/dslh/mcp-metatool/internal/types"
+	"github.com/dslh/mcp-metatool/internal/verify"
 )
 
-// RegisterSaveTool registers the save_tool tool with the MCP server
-func RegisterSaveTool(server *mcp.Server) {
-	mcp.AddTool(server, &mcp.Tool{
+// RegisterSaveTool registers the save_tool tool with the MCP server. Saved tools are added to the
+// tool list straight away, running with proxyManager and opts.
+func RegisterSaveTool(server *mcp.Server, proxyManager ProxyManager, opts ...starlark.Option) {
+	addBuiltinTool(server, &mcp.Tool{
 		Name:        "save_tool",
 		Description: "Create or update a composite tool definition",
-	}, handleSaveTool)
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.SaveToolArgs) (*mcp.CallToolResult, any, error) {
+		return handleSaveTool(server, args, proxyManager, opts...)
+	})
 }
 
-func handleSaveTool(ctx context.Context, req *mcp.CallToolRequest, args types.SaveToolArgs) (*mcp.CallToolResult, any, error) {
-	// Basic validation
-	if args.Name == "" {
-		return ErrorResponse("Error: tool name is required"), nil, nil
+func handleSaveTool(server *mcp.Server, args types.SaveToolArgs, proxyManager ProxyManager, opts ...starlark.Option) (*mcp.CallToolResult, any, error) {
+	// Create tool definition
+	tool := &persistence.SavedToolDefinition{
+		Name:            args.Name,
+		Description:     args.Description,
+		InputSchema:     args.InputSchema,
+		Code:            args.Code,
+		Tests:           args.Tests,
+		Presets:         args.Presets,
+		SessionAffinity: args.SessionAffinity,
+		AllowedServers:  args.AllowedServers,
+		Author:          args.Author,
+		Annotations:     args.Annotations,
 	}
 
-	if args.Description == "" {
-		return ErrorResponse("Error: tool description is required"), nil, nil
+	if err := validateTool(tool); err != nil {
+		return ErrorResponse("Error: %v", err), nil, nil
 	}
 
-	if args.Code == "" {
-		return ErrorResponse("Error: tool code is required"), nil, nil
+	revoked, err := storeTool(server, tool, args.ExpectedVersion, proxyManager, opts...)
+	if err != nil {
+		return saveErrorResponse(err), nil, nil
 	}
 
-	// Create tool definition
-	tool := &persistence.SavedToolDefinition{
-		Name:        args.Name,
-		Description: args.Description,
-		InputSchema: args.InputSchema,
-		Code:        args.Code,
+	message := "Tool '" + args.Name + "' saved successfully" + dependencyWarnings(tool, proxyManager, opts...) + revokedPermissionsNote(revoked)
+	if args.TestParams != nil {
+		message += testRunReport(tool, args.TestParams, args.TestMocks, proxyManager, opts...)
+	}
+	return SuccessResponse("%s", message), tool, nil
+}
+
+// SaveTool validates and saves a tool definition as save_tool does, ignoring any permissions it lists,
+// and adds it to server's tool list if server isn't nil. It returns the permissions that were revoked
+// because the tool's code or allowed servers changed.
+func SaveTool(server *mcp.Server, tool *persistence.SavedToolDefinition, proxyManager ProxyManager, opts ...starlark.Option) ([]string, error) {
+	if err := validateTool(tool); err != nil {
+		return nil, err
+	}
+	return storeTool(server, tool, nil, proxyManager, opts...)
+}
+
+// validateTool checks that a tool definition has the required fields and that its code compiles
+func validateTool(tool *persistence.SavedToolDefinition) error {
+	if tool.Name == "" {
+		return errors.New("tool name is required")
+	}
+	if tool.Description == "" {
+		return errors.New("tool description is required")
+	}
+	if tool.Code == "" {
+		return errors.New("tool code is required")
 	}
+	if err := starlark.CheckSyntax(tool.Code); err != nil {
+		return fmt.Errorf("tool code does not compile: %w", err)
+	}
+	return nil
+}
 
-	// Save to disk
-	if err := persistence.SaveTool(tool); err != nil {
-		return ErrorResponse("Failed to save tool: %v", err), nil, nil
+// storeTool saves a validated tool definition, unless expectedVersion is given and the tool has changed
+// since, and registers it with server if server isn't nil
+func storeTool(server *mcp.Server, tool *persistence.SavedToolDefinition, expectedVersion *int, proxyManager ProxyManager, opts ...starlark.Option) ([]string, error) {
+	// Permissions are granted by the operator, so only survive a save that keeps what they reviewed
+	var revoked []string
+	tool.Permissions, revoked = grantedPermissions(tool)
+
+	var err error
+	if expectedVersion != nil {
+		err = persistence.SaveToolIfUnchanged(tool, *expectedVersion)
+	} else {
+		err = persistence.SaveTool(tool)
+	}
+	if err != nil {
+		return nil, err
 	}
+	if server != nil {
+		registerSavedTool(server, tool, proxyManager, opts...)
+	}
+	return revoked, nil
+}
 
-	return SuccessResponse("Tool '%s' saved successfully", args.Name), tool, nil
-}
\ No newline at end of file
+// grantedPermissions returns the permissions of the saved tool that tool replaces if their grant still
+// applies to it, otherwise the permissions that are revoked
+func grantedPermissions(tool *persistence.SavedToolDefinition) (kept, revoked []string) {
+	existing, err := persistence.LoadTool(tool.Name)
+	if err != nil {
+		return nil, nil
+	}
+	if persistence.GrantApplies(existing, tool) {
+		return existing.Permissions, nil
+	}
+	return nil, existing.Permissions
+}
+
+// revokedPermissionsNote tells the caller which permissions a tool lost when its code changed
+func revokedPermissionsNote(revoked []string) string {
+	if len(revoked) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("\n\nIts permissions (%s) were revoked because its code or allowed servers changed; an operator can grant them again with mcp-metatool grant", strings.Join(revoked, ", "))
+}
+
+// testRunReport runs a just-saved tool once with the given params, describing its result or error
+func testRunReport(tool *persistence.SavedToolDefinition, params, mocks map[string]interface{}, proxyManager ProxyManager, opts ...starlark.Option) string {
+	test := persistence.ToolTest{Name: "testParams", Params: params, Mocks: mocks}
+	caseResult := tooltest.RunCase(tool, test, proxyManager, opts...)
+	if caseResult.Error != "" {
+		return "\n\nTest run failed: " + caseResult.Error
+	}
+	result, err := json.MarshalIndent(caseResult.Actual, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("\n\nTest run succeeded, but its result could not be shown: %v", err)
+	}
+	return "\n\nTest run result:\n" + string(result)
+}
+
+// dependencyWarnings lists the servers and tools a saved tool calls that aren't currently available,
+// starting with a newline, or is empty if there are none. They are warnings, as a server may be configured later.
+func dependencyWarnings(tool *persistence.SavedToolDefinition, proxyManager ProxyManager, opts ...starlark.Option) string {
+	report := verify.Tools([]*persistence.SavedToolDefinition{tool}, proxyManager, opts...)
+	var b strings.Builder
+	for _, problem := range report.Problems {
+		location := problem.Tool
+		if problem.Line > 0 {
+			location = fmt.Sprintf("%s:%d", problem.Tool, problem.Line)
+		}
+		fmt.Fprintf(&b, "\n  ⚠ %s: %s", location, problem.Message)
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return ", with warnings:" + b.String()
+}
+
+// saveErrorResponse reports a failed save, suggesting how to recover from a version conflict
+func saveErrorResponse(err error) *mcp.CallToolResult {
+	var conflict *persistence.VersionConflictError
+	if errors.As(err, &conflict) {
+		return ErrorResponse("Failed to save tool: %v. Use show_saved_tool to read the latest definition and apply your changes to it.", err)
+	}
+	return ErrorResponse("Failed to save tool: %v", err)
+}
@@ -1,7 +1,6 @@
 package tools
 
 import (
-	"context"
 	"os"
 	"path/filepath"
 	"strings"
@@ -31,7 +30,7 @@ func TestHandleSaveTool(t *testing.T) {
 				Name:        "test_tool",
 				Description: "A simple test tool",
 				InputSchema: map[string]interface{}{"type": "object"},
-				Code:        "result = 'hello world'",
+				Code:        "'hello world'",
 			},
 			true,
 			"",
@@ -68,7 +67,7 @@ result = [message] * count`,
 			types.SaveToolArgs{
 				Name:        "",
 				Description: "Tool with empty name",
-				Code:        "result = 'test'",
+				Code:        "'test'",
 			},
 			false,
 			"tool name is required",
@@ -78,7 +77,7 @@ result = [message] * count`,
 			types.SaveToolArgs{
 				Name:        "no_description_tool",
 				Description: "",
-				Code:        "result = 'test'",
+				Code:        "'test'",
 			},
 			false,
 			"tool description is required",
@@ -98,7 +97,7 @@ result = [message] * count`,
 			types.SaveToolArgs{
 				Name:        "invalid/name",
 				Description: "Tool with invalid name",
-				Code:        "result = 'test'",
+				Code:        "'test'",
 			},
 			false,
 			"invalid character",
@@ -108,7 +107,7 @@ result = [message] * count`,
 			types.SaveToolArgs{
 				Name:        strings.Repeat("a", 101),
 				Description: "Tool with overly long name",
-				Code:        "result = 'test'",
+				Code:        "'test'",
 			},
 			false,
 			"too long",
@@ -119,7 +118,7 @@ result = [message] * count`,
 				Name:        "minimal_tool",
 				Description: "Tool with minimal input schema",
 				InputSchema: map[string]interface{}{},
-				Code:        "result = 42",
+				Code:        "42",
 			},
 			true,
 			"",
@@ -130,19 +129,37 @@ result = [message] * count`,
 				Name:        "nil_schema_tool",
 				Description: "Tool with nil input schema",
 				InputSchema: nil,
-				Code:        "result = 'works'",
+				Code:        "'works'",
 			},
 			true,
 			"",
 		},
+		{
+			"code with syntax error",
+			types.SaveToolArgs{
+				Name:        "broken_tool",
+				Description: "Tool whose code doesn't parse",
+				Code:        "def main(:\n    return 1",
+			},
+			false,
+			"does not compile: <eval>:1:11",
+		},
+		{
+			"single line statement",
+			types.SaveToolArgs{
+				Name:        "statement_tool",
+				Description: "Tool whose single line is a statement, not an expression",
+				Code:        "result = 'test'",
+			},
+			false,
+			"does not compile",
+		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ctx := context.Background()
-			req := &mcp.CallToolRequest{}
 
-			result, returnValue, err := handleSaveTool(ctx, req, tt.args)
+			result, returnValue, err := handleSaveTool(nil, tt.args, nil)
 
 			// Check for framework errors
 			if err != nil {
@@ -167,6 +184,10 @@ result = [message] * count`,
 				return
 			}
 
+			if result.IsError == tt.wantSuccess {
+				t.Errorf("handleSaveTool() IsError = %v, want %v", result.IsError, !tt.wantSuccess)
+			}
+
 			if tt.wantSuccess {
 				// Should contain success message
 				expectedMsg := "saved successfully"
@@ -242,17 +263,14 @@ func TestHandleSaveToolOverwrite(t *testing.T) {
 	os.Setenv("MCP_METATOOL_DIR", tempDir)
 	defer os.Unsetenv("MCP_METATOOL_DIR")
 
-	ctx := context.Background()
-	req := &mcp.CallToolRequest{}
-
 	// Save initial tool
 	initialArgs := types.SaveToolArgs{
 		Name:        "overwrite_test",
 		Description: "Initial version",
-		Code:        "result = 'version 1'",
+		Code:        "'version 1'",
 	}
 
-	result1, _, err1 := handleSaveTool(ctx, req, initialArgs)
+	result1, _, err1 := handleSaveTool(nil, initialArgs, nil)
 	if err1 != nil {
 		t.Fatalf("Initial save failed: %v", err1)
 	}
@@ -266,11 +284,11 @@ func TestHandleSaveToolOverwrite(t *testing.T) {
 	updatedArgs := types.SaveToolArgs{
 		Name:        "overwrite_test",
 		Description: "Updated version",
-		Code:        "result = 'version 2'",
+		Code:        "'version 2'",
 		InputSchema: map[string]interface{}{"type": "object", "properties": map[string]interface{}{}},
 	}
 
-	result2, returnValue2, err2 := handleSaveTool(ctx, req, updatedArgs)
+	result2, returnValue2, err2 := handleSaveTool(nil, updatedArgs, nil)
 	if err2 != nil {
 		t.Fatalf("Overwrite save failed: %v", err2)
 	}
@@ -299,6 +317,149 @@ func TestHandleSaveToolOverwrite(t *testing.T) {
 	}
 }
 
+func TestHandleSaveToolExpectedVersion(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	tests := []struct {
+		name     string
+		expected int
+		wantText string
+		wantErr  bool
+	}{
+		{"new tool", 0, "saved successfully", false},
+		{"second agent also creating it", 0, "already exists", true},
+		{"first agent editing version 1", 1, "saved successfully", false},
+		{"second agent editing stale version 1", 1, "has changed since version 1 was read; it is now version 2", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := types.SaveToolArgs{Name: "shared", Description: tt.name, Code: "1", ExpectedVersion: intPtr(tt.expected)}
+			result, _, _ := handleSaveTool(nil, args, nil)
+			text := result.Content[0].(*mcp.TextContent).Text
+			if result.IsError != tt.wantErr || !strings.Contains(text, tt.wantText) {
+				t.Errorf("save_tool = %q (IsError %v), want %q (IsError %v)", text, result.IsError, tt.wantText, tt.wantErr)
+			}
+		})
+	}
+
+	if tool, _ := persistence.LoadTool("shared"); tool.Description != "first agent editing version 1" {
+		t.Errorf("Description = %q, want the first agent's edit kept", tool.Description)
+	}
+}
+
+func TestHandleSaveToolPermissions(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	if err := persistence.SaveTool(&persistence.SavedToolDefinition{Name: "alert", Description: "Alert", Code: "1", Permissions: []string{"notify"}}); err != nil {
+		t.Fatalf("SaveTool() error = %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		code            string
+		wantPermissions int
+		wantText        string
+	}{
+		{"same code keeps granted permissions", "1", 1, "saved successfully"},
+		{"new code revokes them", "2", 0, "permissions (notify) were revoked"},
+		{"restoring the code doesn't grant them again", "1", 0, "saved successfully"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, _ := handleSaveTool(nil, types.SaveToolArgs{Name: "alert", Description: "Alert", Code: tt.code}, nil)
+			if text := result.Content[0].(*mcp.TextContent).Text; result.IsError || !strings.Contains(text, tt.wantText) {
+				t.Errorf("save_tool = %q, want %q", text, tt.wantText)
+			}
+			if tool, _ := persistence.LoadTool("alert"); len(tool.Permissions) != tt.wantPermissions {
+				t.Errorf("Permissions = %v, want %d", tool.Permissions, tt.wantPermissions)
+			}
+		})
+	}
+
+	// Agents can't grant permissions through the tool's arguments
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	RegisterSaveTool(server, nil)
+	if _, ok := listServerTools(t, server)["save_tool"].InputSchema.Properties["permissions"]; ok {
+		t.Error("Expected save_tool not to accept permissions")
+	}
+}
+
+func TestHandleSaveToolDependencyWarnings(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	proxyManager := NewMockProxyManager()
+	proxyManager.AddMockTool("github", &mcp.Tool{Name: "get_issue"})
+
+	tests := []struct {
+		name     string
+		code     string
+		warnings []string
+	}{
+		{"available tool", `github.get_issue(number=1)`, nil},
+		{"missing tool", "issue = github.fetch_issue(number=1)\nslack.post(text=issue)", []string{
+			"with warnings:",
+			"⚠ checked:1: calls github.fetch_issue, but server github has no tool named fetch_issue",
+			"⚠ checked:2: calls slack.post, but no server named slack is configured",
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := types.SaveToolArgs{Name: "checked", Description: tt.name, Code: tt.code}
+			result, _, _ := handleSaveTool(nil, args, proxyManager)
+			text := result.Content[0].(*mcp.TextContent).Text
+			if result.IsError {
+				t.Fatalf("save_tool failed: %s", text)
+			}
+			if tt.warnings == nil && strings.Contains(text, "warning") {
+				t.Errorf("Expected no warnings, got %q", text)
+			}
+			for _, warning := range tt.warnings {
+				if !strings.Contains(text, warning) {
+					t.Errorf("Expected %q in %q", warning, text)
+				}
+			}
+		})
+	}
+}
+
+func TestHandleSaveToolTestParams(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	tests := []struct {
+		name     string
+		code     string
+		params   map[string]interface{}
+		mocks    map[string]interface{}
+		wantText string
+	}{
+		{"result", `params["n"] * 2`, map[string]interface{}{"n": 2}, nil, "Test run result:\n4"},
+		{"mocked call", `github.get_user({"login": params["login"]})["structured"]["public_repos"]`,
+			map[string]interface{}{"login": "alice"},
+			map[string]interface{}{"github.get_user": map[string]interface{}{"public_repos": 3}},
+			"Test run result:\n3"},
+		{"runtime error", `params["missing"]`, map[string]interface{}{}, nil, "Test run failed: Evaluation error: key \"missing\" not in dict"},
+		{"no test run", `params["n"]`, nil, nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args := types.SaveToolArgs{Name: "tried", Description: tt.name, Code: tt.code, TestParams: tt.params, TestMocks: tt.mocks}
+			result, _, _ := handleSaveTool(nil, args, nil)
+			text := result.Content[0].(*mcp.TextContent).Text
+			if result.IsError {
+				t.Fatalf("save_tool failed: %s", text)
+			}
+			if tt.wantText == "" {
+				if strings.Contains(text, "Test run") {
+					t.Errorf("Expected no test run, got %q", text)
+				}
+			} else if !strings.Contains(text, tt.wantText) {
+				t.Errorf("Expected %q in %q", tt.wantText, text)
+			}
+			if _, err := persistence.LoadTool("tried"); err != nil {
+				t.Errorf("Expected the tool to be saved whatever the test run's outcome: %v", err)
+			}
+		})
+	}
+}
+
 func TestRegisterSaveTool(t *testing.T) {
 	// Create a mock server
 	server := mcp.NewServer(&mcp.Implementation{
@@ -307,7 +468,7 @@ func TestRegisterSaveTool(t *testing.T) {
 	}, nil)
 
 	// Register the tool
-	RegisterSaveTool(server)
+	RegisterSaveTool(server, nil)
 
 	// Verify the registration doesn't panic
 	t.Log("RegisterSaveTool completed without panic")
@@ -329,7 +490,7 @@ func TestSaveToolArgsValidation(t *testing.T) {
 			types.SaveToolArgs{
 				Name:        "valid_tool",
 				Description: "Valid tool description",
-				Code:        "result = 'valid'",
+				Code:        "'valid'",
 				InputSchema: map[string]interface{}{"type": "object"},
 			},
 			false,
@@ -338,7 +499,7 @@ func TestSaveToolArgsValidation(t *testing.T) {
 			"missing name",
 			types.SaveToolArgs{
 				Description: "Tool without name",
-				Code:        "result = 'test'",
+				Code:        "'test'",
 			},
 			true,
 		},
@@ -346,7 +507,7 @@ func TestSaveToolArgsValidation(t *testing.T) {
 			"missing description",
 			types.SaveToolArgs{
 				Name: "tool_no_desc",
-				Code: "result = 'test'",
+				Code: "'test'",
 			},
 			true,
 		},
@@ -363,7 +524,7 @@ func TestSaveToolArgsValidation(t *testing.T) {
 			types.SaveToolArgs{
 				Name:        "   ",
 				Description: "Tool with whitespace name",
-				Code:        "result = 'test'",
+				Code:        "'test'",
 			},
 			true,
 		},
@@ -371,10 +532,8 @@ func TestSaveToolArgsValidation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			ctx := context.Background()
-			req := &mcp.CallToolRequest{}
 
-			result, _, err := handleSaveTool(ctx, req, tt.args)
+			result, _, err := handleSaveTool(nil, tt.args, nil)
 
 			if err != nil {
 				t.Errorf("handleSaveTool() framework error = %v", err)
@@ -382,7 +541,7 @@ func TestSaveToolArgsValidation(t *testing.T) {
 			}
 
 			textContent := result.Content[0].(*mcp.TextContent)
-			containsError := strings.Contains(textContent.Text, "Error:") || strings.Contains(textContent.Text, "Failed")
+			containsError := result.IsError
 
 			if tt.wantError && !containsError {
 				t.Errorf("handleSaveTool() expected error, got: %s", textContent.Text)
@@ -400,9 +559,6 @@ func TestSaveToolIntegration(t *testing.T) {
 	os.Setenv("MCP_METATOOL_DIR", tempDir)
 	defer os.Unsetenv("MCP_METATOOL_DIR")
 
-	ctx := context.Background()
-	req := &mcp.CallToolRequest{}
-
 	// Test complete workflow: save -> verify -> list -> load
 	toolArgs := types.SaveToolArgs{
 		Name:        "integration_test_tool",
@@ -416,11 +572,11 @@ func TestSaveToolIntegration(t *testing.T) {
 			},
 		},
 		Code: `input_val = params.get("input", "default")
-result = f"Processed: {input_val}"`,
+result = "Processed: " + input_val`,
 	}
 
 	// 1. Save the tool
-	result, returnValue, err := handleSaveTool(ctx, req, toolArgs)
+	result, returnValue, err := handleSaveTool(nil, toolArgs, nil)
 	if err != nil {
 		t.Fatalf("Save failed: %v", err)
 	}
@@ -3,44 +3,88 @@ package tools
 import (
 	"context"
 	"fmt"
-	"log"
+	"strings"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 
+	"github.com/dslh/mcp-metatool/internal/history"
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/metrics"
 	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/queue"
+	"github.com/dslh/mcp-metatool/internal/results"
 	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/tracing"
 	"github.com/dslh/mcp-metatool/internal/types"
+	"github.com/dslh/mcp-metatool/internal/usage"
 	"github.com/dslh/mcp-metatool/internal/validation"
 )
 
 // RegisterSavedTools loads all saved tools and registers them as MCP tools
 // The proxyManager parameter is optional; pass nil to register without proxy support
-func RegisterSavedTools(server *mcp.Server, proxyManager ProxyManager) error {
+func RegisterSavedTools(server *mcp.Server, proxyManager ProxyManager, opts ...starlark.Option) error {
 	savedTools, err := persistence.ListTools()
 	if err != nil {
 		return fmt.Errorf("failed to list saved tools: %w", err)
 	}
 
 	for _, tool := range savedTools {
-		// Create a closure to capture the tool definition and proxy manager
-		toolDef := tool
-		capturedProxy := proxyManager
-		mcp.AddTool(server, &mcp.Tool{
-			Name:        toolDef.Name,
-			Description: toolDef.Description,
-		}, func(ctx context.Context, req *mcp.CallToolRequest, args types.SavedToolParams) (*mcp.CallToolResult, any, error) {
-			return handleSavedTool(toolDef, args, capturedProxy)
-		})
-		log.Printf("Registered saved tool: %s", tool.Name)
+		registerSavedTool(server, tool, proxyManager, opts...)
 	}
 
 	return nil
 }
 
+// registerSavedTool registers one saved tool as an MCP tool, replacing any tool of the same name
+func registerSavedTool(server *mcp.Server, tool *persistence.SavedToolDefinition, proxyManager ProxyManager, opts ...starlark.Option) {
+	mcpTool := &mcp.Tool{
+		Name:        tool.Name,
+		Description: tool.Description,
+		Annotations: savedToolAnnotations(tool.Annotations),
+	}
+	if len(tool.Presets) > 0 {
+		mcpTool.Description += fmt.Sprintf(" (presets: %s)", strings.Join(tool.PresetNames(), ", "))
+	}
+	applyToolLimits[types.SavedToolParams](mcpTool)
+	mcp.AddTool(server, mcpTool, func(ctx context.Context, req *mcp.CallToolRequest, args types.SavedToolParams) (*mcp.CallToolResult, any, error) {
+		ctx, span := tracing.Start(ctx, "saved_tool", attribute.String("metatool.tool", tool.Name))
+		result, structured, err := runQueued(ctx, req, queue.PriorityBatch, func() (*mcp.CallToolResult, any, error) {
+			return handleSavedTool(tool, args, proxyManager, append([]starlark.Option{starlark.WithContext(ctx), progressOption(ctx, req)}, opts...)...)
+		})
+		endToolSpan(span, result, err)
+		return result, structured, err
+	})
+	logging.Debugf("Registered saved tool: %s", tool.Name)
+}
+
+// savedToolAnnotations converts a saved tool's annotations to those advertised over MCP
+func savedToolAnnotations(annotations *persistence.ToolAnnotations) *mcp.ToolAnnotations {
+	if annotations == nil {
+		return nil
+	}
+	return &mcp.ToolAnnotations{
+		Title:           annotations.Title,
+		ReadOnlyHint:    annotations.ReadOnlyHint,
+		DestructiveHint: annotations.DestructiveHint,
+		IdempotentHint:  annotations.IdempotentHint,
+		OpenWorldHint:   annotations.OpenWorldHint,
+	}
+}
+
 // handleSavedTool executes a saved tool with optional proxy manager support
-func handleSavedTool(tool *persistence.SavedToolDefinition, args types.SavedToolParams, proxyManager ProxyManager) (*mcp.CallToolResult, any, error) {
+func handleSavedTool(tool *persistence.SavedToolDefinition, args types.SavedToolParams, proxyManager ProxyManager, opts ...starlark.Option) (*mcp.CallToolResult, any, error) {
+	// Fill in values from the selected preset, if any
+	params, err := tool.ApplyPreset(args)
+	if err != nil {
+		return ErrorResponse("Error: %v", err), nil, nil
+	}
+
 	// Validate parameters against the tool's input schema
-	if err := validation.ValidateParams(tool.InputSchema, map[string]interface{}(args)); err != nil {
+	if err := validation.ValidateParams(tool.InputSchema, params); err != nil {
 		return ErrorResponse(validation.FormatValidationError(err)), nil, nil
 	}
 
@@ -48,18 +92,56 @@ func handleSavedTool(tool *persistence.SavedToolDefinition, args types.SavedTool
 	var starlarkProxy starlark.ProxyManager
 	if proxyManager != nil {
 		starlarkProxy = proxyManager
+		if tool.SessionAffinity {
+			starlarkProxy = proxy.WithAffinity(proxyManager)
+		}
 	}
 
 	// Execute the tool's Starlark code with the provided arguments and proxy manager
-	result, err := starlark.ExecuteWithProxy(tool.Code, args, starlarkProxy)
+	logging.Debugf("Running saved tool %s (version %d)", tool.Name, tool.Version)
+	start := time.Now()
+	opts = append([]starlark.Option{starlark.WithCompiledCache(), starlark.WithPermissions(tool.Permissions...), starlark.WithAllowedServers(tool.AllowedServers...), starlark.WithFileName(tool.Name)}, opts...)
+	result, err := starlark.ExecuteWithProxy(tool.Code, params, starlarkProxy, opts...)
+	observeExecution(tool.Name, start, result, err)
+	usage.RecordSaved(tool.Name, time.Since(start), err != nil || result.Error != "")
+	history.RecordSaved(tool.Name, params, time.Since(start), executionFailure(result, err))
+	logging.Debugf("Saved tool %s finished in %v", tool.Name, time.Since(start).Round(time.Millisecond))
 	if err != nil {
 		return ErrorResponse("Tool execution failed: %v", err), nil, nil
 	}
 
 	// Handle execution errors
 	if result.Error != "" {
-		return ErrorResponse("Tool error: %s", result.Error), nil, nil
+		return ErrorResponse("Tool error in %s: %s", tool.Name, result.Error), nil, nil
+	}
+	if err := results.Record(tool.Name, params, result.Result); err != nil {
+		logging.Warnf("Failed to record result of %s: %v", tool.Name, err)
+	}
+
+	response := withTruncationMeta(SuccessResponse("Result: %v", result.Result), result.Truncated)
+	return withArtifactLinks(response, result.Artifacts), result, nil
+}
+
+// endToolSpan finishes the span of a tool call, marking it as failed if the tool returned an error
+func endToolSpan(span trace.Span, result *mcp.CallToolResult, err error) {
+	if err == nil && result != nil && result.IsError {
+		tracing.Fail(span, "tool returned an error")
 	}
+	tracing.End(span, err)
+}
+
+// executionFailure describes why a Starlark execution failed, or is empty if it succeeded
+func executionFailure(result *starlark.Result, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	return result.Error
+}
 
-	return SuccessResponse("Result: %v", result.Result), result, nil
+// observeExecution records the duration and outcome of a Starlark execution in the metrics
+func observeExecution(tool string, start time.Time, result *starlark.Result, err error) {
+	metrics.StarlarkDuration.Observe(time.Since(start).Seconds(), tool)
+	if err != nil || result.Error != "" {
+		metrics.StarlarkErrors.Inc(tool)
+	}
 }
\ No newline at end of file
@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"os"
 	"strings"
 	"testing"
@@ -8,6 +9,8 @@ import (
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/secrets"
+	"github.com/dslh/mcp-metatool/internal/starlark"
 	"github.com/dslh/mcp-metatool/internal/types"
 )
 
@@ -106,7 +109,7 @@ result = x * 2`,
 		{
 			name:     "empty schema allows any parameters",
 			toolName: "flexible_tool",
-			toolCode: `result = "Executed with params: " + str(params)`,
+			toolCode: `"Executed with params: " + str(params)`,
 			schema:   map[string]interface{}{},
 			params:   types.SavedToolParams{"anything": "goes", "number": 42},
 			expectError: false,
@@ -115,7 +118,7 @@ result = x * 2`,
 		{
 			name:     "nil schema allows any parameters",
 			toolName: "nil_schema_tool",
-			toolCode: `result = "No schema validation"`,
+			toolCode: `"No schema validation"`,
 			schema:   nil,
 			params:   types.SavedToolParams{"whatever": "works"},
 			expectError: false,
@@ -256,6 +259,10 @@ result = "Processed " + str(len(items)) + " items: " + str(items)`,
 					return
 				}
 				
+				if !result.IsError {
+					t.Errorf("%s: expected IsError to be set", tt.description)
+				}
+
 				// Check that the error message is in the content
 				textContent, ok := result.Content[0].(*mcp.TextContent)
 				if !ok {
@@ -281,6 +288,9 @@ result = "Processed " + str(len(items)) + " items: " + str(items)`,
 				if len(result.Content) == 0 {
 					t.Errorf("%s: expected content in successful result", tt.description)
 				}
+				if result.IsError {
+					t.Errorf("%s: unexpected IsError on successful result", tt.description)
+				}
 			}
 		})
 	}
@@ -338,4 +348,145 @@ func TestHandleSavedTool_RuntimeErrorsAfterValidation(t *testing.T) {
 	if !strings.Contains(textContent.Text, "Tool execution failed") && !strings.Contains(textContent.Text, "Tool error") {
 		t.Errorf("Expected runtime error message, got: %s", textContent.Text)
 	}
-}
\ No newline at end of file
+}
+func TestHandleSavedTool_Presets(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	tool := &persistence.SavedToolDefinition{
+		Name:        "report",
+		Description: "Build a report",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"since": map[string]interface{}{"type": "string"},
+				"team":  map[string]interface{}{"type": "string"},
+			},
+			"required": []interface{}{"since", "team"},
+		},
+		Code: `params["team"] + " since " + params["since"]`,
+		Presets: map[string]map[string]interface{}{
+			"weekly-report": {"since": "7d", "team": "platform"},
+		},
+	}
+	if err := persistence.SaveTool(tool); err != nil {
+		t.Fatalf("SaveTool() error = %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		params    types.SavedToolParams
+		want      string
+		wantError string
+	}{
+		{"preset values", types.SavedToolParams{"preset": "weekly-report"}, "platform since 7d", ""},
+		{"explicit params override preset", types.SavedToolParams{"preset": "weekly-report", "team": "web"}, "web since 7d", ""},
+		{"unknown preset", types.SavedToolParams{"preset": "monthly"}, "", "unknown preset 'monthly' (available: weekly-report)"},
+		{"non-string preset", types.SavedToolParams{"preset": 3}, "", "preset must be a string"},
+		{"no preset", types.SavedToolParams{"team": "web"}, "", "Parameter validation failed"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := handleSavedTool(tool, tt.params, nil)
+			if err != nil {
+				t.Fatalf("handleSavedTool() framework error = %v", err)
+			}
+
+			text := result.Content[0].(*mcp.TextContent).Text
+			if tt.wantError != "" {
+				if !result.IsError || !strings.Contains(text, tt.wantError) {
+					t.Errorf("handleSavedTool() = %q, want error containing %q", text, tt.wantError)
+				}
+				return
+			}
+			if result.IsError || !strings.Contains(text, tt.want) {
+				t.Errorf("handleSavedTool() = %q, want result %q", text, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyPresetDeclaredParam(t *testing.T) {
+	// Tools that declare their own preset parameter receive it untouched
+	tool := &persistence.SavedToolDefinition{
+		Name: "themed",
+		InputSchema: map[string]interface{}{
+			"properties": map[string]interface{}{"preset": map[string]interface{}{"type": "string"}},
+		},
+		Presets: map[string]map[string]interface{}{"dark": {"background": "black"}},
+	}
+
+	params, err := tool.ApplyPreset(map[string]interface{}{"preset": "dark"})
+	if err != nil {
+		t.Fatalf("ApplyPreset() error = %v", err)
+	}
+	if params["preset"] != "dark" || params["background"] != nil {
+		t.Errorf("ApplyPreset() = %v, want params unchanged", params)
+	}
+}
+
+func TestHandleSavedTool_Permissions(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	secrets.Set("api_token", "token-value")
+
+	// Only tools granted secrets may read them; eval_starlark is never granted any
+	opts := []starlark.Option{starlark.WithRestrictedModules("secrets")}
+	trusted := &persistence.SavedToolDefinition{Name: "trusted", Description: "d", Code: `len(secrets.get("api_token"))`, Permissions: []string{"secrets"}}
+	untrusted := &persistence.SavedToolDefinition{Name: "untrusted", Description: "d", Code: `len(secrets.get("api_token"))`}
+
+	result, _, _ := handleSavedTool(trusted, types.SavedToolParams{}, nil, opts...)
+	verifyTextContent(t, result, "Result: 11")
+
+	result, _, _ = handleSavedTool(untrusted, types.SavedToolParams{}, nil, opts...)
+	if !result.IsError {
+		t.Error("Expected the untrusted tool to be denied secrets")
+	}
+	verifyTextContent(t, result, "secrets is a restricted module")
+
+	result, _, _ = handleEvalStarlark(context.Background(), nil, EvalStarlarkArgs{Code: `secrets.get("api_token")`}, nil, opts...)
+	verifyTextContent(t, result, "secrets is a restricted module")
+}
+
+func TestHandleSavedTool_AllowedServers(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	proxyManager := NewMockProxyManager()
+	proxyManager.AddMockTool("github", &mcp.Tool{Name: "get_issue"})
+	proxyManager.AddMockTool("payments", &mcp.Tool{Name: "refund"})
+
+	reporter := &persistence.SavedToolDefinition{Name: "reporter", Description: "d", Code: `payments.refund(id=1)`, AllowedServers: []string{"github"}}
+	result, _, _ := handleSavedTool(reporter, types.SavedToolParams{}, proxyManager)
+	if !result.IsError {
+		t.Error("Expected the tool to be denied the payments server")
+	}
+	verifyTextContent(t, result, "undefined: payments")
+
+	reporter.Code = `github.get_issue(number=1)["content"][0]`
+	result, _, _ = handleSavedTool(reporter, types.SavedToolParams{}, proxyManager)
+	if result.IsError {
+		t.Errorf("Expected the tool to reach the github server, got %+v", result.Content)
+	}
+}
+
+func TestHandleSavedTool_Traceback(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	tool := &persistence.SavedToolDefinition{
+		Name:        "failing_tool",
+		Description: "Fails inside a helper",
+		Code: `def helper(n):
+    return n // 0
+
+result = helper(1)`,
+	}
+	result, _, _ := handleSavedTool(tool, types.SavedToolParams{}, nil)
+	if !result.IsError {
+		t.Fatal("Expected an error result")
+	}
+
+	text := result.Content[0].(*mcp.TextContent).Text
+	for _, want := range []string{"Tool error in failing_tool:", "Traceback (most recent call last):", "failing_tool:4:16: in <toplevel>", "failing_tool:2:14: in helper"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in the error, got:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,189 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+// Limits on the matches search_tools returns
+const (
+	defaultSearchLimit = 10
+	maxSearchLimit     = 50
+)
+
+// Weights of a query term found in a tool's name, as a prefix of a word in it, or in its description
+const (
+	nameWordScore    = 3
+	namePrefixScore  = 2
+	descriptionScore = 1
+)
+
+// searchStopWords are query words too common to say anything about a tool
+var searchStopWords = map[string]bool{
+	"a": true, "an": true, "and": true, "the": true, "that": true, "which": true, "to": true, "for": true,
+	"of": true, "in": true, "on": true, "or": true, "with": true, "tool": true, "tools": true,
+}
+
+// ToolMatch is a proxied tool found by search_tools
+type ToolMatch struct {
+	Server      string `json:"server"`
+	Tool        string `json:"tool"`                  // upstream name
+	Call        string `json:"call"`                  // how Starlark code calls it, e.g. github.create_issue
+	ProxiedName string `json:"proxiedName,omitempty"` // advertised name, unless the tool is hidden
+	Description string `json:"description,omitempty"`
+	Score       int    `json:"score"`
+}
+
+// SearchToolsResponse lists the best matches for a search, best first
+type SearchToolsResponse struct {
+	Matches []ToolMatch `json:"matches"`
+}
+
+// RegisterSearchTools registers the search_tools tool with the MCP server
+func RegisterSearchTools(server *mcp.Server, proxyManager ProxyManager) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "search_tools",
+		Description:  "Search the names and descriptions of every upstream server's tools, including hidden ones, for the tools best matching a description of what they do",
+		OutputSchema: outputSchema[SearchToolsResponse](),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.SearchToolsArgs) (*mcp.CallToolResult, any, error) {
+		return handleSearchTools(args, proxyManager)
+	})
+}
+
+func handleSearchTools(args types.SearchToolsArgs, proxyManager ProxyManager) (*mcp.CallToolResult, any, error) {
+	terms := searchTerms(args.Query)
+	if len(terms) == 0 {
+		return ErrorResponse("Error: query is required"), nil, nil
+	}
+	if args.Limit < 0 {
+		return ErrorResponse("Error: limit must not be negative"), nil, nil
+	}
+	limit := args.Limit
+	if limit == 0 {
+		limit = defaultSearchLimit
+	}
+	limit = min(limit, maxSearchLimit)
+
+	response := SearchToolsResponse{Matches: []ToolMatch{}}
+	if proxyManager == nil {
+		return SuccessResponse("No upstream servers configured"), response, nil
+	}
+	allTools := proxyManager.GetAllTools()
+	if args.Server != "" {
+		if _, exists := allTools[args.Server]; !exists {
+			return ErrorResponse("Error: server %s is not connected", args.Server), nil, nil
+		}
+	}
+
+	for serverName, tools := range allTools {
+		if args.Server != "" && serverName != args.Server {
+			continue
+		}
+		namespace := starlark.NamespaceName(proxyManager, serverName)
+		for attr, tool := range starlark.NamespaceTools(proxyManager, serverName, tools) {
+			score := scoreTool(terms, attr, tool)
+			if score == 0 {
+				continue
+			}
+			match := ToolMatch{
+				Server:      serverName,
+				Tool:        tool.Name,
+				Call:        namespace + "." + attr,
+				Description: tool.Description,
+				Score:       score,
+			}
+			match.ProxiedName, _ = proxiedToolName(serverName, tool.Name)
+			response.Matches = append(response.Matches, match)
+		}
+	}
+
+	sort.Slice(response.Matches, func(i, j int) bool {
+		a, b := response.Matches[i], response.Matches[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		return a.Call < b.Call
+	})
+	if len(response.Matches) > limit {
+		response.Matches = response.Matches[:limit]
+	}
+	if len(response.Matches) == 0 {
+		return SuccessResponse("No tools match %q", args.Query), response, nil
+	}
+
+	lines := make([]string, len(response.Matches))
+	for i, match := range response.Matches {
+		lines[i] = fmt.Sprintf("• %s", match.Call)
+		if match.ProxiedName != "" {
+			lines[i] += fmt.Sprintf(" (tool %s)", match.ProxiedName)
+		}
+		if match.Description != "" {
+			lines[i] += ": " + match.Description
+		}
+	}
+	return SuccessResponse("%d tool(s) matching %q:\n\n%s", len(response.Matches), args.Query, strings.Join(lines, "\n")), response, nil
+}
+
+// scoreTool rates how well a tool matches the query terms; 0 means not at all
+func scoreTool(terms []string, name string, tool *mcp.Tool) int {
+	nameWords := searchTerms(name + " " + tool.Name)
+	description := strings.Join(searchTerms(tool.Description), " ")
+	score := 0
+	for _, term := range terms {
+		best := 0
+		for _, word := range nameWords {
+			if word == term {
+				best = nameWordScore
+				break
+			}
+			if strings.HasPrefix(word, term) || (strings.HasPrefix(term, word) && len(word) >= 4) {
+				best = namePrefixScore
+			}
+		}
+		if best == 0 && strings.Contains(description, term) {
+			best = descriptionScore
+		}
+		score += best
+	}
+	return score
+}
+
+// searchTerms splits text into lowercase words, dropping stop words and common suffixes so that
+// "creates events" finds create_event
+func searchTerms(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	terms := make([]string, 0, len(words))
+	for _, word := range words {
+		if searchStopWords[word] {
+			continue
+		}
+		terms = append(terms, stem(word))
+	}
+	return terms
+}
+
+// stem strips a plural or tense suffix from a word; names and queries are stemmed alike, and
+// prefix matching covers the rest
+func stem(word string) string {
+	switch {
+	case len(word) <= 4:
+		return word
+	case strings.HasSuffix(word, "ies"):
+		return strings.TrimSuffix(word, "ies") + "y"
+	case strings.HasSuffix(word, "ing"), strings.HasSuffix(word, "ed"):
+		return strings.TrimSuffix(strings.TrimSuffix(word, "ing"), "ed")
+	case strings.HasSuffix(word, "s") && !strings.HasSuffix(word, "ss"):
+		return strings.TrimSuffix(word, "s")
+	}
+	return word
+}
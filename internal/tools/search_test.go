@@ -0,0 +1,60 @@
+package tools
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+func TestHandleSearchTools(t *testing.T) {
+	upstream := NewMockProxyManager()
+	upstream.AddMockTool("calendar", &mcp.Tool{Name: "create_event", Description: "Create a new event in a calendar"})
+	upstream.AddMockTool("calendar", &mcp.Tool{Name: "list_events", Description: "List upcoming events"})
+	upstream.AddMockTool("github", &mcp.Tool{Name: "create_issue", Description: "Open an issue in a repository"})
+	upstream.AddMockTool("github", &mcp.Tool{Name: "search_code", Description: "Search code across repositories"})
+	upstream.AddMockTool("my-slack", &mcp.Tool{Name: "post_message", Description: "Post a message, optionally scheduling it as a calendar reminder"})
+
+	tests := []struct {
+		name      string
+		args      types.SearchToolsArgs
+		wantCalls []string
+		wantErr   bool
+	}{
+		{"best match first", types.SearchToolsArgs{Query: "the tool that creates calendar events"}, []string{"calendar.create_event", "calendar.list_events", "github.create_issue", "my_slack.post_message"}, false},
+		{"description only", types.SearchToolsArgs{Query: "repository"}, []string{"github.create_issue", "github.search_code"}, false},
+		{"one server", types.SearchToolsArgs{Query: "create", Server: "github"}, []string{"github.create_issue"}, false},
+		{"limited", types.SearchToolsArgs{Query: "create calendar event", Limit: 1}, []string{"calendar.create_event"}, false},
+		{"no match", types.SearchToolsArgs{Query: "weather forecast"}, nil, false},
+		{"empty query", types.SearchToolsArgs{Query: "the tool"}, nil, true},
+		{"unknown server", types.SearchToolsArgs{Query: "create", Server: "jira"}, nil, true},
+		{"negative limit", types.SearchToolsArgs{Query: "create", Limit: -1}, nil, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, structured, _ := handleSearchTools(tt.args, upstream)
+			if result.IsError != tt.wantErr {
+				t.Fatalf("IsError = %v, want %v: %s", result.IsError, tt.wantErr, result.Content[0].(*mcp.TextContent).Text)
+			}
+			if tt.wantErr {
+				return
+			}
+			var calls []string
+			for _, match := range structured.(SearchToolsResponse).Matches {
+				calls = append(calls, match.Call)
+			}
+			if strings.Join(calls, ",") != strings.Join(tt.wantCalls, ",") {
+				t.Errorf("Matches = %v, want %v", calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestHandleSearchToolsWithoutServers(t *testing.T) {
+	result, structured, _ := handleSearchTools(types.SearchToolsArgs{Query: "create"}, nil)
+	if result.IsError || len(structured.(SearchToolsResponse).Matches) != 0 {
+		t.Errorf("search_tools without servers = %+v, want no matches", result)
+	}
+}
@@ -0,0 +1,308 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+// ConfigEditor changes servers.json and applies the result to the running server
+type ConfigEditor interface {
+	Edit(edit func(cfg *config.Config) error) (*proxy.ReloadSummary, error)
+}
+
+// ServerChangeResponse reports how adding or removing a server changed the upstream servers
+type ServerChangeResponse struct {
+	Server string `json:"server"`
+	*proxy.ReloadSummary
+}
+
+// ListServersResponse wraps the server statuses in an object structure expected by MCP
+type ListServersResponse struct {
+	Servers []proxy.ServerStatus `json:"servers"`
+}
+
+// RestartServerResponse reports the tools a restarted server offers
+type RestartServerResponse struct {
+	Server string `json:"server"`
+	Tools  int    `json:"tools"`
+}
+
+// RegisterListServers registers the list_servers tool with the MCP server
+func RegisterListServers(server *mcp.Server, proxyManager ProxyManager) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "list_servers",
+		Description:  "List configured upstream servers with their transport, connection state, tool count and last error",
+		OutputSchema: outputSchema[ListServersResponse](),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args struct{}) (*mcp.CallToolResult, any, error) {
+		return handleListServers(proxyManager)
+	})
+}
+
+// RegisterServerStatus registers the server_status tool with the MCP server
+func RegisterServerStatus(server *mcp.Server, proxyManager ProxyManager) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "server_status",
+		Description:  "Report the health of upstream servers: ping latency, last successful call and consecutive failures",
+		OutputSchema: outputSchema[ListServersResponse](),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.ServerStatusArgs) (*mcp.CallToolResult, any, error) {
+		return handleServerStatus(args, proxyManager)
+	})
+}
+
+// RegisterRestartServer registers the restart_server tool with the MCP server
+func RegisterRestartServer(server *mcp.Server, proxyManager ProxyManager) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "restart_server",
+		Description:  "Restart a single upstream server by name, relaunching its process and rediscovering its tools",
+		OutputSchema: outputSchema[RestartServerResponse](),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.RestartServerArgs) (*mcp.CallToolResult, any, error) {
+		return handleRestartServer(args, proxyManager)
+	})
+}
+
+// RegisterAddServer registers the add_server tool with the MCP server
+// editor may be nil if no valid config was loaded at startup
+func RegisterAddServer(server *mcp.Server, editor ConfigEditor) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:        "add_server",
+		Description: "Add an upstream MCP server, or update the given settings of an existing server, saving it to servers.json and connecting immediately",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.AddServerArgs) (*mcp.CallToolResult, any, error) {
+		return handleAddServer(args, editor)
+	})
+}
+
+// RegisterRemoveServer registers the remove_server tool with the MCP server
+// editor may be nil if no valid config was loaded at startup
+func RegisterRemoveServer(server *mcp.Server, editor ConfigEditor) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:        "remove_server",
+		Description: "Remove an upstream MCP server from servers.json and disconnect it",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.RemoveServerArgs) (*mcp.CallToolResult, any, error) {
+		return handleRemoveServer(args, editor)
+	})
+}
+
+func handleListServers(proxyManager ProxyManager) (*mcp.CallToolResult, any, error) {
+	if proxyManager == nil {
+		return SuccessResponse("No upstream servers configured"), ListServersResponse{Servers: []proxy.ServerStatus{}}, nil
+	}
+
+	statuses, ok := proxy.ServerStatuses(proxyManager)
+	if !ok {
+		return ErrorResponse("Error: server status is not available"), nil, nil
+	}
+
+	response := ListServersResponse{Servers: statuses}
+	if len(statuses) == 0 {
+		return SuccessResponse("No upstream servers configured"), response, nil
+	}
+
+	var lines []string
+	for _, status := range statuses {
+		line := fmt.Sprintf("• %s (%s): %s, %d tool(s)", status.Name, status.Transport, status.State, status.Tools)
+		if status.Hidden {
+			line += ", hidden"
+		}
+		if status.LastError != "" {
+			line += fmt.Sprintf("\n    last error at %s: %s", status.LastErrorAt.Format("2006-01-02 15:04:05"), status.LastError)
+		}
+		lines = append(lines, line)
+	}
+	return SuccessResponse("%d configured server(s):\n\n%s", len(statuses), strings.Join(lines, "\n")), response, nil
+}
+
+// stderrSummaryLines is how many of a server's latest stderr lines server_status shows in its text;
+// the structured content has all that are kept
+const stderrSummaryLines = 5
+
+func handleServerStatus(args types.ServerStatusArgs, proxyManager ProxyManager) (*mcp.CallToolResult, any, error) {
+	if proxyManager == nil {
+		if args.Name != "" {
+			return ErrorResponse("Error: server %s is not configured", args.Name), nil, nil
+		}
+		return SuccessResponse("No upstream servers configured"), ListServersResponse{Servers: []proxy.ServerStatus{}}, nil
+	}
+
+	if args.Check && !proxy.CheckHealth(proxyManager) {
+		return ErrorResponse("Error: health checks are not available"), nil, nil
+	}
+	statuses, ok := proxy.ServerStatuses(proxyManager)
+	if !ok {
+		return ErrorResponse("Error: server status is not available"), nil, nil
+	}
+
+	if args.Name != "" {
+		var selected []proxy.ServerStatus
+		for _, status := range statuses {
+			if status.Name == args.Name {
+				selected = append(selected, status)
+			}
+		}
+		if len(selected) == 0 {
+			return ErrorResponse("Error: server %s is not configured", args.Name), nil, nil
+		}
+		statuses = selected
+	}
+
+	response := ListServersResponse{Servers: statuses}
+	if len(statuses) == 0 {
+		return SuccessResponse("No upstream servers configured"), response, nil
+	}
+
+	lines := make([]string, len(statuses))
+	for i, status := range statuses {
+		statuses[i].Stderr = proxy.ServerStderr(proxyManager, status.Name)
+		lines[i] = fmt.Sprintf("• %s: %s", status.Name, status.HealthSummary())
+		if recent := statuses[i].Stderr[max(0, len(statuses[i].Stderr)-stderrSummaryLines):]; len(recent) > 0 {
+			lines[i] += "\n    stderr:\n      " + strings.Join(recent, "\n      ")
+		}
+	}
+	return SuccessResponse("%s", strings.Join(lines, "\n")), response, nil
+}
+
+func handleRestartServer(args types.RestartServerArgs, proxyManager ProxyManager) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" {
+		return ErrorResponse("Error: server name is required"), nil, nil
+	}
+	if proxyManager == nil {
+		return ErrorResponse("Error: server %s is not configured", args.Name), nil, nil
+	}
+
+	tools, err := proxy.RestartServer(proxyManager, args.Name)
+	if err != nil {
+		return ErrorResponse("Error: %v", err), nil, nil
+	}
+	response := RestartServerResponse{Server: args.Name, Tools: len(tools)}
+	return SuccessResponse("Restarted server %s, which offers %d tool(s)", args.Name, len(tools)), response, nil
+}
+
+func handleAddServer(args types.AddServerArgs, editor ConfigEditor) (*mcp.CallToolResult, any, error) {
+	if err := validateServerName(args.Name); err != nil {
+		return ErrorResponse("Error: %v", err), nil, nil
+	}
+	if strings.TrimSpace(args.Command) != "" && args.URL != "" {
+		return ErrorResponse("Error: give a server command or a url, not both"), nil, nil
+	}
+	if editor == nil {
+		return ErrorResponse("Error: managing servers requires a valid servers.json at startup; run mcp-metatool init instead"), nil, nil
+	}
+
+	replaced := false
+	summary, err := editor.Edit(func(cfg *config.Config) error {
+		// Start from the existing settings so those this tool can't set, such as approval
+		// requirements, Docker, OAuth and environment passing, survive an update
+		serverConfig, exists := cfg.MCPServers[args.Name]
+		if !exists && strings.TrimSpace(args.Command) == "" && args.URL == "" {
+			return fmt.Errorf("server command or url is required")
+		}
+		mergeServerArgs(&serverConfig, args)
+		replaced = exists
+		cfg.MCPServers[args.Name] = serverConfig
+		return nil
+	})
+	if err != nil {
+		return serverChangeError("add", args.Name, summary, err)
+	}
+
+	response := ServerChangeResponse{Server: args.Name, ReloadSummary: summary}
+	action := "Added"
+	if replaced {
+		action = "Updated"
+	}
+	if slices.Contains(summary.Failed, args.Name) {
+		return SuccessResponse("%s server %s in servers.json, but it failed to connect (%s)", action, args.Name, summary), response, nil
+	}
+	return SuccessResponse("%s server %s (%s)", action, args.Name, summary), response, nil
+}
+
+// mergeServerArgs applies the settings given to add_server over a server's config, leaving the rest as they were
+func mergeServerArgs(serverConfig *config.MCPServerConfig, args types.AddServerArgs) {
+	switch {
+	case strings.TrimSpace(args.Command) != "":
+		// Switching a Docker or remote server to a command drops the settings only they use
+		if serverConfig.Docker != nil || serverConfig.URL != "" {
+			serverConfig.Docker, serverConfig.URL, serverConfig.OAuth = nil, "", nil
+		}
+		serverConfig.Command = args.Command
+	case args.URL != "" && args.URL != serverConfig.URL:
+		serverConfig.Command, serverConfig.Args, serverConfig.Docker = "", nil, nil
+		serverConfig.URL = args.URL
+	}
+	if args.Args != nil {
+		serverConfig.Args = args.Args
+	}
+	if args.Env != nil {
+		serverConfig.Env = args.Env
+	}
+	if args.Hidden != nil {
+		serverConfig.Hidden = *args.Hidden
+	}
+	if args.AllowedTools != nil {
+		serverConfig.AllowedTools = args.AllowedTools
+	}
+	if args.HiddenTools != nil {
+		serverConfig.HiddenTools = args.HiddenTools
+	}
+	if args.LazyStart != nil {
+		serverConfig.LazyStart = *args.LazyStart
+	}
+	if args.IdleTimeout != "" {
+		serverConfig.IdleTimeout = args.IdleTimeout
+	}
+}
+
+func handleRemoveServer(args types.RemoveServerArgs, editor ConfigEditor) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" {
+		return ErrorResponse("Error: server name is required"), nil, nil
+	}
+	if editor == nil {
+		return ErrorResponse("Error: managing servers requires a valid servers.json at startup"), nil, nil
+	}
+
+	summary, err := editor.Edit(func(cfg *config.Config) error {
+		if _, exists := cfg.MCPServers[args.Name]; !exists {
+			return fmt.Errorf("server %s is not configured", args.Name)
+		}
+		delete(cfg.MCPServers, args.Name)
+		return nil
+	})
+	if err != nil {
+		return serverChangeError("remove", args.Name, summary, err)
+	}
+
+	response := ServerChangeResponse{Server: args.Name, ReloadSummary: summary}
+	return SuccessResponse("Removed server %s (%s)", args.Name, summary), response, nil
+}
+
+// serverChangeError reports a failed edit, noting when the file was saved but not fully applied
+func serverChangeError(action, name string, summary *proxy.ReloadSummary, err error) (*mcp.CallToolResult, any, error) {
+	if summary != nil {
+		return ErrorResponse("Saved servers.json but failed to apply it (%s): %v", summary, err), nil, nil
+	}
+	return ErrorResponse("Failed to %s server %s: %v", action, name, err), nil, nil
+}
+
+// validateServerName checks that a server name can be used as a Starlark namespace and tool prefix
+func validateServerName(name string) error {
+	if name == "" {
+		return fmt.Errorf("server name is required")
+	}
+	for i, r := range name {
+		isLetter := r == '_' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+		if !isLetter && (i == 0 || r < '0' || r > '9') {
+			return fmt.Errorf("invalid server name %q: use letters, digits and underscores, not starting with a digit", name)
+		}
+	}
+	if strings.Contains(name, "__") {
+		return fmt.Errorf("invalid server name %q: double underscores separate server and tool names", name)
+	}
+	return nil
+}
@@ -0,0 +1,346 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+// fakeEditor applies edits to an in-memory config, reporting added and removed servers
+type fakeEditor struct {
+	cfg    *config.Config
+	failed []string
+}
+
+func (e *fakeEditor) Edit(edit func(cfg *config.Config) error) (*proxy.ReloadSummary, error) {
+	edited := &config.Config{MCPServers: make(map[string]config.MCPServerConfig)}
+	for name, serverConfig := range e.cfg.MCPServers {
+		edited.MCPServers[name] = serverConfig
+	}
+	if err := edit(edited); err != nil {
+		return nil, err
+	}
+	if err := edited.Validate(); err != nil {
+		return nil, err
+	}
+
+	summary := &proxy.ReloadSummary{Failed: e.failed}
+	for name := range edited.MCPServers {
+		if _, exists := e.cfg.MCPServers[name]; !exists {
+			summary.Added = append(summary.Added, name)
+		}
+	}
+	for name := range e.cfg.MCPServers {
+		if _, exists := edited.MCPServers[name]; !exists {
+			summary.Removed = append(summary.Removed, name)
+		}
+	}
+	e.cfg = edited
+	return summary, nil
+}
+
+func TestHandleAddServer(t *testing.T) {
+	inherit := false
+	editor := &fakeEditor{cfg: &config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"github": {Command: "gh-mcp", ApprovalRequired: []string{"delete_*"}},
+		"linear": {URL: "https://mcp.linear.app/mcp", InheritEnv: &inherit, LazyStart: true},
+		"docker": {Docker: &config.DockerConfig{Image: "ghcr.io/acme/server"}, PassEnv: []string{"HOME"}},
+	}}}
+	hidden := true
+
+	tests := []struct {
+		name     string
+		args     types.AddServerArgs
+		editor   ConfigEditor
+		wantErr  bool
+		wantText string
+	}{
+		{"missing name", types.AddServerArgs{Command: "x"}, editor, true, "server name is required"},
+		{"invalid name", types.AddServerArgs{Name: "my-server", Command: "x"}, editor, true, "invalid server name"},
+		{"double underscore", types.AddServerArgs{Name: "a__b", Command: "x"}, editor, true, "double underscores"},
+		{"missing command", types.AddServerArgs{Name: "jira"}, editor, true, "command or url is required"},
+		{"command and url", types.AddServerArgs{Name: "jira", Command: "jira-mcp", URL: "https://example.com/mcp"}, editor, true, "not both"},
+		{"invalid idle timeout", types.AddServerArgs{Name: "jira", Command: "jira-mcp", IdleTimeout: "soon"}, editor, true, "invalid idleTimeout"},
+		{"no config", types.AddServerArgs{Name: "jira", Command: "jira-mcp"}, nil, true, "mcp-metatool init"},
+		{"added", types.AddServerArgs{Name: "jira", Command: "jira-mcp", Args: []string{"--site", "acme"}}, editor, false, "Added server jira (added: jira)"},
+		{"updated", types.AddServerArgs{Name: "github", Command: "github-mcp"}, editor, false, "Updated server github"},
+		{"updated url server", types.AddServerArgs{Name: "linear", Hidden: &hidden}, editor, false, "Updated server linear"},
+		{"updated docker server", types.AddServerArgs{Name: "docker", Args: []string{"--verbose"}}, editor, false, "Updated server docker"},
+		{"failed to connect", types.AddServerArgs{Name: "broken", Command: "false"}, &fakeEditor{cfg: &config.Config{}, failed: []string{"broken"}}, false, "failed to connect"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, structured, err := handleAddServer(tt.args, tt.editor)
+			if err != nil {
+				t.Fatalf("handleAddServer() error = %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Errorf("Expected IsError = %v, got %v", tt.wantErr, result.IsError)
+			}
+			verifyTextContent(t, result, tt.wantText)
+			if !tt.wantErr {
+				if response, ok := structured.(ServerChangeResponse); !ok || response.Server != tt.args.Name {
+					t.Errorf("Expected ServerChangeResponse for %s, got %+v", tt.args.Name, structured)
+				}
+			}
+		})
+	}
+
+	jira := editor.cfg.MCPServers["jira"]
+	if jira.Command != "jira-mcp" || len(jira.Args) != 2 {
+		t.Errorf("Unexpected jira config: %+v", jira)
+	}
+	github := editor.cfg.MCPServers["github"]
+	if github.Command != "github-mcp" || len(github.ApprovalRequired) != 1 {
+		t.Errorf("Expected github command replaced and approvals kept, got %+v", github)
+	}
+	linear := editor.cfg.MCPServers["linear"]
+	if linear.URL != "https://mcp.linear.app/mcp" || linear.InheritEnv == nil || !linear.LazyStart || !linear.Hidden {
+		t.Errorf("Expected linear hidden with its other settings kept, got %+v", linear)
+	}
+	docker := editor.cfg.MCPServers["docker"]
+	if docker.Docker == nil || docker.Docker.Image != "ghcr.io/acme/server" || len(docker.PassEnv) != 1 || len(docker.Args) != 1 {
+		t.Errorf("Expected docker args set with its other settings kept, got %+v", docker)
+	}
+}
+
+func TestHandleRemoveServer(t *testing.T) {
+	editor := &fakeEditor{cfg: &config.Config{MCPServers: map[string]config.MCPServerConfig{
+		"github": {Command: "gh-mcp"},
+		"jira":   {Command: "jira-mcp"},
+	}}}
+
+	tests := []struct {
+		name     string
+		args     types.RemoveServerArgs
+		editor   ConfigEditor
+		wantErr  bool
+		wantText string
+	}{
+		{"missing name", types.RemoveServerArgs{}, editor, true, "server name is required"},
+		{"unknown server", types.RemoveServerArgs{Name: "slack"}, editor, true, "server slack is not configured"},
+		{"no config", types.RemoveServerArgs{Name: "jira"}, nil, true, "requires a valid servers.json"},
+		{"removed", types.RemoveServerArgs{Name: "jira"}, editor, false, "Removed server jira (removed: jira)"},
+		{"last server", types.RemoveServerArgs{Name: "github"}, editor, true, "no MCP servers configured"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, _, err := handleRemoveServer(tt.args, tt.editor)
+			if err != nil {
+				t.Fatalf("handleRemoveServer() error = %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Errorf("Expected IsError = %v, got %v", tt.wantErr, result.IsError)
+			}
+			verifyTextContent(t, result, tt.wantText)
+		})
+	}
+
+	if _, exists := editor.cfg.MCPServers["github"]; !exists {
+		t.Error("Expected github to remain configured")
+	}
+}
+
+func TestServerChangeErrorAfterSave(t *testing.T) {
+	result, _, _ := serverChangeError("add", "jira", &proxy.ReloadSummary{Added: []string{"jira"}}, errors.New("registration failed"))
+	verifyTextContent(t, result, "Saved servers.json but failed to apply it (added: jira): registration failed")
+}
+
+// statusProxyManager reports canned server statuses and stderr output
+type statusProxyManager struct {
+	*MockProxyManager
+	statuses []proxy.ServerStatus
+	stderr   map[string][]string
+}
+
+func (m *statusProxyManager) ServerStatuses() []proxy.ServerStatus {
+	return slices.Clone(m.statuses)
+}
+
+func (m *statusProxyManager) ServerStderr(serverName string) []string {
+	return m.stderr[serverName]
+}
+
+// checkingProxyManager counts on-demand health checks
+type checkingProxyManager struct {
+	*statusProxyManager
+	checks int
+}
+
+func (m *checkingProxyManager) CheckHealth() {
+	m.checks++
+}
+
+func TestHandleServerStatus(t *testing.T) {
+	calledAt := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+	statuses := &statusProxyManager{MockProxyManager: NewMockProxyManager(), statuses: []proxy.ServerStatus{
+		{Name: "github", State: proxy.StateConnected, Health: proxy.HealthHealthy, LatencyMs: 4.5, LastSuccessAt: &calledAt},
+		{Name: "jira", State: proxy.StateFailed, Health: proxy.HealthUnhealthy, ConsecutiveFailures: 2},
+	}, stderr: map[string][]string{
+		"jira": {"starting jira server", "1", "2", "3", "4", "error: JIRA_TOKEN is not set"},
+	}}
+	checking := &checkingProxyManager{statusProxyManager: statuses}
+
+	tests := []struct {
+		name         string
+		args         types.ServerStatusArgs
+		proxyManager ProxyManager
+		wantErr      bool
+		wantText     []string
+		wantServers  int
+	}{
+		{"no servers", types.ServerStatusArgs{}, nil, false, []string{"No upstream servers configured"}, 0},
+		{"status unavailable", types.ServerStatusArgs{}, NewMockProxyManager(), true, []string{"not available"}, 0},
+		{"check unavailable", types.ServerStatusArgs{Check: true}, statuses, true, []string{"health checks are not available"}, 0},
+		{"all servers", types.ServerStatusArgs{}, statuses, false, []string{"• github: healthy, latency 4.5ms, last successful call 2025-01-15 10:30:00", "• jira: unhealthy (failed), 2 consecutive failure(s)"}, 2},
+		{"one server", types.ServerStatusArgs{Name: "jira", Check: true}, checking, false, []string{"• jira: unhealthy"}, 1},
+		{"stderr", types.ServerStatusArgs{Name: "jira"}, statuses, false, []string{"stderr:\n      1\n      2\n      3\n      4\n      error: JIRA_TOKEN is not set"}, 1},
+		{"unknown server", types.ServerStatusArgs{Name: "slack"}, statuses, true, []string{"server slack is not configured"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, structured, err := handleServerStatus(tt.args, tt.proxyManager)
+			if err != nil {
+				t.Fatalf("handleServerStatus() error = %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Errorf("Expected IsError = %v, got %v", tt.wantErr, result.IsError)
+			}
+			for _, text := range tt.wantText {
+				verifyTextContent(t, result, text)
+			}
+			if tt.wantErr {
+				return
+			}
+			response, ok := structured.(ListServersResponse)
+			if !ok || len(response.Servers) != tt.wantServers {
+				t.Fatalf("Expected %d servers in response, got %+v", tt.wantServers, structured)
+			}
+			for _, status := range response.Servers {
+				if want := tt.proxyManager.(proxy.StderrReporter).ServerStderr(status.Name); !slices.Equal(status.Stderr, want) {
+					t.Errorf("Expected all kept stderr lines for %s, got %v", status.Name, status.Stderr)
+				}
+			}
+		})
+	}
+	if checking.checks != 1 {
+		t.Errorf("Expected one on-demand health check, got %d", checking.checks)
+	}
+}
+
+func TestHandleListServers(t *testing.T) {
+	failedAt := time.Date(2025, 1, 15, 10, 30, 0, 0, time.UTC)
+
+	tests := []struct {
+		name         string
+		proxyManager ProxyManager
+		wantErr      bool
+		wantText     []string
+		wantServers  int
+	}{
+		{
+			name:     "no servers",
+			wantText: []string{"No upstream servers configured"},
+		},
+		{
+			name:         "status unavailable",
+			proxyManager: NewMockProxyManager(),
+			wantErr:      true,
+			wantText:     []string{"not available"},
+		},
+		{
+			name: "statuses",
+			proxyManager: &statusProxyManager{MockProxyManager: NewMockProxyManager(), statuses: []proxy.ServerStatus{
+				{Name: "github", Transport: "stdio", State: proxy.StateConnected, Tools: 12},
+				{Name: "jira", Transport: "stdio", State: proxy.StateFailed, Hidden: true, LastError: "failed to connect to server: EOF", LastErrorAt: &failedAt},
+			}},
+			wantText:    []string{"2 configured server(s)", "• github (stdio): connected, 12 tool(s)", "• jira (stdio): failed, 0 tool(s), hidden", "last error at 2025-01-15 10:30:00: failed to connect"},
+			wantServers: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, structured, err := handleListServers(tt.proxyManager)
+			if err != nil {
+				t.Fatalf("handleListServers() error = %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Errorf("Expected IsError = %v, got %v", tt.wantErr, result.IsError)
+			}
+			for _, text := range tt.wantText {
+				verifyTextContent(t, result, text)
+			}
+			if tt.wantErr {
+				return
+			}
+			if response, ok := structured.(ListServersResponse); !ok || len(response.Servers) != tt.wantServers {
+				t.Errorf("Expected %d servers in response, got %+v", tt.wantServers, structured)
+			}
+		})
+	}
+}
+
+// restartingProxyManager records restarts, failing for servers it doesn't know
+type restartingProxyManager struct {
+	*MockProxyManager
+	restarted []string
+}
+
+func (m *restartingProxyManager) RestartServer(serverName string) ([]*mcp.Tool, error) {
+	if serverName != "github" {
+		return nil, fmt.Errorf("server %s not configured", serverName)
+	}
+	m.restarted = append(m.restarted, serverName)
+	return []*mcp.Tool{{Name: "get_issue"}, {Name: "create_issue"}}, nil
+}
+
+func TestHandleRestartServer(t *testing.T) {
+	restarting := &restartingProxyManager{MockProxyManager: NewMockProxyManager()}
+
+	tests := []struct {
+		name         string
+		args         types.RestartServerArgs
+		proxyManager ProxyManager
+		wantErr      bool
+		wantText     string
+	}{
+		{"missing name", types.RestartServerArgs{}, restarting, true, "server name is required"},
+		{"no servers", types.RestartServerArgs{Name: "github"}, nil, true, "server github is not configured"},
+		{"unsupported", types.RestartServerArgs{Name: "github"}, NewMockProxyManager(), true, "not supported"},
+		{"unknown server", types.RestartServerArgs{Name: "jira"}, restarting, true, "server jira not configured"},
+		{"restarted", types.RestartServerArgs{Name: "github"}, restarting, false, "Restarted server github, which offers 2 tool(s)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, structured, err := handleRestartServer(tt.args, tt.proxyManager)
+			if err != nil {
+				t.Fatalf("handleRestartServer() error = %v", err)
+			}
+			if result.IsError != tt.wantErr {
+				t.Errorf("Expected IsError = %v, got %v", tt.wantErr, result.IsError)
+			}
+			verifyTextContent(t, result, tt.wantText)
+			if !tt.wantErr {
+				if response, ok := structured.(RestartServerResponse); !ok || response.Tools != 2 {
+					t.Errorf("Unexpected response: %+v", structured)
+				}
+			}
+		})
+	}
+	if len(restarting.restarted) != 1 {
+		t.Errorf("Expected one restart, got %v", restarting.restarted)
+	}
+}
@@ -0,0 +1,112 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/types"
+	"github.com/dslh/mcp-metatool/internal/usage"
+)
+
+// statsSavedServer selects saved tools when filtering stats by server
+const statsSavedServer = "saved"
+
+// statsSortKeys order tool stats, most significant first
+var statsSortKeys = map[string]func(a, b usage.ToolStats) bool{
+	"calls":     func(a, b usage.ToolStats) bool { return a.Calls > b.Calls },
+	"errors":    func(a, b usage.ToolStats) bool { return a.Errors > b.Errors },
+	"errorRate": func(a, b usage.ToolStats) bool { return a.ErrorRate > b.ErrorRate },
+	"avg":       func(a, b usage.ToolStats) bool { return a.AvgMs > b.AvgMs },
+	"max":       func(a, b usage.ToolStats) bool { return a.MaxMs > b.MaxMs },
+}
+
+// ToolStatsResponse reports the calls made to saved and proxied tools since tracking began
+type ToolStatsResponse struct {
+	Since   time.Time         `json:"since"`
+	Saved   []usage.ToolStats `json:"saved"`
+	Proxied []usage.ToolStats `json:"proxied"`
+}
+
+// RegisterToolStats registers the tool_stats tool with the MCP server
+func RegisterToolStats(server *mcp.Server) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "tool_stats",
+		Description:  "Show how often each saved and proxied tool has been called, how long calls take on average and at most, and how often they fail",
+		OutputSchema: outputSchema[ToolStatsResponse](),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.ToolStatsArgs) (*mcp.CallToolResult, any, error) {
+		response, err := LoadToolStats(args)
+		if err != nil {
+			return ErrorResponse("Error: %v", err), nil, nil
+		}
+		return SuccessResponse("%s", response), response, nil
+	})
+}
+
+// LoadToolStats reads the recorded tool usage, filtered and sorted as requested
+func LoadToolStats(args types.ToolStatsArgs) (*ToolStatsResponse, error) {
+	if args.Sort == "" {
+		args.Sort = "calls"
+	}
+	less, ok := statsSortKeys[args.Sort]
+	if !ok {
+		return nil, fmt.Errorf("unknown sort %q: use calls, errors, errorRate, avg or max", args.Sort)
+	}
+
+	stats, err := usage.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load tool usage: %w", err)
+	}
+	response := &ToolStatsResponse{Since: stats.Since}
+	response.Saved, response.Proxied = stats.ToolStats()
+	switch args.Server {
+	case "":
+	case statsSavedServer:
+		response.Proxied = []usage.ToolStats{}
+	default:
+		response.Saved = []usage.ToolStats{}
+		filtered := []usage.ToolStats{}
+		for _, tool := range response.Proxied {
+			if tool.Server == args.Server {
+				filtered = append(filtered, tool)
+			}
+		}
+		response.Proxied = filtered
+	}
+
+	for _, list := range [][]usage.ToolStats{response.Saved, response.Proxied} {
+		sort.SliceStable(list, func(i, j int) bool { return less(list[i], list[j]) })
+	}
+	return response, nil
+}
+
+// String lists each tool's stats for people, one per line
+func (r *ToolStatsResponse) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Tool calls since %s", r.Since.Format("2006-01-02"))
+	if len(r.Saved) == 0 && len(r.Proxied) == 0 {
+		b.WriteString(": none recorded")
+		return b.String()
+	}
+	for _, group := range []struct {
+		name  string
+		tools []usage.ToolStats
+	}{{"Saved tools", r.Saved}, {"Proxied tools", r.Proxied}} {
+		if len(group.tools) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n\n%s:", group.name)
+		for _, tool := range group.tools {
+			name := tool.Tool
+			if tool.Server != "" {
+				name = tool.Server + "__" + tool.Tool
+			}
+			fmt.Fprintf(&b, "\n• %s: %d call(s), %d error(s) (%.0f%%), avg %.0fms, max %.0fms", name, tool.Calls, tool.Errors, tool.ErrorRate*100, tool.AvgMs, tool.MaxMs)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,69 @@
+package tools
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+func TestLoadToolStats(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+	stats := `{"since": "2026-01-01T00:00:00Z",
+		"proxied": {
+			"github": {"get_issue": {"calls": 4, "timed": 4, "totalMs": 40, "maxMs": 20}, "search": {"calls": 2, "errors": 1, "timed": 2, "totalMs": 400, "maxMs": 300}},
+			"jira": {"search": {"calls": 1, "timed": 1, "totalMs": 5, "maxMs": 5}}
+		},
+		"saved": {"report": {"calls": 3, "errors": 3, "timed": 3, "totalMs": 90, "maxMs": 50}}}`
+	os.WriteFile(filepath.Join(dir, "usage.json"), []byte(stats), 0644)
+
+	tests := []struct {
+		name        string
+		args        types.ToolStatsArgs
+		wantSaved   []string
+		wantProxied []string
+		wantError   string
+	}{
+		{"default", types.ToolStatsArgs{}, []string{"report"}, []string{"github__get_issue", "github__search", "jira__search"}, ""},
+		{"by average", types.ToolStatsArgs{Sort: "avg"}, []string{"report"}, []string{"github__search", "github__get_issue", "jira__search"}, ""},
+		{"by error rate", types.ToolStatsArgs{Sort: "errorRate"}, []string{"report"}, []string{"github__search", "github__get_issue", "jira__search"}, ""},
+		{"one server", types.ToolStatsArgs{Server: "jira"}, nil, []string{"jira__search"}, ""},
+		{"saved only", types.ToolStatsArgs{Server: "saved"}, []string{"report"}, nil, ""},
+		{"unknown sort", types.ToolStatsArgs{Sort: "name"}, nil, nil, `unknown sort "name"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, err := LoadToolStats(tt.args)
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("LoadToolStats() error = %v, want %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadToolStats() error = %v", err)
+			}
+			var saved, proxied []string
+			for _, tool := range response.Saved {
+				saved = append(saved, tool.Tool)
+			}
+			for _, tool := range response.Proxied {
+				proxied = append(proxied, tool.Server+"__"+tool.Tool)
+			}
+			if strings.Join(saved, ",") != strings.Join(tt.wantSaved, ",") || strings.Join(proxied, ",") != strings.Join(tt.wantProxied, ",") {
+				t.Errorf("LoadToolStats() = %v, %v, want %v, %v", saved, proxied, tt.wantSaved, tt.wantProxied)
+			}
+		})
+	}
+
+	response, _ := LoadToolStats(types.ToolStatsArgs{})
+	text := response.String()
+	for _, want := range []string{"since 2026-01-01", "• report: 3 call(s), 3 error(s) (100%), avg 30ms, max 50ms", "• github__search: 2 call(s), 1 error(s) (50%), avg 200ms, max 300ms"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("Expected %q in:\n%s", want, text)
+		}
+	}
+}
@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/queue"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/tooltest"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+// RegisterTestSavedTool registers the test_saved_tool tool with the MCP server
+// The proxyManager parameter is optional; tests without mocks need it to reach real servers
+func RegisterTestSavedTool(server *mcp.Server, proxyManager ProxyManager, opts ...starlark.Option) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:        "test_saved_tool",
+		Description: "Run the embedded tests of a saved tool and report pass/fail per case",
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.TestToolArgs) (*mcp.CallToolResult, any, error) {
+		return runQueued(ctx, req, queue.PriorityBatch, func() (*mcp.CallToolResult, any, error) {
+			return handleTestSavedTool(ctx, req, args, proxyManager, opts...)
+		})
+	})
+}
+
+func handleTestSavedTool(ctx context.Context, req *mcp.CallToolRequest, args types.TestToolArgs, proxyManager ProxyManager, opts ...starlark.Option) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" {
+		return ErrorResponse("Error: tool name is required"), nil, nil
+	}
+
+	tool, err := persistence.LoadTool(args.Name)
+	if err != nil {
+		return ErrorResponse("Failed to load tool '%s': %v", args.Name, err), nil, nil
+	}
+
+	if len(tool.Tests) == 0 {
+		return ErrorResponse("Tool '%s' has no tests", args.Name), nil, nil
+	}
+
+	// Tests without mocks use the fixtures named in the environment, if any, in place of live servers
+	fixtureProxy, err := tooltest.FixtureProxyFromEnv()
+	if err != nil {
+		return ErrorResponse("Failed to load fixtures: %v", err), nil, nil
+	}
+	if fixtureProxy != nil {
+		proxyManager = fixtureProxy
+	}
+
+	report := tooltest.Run(tool, proxyManager, opts...)
+	if report.Failed > 0 {
+		return ErrorResponse("%s", report.Summary()), report, nil
+	}
+
+	return SuccessResponse("%s", report.Summary()), report, nil
+}
@@ -0,0 +1,57 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/tooltest"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+func TestHandleTestSavedTool(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	persistence.SaveTool(&persistence.SavedToolDefinition{
+		Name:        "double",
+		Description: "Doubles a number",
+		Code:        `params["n"] * 2`,
+		Tests: []persistence.ToolTest{
+			{Name: "doubles", Params: map[string]interface{}{"n": 2}, Expected: 4},
+		},
+	})
+	persistence.SaveTool(&persistence.SavedToolDefinition{
+		Name:        "broken",
+		Description: "Has a failing test",
+		Code:        `params["n"] * 3`,
+		Tests: []persistence.ToolTest{
+			{Name: "doubles", Params: map[string]interface{}{"n": 2}, Expected: 4},
+		},
+	})
+	persistence.SaveTool(&persistence.SavedToolDefinition{
+		Name:        "untested",
+		Description: "No tests",
+		Code:        `1`,
+	})
+
+	result, structured, err := handleTestSavedTool(context.Background(), nil, types.TestToolArgs{Name: "double"}, nil)
+	if err != nil {
+		t.Fatalf("handleTestSavedTool failed: %v", err)
+	}
+	verifyTextContent(t, result, "1 passed, 0 failed")
+	if report, ok := structured.(*tooltest.Report); !ok || report.Passed != 1 {
+		t.Errorf("Expected passing report, got %+v", structured)
+	}
+
+	result, _, _ = handleTestSavedTool(context.Background(), nil, types.TestToolArgs{Name: "broken"}, nil)
+	verifyTextContent(t, result, "0 passed, 1 failed")
+
+	result, _, _ = handleTestSavedTool(context.Background(), nil, types.TestToolArgs{Name: "untested"}, nil)
+	verifyTextContent(t, result, "has no tests")
+
+	result, _, _ = handleTestSavedTool(context.Background(), nil, types.TestToolArgs{Name: "missing"}, nil)
+	verifyTextContent(t, result, "Failed to load tool")
+
+	result, _, _ = handleTestSavedTool(context.Background(), nil, types.TestToolArgs{}, nil)
+	verifyTextContent(t, result, "tool name is required")
+}
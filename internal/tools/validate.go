@@ -0,0 +1,99 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/schema"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+// ValidateCallResponse reports whether arguments satisfy an upstream tool's input schema
+type ValidateCallResponse struct {
+	Server string `json:"server"`
+	Tool   string `json:"tool"`
+	Valid  bool   `json:"valid"`
+	Error  string `json:"error,omitempty"`
+
+	// Corrections describe how the arguments could be fixed, when coercing types and dropping
+	// unknown fields would make them valid; CorrectedArguments are the fixed arguments
+	Corrections        []string               `json:"corrections,omitempty"`
+	CorrectedArguments map[string]interface{} `json:"correctedArguments,omitempty"`
+}
+
+// RegisterValidateCall registers the validate_call tool with the MCP server
+func RegisterValidateCall(server *mcp.Server, proxyManager ProxyManager) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "validate_call",
+		Description:  "Check arguments against an upstream tool's input schema without calling it, suggesting corrections for invalid arguments",
+		OutputSchema: outputSchema[ValidateCallResponse](),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.ValidateCallArgs) (*mcp.CallToolResult, any, error) {
+		return handleValidateCall(args, proxyManager)
+	})
+}
+
+func handleValidateCall(args types.ValidateCallArgs, proxyManager ProxyManager) (*mcp.CallToolResult, any, error) {
+	if args.Server == "" || args.Tool == "" {
+		return ErrorResponse("Error: server and tool are required"), nil, nil
+	}
+	if proxyManager == nil {
+		return ErrorResponse("Error: server %s is not configured", args.Server), nil, nil
+	}
+
+	tool, err := findUpstreamTool(proxyManager, args.Server, args.Tool)
+	if err != nil {
+		return ErrorResponse("Error: %v", err), nil, nil
+	}
+
+	arguments := args.Arguments
+	if arguments == nil {
+		arguments = map[string]interface{}{}
+	}
+	response := ValidateCallResponse{Server: args.Server, Tool: args.Tool, Valid: true}
+
+	// Check against the schema as it is advertised, after the same transformation proxied tools get
+	inputSchema := schema.SafeTransform(tool.InputSchema, fmt.Sprintf("tool %s", tool.Name))
+	if inputSchema == nil {
+		return SuccessResponse("%s.%s has no input schema; any arguments are accepted", args.Server, args.Tool), response, nil
+	}
+	resolved, err := inputSchema.Resolve(nil)
+	if err != nil {
+		return ErrorResponse("Error: the input schema of %s.%s can't be checked: %v", args.Server, args.Tool, err), nil, nil
+	}
+	validationErr := resolved.Validate(arguments)
+	if validationErr == nil {
+		return SuccessResponse("Arguments are valid for %s.%s", args.Server, args.Tool), response, nil
+	}
+	response.Valid = false
+	response.Error = validationErr.Error()
+
+	text := fmt.Sprintf("Arguments are not valid for %s.%s: %s", args.Server, args.Tool, response.Error)
+	corrected, corrections := schema.Correct(inputSchema, arguments)
+	if len(corrections) > 0 && resolved.Validate(corrected) == nil {
+		response.Corrections = corrections
+		response.CorrectedArguments = corrected
+		text += fmt.Sprintf("\n\nThese corrections would make them valid: %s", strings.Join(corrections, "; "))
+	}
+	return SuccessResponse("%s", text), response, nil
+}
+
+// findUpstreamTool looks up a tool an upstream server offers, starting the server if it's started lazily
+func findUpstreamTool(proxyManager ProxyManager, serverName, toolName string) (*mcp.Tool, error) {
+	tools, connected := proxyManager.GetAllTools()[serverName]
+	if !connected {
+		var err error
+		if tools, err = proxy.EnsureStarted(proxyManager, serverName); err != nil {
+			return nil, err
+		}
+	}
+	for _, tool := range tools {
+		if tool.Name == toolName {
+			return tool, nil
+		}
+	}
+	return nil, fmt.Errorf("server %s has no tool named %s", serverName, toolName)
+}
@@ -0,0 +1,117 @@
+package tools
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/google/jsonschema-go/jsonschema"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+func TestHandleValidateCall(t *testing.T) {
+	mockProxy := NewMockProxyManager()
+	mockProxy.AddMockTool("github", &mcp.Tool{
+		Name: "get_issue",
+		InputSchema: &jsonschema.Schema{
+			Schema: "http://json-schema.org/draft-07/schema#",
+			Type:   "object",
+			Properties: map[string]*jsonschema.Schema{
+				"repo":   {Type: "string"},
+				"number": {Type: "integer"},
+			},
+			Required:             []string{"repo", "number"},
+			AdditionalProperties: &jsonschema.Schema{Not: &jsonschema.Schema{}},
+		},
+	})
+	mockProxy.AddMockTool("github", &mcp.Tool{Name: "whoami"})
+
+	tests := []struct {
+		name            string
+		args            types.ValidateCallArgs
+		wantError       string
+		wantValid       bool
+		wantCorrections []string
+		wantCorrected   map[string]interface{}
+	}{
+		{
+			name:      "valid arguments",
+			args:      types.ValidateCallArgs{Server: "github", Tool: "get_issue", Arguments: map[string]interface{}{"repo": "app", "number": 1.0}},
+			wantValid: true,
+		},
+		{
+			name:      "missing required argument",
+			args:      types.ValidateCallArgs{Server: "github", Tool: "get_issue", Arguments: map[string]interface{}{"repo": "app"}},
+			wantValid: false,
+		},
+		{
+			name:            "correctable arguments",
+			args:            types.ValidateCallArgs{Server: "github", Tool: "get_issue", Arguments: map[string]interface{}{"repo": "app", "number": "7", "verbose": true}},
+			wantValid:       false,
+			wantCorrections: []string{`converted "number" from string to integer`, `dropped unknown field "verbose"`},
+			wantCorrected:   map[string]interface{}{"repo": "app", "number": 7.0},
+		},
+		{
+			name:      "no input schema",
+			args:      types.ValidateCallArgs{Server: "github", Tool: "whoami", Arguments: map[string]interface{}{"anything": 1.0}},
+			wantValid: true,
+		},
+		{
+			name:      "unknown tool",
+			args:      types.ValidateCallArgs{Server: "github", Tool: "delete_repo"},
+			wantError: "has no tool named delete_repo",
+		},
+		{
+			name:      "unknown server",
+			args:      types.ValidateCallArgs{Server: "jira", Tool: "get_issue"},
+			wantError: "not connected",
+		},
+		{
+			name:      "missing tool",
+			args:      types.ValidateCallArgs{Server: "github"},
+			wantError: "server and tool are required",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, structured, err := handleValidateCall(tt.args, mockProxy)
+			if err != nil {
+				t.Fatalf("handleValidateCall() error = %v", err)
+			}
+			text := result.Content[0].(*mcp.TextContent).Text
+			if tt.wantError != "" {
+				if !result.IsError || !strings.Contains(text, tt.wantError) {
+					t.Errorf("handleValidateCall() = %q, want an error containing %q", text, tt.wantError)
+				}
+				return
+			}
+			if result.IsError {
+				t.Fatalf("handleValidateCall() unexpected error: %s", text)
+			}
+
+			response := structured.(ValidateCallResponse)
+			if response.Valid != tt.wantValid {
+				t.Errorf("Valid = %v, want %v (%s)", response.Valid, tt.wantValid, response.Error)
+			}
+			if !tt.wantValid && response.Error == "" {
+				t.Error("invalid arguments should report an error")
+			}
+			if !reflect.DeepEqual(response.Corrections, tt.wantCorrections) {
+				t.Errorf("Corrections = %v, want %v", response.Corrections, tt.wantCorrections)
+			}
+			if !reflect.DeepEqual(response.CorrectedArguments, tt.wantCorrected) {
+				t.Errorf("CorrectedArguments = %v, want %v", response.CorrectedArguments, tt.wantCorrected)
+			}
+		})
+	}
+}
+
+func TestHandleValidateCallWithoutServers(t *testing.T) {
+	result, _, _ := handleValidateCall(types.ValidateCallArgs{Server: "github", Tool: "get_issue"}, nil)
+	if !result.IsError {
+		t.Error("validate_call should fail when no servers are configured")
+	}
+}
@@ -0,0 +1,45 @@
+package tools
+
+import (
+	"context"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/types"
+	"github.com/dslh/mcp-metatool/internal/verify"
+)
+
+// RegisterVerifyTools registers the verify_tools tool with the MCP server
+func RegisterVerifyTools(server *mcp.Server, proxyManager ProxyManager, opts ...starlark.Option) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:         "verify_tools",
+		Description:  "Compile saved tools without running them and check the servers and tools they call still exist, listing any problems",
+		OutputSchema: outputSchema[verify.Report](),
+	}, func(ctx context.Context, req *mcp.CallToolRequest, args types.VerifyToolsArgs) (*mcp.CallToolResult, any, error) {
+		return handleVerifyTools(args, proxyManager, opts...)
+	})
+}
+
+func handleVerifyTools(args types.VerifyToolsArgs, proxyManager ProxyManager, opts ...starlark.Option) (*mcp.CallToolResult, any, error) {
+	var tools []*persistence.SavedToolDefinition
+	if len(args.Names) == 0 {
+		all, err := persistence.ListTools()
+		if err != nil {
+			return ErrorResponse("Failed to list saved tools: %v", err), nil, nil
+		}
+		tools = all
+	} else {
+		for _, name := range args.Names {
+			tool, err := persistence.LoadTool(name)
+			if err != nil {
+				return ErrorResponse("Error: failed to load tool '%s': %v", name, err), nil, nil
+			}
+			tools = append(tools, tool)
+		}
+	}
+
+	report := verify.Tools(tools, proxyManager, opts...)
+	return SuccessResponse("%s", report.Summary()), report, nil
+}
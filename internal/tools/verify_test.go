@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/types"
+	"github.com/dslh/mcp-metatool/internal/verify"
+)
+
+func TestHandleVerifyTools(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	createTestTool(t, "fetch", "Fetch an issue", `github.get_issue(number=1)`)
+	createTestTool(t, "stale", "Uses a removed tool", `github.fetch_issue(number=1)`)
+
+	proxyManager := NewMockProxyManager()
+	proxyManager.AddMockTool("github", &mcp.Tool{Name: "get_issue"})
+
+	result, structured, err := handleVerifyTools(types.VerifyToolsArgs{}, proxyManager)
+	if err != nil || result.IsError {
+		t.Fatalf("handleVerifyTools() error = %v, result = %+v", err, result)
+	}
+	verifyTextContent(t, result, "stale:1: calls github.fetch_issue, but server github has no tool named fetch_issue")
+	report := structured.(*verify.Report)
+	if report.Checked != 2 || len(report.Problems) != 1 || report.Problems[0].Kind != verify.KindUnknownTool {
+		t.Errorf("Unexpected report: %+v", report)
+	}
+
+	result, structured, _ = handleVerifyTools(types.VerifyToolsArgs{Names: []string{"fetch"}}, proxyManager)
+	verifyTextContent(t, result, "no problems found")
+	if report := structured.(*verify.Report); report.Checked != 1 {
+		t.Errorf("Expected only the named tool to be checked, got %+v", report)
+	}
+
+	result, _, _ = handleVerifyTools(types.VerifyToolsArgs{Names: []string{"missing"}}, proxyManager)
+	if !result.IsError {
+		t.Error("Expected an error for a missing tool")
+	}
+}
@@ -0,0 +1,118 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+// ToolVersionSummary represents a single archived version for list_tool_versions
+type ToolVersionSummary struct {
+	Version     int    `json:"version"`
+	Description string `json:"description"`
+}
+
+// ToolVersionsResponse wraps the version history in an object structure expected by MCP
+type ToolVersionsResponse struct {
+	Name           string               `json:"name"`
+	CurrentVersion int                  `json:"currentVersion,omitempty"`
+	Versions       []ToolVersionSummary `json:"versions"`
+}
+
+// RegisterListToolVersions registers the list_tool_versions tool with the MCP server
+func RegisterListToolVersions(server *mcp.Server) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:        "list_tool_versions",
+		Description: "List the prior versions kept for a saved tool",
+	}, handleListToolVersions)
+}
+
+// RegisterRollbackSavedTool registers the rollback_saved_tool tool with the MCP server
+func RegisterRollbackSavedTool(server *mcp.Server) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:        "rollback_saved_tool",
+		Description: "Restore a prior version of a saved tool as its current definition",
+	}, handleRollbackSavedTool)
+}
+
+// RegisterRestoreSavedTool registers the restore_saved_tool tool with the MCP server
+func RegisterRestoreSavedTool(server *mcp.Server) {
+	addBuiltinTool(server, &mcp.Tool{
+		Name:        "restore_saved_tool",
+		Description: "Restore the most recent backup of a saved tool, undoing the last overwrite or delete",
+	}, handleRestoreSavedTool)
+}
+
+func handleListToolVersions(ctx context.Context, req *mcp.CallToolRequest, args types.ListVersionsArgs) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" {
+		return ErrorResponse("Error: tool name is required"), nil, nil
+	}
+
+	versions, err := persistence.ListVersions(args.Name)
+	if err != nil {
+		return ErrorResponse("Failed to list versions of '%s': %v", args.Name, err), nil, nil
+	}
+
+	response := ToolVersionsResponse{Name: args.Name, Versions: []ToolVersionSummary{}}
+	if current, err := persistence.LoadTool(args.Name); err == nil {
+		response.CurrentVersion = current.Version
+	}
+
+	for _, version := range versions {
+		response.Versions = append(response.Versions, ToolVersionSummary{
+			Version:     version.Version,
+			Description: version.Description,
+		})
+	}
+
+	if len(response.Versions) == 0 {
+		return SuccessResponse("No prior versions of '%s' found", args.Name), response, nil
+	}
+
+	var versionList []string
+	for _, version := range response.Versions {
+		versionList = append(versionList, fmt.Sprintf("• v%d: %s", version.Version, version.Description))
+	}
+
+	listText := fmt.Sprintf("Found %d prior version(s) of '%s' (current: v%d):\n\n%s",
+		len(response.Versions), args.Name, response.CurrentVersion, strings.Join(versionList, "\n"))
+
+	return SuccessResponse(listText), response, nil
+}
+
+func handleRollbackSavedTool(ctx context.Context, req *mcp.CallToolRequest, args types.RollbackToolArgs) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" {
+		return ErrorResponse("Error: tool name is required"), nil, nil
+	}
+
+	if args.Version <= 0 {
+		return ErrorResponse("Error: a positive version number is required"), nil, nil
+	}
+
+	tool, err := persistence.RollbackTool(args.Name, args.Version)
+	if err != nil {
+		return ErrorResponse("Failed to roll back tool '%s': %v", args.Name, err), nil, nil
+	}
+
+	return SuccessResponse("Tool '%s' rolled back to version %d (saved as version %d). Restart server to use the restored definition.",
+		args.Name, args.Version, tool.Version), tool, nil
+}
+
+func handleRestoreSavedTool(ctx context.Context, req *mcp.CallToolRequest, args types.RestoreToolArgs) (*mcp.CallToolResult, any, error) {
+	if args.Name == "" {
+		return ErrorResponse("Error: tool name is required"), nil, nil
+	}
+
+	tool, err := persistence.RestoreTool(args.Name)
+	if err != nil {
+		return ErrorResponse("Failed to restore tool '%s': %v", args.Name, err), nil, nil
+	}
+
+	return SuccessResponse("Tool '%s' restored from backup (saved as version %d). Restart server to use the restored definition.",
+		args.Name, tool.Version), tool, nil
+}
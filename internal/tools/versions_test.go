@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"context"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/types"
+)
+
+func TestHandleListToolVersions(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	createTestTool(t, "evolving", "first", "1")
+
+	result, _, err := handleListToolVersions(context.Background(), nil, types.ListVersionsArgs{Name: "evolving"})
+	if err != nil {
+		t.Fatalf("handleListToolVersions failed: %v", err)
+	}
+	verifyTextContent(t, result, "No prior versions")
+
+	createTestTool(t, "evolving", "second", "2")
+
+	result, structured, _ := handleListToolVersions(context.Background(), nil, types.ListVersionsArgs{Name: "evolving"})
+	verifyTextContent(t, result, "v1: first")
+
+	response, ok := structured.(ToolVersionsResponse)
+	if !ok {
+		t.Fatalf("Expected ToolVersionsResponse, got %T", structured)
+	}
+	if response.CurrentVersion != 2 || len(response.Versions) != 1 {
+		t.Errorf("Unexpected response: %+v", response)
+	}
+
+	result, _, _ = handleListToolVersions(context.Background(), nil, types.ListVersionsArgs{})
+	verifyTextContent(t, result, "tool name is required")
+}
+
+func TestHandleRollbackSavedTool(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	createTestTool(t, "evolving", "good", "'good'")
+	createTestTool(t, "evolving", "bad", "'bad'")
+
+	result, _, err := handleRollbackSavedTool(context.Background(), nil, types.RollbackToolArgs{Name: "evolving", Version: 1})
+	if err != nil {
+		t.Fatalf("handleRollbackSavedTool failed: %v", err)
+	}
+	verifyTextContent(t, result, "rolled back to version 1")
+
+	current, _ := persistence.LoadTool("evolving")
+	if current.Code != "'good'" {
+		t.Errorf("Expected restored code, got %q", current.Code)
+	}
+
+	result, _, _ = handleRollbackSavedTool(context.Background(), nil, types.RollbackToolArgs{Name: "evolving", Version: 99})
+	verifyTextContent(t, result, "Failed to roll back")
+
+	result, _, _ = handleRollbackSavedTool(context.Background(), nil, types.RollbackToolArgs{Name: "evolving"})
+	verifyTextContent(t, result, "positive version number")
+}
+
+func TestHandleRestoreSavedTool(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	createTestTool(t, "oops", "deleted by accident", "'hi'")
+	persistence.DeleteTool("oops")
+
+	result, _, err := handleRestoreSavedTool(context.Background(), nil, types.RestoreToolArgs{Name: "oops"})
+	if err != nil {
+		t.Fatalf("handleRestoreSavedTool failed: %v", err)
+	}
+	verifyTextContent(t, result, "restored from backup")
+
+	if _, err := persistence.LoadTool("oops"); err != nil {
+		t.Errorf("Expected tool to be restored: %v", err)
+	}
+
+	result, _, _ = handleRestoreSavedTool(context.Background(), nil, types.RestoreToolArgs{Name: "never_existed"})
+	verifyTextContent(t, result, "Failed to restore")
+
+	result, _, _ = handleRestoreSavedTool(context.Background(), nil, types.RestoreToolArgs{})
+	verifyTextContent(t, result, "tool name is required")
+}
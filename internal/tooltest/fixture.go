@@ -0,0 +1,258 @@
+package tooltest
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// Fixture holds the upstream calls made by one run of a saved tool, so the run can be replayed offline
+type Fixture struct {
+	Tool    string                   `json:"tool,omitempty"`
+	Params  map[string]interface{}   `json:"params,omitempty"`
+	Servers map[string]FixtureServer `json:"servers"`
+	Calls   []FixtureCall            `json:"calls"`
+}
+
+// FixtureServer records what code saw of an upstream server: its tools and how they were named
+type FixtureServer struct {
+	Prefix  string            `json:"prefix,omitempty"`
+	Aliases map[string]string `json:"aliases,omitempty"`
+	Tools   []*mcp.Tool       `json:"tools"`
+}
+
+// FixtureCall is a recorded upstream call and its response
+type FixtureCall struct {
+	Server    string                 `json:"server"`
+	Tool      string                 `json:"tool"`
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Result    *mcp.CallToolResult    `json:"result,omitempty"`
+	Error     string                 `json:"error,omitempty"`
+}
+
+// FixturesEnvVar names a fixtures file to answer upstream calls in tests without mocks of their own,
+// so they don't need live servers or credentials
+const FixturesEnvVar = "MCP_METATOOL_FIXTURES"
+
+// LoadFixtureProxy creates a proxy manager serving the canned responses in a fixtures file. The file
+// is either a fixture recorded with mcp-metatool run --record, or an object mapping "server.tool" to
+// structured responses, like test mocks.
+func LoadFixtureProxy(path string) (proxy.ProxyManager, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %w", err)
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, fmt.Errorf("invalid fixtures %s: %w", path, err)
+	}
+
+	// Mock keys always contain a dot, so can't be mistaken for a recorded fixture's calls
+	if _, recorded := fields["calls"]; recorded {
+		var fixture Fixture
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			return nil, fmt.Errorf("invalid fixtures %s: %w", path, err)
+		}
+		return NewReplayer(&fixture), nil
+	}
+	var mocks map[string]interface{}
+	if err := json.Unmarshal(data, &mocks); err != nil {
+		return nil, fmt.Errorf("invalid fixtures %s: %w", path, err)
+	}
+	mockProxy, err := NewMockProxy(mocks)
+	if err != nil {
+		return nil, fmt.Errorf("invalid fixtures %s: %w", path, err)
+	}
+	return mockProxy, nil
+}
+
+// FixtureProxyFromEnv loads the fixtures file named by the environment, returning nil if there is none
+func FixtureProxyFromEnv() (proxy.ProxyManager, error) {
+	path := os.Getenv(FixturesEnvVar)
+	if path == "" {
+		return nil, nil
+	}
+	return LoadFixtureProxy(path)
+}
+
+// LoadFixture reads a fixture file
+func LoadFixture(path string) (*Fixture, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixture: %w", err)
+	}
+	var fixture Fixture
+	if err := json.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("invalid fixture %s: %w", path, err)
+	}
+	return &fixture, nil
+}
+
+// Save writes the fixture to a file
+func (f *Fixture) Save(path string) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode fixture: %w", err)
+	}
+	if err := storage.WriteDiskFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write fixture: %w", err)
+	}
+	return nil
+}
+
+// Recorder wraps a proxy manager, recording every upstream call and its response
+type Recorder struct {
+	proxyManager proxy.ProxyManager
+	mu           sync.Mutex
+	calls        []FixtureCall
+}
+
+// NewRecorder places a recorder in front of the given proxy manager
+func NewRecorder(proxyManager proxy.ProxyManager) *Recorder {
+	return &Recorder{proxyManager: proxyManager}
+}
+
+// GetAllTools passes through to the wrapped proxy manager
+func (r *Recorder) GetAllTools() map[string][]*mcp.Tool {
+	return r.proxyManager.GetAllTools()
+}
+
+// PendingServers passes through to the wrapped proxy manager
+func (r *Recorder) PendingServers() []string {
+	return proxy.PendingServers(r.proxyManager)
+}
+
+// EnsureStarted passes through to the wrapped proxy manager
+func (r *Recorder) EnsureStarted(serverName string) ([]*mcp.Tool, error) {
+	return proxy.EnsureStarted(r.proxyManager, serverName)
+}
+
+// ToolPrefix passes through to the wrapped proxy manager
+func (r *Recorder) ToolPrefix(serverName string) string {
+	return proxy.ToolPrefix(r.proxyManager, serverName)
+}
+
+// ToolAliases passes through to the wrapped proxy manager
+func (r *Recorder) ToolAliases(serverName string) map[string]string {
+	return proxy.ToolAliases(r.proxyManager, serverName)
+}
+
+// CallTool calls the wrapped proxy manager and records the call
+func (r *Recorder) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return r.CallToolContext(context.Background(), serverName, toolName, arguments)
+}
+
+// CallToolContext is CallTool, forwarding ctx to the wrapped proxy manager
+func (r *Recorder) CallToolContext(ctx context.Context, serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	result, err := proxy.CallToolContext(ctx, r.proxyManager, serverName, toolName, arguments)
+
+	call := FixtureCall{Server: serverName, Tool: toolName, Arguments: arguments, Result: result}
+	if err != nil {
+		call.Error = err.Error()
+	}
+	r.mu.Lock()
+	r.calls = append(r.calls, call)
+	r.mu.Unlock()
+
+	return result, err
+}
+
+// Fixture returns the calls recorded so far, along with the servers known to the wrapped
+// proxy manager, so code referring to servers it didn't call still compiles on replay
+func (r *Recorder) Fixture(toolName string, params map[string]interface{}) *Fixture {
+	fixture := &Fixture{Tool: toolName, Params: params, Servers: make(map[string]FixtureServer), Calls: []FixtureCall{}}
+	for serverName, tools := range r.proxyManager.GetAllTools() {
+		server := FixtureServer{Tools: tools, Aliases: proxy.ToolAliases(r.proxyManager, serverName)}
+		if prefix := proxy.ToolPrefix(r.proxyManager, serverName); prefix != serverName {
+			server.Prefix = prefix
+		}
+		fixture.Servers[serverName] = server
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	fixture.Calls = append(fixture.Calls, r.calls...)
+	return fixture
+}
+
+// Replayer serves the responses recorded in a fixture in place of upstream servers.
+// A call is answered by the first unused recording with the same server, tool and arguments;
+// once those are used up, the last of them is repeated.
+type Replayer struct {
+	fixture *Fixture
+	mu      sync.Mutex
+	used    []bool
+}
+
+// NewReplayer creates a proxy manager that replays the given fixture
+func NewReplayer(fixture *Fixture) *Replayer {
+	return &Replayer{fixture: fixture, used: make([]bool, len(fixture.Calls))}
+}
+
+// GetAllTools returns the tools recorded for each server
+func (r *Replayer) GetAllTools() map[string][]*mcp.Tool {
+	tools := make(map[string][]*mcp.Tool, len(r.fixture.Servers))
+	for serverName, server := range r.fixture.Servers {
+		tools[serverName] = server.Tools
+	}
+	return tools
+}
+
+// ToolPrefix returns the recorded prefix of a server's tools
+func (r *Replayer) ToolPrefix(serverName string) string {
+	if prefix := r.fixture.Servers[serverName].Prefix; prefix != "" {
+		return prefix
+	}
+	return serverName
+}
+
+// ToolAliases returns the recorded aliases of a server's tools
+func (r *Replayer) ToolAliases(serverName string) map[string]string {
+	return r.fixture.Servers[serverName].Aliases
+}
+
+// CallTool returns the recorded response to a matching call
+func (r *Replayer) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	last := -1
+	for i, call := range r.fixture.Calls {
+		if call.Server != serverName || call.Tool != toolName {
+			continue
+		}
+		if !sameArguments(call.Arguments, arguments) {
+			continue
+		}
+		last = i
+		if !r.used[i] {
+			break
+		}
+	}
+	if last < 0 {
+		return nil, fmt.Errorf("no recorded response for %s.%s with these arguments", serverName, toolName)
+	}
+
+	r.used[last] = true
+	call := r.fixture.Calls[last]
+	if call.Error != "" {
+		return nil, fmt.Errorf("%s", call.Error)
+	}
+	return call.Result, nil
+}
+
+// sameArguments reports whether two calls' arguments are equal once normalized through JSON
+func sameArguments(a, b map[string]interface{}) bool {
+	if len(a) == 0 && len(b) == 0 {
+		return true
+	}
+	equal, err := jsonEqual(a, b)
+	return err == nil && equal
+}
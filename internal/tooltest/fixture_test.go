@@ -0,0 +1,118 @@
+package tooltest
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/starlark"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	upstream, err := NewMockProxy(map[string]interface{}{
+		"github.get_user":  map[string]interface{}{"public_repos": 3},
+		"slack.post":       map[string]interface{}{"ok": true},
+		"github.get_issue": map[string]interface{}{"title": "Bug"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := `user = github.get_user({"login": params["login"]})
+again = github.get_user({"login": params["login"]})
+if params.get("notify"):
+    slack.post({"text": "hi"})
+result = user["structured"]["public_repos"] + again["structured"]["public_repos"]`
+	params := map[string]interface{}{"login": "alice"}
+
+	recorder := NewRecorder(upstream)
+	recorded, err := starlark.ExecuteWithProxy(code, params, recorder)
+	if err != nil || recorded.Error != "" {
+		t.Fatalf("Recording run failed: %v %s", err, recorded.Error)
+	}
+
+	path := filepath.Join(t.TempDir(), "fixture.json")
+	if err := recorder.Fixture("count_repos", params).Save(path); err != nil {
+		t.Fatalf("Save() error = %v", err)
+	}
+	fixture, err := LoadFixture(path)
+	if err != nil {
+		t.Fatalf("LoadFixture() error = %v", err)
+	}
+	if len(fixture.Calls) != 2 || len(fixture.Servers) != 2 || fixture.Params["login"] != "alice" {
+		t.Fatalf("Unexpected fixture: %+v", fixture)
+	}
+
+	replayed, err := starlark.ExecuteWithProxy(code, fixture.Params, NewReplayer(fixture))
+	if err != nil || replayed.Error != "" {
+		t.Fatalf("Replay failed: %v %s", err, replayed.Error)
+	}
+	if equal, _ := jsonEqual(replayed.Result, recorded.Result); !equal {
+		t.Errorf("Replayed result = %v, want %v", replayed.Result, recorded.Result)
+	}
+
+	// Calls that weren't recorded fail rather than reaching a server
+	for _, params := range []map[string]interface{}{{"login": "bob"}, {"login": "alice", "notify": true}} {
+		replayed, err = starlark.ExecuteWithProxy(code, params, NewReplayer(fixture))
+		if err != nil || !strings.Contains(replayed.Error, "no recorded response for") {
+			t.Errorf("Expected a missing recording error for %v, got %v %q", params, err, replayed.Error)
+		}
+	}
+}
+
+func TestReplayerOrder(t *testing.T) {
+	fixture := &Fixture{Calls: []FixtureCall{
+		{Server: "queue", Tool: "pop", Error: "first"},
+		{Server: "queue", Tool: "pop", Error: "second"},
+	}}
+	replayer := NewReplayer(fixture)
+	for _, want := range []string{"first", "second", "second"} {
+		if _, err := replayer.CallTool("queue", "pop", map[string]interface{}{}); err == nil || err.Error() != want {
+			t.Errorf("CallTool() error = %v, want %q", err, want)
+		}
+	}
+}
+
+func TestLoadFixtureProxy(t *testing.T) {
+	dir := t.TempDir()
+	recorded := filepath.Join(dir, "recorded.json")
+	(&Fixture{Calls: []FixtureCall{{Server: "github", Tool: "get_issue", Error: "not found"}}}).Save(recorded)
+	files := map[string]string{
+		"mocks.json":   `{"github.get_issue": {"title": "Bug"}}`,
+		"bad_key.json": `{"get_issue": {}}`,
+		"invalid.json": `[1, 2]`,
+	}
+	for name, content := range files {
+		os.WriteFile(filepath.Join(dir, name), []byte(content), 0644)
+	}
+
+	tests := []struct {
+		file      string
+		wantType  interface{}
+		wantError string
+	}{
+		{"recorded.json", &Replayer{}, ""},
+		{"mocks.json", &MockProxy{}, ""},
+		{"bad_key.json", nil, "invalid mock key"},
+		{"invalid.json", nil, "invalid fixtures"},
+		{"missing.json", nil, "failed to read fixtures"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			proxyManager, err := LoadFixtureProxy(filepath.Join(dir, tt.file))
+			if tt.wantError != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantError) {
+					t.Errorf("LoadFixtureProxy() error = %v, want it to contain %q", err, tt.wantError)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("LoadFixtureProxy() error = %v", err)
+			}
+			if fmt.Sprintf("%T", proxyManager) != fmt.Sprintf("%T", tt.wantType) {
+				t.Errorf("LoadFixtureProxy() = %T, want %T", proxyManager, tt.wantType)
+			}
+		})
+	}
+}
@@ -0,0 +1,71 @@
+package tooltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// MockCall records an upstream call made against a MockProxy
+type MockCall struct {
+	Tool      string                 `json:"tool"` // "server.tool"
+	Arguments map[string]interface{} `json:"arguments,omitempty"`
+}
+
+// MockProxy serves canned responses for upstream tool calls
+type MockProxy struct {
+	responses map[string]interface{} // "server.tool" -> structured response
+	tools     map[string][]*mcp.Tool
+	calls     []MockCall
+}
+
+// NewMockProxy creates a mock proxy from a map of "server.tool" keys to structured responses
+func NewMockProxy(mocks map[string]interface{}) (*MockProxy, error) {
+	m := &MockProxy{
+		responses: make(map[string]interface{}),
+		tools:     make(map[string][]*mcp.Tool),
+	}
+
+	for key, response := range mocks {
+		serverName, toolName, ok := strings.Cut(key, ".")
+		if !ok || serverName == "" || toolName == "" {
+			return nil, fmt.Errorf("invalid mock key '%s': expected 'server.tool'", key)
+		}
+		m.responses[key] = response
+		m.tools[serverName] = append(m.tools[serverName], &mcp.Tool{Name: toolName})
+	}
+
+	return m, nil
+}
+
+// GetAllTools returns the tools implied by the mock keys
+func (m *MockProxy) GetAllTools() map[string][]*mcp.Tool {
+	return m.tools
+}
+
+// CallTool returns the canned response for the given tool
+func (m *MockProxy) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	m.calls = append(m.calls, MockCall{Tool: serverName + "." + toolName, Arguments: arguments})
+
+	response, exists := m.responses[serverName+"."+toolName]
+	if !exists {
+		return nil, fmt.Errorf("no mock response for %s.%s", serverName, toolName)
+	}
+
+	text, err := json.Marshal(response)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode mock response: %w", err)
+	}
+
+	return &mcp.CallToolResult{
+		Content:           []mcp.Content{&mcp.TextContent{Text: string(text)}},
+		StructuredContent: response,
+	}, nil
+}
+
+// Calls returns the upstream calls made so far, in order
+func (m *MockProxy) Calls() []MockCall {
+	return m.calls
+}
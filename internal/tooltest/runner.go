@@ -0,0 +1,161 @@
+package tooltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/validation"
+)
+
+// CaseResult is the outcome of a single embedded test case
+type CaseResult struct {
+	Name   string      `json:"name"`
+	Passed bool        `json:"passed"`
+	Error  string      `json:"error,omitempty"`
+	Actual interface{} `json:"actual,omitempty"`
+}
+
+// Report summarizes the results of running a saved tool's tests
+type Report struct {
+	Tool   string       `json:"tool"`
+	Passed int          `json:"passed"`
+	Failed int          `json:"failed"`
+	Cases  []CaseResult `json:"cases"`
+}
+
+// Run executes every embedded test of a saved tool
+// Tests that declare mocks run against a MockProxy; the others use proxyManager, which may be nil
+func Run(tool *persistence.SavedToolDefinition, proxyManager proxy.ProxyManager, opts ...starlark.Option) *Report {
+	report := &Report{Tool: tool.Name, Cases: []CaseResult{}}
+
+	for i, test := range tool.Tests {
+		caseResult := RunCase(tool, test, proxyManager, opts...)
+		if caseResult.Name == "" {
+			caseResult.Name = fmt.Sprintf("test %d", i+1)
+		}
+		if caseResult.Passed {
+			report.Passed++
+		} else {
+			report.Failed++
+		}
+		report.Cases = append(report.Cases, caseResult)
+	}
+
+	return report
+}
+
+// RunCase executes a single test case and checks its expectations
+func RunCase(tool *persistence.SavedToolDefinition, test persistence.ToolTest, proxyManager proxy.ProxyManager, opts ...starlark.Option) CaseResult {
+	caseResult := CaseResult{Name: test.Name}
+
+	params := test.Params
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	if err := validation.ValidateParams(tool.InputSchema, params); err != nil {
+		caseResult.Error = validation.FormatValidationError(err)
+		return caseResult
+	}
+
+	if len(test.Mocks) > 0 {
+		mockProxy, err := NewMockProxy(test.Mocks)
+		if err != nil {
+			caseResult.Error = err.Error()
+			return caseResult
+		}
+		proxyManager = mockProxy
+	}
+
+	var starlarkProxy starlark.ProxyManager
+	if proxyManager != nil {
+		starlarkProxy = proxyManager
+	}
+
+	opts = append([]starlark.Option{starlark.WithPermissions(tool.Permissions...), starlark.WithAllowedServers(tool.AllowedServers...), starlark.WithFileName(tool.Name)}, opts...)
+	result, err := starlark.ExecuteWithProxy(tool.Code, params, starlarkProxy, opts...)
+	if err != nil {
+		caseResult.Error = fmt.Sprintf("execution failed: %v", err)
+		return caseResult
+	}
+	if result.Error != "" {
+		caseResult.Error = result.Error
+		return caseResult
+	}
+	caseResult.Actual = result.Result
+
+	if test.Expected != nil {
+		equal, err := jsonEqual(result.Result, test.Expected)
+		if err != nil {
+			caseResult.Error = err.Error()
+			return caseResult
+		}
+		if !equal {
+			caseResult.Error = "result does not match expected value"
+			return caseResult
+		}
+	}
+
+	for _, assertion := range test.Assertions {
+		ok, err := starlark.EvalCondition(assertion, map[string]interface{}{
+			"result": result.Result,
+			"params": params,
+		})
+		if err != nil {
+			caseResult.Error = fmt.Sprintf("assertion %q failed to evaluate: %v", assertion, err)
+			return caseResult
+		}
+		if !ok {
+			caseResult.Error = fmt.Sprintf("assertion %q failed", assertion)
+			return caseResult
+		}
+	}
+
+	caseResult.Passed = true
+	return caseResult
+}
+
+// jsonEqual compares two values after normalizing them through JSON
+// so that e.g. int64 results compare equal to float64 expectations
+func jsonEqual(a, b interface{}) (bool, error) {
+	normalize := func(v interface{}) (interface{}, error) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("failed to normalize value: %w", err)
+		}
+		var out interface{}
+		if err := json.Unmarshal(data, &out); err != nil {
+			return nil, fmt.Errorf("failed to normalize value: %w", err)
+		}
+		return out, nil
+	}
+
+	na, err := normalize(a)
+	if err != nil {
+		return false, err
+	}
+	nb, err := normalize(b)
+	if err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(na, nb), nil
+}
+
+// Summary renders the report as human-readable text
+func (r *Report) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d passed, %d failed", r.Tool, r.Passed, r.Failed)
+	for _, c := range r.Cases {
+		if c.Passed {
+			fmt.Fprintf(&b, "\n  ✓ %s", c.Name)
+		} else {
+			fmt.Fprintf(&b, "\n  ✗ %s: %s", c.Name, c.Error)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,102 @@
+package tooltest
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+)
+
+func TestRun(t *testing.T) {
+	tool := &persistence.SavedToolDefinition{
+		Name: "lookup_user",
+		InputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"login": map[string]interface{}{"type": "string"},
+			},
+			"required": []interface{}{"login"},
+		},
+		Code: `user = github.get_user({"login": params["login"]})
+result = {"login": params["login"], "repos": user["structured"]["public_repos"]}`,
+		Tests: []persistence.ToolTest{
+			{
+				Name:     "expected value",
+				Params:   map[string]interface{}{"login": "alice"},
+				Expected: map[string]interface{}{"login": "alice", "repos": 3},
+				Mocks:    map[string]interface{}{"github.get_user": map[string]interface{}{"public_repos": 3}},
+			},
+			{
+				Name:       "assertions",
+				Params:     map[string]interface{}{"login": "bob"},
+				Assertions: []string{`result["repos"] > 1`, `result["login"] == params["login"]`},
+				Mocks:      map[string]interface{}{"github.get_user": map[string]interface{}{"public_repos": 7}},
+			},
+			{
+				Name:     "wrong expectation",
+				Params:   map[string]interface{}{"login": "carol"},
+				Expected: map[string]interface{}{"login": "carol", "repos": 1},
+				Mocks:    map[string]interface{}{"github.get_user": map[string]interface{}{"public_repos": 2}},
+			},
+			{
+				Name:       "failing assertion",
+				Params:     map[string]interface{}{"login": "dave"},
+				Assertions: []string{`result["repos"] > 100`},
+				Mocks:      map[string]interface{}{"github.get_user": map[string]interface{}{"public_repos": 2}},
+			},
+			{
+				Name:   "invalid params",
+				Params: map[string]interface{}{},
+				Mocks:  map[string]interface{}{"github.get_user": map[string]interface{}{}},
+			},
+			{
+				Name:   "missing mock",
+				Params: map[string]interface{}{"login": "erin"},
+				Mocks:  map[string]interface{}{"github.other": map[string]interface{}{}},
+			},
+		},
+	}
+
+	report := Run(tool, nil)
+
+	if report.Passed != 2 || report.Failed != 4 {
+		t.Fatalf("Expected 2 passed and 4 failed, got %d passed and %d failed: %+v", report.Passed, report.Failed, report.Cases)
+	}
+
+	wantPassed := []bool{true, true, false, false, false, false}
+	for i, c := range report.Cases {
+		if c.Passed != wantPassed[i] {
+			t.Errorf("Case %q passed = %v, want %v (error: %s)", c.Name, c.Passed, wantPassed[i], c.Error)
+		}
+	}
+
+	summary := report.Summary()
+	if !strings.Contains(summary, "lookup_user: 2 passed, 4 failed") {
+		t.Errorf("Unexpected summary: %s", summary)
+	}
+	if !strings.Contains(summary, "✗ failing assertion") {
+		t.Errorf("Summary should list failing cases: %s", summary)
+	}
+}
+
+func TestRunUnnamedCase(t *testing.T) {
+	tool := &persistence.SavedToolDefinition{
+		Name:  "add",
+		Code:  `params["a"] + params["b"]`,
+		Tests: []persistence.ToolTest{{Params: map[string]interface{}{"a": 1, "b": 2}, Expected: 3}},
+	}
+
+	report := Run(tool, nil)
+	if report.Passed != 1 {
+		t.Fatalf("Expected test to pass, got %+v", report.Cases)
+	}
+	if report.Cases[0].Name != "test 1" {
+		t.Errorf("Expected default case name, got %q", report.Cases[0].Name)
+	}
+}
+
+func TestNewMockProxyInvalidKey(t *testing.T) {
+	if _, err := NewMockProxy(map[string]interface{}{"no_dot": nil}); err == nil {
+		t.Error("Expected error for mock key without server prefix")
+	}
+}
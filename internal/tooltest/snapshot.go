@@ -0,0 +1,228 @@
+package tooltest
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/storage"
+	"github.com/dslh/mcp-metatool/internal/validation"
+)
+
+// Snapshot fixture layout: <dir>/<tool>/<case>.json holds a SnapshotCase and
+// <dir>/<tool>/<case>.golden the expected output. An optional <dir>/<tool>/tool.json
+// holds the tool definition; otherwise the saved tool of that name is used.
+const (
+	toolFixtureFile = "tool.json"
+	goldenSuffix    = ".golden"
+)
+
+// SnapshotCase is the input of a single snapshot run
+type SnapshotCase struct {
+	Params map[string]interface{} `json:"params,omitempty"`
+	Mocks  map[string]interface{} `json:"mocks,omitempty"` // "server.tool" -> structured response
+}
+
+// SnapshotOutput is the deterministic record compared against golden files
+type SnapshotOutput struct {
+	Result interface{} `json:"result,omitempty"`
+	Error  string      `json:"error,omitempty"`
+	Calls  []MockCall  `json:"calls"`
+}
+
+// SnapshotResult is the outcome of comparing one case against its golden file
+type SnapshotResult struct {
+	Tool    string `json:"tool"`
+	Case    string `json:"case"`
+	Passed  bool   `json:"passed"`
+	Updated bool   `json:"updated,omitempty"`
+	Diff    string `json:"diff,omitempty"`
+	Error   string `json:"error,omitempty"`
+}
+
+// RunSnapshots runs every snapshot case under dir against fixture proxies and compares the
+// output with the golden files. With update set, golden files are (re)written instead.
+func RunSnapshots(dir string, update bool, opts ...starlark.Option) ([]SnapshotResult, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read snapshot directory: %w", err)
+	}
+
+	var results []SnapshotResult
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		toolDir := filepath.Join(dir, entry.Name())
+		tool, err := loadSnapshotTool(toolDir, entry.Name())
+		if err != nil {
+			results = append(results, SnapshotResult{Tool: entry.Name(), Error: err.Error()})
+			continue
+		}
+
+		cases, err := snapshotCases(toolDir)
+		if err != nil {
+			return nil, err
+		}
+		for _, caseName := range cases {
+			results = append(results, runSnapshotCase(tool, toolDir, caseName, update, opts))
+		}
+	}
+
+	return results, nil
+}
+
+// CheckSnapshots runs the snapshot cases under dir as subtests, failing with a diff on mismatch.
+// Set UPDATE_SNAPSHOTS=1 to rewrite the golden files from the current output.
+func CheckSnapshots(t *testing.T, dir string, opts ...starlark.Option) {
+	t.Helper()
+
+	results, err := RunSnapshots(dir, os.Getenv("UPDATE_SNAPSHOTS") != "", opts...)
+	if err != nil {
+		t.Fatalf("failed to run snapshots: %v", err)
+	}
+
+	for _, result := range results {
+		result := result
+		t.Run(result.Tool+"/"+result.Case, func(t *testing.T) {
+			switch {
+			case result.Error != "":
+				t.Error(result.Error)
+			case !result.Passed:
+				t.Errorf("output does not match golden file:\n%s", result.Diff)
+			}
+		})
+	}
+}
+
+// RenderSnapshot executes a tool for one case and renders its output as indented JSON
+func RenderSnapshot(tool *persistence.SavedToolDefinition, snapshotCase SnapshotCase, opts ...starlark.Option) (string, error) {
+	mockProxy, err := NewMockProxy(snapshotCase.Mocks)
+	if err != nil {
+		return "", err
+	}
+
+	params := snapshotCase.Params
+	if params == nil {
+		params = map[string]interface{}{}
+	}
+
+	output := SnapshotOutput{}
+	if err := validation.ValidateParams(tool.InputSchema, params); err != nil {
+		output.Error = validation.FormatValidationError(err)
+	} else {
+		result, err := starlark.ExecuteWithProxy(tool.Code, params, mockProxy, opts...)
+		if err != nil {
+			output.Error = fmt.Sprintf("execution failed: %v", err)
+		} else if result.Error != "" {
+			output.Error = result.Error
+		} else {
+			output.Result = result.Result
+		}
+	}
+
+	output.Calls = mockProxy.Calls()
+	if output.Calls == nil {
+		output.Calls = []MockCall{}
+	}
+
+	data, err := json.MarshalIndent(output, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to render snapshot: %w", err)
+	}
+	return string(data) + "\n", nil
+}
+
+// runSnapshotCase renders one case and compares it with (or writes) its golden file
+func runSnapshotCase(tool *persistence.SavedToolDefinition, toolDir, caseName string, update bool, opts []starlark.Option) SnapshotResult {
+	result := SnapshotResult{Tool: tool.Name, Case: caseName}
+
+	data, err := os.ReadFile(filepath.Join(toolDir, caseName+".json"))
+	if err != nil {
+		result.Error = fmt.Sprintf("failed to read case: %v", err)
+		return result
+	}
+
+	var snapshotCase SnapshotCase
+	if err := json.Unmarshal(data, &snapshotCase); err != nil {
+		result.Error = fmt.Sprintf("failed to parse case: %v", err)
+		return result
+	}
+
+	actual, err := RenderSnapshot(tool, snapshotCase, opts...)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+
+	goldenPath := filepath.Join(toolDir, caseName+goldenSuffix)
+	if update {
+		if err := storage.WriteDiskFile(goldenPath, []byte(actual), 0644); err != nil {
+			result.Error = fmt.Sprintf("failed to write golden file: %v", err)
+			return result
+		}
+		result.Passed = true
+		result.Updated = true
+		return result
+	}
+
+	expected, err := os.ReadFile(goldenPath)
+	if err != nil {
+		result.Error = fmt.Sprintf("missing golden file (run with update to create it): %v", err)
+		return result
+	}
+
+	if string(expected) != actual {
+		result.Diff = Diff(string(expected), actual)
+		return result
+	}
+
+	result.Passed = true
+	return result
+}
+
+// loadSnapshotTool loads the tool definition from the fixture directory, or the saved tool of the same name
+func loadSnapshotTool(toolDir, name string) (*persistence.SavedToolDefinition, error) {
+	data, err := os.ReadFile(filepath.Join(toolDir, toolFixtureFile))
+	if os.IsNotExist(err) {
+		return persistence.LoadTool(name)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", toolFixtureFile, err)
+	}
+
+	var tool persistence.SavedToolDefinition
+	if err := json.Unmarshal(data, &tool); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", toolFixtureFile, err)
+	}
+	if tool.Name == "" {
+		tool.Name = name
+	}
+	return &tool, nil
+}
+
+// snapshotCases lists the case names in a tool's fixture directory
+func snapshotCases(toolDir string) ([]string, error) {
+	entries, err := os.ReadDir(toolDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", toolDir, err)
+	}
+
+	var cases []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || name == toolFixtureFile || !strings.HasSuffix(name, ".json") {
+			continue
+		}
+		cases = append(cases, strings.TrimSuffix(name, ".json"))
+	}
+	sort.Strings(cases)
+	return cases, nil
+}
@@ -0,0 +1,73 @@
+package tooltest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSnapshots(t *testing.T) {
+	CheckSnapshots(t, filepath.Join("testdata", "snapshots"))
+}
+
+func TestRunSnapshots_UpdateAndMismatch(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	dir := t.TempDir()
+	toolDir := filepath.Join(dir, "double")
+	os.MkdirAll(toolDir, 0755)
+	os.WriteFile(filepath.Join(toolDir, "tool.json"), []byte(`{"code": "params[\"n\"] * 2"}`), 0644)
+	os.WriteFile(filepath.Join(toolDir, "two.json"), []byte(`{"params": {"n": 2}}`), 0644)
+
+	results, err := RunSnapshots(dir, false)
+	if err != nil {
+		t.Fatalf("RunSnapshots() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Passed || !strings.Contains(results[0].Error, "missing golden file") {
+		t.Fatalf("Expected missing golden failure, got %+v", results)
+	}
+
+	results, err = RunSnapshots(dir, true)
+	if err != nil || len(results) != 1 || !results[0].Updated {
+		t.Fatalf("Expected golden file to be written, got %+v, %v", results, err)
+	}
+
+	results, _ = RunSnapshots(dir, false)
+	if !results[0].Passed {
+		t.Fatalf("Expected snapshot to match after update, got %+v", results[0])
+	}
+
+	os.WriteFile(filepath.Join(toolDir, "tool.json"), []byte(`{"code": "params[\"n\"] * 3"}`), 0644)
+	results, _ = RunSnapshots(dir, false)
+	if results[0].Passed {
+		t.Fatal("Expected mismatch after changing the tool")
+	}
+	if !strings.Contains(results[0].Diff, `-  "result": 4,`) || !strings.Contains(results[0].Diff, `+  "result": 6,`) {
+		t.Errorf("Expected diff to show changed result, got:\n%s", results[0].Diff)
+	}
+}
+
+func TestRunSnapshots_SavedTool(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+
+	dir := t.TempDir()
+	os.MkdirAll(filepath.Join(dir, "not_saved"), 0755)
+	os.WriteFile(filepath.Join(dir, "not_saved", "case.json"), []byte(`{}`), 0644)
+
+	results, err := RunSnapshots(dir, false)
+	if err != nil {
+		t.Fatalf("RunSnapshots() error = %v", err)
+	}
+	if len(results) != 1 || results[0].Error == "" {
+		t.Errorf("Expected error for unknown saved tool, got %+v", results)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	diff := Diff("a\nb\nc\n", "a\nx\nc\n")
+	expected := "--- golden\n+++ actual\n a\n-b\n+x\n c\n"
+	if diff != expected {
+		t.Errorf("Diff() =\n%s\nexpected\n%s", diff, expected)
+	}
+}
@@ -0,0 +1,142 @@
+// Package tracing records OpenTelemetry spans for Starlark executions and upstream tool calls,
+// exporting them over OTLP when an endpoint is configured
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// ServiceName identifies the metatool in exported traces
+const ServiceName = "mcp-metatool"
+
+// instrumentationName names the tracer spans are created with
+const instrumentationName = "github.com/dslh/mcp-metatool"
+
+// Standard OpenTelemetry environment variables that enable exporting without any config
+const (
+	EndpointEnvVar       = "OTEL_EXPORTER_OTLP_ENDPOINT"
+	TracesEndpointEnvVar = "OTEL_EXPORTER_OTLP_TRACES_ENDPOINT"
+)
+
+// shutdownTimeout bounds how long Shutdown waits for buffered spans to be exported
+const shutdownTimeout = 5 * time.Second
+
+// Options configure where spans are exported and how many are sampled
+type Options struct {
+	Endpoint    string            // OTLP/HTTP endpoint URL, e.g. http://localhost:4318
+	Headers     map[string]string // sent with every export, e.g. for authentication
+	SampleRatio *float64          // fraction of traces recorded, defaults to 1
+}
+
+var (
+	mu       sync.Mutex
+	provider *sdktrace.TracerProvider
+)
+
+// Validate checks that the sample ratio is a fraction
+func (o Options) Validate() error {
+	if o.SampleRatio != nil && (*o.SampleRatio < 0 || *o.SampleRatio > 1) {
+		return fmt.Errorf("sampleRatio must be between 0 and 1, got %v", *o.SampleRatio)
+	}
+	return nil
+}
+
+// Enabled reports whether spans will be exported: an endpoint is configured, either in opts
+// or through the standard OTEL_EXPORTER_OTLP_* environment variables
+func (o Options) Enabled() bool {
+	return o.Endpoint != "" || os.Getenv(EndpointEnvVar) != "" || os.Getenv(TracesEndpointEnvVar) != ""
+}
+
+// Configure starts exporting spans if tracing is enabled; otherwise spans are discarded at no cost.
+// Other exporter settings are read from the standard OTEL_EXPORTER_OTLP_* environment variables.
+func Configure(opts Options) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	if !opts.Enabled() {
+		return nil
+	}
+
+	var exporterOpts []otlptracehttp.Option
+	if opts.Endpoint != "" {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithEndpointURL(opts.Endpoint))
+	}
+	if len(opts.Headers) > 0 {
+		exporterOpts = append(exporterOpts, otlptracehttp.WithHeaders(opts.Headers))
+	}
+	exporter, err := otlptracehttp.New(context.Background(), exporterOpts...)
+	if err != nil {
+		return fmt.Errorf("failed to create OTLP exporter: %w", err)
+	}
+
+	ratio := 1.0
+	if opts.SampleRatio != nil {
+		ratio = *opts.SampleRatio
+	}
+	setProvider(sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(ratio))),
+		sdktrace.WithResource(resource.NewSchemaless(attribute.String("service.name", ServiceName))),
+	))
+	return nil
+}
+
+// setProvider makes tp the source of all spans, replacing any provider set before
+func setProvider(tp *sdktrace.TracerProvider) {
+	mu.Lock()
+	defer mu.Unlock()
+	provider = tp
+	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+}
+
+// Shutdown exports any buffered spans and stops exporting
+func Shutdown() error {
+	mu.Lock()
+	tp := provider
+	provider = nil
+	mu.Unlock()
+	if tp == nil {
+		return nil
+	}
+	otel.SetTracerProvider(noop.NewTracerProvider())
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+	return tp.Shutdown(ctx)
+}
+
+// Start begins a span as a child of any span in ctx
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// End finishes a span, marking it as failed if err is non-nil
+func End(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+// Fail marks a span as failed with a message, for failures that aren't Go errors
+func Fail(span trace.Span, message string) {
+	span.SetStatus(codes.Error, message)
+}
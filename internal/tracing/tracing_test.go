@@ -0,0 +1,56 @@
+package tracing
+
+import (
+	"testing"
+)
+
+func TestOptionsValidate(t *testing.T) {
+	ratio := func(r float64) *float64 { return &r }
+	tests := []struct {
+		name    string
+		opts    Options
+		wantErr bool
+	}{
+		{name: "defaults", opts: Options{}},
+		{name: "sampled", opts: Options{Endpoint: "http://localhost:4318", SampleRatio: ratio(0.25)}},
+		{name: "ratio too high", opts: Options{SampleRatio: ratio(1.5)}, wantErr: true},
+		{name: "negative ratio", opts: Options{SampleRatio: ratio(-0.1)}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.opts.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestConfigure(t *testing.T) {
+	t.Setenv(EndpointEnvVar, "")
+	t.Setenv(TracesEndpointEnvVar, "")
+
+	if err := Configure(Options{}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if provider != nil {
+		t.Error("Expected no exporter without an endpoint")
+	}
+
+	t.Setenv(EndpointEnvVar, "http://127.0.0.1:1")
+	if !(Options{}).Enabled() {
+		t.Error("Expected the OTLP environment variable to enable tracing")
+	}
+	if err := Configure(Options{Endpoint: "http://127.0.0.1:1", Headers: map[string]string{"x-api-key": "k"}}); err != nil {
+		t.Fatalf("Configure() error = %v", err)
+	}
+	if provider == nil {
+		t.Fatal("Expected an exporter for the configured endpoint")
+	}
+	// Nothing was recorded, so nothing is sent to the unreachable endpoint
+	if err := Shutdown(); err != nil {
+		t.Errorf("Shutdown() error = %v", err)
+	}
+	if provider != nil {
+		t.Error("Expected Shutdown to stop exporting")
+	}
+}
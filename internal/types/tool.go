@@ -1,11 +1,22 @@
 package types
 
+import "github.com/dslh/mcp-metatool/internal/persistence"
+
 // SaveToolArgs defines the arguments for the save_tool MCP tool
 type SaveToolArgs struct {
-	Name        string                 `json:"name" jsonschema:"Tool identifier"`
-	Description string                 `json:"description" jsonschema:"Human-readable description of what the tool does"`
-	InputSchema map[string]interface{} `json:"inputSchema" jsonschema:"JSON Schema for tool parameters"`
-	Code        string                 `json:"code" jsonschema:"Starlark implementation of the tool"`
+	Name            string                            `json:"name" jsonschema:"Tool identifier"`
+	Description     string                            `json:"description" jsonschema:"Human-readable description of what the tool does"`
+	InputSchema     map[string]interface{}            `json:"inputSchema" jsonschema:"JSON Schema for tool parameters"`
+	Code            string                            `json:"code" jsonschema:"Starlark implementation of the tool"`
+	Tests           []persistence.ToolTest            `json:"tests,omitempty" jsonschema:"Optional test cases with params, expected result, assertions, and mocked upstream responses"`
+	Presets         map[string]map[string]interface{} `json:"presets,omitempty" jsonschema:"Optional named sets of parameter values, selected by calling the tool with a preset parameter"`
+	SessionAffinity bool                              `json:"sessionAffinity,omitempty" jsonschema:"Keep each run's calls to a server on the same upstream session, failing if the server is reconnected mid-run"`
+	AllowedServers  []string                          `json:"allowedServers,omitempty" jsonschema:"Upstream servers the tool's code may call; others are hidden from it. Omit to allow all servers"`
+	Author          string                            `json:"author,omitempty" jsonschema:"Who wrote the tool; kept from the previous definition when omitted"`
+	Annotations     *persistence.ToolAnnotations      `json:"annotations,omitempty" jsonschema:"MCP annotations advertised with the tool, such as readOnlyHint, so clients can choose whether to confirm calls"`
+	ExpectedVersion *int                              `json:"expectedVersion,omitempty" jsonschema:"Only save if the tool is still at this version, as last read with show_saved_tool; 0 if it must not exist yet"`
+	TestParams      map[string]interface{}            `json:"testParams,omitempty" jsonschema:"Parameters to run the tool with once it is saved, reporting its result or error"`
+	TestMocks       map[string]interface{}            `json:"testMocks,omitempty" jsonschema:"Responses for the test run's upstream calls, keyed by server.tool as in test mocks"`
 }
 
 // SavedToolParams provides a flexible parameter structure for saved tools
@@ -13,6 +24,27 @@ type SaveToolArgs struct {
 // against the dynamic schemas from saved tool definitions
 type SavedToolParams map[string]interface{}
 
+// PatchToolArgs defines the arguments for the patch_saved_tool MCP tool. Omitted fields are left
+// unchanged; an empty array or object clears tests, presets or allowed servers.
+type PatchToolArgs struct {
+	Name            string                            `json:"name" jsonschema:"Name of the saved tool to update"`
+	Description     string                            `json:"description,omitempty" jsonschema:"New description"`
+	InputSchema     map[string]interface{}            `json:"inputSchema,omitempty" jsonschema:"New JSON Schema for tool parameters"`
+	Code            string                            `json:"code,omitempty" jsonschema:"New Starlark implementation"`
+	Tests           []persistence.ToolTest            `json:"tests,omitempty" jsonschema:"New test cases, replacing the existing ones; an empty array removes them"`
+	Presets         map[string]map[string]interface{} `json:"presets,omitempty" jsonschema:"New presets, replacing the existing ones; an empty object removes them"`
+	SessionAffinity *bool                             `json:"sessionAffinity,omitempty" jsonschema:"Whether to keep each run's calls on the same upstream sessions"`
+	AllowedServers  []string                          `json:"allowedServers,omitempty" jsonschema:"New allowed servers; an empty array allows all servers"`
+	Author          string                            `json:"author,omitempty" jsonschema:"New author"`
+	Annotations     *persistence.ToolAnnotations      `json:"annotations,omitempty" jsonschema:"New annotations, replacing the existing ones; an empty object removes them"`
+	ExpectedVersion *int                              `json:"expectedVersion,omitempty" jsonschema:"Only update if the tool is still at this version, as last read with show_saved_tool"`
+}
+
+// ListSavedToolsArgs defines the arguments for the list_saved_tools MCP tool
+type ListSavedToolsArgs struct {
+	Sort string `json:"sort,omitempty" jsonschema:"Order tools by name (default), created or updated, newest first"`
+}
+
 // ShowToolArgs defines the arguments for the show_saved_tool MCP tool
 type ShowToolArgs struct {
 	Name string `json:"name" jsonschema:"Tool name to display"`
@@ -21,4 +53,128 @@ type ShowToolArgs struct {
 // DeleteToolArgs defines the arguments for the delete_saved_tool MCP tool
 type DeleteToolArgs struct {
 	Name string `json:"name" jsonschema:"Tool name to delete"`
-}
\ No newline at end of file
+}
+
+// DeleteToolsArgs defines the arguments for the delete_saved_tools MCP tool
+type DeleteToolsArgs struct {
+	Patterns []string `json:"patterns" jsonschema:"Glob patterns matched against tool names, such as experiment_*"`
+	DryRun   bool     `json:"dryRun,omitempty" jsonschema:"List the matching tools without deleting them"`
+}
+
+// PruneToolsArgs defines the arguments for the prune_saved_tools MCP tool
+type PruneToolsArgs struct {
+	Days    int  `json:"days" jsonschema:"Saved tools that haven't run in this many days are pruned"`
+	Confirm bool `json:"confirm,omitempty" jsonschema:"Delete the unused tools; without it they are only listed"`
+}
+
+// ExportContextArgs defines the arguments for the export_context MCP tool
+type ExportContextArgs struct {
+	Name      string `json:"name" jsonschema:"Saved tool whose latest result to export"`
+	ChunkSize int    `json:"chunkSize,omitempty" jsonschema:"Most characters of result in each chunk (default 8000)"`
+}
+
+// VerifyToolsArgs defines the arguments for the verify_tools MCP tool
+type VerifyToolsArgs struct {
+	Names []string `json:"names,omitempty" jsonschema:"Saved tools to verify (default: all of them)"`
+}
+
+// ApprovalArgs identifies a queued call for the deny_call MCP tool
+type ApprovalArgs struct {
+	ID string `json:"id" jsonschema:"ID of the pending call"`
+}
+
+// TestToolArgs defines the arguments for the test_saved_tool MCP tool
+type TestToolArgs struct {
+	Name string `json:"name" jsonschema:"Tool name to test"`
+}
+
+// ListVersionsArgs defines the arguments for the list_tool_versions MCP tool
+type ListVersionsArgs struct {
+	Name string `json:"name" jsonschema:"Tool name to list versions for"`
+}
+
+// RollbackToolArgs defines the arguments for the rollback_saved_tool MCP tool
+type RollbackToolArgs struct {
+	Name    string `json:"name" jsonschema:"Tool name to roll back"`
+	Version int    `json:"version" jsonschema:"Version number to restore"`
+}
+
+// RestoreToolArgs defines the arguments for the restore_saved_tool MCP tool
+type RestoreToolArgs struct {
+	Name string `json:"name" jsonschema:"Tool name to restore"`
+}
+
+// RenameToolArgs defines the arguments for the rename_saved_tool and duplicate_saved_tool MCP tools
+type RenameToolArgs struct {
+	Name    string `json:"name" jsonschema:"Name of the saved tool"`
+	NewName string `json:"newName" jsonschema:"Name to save it under, which must not be taken"`
+}
+
+// ServerStatusArgs defines the arguments for the server_status MCP tool
+type ServerStatusArgs struct {
+	Name  string `json:"name,omitempty" jsonschema:"Only report this server"`
+	Check bool   `json:"check,omitempty" jsonschema:"Ping servers now instead of reporting the last periodic health check"`
+}
+
+// RestartServerArgs defines the arguments for the restart_server MCP tool
+type RestartServerArgs struct {
+	Name string `json:"name" jsonschema:"Name of the server to restart"`
+}
+
+// AddServerArgs defines the arguments for the add_server MCP tool
+type AddServerArgs struct {
+	Name         string            `json:"name" jsonschema:"Server name, used as its Starlark namespace and tool prefix"`
+	Command      string            `json:"command,omitempty" jsonschema:"Command that launches the server"`
+	URL          string            `json:"url,omitempty" jsonschema:"URL of a remote server, instead of a command"`
+	Args         []string          `json:"args,omitempty" jsonschema:"Command arguments"`
+	Env          map[string]string `json:"env,omitempty" jsonschema:"Environment variables for the server; values may reference ${VAR}"`
+	Hidden       *bool             `json:"hidden,omitempty" jsonschema:"Only expose the server's tools through Starlark"`
+	AllowedTools []string          `json:"allowedTools,omitempty" jsonschema:"Tool patterns to expose, hiding all others"`
+	HiddenTools  []string          `json:"hiddenTools,omitempty" jsonschema:"Tool patterns to hide"`
+	LazyStart    *bool             `json:"lazyStart,omitempty" jsonschema:"Defer launching the server until one of its tools is first used"`
+	IdleTimeout  string            `json:"idleTimeout,omitempty" jsonschema:"Duration after which an unused server is shut down, e.g. 10m"`
+}
+
+// RemoveServerArgs defines the arguments for the remove_server MCP tool
+type RemoveServerArgs struct {
+	Name string `json:"name" jsonschema:"Name of the server to remove"`
+}
+
+// CurateToolsArgs defines the arguments for the curate_tools MCP tool
+type CurateToolsArgs struct {
+	MaxCalls int64 `json:"maxCalls,omitempty" jsonschema:"Tools called at most this many times count as unused (default 0)"`
+	Apply    bool  `json:"apply,omitempty" jsonschema:"Apply the suggested patch to servers.json"`
+}
+
+// ValidateCallArgs defines the arguments for the validate_call MCP tool
+type ValidateCallArgs struct {
+	Server    string                 `json:"server" jsonschema:"Name of the upstream server"`
+	Tool      string                 `json:"tool" jsonschema:"Name of the tool on that server, without the server prefix"`
+	Arguments map[string]interface{} `json:"arguments,omitempty" jsonschema:"Arguments to check against the tool's input schema"`
+}
+
+// ToolStatsArgs defines the arguments for the tool_stats MCP tool
+type ToolStatsArgs struct {
+	Server string `json:"server,omitempty" jsonschema:"Only show tools on this upstream server; use \"saved\" for saved tools"`
+	Sort   string `json:"sort,omitempty" jsonschema:"Order tools by calls (default), errors, errorRate, avg or max"`
+}
+
+// SearchToolsArgs defines the arguments for the search_tools MCP tool
+type SearchToolsArgs struct {
+	Query  string `json:"query" jsonschema:"Words describing the tool to find, such as \"create calendar event\""`
+	Server string `json:"server,omitempty" jsonschema:"Only search the tools of this upstream server"`
+	Limit  int    `json:"limit,omitempty" jsonschema:"Most matches to return (default 10)"`
+}
+
+// DescribeToolArgs defines the arguments for the describe_tool MCP tool
+type DescribeToolArgs struct {
+	Name   string `json:"name" jsonschema:"Saved tool name, proxied tool name such as github__create_issue, or Starlark call such as github.create_issue"`
+	Server string `json:"server,omitempty" jsonschema:"Upstream server whose tool to describe, when name is the tool's name on that server"`
+}
+
+// CallToolArgs defines the arguments for the call_tool MCP tool
+type CallToolArgs struct {
+	Server    string                 `json:"server" jsonschema:"Name of the upstream server"`
+	Tool      string                 `json:"tool" jsonschema:"Name or alias of the tool on that server, without the server prefix"`
+	Arguments map[string]interface{} `json:"arguments,omitempty" jsonschema:"Arguments to call the tool with"`
+}
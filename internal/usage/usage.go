@@ -0,0 +1,235 @@
+package usage
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/storage"
+)
+
+// ToolUsage counts the calls made to one tool and how long they took
+type ToolUsage struct {
+	Calls    int64     `json:"calls"`
+	LastUsed time.Time `json:"lastUsed"`
+	Errors   int64     `json:"errors,omitempty"`
+	Timed    int64     `json:"timed,omitempty"` // calls with a recorded duration and outcome
+	TotalMs  float64   `json:"totalMs,omitempty"`
+	MaxMs    float64   `json:"maxMs,omitempty"`
+}
+
+// ToolStats summarizes the calls made to one tool
+type ToolStats struct {
+	Server    string    `json:"server,omitempty"` // empty for saved tools
+	Tool      string    `json:"tool"`
+	Calls     int64     `json:"calls"`
+	Errors    int64     `json:"errors"`
+	ErrorRate float64   `json:"errorRate"` // fraction of timed calls that failed
+	AvgMs     float64   `json:"avgMs"`
+	MaxMs     float64   `json:"maxMs"`
+	LastUsed  time.Time `json:"lastUsed"`
+}
+
+// Stats records tool usage since tracking began
+type Stats struct {
+	Since   time.Time                        `json:"since"`
+	Proxied map[string]map[string]*ToolUsage `json:"proxied"` // server -> tool -> usage
+	Saved   map[string]*ToolUsage            `json:"saved"`
+}
+
+// mu serializes updates to the usage file within this process
+var mu sync.Mutex
+
+// enabled turns on recording. It stays off unless the metatool is serving clients,
+// so CLI runs and tests don't count as usage.
+var enabled atomic.Bool
+
+// Enable starts recording tool usage
+func Enable() {
+	enabled.Store(true)
+}
+
+// RecordProxied counts a finished call to a tool on an upstream server
+func RecordProxied(serverName, toolName string, elapsed time.Duration, failed bool) {
+	update(func(stats *Stats) {
+		if stats.Proxied[serverName] == nil {
+			stats.Proxied[serverName] = make(map[string]*ToolUsage)
+		}
+		touch(stats.Proxied[serverName], toolName, elapsed, failed)
+	})
+}
+
+// RecordSaved counts a finished run of a saved tool
+func RecordSaved(toolName string, elapsed time.Duration, failed bool) {
+	update(func(stats *Stats) {
+		touch(stats.Saved, toolName, elapsed, failed)
+	})
+}
+
+// Load returns the usage recorded so far
+func Load() (*Stats, error) {
+	mu.Lock()
+	defer mu.Unlock()
+
+	stats, _, err := load()
+	return stats, err
+}
+
+// ProxiedCalls returns how many times a tool on an upstream server has been called
+func (s *Stats) ProxiedCalls(serverName, toolName string) int64 {
+	if usage := s.Proxied[serverName][toolName]; usage != nil {
+		return usage.Calls
+	}
+	return 0
+}
+
+// SavedCalls returns how many times a saved tool has been run
+func (s *Stats) SavedCalls(toolName string) int64 {
+	if usage := s.Saved[toolName]; usage != nil {
+		return usage.Calls
+	}
+	return 0
+}
+
+// UnusedSaved returns the saved tools, given with when each was last saved, that haven't run
+// since cutoff, sorted by name. Tools saved since cutoff are never unused. Tools that have never run
+// only count if usage was already being tracked at cutoff, since earlier runs weren't recorded.
+func (s *Stats) UnusedSaved(saved map[string]time.Time, cutoff time.Time) []string {
+	var unused []string
+	for name, savedAt := range saved {
+		if savedAt.After(cutoff) {
+			continue
+		}
+		usage := s.Saved[name]
+		if usage == nil {
+			if s.Since.Before(cutoff) {
+				unused = append(unused, name)
+			}
+			continue
+		}
+		if usage.LastUsed.Before(cutoff) {
+			unused = append(unused, name)
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// ToolStats summarizes every tool called since tracking began, saved and proxied,
+// each sorted by name
+func (s *Stats) ToolStats() (saved, proxied []ToolStats) {
+	saved = []ToolStats{}
+	for name, usage := range s.Saved {
+		saved = append(saved, usage.summarize("", name))
+	}
+	proxied = []ToolStats{}
+	for serverName, tools := range s.Proxied {
+		for name, usage := range tools {
+			proxied = append(proxied, usage.summarize(serverName, name))
+		}
+	}
+	for _, list := range [][]ToolStats{saved, proxied} {
+		sort.Slice(list, func(i, j int) bool {
+			if list[i].Server != list[j].Server {
+				return list[i].Server < list[j].Server
+			}
+			return list[i].Tool < list[j].Tool
+		})
+	}
+	return saved, proxied
+}
+
+// summarize derives averages and rates from a tool's totals. Calls recorded before durations
+// were tracked count toward Calls but not the averages.
+func (u *ToolUsage) summarize(serverName, toolName string) ToolStats {
+	stats := ToolStats{
+		Server:   serverName,
+		Tool:     toolName,
+		Calls:    u.Calls,
+		Errors:   u.Errors,
+		MaxMs:    u.MaxMs,
+		LastUsed: u.LastUsed,
+	}
+	if u.Timed > 0 {
+		stats.ErrorRate = float64(u.Errors) / float64(u.Timed)
+		stats.AvgMs = u.TotalMs / float64(u.Timed)
+	}
+	return stats
+}
+
+// touch counts a call to a tool, adding its duration and outcome to the totals
+func touch(tools map[string]*ToolUsage, toolName string, elapsed time.Duration, failed bool) {
+	usage := tools[toolName]
+	if usage == nil {
+		usage = &ToolUsage{}
+		tools[toolName] = usage
+	}
+	ms := float64(elapsed) / float64(time.Millisecond)
+	usage.Calls++
+	usage.Timed++
+	usage.TotalMs += ms
+	usage.MaxMs = max(usage.MaxMs, ms)
+	if failed {
+		usage.Errors++
+	}
+	usage.LastUsed = time.Now()
+}
+
+// update applies a change to the usage file. Failures are logged rather than returned,
+// since losing a usage count should never fail the call being counted.
+func update(change func(stats *Stats)) {
+	if !enabled.Load() {
+		return
+	}
+	mu.Lock()
+	defer mu.Unlock()
+
+	stats, path, err := load()
+	if err != nil {
+		logging.Warnf("Failed to load tool usage: %v", err)
+		return
+	}
+	change(stats)
+
+	data, err := json.MarshalIndent(stats, "", "  ")
+	if err != nil {
+		logging.Warnf("Failed to encode tool usage: %v", err)
+		return
+	}
+	if err := storage.WriteFile(path, data, 0644); err != nil {
+		logging.Warnf("Failed to save tool usage: %v", err)
+	}
+}
+
+// load reads the usage file, starting fresh stats if it doesn't exist yet. The caller must hold mu.
+func load() (*Stats, string, error) {
+	path, err := paths.GetUsagePath()
+	if err != nil {
+		return nil, "", err
+	}
+
+	stats := &Stats{}
+	data, err := storage.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		stats.Since = time.Now()
+	} else if err != nil {
+		return nil, "", err
+	} else if err := json.Unmarshal(data, stats); err != nil {
+		return nil, "", fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if stats.Proxied == nil {
+		stats.Proxied = make(map[string]map[string]*ToolUsage)
+	}
+	if stats.Saved == nil {
+		stats.Saved = make(map[string]*ToolUsage)
+	}
+	return stats, path, nil
+}
@@ -0,0 +1,133 @@
+package usage
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordAndLoad(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+
+	// Nothing is recorded until enabled
+	RecordSaved("report", time.Millisecond, false)
+	if _, err := os.Stat(filepath.Join(dir, "usage.json")); err == nil {
+		t.Fatal("Expected no usage file before Enable")
+	}
+
+	Enable()
+	defer enabled.Store(false)
+
+	stats, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if stats.Since.IsZero() || stats.ProxiedCalls("github", "get_issue") != 0 {
+		t.Errorf("Expected fresh stats, got %+v", stats)
+	}
+
+	RecordProxied("github", "get_issue", 10*time.Millisecond, false)
+	RecordProxied("github", "get_issue", 30*time.Millisecond, true)
+	RecordProxied("jira", "search", time.Millisecond, false)
+	RecordSaved("report", 5*time.Millisecond, false)
+
+	stats, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	tests := []struct {
+		name string
+		got  int64
+		want int64
+	}{
+		{"github get_issue", stats.ProxiedCalls("github", "get_issue"), 2},
+		{"jira search", stats.ProxiedCalls("jira", "search"), 1},
+		{"unknown tool", stats.ProxiedCalls("jira", "create"), 0},
+		{"saved report", stats.SavedCalls("report"), 1},
+		{"unknown saved", stats.SavedCalls("other"), 0},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s: got %d calls, want %d", tt.name, tt.got, tt.want)
+		}
+	}
+	if stats.Saved["report"].LastUsed.Before(stats.Since) {
+		t.Errorf("Expected last use after tracking began, got %v < %v", stats.Saved["report"].LastUsed, stats.Since)
+	}
+	if got := *stats.Proxied["github"]["get_issue"]; got.Errors != 1 || got.Timed != 2 || got.TotalMs != 40 || got.MaxMs != 30 {
+		t.Errorf("Expected durations and errors recorded, got %+v", got)
+	}
+}
+
+func TestToolStats(t *testing.T) {
+	stats := &Stats{
+		Proxied: map[string]map[string]*ToolUsage{
+			"github": {
+				"search":    {Calls: 4, Errors: 1, Timed: 4, TotalMs: 100, MaxMs: 40},
+				"get_issue": {Calls: 2, Timed: 2, TotalMs: 30, MaxMs: 20},
+			},
+		},
+		Saved: map[string]*ToolUsage{
+			// Counted before durations were recorded
+			"report": {Calls: 5, Errors: 1, Timed: 2, TotalMs: 50, MaxMs: 30},
+			"legacy": {Calls: 3},
+		},
+	}
+
+	saved, proxied := stats.ToolStats()
+	want := []ToolStats{
+		{Tool: "legacy", Calls: 3},
+		{Tool: "report", Calls: 5, Errors: 1, ErrorRate: 0.5, AvgMs: 25, MaxMs: 30},
+		{Server: "github", Tool: "get_issue", Calls: 2, AvgMs: 15, MaxMs: 20},
+		{Server: "github", Tool: "search", Calls: 4, Errors: 1, ErrorRate: 0.25, AvgMs: 25, MaxMs: 40},
+	}
+	if got := append(saved, proxied...); fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("ToolStats() = %+v, want %+v", got, want)
+	}
+}
+
+func TestLoad_Corrupt(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("MCP_METATOOL_DIR", dir)
+	os.WriteFile(filepath.Join(dir, "usage.json"), []byte("{not json"), 0644)
+
+	if _, err := Load(); err == nil {
+		t.Error("Expected error for corrupt usage file")
+	}
+}
+
+func TestUnusedSaved(t *testing.T) {
+	now := time.Now()
+	stats := &Stats{
+		Since: now.AddDate(0, 0, -60),
+		Saved: map[string]*ToolUsage{
+			"recent": {Calls: 3, LastUsed: now.AddDate(0, 0, -1)},
+			"stale":  {Calls: 1, LastUsed: now.AddDate(0, 0, -45)},
+		},
+	}
+	longAgo := now.AddDate(-1, 0, 0)
+	saved := map[string]time.Time{"never": longAgo, "recent": longAgo, "stale": longAgo, "fresh": now.AddDate(0, 0, -2)}
+
+	tests := []struct {
+		name  string
+		stats *Stats
+		days  int
+		want  []string
+	}{
+		{"thirty days", stats, 30, []string{"never", "stale"}},
+		{"ninety days predates tracking", stats, 90, nil},
+		{"today", stats, 0, []string{"fresh", "never", "recent", "stale"}},
+		{"tracking just began", &Stats{Since: now}, 30, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.stats.UnusedSaved(saved, now.AddDate(0, 0, -tt.days))
+			if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Errorf("UnusedSaved() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,178 @@
+// Package verify audits saved tools without running them: each is compiled, and the upstream
+// servers and tools it calls are checked against the configured and discovered inventory
+package verify
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+)
+
+// Kinds of problem found in saved tools
+const (
+	KindCompile       = "compile"        // the code doesn't parse or compile
+	KindUnknownServer = "unknown_server" // calls a server that isn't configured
+	KindUnknownTool   = "unknown_tool"   // calls a tool its server doesn't offer
+	KindUndefined     = "undefined"      // uses a name that is never defined
+	KindUnavailable   = "unavailable"    // calls a server whose tools couldn't be discovered to check
+	KindNotAllowed    = "not_allowed"    // calls a server missing from its allowed servers
+)
+
+// Problem is something found wrong with a saved tool
+type Problem struct {
+	Tool    string `json:"tool"`
+	Kind    string `json:"kind"`
+	Message string `json:"message"`
+	Line    int    `json:"line,omitempty"`
+}
+
+// Report lists the problems found in the checked tools
+type Report struct {
+	Checked  int       `json:"checked"`
+	Problems []Problem `json:"problems"`
+}
+
+// inventory is the upstream servers a proxy manager can reach and their tools, discovered on demand
+type inventory struct {
+	proxyManager proxy.ProxyManager
+	servers      map[string]string // Starlark namespace -> server name
+	tools        map[string][]*mcp.Tool
+	errors       map[string]error
+}
+
+// newInventory lists the servers configured in the proxy manager, which may be nil if there are none
+func newInventory(proxyManager proxy.ProxyManager) *inventory {
+	inv := &inventory{
+		proxyManager: proxyManager,
+		servers:      make(map[string]string),
+		tools:        make(map[string][]*mcp.Tool),
+		errors:       make(map[string]error),
+	}
+	if proxyManager == nil {
+		return inv
+	}
+	for name, tools := range proxyManager.GetAllTools() {
+		inv.add(name)
+		inv.tools[name] = tools
+	}
+	for _, name := range proxy.PendingServers(proxyManager) {
+		inv.add(name)
+	}
+	if statuses, ok := proxy.ServerStatuses(proxyManager); ok {
+		for _, status := range statuses {
+			inv.add(status.Name)
+		}
+	}
+	return inv
+}
+
+// add records a configured server under the name code refers to it by
+func (inv *inventory) add(serverName string) {
+	inv.servers[starlark.NamespaceName(inv.proxyManager, serverName)] = serverName
+}
+
+// serverTools returns a configured server's tools, starting it if it hasn't been yet
+func (inv *inventory) serverTools(serverName string) ([]*mcp.Tool, error) {
+	if tools, ok := inv.tools[serverName]; ok {
+		return tools, nil
+	}
+	if err, ok := inv.errors[serverName]; ok {
+		return nil, err
+	}
+	tools, err := proxy.EnsureStarted(inv.proxyManager, serverName)
+	if err != nil {
+		inv.errors[serverName] = err
+		return nil, err
+	}
+	inv.tools[serverName] = tools
+	return tools, nil
+}
+
+// Tools checks the given saved tools against the servers reachable through the proxy manager.
+// The Starlark options should match those the tools run with, so optional modules are known.
+func Tools(tools []*persistence.SavedToolDefinition, proxyManager proxy.ProxyManager, opts ...starlark.Option) *Report {
+	globals := starlark.Globals(opts...)
+	known := func(name string) bool {
+		_, ok := globals[name]
+		return ok || name == "params"
+	}
+
+	inv := newInventory(proxyManager)
+	report := &Report{Problems: []Problem{}}
+	for _, tool := range tools {
+		report.Checked++
+		report.Problems = append(report.Problems, checkTool(tool, known, inv)...)
+	}
+	return report
+}
+
+// checkTool compiles a saved tool and checks the servers and tools it references
+func checkTool(tool *persistence.SavedToolDefinition, known func(string) bool, inv *inventory) []Problem {
+	references, err := starlark.Analyze(tool.Code, known)
+	if err != nil {
+		return []Problem{{Tool: tool.Name, Kind: KindCompile, Message: err.Error()}}
+	}
+
+	var problems []Problem
+	reported := make(map[string]bool)
+	add := func(problem Problem) {
+		key := problem.Kind + "\x00" + problem.Message
+		if !reported[key] {
+			reported[key] = true
+			problems = append(problems, problem)
+		}
+	}
+	for _, ref := range references {
+		switch {
+		case ref.Attr == "":
+			add(Problem{Tool: tool.Name, Kind: KindUndefined, Message: fmt.Sprintf("undefined: %s", ref.Name), Line: ref.Line})
+		case inv.servers[ref.Name] == "":
+			add(Problem{Tool: tool.Name, Kind: KindUnknownServer, Message: fmt.Sprintf("calls %s.%s, but no server named %s is configured", ref.Name, ref.Attr, ref.Name), Line: ref.Line})
+		case !allowedServer(tool, ref.Name, inv.servers[ref.Name]):
+			add(Problem{Tool: tool.Name, Kind: KindNotAllowed, Message: fmt.Sprintf("calls %s.%s, but %s is not in its allowed servers", ref.Name, ref.Attr, inv.servers[ref.Name]), Line: ref.Line})
+		default:
+			serverTools, err := inv.serverTools(inv.servers[ref.Name])
+			if err != nil {
+				add(Problem{Tool: tool.Name, Kind: KindUnavailable, Message: fmt.Sprintf("can't check %s.%s: %v", ref.Name, ref.Attr, err), Line: ref.Line})
+			} else if _, ok := starlark.NamespaceTools(inv.proxyManager, inv.servers[ref.Name], serverTools)[ref.Attr]; !ok {
+				add(Problem{Tool: tool.Name, Kind: KindUnknownTool, Message: fmt.Sprintf("calls %s.%s, but server %s has no tool named %s", ref.Name, ref.Attr, inv.servers[ref.Name], ref.Attr), Line: ref.Line})
+			}
+		}
+	}
+	return problems
+}
+
+// allowedServer reports whether a tool may call a server, given by its namespace and configured names
+func allowedServer(tool *persistence.SavedToolDefinition, namespace, serverName string) bool {
+	if len(tool.AllowedServers) == 0 {
+		return true
+	}
+	for _, allowed := range tool.AllowedServers {
+		if allowed == namespace || allowed == serverName {
+			return true
+		}
+	}
+	return false
+}
+
+// Summary describes the report for people, one problem per line
+func (r *Report) Summary() string {
+	if len(r.Problems) == 0 {
+		return fmt.Sprintf("Verified %d saved tool(s): no problems found", r.Checked)
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "Verified %d saved tool(s): %d problem(s) found", r.Checked, len(r.Problems))
+	for _, problem := range r.Problems {
+		location := problem.Tool
+		if problem.Line > 0 {
+			location = fmt.Sprintf("%s:%d", problem.Tool, problem.Line)
+		}
+		fmt.Fprintf(&b, "\n  ✗ %s: %s", location, problem.Message)
+	}
+	return b.String()
+}
@@ -0,0 +1,91 @@
+package verify
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/starlark"
+)
+
+// inventoryProxy offers fixed tools, plus lazily started servers that fail to start
+type inventoryProxy struct {
+	tools   map[string][]*mcp.Tool
+	pending []string
+}
+
+func (p *inventoryProxy) GetAllTools() map[string][]*mcp.Tool { return p.tools }
+
+func (p *inventoryProxy) CallTool(serverName, toolName string, arguments map[string]interface{}) (*mcp.CallToolResult, error) {
+	return nil, fmt.Errorf("verification should not call tools")
+}
+
+func (p *inventoryProxy) PendingServers() []string { return p.pending }
+
+func (p *inventoryProxy) EnsureStarted(serverName string) ([]*mcp.Tool, error) {
+	return nil, fmt.Errorf("failed to launch %s", serverName)
+}
+
+func TestTools(t *testing.T) {
+	proxy := &inventoryProxy{
+		tools: map[string][]*mcp.Tool{
+			"github":    {{Name: "get_issue"}},
+			"my-server": {{Name: "lookup"}},
+		},
+		pending: []string{"jira"},
+	}
+	tools := []*persistence.SavedToolDefinition{
+		{Name: "good", Code: "issue = github.get_issue(number=params['n'])\nresult = my_server.lookup(id=issue['content'][0])"},
+		{Name: "expression", Code: `json.encode(github.get_issue(number=1))`},
+		{Name: "broken", Code: "def f(:\n    pass"},
+		{Name: "removed_server", Code: "a = slack.post(text='hi')\nb = slack.post(text='again')"},
+		{Name: "renamed_tool", Code: "github.fetch_issue(number=1)"},
+		{Name: "typo", Code: "[x for x in itmes]"},
+		{Name: "lazy", Code: "jira.search(q='x')"},
+		{Name: "notify_user", Code: "notify.send('hi')"},
+		{Name: "restricted", Code: "github.get_issue(number=1)\nmy_server.lookup(id=1)", AllowedServers: []string{"my-server"}},
+	}
+
+	report := Tools(tools, proxy, starlark.WithModule("notify", starlark.SecretsModule))
+	if report.Checked != len(tools) {
+		t.Errorf("Checked = %d, want %d", report.Checked, len(tools))
+	}
+
+	kinds := make(map[string][]string)
+	for _, problem := range report.Problems {
+		kinds[problem.Tool] = append(kinds[problem.Tool], problem.Kind)
+	}
+	expected := map[string][]string{
+		"broken":         {KindCompile},
+		"removed_server": {KindUnknownServer},
+		"renamed_tool":   {KindUnknownTool},
+		"typo":           {KindUndefined},
+		"lazy":           {KindUnavailable},
+		"restricted":     {KindNotAllowed},
+	}
+	if !reflect.DeepEqual(kinds, expected) {
+		t.Errorf("Problems = %+v, want kinds %v", report.Problems, expected)
+	}
+
+	summary := report.Summary()
+	for _, want := range []string{"6 problem(s)", "restricted:1: calls github.get_issue, but github is not in its allowed servers", "renamed_tool:1: calls github.fetch_issue, but server github has no tool named fetch_issue", "typo:1: undefined: itmes", "failed to launch jira"} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("Summary() = %q, want it to contain %q", summary, want)
+		}
+	}
+}
+
+func TestToolsWithoutServers(t *testing.T) {
+	report := Tools([]*persistence.SavedToolDefinition{{Name: "t", Code: "github.get_issue(number=1)"}}, nil)
+	if len(report.Problems) != 1 || report.Problems[0].Kind != KindUnknownServer {
+		t.Errorf("Problems = %+v, want an unknown server", report.Problems)
+	}
+
+	if report := Tools(nil, nil); report.Summary() != "Verified 0 saved tool(s): no problems found" {
+		t.Errorf("Summary() = %q", report.Summary())
+	}
+}
@@ -2,76 +2,151 @@ package main
 
 import (
 	"context"
-	"log"
+	"errors"
+	"fmt"
 	"os"
+	"os/signal"
+	"slices"
+	"syscall"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 
 	"github.com/dslh/mcp-metatool/internal/cmd"
 	"github.com/dslh/mcp-metatool/internal/config"
-	"github.com/dslh/mcp-metatool/internal/proxy"
-	"github.com/dslh/mcp-metatool/internal/tools"
+	"github.com/dslh/mcp-metatool/internal/gitsync"
+	"github.com/dslh/mcp-metatool/internal/history"
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/serve"
+	"github.com/dslh/mcp-metatool/internal/storage"
+	"github.com/dslh/mcp-metatool/internal/tracing"
+	"github.com/dslh/mcp-metatool/internal/usage"
+	"github.com/dslh/mcp-metatool/pkg/metatool"
 )
 
 func main() {
-	// Check for subcommands
-	if exitCode := cmd.Run(os.Args[1:]); exitCode >= 0 {
+	args := os.Args[1:]
+
+	// Keep saved tools, approvals and artifacts in memory if requested, wherever the flag is given
+	ephemeral := slices.Contains(args, "--ephemeral")
+	args = slices.DeleteFunc(args, func(arg string) bool { return arg == "--ephemeral" })
+	if ephemeral || config.ShouldUseEphemeralStorage() {
+		storage.UseMemory()
+	}
+
+	// Keep saved tools in the configured backend; in ephemeral mode they're always kept in memory
+	if !storage.IsEphemeral() {
+		if err := configureToolStorage(); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	// The serve subcommand runs the server, optionally over HTTP; anything else may be a CLI subcommand
+	serveOpts := &serve.Options{}
+	if len(args) > 0 && args[0] == "serve" {
+		opts, err := serve.ParseArgs(args[1:])
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		serveOpts = opts
+	} else if exitCode := cmd.Run(args); exitCode >= 0 {
 		os.Exit(exitCode)
 	}
 
 	// No subcommand matched, proceed with normal MCP server startup
-	server := mcp.NewServer(&mcp.Implementation{
-		Name:    "mcp-metatool",
-		Version: "0.1.0",
-	}, nil)
-
-	// Initialize proxy manager if config exists
-	var proxyManager *proxy.Manager
+	usage.Enable()
+	history.Enable()
+	logging.ToggleOnSignal(context.Background())
 	cfg, err := config.LoadDefaultConfig()
+
+	// Log to the configured destination, by default a rotating file in the metatool directory,
+	// keeping stderr free for stdio clients that show it to users
+	var logOpts logging.Options
+	if err == nil {
+		logOpts = cfg.Logging.Options()
+	}
+	if logErr := logging.Configure(logOpts); logErr != nil {
+		logging.Warnf("Logging to stderr: %v", logErr)
+	}
+	defer logging.Close()
+
+	// Export traces of tool executions when an OTLP endpoint is configured
+	var traceOpts tracing.Options
+	if err == nil {
+		traceOpts = cfg.Tracing.Options()
+	}
+	if traceErr := tracing.Configure(traceOpts); traceErr != nil {
+		logging.Warnf("Tracing disabled: %v", traceErr)
+	}
+	defer tracing.Shutdown()
+
 	if err != nil {
 		// Check if it's just a missing file
-		if _, ok := err.(*os.PathError); ok {
-			log.Printf("No MCP server configuration found - running without proxied servers")
+		var pathErr *os.PathError
+		if errors.As(err, &pathErr) {
+			logging.Infof("No MCP server configuration found - running without proxied servers")
 		} else {
-			log.Printf("Warning: failed to load config: %v", err)
+			logging.Warnf("Failed to load config: %v", err)
 		}
+		cfg = nil
 	} else if err := cfg.Validate(); err != nil {
-		log.Printf("Warning: invalid config: %v", err)
-	} else {
-		proxyManager = proxy.NewManager(cfg)
-		if err := proxyManager.Start(); err != nil {
-			log.Printf("Warning: failed to start proxy manager: %v", err)
-			proxyManager = nil
-		} else {
-			log.Printf("Proxy manager started with %d servers", len(proxyManager.GetConnectedServers()))
-			
-			// Register proxied tools with the MCP server
-			if err := tools.RegisterProxiedTools(server, proxyManager, cfg); err != nil {
-				log.Printf("Warning: failed to register proxied tools: %v", err)
-			}
-		}
+		logging.Warnf("Invalid config: %v", err)
+		cfg = nil
 	}
 
-	// Ensure proxy manager is cleaned up on exit
-	if proxyManager != nil {
-		defer proxyManager.Stop()
+	// Start the upstream servers and register every tool; edits to servers.json apply without restarting
+	gatewayOpts := []metatool.Option{metatool.WithConfig(cfg)}
+	if configPath, err := paths.GetConfigPath(); err == nil {
+		gatewayOpts = append(gatewayOpts, metatool.WithReload(configPath))
 	}
+	gateway, err := metatool.New(gatewayOpts...)
+	if err != nil {
+		fatalf("Failed to start: %v", err)
+	}
+	defer gateway.Close()
 
-	// Register built-in tools
-	tools.RegisterEvalStarlark(server, proxyManager)
-	tools.RegisterSaveTool(server)
-	tools.RegisterListSavedTools(server)
-	tools.RegisterShowSavedTool(server)
-	tools.RegisterDeleteSavedTool(server)
+	if storage.IsEphemeral() {
+		logging.Infof("Ephemeral mode: saved tools and other data will not be written to disk")
+	}
+	if serveOpts.HTTPAddr != "" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+		if err := serve.ListenAndServe(ctx, serve.NewMux(gateway.Server(), *serveOpts), *serveOpts); err != nil {
+			fatalf("Server failed: %v", err)
+		}
+		return
+	}
 
-	// Load and register saved tools
-	if err := tools.RegisterSavedTools(server, proxyManager); err != nil {
-		log.Printf("Warning: failed to load saved tools: %v", err)
+	logging.Infof("Starting MCP metatool server...")
+	if err := gateway.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
+		fatalf("Server failed: %v", err)
 	}
+}
 
-	log.Printf("Starting MCP metatool server...")
-	if err := server.Run(context.Background(), &mcp.StdioTransport{}); err != nil {
-		log.Fatalf("Server failed: %v", err)
+// configureToolStorage selects the backend saved tools are kept in, committing changes to them
+// to git if tool sync is configured
+func configureToolStorage() error {
+	cfg, _ := config.LoadDefaultConfig()
+	backend := config.ToolStorageBackend(cfg)
+	if err := persistence.UseBackend(backend); err != nil {
+		return err
 	}
+	if cfg != nil && cfg.ToolSync != nil {
+		if backend == config.ToolStorageSQLite {
+			return fmt.Errorf("toolSync requires toolStorage %s", config.ToolStorageFiles)
+		}
+		persistence.Use(gitsync.NewBackend(persistence.Current(), gitsync.NewRepo(cfg.ToolSync)))
+	}
+	return nil
 }
 
+// fatalf logs a failure that stops the server and exits
+func fatalf(format string, args ...interface{}) {
+	logging.Errorf(format, args...)
+	tracing.Shutdown()
+	logging.Close()
+	os.Exit(1)
+}
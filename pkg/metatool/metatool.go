@@ -0,0 +1,305 @@
+// Package metatool embeds an MCP metatool gateway in other Go programs. A Gateway proxies the tools
+// of the configured upstream servers and serves them alongside eval_starlark, the other built-in tools
+// and saved tools, over any MCP transport.
+package metatool
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"slices"
+	"sync/atomic"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.starlark.net/starlark"
+
+	"github.com/dslh/mcp-metatool/internal/admin"
+	"github.com/dslh/mcp-metatool/internal/approval"
+	"github.com/dslh/mcp-metatool/internal/chaos"
+	"github.com/dslh/mcp-metatool/internal/config"
+	"github.com/dslh/mcp-metatool/internal/logging"
+	"github.com/dslh/mcp-metatool/internal/notify"
+	"github.com/dslh/mcp-metatool/internal/paths"
+	"github.com/dslh/mcp-metatool/internal/persistence"
+	"github.com/dslh/mcp-metatool/internal/proxy"
+	"github.com/dslh/mcp-metatool/internal/reload"
+	metastarlark "github.com/dslh/mcp-metatool/internal/starlark"
+	"github.com/dslh/mcp-metatool/internal/tools"
+)
+
+// Config is the contents of servers.json: the upstream servers to proxy and how the gateway behaves
+type Config = config.Config
+
+// ServerConfig configures one upstream server
+type ServerConfig = config.MCPServerConfig
+
+// SavedTool is a tool written in Starlark and saved for reuse
+type SavedTool = persistence.SavedToolDefinition
+
+// Result is the outcome of executing Starlark code
+type Result = metastarlark.Result
+
+// Name and version the gateway reports to clients unless WithImplementation says otherwise
+const (
+	DefaultName    = "mcp-metatool"
+	DefaultVersion = "0.1.0"
+)
+
+// ErrGatewayOpen is returned by New while another Gateway in the process hasn't been closed
+var ErrGatewayOpen = errors.New("another gateway is open in this process: close it before creating a new one")
+
+// gatewayOpen is set while a Gateway is open, since its settings are held process-wide
+var gatewayOpen atomic.Bool
+
+// LoadConfig reads a servers.json file, expanding environment variable references
+func LoadConfig(path string) (*Config, error) {
+	return config.LoadConfig(path)
+}
+
+// LoadDefaultConfig reads servers.json from the metatool directory
+func LoadDefaultConfig() (*Config, error) {
+	return config.LoadDefaultConfig()
+}
+
+// Option configures a Gateway
+type Option func(*options)
+
+// options holds the settings a Gateway is created with
+type options struct {
+	name       string
+	version    string
+	config     *Config
+	configSet  bool
+	reloadPath string
+	modules    []metastarlark.Option
+}
+
+// WithImplementation sets the name and version the gateway reports to clients
+func WithImplementation(name, version string) Option {
+	return func(o *options) {
+		o.name, o.version = name, version
+	}
+}
+
+// WithConfig uses cfg instead of loading servers.json from the metatool directory, which is otherwise
+// also watched for changes. A nil cfg runs the gateway without upstream servers.
+func WithConfig(cfg *Config) Option {
+	return func(o *options) {
+		o.config, o.configSet = cfg, true
+	}
+}
+
+// WithReload applies edits to the config file at path to the running gateway without restarting it
+func WithReload(path string) Option {
+	return func(o *options) {
+		o.reloadPath = path
+	}
+}
+
+// WithModule makes a value available to all Starlark code under name, such as a *starlark.Builtin
+// or a module of builtins, so embedding programs can offer their own functions to tools
+func WithModule(name string, module starlark.Value) Option {
+	return func(o *options) {
+		o.modules = append(o.modules, metastarlark.WithModule(name, module))
+	}
+}
+
+// Gateway is an MCP server proxying upstream servers and serving the metatool's own tools
+type Gateway struct {
+	server       *mcp.Server
+	proxyManager *proxy.Manager
+	upstream     proxy.ProxyManager // used by Starlark code, subject to approval gating
+	starlarkOpts []metastarlark.Option
+	closers      []func()
+	open         bool
+}
+
+// New creates a gateway, starting its upstream servers. Settings such as built-in tool overrides and
+// limits in the config apply process-wide, so only one Gateway can be open at a time: New returns
+// ErrGatewayOpen until the previous one is closed. Servers that fail to start are logged rather than returned as errors, as the gateway stays useful
+// without them; an invalid config is an error.
+func New(opts ...Option) (*Gateway, error) {
+	o := options{name: DefaultName, version: DefaultVersion}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	cfg := o.config
+	if !o.configSet {
+		configPath, err := paths.GetConfigPath()
+		if err != nil {
+			return nil, err
+		}
+		cfg, err = config.LoadConfig(configPath)
+		if errors.Is(err, fs.ErrNotExist) {
+			logging.Infof("No MCP server configuration found - running without proxied servers")
+		} else if err != nil {
+			return nil, fmt.Errorf("failed to load config: %w", err)
+		}
+		if o.reloadPath == "" {
+			o.reloadPath = configPath
+		}
+	}
+	if cfg != nil {
+		if err := cfg.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid config: %w", err)
+		}
+	}
+
+	if !gatewayOpen.CompareAndSwap(false, true) {
+		return nil, ErrGatewayOpen
+	}
+	g := &Gateway{
+		server:       mcp.NewServer(&mcp.Implementation{Name: o.name, Version: o.version}, nil),
+		starlarkOpts: o.modules,
+		open:         true,
+	}
+	tools.ConfigureReadOnly(config.IsReadOnly(cfg))
+	if config.IsReadOnly(cfg) {
+		g.starlarkOpts = append(g.starlarkOpts, metastarlark.WithReadOnly())
+	}
+	var upstream tools.ProxyManager
+	var reloader *reload.Reloader
+	if cfg != nil {
+		upstream, reloader = g.startUpstream(cfg, o.reloadPath)
+	}
+
+	// Register built-in tools
+	deps := tools.Dependencies{
+		Upstream:        upstream,
+		GatedUpstream:   g.upstream,
+		StarlarkOptions: g.starlarkOpts,
+	}
+	if reloader != nil {
+		deps.Reloader, deps.Editor = reloader, reloader
+	}
+	tools.RegisterBuiltinTools(g.server, deps)
+
+	// Expose published artifacts and the latest results of saved tools as resources
+	tools.RegisterArtifactResources(g.server)
+	tools.RegisterContextResources(g.server)
+
+	// Load and register saved tools
+	if err := tools.RegisterSavedTools(g.server, g.upstream, g.starlarkOpts...); err != nil {
+		logging.Warnf("Failed to load saved tools: %v", err)
+	}
+	return g, nil
+}
+
+// startUpstream applies a valid config: it starts the upstream servers and registers their tools,
+// along with the admin API and config reloading if configured. It returns the ungated upstream,
+// which is nil if no servers could be started, and the reloader if there is one.
+func (g *Gateway) startUpstream(cfg *Config, reloadPath string) (tools.ProxyManager, *reload.Reloader) {
+	tools.ConfigureBuiltinTools(cfg.BuiltinTools)
+	tools.ConfigureToolLimits(cfg.ToolLimits)
+	tools.ConfigureQueue(cfg.Queue)
+	tools.ConfigureParamLimits(cfg.ParamLimits)
+	tools.ConfigureResultLimits(cfg.ResultLimits)
+
+	if cfg.Notify != nil {
+		g.starlarkOpts = append(g.starlarkOpts, metastarlark.WithModule("notify", notify.NewNotifier(cfg.Notify).Module()))
+	}
+	g.starlarkOpts = append(g.starlarkOpts, metastarlark.WithRestrictedModules(cfg.RestrictedModules...))
+
+	// A reload replaces the config in effect, so anything that outlives this call loads it from live
+	live := config.NewLive(cfg)
+
+	// Servers with cached tools are advertised immediately and connect in the background;
+	// if their tools turn out to have changed, the proxied tools are re-registered
+	var upstream tools.ProxyManager
+	g.proxyManager = proxy.NewManager(cfg, proxy.WithToolCache(), proxy.WithToolsChangedHandler(func(serverName string, previous, current []*mcp.Tool) {
+		tools.UpdateProxiedTools(g.server, upstream, live.Load(), serverName, previous, current)
+	}))
+	upstream = g.proxyManager
+	g.closers = append(g.closers, g.proxyManager.Stop)
+
+	// Inject upstream faults if chaos mode is enabled
+	if wrapped, err := chaos.WrapFromEnv(g.proxyManager); err != nil {
+		logging.Warnf("Invalid %s: %v", chaos.EnvVar, err)
+	} else if wrapped != upstream {
+		logging.Infof("Chaos mode enabled: upstream calls may be delayed, fail, or be truncated")
+		upstream = wrapped
+	}
+
+	// Hide and refuse upstream tools with side effects in read-only mode
+	if config.IsReadOnly(cfg) {
+		logging.Infof("Read-only mode enabled: only upstream tools marked read-only are available")
+		upstream = proxy.ReadOnly(upstream)
+	}
+
+	var reloader *reload.Reloader
+	if err := g.proxyManager.Start(); err != nil {
+		logging.Warnf("Failed to start proxy manager: %v", err)
+		upstream = nil
+	} else {
+		logging.Infof("Proxy manager started with %d servers", len(g.proxyManager.GetConnectedServers()))
+		g.upstream = approval.NewGate(upstream, live)
+
+		// Register proxied tools with the MCP server
+		if err := tools.RegisterProxiedTools(g.server, upstream, cfg); err != nil {
+			logging.Warnf("Failed to register proxied tools: %v", err)
+		}
+
+		// Apply edits to the config file without restarting
+		if reloadPath != "" {
+			reloader = reload.New(reloadPath, live, g.proxyManager, g.server, upstream)
+			watchCtx, stopWatching := context.WithCancel(context.Background())
+			g.closers = append(g.closers, stopWatching)
+			go reloader.Watch(watchCtx, reload.DefaultInterval)
+		}
+	}
+
+	// Start the admin API if configured
+	if cfg.Admin != nil {
+		adminDeps := admin.Dependencies{
+			MCPServer:       g.server,
+			Upstream:        upstream,
+			GatedUpstream:   g.upstream,
+			StarlarkOptions: g.starlarkOpts,
+		}
+		if reloader != nil {
+			adminDeps.Editor = reloader
+		}
+		adminServer, err := admin.Serve(live, adminDeps)
+		if err != nil {
+			logging.Warnf("Failed to start admin API: %v", err)
+		} else {
+			g.closers = append(g.closers, func() { adminServer.Close() })
+		}
+	}
+	return upstream, reloader
+}
+
+// Server returns the gateway's MCP server, to be served over any transport or HTTP handler
+func (g *Gateway) Server() *mcp.Server {
+	return g.server
+}
+
+// Run serves the gateway over transport until the client disconnects or ctx is cancelled
+func (g *Gateway) Run(ctx context.Context, transport mcp.Transport) error {
+	return g.server.Run(ctx, transport)
+}
+
+// Execute runs Starlark code with access to the upstream servers, as eval_starlark does
+func (g *Gateway) Execute(ctx context.Context, code string, params map[string]interface{}) (*Result, error) {
+	opts := append(slices.Clip(g.starlarkOpts), metastarlark.WithContext(ctx))
+	return metastarlark.ExecuteWithProxy(code, params, g.upstream, opts...)
+}
+
+// SavedTools lists the saved tools the gateway serves
+func (g *Gateway) SavedTools() ([]*SavedTool, error) {
+	return persistence.ListTools()
+}
+
+// Close stops the upstream servers and anything else the gateway started, after which another
+// Gateway can be created
+func (g *Gateway) Close() {
+	for i := len(g.closers) - 1; i >= 0; i-- {
+		g.closers[i]()
+	}
+	g.closers = nil
+	if g.open {
+		g.open = false
+		gatewayOpen.Store(false)
+	}
+}
@@ -0,0 +1,103 @@
+package metatool
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"go.starlark.net/starlark"
+)
+
+// greet is a custom builtin offered to Starlark code by an embedding program
+var greet = starlark.NewBuiltin("greet", func(thread *starlark.Thread, fn *starlark.Builtin, args starlark.Tuple, kwargs []starlark.Tuple) (starlark.Value, error) {
+	var name string
+	if err := starlark.UnpackArgs(fn.Name(), args, kwargs, "name", &name); err != nil {
+		return nil, err
+	}
+	return starlark.String("Hello, " + name), nil
+})
+
+func TestGateway(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	ctx := context.Background()
+
+	gateway, err := New(WithConfig(nil), WithImplementation("embedded", "1.2.3"), WithModule("greet", greet))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer gateway.Close()
+
+	result, err := gateway.Execute(ctx, `greet("Ada")`, nil)
+	if err != nil || result.Error != "" {
+		t.Fatalf("Execute() = %+v, %v", result, err)
+	}
+	if result.Result != "Hello, Ada" {
+		t.Errorf("Execute() = %v, want Hello, Ada", result.Result)
+	}
+
+	// The gateway can be served over any transport
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	go gateway.Run(ctx, serverTransport)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "1.0.0"}, nil)
+	session, err := client.Connect(ctx, clientTransport, nil)
+	if err != nil {
+		t.Fatalf("Failed to connect client: %v", err)
+	}
+	defer session.Close()
+
+	if info := session.InitializeResult().ServerInfo; info.Name != "embedded" || info.Version != "1.2.3" {
+		t.Errorf("Server reported itself as %s %s, want embedded 1.2.3", info.Name, info.Version)
+	}
+	call, err := session.CallTool(ctx, &mcp.CallToolParams{Name: "eval_starlark", Arguments: map[string]any{"code": `greet("Grace")`}})
+	if err != nil || call.IsError {
+		t.Fatalf("CallTool() = %v, %v", call, err)
+	}
+	if text := call.Content[0].(*mcp.TextContent).Text; !strings.Contains(text, "Hello, Grace") {
+		t.Errorf("eval_starlark returned %s, want it to use the custom builtin", text)
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	cfg := &Config{MCPServers: map[string]ServerConfig{"broken": {}}}
+	if _, err := New(WithConfig(cfg)); err == nil || !strings.Contains(err.Error(), "invalid config") {
+		t.Errorf("New() error = %v, want an invalid config error", err)
+	}
+}
+
+func TestNewWithoutConfigFile(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	gateway, err := New()
+	if err != nil {
+		t.Fatalf("New() error = %v, want the gateway to run without upstream servers", err)
+	}
+	defer gateway.Close()
+
+	tools, err := gateway.SavedTools()
+	if err != nil || len(tools) != 0 {
+		t.Errorf("SavedTools() = %v, %v, want none", tools, err)
+	}
+}
+
+func TestNewWhileGatewayOpen(t *testing.T) {
+	t.Setenv("MCP_METATOOL_DIR", t.TempDir())
+	first, err := New(WithConfig(nil))
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+
+	// A second gateway would change the settings the first runs with
+	if _, err := New(WithConfig(nil)); !errors.Is(err, ErrGatewayOpen) {
+		t.Fatalf("New() with a gateway open error = %v, want ErrGatewayOpen", err)
+	}
+
+	first.Close()
+	first.Close()
+	second, err := New(WithConfig(nil))
+	if err != nil {
+		t.Fatalf("New() after Close() error = %v", err)
+	}
+	second.Close()
+}